@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/tanqiangyes/fyne-word/pkg/index"
+)
+
+// addIndexDirFlag 注册索引目录参数
+func addIndexDirFlag(fs *flag.FlagSet) *string {
+	return fs.String("dir", "", "索引目录（默认使用应用数据目录）")
+}
+
+// openIndex 打开指定目录或默认目录中的索引
+func openIndex(dir string) (*index.Index, error) {
+	if dir == "" {
+		defaultDir, err := index.DefaultDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = defaultDir
+	}
+	return index.Open(dir)
+}
+
+// runIndex 执行 index 子命令
+func runIndex(args []string) error {
+	fs := newFlagSet("index")
+	dir := addIndexDirFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("缺少操作，可选: folders, add, remove, update")
+	}
+
+	idx, err := openIndex(*dir)
+	if err != nil {
+		return err
+	}
+
+	action, rest := fs.Arg(0), fs.Args()[1:]
+	switch action {
+	case "folders":
+		folders := idx.Folders()
+		if len(folders) == 0 {
+			fmt.Println("尚未配置索引文件夹")
+		}
+		for _, folder := range folders {
+			fmt.Println(folder)
+		}
+		fmt.Printf("已索引文件: %d\n", idx.FileCount())
+	case "add":
+		if len(rest) == 0 {
+			return fmt.Errorf("请指定要添加的文件夹")
+		}
+		for _, folder := range rest {
+			if err := idx.AddFolder(folder); err != nil {
+				return err
+			}
+		}
+	case "remove":
+		if len(rest) == 0 {
+			return fmt.Errorf("请指定要移除的文件夹")
+		}
+		for _, folder := range rest {
+			if err := idx.RemoveFolder(folder); err != nil {
+				return err
+			}
+		}
+	case "update":
+		stats, err := idx.Update(func(path string) {
+			fmt.Printf("索引: %s\n", path)
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println(stats)
+	default:
+		return fmt.Errorf("未知操作: %s", action)
+	}
+
+	return nil
+}
+
+// runSearch 执行 search 子命令
+func runSearch(args []string) error {
+	fs := newFlagSet("search")
+	dir := addIndexDirFlag(fs)
+	limit := fs.Int("n", 20, "最多显示的结果数")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("请输入查询词")
+	}
+
+	idx, err := openIndex(*dir)
+	if err != nil {
+		return err
+	}
+
+	query := fs.Arg(0)
+	for _, extra := range fs.Args()[1:] {
+		query += " " + extra
+	}
+
+	results := idx.Search(query, *limit)
+	if len(results) == 0 {
+		fmt.Println("没有找到匹配的文档")
+		return nil
+	}
+	for i, result := range results {
+		fmt.Printf("%d. %s (%.3f)\n   %s\n", i+1, result.Path, result.Score, result.Snippet)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// runMailMerge 执行 mailmerge 子命令
+func runMailMerge(args []string) error {
+	fs := newFlagSet("mailmerge")
+	sheet := fs.String("sheet", "", "XLSX数据源的工作表，默认为第一个工作表")
+	records := fs.String("records", "", "要合并的记录号，如 1-10,15，默认为全部记录")
+	out := fs.String("out", "", "合并为一个文档时的输出文档")
+	dir := fs.String("dir", "", "每条记录保存为一个文档时的输出文件夹")
+	name := fs.String("name", "{{#}}.docx", "每条记录的文件名模式，{{列名}} 为记录中的值，{{#}} 为记录号")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("需要指定主文档和数据源")
+	}
+	if (*out == "") == (*dir == "") {
+		return fmt.Errorf("需要指定 -out 或 -dir 其中之一")
+	}
+
+	pkg, err := document.OpenTemplate(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	template := &document.Document{FilePath: fs.Arg(0), FileName: filepath.Base(fs.Arg(0)), Package: pkg, IsOpen: true}
+	source, err := document.LoadDataSource(fs.Arg(1), *sheet)
+	if err != nil {
+		return err
+	}
+	names, err := template.MergeFieldNames()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("主文档中没有合并域或 {{名称}} 占位符")
+	}
+	if missing := source.MissingColumns(names); len(missing) > 0 {
+		fmt.Fprintf(os.Stderr, "警告: 数据源中没有这些列: %s\n", strings.Join(missing, ", "))
+	}
+	selected, err := document.ParseRecordRange(*records, source.Len())
+	if err != nil {
+		return err
+	}
+	opts := document.MailMergeOptions{
+		Records: selected,
+		Progress: func(done, total int) {
+			fmt.Fprintf(os.Stderr, "\r正在合并: %d/%d", done, total)
+			if done == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		},
+	}
+
+	if *out != "" {
+		manager := document.NewManager()
+		doc, err := manager.MailMerge(template, source, opts)
+		if err != nil {
+			return err
+		}
+		if err := manager.SaveDocumentAs(doc, *out); err != nil {
+			return err
+		}
+		fmt.Printf("已合并 %d 条记录: %s\n", len(selected), *out)
+		return nil
+	}
+
+	paths, err := template.MailMergeFiles(source, *dir, *name, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已保存 %d 个文档到 %s\n", len(paths), *dir)
+	return nil
+}
@@ -0,0 +1,100 @@
+// fyne-word-cli 是不依赖图形界面的命令行工具，与GUI共用文档库索引等功能
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// command 子命令定义
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(args []string) error
+}
+
+// commands 返回所有可用的子命令
+func commands() []command {
+	return []command{
+		{
+			name:    "index",
+			usage:   "index [-dir 索引目录] folders|add 文件夹...|remove 文件夹...|update",
+			summary: "管理文档库索引的文件夹并增量更新索引",
+			run:     runIndex,
+		},
+		{
+			name:    "mailmerge",
+			usage:   "mailmerge [-sheet 工作表] [-records 1-10,15] (-out 输出文档 | -dir 输出文件夹 [-name 文件名模式]) 主文档 数据源",
+			summary: "用CSV或XLSX数据源中的记录填写主文档的合并域，合并为一个文档或每条记录一个文档",
+			run:     runMailMerge,
+		},
+		{
+			name:    "redline",
+			usage:   "redline [-author 作者] 原文档 修订后文档 输出文档",
+			summary: "比较两个文档并生成以修订标记显示差异的Word文档",
+			run:     runRedline,
+		},
+		{
+			name:    "search",
+			usage:   "search [-dir 索引目录] [-n 数量] 查询词",
+			summary: "在文档库索引中按相关度搜索文档",
+			run:     runSearch,
+		},
+		{
+			name:    "split",
+			usage:   "split [-by heading|page|section] [-level 级别] [-dir 输出文件夹] 文档",
+			summary: "在标题、分页符或分节符处将文档拆分为多个文档，以标题文字命名",
+			run:     runSplit,
+		},
+		{
+			name:    "template",
+			usage:   "template [-data 数据文件] 模板 输出文档",
+			summary: "以 .dotx/.docx 模板新建文档，并用JSON或YAML数据填写 {{名称}} 占位符",
+			run:     runTemplate,
+		},
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	if name != "help" && name != "-h" && name != "--help" {
+		fmt.Fprintf(os.Stderr, "未知命令: %s\n\n", name)
+	}
+	printUsage()
+	os.Exit(2)
+}
+
+// printUsage 打印命令用法
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "用法: fyne-word-cli <命令> [参数]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "命令:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+		fmt.Fprintf(os.Stderr, "             %s\n", cmd.usage)
+	}
+}
+
+// newFlagSet 创建子命令的参数解析器
+func newFlagSet(cmd string) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
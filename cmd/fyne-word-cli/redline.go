@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// runRedline 执行 redline 子命令
+func runRedline(args []string) error {
+	fs := newFlagSet("redline")
+	author := fs.String("author", "", "修订作者")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 3 {
+		return fmt.Errorf("需要指定原文档、修订后文档和输出文档")
+	}
+
+	manager := document.NewManager()
+	doc, err := manager.CreateRedline(
+		document.CompareSource{Path: fs.Arg(0)},
+		document.CompareSource{Path: fs.Arg(1)},
+		document.RedlineOptions{Author: *author},
+	)
+	if err != nil {
+		return err
+	}
+	if err := manager.SaveDocumentAs(doc, fs.Arg(2)); err != nil {
+		return err
+	}
+
+	fmt.Printf("修订文档已保存: %s\n", fs.Arg(2))
+	return nil
+}
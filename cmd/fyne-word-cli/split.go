@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// splitModes -by 参数可用的拆分方式
+var splitModes = map[string]document.SplitMode{
+	"heading": document.SplitByHeading,
+	"page":    document.SplitByPageBreak,
+	"section": document.SplitBySection,
+}
+
+// runSplit 执行 split 子命令
+func runSplit(args []string) error {
+	fs := newFlagSet("split")
+	by := fs.String("by", "heading", "拆分位置: heading 标题、page 分页符、section 分节符")
+	level := fs.Int("level", 1, "按标题拆分时的标题级别，在这一级及以上的标题处拆分")
+	dir := fs.String("dir", "", "输出文件夹，默认为文档所在文件夹中的“文档名-拆分”")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("需要指定要拆分的文档")
+	}
+	mode, ok := splitModes[*by]
+	if !ok {
+		return fmt.Errorf("未知的拆分位置: %s", *by)
+	}
+	if *level < 1 || *level > 9 {
+		return fmt.Errorf("标题级别应为1到9: %d", *level)
+	}
+
+	path := fs.Arg(0)
+	pkg, err := document.OpenPackage(path)
+	if err != nil {
+		return err
+	}
+	if *dir == "" {
+		*dir = filepath.Join(filepath.Dir(path), strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))+"-拆分")
+	}
+	doc := &document.Document{FilePath: path, FileName: filepath.Base(path), Package: pkg, IsOpen: true}
+	paths, err := doc.SplitFiles(*dir, document.SplitOptions{
+		Mode:  mode,
+		Level: *level,
+		Progress: func(done, total int) {
+			fmt.Fprintf(os.Stderr, "\r正在拆分: %d/%d", done, total)
+			if done == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		fmt.Println(p)
+	}
+	fmt.Printf("已拆分为 %d 个文档: %s\n", len(paths), *dir)
+	return nil
+}
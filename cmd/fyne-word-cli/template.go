@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// runTemplate 执行 template 子命令
+func runTemplate(args []string) error {
+	fs := newFlagSet("template")
+	data := fs.String("data", "", "占位符数据文件（JSON或YAML）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("需要指定模板和输出文档")
+	}
+
+	manager := document.NewManager()
+	doc, err := manager.NewDocument(document.NewDocumentOptions{Template: fs.Arg(0)})
+	if err != nil {
+		return err
+	}
+	if *data != "" {
+		values, err := document.LoadTemplateData(*data)
+		if err != nil {
+			return err
+		}
+		filled, missing, err := doc.FillPlaceholders(values)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已替换 %d 处占位符\n", filled)
+		if len(missing) > 0 {
+			fmt.Printf("缺少值的占位符: %s\n", strings.Join(missing, ", "))
+		}
+	}
+	if err := manager.SaveDocumentAs(doc, fs.Arg(1)); err != nil {
+		return err
+	}
+
+	fmt.Printf("文档已保存: %s\n", fs.Arg(1))
+	return nil
+}
@@ -1,9 +1,9 @@
 package app
 
 import (
-    "fmt"
     "log"
     "path/filepath"
+    "time"
 
     "fyne.io/fyne/v2"
     fyneApp "fyne.io/fyne/v2/app"
@@ -13,6 +13,8 @@ import (
     "fyne.io/fyne/v2/widget"
 
     "github.com/tanqiangyes/fyne-word/pkg/document"
+    "github.com/tanqiangyes/fyne-word/pkg/index"
+    "github.com/tanqiangyes/fyne-word/pkg/layout"
     "github.com/tanqiangyes/fyne-word/pkg/ui"
 )
 
@@ -27,6 +29,13 @@ type App struct {
     docManager  *document.Manager
     treeView    *ui.TreeView
     contentView *ui.ContentView
+
+    libraryIndex *index.Index
+    layoutEngine *layout.Engine
+
+    statusBar       *ui.StatusBar
+    statisticsTimer *time.Timer    // 文档修改后延迟更新字数
+    recentMenu      *fyne.MenuItem // 文件菜单中的最近使用的文件
 }
 
 // New 创建新的基于go-word库的应用程序
@@ -35,11 +44,13 @@ func New() *App {
         app:        fyneApp.NewWithID("com.tanqiang.fyneword"),
         docManager: document.NewManager(),
     }
+    myApp.layoutEngine = ui.NewLayoutEngine()
 
     myApp.setupMainWindow()
     myApp.setupMenu()
     myApp.setupToolbar()
     myApp.setupContent()
+    myApp.setupSession()
 
     return myApp
 }
@@ -77,33 +88,101 @@ func (app *App) setupContent() {
 func (app *App) createMainMenu() *fyne.MainMenu {
     fileMenu := fyne.NewMenu("文件",
         fyne.NewMenuItem("新建", app.newDocument),
+        fyne.NewMenuItem("从模板新建...", app.newFromTemplate),
         fyne.NewMenuItem("打开", app.openDocument),
+        app.createRecentMenu(),
         fyne.NewMenuItem("保存", app.saveDocument),
         fyne.NewMenuItem("另存为", app.saveDocumentAs),
         fyne.NewMenuItem("导出PDF", app.exportToPDF),
+        fyne.NewMenuItem("合并文档...", app.showMergeDocuments),
+        fyne.NewMenuItem("拆分文档...", app.showSplitDocument),
+        fyne.NewMenuItemSeparator(),
+        fyne.NewMenuItem("页面设置...", app.showPageSetup),
+        fyne.NewMenuItem("填写模板占位符...", app.fillPlaceholders),
+        fyne.NewMenuItemSeparator(),
         fyne.NewMenuItem("退出", func() { app.app.Quit() }),
     )
 
     editMenu := fyne.NewMenu("编辑",
-        fyne.NewMenuItem("撤销", func() {}),
-        fyne.NewMenuItem("重做", func() {}),
-        fyne.NewMenuItem("剪切", func() {}),
-        fyne.NewMenuItem("复制", func() {}),
-        fyne.NewMenuItem("粘贴", func() {}),
+        fyne.NewMenuItem("撤销", app.undo),
+        fyne.NewMenuItem("重做", app.redo),
+        fyne.NewMenuItemSeparator(),
+        fyne.NewMenuItem("剪切", app.cut),
+        fyne.NewMenuItem("复制", app.copy),
+        fyne.NewMenuItem("粘贴", app.paste),
+    )
+
+    sectionBreakMenu := fyne.NewMenuItem("分节符", nil)
+    sectionBreakMenu.ChildMenu = fyne.NewMenu("",
+        fyne.NewMenuItem("下一页", func() { app.insertSectionBreak(document.SectionNextPage) }),
+        fyne.NewMenuItem("连续", func() { app.insertSectionBreak(document.SectionContinuous) }),
+        fyne.NewMenuItem("偶数页", func() { app.insertSectionBreak(document.SectionEvenPage) }),
+        fyne.NewMenuItem("奇数页", func() { app.insertSectionBreak(document.SectionOddPage) }),
+    )
+
+    insertMenu := fyne.NewMenu("插入",
+        fyne.NewMenuItem("插入目录", app.insertTOC),
+        fyne.NewMenuItem("更新目录", app.updateTOC),
+        fyne.NewMenuItemSeparator(),
+        sectionBreakMenu,
+        fyne.NewMenuItem("脚注和尾注", func() { app.treeView.Select("notes") }),
+        fyne.NewMenuItemSeparator(),
+        fyne.NewMenuItem("超链接...", app.insertHyperlink),
+        fyne.NewMenuItem("书签...", app.showBookmarks),
+        fyne.NewMenuItemSeparator(),
+        fyne.NewMenuItem("域...", app.insertField),
+        fyne.NewMenuItem("更新域", app.updateFields),
+    )
+
+    alignMenu := fyne.NewMenuItem("对齐方式", nil)
+    alignMenu.ChildMenu = fyne.NewMenu("",
+        fyne.NewMenuItem("左对齐", func() { app.setAlignment(document.AlignLeft) }),
+        fyne.NewMenuItem("居中", func() { app.setAlignment(document.AlignCenter) }),
+        fyne.NewMenuItem("右对齐", func() { app.setAlignment(document.AlignRight) }),
+        fyne.NewMenuItem("两端对齐", func() { app.setAlignment(document.AlignJustify) }),
+        fyne.NewMenuItem("分散对齐", func() { app.setAlignment(document.AlignDistribute) }),
+    )
+
+    formatMenu := fyne.NewMenu("格式",
+        fyne.NewMenuItem("段落...", app.showParagraphFormat),
+        alignMenu,
+        fyne.NewMenuItem("首行缩进2字符", app.indentFirstLine),
+        fyne.NewMenuItemSeparator(),
+        fyne.NewMenuItem("项目符号", func() { app.toggleList(document.BulletList) }),
+        fyne.NewMenuItem("编号", func() { app.toggleList(document.NumberedList) }),
+        fyne.NewMenuItem("增加列表级别", func() { app.changeListLevel(1) }),
+        fyne.NewMenuItem("减少列表级别", func() { app.changeListLevel(-1) }),
+        fyne.NewMenuItem("重新编号", app.restartNumbering),
     )
 
     viewMenu := fyne.NewMenu("视图",
-        fyne.NewMenuItem("树形视图", func() {}),
+        fyne.NewMenuItem("树形视图", func() { app.treeView.SetOutlineMode(false) }),
+        fyne.NewMenuItem("大纲视图", func() { app.treeView.SetOutlineMode(true) }),
+        fyne.NewMenuItem("页面视图", app.showPagePreview),
+        fyne.NewMenuItem("全部展开", func() { app.treeView.ExpandAll() }),
+        fyne.NewMenuItem("全部折叠", func() { app.treeView.CollapseAll() }),
         fyne.NewMenuItem("内容视图", func() {}),
         fyne.NewMenuItem("全屏", func() { app.window.SetFullScreen(true) }),
     )
 
+    reviewMenu := fyne.NewMenu("审阅",
+        fyne.NewMenuItem("修订", func() { app.treeView.Select("revisions") }),
+        fyne.NewMenuItem("批注", func() { app.treeView.Select("comments") }),
+    )
+
+    toolsMenu := fyne.NewMenu("工具",
+        fyne.NewMenuItem("字数统计...", app.showStatistics),
+        fyne.NewMenuItem("比较文档", app.compareDocuments),
+        fyne.NewMenuItem("邮件合并...", app.showMailMerge),
+        fyne.NewMenuItem("文档库搜索", app.showLibrarySearch),
+    )
+
     helpMenu := fyne.NewMenu("帮助",
         fyne.NewMenuItem("关于", func() {}),
         fyne.NewMenuItem("帮助", func() {}),
     )
 
-    return fyne.NewMainMenu(fileMenu, editMenu, viewMenu, helpMenu)
+    return fyne.NewMainMenu(fileMenu, editMenu, insertMenu, formatMenu, viewMenu, reviewMenu, toolsMenu, helpMenu)
 }
 
 // createToolbar 创建工具栏
@@ -113,19 +192,28 @@ func (app *App) createToolbar() fyne.CanvasObject {
     saveBtn := widget.NewButton("保存", app.saveDocument)
     exportBtn := widget.NewButton("导出PDF", app.exportToPDF)
 
-    return container.NewHBox(
+    items := []fyne.CanvasObject{
         newBtn, openBtn, saveBtn, exportBtn,
         widget.NewSeparator(),
-    )
+    }
+    items = append(items, app.createFormatToolbar()...)
+    return container.NewHBox(items...)
 }
 
 // createMainLayout 创建主布局
 func (app *App) createMainLayout() fyne.CanvasObject {
     // 创建树形视图
     app.treeView = ui.NewTreeView(app.docManager)
+    app.treeView.SetWindow(app.window)
 
     // 创建内容视图
     app.contentView = ui.NewContentView(app.docManager)
+    app.contentView.SetWindow(app.window)
+    app.contentView.SetOnChanged(app.documentChanged)
+    app.contentView.SetLayoutEngine(app.layoutEngine)
+    app.contentView.SetAuthorFunc(func() string {
+        return app.app.Preferences().StringWithFallback(authorKey, "fyne-word")
+    })
 
     // 设置树形视图的选择回调
     app.treeView.SetOnSelect(func(nodeID string) {
@@ -139,7 +227,7 @@ func (app *App) createMainLayout() fyne.CanvasObject {
     )
     split.SetOffset(0.3) // 树形视图占30%宽度
 
-    return container.NewBorder(app.toolbar, nil, nil, nil, split)
+    return container.NewBorder(app.toolbar, app.createStatusBar(), nil, nil, split)
 }
 
 // newDocument 新建文档
@@ -147,18 +235,18 @@ func (app *App) newDocument() {
     log.Println("新建文档")
 
     // 使用文档管理器创建新文档
-    doc, err := app.docManager.NewDocument()
+    doc, err := app.docManager.NewDocument(document.NewDocumentOptions{})
     if err != nil {
         dialog.ShowError(err, app.window)
         return
     }
 
     // 刷新UI显示新文档
-    app.treeView.Refresh()
+    app.documentChanged()
     app.contentView.ShowNode("title")
 
     log.Printf("新文档创建成功: %s", doc.FileName)
-    dialog.ShowInformation("成功", fmt.Sprintf("新文档创建成功: %s", doc.FileName), app.window)
+    app.showStatus("已新建文档: %s", doc.FileName)
 }
 
 // openDocument 打开文档
@@ -173,28 +261,33 @@ func (app *App) openDocument() {
         }
         defer reader.Close()
 
-        filePath := reader.URI().Path()
-        log.Printf("正在使用go-word库打开文档: %s", filePath)
-
-        // 使用go-word库打开文档
-        doc, err := app.docManager.OpenDocument(filePath)
-        if err != nil {
-            dialog.ShowError(err, app.window)
-            return
-        }
-
-        // 刷新UI
-        app.treeView.Refresh()
-        app.contentView.ShowNode("title")
-
-        log.Printf("go-word文档打开成功: %s", doc.FileName)
-        dialog.ShowInformation("成功", fmt.Sprintf("文档打开成功: %s", doc.FileName), app.window)
+        app.openDocumentPath(reader.URI().Path())
     }, app.window)
 
     fd.SetFilter(storage.NewExtensionFileFilter([]string{".docx", ".doc"}))
     fd.Show()
 }
 
+// openDocumentPath 打开指定路径的文档并刷新界面
+func (app *App) openDocumentPath(filePath string) {
+    log.Printf("正在使用go-word库打开文档: %s", filePath)
+
+    // 使用go-word库打开文档
+    doc, err := app.docManager.OpenDocument(filePath)
+    if err != nil {
+        dialog.ShowError(err, app.window)
+        return
+    }
+
+    // 刷新UI
+    app.documentChanged()
+    app.contentView.ShowNode("title")
+
+    log.Printf("go-word文档打开成功: %s", doc.FileName)
+    app.addRecentFile(doc.FilePath)
+    app.showStatus("已打开: %s", doc.FileName)
+}
+
 // saveDocument 保存文档
 func (app *App) saveDocument() {
 	doc := app.docManager.GetCurrentDocument()
@@ -203,20 +296,7 @@ func (app *App) saveDocument() {
 		return
 	}
 
-	err := app.docManager.SaveDocument(doc)
-	if err != nil {
-		// 检查是否为保存路径未设置错误
-		if document.IsSavePathNotSetError(err) {
-			// 自动打开文件保存对话框
-			app.showSaveDialog(doc)
-			return
-		}
-		// 其他错误正常显示
-		dialog.ShowError(err, app.window)
-		return
-	}
-
-	dialog.ShowInformation("成功", "文档保存成功", app.window)
+	app.saveDocumentTo(doc, "")
 }
 
 // showSaveDialog 显示保存对话框
@@ -231,14 +311,7 @@ func (app *App) showSaveDialog(doc *document.Document) {
 		}
 		defer writer.Close()
 
-		newPath := writer.URI().Path()
-		err = app.docManager.SaveDocumentAs(doc, newPath)
-		if err != nil {
-			dialog.ShowError(err, app.window)
-			return
-		}
-
-		dialog.ShowInformation("成功", "文档保存成功", app.window)
+		app.saveDocumentTo(doc, writer.URI().Path())
 	}, app.window)
 
 	fd.SetFileName(doc.FileName)
@@ -264,14 +337,7 @@ func (app *App) saveDocumentAs() {
         }
         defer writer.Close()
 
-        newPath := writer.URI().Path()
-        err = app.docManager.SaveDocumentAs(doc, newPath)
-        if err != nil {
-            dialog.ShowError(err, app.window)
-            return
-        }
-
-        dialog.ShowInformation("成功", "文档另存为成功", app.window)
+        app.saveDocumentTo(doc, writer.URI().Path())
     }, app.window)
 
     fd.SetFileName(doc.FileName)
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+	"github.com/tanqiangyes/fyne-word/pkg/ui"
+)
+
+// browseOption 文档选择框中用于从磁盘选择文件的选项
+const browseOption = "从文件选择..."
+
+// authorKey 修订和批注使用的作者名称的偏好设置键
+const authorKey = "redline.author"
+
+// documentPicker 选择已打开的文档或磁盘上的文件
+type documentPicker struct {
+	selector *widget.Select
+	sources  map[string]document.CompareSource
+	selected string
+}
+
+// newDocumentPicker 创建文档选择框，选项为所有已打开的文档
+func (app *App) newDocumentPicker(parent fyne.Window) *documentPicker {
+	picker := &documentPicker{
+		sources: make(map[string]document.CompareSource),
+	}
+
+	var options []string
+	for _, doc := range app.docManager.GetOpenDocuments() {
+		label := doc.FileName
+		if _, exists := picker.sources[label]; exists {
+			label = fmt.Sprintf("%s (%s)", doc.FileName, doc.FilePath)
+		}
+		picker.sources[label] = document.CompareSource{Doc: doc}
+		options = append(options, label)
+	}
+	options = append(options, browseOption)
+
+	picker.selector = widget.NewSelect(options, nil)
+	picker.selector.OnChanged = func(option string) {
+		if option != browseOption {
+			picker.selected = option
+			return
+		}
+
+		// 从磁盘选择文件后将其加入选项
+		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, parent)
+			}
+			if err != nil || reader == nil {
+				picker.selector.SetSelected(picker.selected)
+				return
+			}
+			defer reader.Close()
+
+			path := reader.URI().Path()
+			label := filepath.Base(path) + " (" + path + ")"
+			if _, exists := picker.sources[label]; !exists {
+				picker.sources[label] = document.CompareSource{Path: path}
+				picker.selector.Options = append([]string{label}, picker.selector.Options...)
+			}
+			picker.selector.SetSelected(label)
+		}, parent)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".docx"}))
+		fd.Show()
+	}
+
+	return picker
+}
+
+// source 返回当前选择的文档来源
+func (p *documentPicker) source() (document.CompareSource, bool) {
+	source, ok := p.sources[p.selected]
+	return source, ok
+}
+
+// compareDocuments 选择两个文档并显示比较结果
+func (app *App) compareDocuments() {
+	oldPicker := app.newDocumentPicker(app.window)
+	newPicker := app.newDocumentPicker(app.window)
+
+	// 默认将当前文档作为修订后的文档
+	if current := app.docManager.GetCurrentDocument(); current != nil {
+		for label, source := range newPicker.sources {
+			if source.Doc == current {
+				newPicker.selector.SetSelected(label)
+			}
+		}
+	}
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("原文档", oldPicker.selector),
+		widget.NewFormItem("修订后文档", newPicker.selector),
+	}
+
+	dialog.ShowForm("比较文档", "比较", "取消", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		oldSource, ok1 := oldPicker.source()
+		newSource, ok2 := newPicker.source()
+		if !ok1 || !ok2 {
+			dialog.ShowInformation("提示", "请选择要比较的两个文档", app.window)
+			return
+		}
+
+		comparison, err := document.Compare(oldSource, newSource)
+		if err != nil {
+			dialog.ShowError(err, app.window)
+			return
+		}
+		app.showComparison(oldSource, newSource, comparison)
+	}, app.window)
+}
+
+// showComparison 在独立窗口中显示比较结果
+func (app *App) showComparison(oldSource, newSource document.CompareSource, comparison *document.Comparison) {
+	win := app.app.NewWindow(fmt.Sprintf("比较文档 - %s ↔ %s",
+		filepath.Base(comparison.OldName), filepath.Base(comparison.NewName)))
+	win.Resize(fyne.NewSize(1000, 700))
+
+	redlineBtn := widget.NewButton("生成修订文档", func() {
+		app.createRedline(win, oldSource, newSource)
+	})
+
+	compareView := ui.NewCompareView(comparison)
+	win.SetContent(container.NewBorder(
+		container.NewHBox(redlineBtn), nil, nil, nil,
+		compareView.GetWidget(),
+	))
+	win.Show()
+}
+
+// createRedline 生成以修订标记显示差异的文档并提示保存
+func (app *App) createRedline(parent fyne.Window, oldSource, newSource document.CompareSource) {
+	authorEntry := widget.NewEntry()
+	authorEntry.SetText(app.app.Preferences().StringWithFallback(authorKey, "fyne-word"))
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("修订作者", authorEntry),
+	}
+	dialog.ShowForm("生成修订文档", "生成", "取消", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		app.app.Preferences().SetString(authorKey, authorEntry.Text)
+
+		doc, err := app.docManager.CreateRedline(oldSource, newSource, document.RedlineOptions{
+			Author: authorEntry.Text,
+		})
+		if err != nil {
+			dialog.ShowError(err, parent)
+			return
+		}
+
+		app.documentChanged()
+		app.contentView.ShowNode("title")
+		app.showSaveDialog(doc)
+	}, parent)
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"fyne.io/fyne/v2/dialog"
+)
+
+// undo 撤销当前文档最近一步操作
+func (app *App) undo() {
+	if _, err := app.contentView.Undo(); err != nil {
+		dialog.ShowInformation("提示", err.Error(), app.window)
+	}
+}
+
+// redo 重做当前文档最近撤销的操作
+func (app *App) redo() {
+	if _, err := app.contentView.Redo(); err != nil {
+		dialog.ShowInformation("提示", err.Error(), app.window)
+	}
+}
+
+// cut 剪切编辑器中选择的文字
+func (app *App) cut() {
+	if err := app.contentView.Cut(); err != nil {
+		dialog.ShowInformation("提示", err.Error(), app.window)
+	}
+}
+
+// copy 复制编辑器中选择的文字
+func (app *App) copy() {
+	if err := app.contentView.Copy(); err != nil {
+		dialog.ShowInformation("提示", err.Error(), app.window)
+	}
+}
+
+// paste 将剪贴板的内容粘贴到编辑器中
+func (app *App) paste() {
+	if err := app.contentView.Paste(); err != nil {
+		dialog.ShowInformation("提示", err.Error(), app.window)
+	}
+}
@@ -0,0 +1,323 @@
+package app
+
+import (
+	"fmt"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+	"github.com/tanqiangyes/fyne-word/pkg/ui"
+)
+
+// formatParagraph 返回当前文档和内容视图中显示的段落并记录撤销点，没有时提示用户
+func (app *App) formatParagraph() (*document.Document, int, bool) {
+	doc, paragraph, ok := app.currentParagraph()
+	if ok {
+		doc.Checkpoint("设置格式")
+	}
+	return doc, paragraph, ok
+}
+
+// currentParagraph 返回当前文档和内容视图中显示的段落，没有时提示用户
+func (app *App) currentParagraph() (*document.Document, int, bool) {
+	doc := app.docManager.GetCurrentDocument()
+	if doc == nil {
+		dialog.ShowInformation("提示", "没有打开的文档", app.window)
+		return nil, 0, false
+	}
+	paragraph := app.contentView.CurrentParagraph()
+	if paragraph < 0 {
+		dialog.ShowInformation("提示", "请先在树形视图中选择段落", app.window)
+		return nil, 0, false
+	}
+	return doc, paragraph, true
+}
+
+// afterFormat 格式修改后显示错误，或刷新树形视图和段落内容
+func (app *App) afterFormat(err error, paragraph int) {
+	if err != nil {
+		dialog.ShowError(err, app.window)
+		return
+	}
+	app.documentChanged()
+	app.contentView.ShowParagraph(paragraph)
+}
+
+// toggleList 为当前段落切换项目符号或编号
+func (app *App) toggleList(kind document.ListKind) {
+	doc, paragraph, ok := app.formatParagraph()
+	if !ok {
+		return
+	}
+	app.afterFormat(doc.ToggleList(kind, paragraph, paragraph), paragraph)
+}
+
+// changeListLevel 将当前段落的列表级别增加 delta 级
+func (app *App) changeListLevel(delta int) {
+	doc, paragraph, ok := app.formatParagraph()
+	if !ok {
+		return
+	}
+	item, inList := document.NewDocumentAdapter(doc).GetParagraphListItem(paragraph)
+	if !inList {
+		dialog.ShowInformation("提示", "当前段落不在列表中", app.window)
+		return
+	}
+	app.afterFormat(doc.SetListLevel(paragraph, item.Level+delta), paragraph)
+}
+
+// restartNumbering 从当前段落开始重新编号
+func (app *App) restartNumbering() {
+	doc, paragraph, ok := app.formatParagraph()
+	if !ok {
+		return
+	}
+	app.afterFormat(doc.RestartNumbering(paragraph), paragraph)
+}
+
+// fontSizes 字号选项，中文字号和对应的磅值
+var fontSizes = []struct {
+	name string
+	size float64
+}{
+	{"初号", 42}, {"小初", 36}, {"一号", 26}, {"小一", 24}, {"二号", 22}, {"小二", 18},
+	{"三号", 16}, {"小三", 15}, {"四号", 14}, {"小四", 12}, {"五号", 10.5}, {"小五", 9},
+	{"六号", 7.5}, {"小六", 6.5}, {"七号", 5.5}, {"八号", 5},
+	{"8", 8}, {"10", 10}, {"12", 12}, {"14", 14}, {"16", 16}, {"18", 18},
+	{"20", 20}, {"24", 24}, {"28", 28}, {"36", 36}, {"48", 48}, {"72", 72},
+}
+
+// eastAsiaFonts 常用中文字体
+var eastAsiaFonts = []string{"宋体", "黑体", "楷体", "仿宋", "微软雅黑", "等线"}
+
+// latinFonts 常用西文字体
+var latinFonts = []string{"Times New Roman", "Arial", "Calibri", "Cambria", "Courier New"}
+
+// fontColors 字体颜色选项
+var fontColors = []struct {
+	name  string
+	value string
+}{
+	{"自动", ""}, {"黑色", "000000"}, {"红色", "FF0000"}, {"深红", "C00000"}, {"橙色", "FFC000"},
+	{"黄色", "FFFF00"}, {"绿色", "00B050"}, {"蓝色", "0070C0"}, {"深蓝", "002060"}, {"紫色", "7030A0"},
+	{"灰色", "808080"},
+}
+
+// highlightOptions 突出显示颜色选项
+var highlightOptions = []struct {
+	name  string
+	value string
+}{
+	{"无", ""}, {"黄色", "yellow"}, {"鲜绿", "green"}, {"青绿", "cyan"}, {"粉红", "magenta"},
+	{"蓝色", "blue"}, {"红色", "red"}, {"灰色", "lightGray"},
+}
+
+// createFormatToolbar 创建字符格式工具栏，格式作用于内容视图中选择的文字，未选择时作用于整个段落
+func (app *App) createFormatToolbar() []fyne.CanvasObject {
+	boldBtn := widget.NewButton("B", func() { app.toggleCharFormat(document.CharBold) })
+	italicBtn := widget.NewButton("I", func() { app.toggleCharFormat(document.CharItalic) })
+	underlineBtn := widget.NewButton("U", func() { app.toggleCharFormat(document.CharUnderline) })
+	strikeBtn := widget.NewButton("S", func() { app.toggleCharFormat(document.CharStrike) })
+
+	eastAsiaSelect := widget.NewSelect(eastAsiaFonts, nil)
+	eastAsiaSelect.PlaceHolder = "中文字体"
+	eastAsiaSelect.OnChanged = func(font string) {
+		if font != "" {
+			app.applyCharFormat(document.CharFormat{EastAsiaFont: font}, document.CharEastAsiaFont)
+		}
+	}
+	latinSelect := widget.NewSelect(latinFonts, nil)
+	latinSelect.PlaceHolder = "西文字体"
+	latinSelect.OnChanged = func(font string) {
+		if font != "" {
+			app.applyCharFormat(document.CharFormat{Font: font}, document.CharFont)
+		}
+	}
+
+	var sizeNames []string
+	for _, s := range fontSizes {
+		sizeNames = append(sizeNames, s.name)
+	}
+	sizeSelect := widget.NewSelect(sizeNames, nil)
+	sizeSelect.PlaceHolder = "字号"
+	sizeSelect.OnChanged = func(string) {
+		if i := sizeSelect.SelectedIndex(); i >= 0 {
+			app.applyCharFormat(document.CharFormat{Size: fontSizes[i].size}, document.CharSize)
+		}
+	}
+
+	var colorNames []string
+	for _, c := range fontColors {
+		colorNames = append(colorNames, c.name)
+	}
+	colorNames = append(colorNames, "其他颜色...")
+	colorSelect := widget.NewSelect(colorNames, nil)
+	colorSelect.PlaceHolder = "字体颜色"
+	colorSelect.OnChanged = func(string) {
+		i := colorSelect.SelectedIndex()
+		switch {
+		case i < 0:
+		case i < len(fontColors):
+			app.applyCharFormat(document.CharFormat{Color: fontColors[i].value}, document.CharColor)
+		default:
+			app.pickFontColor()
+		}
+	}
+
+	var highlightNames []string
+	for _, h := range highlightOptions {
+		highlightNames = append(highlightNames, h.name)
+	}
+	highlightSelect := widget.NewSelect(highlightNames, nil)
+	highlightSelect.PlaceHolder = "突出显示"
+	highlightSelect.OnChanged = func(string) {
+		if i := highlightSelect.SelectedIndex(); i >= 0 {
+			app.applyCharFormat(document.CharFormat{Highlight: highlightOptions[i].value}, document.CharHighlight)
+		}
+	}
+
+	clearBtn := widget.NewButton("清除格式", func() {
+		doc, r, ok := app.formatRange()
+		if !ok {
+			return
+		}
+		app.afterFormat(doc.ClearCharFormat(r, document.AllCharProperties), r.StartParagraph)
+	})
+
+	return []fyne.CanvasObject{
+		boldBtn, italicBtn, underlineBtn, strikeBtn,
+		eastAsiaSelect, latinSelect, sizeSelect, colorSelect, highlightSelect, clearBtn,
+	}
+}
+
+// formatRange 返回当前文档和内容视图中选择的文字范围并记录撤销点，没有时提示用户
+func (app *App) formatRange() (*document.Document, document.TextRange, bool) {
+	doc := app.docManager.GetCurrentDocument()
+	if doc == nil {
+		dialog.ShowInformation("提示", "没有打开的文档", app.window)
+		return nil, document.TextRange{}, false
+	}
+	r, ok := app.contentView.Selection()
+	if !ok {
+		dialog.ShowInformation("提示", "请先在树形视图中选择段落", app.window)
+		return nil, document.TextRange{}, false
+	}
+	doc.Checkpoint("设置格式")
+	return doc, r, true
+}
+
+// applyCharFormat 为选择的文字设置字符格式
+func (app *App) applyCharFormat(format document.CharFormat, props document.CharProperty) {
+	doc, r, ok := app.formatRange()
+	if !ok {
+		return
+	}
+	app.afterFormat(doc.ApplyCharFormat(r, format, props), r.StartParagraph)
+}
+
+// toggleCharFormat 切换选择文字的加粗、倾斜、下划线或删除线，按范围第一个字符的格式决定开关
+func (app *App) toggleCharFormat(prop document.CharProperty) {
+	doc, r, ok := app.formatRange()
+	if !ok {
+		return
+	}
+	current, err := doc.CharFormatAt(r.StartParagraph, r.StartOffset)
+	if err != nil {
+		dialog.ShowError(err, app.window)
+		return
+	}
+	var format document.CharFormat
+	switch prop {
+	case document.CharBold:
+		format.Bold = !current.Bold
+	case document.CharItalic:
+		format.Italic = !current.Italic
+	case document.CharUnderline:
+		if current.Underline == "" {
+			format.Underline = "single"
+		}
+	case document.CharStrike:
+		format.Strike = !current.Strike
+	}
+	app.afterFormat(doc.ApplyCharFormat(r, format, prop), r.StartParagraph)
+}
+
+// pickFontColor 打开颜色选择器设置字体颜色
+func (app *App) pickFontColor() {
+	picker := dialog.NewColorPicker("字体颜色", "选择字体颜色", func(c color.Color) {
+		r, g, b, _ := c.RGBA()
+		value := fmt.Sprintf("%02X%02X%02X", r>>8, g>>8, b>>8)
+		app.applyCharFormat(document.CharFormat{Color: value}, document.CharColor)
+	}, app.window)
+	picker.Advanced = true
+	picker.Show()
+}
+
+// showParagraphFormat 显示当前段落的段落格式对话框
+func (app *App) showParagraphFormat() {
+	doc, paragraph, ok := app.currentParagraph()
+	if !ok {
+		return
+	}
+	ui.ShowParagraphFormatDialog(doc, paragraph, app.window, func() {
+		app.afterFormat(nil, paragraph)
+	})
+}
+
+// setAlignment 设置当前段落的对齐方式
+func (app *App) setAlignment(alignment document.Alignment) {
+	doc, paragraph, ok := app.formatParagraph()
+	if !ok {
+		return
+	}
+	app.afterFormat(doc.SetAlignment(paragraph, alignment), paragraph)
+}
+
+// indentFirstLine 将当前段落设置为中文习惯的首行缩进两个字符
+func (app *App) indentFirstLine() {
+	doc, paragraph, ok := app.formatParagraph()
+	if !ok {
+		return
+	}
+	format := document.ParagraphFormat{FirstLineChars: document.ChineseIndentChars}
+	app.afterFormat(doc.SetParagraphFormat(paragraph, paragraph, format, document.ParaFirstLine), paragraph)
+}
+
+// showPageSetup 显示当前段落所在节的页面设置对话框，没有选择段落时设置第一节
+func (app *App) showPageSetup() {
+	doc := app.docManager.GetCurrentDocument()
+	if doc == nil {
+		dialog.ShowInformation("提示", "没有打开的文档", app.window)
+		return
+	}
+	section := 0
+	if paragraph := app.contentView.CurrentParagraph(); paragraph >= 0 {
+		sections, err := doc.Sections()
+		if err != nil {
+			dialog.ShowError(err, app.window)
+			return
+		}
+		for _, s := range sections {
+			if paragraph >= s.StartParagraph && paragraph <= s.EndParagraph {
+				section = s.Index
+				break
+			}
+		}
+	}
+	ui.ShowPageSetupDialog(doc, section, app.window, func() {
+		app.documentChanged()
+		app.contentView.ShowNode(fmt.Sprintf("x%d", section+1))
+	})
+}
+
+// insertSectionBreak 在当前段落之后插入分节符
+func (app *App) insertSectionBreak(kind document.SectionBreak) {
+	doc, paragraph, ok := app.formatParagraph()
+	if !ok {
+		return
+	}
+	app.afterFormat(doc.InsertSectionBreak(paragraph, kind), paragraph)
+}
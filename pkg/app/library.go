@@ -0,0 +1,168 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/index"
+)
+
+// getLibraryIndex 获取文档库索引，首次调用时从应用数据目录加载
+func (app *App) getLibraryIndex() (*index.Index, error) {
+	if app.libraryIndex != nil {
+		return app.libraryIndex, nil
+	}
+
+	dir, err := index.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	idx, err := index.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	app.libraryIndex = idx
+	return idx, nil
+}
+
+// showLibrarySearch 显示文档库索引与搜索窗口
+func (app *App) showLibrarySearch() {
+	idx, err := app.getLibraryIndex()
+	if err != nil {
+		dialog.ShowError(err, app.window)
+		return
+	}
+
+	win := app.app.NewWindow("文档库搜索")
+	win.Resize(fyne.NewSize(800, 600))
+
+	status := widget.NewLabel(fmt.Sprintf("已索引文件: %d", idx.FileCount()))
+
+	// 索引文件夹列表
+	folders := idx.Folders()
+	selectedFolder := -1
+	folderList := widget.NewList(
+		func() int { return len(folders) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(folders[id])
+		},
+	)
+	folderList.OnSelected = func(id widget.ListItemID) { selectedFolder = id }
+	refreshFolders := func() {
+		folders = idx.Folders()
+		selectedFolder = -1
+		folderList.UnselectAll()
+		folderList.Refresh()
+	}
+
+	var updateBtn *widget.Button
+	updateIndex := func() {
+		updateBtn.Disable()
+		status.SetText("正在更新索引...")
+		go func() {
+			stats, err := idx.Update(func(path string) {
+				fyne.Do(func() {
+					status.SetText("正在索引: " + filepath.Base(path))
+				})
+			})
+			fyne.Do(func() {
+				updateBtn.Enable()
+				if err != nil {
+					status.SetText("索引更新失败")
+					dialog.ShowError(err, win)
+					return
+				}
+				status.SetText(fmt.Sprintf("已索引文件: %d（%s）", idx.FileCount(), stats))
+			})
+		}()
+	}
+	updateBtn = widget.NewButton("更新索引", updateIndex)
+
+	addBtn := widget.NewButton("添加文件夹", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if uri == nil {
+				return
+			}
+			if err := idx.AddFolder(uri.Path()); err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			refreshFolders()
+			updateIndex()
+		}, win)
+	})
+	removeBtn := widget.NewButton("移除文件夹", func() {
+		if selectedFolder < 0 || selectedFolder >= len(folders) {
+			return
+		}
+		if err := idx.RemoveFolder(folders[selectedFolder]); err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		refreshFolders()
+		status.SetText(fmt.Sprintf("已索引文件: %d", idx.FileCount()))
+	})
+
+	// 搜索结果列表
+	var results []index.Result
+	resultList := widget.NewList(
+		func() int { return len(results) },
+		func() fyne.CanvasObject {
+			return container.NewVBox(widget.NewLabel(""), widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			box := o.(*fyne.Container)
+			result := results[id]
+			box.Objects[0].(*widget.Label).SetText(fmt.Sprintf("%s  (%.2f)", result.Path, result.Score))
+			box.Objects[1].(*widget.Label).SetText(result.Snippet)
+		},
+	)
+	resultList.OnSelected = func(id widget.ListItemID) {
+		resultList.UnselectAll()
+		if id < 0 || id >= len(results) {
+			return
+		}
+		log.Printf("从文档库打开: %s", results[id].Path)
+		app.openDocumentPath(results[id].Path)
+	}
+
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("输入关键词搜索文档库...")
+	searchEntry.OnSubmitted = func(query string) {
+		results = idx.Search(query, 100)
+		resultList.Refresh()
+		status.SetText(fmt.Sprintf("找到 %d 个文档", len(results)))
+	}
+
+	folderPanel := container.NewBorder(
+		widget.NewLabel("索引文件夹"),
+		container.NewHBox(addBtn, removeBtn, updateBtn),
+		nil, nil,
+		folderList,
+	)
+	searchPanel := container.NewBorder(
+		container.NewBorder(nil, nil, nil,
+			widget.NewButton("搜索", func() { searchEntry.OnSubmitted(searchEntry.Text) }),
+			searchEntry),
+		nil, nil, nil,
+		resultList,
+	)
+
+	split := container.NewVSplit(folderPanel, searchPanel)
+	split.SetOffset(0.3)
+
+	win.SetContent(container.NewBorder(nil, status, nil, nil, split))
+	win.Show()
+}
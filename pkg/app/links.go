@@ -0,0 +1,98 @@
+package app
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+	"github.com/tanqiangyes/fyne-word/pkg/ui"
+)
+
+// editorRange 返回当前文档和编辑器中选择的范围，没有显示编辑器时提示用户
+func (app *App) editorRange() (*document.Document, document.TextRange, bool) {
+	doc := app.docManager.GetCurrentDocument()
+	if doc == nil {
+		dialog.ShowInformation("提示", "没有打开的文档", app.window)
+		return nil, document.TextRange{}, false
+	}
+	r, ok := app.contentView.EditorRange()
+	if !ok {
+		dialog.ShowInformation("提示", "请先在文档内容中选择文字或确定插入位置", app.window)
+		return nil, document.TextRange{}, false
+	}
+	return doc, r, true
+}
+
+// insertHyperlink 为选择的文字插入超链接，光标位于超链接中时编辑该超链接
+func (app *App) insertHyperlink() {
+	doc, r, ok := app.editorRange()
+	if !ok {
+		return
+	}
+	ui.ShowHyperlinkDialog(doc, r, app.window, func() {
+		app.afterFormat(nil, r.StartParagraph)
+	})
+}
+
+// showBookmarks 显示书签对话框
+func (app *App) showBookmarks() {
+	doc, r, ok := app.editorRange()
+	if !ok {
+		return
+	}
+	ui.ShowBookmarkDialog(doc, r, app.window, func(bookmark document.Bookmark) {
+		app.contentView.FollowLink("#" + bookmark.Name)
+	}, func() {
+		app.afterFormat(nil, r.StartParagraph)
+	})
+}
+
+// fieldOptions 返回计算域结果的选项，页码由排版引擎计算
+func (app *App) fieldOptions() document.FieldUpdateOptions {
+	return document.FieldUpdateOptions{Pages: app.layoutEngine}
+}
+
+// insertField 在编辑器的光标位置插入域，有选择的文字时插入到选择范围的开头
+func (app *App) insertField() {
+	doc, r, ok := app.editorRange()
+	if !ok {
+		return
+	}
+	pos := document.TextPosition{Paragraph: r.StartParagraph, Offset: r.StartOffset}
+	ui.ShowFieldDialog(doc, pos, app.fieldOptions(), app.window, func(document.TextPosition) {
+		app.afterFormat(nil, r.StartParagraph)
+	})
+}
+
+// updateFields 按文档属性和当前排版重新计算所有域，文档有目录时一并更新目录
+func (app *App) updateFields() {
+	doc := app.docManager.GetCurrentDocument()
+	if doc == nil {
+		dialog.ShowInformation("提示", "没有打开的文档", app.window)
+		return
+	}
+	if doc.Package == nil {
+		dialog.ShowInformation("提示", "文档不支持域", app.window)
+		return
+	}
+	doc.Checkpoint("更新域")
+	if doc.HasTOC() {
+		if err := doc.UpdateTOC(app.tocOptions(0)); err != nil {
+			dialog.ShowError(err, app.window)
+			return
+		}
+	}
+	count, err := doc.UpdateFields(app.fieldOptions())
+	if err != nil {
+		dialog.ShowError(err, app.window)
+		return
+	}
+	app.documentChanged()
+	if paragraph := app.contentView.CurrentParagraph(); paragraph >= 0 {
+		app.contentView.ShowParagraph(paragraph)
+	} else {
+		app.contentView.ShowNode("fields")
+	}
+	dialog.ShowInformation("更新域", fmt.Sprintf("已更新 %d 个域", count), app.window)
+}
@@ -0,0 +1,318 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+	"github.com/tanqiangyes/fyne-word/pkg/ui"
+)
+
+// 邮件合并的输出方式
+const (
+	mergeToDocument = "合并到一个新文档"
+	mergeToFiles    = "每条记录保存为一个文档"
+)
+
+// dataSourceExtensions 邮件合并数据源的扩展名
+var dataSourceExtensions = []string{".csv", ".txt", ".xlsx", ".xlsm"}
+
+// showMailMerge 显示邮件合并窗口：选择主文档和数据源，预览记录，合并为一个文档或每条记录一个文档
+func (app *App) showMailMerge() {
+	win := app.app.NewWindow("邮件合并")
+	win.Resize(fyne.NewSize(1000, 800))
+
+	var (
+		template   *document.Document
+		source     *document.DataSource
+		sourcePath string
+		names      []string
+		record     int
+		folder     string
+	)
+
+	templateLabel := widget.NewLabel("未选择主文档")
+	sourceLabel := widget.NewLabel("未选择数据源")
+	sheetSelect := widget.NewSelect(nil, nil)
+	sheetSelect.PlaceHolder = "工作表"
+	sheetSelect.Disable()
+	fieldsLabel := widget.NewLabel("")
+	fieldsLabel.Wrapping = fyne.TextWrapWord
+	recordLabel := widget.NewLabel("")
+	previewHolder := container.NewStack(widget.NewLabel("选择主文档和数据源后在这里预览合并结果"))
+	patternEntry := widget.NewEntry()
+	patternEntry.SetText("{{#}}.docx")
+	exampleLabel := widget.NewLabel("")
+
+	updateExample := func() {
+		if source == nil || source.Len() == 0 {
+			exampleLabel.SetText("")
+			return
+		}
+		exampleLabel.SetText("示例: " + document.MergeFileName(patternEntry.Text, source.Record(record), record+1))
+	}
+	patternEntry.OnChanged = func(string) { updateExample() }
+
+	showRecord := func(index int) {
+		if template == nil || source == nil || source.Len() == 0 {
+			return
+		}
+		if index < 0 {
+			index = 0
+		}
+		if index >= source.Len() {
+			index = source.Len() - 1
+		}
+		record = index
+		recordLabel.SetText(fmt.Sprintf("记录 %d / %d", record+1, source.Len()))
+		updateExample()
+		merged, err := template.MergeRecord(source, record)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		preview := ui.NewPagePreview(merged, app.layoutEngine)
+		if err := preview.Refresh(); err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		previewHolder.Objects = []fyne.CanvasObject{preview.Content()}
+		previewHolder.Refresh()
+	}
+
+	updateFields := func() {
+		if template == nil {
+			fieldsLabel.SetText("")
+			return
+		}
+		var err error
+		if names, err = template.MergeFieldNames(); err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		if len(names) == 0 {
+			fieldsLabel.SetText("主文档中没有合并域（MERGEFIELD）或 {{列名}} 占位符")
+			return
+		}
+		text := "合并域: " + strings.Join(names, ", ")
+		if source != nil {
+			if missing := source.MissingColumns(names); len(missing) > 0 {
+				text += "\n数据源中没有这些列，合并时留空: " + strings.Join(missing, ", ")
+			}
+		}
+		fieldsLabel.SetText(text)
+	}
+
+	setTemplate := func(doc *document.Document) {
+		template = doc
+		templateLabel.SetText(doc.FileName)
+		updateFields()
+		showRecord(record)
+	}
+	loadSource := func(path, sheet string) {
+		loaded, err := document.LoadDataSource(path, sheet)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		source, sourcePath, record = loaded, path, 0
+		sourceLabel.SetText(fmt.Sprintf("%s（%d 列，%d 条记录）", loaded.Name, len(loaded.Columns), loaded.Len()))
+		recordLabel.SetText("")
+		updateFields()
+		updateExample()
+		showRecord(0)
+	}
+	sheetSelect.OnChanged = func(sheet string) {
+		if sourcePath != "" && sheet != "" {
+			loadSource(sourcePath, sheet)
+		}
+	}
+
+	useCurrent := widget.NewButton("使用当前文档", func() {
+		doc := app.docManager.GetCurrentDocument()
+		if doc == nil || doc.Package == nil {
+			dialog.ShowInformation("提示", "没有可以作为主文档的当前文档", win)
+			return
+		}
+		setTemplate(doc)
+	})
+	chooseTemplate := widget.NewButton("选择主文档文件...", func() {
+		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if reader == nil {
+				return
+			}
+			reader.Close()
+			path := reader.URI().Path()
+			pkg, err := document.OpenTemplate(path)
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			setTemplate(&document.Document{FilePath: path, FileName: filepath.Base(path), Package: pkg, IsOpen: true})
+		}, win)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".docx", ".dotx"}))
+		fd.Show()
+	})
+	chooseSource := widget.NewButton("选择数据源...", func() {
+		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if reader == nil {
+				return
+			}
+			reader.Close()
+			path := reader.URI().Path()
+			sheetSelect.Options, sheetSelect.Selected = nil, ""
+			sheetSelect.Disable()
+			sheet := ""
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".xlsx", ".xlsm":
+				sheets, err := document.WorkbookSheets(path)
+				if err != nil {
+					dialog.ShowError(err, win)
+					return
+				}
+				sheet = sheets[0]
+				sheetSelect.Options, sheetSelect.Selected = sheets, sheet
+				sheetSelect.Enable()
+			}
+			sheetSelect.Refresh()
+			loadSource(path, sheet)
+		}, win)
+		fd.SetFilter(storage.NewExtensionFileFilter(dataSourceExtensions))
+		fd.Show()
+	})
+
+	navigation := container.NewHBox(
+		widget.NewButtonWithIcon("", theme.MediaSkipPreviousIcon(), func() { showRecord(0) }),
+		widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() { showRecord(record - 1) }),
+		recordLabel,
+		widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() { showRecord(record + 1) }),
+		widget.NewButtonWithIcon("", theme.MediaSkipNextIcon(), func() {
+			if source != nil {
+				showRecord(source.Len() - 1)
+			}
+		}),
+	)
+
+	rangeEntry := widget.NewEntry()
+	rangeEntry.SetPlaceHolder("全部记录，例如 1-10,15")
+	folderLabel := widget.NewLabel("未选择输出文件夹")
+	chooseFolder := widget.NewButton("选择输出文件夹...", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if uri == nil {
+				return
+			}
+			folder = uri.Path()
+			folderLabel.SetText(folder)
+		}, win)
+	})
+	fileOptions := container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel("文件名模式:"), exampleLabel, patternEntry),
+		container.NewBorder(nil, nil, chooseFolder, nil, folderLabel),
+	)
+	mode := widget.NewRadioGroup([]string{mergeToDocument, mergeToFiles}, func(selected string) {
+		if selected == mergeToFiles {
+			fileOptions.Show()
+		} else {
+			fileOptions.Hide()
+		}
+	})
+	mode.Horizontal = true
+	mode.SetSelected(mergeToDocument)
+
+	progress := widget.NewProgressBar()
+	var startButton *widget.Button
+	startButton = widget.NewButtonWithIcon("开始合并", theme.ConfirmIcon(), func() {
+		if template == nil || source == nil {
+			dialog.ShowInformation("提示", "请先选择主文档和数据源", win)
+			return
+		}
+		records, err := document.ParseRecordRange(rangeEntry.Text, source.Len())
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		toFiles := mode.Selected == mergeToFiles
+		if toFiles && folder == "" {
+			dialog.ShowInformation("提示", "请选择输出文件夹", win)
+			return
+		}
+
+		// 合并在后台进行，使用主文档的副本，合并期间编辑当前文档不受影响
+		snapshot := &document.Document{FileName: template.FileName, Package: template.Package.Clone()}
+		data, pattern := source, patternEntry.Text
+		opts := document.MailMergeOptions{
+			Records: records,
+			Progress: func(done, total int) {
+				fyne.Do(func() { progress.SetValue(float64(done) / float64(total)) })
+			},
+		}
+		progress.SetValue(0)
+		startButton.Disable()
+		go func() {
+			if toFiles {
+				paths, err := snapshot.MailMergeFiles(data, folder, pattern, opts)
+				fyne.Do(func() {
+					startButton.Enable()
+					if err != nil {
+						dialog.ShowError(err, win)
+						return
+					}
+					dialog.ShowInformation("邮件合并", fmt.Sprintf("已保存 %d 个文档到 %s", len(paths), folder), win)
+				})
+				return
+			}
+			pkg, err := snapshot.MailMergeDocument(data, opts)
+			fyne.Do(func() {
+				startButton.Enable()
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("邮件合并失败: %v", err), win)
+					return
+				}
+				app.docManager.NewDocumentFromPackage(pkg, document.MailMergeDocumentName(snapshot))
+				app.documentChanged()
+				app.contentView.ShowNode("title")
+				win.Close()
+				dialog.ShowInformation("邮件合并", fmt.Sprintf("已合并 %d 条记录到新文档", len(records)), app.window)
+			})
+		}()
+	})
+
+	sources := widget.NewForm(
+		widget.NewFormItem("主文档", container.NewBorder(nil, nil, nil, container.NewHBox(useCurrent, chooseTemplate), templateLabel)),
+		widget.NewFormItem("数据源", container.NewBorder(nil, nil, nil, container.NewHBox(sheetSelect, chooseSource), sourceLabel)),
+	)
+	output := container.NewVBox(
+		widget.NewSeparator(),
+		mode,
+		fileOptions,
+		container.NewBorder(nil, nil, widget.NewLabel("记录范围:"), nil, rangeEntry),
+		container.NewBorder(nil, nil, nil, startButton, progress),
+	)
+	top := container.NewVBox(sources, fieldsLabel, navigation)
+	win.SetContent(container.NewBorder(top, output, nil, nil, previewHolder))
+
+	if doc := app.docManager.GetCurrentDocument(); doc != nil && doc.Package != nil {
+		setTemplate(doc)
+	}
+	win.Show()
+}
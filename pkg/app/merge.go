@@ -0,0 +1,145 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// mergeSectionBreaks 合并文档时可选的分节符类型
+var mergeSectionBreaks = []document.SectionBreak{
+	document.SectionNextPage, document.SectionContinuous, document.SectionEvenPage, document.SectionOddPage,
+}
+
+// showMergeDocuments 显示合并文档窗口：按顺序选择多个文档，合并为一个新文档
+func (app *App) showMergeDocuments() {
+	win := app.app.NewWindow("合并文档")
+	win.Resize(fyne.NewSize(700, 500))
+
+	var paths []string
+	selected := -1
+	list := widget.NewList(
+		func() int { return len(paths) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(fmt.Sprintf("%d. %s", id+1, paths[id]))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+	list.OnUnselected = func(widget.ListItemID) { selected = -1 }
+	addPath := func(path string) {
+		for _, p := range paths {
+			if p == path {
+				dialog.ShowInformation("提示", "列表中已有这个文档: "+filepath.Base(path), win)
+				return
+			}
+		}
+		paths = append(paths, path)
+		list.Refresh()
+	}
+	move := func(delta int) {
+		target := selected + delta
+		if selected < 0 || target < 0 || target >= len(paths) {
+			return
+		}
+		paths[selected], paths[target] = paths[target], paths[selected]
+		list.Refresh()
+		list.Select(target)
+	}
+
+	addButton := widget.NewButtonWithIcon("添加文档...", theme.ContentAddIcon(), func() {
+		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if reader == nil {
+				return
+			}
+			reader.Close()
+			addPath(reader.URI().Path())
+		}, win)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".docx"}))
+		fd.Show()
+	})
+	addCurrent := widget.NewButton("添加当前文档", func() {
+		doc := app.docManager.GetCurrentDocument()
+		if doc == nil || doc.FilePath == "" || strings.ToLower(filepath.Ext(doc.FilePath)) != ".docx" {
+			dialog.ShowInformation("提示", "当前文档还没有保存为 .docx 文件", win)
+			return
+		}
+		if doc.IsModified {
+			dialog.ShowInformation("提示", "当前文档有未保存的修改，合并时使用已保存的内容", win)
+		}
+		addPath(doc.FilePath)
+	})
+	removeButton := widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), func() {
+		if selected < 0 || selected >= len(paths) {
+			return
+		}
+		paths = append(paths[:selected], paths[selected+1:]...)
+		list.UnselectAll()
+		list.Refresh()
+	})
+	upButton := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() { move(-1) })
+	downButton := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() { move(1) })
+
+	breakNames := make([]string, len(mergeSectionBreaks))
+	for i, b := range mergeSectionBreaks {
+		breakNames[i] = b.String()
+	}
+	breakSelect := widget.NewSelect(breakNames, nil)
+	breakSelect.SetSelected(breakNames[0])
+
+	progress := widget.NewProgressBar()
+	var mergeButton *widget.Button
+	mergeButton = widget.NewButtonWithIcon("合并", theme.ConfirmIcon(), func() {
+		if len(paths) < 2 {
+			dialog.ShowInformation("提示", "请至少添加两个文档", win)
+			return
+		}
+		opts := document.MergeDocumentsOptions{
+			SectionBreak: mergeSectionBreaks[breakSelect.SelectedIndex()],
+			Progress: func(done, total int) {
+				fyne.Do(func() { progress.SetValue(float64(done) / float64(total)) })
+			},
+		}
+		files := append([]string(nil), paths...)
+		progress.SetValue(0)
+		mergeButton.Disable()
+		go func() {
+			pkg, err := document.MergeDocuments(files, opts)
+			fyne.Do(func() {
+				mergeButton.Enable()
+				if err != nil {
+					dialog.ShowError(err, win)
+					return
+				}
+				app.docManager.NewDocumentFromPackage(pkg, document.MergedDocumentName(files))
+				app.documentChanged()
+				app.contentView.ShowNode("title")
+				win.Close()
+				dialog.ShowInformation("合并文档", fmt.Sprintf("已将 %d 个文档合并为新文档", len(files)), app.window)
+			})
+		}()
+	})
+
+	tools := container.NewHBox(addButton, addCurrent, removeButton, upButton, downButton)
+	bottom := container.NewVBox(
+		widget.NewSeparator(),
+		container.NewBorder(nil, nil, widget.NewLabel("各文档之间的分节符:"), nil, breakSelect),
+		container.NewBorder(nil, nil, nil, mergeButton, progress),
+	)
+	hint := widget.NewLabel("按列表顺序合并，第一个文档的页面设置和文档属性用于合并结果")
+	win.SetContent(container.NewBorder(container.NewVBox(tools, hint), bottom, nil, nil, list))
+	win.Show()
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/ui"
+)
+
+// showPagePreview 在新窗口中按页显示当前文档，并定位到当前段落所在的页
+func (app *App) showPagePreview() {
+	doc := app.docManager.GetCurrentDocument()
+	if doc == nil {
+		dialog.ShowInformation("提示", "没有打开的文档", app.window)
+		return
+	}
+	if doc.Package == nil {
+		dialog.ShowInformation("提示", "文档不支持页面视图", app.window)
+		return
+	}
+	preview := ui.NewPagePreview(doc, app.layoutEngine)
+	if err := preview.Refresh(); err != nil {
+		dialog.ShowError(err, app.window)
+		return
+	}
+
+	win := app.app.NewWindow(fmt.Sprintf("页面视图 - %s", doc.FileName))
+	win.Resize(fyne.NewSize(900, 800))
+	refreshBtn := widget.NewButtonWithIcon("刷新", theme.ViewRefreshIcon(), func() {
+		if err := preview.Refresh(); err != nil {
+			dialog.ShowError(err, win)
+		}
+	})
+	win.SetContent(preview.Content(refreshBtn))
+	win.Show()
+
+	if paragraph := app.contentView.CurrentParagraph(); paragraph >= 0 {
+		preview.ShowPage(preview.Result().ParagraphPage(paragraph))
+	}
+}
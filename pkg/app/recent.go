@@ -0,0 +1,337 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// 最近使用的文件和上次会话的偏好设置键
+const (
+	recentFilesKey      = "recent.files"
+	recentPinnedKey     = "recent.pinned"
+	sessionRestoreKey   = "session.restore"
+	sessionDocumentsKey = "session.documents"
+	sessionActiveKey    = "session.active"
+	sessionNodeKey      = "session.node"
+)
+
+// maxRecentFiles 最多记住的未固定的最近使用文件数，固定的文件不计入
+const maxRecentFiles = 10
+
+// recentFiles 返回最近使用的文件，固定的文件在前，其余按最后使用的时间排列
+func (app *App) recentFiles() []string {
+	prefs := app.app.Preferences()
+	pinned := prefs.StringList(recentPinnedKey)
+	files := append([]string(nil), pinned...)
+	for _, path := range prefs.StringList(recentFilesKey) {
+		if !containsPath(pinned, path) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// isRecentPinned 判断文件是否固定在最近使用的文件列表中
+func (app *App) isRecentPinned(path string) bool {
+	return containsPath(app.app.Preferences().StringList(recentPinnedKey), path)
+}
+
+// addRecentFile 将打开或保存的文件记录为最近使用的文件
+func (app *App) addRecentFile(path string) {
+	if path == "" {
+		return
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	prefs := app.app.Preferences()
+	if !containsPath(prefs.StringList(recentPinnedKey), path) {
+		files := append([]string{path}, removePath(prefs.StringList(recentFilesKey), path)...)
+		if len(files) > maxRecentFiles {
+			files = files[:maxRecentFiles]
+		}
+		prefs.SetStringList(recentFilesKey, files)
+	}
+	app.refreshRecentMenu()
+}
+
+// removeRecentFile 从最近使用的文件中移除文件，包括固定的文件
+func (app *App) removeRecentFile(path string) {
+	prefs := app.app.Preferences()
+	prefs.SetStringList(recentFilesKey, removePath(prefs.StringList(recentFilesKey), path))
+	prefs.SetStringList(recentPinnedKey, removePath(prefs.StringList(recentPinnedKey), path))
+	app.refreshRecentMenu()
+}
+
+// setRecentPinned 固定或取消固定最近使用的文件，固定的文件不会因为打开了其他文件而被挤出列表
+func (app *App) setRecentPinned(path string, pinned bool) {
+	prefs := app.app.Preferences()
+	recent := removePath(prefs.StringList(recentFilesKey), path)
+	pinnedFiles := removePath(prefs.StringList(recentPinnedKey), path)
+	if pinned {
+		pinnedFiles = append(pinnedFiles, path)
+	} else {
+		recent = append([]string{path}, recent...)
+		if len(recent) > maxRecentFiles {
+			recent = recent[:maxRecentFiles]
+		}
+	}
+	prefs.SetStringList(recentFilesKey, recent)
+	prefs.SetStringList(recentPinnedKey, pinnedFiles)
+	app.refreshRecentMenu()
+}
+
+// removeMissingRecentFiles 移除已经不存在的最近使用文件，返回移除的数量
+func (app *App) removeMissingRecentFiles() int {
+	removed := 0
+	for _, path := range app.recentFiles() {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			app.removeRecentFile(path)
+			removed++
+		}
+	}
+	return removed
+}
+
+// clearRecentFiles 清除未固定的最近使用文件
+func (app *App) clearRecentFiles() {
+	app.app.Preferences().SetStringList(recentFilesKey, nil)
+	app.refreshRecentMenu()
+}
+
+// openRecentFile 打开最近使用的文件，文件不存在时询问是否从列表中移除
+func (app *App) openRecentFile(path string) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		dialog.ShowConfirm("文件不存在",
+			fmt.Sprintf("找不到文件:\n%s\n\n是否从最近使用的文件中移除？", path),
+			func(remove bool) {
+				if remove {
+					app.removeRecentFile(path)
+				}
+			}, app.window)
+		return
+	}
+	app.openDocumentPath(path)
+}
+
+// recentFileLabel 返回最近使用的文件在菜单和列表中显示的名称
+func recentFileLabel(path string, pinned bool) string {
+	label := fmt.Sprintf("%s  (%s)", filepath.Base(path), filepath.Dir(path))
+	if pinned {
+		label = "★ " + label
+	}
+	return label
+}
+
+// createRecentMenu 创建文件菜单中的最近使用的文件子菜单
+func (app *App) createRecentMenu() *fyne.MenuItem {
+	app.recentMenu = fyne.NewMenuItem("最近使用的文件", nil)
+	app.recentMenu.ChildMenu = fyne.NewMenu("")
+	app.refreshRecentMenu()
+	return app.recentMenu
+}
+
+// refreshRecentMenu 按偏好设置中的列表重建最近使用的文件子菜单
+func (app *App) refreshRecentMenu() {
+	if app.recentMenu == nil {
+		return
+	}
+	var items []*fyne.MenuItem
+	for _, path := range app.recentFiles() {
+		path := path
+		items = append(items, fyne.NewMenuItem(recentFileLabel(path, app.isRecentPinned(path)), func() {
+			app.openRecentFile(path)
+		}))
+	}
+	if len(items) == 0 {
+		empty := fyne.NewMenuItem("（无）", nil)
+		empty.Disabled = true
+		items = append(items, empty)
+	}
+
+	restore := fyne.NewMenuItem("启动时恢复上次打开的文档", func() {
+		prefs := app.app.Preferences()
+		prefs.SetBool(sessionRestoreKey, !prefs.Bool(sessionRestoreKey))
+		app.refreshRecentMenu()
+	})
+	restore.Checked = app.app.Preferences().Bool(sessionRestoreKey)
+
+	items = append(items,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("管理最近使用的文件...", app.showRecentFiles),
+		fyne.NewMenuItem("移除不存在的文件", func() {
+			app.showStatus("已移除 %d 个不存在的文件", app.removeMissingRecentFiles())
+		}),
+		fyne.NewMenuItem("清除未固定的文件", app.clearRecentFiles),
+		fyne.NewMenuItemSeparator(),
+		restore,
+	)
+	app.recentMenu.ChildMenu.Items = items
+	if app.mainMenu != nil {
+		app.mainMenu.Refresh()
+	}
+}
+
+// showRecentFiles 显示管理最近使用的文件的窗口：打开、固定、移除文件
+func (app *App) showRecentFiles() {
+	win := app.app.NewWindow("最近使用的文件")
+	win.Resize(fyne.NewSize(700, 420))
+
+	files := app.recentFiles()
+	selected := -1
+	list := widget.NewList(
+		func() int { return len(files) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(recentFileLabel(files[id], app.isRecentPinned(files[id])))
+		},
+	)
+	reload := func() {
+		files = app.recentFiles()
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+	}
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+	list.OnUnselected = func(widget.ListItemID) { selected = -1 }
+	current := func() (string, bool) {
+		if selected < 0 || selected >= len(files) {
+			dialog.ShowInformation("提示", "请先选择一个文件", win)
+			return "", false
+		}
+		return files[selected], true
+	}
+
+	openButton := widget.NewButtonWithIcon("打开", theme.FolderOpenIcon(), func() {
+		if path, ok := current(); ok {
+			win.Close()
+			app.openRecentFile(path)
+		}
+	})
+	pinButton := widget.NewButton("固定/取消固定", func() {
+		if path, ok := current(); ok {
+			app.setRecentPinned(path, !app.isRecentPinned(path))
+			reload()
+		}
+	})
+	removeButton := widget.NewButtonWithIcon("移除", theme.ContentRemoveIcon(), func() {
+		if path, ok := current(); ok {
+			app.removeRecentFile(path)
+			reload()
+		}
+	})
+	missingButton := widget.NewButton("移除不存在的文件", func() {
+		removed := app.removeMissingRecentFiles()
+		reload()
+		dialog.ShowInformation("最近使用的文件", fmt.Sprintf("已移除 %d 个不存在的文件", removed), win)
+	})
+
+	tools := container.NewHBox(openButton, pinButton, removeButton, missingButton)
+	win.SetContent(container.NewBorder(widget.NewLabel("固定的文件（★）始终显示在列表前面:"), tools, nil, nil, list))
+	win.Show()
+}
+
+// setupSession 启动时按设置恢复上次打开的文档，退出时记录当前打开的文档
+func (app *App) setupSession() {
+	lifecycle := app.app.Lifecycle()
+	lifecycle.SetOnStarted(app.restoreSession)
+	lifecycle.SetOnStopped(app.saveSession)
+}
+
+// saveSession 记录已保存过的打开文档、当前文档和树形视图中选中的节点
+func (app *App) saveSession() {
+	var paths []string
+	for _, doc := range app.docManager.GetOpenDocuments() {
+		if doc.FilePath != "" {
+			paths = append(paths, doc.FilePath)
+		}
+	}
+	sort.Strings(paths)
+
+	active, node := "", ""
+	if doc := app.docManager.GetCurrentDocument(); doc != nil && doc.FilePath != "" {
+		active = doc.FilePath
+		node = app.treeView.Selected()
+	}
+
+	prefs := app.app.Preferences()
+	prefs.SetStringList(sessionDocumentsKey, paths)
+	prefs.SetString(sessionActiveKey, active)
+	prefs.SetString(sessionNodeKey, node)
+}
+
+// restoreSession 开启了恢复上次会话时重新打开上次的文档，并切换到上次的当前文档和节点
+func (app *App) restoreSession() {
+	prefs := app.app.Preferences()
+	if !prefs.Bool(sessionRestoreKey) {
+		return
+	}
+	active := prefs.String(sessionActiveKey)
+	opened, missing := 0, 0
+	activeOpened := false
+	for _, path := range prefs.StringList(sessionDocumentsKey) {
+		if _, err := os.Stat(path); err != nil {
+			log.Printf("跳过上次打开的文档 %s: %v", path, err)
+			missing++
+			continue
+		}
+		if _, err := app.docManager.OpenDocument(path); err != nil {
+			log.Printf("恢复上次打开的文档失败 %s: %v", path, err)
+			missing++
+			continue
+		}
+		opened++
+		activeOpened = activeOpened || path == active
+	}
+	if opened == 0 {
+		if missing > 0 {
+			app.showStatus("上次打开的 %d 个文档都无法恢复", missing)
+		}
+		return
+	}
+	if activeOpened {
+		// 打开已经打开的文档只会把它设为当前文档
+		app.docManager.OpenDocument(active)
+	}
+
+	app.documentChanged()
+	app.contentView.ShowNode("title")
+	if node := prefs.String(sessionNodeKey); activeOpened && node != "" {
+		app.treeView.Select(node)
+	}
+	if missing > 0 {
+		app.showStatus("已恢复上次的 %d 个文档，%d 个文档无法打开", opened, missing)
+	} else {
+		app.showStatus("已恢复上次的 %d 个文档", opened)
+	}
+}
+
+// containsPath 判断列表中是否有这个路径
+func containsPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// removePath 返回去掉指定路径后的列表
+func removePath(paths []string, path string) []string {
+	var result []string
+	for _, p := range paths {
+		if p != path {
+			result = append(result, p)
+		}
+	}
+	return result
+}
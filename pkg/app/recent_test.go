@@ -0,0 +1,141 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+)
+
+// newRecentTestApp 创建只有偏好设置和最近使用的文件菜单的应用程序
+func newRecentTestApp(t *testing.T) *App {
+	t.Helper()
+	app := &App{app: test.NewApp()}
+	t.Cleanup(app.app.Quit)
+	app.createRecentMenu()
+	return app
+}
+
+// recentMenuLabels 返回最近使用的文件子菜单中文件的名称
+func recentMenuLabels(app *App) []string {
+	var labels []string
+	for _, item := range app.recentMenu.ChildMenu.Items {
+		if item.IsSeparator {
+			break
+		}
+		labels = append(labels, item.Label)
+	}
+	return labels
+}
+
+func TestAddRecentFile(t *testing.T) {
+	app := newRecentTestApp(t)
+	if got := recentMenuLabels(app); !reflect.DeepEqual(got, []string{"（无）"}) {
+		t.Errorf("没有最近使用的文件时的菜单 %q", got)
+	}
+
+	dir := t.TempDir()
+	for i := 0; i <= maxRecentFiles; i++ {
+		app.addRecentFile(filepath.Join(dir, fmt.Sprintf("%d.docx", i)))
+	}
+	// 再次打开的文件移到最前面，超出的文件被挤出列表
+	app.addRecentFile(filepath.Join(dir, "5.docx"))
+	app.addRecentFile("")
+
+	files := app.recentFiles()
+	if len(files) != maxRecentFiles {
+		t.Fatalf("最近使用的文件有 %d 个, 期望 %d 个", len(files), maxRecentFiles)
+	}
+	if files[0] != filepath.Join(dir, "5.docx") || files[1] != filepath.Join(dir, "10.docx") {
+		t.Errorf("最近使用的文件的顺序 %q", files[:2])
+	}
+	if containsPath(files, filepath.Join(dir, "0.docx")) {
+		t.Errorf("超出数量的文件没有被移除: %q", files)
+	}
+	if got := recentMenuLabels(app); len(got) != maxRecentFiles || got[0] != recentFileLabel(files[0], false) {
+		t.Errorf("最近使用的文件菜单 %q", got)
+	}
+}
+
+func TestAddRecentFileAbsolute(t *testing.T) {
+	app := newRecentTestApp(t)
+	app.addRecentFile("相对路径.docx")
+	want, _ := filepath.Abs("相对路径.docx")
+	if got := app.recentFiles(); !reflect.DeepEqual(got, []string{want}) {
+		t.Errorf("最近使用的文件 %q, 期望 %q", got, want)
+	}
+}
+
+func TestRecentPinned(t *testing.T) {
+	app := newRecentTestApp(t)
+	dir := t.TempDir()
+	a, b, c := filepath.Join(dir, "a.docx"), filepath.Join(dir, "b.docx"), filepath.Join(dir, "c.docx")
+	for _, path := range []string{a, b, c} {
+		app.addRecentFile(path)
+	}
+
+	// 固定的文件排在前面，再次打开时不进入未固定的列表
+	app.setRecentPinned(a, true)
+	app.addRecentFile(a)
+	if got := app.recentFiles(); !reflect.DeepEqual(got, []string{a, c, b}) {
+		t.Errorf("固定后的最近使用的文件 %q", got)
+	}
+	if !app.isRecentPinned(a) || app.isRecentPinned(b) {
+		t.Errorf("固定状态错误")
+	}
+	if got := recentMenuLabels(app)[0]; got != recentFileLabel(a, true) {
+		t.Errorf("固定的文件在菜单中的名称 %q", got)
+	}
+
+	// 清除时保留固定的文件
+	app.clearRecentFiles()
+	if got := app.recentFiles(); !reflect.DeepEqual(got, []string{a}) {
+		t.Errorf("清除后的最近使用的文件 %q", got)
+	}
+
+	// 取消固定后回到未固定列表的最前面
+	app.addRecentFile(b)
+	app.setRecentPinned(a, false)
+	if got := app.recentFiles(); !reflect.DeepEqual(got, []string{a, b}) {
+		t.Errorf("取消固定后的最近使用的文件 %q", got)
+	}
+
+	app.setRecentPinned(b, true)
+	app.removeRecentFile(b)
+	if got := app.recentFiles(); !reflect.DeepEqual(got, []string{a}) {
+		t.Errorf("移除固定的文件后的最近使用的文件 %q", got)
+	}
+}
+
+func TestRemoveMissingRecentFiles(t *testing.T) {
+	app := newRecentTestApp(t)
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "存在.docx")
+	if err := os.WriteFile(existing, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "不存在.docx")
+	app.addRecentFile(existing)
+	app.addRecentFile(missing)
+	app.setRecentPinned(filepath.Join(dir, "固定.docx"), true)
+
+	if removed := app.removeMissingRecentFiles(); removed != 2 {
+		t.Errorf("移除了 %d 个文件, 期望 2 个", removed)
+	}
+	if got := app.recentFiles(); !reflect.DeepEqual(got, []string{existing}) {
+		t.Errorf("移除后的最近使用的文件 %q", got)
+	}
+}
+
+func TestRecentFileLabel(t *testing.T) {
+	path := filepath.Join("文档", "报告.docx")
+	if got, want := recentFileLabel(path, false), "报告.docx  (文档)"; got != want {
+		t.Errorf("recentFileLabel = %q, 期望 %q", got, want)
+	}
+	if got, want := recentFileLabel(path, true), "★ 报告.docx  (文档)"; got != want {
+		t.Errorf("recentFileLabel = %q, 期望 %q", got, want)
+	}
+}
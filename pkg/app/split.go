@@ -0,0 +1,148 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// splitModes 拆分文档窗口中可选的拆分位置
+var splitModes = []document.SplitMode{document.SplitByHeading, document.SplitByPageBreak, document.SplitBySection}
+
+// showSplitDocument 显示拆分文档窗口：在标题、分页符或分节符处将当前文档拆分为多个文档
+func (app *App) showSplitDocument() {
+	doc := app.docManager.GetCurrentDocument()
+	if doc == nil || doc.Package == nil {
+		dialog.ShowInformation("提示", "没有可以拆分的当前文档", app.window)
+		return
+	}
+	// 拆分使用文档的副本，拆分期间编辑当前文档不受影响
+	snapshot := &document.Document{FileName: doc.FileName, Package: doc.Package.Clone()}
+
+	win := app.app.NewWindow("拆分文档")
+	win.Resize(fyne.NewSize(600, 500))
+
+	var titles []string
+	folder := ""
+	if doc.FilePath != "" {
+		folder = filepath.Join(filepath.Dir(doc.FilePath), strings.TrimSuffix(doc.FileName, filepath.Ext(doc.FileName))+"-拆分")
+	}
+
+	modeNames := make([]string, len(splitModes))
+	for i, m := range splitModes {
+		modeNames[i] = "在每个" + m.String() + "处拆分"
+	}
+	modeSelect := widget.NewSelect(modeNames, nil)
+	levels := make([]string, 9)
+	for i := range levels {
+		levels[i] = strconv.Itoa(i + 1)
+	}
+	levelSelect := widget.NewSelect(levels, nil)
+	levelSelect.SetSelected("1")
+	levelRow := container.NewHBox(widget.NewLabel("标题级别（在这一级及以上的标题处拆分）:"), levelSelect)
+
+	statusLabel := widget.NewLabel("")
+	partList := widget.NewList(
+		func() int { return len(titles) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(document.SplitFileName(id+1, len(titles), titles[id]))
+		},
+	)
+
+	options := func() document.SplitOptions {
+		level, _ := strconv.Atoi(levelSelect.Selected)
+		return document.SplitOptions{Mode: splitModes[modeSelect.SelectedIndex()], Level: level}
+	}
+	// 预览拆分得到的文件名
+	updatePreview := func() {
+		if modeSelect.SelectedIndex() < 0 {
+			return
+		}
+		titles = nil
+		parts, err := snapshot.Split(options())
+		if err != nil {
+			statusLabel.SetText(err.Error())
+		} else {
+			for _, part := range parts {
+				titles = append(titles, part.Title)
+			}
+			statusLabel.SetText(fmt.Sprintf("将拆分为 %d 个文档:", len(parts)))
+		}
+		partList.Refresh()
+	}
+	modeSelect.OnChanged = func(string) {
+		if splitModes[modeSelect.SelectedIndex()] == document.SplitByHeading {
+			levelRow.Show()
+		} else {
+			levelRow.Hide()
+		}
+		updatePreview()
+	}
+	levelSelect.OnChanged = func(string) { updatePreview() }
+
+	folderLabel := widget.NewLabel("未选择输出文件夹")
+	if folder != "" {
+		folderLabel.SetText(folder)
+	}
+	chooseFolder := widget.NewButton("选择输出文件夹...", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if uri == nil {
+				return
+			}
+			folder = uri.Path()
+			folderLabel.SetText(folder)
+		}, win)
+	})
+
+	progress := widget.NewProgressBar()
+	var splitButton *widget.Button
+	splitButton = widget.NewButtonWithIcon("拆分", theme.ConfirmIcon(), func() {
+		if folder == "" {
+			dialog.ShowInformation("提示", "请选择输出文件夹", win)
+			return
+		}
+		opts := options()
+		opts.Progress = func(done, total int) {
+			fyne.Do(func() { progress.SetValue(float64(done) / float64(total)) })
+		}
+		dir := folder
+		progress.SetValue(0)
+		splitButton.Disable()
+		go func() {
+			paths, err := snapshot.SplitFiles(dir, opts)
+			fyne.Do(func() {
+				splitButton.Enable()
+				if err != nil {
+					dialog.ShowError(err, win)
+					return
+				}
+				win.Close()
+				dialog.ShowInformation("拆分文档", fmt.Sprintf("已拆分为 %d 个文档并保存到 %s", len(paths), dir), app.window)
+			})
+		}()
+	})
+
+	top := container.NewVBox(modeSelect, levelRow, statusLabel)
+	bottom := container.NewVBox(
+		widget.NewSeparator(),
+		container.NewBorder(nil, nil, chooseFolder, nil, folderLabel),
+		container.NewBorder(nil, nil, nil, splitButton, progress),
+	)
+	win.SetContent(container.NewBorder(top, bottom, nil, nil, partList))
+	modeSelect.SetSelected(modeNames[0])
+	win.Show()
+}
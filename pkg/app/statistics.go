@@ -0,0 +1,51 @@
+package app
+
+import (
+	"strconv"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// showStatistics 显示当前文档的字数统计，包括排版后的页数和行数
+func (app *App) showStatistics() {
+	doc := app.docManager.GetCurrentDocument()
+	if doc == nil || doc.Package == nil {
+		dialog.ShowInformation("字数统计", "没有可以统计的文档", app.window)
+		return
+	}
+
+	values := make(map[string]*widget.Label)
+	rows := []string{"页数", "行数", "字数", "中文字符和朝鲜语单词", "非中文单词", "字符数（不计空格）", "字符数（计空格）", "段落数", "表格数", "图片数"}
+	grid := container.NewGridWithColumns(2)
+	for _, name := range rows {
+		values[name] = widget.NewLabel("")
+		grid.Add(widget.NewLabel(name))
+		grid.Add(values[name])
+	}
+	includeNotes := widget.NewCheck("包括脚注和尾注", nil)
+
+	update := func() {
+		stats, err := doc.Statistics(document.StatisticsOptions{Pages: app.layoutEngine, IncludeNotes: includeNotes.Checked})
+		if err != nil {
+			dialog.ShowError(err, app.window)
+			return
+		}
+		for name, value := range map[string]int{
+			"页数": stats.Pages, "行数": stats.Lines, "字数": stats.Words,
+			"中文字符和朝鲜语单词": stats.EastAsianCharacters, "非中文单词": stats.LatinWords,
+			"字符数（不计空格）": stats.Characters, "字符数（计空格）": stats.CharactersWithSpaces,
+			"段落数": stats.Paragraphs, "表格数": stats.Tables, "图片数": stats.Images,
+		} {
+			values[name].SetText(strconv.Itoa(value))
+		}
+	}
+	includeNotes.OnChanged = func(bool) { update() }
+	update()
+
+	content := container.NewVBox(widget.NewLabel("统计信息:"), grid, widget.NewSeparator(), includeNotes)
+	dialog.ShowCustom("字数统计", "关闭", content, app.window)
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+	"github.com/tanqiangyes/fyne-word/pkg/ui"
+)
+
+// statisticsDelay 文档修改后等待这么久再重新统计字数，连续输入时只统计一次
+const statisticsDelay = 300 * time.Millisecond
+
+// createStatusBar 创建主窗口底部的状态栏
+func (app *App) createStatusBar() fyne.CanvasObject {
+	app.statusBar = ui.NewStatusBar(app.showStatistics)
+	app.contentView.SetOnPositionChanged(app.updatePosition)
+	app.updateStatus()
+	return app.statusBar.GetWidget()
+}
+
+// documentChanged 文档内容或当前文档变化后刷新树形视图和状态栏，字数稍后更新
+func (app *App) documentChanged() {
+	app.treeView.Refresh()
+	app.updateDocumentState()
+	if app.statisticsTimer != nil {
+		app.statisticsTimer.Stop()
+	}
+	app.statisticsTimer = time.AfterFunc(statisticsDelay, func() {
+		fyne.Do(app.updateWordCount)
+	})
+}
+
+// updateStatus 更新状态栏中的所有信息
+func (app *App) updateStatus() {
+	app.updateDocumentState()
+	app.updateWordCount()
+	app.updatePosition(app.contentView.CurrentParagraph())
+}
+
+// updateDocumentState 在状态栏中显示当前文档的路径和修改状态
+func (app *App) updateDocumentState() {
+	if app.statusBar == nil {
+		return
+	}
+	doc := app.docManager.GetCurrentDocument()
+	switch {
+	case doc == nil:
+		app.statusBar.SetDocument("", false, false)
+	case doc.FilePath == "":
+		app.statusBar.SetDocument(doc.FileName+"（未保存）", doc.IsModified, false)
+	default:
+		app.statusBar.SetDocument(doc.FilePath, doc.IsModified, doc.ReadOnly)
+	}
+}
+
+// updateWordCount 统计当前文档的字数和字符数并显示在状态栏中
+func (app *App) updateWordCount() {
+	if app.statusBar == nil {
+		return
+	}
+	doc := app.docManager.GetCurrentDocument()
+	if doc == nil || doc.Package == nil {
+		app.statusBar.SetWordCount(-1, 0)
+		return
+	}
+	stats, err := doc.Statistics(document.StatisticsOptions{})
+	if err != nil {
+		app.statusBar.SetWordCount(-1, 0)
+		return
+	}
+	app.statusBar.SetWordCount(stats.Words, stats.CharactersWithSpaces)
+}
+
+// updatePosition 在状态栏中显示当前段落和它的样式
+func (app *App) updatePosition(paragraph int) {
+	if app.statusBar == nil {
+		return
+	}
+	doc := app.docManager.GetCurrentDocument()
+	if doc == nil || doc.Package == nil || paragraph < 0 {
+		app.statusBar.SetPosition(-1, "")
+		return
+	}
+	style, err := doc.ParagraphStyleName(paragraph)
+	if err != nil {
+		app.statusBar.SetPosition(-1, "")
+		return
+	}
+	app.statusBar.SetPosition(paragraph, style)
+	// 编辑会标记文档为已修改
+	app.updateDocumentState()
+}
+
+// showStatus 在状态栏中显示一条临时消息
+func (app *App) showStatus(format string, args ...interface{}) {
+	if app.statusBar != nil {
+		app.statusBar.ShowMessage(fmt.Sprintf(format, args...))
+	}
+}
+
+// saveDocumentTo 在后台保存文档，path 为空时保存到文档原来的路径，还没有路径时打开保存对话框
+//
+// 保存的是开始保存时文档内容的副本，保存期间可以继续编辑，进度和结果显示在状态栏中。
+func (app *App) saveDocumentTo(doc *document.Document, path string) {
+	task, err := app.docManager.BeginSave(doc, path)
+	if err != nil {
+		if document.IsSavePathNotSetError(err) {
+			app.showSaveDialog(doc)
+			return
+		}
+		dialog.ShowError(err, app.window)
+		return
+	}
+
+	name := filepath.Base(task.Path)
+	app.statusBar.StartProgress(fmt.Sprintf("正在保存 %s...", name))
+	app.updateDocumentState()
+	go func() {
+		err := task.Write()
+		fyne.Do(func() {
+			err = task.Finish(err)
+			app.updateDocumentState()
+			if err != nil {
+				app.statusBar.StopProgress("")
+				dialog.ShowError(err, app.window)
+				return
+			}
+			app.statusBar.StopProgress(fmt.Sprintf("已保存 %s", name))
+			app.addRecentFile(task.Path)
+			app.treeView.Refresh()
+		})
+	}()
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+	"github.com/tanqiangyes/fyne-word/pkg/ui"
+)
+
+// templateDirKey 模板库文件夹的偏好设置键
+const templateDirKey = "templates.directory"
+
+// templateDir 返回模板库文件夹，没有设置时使用默认目录
+func (app *App) templateDir() string {
+	if dir := app.app.Preferences().String(templateDirKey); dir != "" {
+		return dir
+	}
+	dir, err := document.DefaultTemplateDir()
+	if err != nil {
+		log.Printf("获取模板库目录失败: %v", err)
+		return ""
+	}
+	return dir
+}
+
+// newFromTemplate 显示模板库，选择模板后以它为起点新建文档并填写占位符
+func (app *App) newFromTemplate() {
+	win := app.app.NewWindow("从模板新建")
+	win.Resize(fyne.NewSize(560, 480))
+
+	folder := widget.NewLabel("")
+	folder.Wrapping = fyne.TextWrapBreak
+	var templates []document.TemplateInfo
+	selected := -1
+	list := widget.NewList(
+		func() int { return len(templates) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			name := templates[id].Name
+			if templates[id].Category != "" {
+				name = templates[id].Category + " / " + name
+			}
+			o.(*widget.Label).SetText(name)
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+	refresh := func() {
+		dir := app.templateDir()
+		var err error
+		templates, err = document.ListTemplates(dir)
+		if err != nil {
+			dialog.ShowError(err, win)
+		}
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+		if len(templates) == 0 {
+			folder.SetText(fmt.Sprintf("模板库: %s（没有 .dotx 或 .docx 模板）", dir))
+		} else {
+			folder.SetText(fmt.Sprintf("模板库: %s（%d 个模板）", dir, len(templates)))
+		}
+	}
+
+	create := func(path string) {
+		win.Close()
+		app.createFromTemplate(path)
+	}
+	createButton := widget.NewButton("新建", func() {
+		if selected < 0 || selected >= len(templates) {
+			dialog.ShowInformation("提示", "请先选择模板", win)
+			return
+		}
+		create(templates[selected].Path)
+	})
+	chooseFolder := widget.NewButton("选择模板文件夹...", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if uri == nil {
+				return
+			}
+			app.app.Preferences().SetString(templateDirKey, uri.Path())
+			refresh()
+		}, win)
+	})
+	browse := widget.NewButton("浏览模板文件...", func() {
+		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if reader == nil {
+				return
+			}
+			reader.Close()
+			create(reader.URI().Path())
+		}, win)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".dotx", ".docx"}))
+		fd.Show()
+	})
+
+	refresh()
+	buttons := container.NewHBox(chooseFolder, browse, widget.NewButton("刷新", refresh), createButton)
+	win.SetContent(container.NewBorder(folder, buttons, nil, nil, list))
+	win.Show()
+}
+
+// createFromTemplate 以模板新建文档，文档中有占位符时显示填写表单
+func (app *App) createFromTemplate(path string) {
+	doc, err := app.docManager.NewDocument(document.NewDocumentOptions{Template: path})
+	if err != nil {
+		dialog.ShowError(err, app.window)
+		return
+	}
+	app.documentChanged()
+	app.contentView.ShowNode("title")
+
+	if names, err := doc.Placeholders(); err == nil && len(names) > 0 {
+		app.fillPlaceholders()
+	}
+}
+
+// fillPlaceholders 为当前文档中的模板占位符填写值
+func (app *App) fillPlaceholders() {
+	doc := app.docManager.GetCurrentDocument()
+	if doc == nil {
+		dialog.ShowInformation("提示", "没有打开的文档", app.window)
+		return
+	}
+	ui.ShowPlaceholderDialog(doc, app.window, func(filled int, missing []string) {
+		app.documentChanged()
+		app.contentView.ShowNode("paragraphs")
+		message := fmt.Sprintf("已替换 %d 处占位符", filled)
+		if len(missing) > 0 {
+			message += fmt.Sprintf("\n未填写: %s", strings.Join(missing, ", "))
+		}
+		dialog.ShowInformation("填写占位符", message, app.window)
+	})
+}
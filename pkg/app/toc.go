@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// tocOptions 返回生成目录的选项，levels 为0时沿用原目录的级别，页码由排版引擎计算
+func (app *App) tocOptions(levels int) document.TOCOptions {
+	return document.TOCOptions{Levels: levels, Pages: app.layoutEngine}
+}
+
+// tocDocument 返回可以生成目录的当前文档，不支持时提示用户并返回nil
+func (app *App) tocDocument() *document.Document {
+	doc := app.docManager.GetCurrentDocument()
+	if doc == nil {
+		dialog.ShowInformation("提示", "没有打开的文档", app.window)
+		return nil
+	}
+	if doc.Package == nil {
+		dialog.ShowInformation("提示", "文档不支持目录", app.window)
+		return nil
+	}
+	return doc
+}
+
+// insertTOC 选择位置和标题级别后插入目录，文档已有目录时询问是否更新
+func (app *App) insertTOC() {
+	doc := app.tocDocument()
+	if doc == nil {
+		return
+	}
+	if doc.HasTOC() {
+		dialog.ShowConfirm("插入目录", "文档中已有目录，是否按当前标题更新目录？", func(ok bool) {
+			if ok {
+				app.updateTOC()
+			}
+		}, app.window)
+		return
+	}
+
+	adapter := document.NewDocumentAdapter(doc)
+	count := adapter.GetParagraphCount()
+	var positions []string
+	for i := 0; i < count; i++ {
+		positions = append(positions, fmt.Sprintf("段落 %d 之前: %s", i+1, truncate(adapter.GetParagraphText(i), 20)))
+	}
+	positions = append(positions, "文档末尾")
+	positionSelect := widget.NewSelect(positions, nil)
+	if current := app.contentView.CurrentParagraph(); current >= 0 && current < count {
+		positionSelect.SetSelectedIndex(current)
+	} else {
+		positionSelect.SetSelectedIndex(0)
+	}
+
+	var levels []string
+	for i := 1; i <= 9; i++ {
+		levels = append(levels, strconv.Itoa(i))
+	}
+	levelSelect := widget.NewSelect(levels, nil)
+	levelSelect.SetSelected("3")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("插入位置", positionSelect),
+		widget.NewFormItem("显示级别", levelSelect),
+	}
+	dialog.ShowForm("插入目录", "插入", "取消", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		level, _ := strconv.Atoi(levelSelect.Selected)
+		doc.Checkpoint("插入目录")
+		if err := doc.InsertTOC(positionSelect.SelectedIndex(), app.tocOptions(level)); err != nil {
+			dialog.ShowError(err, app.window)
+			return
+		}
+		app.showTOC()
+	}, app.window)
+}
+
+// updateTOC 按当前的标题更新文档中的目录
+func (app *App) updateTOC() {
+	doc := app.tocDocument()
+	if doc == nil {
+		return
+	}
+	doc.Checkpoint("更新目录")
+	if err := doc.UpdateTOC(app.tocOptions(0)); err != nil {
+		dialog.ShowError(err, app.window)
+		return
+	}
+	app.showTOC()
+}
+
+// showTOC 目录变化后刷新树形视图并显示段落列表
+func (app *App) showTOC() {
+	app.documentChanged()
+	app.contentView.ShowNode("paragraphs")
+}
+
+// truncate 按字符截断文本
+func truncate(text string, maxLen int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+	return string(runes[:maxLen]) + "..."
+}
@@ -2,11 +2,14 @@ package document
 
 import (
 	"fmt"
+	"strconv"
 )
 
 // DocumentAdapter 文档适配器，将go-word库的数据结构适配到我们的UI接口
 type DocumentAdapter struct {
-	goWordDoc *Document
+	goWordDoc   *Document
+	noteNumbers map[string]int // 脚注和尾注编号，首次使用时计算
+	listItems   []ListItem     // 段落的列表编号，首次使用时计算
 }
 
 // NewDocumentAdapter 创建文档适配器
@@ -37,6 +40,11 @@ func (da *DocumentAdapter) GetParagraphCount() int {
 		return 0
 	}
 	
+	// 基于OPC包的文档直接读取正文中的段落
+	if da.goWordDoc.Package != nil {
+		return len(da.goWordDoc.paragraphNodes())
+	}
+	
 	// 如果使用DocumentWriter，从DocumentWriter的document字段获取段落数量
 	if da.goWordDoc.DocWriter != nil {
 		// 通过DocumentWriter的document字段访问MainDocumentPart
@@ -137,6 +145,15 @@ func (da *DocumentAdapter) GetParagraphText(index int) string {
 		return ""
 	}
 	
+	// 基于OPC包的文档读取段落的最终文本
+	if da.goWordDoc.Package != nil {
+		paragraphs := da.goWordDoc.paragraphNodes()
+		if index >= 0 && index < len(paragraphs) {
+			return paragraphNodeText(paragraphs[index])
+		}
+		return fmt.Sprintf("无法获取段落%d", index+1)
+	}
+	
 	// 如果使用DocumentWriter，从DocumentWriter的document字段获取段落文本
 	if da.goWordDoc.DocWriter != nil {
 		if da.goWordDoc.DocWriter.Document != nil {
@@ -175,6 +192,81 @@ func (da *DocumentAdapter) GetParagraphText(index int) string {
 	return "文档未初始化"
 }
 
+// GetParagraphSpans 获取指定段落的格式片段，只有基于OPC包的文档支持
+func (da *DocumentAdapter) GetParagraphSpans(index int) ([]TextSpan, error) {
+	if da.goWordDoc == nil {
+		return nil, fmt.Errorf("文档未初始化")
+	}
+	return da.goWordDoc.ParagraphSpans(index)
+}
+
+// GetParagraphMarkedText 获取指定段落的文本，脚注和尾注引用显示为编号标记
+func (da *DocumentAdapter) GetParagraphMarkedText(index int) string {
+	if da.goWordDoc == nil || da.goWordDoc.Package == nil {
+		return da.GetParagraphText(index)
+	}
+	paragraphs := da.goWordDoc.paragraphNodes()
+	if index < 0 || index >= len(paragraphs) {
+		return fmt.Sprintf("无法获取段落%d", index+1)
+	}
+	if da.noteNumbers == nil {
+		da.noteNumbers = da.goWordDoc.noteNumbers()
+	}
+	
+	spans := paragraphSpans(paragraphs[index])
+	numberNoteSpans(spans, da.noteNumbers)
+	var text string
+	for _, span := range spans {
+		if !span.Deleted {
+			text += span.Text
+		}
+	}
+	return text
+}
+
+// GetParagraphListItem 获取指定段落的列表信息，段落不在列表中时返回false
+func (da *DocumentAdapter) GetParagraphListItem(index int) (ListItem, bool) {
+	if da.goWordDoc == nil || da.goWordDoc.Package == nil {
+		return ListItem{}, false
+	}
+	if da.listItems == nil {
+		items, err := da.goWordDoc.ListItems()
+		if err != nil {
+			return ListItem{}, false
+		}
+		da.listItems = items
+	}
+	if index < 0 || index >= len(da.listItems) || da.listItems[index].NumID == "" {
+		return ListItem{}, false
+	}
+	return da.listItems[index], true
+}
+
+// GetParagraphLabeledText 获取指定段落带列表编号的文本
+func (da *DocumentAdapter) GetParagraphLabeledText(index int) string {
+	text := da.GetParagraphMarkedText(index)
+	if item, ok := da.GetParagraphListItem(index); ok && item.Label != "" {
+		return item.Label + " " + text
+	}
+	return text
+}
+
+// GetNotesByParagraph 获取按段落分组的脚注和尾注
+func (da *DocumentAdapter) GetNotesByParagraph() map[int][]Note {
+	if da.goWordDoc == nil || da.goWordDoc.Package == nil {
+		return map[int][]Note{}
+	}
+	return da.goWordDoc.NotesByParagraph()
+}
+
+// GetCommentsByParagraph 获取按段落分组的批注
+func (da *DocumentAdapter) GetCommentsByParagraph() map[int][]Comment {
+	if da.goWordDoc == nil || da.goWordDoc.Package == nil {
+		return map[int][]Comment{}
+	}
+	return da.goWordDoc.CommentsByParagraph()
+}
+
 // GetTableInfo 获取指定表格的信息
 func (da *DocumentAdapter) GetTableInfo(index int) string {
 	if da.goWordDoc == nil || da.goWordDoc.WordDoc == nil {
@@ -226,30 +318,38 @@ func (da *DocumentAdapter) GetStyleInfo(index int) string {
 	return "示例样式信息" // 临时返回值
 }
 
-// GetMetadataInfo 获取文档元数据信息
+// GetMetadataInfo 获取文档元数据信息：文档属性和字数统计
 func (da *DocumentAdapter) GetMetadataInfo() map[string]string {
-	if da.goWordDoc == nil || da.goWordDoc.WordDoc == nil {
+	if da.goWordDoc == nil || da.goWordDoc.Package == nil {
 		return make(map[string]string)
 	}
 	
-	metadata, err := da.goWordDoc.GetMetadata()
-	if err != nil {
-		return map[string]string{
-			"错误": fmt.Sprintf("获取元数据时出错: %v", err),
+	properties := da.goWordDoc.DocumentProperties()
+	metadata := map[string]string{
+		"标题": da.GetTitle(),
+	}
+	for name, label := range map[string]string{
+		"Author":        "作者",
+		"LastSavedBy":   "最后保存者",
+		"CreateTime":    "创建时间",
+		"LastSavedTime": "修改时间",
+		"Pages":         "页数（上次保存时）",
+	} {
+		if value := properties[name]; value != "" {
+			metadata[label] = value
 		}
 	}
 	
-	// TODO: 根据go-word库的实际接口提取元数据
-	_ = metadata
-	
-	// 临时返回示例数据
-	return map[string]string{
-		"作者":   "示例作者",
-		"标题":   da.GetTitle(),
-		"页数":   "1",
-		"字数":   "100",
-		"字符数": "500",
-		"创建时间": "2025-08-20",
-		"修改时间": "2025-08-20",
+	stats, err := da.goWordDoc.Statistics(StatisticsOptions{})
+	if err != nil {
+		metadata["错误"] = fmt.Sprintf("统计字数时出错: %v", err)
+		return metadata
 	}
+	metadata["字数"] = strconv.Itoa(stats.Words)
+	metadata["字符数（不计空格）"] = strconv.Itoa(stats.Characters)
+	metadata["字符数（计空格）"] = strconv.Itoa(stats.CharactersWithSpaces)
+	metadata["段落数"] = strconv.Itoa(stats.Paragraphs)
+	metadata["表格数"] = strconv.Itoa(stats.Tables)
+	metadata["图片数"] = strconv.Itoa(stats.Images)
+	return metadata
 }
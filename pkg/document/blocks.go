@@ -0,0 +1,492 @@
+package document
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ObjectReplacement 块文字中表示嵌入式图片的占位字符
+const ObjectReplacement = '\uFFFC'
+
+// PageBreak 块文字中表示分页符的字符
+const PageBreak = '\f'
+
+// emuPerTwip 每缇的EMU数（DrawingML 的尺寸单位）
+const emuPerTwip = 635
+
+// BlockKind 正文块的类型
+type BlockKind int
+
+const (
+	ParagraphBlock BlockKind = iota // 段落
+	TableBlock                      // 表格
+)
+
+// InlineImage 段落中的嵌入式图片
+type InlineImage struct {
+	Part   string // 图片部件名称，找不到图片时为空
+	Name   string // 图片的说明或名称
+	Width  int    // 宽度，单位为缇
+	Height int    // 高度，单位为缇
+}
+
+// Block 正文中按顺序排列的段落或表格，格式已合并文档默认格式、样式和列表缩进，供排版使用
+type Block struct {
+	Kind         BlockKind
+	Paragraph    int             // 段落索引，与 GetParagraphs 的顺序一致，表格中的段落为-1
+	Section      int             // 所在节的索引
+	Style        string          // 段落样式ID
+	Format       ParagraphFormat // 最终的段落格式
+	WidowControl bool            // 孤行控制，段落的首行或末行不单独留在一页
+	ListLabel    string          // 列表编号文本
+	Mark         TextSpan        // 段落标记的字符格式，用于列表编号和空段落的行高
+	Spans        []TextSpan      // 最终文字，不含删除的内容；分页符为 PageBreak，图片为 ObjectReplacement
+	SpanOffsets  []int           // 各片段在段落最终文本中的起始位置，与 InsertText 等编辑操作的位置一致
+	Images       []InlineImage   // 按出现顺序排列的图片
+	Rows         []TableRow      // 表格的行
+	Borders      bool            // 表格有可见的边框
+}
+
+// TableRow 表格的一行
+type TableRow struct {
+	Cells  []TableCell
+	Height int  // 指定的行高，单位为缇，0表示自动
+	Exact  bool // 行高固定
+	Header bool // 标题行，表格跨页时在每页顶端重复
+}
+
+// TableCell 表格的单元格
+type TableCell struct {
+	Width   int     // 宽度，单位为缇，0表示未指定
+	Span    int     // 横向合并的网格列数
+	Merged  bool    // 被上方单元格纵向合并，内容为空
+	Shading string  // 底纹填充色 RRGGBB，空表示无
+	Blocks  []Block // 单元格中的段落和表格
+}
+
+// Text 返回块的纯文本，图片和分页符除外
+func (b Block) Text() string {
+	var builder strings.Builder
+	for _, span := range b.Spans {
+		for _, r := range span.Text {
+			if r != ObjectReplacement && r != PageBreak {
+				builder.WriteRune(r)
+			}
+		}
+	}
+	return builder.String()
+}
+
+// styleSheet 样式部件中的文档默认格式和样式定义，用于计算段落和文字的最终格式
+type styleSheet struct {
+	pPrDefault *Node
+	rPrDefault *Node
+	styles     map[string]*Node  // 样式ID到 w:style
+	defaults   map[string]string // 样式类型到默认样式ID
+}
+
+// styleSheet 读取文档的样式表，没有样式部件时返回空样式表
+func (doc *Document) styleSheet() *styleSheet {
+	sheet := &styleSheet{styles: make(map[string]*Node), defaults: make(map[string]string)}
+	if doc.Package == nil {
+		return sheet
+	}
+	part, ok := doc.Package.RelatedPart(doc.Package.MainPartName(), relTypeStyles)
+	if !ok {
+		return sheet
+	}
+	root, err := doc.Package.XML(part)
+	if err != nil {
+		return sheet
+	}
+	if defaults := root.Child("w:docDefaults"); defaults != nil {
+		if rPr := defaults.Child("w:rPrDefault"); rPr != nil {
+			sheet.rPrDefault = rPr.Child("w:rPr")
+		}
+		if pPr := defaults.Child("w:pPrDefault"); pPr != nil {
+			sheet.pPrDefault = pPr.Child("w:pPr")
+		}
+	}
+	for _, s := range root.ChildrenNamed("w:style") {
+		id := s.Attr("w:styleId")
+		sheet.styles[id] = s
+		if value := s.Attr("w:default"); value == "1" || value == "true" {
+			sheet.defaults[s.Attr("w:type")] = id
+		}
+	}
+	return sheet
+}
+
+// properties 返回样式沿 basedOn 继承后的属性，name 为 w:pPr 或 w:rPr
+func (s *styleSheet) properties(styleID, name string) *Node {
+	var chain []*Node
+	seen := make(map[string]bool)
+	for id := styleID; id != "" && !seen[id]; {
+		seen[id] = true
+		style, ok := s.styles[id]
+		if !ok {
+			break
+		}
+		chain = append(chain, style)
+		id = ""
+		if basedOn := style.Child("w:basedOn"); basedOn != nil {
+			id = basedOn.Attr("w:val")
+		}
+	}
+	merged := NewNode(name)
+	for i := len(chain) - 1; i >= 0; i-- {
+		mergeProperties(merged, chain[i].Child(name))
+	}
+	return merged
+}
+
+// mergeProperties 将 src 中的属性合并到 dst，同名元素逐个属性覆盖
+func mergeProperties(dst, src *Node) {
+	if src == nil {
+		return
+	}
+	for _, c := range src.Children {
+		if !c.IsElement() {
+			continue
+		}
+		existing := dst.Child(c.Name)
+		if existing == nil {
+			dst.AppendChild(c.Clone())
+			continue
+		}
+		// 首行缩进和悬挂缩进互斥，后设置的生效
+		if c.Name == "w:ind" {
+			for _, pair := range [][2]string{{"w:firstLine", "w:hanging"}, {"w:firstLineChars", "w:hangingChars"}} {
+				if c.Attr(pair[0]) != "" {
+					existing.RemoveAttr(pair[1])
+				}
+				if c.Attr(pair[1]) != "" {
+					existing.RemoveAttr(pair[0])
+				}
+			}
+		}
+		for _, attr := range c.Attrs {
+			existing.SetAttr(attr.Name, attr.Value)
+		}
+		mergeProperties(existing, c)
+	}
+}
+
+// blockBuilder 收集正文块时使用的样式、编号等共享信息
+type blockBuilder struct {
+	doc            *Document
+	sheet          *styleSheet
+	numbering      *numberingDefinitions
+	styleNumbering map[string]ListItem
+	labels         []string
+	notes          map[string]int
+	paragraphs     int    // 已收集的正文段落数
+	section        int    // 当前节
+	offset         int    // 当前段落中已收集的最终文本长度
+	link           string // 当前所在超链接的目标
+}
+
+// Blocks 返回正文中按顺序排列的段落和表格
+func (doc *Document) Blocks() ([]Block, error) {
+	body, err := doc.body()
+	if err != nil {
+		return nil, err
+	}
+	b := &blockBuilder{
+		doc:            doc,
+		sheet:          doc.styleSheet(),
+		numbering:      &numberingDefinitions{},
+		styleNumbering: doc.styleNumbering(),
+		labels:         doc.ListLabels(),
+		notes:          doc.noteNumbers(),
+	}
+	if root, err := doc.numberingRoot(false); err == nil && root != nil {
+		b.numbering = parseNumbering(root)
+	}
+	return b.collect(body, true), nil
+}
+
+// collect 收集容器中的块，top 表示容器中的段落属于正文段落序列
+func (b *blockBuilder) collect(container *Node, top bool) []Block {
+	var blocks []Block
+	for _, c := range container.Children {
+		switch c.Name {
+		case "w:p":
+			block := b.paragraph(c, top)
+			blocks = append(blocks, block)
+			if pPr := c.Child("w:pPr"); top && pPr != nil && pPr.Child("w:sectPr") != nil {
+				b.section++
+			}
+		case "w:tbl":
+			blocks = append(blocks, b.table(c))
+		case "w:sdt", "w:sdtContent", "w:customXml":
+			blocks = append(blocks, b.collect(c, top)...)
+		}
+	}
+	return blocks
+}
+
+// paragraph 将段落节点转换为块
+func (b *blockBuilder) paragraph(p *Node, top bool) Block {
+	block := Block{Kind: ParagraphBlock, Paragraph: -1, Section: b.section, Style: paragraphStyle(p)}
+	if top {
+		block.Paragraph = b.paragraphs
+		if block.Paragraph < len(b.labels) {
+			block.ListLabel = b.labels[block.Paragraph]
+		}
+		b.paragraphs++
+	}
+	styleID := block.Style
+	if styleID == "" {
+		styleID = b.sheet.defaults["paragraph"]
+	}
+
+	// 段落属性：文档默认 < 样式 < 列表级别 < 直接格式
+	pPr := NewNode("w:pPr")
+	mergeProperties(pPr, b.sheet.pPrDefault)
+	mergeProperties(pPr, b.sheet.properties(styleID, "w:pPr"))
+	if numID, ilvl := paragraphNumbering(p, b.styleNumbering); numID != "" && numID != "0" {
+		if level, ok := b.numbering.level(numID, ilvl); ok {
+			mergeProperties(pPr, level.pPr)
+		}
+	}
+	mergeProperties(pPr, p.Child("w:pPr"))
+	block.Format = directParagraphFormat(pPr)
+	block.WidowControl = true
+	if widow := pPr.Child("w:widowControl"); widow != nil {
+		block.WidowControl = onOffValue(widow)
+	}
+
+	// 文字属性：文档默认 < 段落样式
+	rPr := NewNode("w:rPr")
+	mergeProperties(rPr, b.sheet.rPrDefault)
+	mergeProperties(rPr, b.sheet.properties(styleID, "w:rPr"))
+	mark := rPr.Clone()
+	if direct := p.Child("w:pPr"); direct != nil {
+		mergeProperties(mark, direct.Child("w:rPr"))
+	}
+	applyRunProperties(&block.Mark, mark)
+	b.offset = 0
+	b.spans(p, rPr, &block)
+	return block
+}
+
+// spans 收集段落中的最终文字，跳过删除修订
+func (b *blockBuilder) spans(n, rPr *Node, block *Block) {
+	for _, c := range n.Children {
+		switch {
+		case c.Name == "w:r":
+			b.run(c, rPr, block)
+		case c.Name == "w:hyperlink":
+			outer := b.link
+			b.link = b.doc.hyperlinkTarget(c).Link()
+			b.spans(c, rPr, block)
+			b.link = outer
+		case c.Name == "w:ins" || c.Name == "w:moveTo" || containerElements[c.Name]:
+			b.spans(c, rPr, block)
+		}
+	}
+}
+
+// run 将Run的文字和图片加入块，字符样式和直接格式覆盖段落样式
+//
+// 隐藏文字不显示，但仍计入段落最终文本的位置。
+func (b *blockBuilder) run(r, paragraphRPr *Node, block *Block) {
+	rPr := paragraphRPr.Clone()
+	if direct := r.Child("w:rPr"); direct != nil {
+		if rStyle := direct.Child("w:rStyle"); rStyle != nil {
+			mergeProperties(rPr, b.sheet.properties(rStyle.Attr("w:val"), "w:rPr"))
+		}
+		mergeProperties(rPr, direct)
+	}
+	if onOffValue(rPr.Child("w:vanish")) {
+		b.offset += runTextLength(r)
+		return
+	}
+	span := TextSpan{Link: b.link}
+	applyRunProperties(&span, rPr)
+
+	var builder strings.Builder
+	start := b.offset
+	flush := func() {
+		if builder.Len() > 0 {
+			text := span
+			text.Text = builder.String()
+			b.appendSpan(block, text, start)
+			builder.Reset()
+		}
+		start = b.offset
+	}
+	for _, c := range r.Children {
+		switch c.Name {
+		case "w:t":
+			builder.WriteString(c.InnerText())
+		case "w:tab", "w:ptab":
+			builder.WriteString("\t")
+		case "w:br", "w:cr":
+			if c.Attr("w:type") == "page" {
+				builder.WriteRune(PageBreak)
+			} else {
+				builder.WriteString("\n")
+			}
+		case "w:noBreakHyphen":
+			builder.WriteString("-")
+		case "w:footnoteReference", "w:endnoteReference":
+			endnote := c.Name == "w:endnoteReference"
+			if number, ok := b.notes[noteKey(endnote, c.Attr("w:id"))]; ok {
+				flush()
+				note := span
+				note.Text = noteLabel(endnote, number)
+				note.NoteID = c.Attr("w:id")
+				note.Endnote = endnote
+				b.appendSpan(block, note, b.offset)
+			}
+		case "w:drawing":
+			if image, ok := b.image(c); ok {
+				block.Images = append(block.Images, image)
+				builder.WriteRune(ObjectReplacement)
+			}
+		}
+		b.offset += runChildLength(c)
+	}
+	flush()
+}
+
+// appendSpan 加入片段并记录它在段落最终文本中的位置，位置不相连的片段不合并
+func (b *blockBuilder) appendSpan(block *Block, span TextSpan, offset int) {
+	if n := len(block.Spans); n > 0 && block.SpanOffsets[n-1]+SpanLength(block.Spans[n-1]) == offset {
+		appendSpan(&block.Spans, span)
+		if len(block.Spans) == n {
+			return
+		}
+	} else {
+		block.Spans = append(block.Spans, span)
+	}
+	block.SpanOffsets = append(block.SpanOffsets, offset)
+}
+
+// SpanLength 返回片段在段落最终文本中占用的字符数，注释编号和图片不占位置
+func SpanLength(span TextSpan) int {
+	if span.NoteID != "" {
+		return 0
+	}
+	length := 0
+	for _, r := range span.Text {
+		if r != ObjectReplacement {
+			length++
+		}
+	}
+	return length
+}
+
+// image 读取 w:drawing 中图片的尺寸和部件，浮动图片按嵌入式处理
+func (b *blockBuilder) image(drawing *Node) (InlineImage, bool) {
+	var image InlineImage
+	extents := drawing.Find("wp:extent")
+	if len(extents) == 0 {
+		return image, false
+	}
+	cx, _ := strconv.Atoi(extents[0].Attr("cx"))
+	cy, _ := strconv.Atoi(extents[0].Attr("cy"))
+	image.Width, image.Height = cx/emuPerTwip, cy/emuPerTwip
+	if docPr := drawing.Find("wp:docPr"); len(docPr) > 0 {
+		image.Name = docPr[0].Attr("descr")
+		if image.Name == "" {
+			image.Name = docPr[0].Attr("name")
+		}
+	}
+	if blips := drawing.Find("a:blip"); len(blips) > 0 {
+		source := b.doc.Package.MainPartName()
+		if rel, ok := b.doc.Package.Relationship(source, blips[0].Attr("r:embed")); ok && !rel.External {
+			image.Part = b.doc.Package.ResolveTarget(source, rel.Target)
+		}
+	}
+	return image, image.Width > 0 && image.Height > 0
+}
+
+// table 将表格节点转换为块，单元格宽度按表格网格计算
+func (b *blockBuilder) table(tbl *Node) Block {
+	block := Block{Kind: TableBlock, Paragraph: -1, Section: b.section}
+	tblPr := NewNode("w:tblPr")
+	if direct := tbl.Child("w:tblPr"); direct != nil {
+		if style := direct.Child("w:tblStyle"); style != nil {
+			block.Style = style.Attr("w:val")
+			mergeProperties(tblPr, b.sheet.properties(block.Style, "w:tblPr"))
+		}
+		mergeProperties(tblPr, direct)
+	}
+	if borders := tblPr.Child("w:tblBorders"); borders != nil {
+		for _, side := range borders.Children {
+			if value := side.Attr("w:val"); side.IsElement() && value != "" && value != "none" && value != "nil" {
+				block.Borders = true
+			}
+		}
+	}
+	var grid []int
+	if tblGrid := tbl.Child("w:tblGrid"); tblGrid != nil {
+		for _, col := range tblGrid.ChildrenNamed("w:gridCol") {
+			width, _ := strconv.Atoi(col.Attr("w:w"))
+			grid = append(grid, width)
+		}
+	}
+	for _, tr := range tableRows(tbl) {
+		row := TableRow{}
+		if trPr := tr.Child("w:trPr"); trPr != nil {
+			if height := trPr.Child("w:trHeight"); height != nil {
+				row.Height, _ = strconv.Atoi(height.Attr("w:val"))
+				row.Exact = height.Attr("w:hRule") == "exact"
+			}
+			row.Header = onOffValue(trPr.Child("w:tblHeader"))
+		}
+		column := 0
+		for _, tc := range tr.ChildrenNamed("w:tc") {
+			cell := TableCell{Span: 1}
+			tcPr := tc.Child("w:tcPr")
+			if tcPr != nil {
+				if span := tcPr.Child("w:gridSpan"); span != nil {
+					if value, err := strconv.Atoi(span.Attr("w:val")); err == nil && value > 1 {
+						cell.Span = value
+					}
+				}
+				if merge := tcPr.Child("w:vMerge"); merge != nil {
+					cell.Merged = merge.Attr("w:val") != "restart"
+				}
+				if shd := tcPr.Child("w:shd"); shd != nil {
+					if fill := shd.Attr("w:fill"); fill != "" && fill != "auto" {
+						cell.Shading = fill
+					}
+				}
+			}
+			if column+cell.Span <= len(grid) {
+				for _, width := range grid[column : column+cell.Span] {
+					cell.Width += width
+				}
+			} else if tcPr != nil {
+				if tcW := tcPr.Child("w:tcW"); tcW != nil && (tcW.Attr("w:type") == "dxa" || tcW.Attr("w:type") == "") {
+					cell.Width, _ = strconv.Atoi(tcW.Attr("w:w"))
+				}
+			}
+			column += cell.Span
+			if !cell.Merged {
+				cell.Blocks = b.collect(tc, false)
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		block.Rows = append(block.Rows, row)
+	}
+	return block
+}
+
+// tableRows 返回表格的行，包括内容控件中的行
+func tableRows(tbl *Node) []*Node {
+	var rows []*Node
+	for _, c := range tbl.Children {
+		switch c.Name {
+		case "w:tr":
+			rows = append(rows, c)
+		case "w:sdt", "w:sdtContent", "w:customXml":
+			rows = append(rows, tableRows(c)...)
+		}
+	}
+	return rows
+}
@@ -0,0 +1,248 @@
+package document
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// TextSpan 段落中格式相同的一段文字，供界面按格式渲染
+type TextSpan struct {
+	Text      string
+	Bold      bool
+	Italic    bool
+	Underline bool
+	Strike    bool
+	FontSize  int    // 半磅，0表示默认
+	FontName  string // 字体名称
+	Color     string // RRGGBB，空表示自动
+	Highlight string // 突出显示颜色名称，空表示无
+	Inserted  bool   // 属于插入修订
+	Deleted   bool   // 属于删除修订
+	Author    string // 修订作者
+	NoteID    string // 脚注或尾注引用的ID，非空时 Text 为注释编号
+	Endnote   bool   // 引用的是尾注
+	Link      string // 所在超链接的目标（外部地址，或 # 加书签名称），只有 Blocks 返回的片段设置
+}
+
+// containerElements 段落中只起包装作用、需要继续深入查找Run的元素
+var containerElements = map[string]bool{
+	"w:hyperlink":  true,
+	"w:smartTag":   true,
+	"w:customXml":  true,
+	"w:sdt":        true,
+	"w:sdtContent": true,
+	"w:fldSimple":  true,
+	"w:bdo":        true,
+	"w:dir":        true,
+}
+
+// body 返回文档正文节点，只有基于OPC包的文档才有
+func (doc *Document) body() (*Node, error) {
+	if doc.ReadOnly {
+		return nil, fmt.Errorf("文档以只读方式打开，不支持此操作")
+	}
+	if doc.Package == nil {
+		return nil, fmt.Errorf("文档不支持此操作")
+	}
+	return doc.Package.Body()
+}
+
+// paragraphNodes 返回正文中的顶层段落（包括内容控件中的段落，不包括表格中的段落）
+func (doc *Document) paragraphNodes() []*Node {
+	body, err := doc.body()
+	if err != nil {
+		return nil
+	}
+	return collectParagraphs(body)
+}
+
+// collectParagraphs 收集容器中的顶层段落
+func collectParagraphs(container *Node) []*Node {
+	var paragraphs []*Node
+	for _, c := range container.Children {
+		switch c.Name {
+		case "w:p":
+			paragraphs = append(paragraphs, c)
+		case "w:sdt", "w:sdtContent", "w:customXml":
+			paragraphs = append(paragraphs, collectParagraphs(c)...)
+		}
+	}
+	return paragraphs
+}
+
+// paragraphNodeText 返回段落的最终文本（包含插入的内容，不包含删除的内容和注释引用标记）
+func paragraphNodeText(p *Node) string {
+	var builder strings.Builder
+	for _, span := range paragraphSpans(p) {
+		if !span.Deleted && span.NoteID == "" {
+			builder.WriteString(span.Text)
+		}
+	}
+	return builder.String()
+}
+
+// paragraphSpans 将段落拆分为格式相同的文字片段，删除的内容也包含在内
+func paragraphSpans(p *Node) []TextSpan {
+	var spans []TextSpan
+	collectSpans(p, TextSpan{}, &spans)
+	return spans
+}
+
+// collectSpans 递归收集Run中的文字片段，base 携带外层修订信息
+func collectSpans(n *Node, base TextSpan, spans *[]TextSpan) {
+	for _, c := range n.Children {
+		switch {
+		case c.Name == "w:r":
+			appendRunSpans(c, base, spans)
+		case c.Name == "w:ins" || c.Name == "w:moveTo":
+			inner := base
+			inner.Inserted = true
+			inner.Author = c.Attr("w:author")
+			collectSpans(c, inner, spans)
+		case c.Name == "w:del" || c.Name == "w:moveFrom":
+			inner := base
+			inner.Deleted = true
+			inner.Author = c.Attr("w:author")
+			collectSpans(c, inner, spans)
+		case containerElements[c.Name]:
+			collectSpans(c, base, spans)
+		}
+	}
+}
+
+// appendRunSpans 将单个Run的文字按格式加入片段列表，脚注和尾注引用单独成为片段
+func appendRunSpans(r *Node, base TextSpan, spans *[]TextSpan) {
+	span := base
+	applyRunProperties(&span, r.Child("w:rPr"))
+
+	var builder strings.Builder
+	flush := func() {
+		if builder.Len() == 0 {
+			return
+		}
+		text := span
+		text.Text = builder.String()
+		appendSpan(spans, text)
+		builder.Reset()
+	}
+	for _, c := range r.Children {
+		switch c.Name {
+		case "w:t", "w:delText":
+			builder.WriteString(c.InnerText())
+		case "w:tab", "w:ptab":
+			builder.WriteString("\t")
+		case "w:br", "w:cr":
+			builder.WriteString("\n")
+		case "w:noBreakHyphen":
+			builder.WriteString("-")
+		case "w:footnoteReference", "w:endnoteReference":
+			flush()
+			note := span
+			note.NoteID = c.Attr("w:id")
+			note.Endnote = c.Name == "w:endnoteReference"
+			note.Text = "[" + note.NoteID + "]"
+			*spans = append(*spans, note)
+		}
+	}
+	flush()
+}
+
+// appendSpan 加入文字片段，与前一个格式相同的片段合并
+func appendSpan(spans *[]TextSpan, span TextSpan) {
+	if n := len(*spans); n > 0 && (*spans)[n-1].NoteID == "" {
+		last := &(*spans)[n-1]
+		text := last.Text
+		last.Text = ""
+		merged := span
+		merged.Text = ""
+		if *last == merged {
+			last.Text = text + span.Text
+			return
+		}
+		last.Text = text
+	}
+	*spans = append(*spans, span)
+}
+
+// applyRunProperties 读取 w:rPr 中的字符格式
+func applyRunProperties(span *TextSpan, rPr *Node) {
+	if rPr == nil {
+		return
+	}
+	if b := rPr.Child("w:b"); b != nil {
+		span.Bold = onOffValue(b)
+	}
+	if i := rPr.Child("w:i"); i != nil {
+		span.Italic = onOffValue(i)
+	}
+	if u := rPr.Child("w:u"); u != nil {
+		span.Underline = u.Attr("w:val") != "none"
+	}
+	if s := rPr.Child("w:strike"); s != nil {
+		span.Strike = onOffValue(s)
+	}
+	if s := rPr.Child("w:dstrike"); s != nil && onOffValue(s) {
+		span.Strike = true
+	}
+	if sz := rPr.Child("w:sz"); sz != nil {
+		if size, err := strconv.Atoi(sz.Attr("w:val")); err == nil {
+			span.FontSize = size
+		}
+	}
+	if fonts := rPr.Child("w:rFonts"); fonts != nil {
+		span.FontName = fonts.Attr("w:eastAsia")
+		if span.FontName == "" {
+			span.FontName = fonts.Attr("w:ascii")
+		}
+		if span.FontName == "" {
+			span.FontName = fonts.Attr("w:hAnsi")
+		}
+	}
+	if color := rPr.Child("w:color"); color != nil {
+		span.Color = color.Attr("w:val")
+		if strings.EqualFold(span.Color, "auto") {
+			span.Color = ""
+		}
+	}
+	if highlight := rPr.Child("w:highlight"); highlight != nil {
+		span.Highlight = highlight.Attr("w:val")
+		if span.Highlight == "none" {
+			span.Highlight = ""
+		}
+	}
+}
+
+// paragraphFromNode 将段落节点转换为go-word的段落结构（最终文本，不含删除的内容）
+func paragraphFromNode(p *Node) types.Paragraph {
+	paragraph := types.Paragraph{Style: paragraphStyle(p)}
+	for _, span := range paragraphSpans(p) {
+		if span.Deleted || span.NoteID != "" {
+			continue
+		}
+		paragraph.Runs = append(paragraph.Runs, types.Run{
+			Text:      span.Text,
+			Bold:      span.Bold,
+			Italic:    span.Italic,
+			Underline: span.Underline,
+			FontSize:  span.FontSize,
+			FontName:  span.FontName,
+			Color:     span.Color,
+		})
+		paragraph.Text += span.Text
+	}
+	return paragraph
+}
+
+// ParagraphSpans 返回指定段落的格式片段，包括修订中删除的文字，注释引用显示为编号
+func (doc *Document) ParagraphSpans(index int) ([]TextSpan, error) {
+	paragraphs := doc.paragraphNodes()
+	if index < 0 || index >= len(paragraphs) {
+		return nil, fmt.Errorf("段落索引超出范围: %d", index+1)
+	}
+	spans := paragraphSpans(paragraphs[index])
+	numberNoteSpans(spans, doc.noteNumbers())
+	return spans, nil
+}
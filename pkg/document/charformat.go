@@ -0,0 +1,400 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CharProperty 字符格式属性，可以按位组合，指定要设置或清除的属性
+type CharProperty int
+
+const (
+	CharBold         CharProperty = 1 << iota // 加粗
+	CharItalic                                // 倾斜
+	CharUnderline                             // 下划线
+	CharStrike                                // 删除线
+	CharFont                                  // 西文字体
+	CharEastAsiaFont                          // 中文字体
+	CharSize                                  // 字号
+	CharColor                                 // 字体颜色
+	CharHighlight                             // 突出显示
+
+	AllCharProperties = CharBold | CharItalic | CharUnderline | CharStrike | CharFont |
+		CharEastAsiaFont | CharSize | CharColor | CharHighlight
+)
+
+// CharFormat 字符格式，只有 CharProperty 指定的字段会被使用
+type CharFormat struct {
+	Bold         bool
+	Italic       bool
+	Underline    string // 下划线类型，如 single、double、wave，空表示无下划线
+	Strike       bool
+	Font         string  // 西文字体
+	EastAsiaFont string  // 中文字体
+	Size         float64 // 字号，单位为磅，0表示默认
+	Color        string  // RRGGBB，空表示自动
+	Highlight    string  // 突出显示颜色名称，如 yellow，空表示无
+}
+
+// TextRange 正文中的文字范围，偏移量按段落最终文本的字符计算
+type TextRange struct {
+	StartParagraph int
+	StartOffset    int
+	EndParagraph   int
+	EndOffset      int // 不包含，-1表示段落末尾
+}
+
+// ParagraphRange 返回整个段落的文字范围
+func ParagraphRange(paragraph int) TextRange {
+	return TextRange{StartParagraph: paragraph, EndParagraph: paragraph, EndOffset: -1}
+}
+
+// HighlightColors Word支持的突出显示颜色
+var HighlightColors = []string{
+	"yellow", "green", "cyan", "magenta", "blue", "red", "darkBlue", "darkCyan",
+	"darkGreen", "darkMagenta", "darkRed", "darkYellow", "darkGray", "lightGray", "black", "white",
+}
+
+// hexColorPattern RRGGBB格式的颜色
+var hexColorPattern = regexp.MustCompile(`^[0-9A-Fa-f]{6}$`)
+
+// mergeableRunElements 可以合并到相邻Run中的Run子元素
+var mergeableRunElements = map[string]bool{
+	"w:rPr": true, "w:t": true, "w:tab": true, "w:br": true, "w:cr": true, "w:noBreakHyphen": true,
+}
+
+// validate 检查格式中要设置的属性是否有效
+func (f CharFormat) validate(props CharProperty) error {
+	if props&CharSize != 0 && (f.Size < 0 || f.Size > 1638) {
+		return fmt.Errorf("无效的字号: %g", f.Size)
+	}
+	if props&CharColor != 0 && f.Color != "" && !hexColorPattern.MatchString(f.Color) {
+		return fmt.Errorf("无效的颜色: %s", f.Color)
+	}
+	if props&CharHighlight != 0 && f.Highlight != "" && indexOfString(HighlightColors, f.Highlight) < 0 {
+		return fmt.Errorf("无效的突出显示颜色: %s", f.Highlight)
+	}
+	return nil
+}
+
+// ApplyCharFormat 为范围内的文字设置字符格式，props 指定要设置的属性
+//
+// 范围边界处的Run会被拆分，设置后格式相同的相邻Run会被合并。
+func (doc *Document) ApplyCharFormat(r TextRange, format CharFormat, props CharProperty) error {
+	if err := format.validate(props); err != nil {
+		return err
+	}
+	return doc.formatRange(r, func(rPr *Node) {
+		setRunProperties(rPr, format, props)
+	})
+}
+
+// ClearCharFormat 清除范围内文字直接设置的字符格式，props 指定要清除的属性
+func (doc *Document) ClearCharFormat(r TextRange, props CharProperty) error {
+	return doc.formatRange(r, func(rPr *Node) {
+		clearRunProperties(rPr, props)
+	})
+}
+
+// CharFormatAt 返回段落中第 offset 个字符直接设置的字符格式，offset 超出段落时返回最后一个字符的格式
+func (doc *Document) CharFormatAt(paragraph, offset int) (CharFormat, error) {
+	paragraphs := doc.paragraphNodes()
+	if paragraph < 0 || paragraph >= len(paragraphs) {
+		return CharFormat{}, fmt.Errorf("段落索引超出范围: %d", paragraph+1)
+	}
+	var format CharFormat
+	pos := 0
+	for _, run := range textRuns(paragraphs[paragraph]) {
+		length := runTextLength(run)
+		if length == 0 {
+			continue
+		}
+		format = directCharFormat(run.Child("w:rPr"))
+		if offset < pos+length {
+			break
+		}
+		pos += length
+	}
+	return format, nil
+}
+
+// formatRange 拆分范围边界处的Run，对范围内每个Run的 w:rPr 调用 apply，然后合并相同格式的Run
+func (doc *Document) formatRange(r TextRange, apply func(rPr *Node)) error {
+	paragraphs := doc.paragraphNodes()
+	if r.StartParagraph < 0 || r.EndParagraph >= len(paragraphs) || r.StartParagraph > r.EndParagraph {
+		return fmt.Errorf("段落范围无效: %d-%d", r.StartParagraph+1, r.EndParagraph+1)
+	}
+	if r.StartOffset < 0 || (r.StartParagraph == r.EndParagraph && r.EndOffset >= 0 && r.EndOffset < r.StartOffset) {
+		return fmt.Errorf("文字范围无效: %d-%d", r.StartOffset, r.EndOffset)
+	}
+
+	count := 0
+	for i := r.StartParagraph; i <= r.EndParagraph; i++ {
+		p := paragraphs[i]
+		start, end := 0, -1
+		if i == r.StartParagraph {
+			start = r.StartOffset
+		}
+		if i == r.EndParagraph {
+			end = r.EndOffset
+		}
+		if end >= 0 {
+			splitRunsAt(p, end)
+		}
+		splitRunsAt(p, start)
+
+		pos := 0
+		for _, run := range textRuns(p) {
+			length := runTextLength(run)
+			if length > 0 && pos >= start && (end < 0 || pos+length <= end) {
+				rPr := runProperties(run)
+				apply(rPr)
+				if len(rPr.Children) == 0 && len(rPr.Attrs) == 0 {
+					run.RemoveChild(rPr)
+				}
+				count++
+			}
+			pos += length
+		}
+		mergeRuns(p)
+	}
+
+	doc.IsModified = true
+	log.Printf("已修改 %d 个文字片段的字符格式", count)
+	return nil
+}
+
+// textRuns 按文本顺序返回段落中计入最终文本的Run，不包含删除修订中的Run
+func textRuns(p *Node) []*Node {
+	var runs []*Node
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for _, c := range n.Children {
+			switch {
+			case c.Name == "w:r":
+				runs = append(runs, c)
+			case c.Name == "w:ins" || c.Name == "w:moveTo" || containerElements[c.Name]:
+				walk(c)
+			}
+		}
+	}
+	walk(p)
+	return runs
+}
+
+// setRunProperties 在 w:rPr 中写入 props 指定的字符格式
+func setRunProperties(rPr *Node, f CharFormat, props CharProperty) {
+	if props&CharBold != 0 {
+		setOnOff(rPr, "w:b", f.Bold)
+		setOnOff(rPr, "w:bCs", f.Bold)
+	}
+	if props&CharItalic != 0 {
+		setOnOff(rPr, "w:i", f.Italic)
+		setOnOff(rPr, "w:iCs", f.Italic)
+	}
+	if props&CharUnderline != 0 {
+		u := rPr.EnsureChild("w:u", rPrOrder)
+		u.Attrs = nil
+		if f.Underline == "" {
+			u.SetAttr("w:val", "none")
+		} else {
+			u.SetAttr("w:val", f.Underline)
+		}
+	}
+	if props&CharStrike != 0 {
+		rPr.RemoveChildrenNamed("w:dstrike")
+		setOnOff(rPr, "w:strike", f.Strike)
+	}
+	if props&CharFont != 0 && f.Font != "" {
+		fonts := rPr.EnsureChild("w:rFonts", rPrOrder)
+		fonts.RemoveAttr("w:asciiTheme")
+		fonts.RemoveAttr("w:hAnsiTheme")
+		fonts.SetAttr("w:ascii", f.Font)
+		fonts.SetAttr("w:hAnsi", f.Font)
+	}
+	if props&CharEastAsiaFont != 0 && f.EastAsiaFont != "" {
+		fonts := rPr.EnsureChild("w:rFonts", rPrOrder)
+		fonts.RemoveAttr("w:eastAsiaTheme")
+		fonts.SetAttr("w:eastAsia", f.EastAsiaFont)
+		fonts.SetAttr("w:hint", "eastAsia")
+	}
+	if props&CharSize != 0 {
+		if f.Size > 0 {
+			value := strconv.Itoa(int(f.Size*2 + 0.5))
+			rPr.EnsureChild("w:sz", rPrOrder).SetAttr("w:val", value)
+			rPr.EnsureChild("w:szCs", rPrOrder).SetAttr("w:val", value)
+		} else {
+			rPr.RemoveChildrenNamed("w:sz")
+			rPr.RemoveChildrenNamed("w:szCs")
+		}
+	}
+	if props&CharColor != 0 {
+		color := rPr.EnsureChild("w:color", rPrOrder)
+		color.Attrs = nil
+		if f.Color == "" {
+			color.SetAttr("w:val", "auto")
+		} else {
+			color.SetAttr("w:val", strings.ToUpper(f.Color))
+		}
+	}
+	if props&CharHighlight != 0 {
+		value := f.Highlight
+		if value == "" {
+			value = "none"
+		}
+		rPr.EnsureChild("w:highlight", rPrOrder).SetAttr("w:val", value)
+	}
+}
+
+// clearRunProperties 删除 w:rPr 中 props 指定的字符格式
+func clearRunProperties(rPr *Node, props CharProperty) {
+	remove := func(prop CharProperty, names ...string) {
+		if props&prop == 0 {
+			return
+		}
+		for _, name := range names {
+			rPr.RemoveChildrenNamed(name)
+		}
+	}
+	remove(CharBold, "w:b", "w:bCs")
+	remove(CharItalic, "w:i", "w:iCs")
+	remove(CharUnderline, "w:u")
+	remove(CharStrike, "w:strike", "w:dstrike")
+	remove(CharSize, "w:sz", "w:szCs")
+	remove(CharColor, "w:color")
+	remove(CharHighlight, "w:highlight")
+
+	if fonts := rPr.Child("w:rFonts"); fonts != nil {
+		if props&CharFont != 0 {
+			for _, name := range []string{"w:ascii", "w:hAnsi", "w:asciiTheme", "w:hAnsiTheme", "w:cs", "w:cstheme"} {
+				fonts.RemoveAttr(name)
+			}
+		}
+		if props&CharEastAsiaFont != 0 {
+			for _, name := range []string{"w:eastAsia", "w:eastAsiaTheme", "w:hint"} {
+				fonts.RemoveAttr(name)
+			}
+		}
+		if len(fonts.Attrs) == 0 {
+			rPr.RemoveChild(fonts)
+		}
+	}
+}
+
+// setOnOff 设置开关属性，关闭时写入 w:val="0" 以覆盖样式中的设置
+func setOnOff(rPr *Node, name string, on bool) {
+	n := rPr.EnsureChild(name, rPrOrder)
+	if on {
+		n.RemoveAttr("w:val")
+	} else {
+		n.SetAttr("w:val", "0")
+	}
+}
+
+// directCharFormat 读取 w:rPr 中直接设置的字符格式
+func directCharFormat(rPr *Node) CharFormat {
+	var f CharFormat
+	if rPr == nil {
+		return f
+	}
+	if b := rPr.Child("w:b"); b != nil {
+		f.Bold = onOffValue(b)
+	}
+	if i := rPr.Child("w:i"); i != nil {
+		f.Italic = onOffValue(i)
+	}
+	if u := rPr.Child("w:u"); u != nil && u.Attr("w:val") != "none" {
+		f.Underline = u.Attr("w:val")
+		if f.Underline == "" {
+			f.Underline = "single"
+		}
+	}
+	f.Strike = onOffValue(rPr.Child("w:strike")) || onOffValue(rPr.Child("w:dstrike"))
+	if fonts := rPr.Child("w:rFonts"); fonts != nil {
+		f.Font = fonts.Attr("w:ascii")
+		if f.Font == "" {
+			f.Font = fonts.Attr("w:hAnsi")
+		}
+		f.EastAsiaFont = fonts.Attr("w:eastAsia")
+	}
+	if sz := rPr.Child("w:sz"); sz != nil {
+		if value, err := strconv.Atoi(sz.Attr("w:val")); err == nil {
+			f.Size = float64(value) / 2
+		}
+	}
+	if color := rPr.Child("w:color"); color != nil && !strings.EqualFold(color.Attr("w:val"), "auto") {
+		f.Color = color.Attr("w:val")
+	}
+	if highlight := rPr.Child("w:highlight"); highlight != nil && highlight.Attr("w:val") != "none" {
+		f.Highlight = highlight.Attr("w:val")
+	}
+	return f
+}
+
+// mergeRuns 合并段落中格式相同的相邻Run，包括超链接、插入修订等容器中的Run
+func mergeRuns(n *Node) {
+	for i := 0; i < len(n.Children); i++ {
+		c := n.Children[i]
+		if c.Name != "w:r" {
+			if c.Name == "w:ins" || c.Name == "w:moveTo" || containerElements[c.Name] {
+				mergeRuns(c)
+			}
+			continue
+		}
+		for i+1 < len(n.Children) && canMergeRuns(c, n.Children[i+1]) {
+			next := n.Children[i+1]
+			for _, child := range next.Children {
+				if child.Name == "w:rPr" {
+					continue
+				}
+				last := len(c.Children) - 1
+				if child.Name == "w:t" && last >= 0 && c.Children[last].Name == "w:t" {
+					// 合并相邻的文字元素
+					text := c.Children[last].InnerText() + child.InnerText()
+					c.Children = c.Children[:last]
+					appendRunText(c, text, "w:t")
+					continue
+				}
+				c.AppendChild(child)
+			}
+			n.RemoveChild(next)
+		}
+	}
+}
+
+// canMergeRuns 判断两个Run是否可以合并：只包含文字且格式和属性相同
+func canMergeRuns(a, b *Node) bool {
+	if a.Name != "w:r" || b.Name != "w:r" {
+		return false
+	}
+	for _, r := range []*Node{a, b} {
+		for _, c := range r.Children {
+			if c.IsElement() && !mergeableRunElements[c.Name] {
+				return false
+			}
+		}
+	}
+	if runAttrs(a) != runAttrs(b) {
+		return false
+	}
+	aPr, bPr := a.Child("w:rPr"), b.Child("w:rPr")
+	if aPr == nil || bPr == nil {
+		return aPr == nil && bPr == nil
+	}
+	return aPr.String() == bPr.String()
+}
+
+// runAttrs 返回Run中除修订标识外的属性，用于比较
+func runAttrs(r *Node) string {
+	var builder strings.Builder
+	for _, attr := range r.Attrs {
+		if strings.HasPrefix(attr.Name, "w:rsid") {
+			continue
+		}
+		builder.WriteString(attr.Name + "=" + attr.Value + ";")
+	}
+	return builder.String()
+}
@@ -0,0 +1,174 @@
+package document
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// describeRuns 描述节点的子元素：Run 显示为文字，加粗以 * 开头、倾斜以 / 开头，
+// 其它元素显示为元素名和其中的内容
+func describeRuns(n *Node) []string {
+	var result []string
+	for _, c := range n.Elements() {
+		if c.Name != "w:r" {
+			if c.Name != "w:pPr" {
+				result = append(result, c.Name+"("+strings.Join(describeRuns(c), ",")+")")
+			}
+			continue
+		}
+		var text strings.Builder
+		for _, t := range c.Elements() {
+			switch t.Name {
+			case "w:t", "w:delText":
+				text.WriteString(t.InnerText())
+			case "w:tab":
+				text.WriteString("\t")
+			}
+		}
+		format := directCharFormat(c.Child("w:rPr"))
+		prefix := ""
+		if format.Bold {
+			prefix += "*"
+		}
+		if format.Italic {
+			prefix += "/"
+		}
+		result = append(result, prefix+text.String())
+	}
+	return result
+}
+
+func TestApplyCharFormat(t *testing.T) {
+	const bold = `<w:rPr><w:b/><w:bCs/></w:rPr>`
+	tests := []struct {
+		name string
+		body string
+		r    TextRange
+		want [][]string
+	}{
+		{"拆分Run", `<w:p><w:r><w:t>一二三四五</w:t></w:r></w:p>`, TextRange{0, 1, 0, 3},
+			[][]string{{"一", "*二三", "四五"}}},
+		{"与相同格式的Run合并", `<w:p><w:r>` + bold + `<w:t>一二</w:t></w:r><w:r><w:t>三四</w:t></w:r></w:p>`, TextRange{0, 2, 0, 4},
+			[][]string{{"*一二三四"}}},
+		{"整个段落", `<w:p><w:r><w:t>一</w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>二</w:t></w:r><w:r><w:t>三</w:t></w:r></w:p>`, ParagraphRange(0),
+			[][]string{{"*一", "*/二", "*三"}}},
+		{"不跨越超链接合并", `<w:p><w:r><w:t>前</w:t></w:r><w:hyperlink w:anchor="a"><w:r><w:t>链接</w:t></w:r></w:hyperlink><w:r><w:t>后</w:t></w:r></w:p>`,
+			TextRange{0, 0, 0, 4}, [][]string{{"*前", "w:hyperlink(*链接)", "*后"}}},
+		{"超链接中拆分", `<w:p><w:hyperlink w:anchor="a"><w:r><w:t>链接文字</w:t></w:r></w:hyperlink></w:p>`,
+			TextRange{0, 2, 0, -1}, [][]string{{"w:hyperlink(链接,*文字)"}}},
+		{"插入修订中的文字", `<w:p><w:r><w:t>前</w:t></w:r><w:ins ` + testIns + `><w:r><w:t>插入</w:t></w:r></w:ins></w:p>`,
+			TextRange{0, 0, 0, 3}, [][]string{{"*前", "w:ins(*插入)"}}},
+		{"删除的文字不计入范围", `<w:p><w:r><w:t>前</w:t></w:r><w:del ` + testDel + `><w:r><w:delText>删除</w:delText></w:r></w:del><w:r><w:t>后</w:t></w:r></w:p>`,
+			TextRange{0, 0, 0, 2}, [][]string{{"*前", "w:del(删除)", "*后"}}},
+		{"跨越段落", `<w:p><w:r><w:t>第一段</w:t></w:r></w:p><w:p><w:r><w:t>第二段</w:t></w:r></w:p>`, TextRange{0, 1, 1, 2},
+			[][]string{{"第", "*一段"}, {"*第二", "段"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := newBodyTestDocument(t, tt.body)
+			if err := doc.ApplyCharFormat(tt.r, CharFormat{Bold: true}, CharBold); err != nil {
+				t.Fatalf("设置字符格式失败: %v", err)
+			}
+			var got [][]string
+			for _, p := range doc.paragraphNodes() {
+				got = append(got, describeRuns(p))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("设置后的Run %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClearCharFormat(t *testing.T) {
+	doc := newBodyTestDocument(t, `<w:p><w:r><w:t>普通</w:t></w:r><w:r><w:rPr><w:rFonts w:ascii="Arial" w:eastAsia="黑体" w:hint="eastAsia"/><w:b/><w:color w:val="FF0000"/></w:rPr><w:t>格式</w:t></w:r></w:p>`)
+	if err := doc.ClearCharFormat(ParagraphRange(0), CharBold|CharColor|CharEastAsiaFont); err != nil {
+		t.Fatalf("清除字符格式失败: %v", err)
+	}
+	if got, _ := doc.CharFormatAt(0, 2); !reflect.DeepEqual(got, CharFormat{Font: "Arial"}) {
+		t.Errorf("清除后的格式 %+v", got)
+	}
+	if err := doc.ClearCharFormat(ParagraphRange(0), CharFont); err != nil {
+		t.Fatalf("清除字符格式失败: %v", err)
+	}
+	if got := describeRuns(doc.paragraphNodes()[0]); !reflect.DeepEqual(got, []string{"普通格式"}) {
+		t.Errorf("清除所有格式后没有合并Run: %q", got)
+	}
+}
+
+func TestSetRunProperties(t *testing.T) {
+	tests := []struct {
+		name   string
+		format CharFormat
+		props  CharProperty
+		want   string
+	}{
+		{"取消加粗写入关闭值", CharFormat{}, CharBold, `<w:rPr><w:b w:val="0"/><w:bCs w:val="0"/></w:rPr>`},
+		{"字号按半磅保存", CharFormat{Size: 10.5}, CharSize, `<w:rPr><w:sz w:val="21"/><w:szCs w:val="21"/></w:rPr>`},
+		{"中文字体", CharFormat{EastAsiaFont: "宋体"}, CharEastAsiaFont, `<w:rPr><w:rFonts w:eastAsia="宋体" w:hint="eastAsia"/></w:rPr>`},
+		{"颜色大写", CharFormat{Color: "ff0000"}, CharColor, `<w:rPr><w:color w:val="FF0000"/></w:rPr>`},
+		{"无下划线", CharFormat{}, CharUnderline, `<w:rPr><w:u w:val="none"/></w:rPr>`},
+		{"按架构顺序写入", CharFormat{Bold: true, Highlight: "yellow", Font: "Arial"}, CharHighlight | CharBold | CharFont,
+			`<w:rPr><w:rFonts w:ascii="Arial" w:hAnsi="Arial"/><w:b/><w:bCs/><w:highlight w:val="yellow"/></w:rPr>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rPr := NewNode("w:rPr")
+			setRunProperties(rPr, tt.format, tt.props)
+			if got := rPr.String(); got != tt.want {
+				t.Errorf("w:rPr %s, 期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyCharFormatErrors(t *testing.T) {
+	doc := newTestDocument(t, "正文")
+	tests := []struct {
+		name   string
+		r      TextRange
+		format CharFormat
+		props  CharProperty
+	}{
+		{"无效的颜色", ParagraphRange(0), CharFormat{Color: "red"}, CharColor},
+		{"无效的突出显示颜色", ParagraphRange(0), CharFormat{Highlight: "orange"}, CharHighlight},
+		{"无效的字号", ParagraphRange(0), CharFormat{Size: 2000}, CharSize},
+		{"段落超出范围", ParagraphRange(1), CharFormat{Bold: true}, CharBold},
+		{"结束位置在开始之前", TextRange{0, 2, 0, 1}, CharFormat{Bold: true}, CharBold},
+	}
+	for _, tt := range tests {
+		if err := doc.ApplyCharFormat(tt.r, tt.format, tt.props); err == nil {
+			t.Errorf("%s没有返回错误", tt.name)
+		}
+	}
+}
+
+func TestMergeRuns(t *testing.T) {
+	tests := []struct {
+		name string
+		xml  string
+		want []string
+	}{
+		{"格式相同", `<w:r><w:t>一</w:t></w:r><w:r><w:t xml:space="preserve"> 二</w:t></w:r>`, []string{"一 二"}},
+		{"忽略修订标识", `<w:r w:rsidR="001"><w:t>一</w:t></w:r><w:r w:rsidR="002"><w:t>二</w:t></w:r>`, []string{"一二"}},
+		{"格式不同", `<w:r><w:rPr><w:b/></w:rPr><w:t>一</w:t></w:r><w:r><w:t>二</w:t></w:r>`, []string{"*一", "二"}},
+		{"制表符", `<w:r><w:t>一</w:t></w:r><w:r><w:tab/><w:t>二</w:t></w:r>`, []string{"一\t二"}},
+		{"域字符不合并", `<w:r><w:t>一</w:t></w:r><w:r><w:fldChar w:fldCharType="begin"/></w:r>`, []string{"一", ""}},
+		{"超链接和插入修订中分别合并",
+			`<w:r><w:t>一</w:t></w:r><w:hyperlink w:anchor="a"><w:r><w:t>二</w:t></w:r><w:r><w:t>三</w:t></w:r></w:hyperlink>` +
+				`<w:ins ` + testIns + `><w:r><w:t>四</w:t></w:r><w:r><w:t>五</w:t></w:r></w:ins><w:r><w:t>六</w:t></w:r>`,
+			[]string{"一", "w:hyperlink(二三)", "w:ins(四五)", "六"}},
+		{"删除修订中不合并", `<w:del ` + testDel + `><w:r><w:delText>一</w:delText></w:r><w:r><w:delText>二</w:delText></w:r></w:del>`,
+			[]string{"w:del(一,二)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parseTestParagraph(t, "<w:p>"+tt.xml+"</w:p>")
+			mergeRuns(p)
+			if got := describeRuns(p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("合并后 %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
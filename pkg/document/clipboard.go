@@ -0,0 +1,554 @@
+package document
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// highlightHex Word突出显示颜色对应的RGB值，用于与HTML的背景色互相转换
+var highlightHex = map[string]string{
+	"yellow": "FFFF00", "green": "00FF00", "cyan": "00FFFF", "magenta": "FF00FF",
+	"blue": "0000FF", "red": "FF0000", "darkBlue": "000080", "darkCyan": "008080",
+	"darkGreen": "008000", "darkMagenta": "800080", "darkRed": "800000", "darkYellow": "808000",
+	"darkGray": "808080", "lightGray": "C0C0C0", "black": "000000", "white": "FFFFFF",
+}
+
+// cssColorNames 粘贴HTML时识别的颜色名称
+var cssColorNames = map[string]string{
+	"black": "000000", "white": "FFFFFF", "red": "FF0000", "green": "008000", "blue": "0000FF",
+	"yellow": "FFFF00", "gray": "808080", "grey": "808080", "silver": "C0C0C0", "maroon": "800000",
+	"purple": "800080", "fuchsia": "FF00FF", "lime": "00FF00", "olive": "808000", "navy": "000080",
+	"teal": "008080", "aqua": "00FFFF", "orange": "FFA500",
+}
+
+// htmlFontSizes HTML font 元素的 size 属性（1-7）对应的字号，单位为磅
+var htmlFontSizes = []float64{7.5, 10, 12, 13.5, 18, 24, 36}
+
+// ClipboardContent 复制到剪贴板的内容
+type ClipboardContent struct {
+	Text string // 纯文本，段落之间用换行分隔
+	HTML string // 保留标题和字符格式的HTML
+}
+
+// CopyRange 返回范围内文字的纯文本和HTML，删除修订中的文字和注释编号不复制
+func (doc *Document) CopyRange(r TextRange) (ClipboardContent, error) {
+	paragraphs := doc.paragraphNodes()
+	if r.StartParagraph < 0 || r.EndParagraph >= len(paragraphs) || r.StartParagraph > r.EndParagraph {
+		return ClipboardContent{}, fmt.Errorf("段落范围无效: %d-%d", r.StartParagraph+1, r.EndParagraph+1)
+	}
+	styleLevels := doc.styleOutlineLevels()
+
+	var text, markup strings.Builder
+	markup.WriteString("<html><head><meta charset=\"utf-8\"></head><body>\n<!--StartFragment-->")
+	for i := r.StartParagraph; i <= r.EndParagraph; i++ {
+		start, end := 0, -1
+		if i == r.StartParagraph {
+			start = r.StartOffset
+		}
+		if i == r.EndParagraph {
+			end = r.EndOffset
+		}
+		if i > r.StartParagraph {
+			text.WriteString("\n")
+		}
+		tag := "p"
+		if level := headingLevel(paragraphs[i], styleLevels); level >= 1 && level <= 6 {
+			tag = fmt.Sprintf("h%d", level)
+		}
+		markup.WriteString("<" + tag + " style=\"white-space:pre-wrap\">")
+		for _, span := range rangeSpans(paragraphs[i], start, end) {
+			text.WriteString(span.Text)
+			markup.WriteString(spanHTML(span))
+		}
+		markup.WriteString("</" + tag + ">")
+	}
+	markup.WriteString("<!--EndFragment-->\n</body></html>")
+	return ClipboardContent{Text: text.String(), HTML: markup.String()}, nil
+}
+
+// rangeSpans 返回段落最终文本中 start 到 end（不包含，-1表示段落末尾）之间的文字片段
+func rangeSpans(p *Node, start, end int) []TextSpan {
+	var spans []TextSpan
+	pos := 0
+	for _, span := range paragraphSpans(p) {
+		if span.Deleted || span.NoteID != "" {
+			continue
+		}
+		runes := []rune(span.Text)
+		from, to := max(start-pos, 0), len(runes)
+		if end >= 0 {
+			to = min(to, end-pos)
+		}
+		if from < to {
+			part := span
+			part.Text = string(runes[from:to])
+			spans = append(spans, part)
+		}
+		pos += len(runes)
+	}
+	return spans
+}
+
+// spanHTML 将文字片段转换为带内联样式的HTML
+func spanHTML(span TextSpan) string {
+	var styles []string
+	if span.Bold {
+		styles = append(styles, "font-weight:bold")
+	}
+	if span.Italic {
+		styles = append(styles, "font-style:italic")
+	}
+	var decorations []string
+	if span.Underline {
+		decorations = append(decorations, "underline")
+	}
+	if span.Strike {
+		decorations = append(decorations, "line-through")
+	}
+	if len(decorations) > 0 {
+		styles = append(styles, "text-decoration:"+strings.Join(decorations, " "))
+	}
+	if span.FontSize > 0 {
+		styles = append(styles, "font-size:"+formatPoints(float64(span.FontSize)/2)+"pt")
+	}
+	if span.FontName != "" {
+		styles = append(styles, "font-family:'"+span.FontName+"'")
+	}
+	if span.Color != "" {
+		styles = append(styles, "color:#"+span.Color)
+	}
+	if hex, ok := highlightHex[span.Highlight]; ok {
+		styles = append(styles, "background-color:#"+hex)
+	}
+	text := strings.ReplaceAll(html.EscapeString(span.Text), "\n", "<br>")
+	if len(styles) == 0 {
+		return text
+	}
+	return "<span style=\"" + html.EscapeString(strings.Join(styles, ";")) + "\">" + text + "</span>"
+}
+
+// formatPoints 格式化磅值，整数不带小数
+func formatPoints(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// htmlRun 从HTML转换得到的一段格式相同的文字
+type htmlRun struct {
+	text   string
+	format CharFormat
+	props  CharProperty
+	link   string // 所在链接的地址，空表示不是链接
+}
+
+// htmlParagraph 从HTML转换得到的段落
+type htmlParagraph struct {
+	level int // 标题级别，0为正文
+	runs  []htmlRun
+}
+
+// htmlConverter 将HTML转换为段落和Run
+type htmlConverter struct {
+	paragraphs []htmlParagraph
+	current    *htmlParagraph
+	level      int    // 下一个段落的标题级别
+	link       string // 当前所在链接的地址
+}
+
+// InsertHTML 将HTML转换为带格式的段落和文字插入到指定位置，返回插入内容之后的位置
+//
+// 支持常见的字符格式标签和内联样式，h1-h6 转换为对应级别的标题样式，
+// 其他块元素转换为段落，表格的单元格之间用制表符分隔，链接转换为超链接。
+// 第一段插入到当前段落中，完整粘贴的段落使用来源的标题级别。
+func (doc *Document) InsertHTML(pos TextPosition, source string) (TextPosition, error) {
+	p, err := doc.paragraphNode(pos.Paragraph)
+	if err != nil {
+		return pos, err
+	}
+	if length := len([]rune(paragraphNodeText(p))); pos.Offset < 0 || pos.Offset > length {
+		return pos, fmt.Errorf("文字位置超出段落范围: %d", pos.Offset)
+	}
+	paragraphs, err := parseHTMLParagraphs(source)
+	if err != nil {
+		return pos, err
+	}
+
+	styleLevels := doc.styleOutlineLevels()
+	start := pos.Offset // 当前粘贴段落在所在段落中的起始位置
+	for i, para := range paragraphs {
+		if i > 0 {
+			previous := p
+			if p, err = doc.splitParagraphNode(p, pos.Offset); err != nil {
+				return pos, err
+			}
+			pos = TextPosition{Paragraph: pos.Paragraph + 1}
+			// 完整粘贴的段落使用来源的标题级别，拆分之后再设置，后一段保留原来的段落格式
+			if level := paragraphs[i-1].level; level > 0 && start == 0 {
+				style, err := doc.headingStyle(level, styleLevels)
+				if err != nil {
+					return pos, err
+				}
+				paragraphProperties(previous).EnsureChild("w:pStyle", pPrOrder).SetAttr("w:val", style)
+			}
+			start = 0
+		}
+		ip := splitRunsAt(p, pos.Offset)
+		// 超链接不能嵌套，粘贴到超链接中时链接只保留文字
+		nested := insideElement(p, ip.parent, "w:hyperlink")
+		var link *Node
+		for j, run := range para.runs {
+			var rPr *Node
+			if run.props != 0 || (run.link != "" && !nested) {
+				rPr = NewNode("w:rPr")
+				setRunProperties(rPr, run.format, run.props)
+			}
+			r := newRunNode(run.text, rPr)
+			pos.Offset += len([]rune(run.text))
+			if run.link == "" || nested {
+				ip.insert(r)
+				continue
+			}
+			styleID, err := doc.hyperlinkStyle()
+			if err != nil {
+				return pos, err
+			}
+			rPr.EnsureChild("w:rStyle", rPrOrder).SetAttr("w:val", styleID)
+			if j == 0 || para.runs[j-1].link != run.link {
+				target := LinkTarget{URL: run.link}
+				if strings.HasPrefix(run.link, "#") {
+					target = LinkTarget{Anchor: run.link[1:]}
+				}
+				link = doc.newHyperlinkNode(target)
+				ip.insert(link)
+			}
+			link.AppendChild(r)
+			mergeRuns(link)
+		}
+		mergeRuns(ip.parent)
+	}
+	doc.IsModified = true
+	return pos, nil
+}
+
+// insideElement 判断段落中的节点 n 是否位于名为 name 的元素中（包括 n 本身）
+func insideElement(p, n *Node, name string) bool {
+	for n != nil && n != p {
+		if n.Name == name {
+			return true
+		}
+		n = findParent(p, n)
+	}
+	return false
+}
+
+// parseHTMLParagraphs 解析HTML，返回段落和带格式的文字
+func parseHTMLParagraphs(source string) ([]htmlParagraph, error) {
+	// Windows 剪贴板的HTML格式在片段标记之间是复制的内容
+	if start := strings.Index(source, "<!--StartFragment-->"); start >= 0 {
+		if end := strings.Index(source, "<!--EndFragment-->"); end > start {
+			source = source[start+len("<!--StartFragment-->") : end]
+		}
+	} else if strings.HasPrefix(source, "Version:") {
+		if start := strings.Index(source, "<"); start >= 0 {
+			source = source[start:]
+		}
+	}
+	root, err := nethtml.Parse(strings.NewReader(source))
+	if err != nil {
+		return nil, fmt.Errorf("解析HTML失败: %v", err)
+	}
+	c := &htmlConverter{}
+	c.walk(root, CharFormat{}, 0, false)
+	c.flush()
+	return c.paragraphs, nil
+}
+
+// walk 递归转换HTML节点，format 和 props 是外层元素的字符格式，pre 表示保留空白
+func (c *htmlConverter) walk(n *nethtml.Node, format CharFormat, props CharProperty, pre bool) {
+	switch n.Type {
+	case nethtml.TextNode:
+		c.text(n.Data, format, props, pre)
+		return
+	case nethtml.DocumentNode:
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			c.walk(child, format, props, pre)
+		}
+		return
+	case nethtml.ElementNode:
+	default:
+		return
+	}
+
+	block, level := false, 0
+	outerLink := c.link
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head, atom.Title, atom.Template, atom.Noscript:
+		return
+	case atom.Br:
+		c.text("\n", format, props, true)
+		return
+	case atom.B, atom.Strong:
+		format.Bold, props = true, props|CharBold
+	case atom.I, atom.Em, atom.Cite:
+		format.Italic, props = true, props|CharItalic
+	case atom.U, atom.Ins:
+		format.Underline, props = "single", props|CharUnderline
+	case atom.S, atom.Strike, atom.Del:
+		format.Strike, props = true, props|CharStrike
+	case atom.Mark:
+		format.Highlight, props = "yellow", props|CharHighlight
+	case atom.Pre:
+		pre, block = true, true
+	case atom.A:
+		if href := strings.TrimSpace(htmlAttr(n, "href")); href != "" && !strings.HasPrefix(strings.ToLower(href), "javascript:") {
+			c.link = href
+		}
+	case atom.Font:
+		if color, ok := parseCSSColor(htmlAttr(n, "color")); ok {
+			format.Color, props = color, props|CharColor
+		}
+		if face := htmlAttr(n, "face"); face != "" {
+			format, props = applyFontFamily(format, props, face)
+		}
+		if size, err := strconv.Atoi(htmlAttr(n, "size")); err == nil && size >= 1 && size <= len(htmlFontSizes) {
+			format.Size, props = htmlFontSizes[size-1], props|CharSize
+		}
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		block, level = true, int(n.Data[1]-'0')
+	case atom.P, atom.Div, atom.Li, atom.Tr, atom.Blockquote, atom.Section, atom.Article,
+		atom.Header, atom.Footer, atom.Dt, atom.Dd, atom.Caption, atom.Figcaption, atom.Address:
+		block = true
+	case atom.Td, atom.Th:
+		// 单元格之间用制表符分隔
+		if c.current != nil && len(c.current.runs) > 0 {
+			c.text("\t", format, props, true)
+		}
+	}
+	format, props, pre = applyInlineStyle(htmlAttr(n, "style"), format, props, pre)
+
+	if block {
+		c.flush()
+		c.level = level
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.walk(child, format, props, pre)
+	}
+	if block {
+		c.flush()
+		c.level = 0
+	}
+	c.link = outerLink
+}
+
+// text 加入文字，不保留空白时按HTML的规则合并空白
+func (c *htmlConverter) text(s string, format CharFormat, props CharProperty, pre bool) {
+	if !pre {
+		s = collapseSpace(s)
+	}
+	if c.current == nil {
+		if !pre && strings.TrimSpace(s) == "" {
+			return
+		}
+		c.current = &htmlParagraph{level: c.level}
+	}
+	if !pre && (len(c.current.runs) == 0 || strings.HasSuffix(c.lastText(), " ")) {
+		s = strings.TrimLeft(s, " ")
+	}
+	if s == "" {
+		return
+	}
+	runs := c.current.runs
+	if n := len(runs); n > 0 && runs[n-1].format == format && runs[n-1].props == props && runs[n-1].link == c.link {
+		runs[n-1].text += s
+		return
+	}
+	c.current.runs = append(runs, htmlRun{text: s, format: format, props: props, link: c.link})
+}
+
+// collapseSpace 将连续的空白合并为一个空格
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// lastText 返回当前段落最后一段文字
+func (c *htmlConverter) lastText() string {
+	if c.current == nil || len(c.current.runs) == 0 {
+		return ""
+	}
+	return c.current.runs[len(c.current.runs)-1].text
+}
+
+// flush 结束当前段落，去掉段落末尾的空格
+func (c *htmlConverter) flush() {
+	if c.current == nil {
+		return
+	}
+	runs := c.current.runs
+	for len(runs) > 0 {
+		last := &runs[len(runs)-1]
+		last.text = strings.TrimRight(last.text, " ")
+		if last.text != "" {
+			break
+		}
+		runs = runs[:len(runs)-1]
+	}
+	c.current.runs = runs
+	c.paragraphs = append(c.paragraphs, *c.current)
+	c.current = nil
+}
+
+// htmlAttr 返回HTML元素的属性值
+func htmlAttr(n *nethtml.Node, name string) string {
+	for _, attr := range n.Attr {
+		if strings.EqualFold(attr.Key, name) {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// applyInlineStyle 按元素的 style 属性修改字符格式和空白处理方式
+func applyInlineStyle(style string, format CharFormat, props CharProperty, pre bool) (CharFormat, CharProperty, bool) {
+	for _, declaration := range strings.Split(style, ";") {
+		name, value, ok := strings.Cut(declaration, ":")
+		if !ok {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+		lower := strings.ToLower(value)
+		switch name {
+		case "font-weight":
+			weight, err := strconv.Atoi(lower)
+			format.Bold = lower == "bold" || lower == "bolder" || (err == nil && weight >= 600)
+			props |= CharBold
+		case "font-style":
+			format.Italic = lower == "italic" || lower == "oblique"
+			props |= CharItalic
+		case "text-decoration", "text-decoration-line":
+			if strings.Contains(lower, "underline") {
+				format.Underline, props = "single", props|CharUnderline
+			}
+			if strings.Contains(lower, "line-through") {
+				format.Strike, props = true, props|CharStrike
+			}
+			if lower == "none" {
+				format.Underline, format.Strike = "", false
+				props |= CharUnderline | CharStrike
+			}
+		case "color":
+			if color, ok := parseCSSColor(lower); ok {
+				format.Color, props = color, props|CharColor
+			}
+		case "background-color", "background":
+			if color, ok := parseCSSColor(lower); ok {
+				for name, hex := range highlightHex {
+					if hex == color && name != "white" {
+						format.Highlight, props = name, props|CharHighlight
+					}
+				}
+			}
+		case "font-size":
+			if size, ok := parseCSSLength(lower); ok {
+				format.Size, props = size, props|CharSize
+			}
+		case "font-family":
+			format, props = applyFontFamily(format, props, value)
+		case "white-space":
+			pre = strings.HasPrefix(lower, "pre")
+		}
+	}
+	return format, props, pre
+}
+
+// applyFontFamily 使用字体列表中的第一个字体，名称含中文时作为中文字体
+func applyFontFamily(format CharFormat, props CharProperty, families string) (CharFormat, CharProperty) {
+	family, _, _ := strings.Cut(families, ",")
+	family = strings.Trim(strings.TrimSpace(family), "'\"")
+	if family == "" {
+		return format, props
+	}
+	for _, r := range family {
+		if r > unicode.MaxASCII {
+			format.EastAsiaFont = family
+			return format, props | CharEastAsiaFont
+		}
+	}
+	format.Font = family
+	return format, props | CharFont
+}
+
+// parseCSSColor 解析 #RGB、#RRGGBB、rgb() 或颜色名称，返回 RRGGBB
+func parseCSSColor(value string) (string, bool) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	if hex, ok := cssColorNames[value]; ok {
+		return hex, true
+	}
+	if strings.HasPrefix(value, "#") {
+		hex := value[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if hexColorPattern.MatchString(hex) {
+			return strings.ToUpper(hex), true
+		}
+		return "", false
+	}
+	if strings.HasPrefix(value, "rgb(") || strings.HasPrefix(value, "rgba(") {
+		inner := value[strings.Index(value, "(")+1 : len(strings.TrimSuffix(value, ")"))]
+		parts := strings.Split(inner, ",")
+		if len(parts) < 3 {
+			return "", false
+		}
+		var hex strings.Builder
+		for _, part := range parts[:3] {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || n < 0 || n > 255 {
+				return "", false
+			}
+			fmt.Fprintf(&hex, "%02X", n)
+		}
+		return hex.String(), true
+	}
+	return "", false
+}
+
+// parseCSSLength 解析以 pt 或 px 为单位的字号，返回磅
+func parseCSSLength(value string) (float64, bool) {
+	unit := 1.0
+	switch {
+	case strings.HasSuffix(value, "pt"):
+		value = strings.TrimSuffix(value, "pt")
+	case strings.HasSuffix(value, "px"):
+		value, unit = strings.TrimSuffix(value, "px"), 0.75
+	default:
+		return 0, false
+	}
+	size, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || size <= 0 {
+		return 0, false
+	}
+	return math.Round(size*unit*2) / 2, true
+}
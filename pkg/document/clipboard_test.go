@@ -0,0 +1,119 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCopyRange(t *testing.T) {
+	doc := newBodyTestDocument(t, styledParagraph("Heading2", "标题")+
+		`<w:p><w:r><w:t>正</w:t></w:r><w:r><w:rPr><w:b/><w:color w:val="FF0000"/></w:rPr><w:t>文&lt;</w:t></w:r>`+
+		`<w:del `+testDel+`><w:r><w:delText>删除</w:delText></w:r></w:del>`+
+		`<w:r><w:rPr><w:highlight w:val="yellow"/><w:sz w:val="21"/></w:rPr><w:t>一</w:t><w:br/><w:t>二</w:t></w:r>`+
+		`<w:r><w:footnoteReference w:id="1"/></w:r><w:r><w:t>尾</w:t></w:r></w:p>`)
+
+	content, err := doc.CopyRange(TextRange{0, 1, 1, 6})
+	if err != nil {
+		t.Fatalf("复制失败: %v", err)
+	}
+	if want := "题\n正文<一\n二"; content.Text != want {
+		t.Errorf("纯文本 %q, 期望 %q", content.Text, want)
+	}
+	want := "<html><head><meta charset=\"utf-8\"></head><body>\n<!--StartFragment-->" +
+		`<h2 style="white-space:pre-wrap">题</h2><p style="white-space:pre-wrap">正` +
+		`<span style="font-weight:bold;color:#FF0000">文&lt;</span>` +
+		`<span style="font-size:10.5pt;background-color:#FFFF00">一<br>二</span></p>` +
+		"<!--EndFragment-->\n</body></html>"
+	if content.HTML != want {
+		t.Errorf("HTML %s, 期望 %s", content.HTML, want)
+	}
+
+	if _, err := doc.CopyRange(TextRange{1, 0, 0, 0}); err == nil {
+		t.Errorf("无效的范围没有返回错误")
+	}
+}
+
+func TestParseHTMLParagraphs(t *testing.T) {
+	bold := CharFormat{Bold: true}
+	tests := []struct {
+		name   string
+		source string
+		want   []htmlParagraph
+	}{
+		{"合并空白", "<p>  一 \n  二  </p>", []htmlParagraph{{runs: []htmlRun{{text: "一 二"}}}}},
+		{"字符格式标签", "<p>普通<b>粗<i>斜</i></b></p>", []htmlParagraph{{runs: []htmlRun{
+			{text: "普通"},
+			{text: "粗", format: bold, props: CharBold},
+			{text: "斜", format: CharFormat{Bold: true, Italic: true}, props: CharBold | CharItalic},
+		}}}},
+		{"内联样式", `<span style="font-weight:700;color:rgb(255, 0, 0);font-size:16px;font-family:'宋体', serif">文字</span>`,
+			[]htmlParagraph{{runs: []htmlRun{{text: "文字",
+				format: CharFormat{Bold: true, Color: "FF0000", Size: 12, EastAsiaFont: "宋体"},
+				props:  CharBold | CharColor | CharSize | CharEastAsiaFont}}}}},
+		{"取消格式", `<b><span style="font-weight:normal">文字</span></b>`,
+			[]htmlParagraph{{runs: []htmlRun{{text: "文字", props: CharBold}}}}},
+		{"font元素", `<font color="#f00" face="Arial" size="5">文字</font>`,
+			[]htmlParagraph{{runs: []htmlRun{{text: "文字",
+				format: CharFormat{Color: "FF0000", Font: "Arial", Size: 18}, props: CharColor | CharFont | CharSize}}}}},
+		{"背景色转换为突出显示", `<span style="background-color:#ffff00">文字</span>`,
+			[]htmlParagraph{{runs: []htmlRun{{text: "文字", format: CharFormat{Highlight: "yellow"}, props: CharHighlight}}}}},
+		{"标题和段落", "<h2>标题</h2><div>正文<br>第二行</div>", []htmlParagraph{
+			{level: 2, runs: []htmlRun{{text: "标题"}}},
+			{runs: []htmlRun{{text: "正文\n第二行"}}},
+		}},
+		{"保留空白", "<pre>a  b\n c</pre>", []htmlParagraph{{runs: []htmlRun{{text: "a  b\n c"}}}}},
+		{"表格", "<table><tr><td>一</td><td>二</td></tr><tr><th>三</th></tr></table>", []htmlParagraph{
+			{runs: []htmlRun{{text: "一\t二"}}},
+			{runs: []htmlRun{{text: "三"}}},
+		}},
+		{"忽略脚本和样式", "<style>p{}</style><script>x()</script><p>正文</p>", []htmlParagraph{{runs: []htmlRun{{text: "正文"}}}}},
+		{"剪贴板片段", "Version:0.9\r\n<html><body>外<!--StartFragment--><b>内</b><!--EndFragment--></body></html>",
+			[]htmlParagraph{{runs: []htmlRun{{text: "内", format: bold, props: CharBold}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHTMLParagraphs(tt.source)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("解析结果 %+v, 期望 %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsertHTML(t *testing.T) {
+	// 粘贴到段落中间时第一段和最后一段与原来的文字合并
+	doc := newTestDocument(t, "前后")
+	pos, err := doc.InsertHTML(TextPosition{0, 1}, "<h1>标题</h1><p><b>粗</b>体</p>")
+	if err != nil {
+		t.Fatalf("粘贴失败: %v", err)
+	}
+	if got, want := describeParagraphs(doc), [][]string{{"前标题"}, {"*粗", "体后"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("粘贴后的Run %q, 期望 %q", got, want)
+	}
+	if pos != (TextPosition{1, 2}) {
+		t.Errorf("粘贴后的位置 %+v", pos)
+	}
+	if got := paragraphStyle(doc.paragraphNodes()[0]); got != "" {
+		t.Errorf("不完整的段落使用了标题样式 %q", got)
+	}
+
+	// 完整粘贴的段落使用来源的标题级别
+	doc = newTestDocument(t, "")
+	if _, err := doc.InsertHTML(TextPosition{0, 0}, "<h1>标题</h1><p>正文</p>"); err != nil {
+		t.Fatalf("粘贴失败: %v", err)
+	}
+	paragraphs := doc.paragraphNodes()
+	if got := paragraphStyle(paragraphs[0]); got != "Heading1" {
+		t.Errorf("标题段落的样式 %q, 期望 Heading1", got)
+	}
+	if got := paragraphStyle(paragraphs[1]); got != "" {
+		t.Errorf("正文段落的样式 %q", got)
+	}
+
+	if _, err := doc.InsertHTML(TextPosition{0, 3}, "文字"); err == nil {
+		t.Errorf("超出段落范围的位置没有返回错误")
+	}
+}
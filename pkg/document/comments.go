@@ -0,0 +1,577 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 批注相关的部件类型和命名空间
+const (
+	relTypeComments             = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments"
+	relTypeCommentsExtended     = "http://schemas.microsoft.com/office/2011/relationships/commentsExtended"
+	contentTypeComments         = "application/vnd.openxmlformats-officedocument.wordprocessingml.comments+xml"
+	contentTypeCommentsExtended = "application/vnd.openxmlformats-officedocument.wordprocessingml.commentsExtended+xml"
+
+	nsW14          = "http://schemas.microsoft.com/office/word/2010/wordml"
+	nsW15          = "http://schemas.microsoft.com/office/word/2012/wordml"
+	nsMarkupCompat = "http://schemas.openxmlformats.org/markup-compatibility/2006"
+)
+
+// Comment 文档批注
+type Comment struct {
+	ID         string
+	Author     string
+	Initials   string
+	Date       time.Time // 批注时间，文档未记录时为零值
+	Text       string
+	Done       bool      // 是否已解决（commentsExtended 中的 w15:done）
+	ParentID   string    // 回复所属批注的ID，顶层批注为空
+	Paragraph  int       // 批注范围开始的顶层段落索引，找不到时为-1
+	AnchorText string    // 批注范围内的文字
+	Replies    []Comment // 对该批注的回复，按时间顺序排列
+}
+
+// commentExtension commentsExtended 中记录的批注扩展信息
+type commentExtension struct {
+	done       bool
+	parentPara string
+}
+
+// Comments 返回文档中的批注，回复放在所属批注的 Replies 中
+func (doc *Document) Comments() ([]Comment, error) {
+	root, err := doc.commentsRoot(false)
+	if err != nil || root == nil {
+		return nil, err
+	}
+
+	extensions := doc.commentExtensions()
+	anchors := map[string]*commentAnchor{}
+	if body, err := doc.body(); err == nil {
+		anchors = commentAnchors(body)
+	}
+
+	var all []Comment
+	paraIDs := make(map[string]string) // w14:paraId → 批注ID
+	parentParas := make(map[string]string)
+	for _, node := range root.ChildrenNamed("w:comment") {
+		c := Comment{
+			ID:        node.Attr("w:id"),
+			Author:    node.Attr("w:author"),
+			Initials:  node.Attr("w:initials"),
+			Text:      commentText(node),
+			Paragraph: -1,
+		}
+		if date := node.Attr("w:date"); date != "" {
+			if t, err := time.Parse(time.RFC3339, date); err == nil {
+				c.Date = t
+			}
+		}
+		if anchor, ok := anchors[c.ID]; ok {
+			c.Paragraph = anchor.paragraph
+			c.AnchorText = strings.TrimSpace(anchor.text.String())
+		}
+		if paraID := commentParaID(node); paraID != "" {
+			paraIDs[paraID] = c.ID
+			if ext, ok := extensions[paraID]; ok {
+				c.Done = ext.done
+				parentParas[c.ID] = ext.parentPara
+			}
+		}
+		all = append(all, c)
+	}
+	for i := range all {
+		if parentPara := parentParas[all[i].ID]; parentPara != "" {
+			all[i].ParentID = paraIDs[parentPara]
+		}
+	}
+
+	// 组织为批注和回复两级，找不到所属批注的回复作为顶层批注显示
+	var threads []Comment
+	position := make(map[string]int)
+	for _, c := range all {
+		if i, ok := position[c.ParentID]; ok && c.ParentID != "" {
+			threads[i].Replies = append(threads[i].Replies, c)
+			continue
+		}
+		position[c.ID] = len(threads)
+		threads = append(threads, c)
+	}
+	return threads, nil
+}
+
+// CommentsByParagraph 返回按锚点段落分组的批注
+func (doc *Document) CommentsByParagraph() map[int][]Comment {
+	comments, _ := doc.Comments()
+	result := make(map[int][]Comment)
+	for _, c := range comments {
+		result[c.Paragraph] = append(result[c.Paragraph], c)
+	}
+	return result
+}
+
+// AddComment 为整个段落添加批注，返回新批注的ID
+func (doc *Document) AddComment(paragraph int, author, text string) (string, error) {
+	paragraphs := doc.paragraphNodes()
+	if paragraph < 0 || paragraph >= len(paragraphs) {
+		return "", fmt.Errorf("段落索引超出范围: %d", paragraph+1)
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("批注内容不能为空")
+	}
+
+	root, err := doc.commentsRoot(true)
+	if err != nil {
+		return "", err
+	}
+	id := nextCommentID(root)
+	root.AppendChild(newCommentNode(id, author, text, newParaID(root)))
+
+	// 批注范围覆盖整个段落，引用标记放在段落末尾
+	p := paragraphs[paragraph]
+	position := 0
+	if p.Child("w:pPr") != nil {
+		position = 1
+	}
+	p.InsertChild(position, NewNode("w:commentRangeStart", "w:id", id))
+	p.AppendChild(NewNode("w:commentRangeEnd", "w:id", id), newCommentReference(id))
+
+	doc.IsModified = true
+	log.Printf("已在段落 %d 添加批注: %s", paragraph+1, truncateText(text, 30))
+	return id, nil
+}
+
+// ReplyComment 回复批注，回复与原批注使用相同的范围，返回新批注的ID
+func (doc *Document) ReplyComment(parentID, author, text string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("回复内容不能为空")
+	}
+	root, err := doc.commentsRoot(false)
+	if err != nil {
+		return "", err
+	}
+	parent := findComment(root, parentID)
+	if parent == nil {
+		return "", fmt.Errorf("批注不存在: %s", parentID)
+	}
+
+	parentPara := ensureCommentParaID(root, parent)
+	id := nextCommentID(root)
+	paraID := newParaID(root)
+
+	// 回复放在已有的回复之后，保持时间顺序
+	thread := map[string]bool{parentID: true}
+	index := root.IndexOf(parent)
+	extensions := doc.commentExtensions()
+	for _, node := range root.ChildrenNamed("w:comment") {
+		if ext, ok := extensions[commentParaID(node)]; ok && ext.parentPara == parentPara {
+			thread[node.Attr("w:id")] = true
+			index = max(index, root.IndexOf(node))
+		}
+	}
+	root.InsertChild(index+1, newCommentNode(id, author, text, paraID))
+
+	ext, err := doc.commentsExtendedRoot(true)
+	if err != nil {
+		return "", err
+	}
+	ensureCommentExtension(ext, parentPara)
+	entry := ensureCommentExtension(ext, paraID)
+	entry.SetAttr("w15:paraIdParent", parentPara)
+
+	// 在原批注及已有回复的最后一个范围标记和引用之后添加回复的范围标记和引用
+	if body, err := doc.body(); err == nil {
+		last := make(map[string]nodeRef)
+		body.Walk(func(node, p *Node) bool {
+			switch node.Name {
+			case "w:commentRangeStart", "w:commentRangeEnd":
+				if thread[node.Attr("w:id")] && p != nil {
+					last[node.Name] = nodeRef{node: node, parent: p}
+				}
+			case "w:r":
+				if ref := node.Child("w:commentReference"); ref != nil && thread[ref.Attr("w:id")] && p != nil {
+					last["w:commentReference"] = nodeRef{node: node, parent: p}
+				}
+				return false
+			}
+			return true
+		})
+		for name, ref := range last {
+			marker := newCommentReference(id)
+			if name != "w:commentReference" {
+				marker = NewNode(name, "w:id", id)
+			}
+			ref.parent.InsertChild(ref.parent.IndexOf(ref.node)+1, marker)
+		}
+	}
+
+	doc.IsModified = true
+	log.Printf("已回复批注 %s: %s", parentID, truncateText(text, 30))
+	return id, nil
+}
+
+// ResolveComment 将批注标记为已解决或重新打开
+func (doc *Document) ResolveComment(id string, done bool) error {
+	root, err := doc.commentsRoot(false)
+	if err != nil {
+		return err
+	}
+	comment := findComment(root, id)
+	if comment == nil {
+		return fmt.Errorf("批注不存在: %s", id)
+	}
+
+	paraID := ensureCommentParaID(root, comment)
+	ext, err := doc.commentsExtendedRoot(true)
+	if err != nil {
+		return err
+	}
+	value, action := "0", "重新打开"
+	if done {
+		value, action = "1", "解决"
+	}
+	ensureCommentExtension(ext, paraID).SetAttr("w15:done", value)
+
+	doc.IsModified = true
+	log.Printf("批注 %s 已%s", id, action)
+	return nil
+}
+
+// DeleteComment 删除批注及其所有回复，同时删除正文中的范围标记和引用
+func (doc *Document) DeleteComment(id string) error {
+	root, err := doc.commentsRoot(false)
+	if err != nil {
+		return err
+	}
+	if findComment(root, id) == nil {
+		return fmt.Errorf("批注不存在: %s", id)
+	}
+
+	// 找出批注及其回复
+	ext, _ := doc.commentsExtendedRoot(false)
+	parents := make(map[string]string) // paraId → 所属批注的 paraId
+	if ext != nil {
+		for _, entry := range ext.ChildrenNamed("w15:commentEx") {
+			parents[entry.Attr("w15:paraId")] = entry.Attr("w15:paraIdParent")
+		}
+	}
+	removed := map[string]bool{id: true}
+	removedParas := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, node := range root.ChildrenNamed("w:comment") {
+			commentID := node.Attr("w:id")
+			paraID := commentParaID(node)
+			if removed[commentID] && paraID != "" && !removedParas[paraID] {
+				removedParas[paraID] = true
+				changed = true
+			}
+			if !removed[commentID] && paraID != "" && removedParas[parents[paraID]] {
+				removed[commentID] = true
+				changed = true
+			}
+		}
+	}
+
+	for _, node := range root.ChildrenNamed("w:comment") {
+		if removed[node.Attr("w:id")] {
+			root.RemoveChild(node)
+		}
+	}
+	if ext != nil {
+		for _, entry := range ext.ChildrenNamed("w15:commentEx") {
+			if removedParas[entry.Attr("w15:paraId")] {
+				ext.RemoveChild(entry)
+			}
+		}
+	}
+
+	if body, err := doc.body(); err == nil {
+		for commentID := range removed {
+			for _, run := range commentReferenceRuns(body, commentID) {
+				run.parent.RemoveChild(run.node)
+			}
+		}
+		body.Walk(func(node, parent *Node) bool {
+			if parent != nil && removed[node.Attr("w:id")] &&
+				(node.Name == "w:commentRangeStart" || node.Name == "w:commentRangeEnd") {
+				parent.RemoveChild(node)
+			}
+			return true
+		})
+	}
+
+	doc.IsModified = true
+	log.Printf("已删除批注 %s 及 %d 条回复", id, len(removed)-1)
+	return nil
+}
+
+// commentsRoot 返回批注部件的根节点，create 为真时在不存在时创建
+func (doc *Document) commentsRoot(create bool) (*Node, error) {
+	if doc.Package == nil {
+		return nil, fmt.Errorf("文档不支持批注")
+	}
+	pkg := doc.Package
+	if name, ok := pkg.RelatedPart(pkg.MainPartName(), relTypeComments); ok && pkg.HasPart(name) {
+		root, err := pkg.XML(name)
+		if err == nil && !root.HasAttr("xmlns:w14") {
+			root.SetAttr("xmlns:w14", nsW14)
+		}
+		return root, err
+	}
+	if !create {
+		return nil, nil
+	}
+
+	_, root, err := pkg.ensureRelatedPart(relTypeComments, "word/comments.xml", contentTypeComments, func() *Node {
+		return NewNode("w:comments", "xmlns:w", nsWordML, "xmlns:w14", nsW14)
+	})
+	return root, err
+}
+
+// commentsExtendedRoot 返回批注扩展部件的根节点，create 为真时在不存在时创建
+func (doc *Document) commentsExtendedRoot(create bool) (*Node, error) {
+	pkg := doc.Package
+	if name, ok := pkg.RelatedPart(pkg.MainPartName(), relTypeCommentsExtended); ok && pkg.HasPart(name) {
+		return pkg.XML(name)
+	}
+	if !create {
+		return nil, nil
+	}
+
+	_, root, err := pkg.ensureRelatedPart(relTypeCommentsExtended, "word/commentsExtended.xml",
+		contentTypeCommentsExtended, func() *Node {
+			return NewNode("w15:commentsEx",
+				"xmlns:mc", nsMarkupCompat, "xmlns:w15", nsW15, "mc:Ignorable", "w15")
+		})
+	return root, err
+}
+
+// commentExtensions 读取批注扩展信息，键为批注最后一个段落的 w14:paraId
+func (doc *Document) commentExtensions() map[string]commentExtension {
+	extensions := make(map[string]commentExtension)
+	ext, err := doc.commentsExtendedRoot(false)
+	if err != nil || ext == nil {
+		return extensions
+	}
+	for _, entry := range ext.ChildrenNamed("w15:commentEx") {
+		extensions[entry.Attr("w15:paraId")] = commentExtension{
+			done:       entry.Attr("w15:done") == "1" || entry.Attr("w15:done") == "true",
+			parentPara: entry.Attr("w15:paraIdParent"),
+		}
+	}
+	return extensions
+}
+
+// commentText 返回批注的文字，多个段落以换行分隔
+func commentText(comment *Node) string {
+	var lines []string
+	for _, p := range comment.ChildrenNamed("w:p") {
+		lines = append(lines, paragraphNodeText(p))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// commentParaID 返回批注最后一个段落的 w14:paraId
+func commentParaID(comment *Node) string {
+	paragraphs := comment.ChildrenNamed("w:p")
+	if len(paragraphs) == 0 {
+		return ""
+	}
+	return paragraphs[len(paragraphs)-1].Attr("w14:paraId")
+}
+
+// ensureCommentParaID 确保批注最后一个段落有 w14:paraId 并返回它
+func ensureCommentParaID(root, comment *Node) string {
+	if paraID := commentParaID(comment); paraID != "" {
+		return paraID
+	}
+	paragraphs := comment.ChildrenNamed("w:p")
+	if len(paragraphs) == 0 {
+		comment.AppendChild(NewNode("w:p"))
+		paragraphs = comment.ChildrenNamed("w:p")
+	}
+	paraID := newParaID(root)
+	paragraphs[len(paragraphs)-1].SetAttr("w14:paraId", paraID)
+	return paraID
+}
+
+// ensureCommentExtension 返回指定 paraId 的批注扩展条目，不存在时创建
+func ensureCommentExtension(ext *Node, paraID string) *Node {
+	for _, entry := range ext.ChildrenNamed("w15:commentEx") {
+		if entry.Attr("w15:paraId") == paraID {
+			return entry
+		}
+	}
+	entry := NewNode("w15:commentEx", "w15:paraId", paraID, "w15:done", "0")
+	ext.AppendChild(entry)
+	return entry
+}
+
+// findComment 按ID查找批注节点
+func findComment(root *Node, id string) *Node {
+	if root == nil {
+		return nil
+	}
+	for _, node := range root.ChildrenNamed("w:comment") {
+		if node.Attr("w:id") == id {
+			return node
+		}
+	}
+	return nil
+}
+
+// nextCommentID 返回未使用的批注ID
+func nextCommentID(root *Node) string {
+	next := 0
+	for _, node := range root.ChildrenNamed("w:comment") {
+		if id, err := strconv.Atoi(node.Attr("w:id")); err == nil && id >= next {
+			next = id + 1
+		}
+	}
+	return strconv.Itoa(next)
+}
+
+// newParaID 生成部件中未使用的 w14:paraId（8位十六进制，小于0x80000000）
+func newParaID(root *Node) string {
+	used := make(map[string]bool)
+	root.Walk(func(node, parent *Node) bool {
+		if id := node.Attr("w14:paraId"); id != "" {
+			used[id] = true
+		}
+		return true
+	})
+	for {
+		id := fmt.Sprintf("%08X", rand.Uint32N(0x7FFFFFFF)+1)
+		if !used[id] {
+			return id
+		}
+	}
+}
+
+// newCommentNode 创建批注节点，文字中的换行拆分为多个段落
+func newCommentNode(id, author, text, paraID string) *Node {
+	comment := NewNode("w:comment",
+		"w:id", id,
+		"w:author", author,
+		"w:date", time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		"w:initials", authorInitials(author))
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		p := NewNode("w:p")
+		if i == 0 {
+			ref := NewNode("w:r")
+			ref.AppendChild(NewNode("w:annotationRef"))
+			p.AppendChild(ref)
+		}
+		if line != "" {
+			p.AppendChild(newRunNode(line, nil))
+		}
+		if i == len(lines)-1 {
+			p.SetAttr("w14:paraId", paraID)
+		}
+		comment.AppendChild(p)
+	}
+	return comment
+}
+
+// newCommentReference 创建正文中的批注引用Run
+func newCommentReference(id string) *Node {
+	r := NewNode("w:r")
+	rPr := NewNode("w:rPr")
+	rPr.AppendChild(NewNode("w:rStyle", "w:val", "CommentReference"))
+	r.AppendChild(rPr)
+	r.AppendChild(NewNode("w:commentReference", "w:id", id))
+	return r
+}
+
+// authorInitials 取作者名称的首字符作为缩写
+func authorInitials(author string) string {
+	for _, ch := range author {
+		return string(ch)
+	}
+	return ""
+}
+
+// nodeRef 节点及其父节点
+type nodeRef struct {
+	node, parent *Node
+}
+
+// commentReferenceRuns 查找正文中引用指定批注的Run
+func commentReferenceRuns(body *Node, id string) []nodeRef {
+	var runs []nodeRef
+	body.Walk(func(node, parent *Node) bool {
+		if node.Name != "w:r" {
+			return true
+		}
+		if ref := node.Child("w:commentReference"); ref != nil && ref.Attr("w:id") == id {
+			runs = append(runs, nodeRef{node: node, parent: parent})
+		}
+		return false
+	})
+	return runs
+}
+
+// commentAnchor 批注范围所在的段落和文字
+type commentAnchor struct {
+	paragraph int
+	text      strings.Builder
+}
+
+// commentAnchors 扫描正文，返回每个批注的范围
+func commentAnchors(body *Node) map[string]*commentAnchor {
+	indexes := make(map[*Node]int)
+	for i, p := range collectParagraphs(body) {
+		indexes[p] = i
+	}
+
+	anchors := make(map[string]*commentAnchor)
+	active := make(map[string]*commentAnchor)
+	var visit func(n *Node, paragraph int)
+	visit = func(n *Node, paragraph int) {
+		for _, c := range n.Children {
+			switch c.Name {
+			case "w:p":
+				index := paragraph
+				if i, ok := indexes[c]; ok {
+					index = i
+				}
+				visit(c, index)
+				for _, anchor := range active {
+					anchor.text.WriteString("\n")
+				}
+			case "w:commentRangeStart":
+				anchor := &commentAnchor{paragraph: paragraph}
+				anchors[c.Attr("w:id")] = anchor
+				active[c.Attr("w:id")] = anchor
+			case "w:commentRangeEnd":
+				delete(active, c.Attr("w:id"))
+			case "w:commentReference":
+				if _, ok := anchors[c.Attr("w:id")]; !ok {
+					anchors[c.Attr("w:id")] = &commentAnchor{paragraph: paragraph}
+				}
+			case "w:del", "w:moveFrom":
+				// 删除的文字不计入批注范围
+			case "w:t":
+				for _, anchor := range active {
+					anchor.text.WriteString(c.InnerText())
+				}
+			case "w:tab":
+				for _, anchor := range active {
+					anchor.text.WriteString("\t")
+				}
+			default:
+				if c.IsElement() {
+					visit(c, paragraph)
+				}
+			}
+		}
+	}
+	visit(body, -1)
+	return anchors
+}
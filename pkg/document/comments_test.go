@@ -0,0 +1,189 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+// commentMarkers 返回正文中批注范围标记和引用的ID，按出现顺序排列
+func commentMarkers(t *testing.T, doc *Document) []string {
+	t.Helper()
+	var markers []string
+	testBody(t, doc).Walk(func(node, _ *Node) bool {
+		switch node.Name {
+		case "w:commentRangeStart":
+			markers = append(markers, "["+node.Attr("w:id"))
+		case "w:commentRangeEnd":
+			markers = append(markers, node.Attr("w:id")+"]")
+		case "w:commentReference":
+			markers = append(markers, "*"+node.Attr("w:id"))
+		}
+		return true
+	})
+	return markers
+}
+
+func TestAddComment(t *testing.T) {
+	doc := newBodyTestDocument(t, `<w:p><w:r><w:t>第一段</w:t></w:r></w:p>`+
+		`<w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t>第二段</w:t></w:r></w:p>`)
+	id, err := doc.AddComment(1, "张三", "第一行\n第二行")
+	if err != nil {
+		t.Fatalf("添加批注失败: %v", err)
+	}
+	comments, err := doc.Comments()
+	if err != nil || len(comments) != 1 {
+		t.Fatalf("批注 %+v (%v)", comments, err)
+	}
+	c := comments[0]
+	if c.ID != id || c.Author != "张三" || c.Initials != "张" || c.Text != "第一行\n第二行" {
+		t.Errorf("批注内容 %+v", c)
+	}
+	if c.Paragraph != 1 || c.AnchorText != "第二段" {
+		t.Errorf("批注范围在段落 %d: %q, 期望段落 2: %q", c.Paragraph, c.AnchorText, "第二段")
+	}
+	if c.Date.IsZero() || c.Done || c.ParentID != "" {
+		t.Errorf("新批注的状态 %+v", c)
+	}
+
+	// 范围开始标记位于段落属性之后
+	p := doc.paragraphNodes()[1]
+	if p.Elements()[0].Name != "w:pPr" || p.Elements()[1].Name != "w:commentRangeStart" {
+		t.Errorf("批注范围开始标记的位置错误: %s", p)
+	}
+	if got, want := commentMarkers(t, doc), []string{"[" + id, id + "]", "*" + id}; !reflect.DeepEqual(got, want) {
+		t.Errorf("正文中的批注标记 %q, 期望 %q", got, want)
+	}
+	if got := doc.CommentsByParagraph(); len(got[1]) != 1 {
+		t.Errorf("按段落分组的批注 %+v", got)
+	}
+
+	if _, err := doc.AddComment(2, "张三", "批注"); err == nil {
+		t.Errorf("为不存在的段落添加批注没有返回错误")
+	}
+	if _, err := doc.AddComment(0, "张三", "  "); err == nil {
+		t.Errorf("添加空白批注没有返回错误")
+	}
+}
+
+func TestReplyComment(t *testing.T) {
+	doc := newTestDocument(t, "正文")
+	id, err := doc.AddComment(0, "张三", "问题")
+	if err != nil {
+		t.Fatalf("添加批注失败: %v", err)
+	}
+	first, err := doc.ReplyComment(id, "李四", "回答")
+	if err != nil {
+		t.Fatalf("回复批注失败: %v", err)
+	}
+	second, err := doc.ReplyComment(id, "张三", "谢谢")
+	if err != nil {
+		t.Fatalf("回复批注失败: %v", err)
+	}
+
+	comments, _ := doc.Comments()
+	if len(comments) != 1 {
+		t.Fatalf("顶层批注 %d 条, 期望 1 条", len(comments))
+	}
+	replies := comments[0].Replies
+	if len(replies) != 2 {
+		t.Fatalf("回复 %d 条, 期望 2 条", len(replies))
+	}
+	if replies[0].ID != first || replies[1].ID != second {
+		t.Errorf("回复的顺序 %s, %s, 期望 %s, %s", replies[0].ID, replies[1].ID, first, second)
+	}
+	for _, reply := range replies {
+		if reply.ParentID != id || reply.AnchorText != "正文" {
+			t.Errorf("回复 %+v 没有关联到批注 %s", reply, id)
+		}
+	}
+
+	// 回复通过 w15:paraIdParent 关联到原批注最后一个段落的 w14:paraId
+	root, _ := doc.commentsRoot(false)
+	ext, _ := doc.commentsExtendedRoot(false)
+	if ext == nil {
+		t.Fatalf("没有创建 commentsExtended 部件")
+	}
+	parentPara := commentParaID(findComment(root, id))
+	for _, reply := range []string{first, second} {
+		paraID := commentParaID(findComment(root, reply))
+		if paraID == "" || paraID == parentPara {
+			t.Fatalf("回复 %s 的 paraId %q", reply, paraID)
+		}
+		if got := ensureCommentExtension(ext, paraID).Attr("w15:paraIdParent"); got != parentPara {
+			t.Errorf("回复 %s 的 paraIdParent %q, 期望 %q", reply, got, parentPara)
+		}
+	}
+
+	want := []string{"[" + id, "[" + first, "[" + second, id + "]", first + "]", second + "]", "*" + id, "*" + first, "*" + second}
+	if got := commentMarkers(t, doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("正文中的批注标记 %q, 期望 %q", got, want)
+	}
+	if _, err := doc.ReplyComment("99", "李四", "回答"); err == nil {
+		t.Errorf("回复不存在的批注没有返回错误")
+	}
+}
+
+func TestResolveComment(t *testing.T) {
+	// 批注段落没有 paraId 时解决批注会为它添加
+	doc := newBodyTestDocument(t, `<w:p><w:r><w:t>正文</w:t></w:r></w:p>`)
+	id, err := doc.AddComment(0, "张三", "问题")
+	if err != nil {
+		t.Fatalf("添加批注失败: %v", err)
+	}
+	root, _ := doc.commentsRoot(false)
+	for _, p := range findComment(root, id).ChildrenNamed("w:p") {
+		p.RemoveAttr("w14:paraId")
+	}
+
+	for _, done := range []bool{true, false} {
+		if err := doc.ResolveComment(id, done); err != nil {
+			t.Fatalf("设置批注状态失败: %v", err)
+		}
+		comments, _ := doc.Comments()
+		if comments[0].Done != done {
+			t.Errorf("批注的解决状态 %v, 期望 %v", comments[0].Done, done)
+		}
+	}
+	ext, _ := doc.commentsExtendedRoot(false)
+	if n := len(ext.ChildrenNamed("w15:commentEx")); n != 1 {
+		t.Errorf("commentsExtended 中有 %d 个条目, 期望 1 个", n)
+	}
+	if err := doc.ResolveComment("99", true); err == nil {
+		t.Errorf("解决不存在的批注没有返回错误")
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	doc := newTestDocument(t, "第一段", "第二段")
+	first, _ := doc.AddComment(0, "张三", "第一条")
+	reply, _ := doc.ReplyComment(first, "李四", "回复")
+	second, _ := doc.AddComment(1, "王五", "第二条")
+	if err := doc.ResolveComment(second, true); err != nil {
+		t.Fatalf("解决批注失败: %v", err)
+	}
+
+	if err := doc.DeleteComment(first); err != nil {
+		t.Fatalf("删除批注失败: %v", err)
+	}
+	comments, _ := doc.Comments()
+	if len(comments) != 1 || comments[0].ID != second || !comments[0].Done {
+		t.Fatalf("删除后的批注 %+v", comments)
+	}
+	if got, want := commentMarkers(t, doc), []string{"[" + second, second + "]", "*" + second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("删除后正文中的批注标记 %q, 期望 %q", got, want)
+	}
+	root, _ := doc.commentsRoot(false)
+	if findComment(root, reply) != nil {
+		t.Errorf("删除批注后回复仍然存在")
+	}
+	ext, _ := doc.commentsExtendedRoot(false)
+	if n := len(ext.ChildrenNamed("w15:commentEx")); n != 1 {
+		t.Errorf("删除后 commentsExtended 中有 %d 个条目, 期望 1 个", n)
+	}
+	if got := paragraphTexts(doc); !reflect.DeepEqual(got, []string{"第一段", "第二段"}) {
+		t.Errorf("删除批注后的段落 %q", got)
+	}
+	if err := doc.DeleteComment(first); err == nil {
+		t.Errorf("删除不存在的批注没有返回错误")
+	}
+}
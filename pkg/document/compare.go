@@ -0,0 +1,520 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// similarityThreshold 删除和插入的段落相似度达到该值时视为同一段落被修改
+const similarityThreshold = 0.5
+
+// ChangeType 段落变化类型
+type ChangeType int
+
+const (
+	ChangeUnchanged ChangeType = iota // 未变化
+	ChangeInserted                    // 新增段落
+	ChangeDeleted                     // 删除段落
+	ChangeModified                    // 文字被修改
+	ChangeFormatted                   // 仅格式变化
+)
+
+// String 返回变化类型的中文名称
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeInserted:
+		return "插入"
+	case ChangeDeleted:
+		return "删除"
+	case ChangeModified:
+		return "修改"
+	case ChangeFormatted:
+		return "格式"
+	default:
+		return "未变化"
+	}
+}
+
+// DiffOp 字词级差异操作
+type DiffOp int
+
+const (
+	DiffEqual  DiffOp = iota // 相同文字
+	DiffDelete               // 删除的文字
+	DiffInsert               // 插入的文字
+)
+
+// DiffSegment 段落内的一段差异文字
+type DiffSegment struct {
+	Op   DiffOp
+	Text string
+}
+
+// FormatChange 一段文字的格式变化
+type FormatChange struct {
+	Text     string // 受影响的文字，段落样式变化时为空
+	Property string // 变化的属性，如"样式"、"加粗"
+	Old      string
+	New      string
+}
+
+// String 返回格式变化的文字描述
+func (fc FormatChange) String() string {
+	if fc.Text == "" {
+		return fmt.Sprintf("%s: %s → %s", fc.Property, fc.Old, fc.New)
+	}
+	return fmt.Sprintf("“%s” %s: %s → %s", fc.Text, fc.Property, fc.Old, fc.New)
+}
+
+// ParagraphChange 两个文档之间一个段落的比较结果
+type ParagraphChange struct {
+	Type     ChangeType
+	OldIndex int // 原文档中的段落索引，新增段落为-1
+	NewIndex int // 新文档中的段落索引，删除段落为-1
+	OldText  string
+	NewText  string
+	Segments []DiffSegment  // 修改段落的字词级差异
+	Formats  []FormatChange // 格式变化（样式、加粗等）
+}
+
+// CompareStats 比较结果统计
+type CompareStats struct {
+	Inserted  int
+	Deleted   int
+	Modified  int
+	Formatted int
+}
+
+// Comparison 两个文档的比较结果
+type Comparison struct {
+	OldName string
+	NewName string
+	Changes []ParagraphChange
+}
+
+// Stats 统计各类变化的段落数量
+func (c *Comparison) Stats() CompareStats {
+	var stats CompareStats
+	for _, change := range c.Changes {
+		switch change.Type {
+		case ChangeInserted:
+			stats.Inserted++
+		case ChangeDeleted:
+			stats.Deleted++
+		case ChangeModified:
+			stats.Modified++
+		case ChangeFormatted:
+			stats.Formatted++
+		}
+	}
+	return stats
+}
+
+// HasChanges 判断两个文档是否存在差异
+func (c *Comparison) HasChanges() bool {
+	stats := c.Stats()
+	return stats.Inserted+stats.Deleted+stats.Modified+stats.Formatted > 0
+}
+
+// CompareSource 比较的文档来源：已打开的文档或磁盘上的文件
+type CompareSource struct {
+	Doc  *Document
+	Path string
+}
+
+// Name 返回来源的显示名称
+func (s CompareSource) Name() string {
+	if s.Doc != nil {
+		return s.Doc.FileName
+	}
+	return s.Path
+}
+
+// load 读取来源文档的段落
+//
+// 已打开的文档和磁盘上的文件都从OPC包解析段落，两侧的文字（包括超链接中的文字）、
+// 字体和颜色按同样的规则读取，避免解析方式不同产生虚假的差异。
+func (s CompareSource) load() ([]types.Paragraph, error) {
+	pkg, err := s.openPackage()
+	if err != nil {
+		return nil, err
+	}
+	return packageParagraphs(pkg), nil
+}
+
+// openPackage 返回来源文档的OPC包，已打开的文档返回它的包本身
+func (s CompareSource) openPackage() (*Package, error) {
+	if s.Doc != nil && s.Doc.Package != nil {
+		return s.Doc.Package, nil
+	}
+	filePath := s.Path
+	if s.Doc != nil {
+		filePath = s.Doc.FilePath
+	}
+	if filePath == "" {
+		return nil, fmt.Errorf("请选择要比较的文档")
+	}
+	if !isWordDocument(filePath) {
+		return nil, fmt.Errorf("不支持的文件格式: %s", filePath)
+	}
+	return OpenPackage(filePath)
+}
+
+// Compare 比较两个来源的文档
+func Compare(oldSrc, newSrc CompareSource) (*Comparison, error) {
+	oldParas, err := oldSrc.load()
+	if err != nil {
+		return nil, fmt.Errorf("读取原文档失败: %v", err)
+	}
+	newParas, err := newSrc.load()
+	if err != nil {
+		return nil, fmt.Errorf("读取新文档失败: %v", err)
+	}
+
+	log.Printf("正在比较文档: %s ↔ %s", oldSrc.Name(), newSrc.Name())
+	comparison := CompareParagraphs(oldParas, newParas)
+	comparison.OldName = oldSrc.Name()
+	comparison.NewName = newSrc.Name()
+	return comparison, nil
+}
+
+// CompareDocuments 比较两个已打开的文档
+func (m *Manager) CompareDocuments(oldDoc, newDoc *Document) (*Comparison, error) {
+	if oldDoc == nil || newDoc == nil {
+		return nil, fmt.Errorf("请选择要比较的两个文档")
+	}
+	return Compare(CompareSource{Doc: oldDoc}, CompareSource{Doc: newDoc})
+}
+
+// CompareFiles 比较磁盘上的两个文档，文档不会加入管理器
+func CompareFiles(oldPath, newPath string) (*Comparison, error) {
+	return Compare(CompareSource{Path: oldPath}, CompareSource{Path: newPath})
+}
+
+// packageParagraphs 读取文档包中的顶层正文段落
+func packageParagraphs(pkg *Package) []types.Paragraph {
+	nodes := (&Document{Package: pkg}).paragraphNodes()
+	paragraphs := make([]types.Paragraph, len(nodes))
+	for i, p := range nodes {
+		paragraphs[i] = paragraphFromNode(p)
+	}
+	return paragraphs
+}
+
+// CompareParagraphs 逐段对齐两组段落，并对修改过的段落计算字词级差异
+func CompareParagraphs(oldParas, newParas []types.Paragraph) *Comparison {
+	oldTexts := make([]string, len(oldParas))
+	for i, p := range oldParas {
+		oldTexts[i] = paragraphText(p)
+	}
+	newTexts := make([]string, len(newParas))
+	for i, p := range newParas {
+		newTexts[i] = paragraphText(p)
+	}
+
+	comparison := &Comparison{}
+	edits := diffStrings(oldTexts, newTexts)
+
+	var deleted, inserted []int
+	flush := func() {
+		comparison.Changes = append(comparison.Changes,
+			pairChangedParagraphs(oldParas, newParas, oldTexts, newTexts, deleted, inserted)...)
+		deleted, inserted = deleted[:0], inserted[:0]
+	}
+
+	for _, e := range edits {
+		switch e.op {
+		case opDelete:
+			deleted = append(deleted, e.oldIndex)
+		case opInsert:
+			inserted = append(inserted, e.newIndex)
+		case opEqual:
+			flush()
+			change := ParagraphChange{
+				Type:     ChangeUnchanged,
+				OldIndex: e.oldIndex,
+				NewIndex: e.newIndex,
+				OldText:  oldTexts[e.oldIndex],
+				NewText:  newTexts[e.newIndex],
+				Segments: []DiffSegment{{Op: DiffEqual, Text: newTexts[e.newIndex]}},
+			}
+			change.Formats = compareFormats(oldParas[e.oldIndex], newParas[e.newIndex], change.Segments)
+			if len(change.Formats) > 0 {
+				change.Type = ChangeFormatted
+			}
+			comparison.Changes = append(comparison.Changes, change)
+		}
+	}
+	flush()
+
+	return comparison
+}
+
+// pairChangedParagraphs 将相邻的删除和插入段落按相似度配对为修改段落
+func pairChangedParagraphs(oldParas, newParas []types.Paragraph, oldTexts, newTexts []string, deleted, inserted []int) []ParagraphChange {
+	var changes []ParagraphChange
+
+	insertedChange := func(j int) ParagraphChange {
+		return ParagraphChange{
+			Type:     ChangeInserted,
+			OldIndex: -1,
+			NewIndex: j,
+			NewText:  newTexts[j],
+			Segments: []DiffSegment{{Op: DiffInsert, Text: newTexts[j]}},
+		}
+	}
+
+	next := 0
+	for _, i := range deleted {
+		match := -1
+		var segments []DiffSegment
+		for k := next; k < len(inserted); k++ {
+			segs, similarity := diffParagraphText(oldTexts[i], newTexts[inserted[k]])
+			if similarity >= similarityThreshold {
+				match, segments = k, segs
+				break
+			}
+		}
+
+		if match < 0 {
+			changes = append(changes, ParagraphChange{
+				Type:     ChangeDeleted,
+				OldIndex: i,
+				NewIndex: -1,
+				OldText:  oldTexts[i],
+				Segments: []DiffSegment{{Op: DiffDelete, Text: oldTexts[i]}},
+			})
+			continue
+		}
+
+		for ; next < match; next++ {
+			changes = append(changes, insertedChange(inserted[next]))
+		}
+		j := inserted[match]
+		next = match + 1
+
+		changes = append(changes, ParagraphChange{
+			Type:     ChangeModified,
+			OldIndex: i,
+			NewIndex: j,
+			OldText:  oldTexts[i],
+			NewText:  newTexts[j],
+			Segments: segments,
+			Formats:  compareFormats(oldParas[i], newParas[j], segments),
+		})
+	}
+	for ; next < len(inserted); next++ {
+		changes = append(changes, insertedChange(inserted[next]))
+	}
+
+	return changes
+}
+
+// diffParagraphText 计算两个段落文本的字词级差异及相似度（0~1）
+func diffParagraphText(oldText, newText string) ([]DiffSegment, float64) {
+	oldTokens := splitDiffTokens(oldText)
+	newTokens := splitDiffTokens(newText)
+
+	var segments []DiffSegment
+	common := 0
+	appendSegment := func(op DiffOp, text string) {
+		if n := len(segments); n > 0 && segments[n-1].Op == op {
+			segments[n-1].Text += text
+			return
+		}
+		segments = append(segments, DiffSegment{Op: op, Text: text})
+	}
+
+	for _, e := range diffStrings(oldTokens, newTokens) {
+		switch e.op {
+		case opEqual:
+			common += utf8.RuneCountInString(oldTokens[e.oldIndex])
+			appendSegment(DiffEqual, oldTokens[e.oldIndex])
+		case opDelete:
+			appendSegment(DiffDelete, oldTokens[e.oldIndex])
+		case opInsert:
+			appendSegment(DiffInsert, newTokens[e.newIndex])
+		}
+	}
+
+	total := utf8.RuneCountInString(oldText) + utf8.RuneCountInString(newText)
+	if total == 0 {
+		return segments, 1
+	}
+	return segments, float64(2*common) / float64(total)
+}
+
+// runFormat 单个字符的格式
+type runFormat struct {
+	Bold      bool
+	Italic    bool
+	Underline bool
+	FontSize  int
+	FontName  string
+	Color     string
+}
+
+// formatProperty 可比较的格式属性
+type formatProperty struct {
+	name  string
+	value func(f runFormat) string
+}
+
+// formatProperties 参与比较的字符格式属性
+var formatProperties = []formatProperty{
+	{"加粗", func(f runFormat) string { return yesNo(f.Bold) }},
+	{"倾斜", func(f runFormat) string { return yesNo(f.Italic) }},
+	{"下划线", func(f runFormat) string { return yesNo(f.Underline) }},
+	{"字号", func(f runFormat) string {
+		if f.FontSize == 0 {
+			return "默认"
+		}
+		// w:sz 以半磅为单位
+		return fmt.Sprintf("%g磅", float64(f.FontSize)/2)
+	}},
+	{"字体", func(f runFormat) string { return valueOrDefault(f.FontName) }},
+	{"颜色", func(f runFormat) string { return valueOrDefault(f.Color) }},
+}
+
+// compareFormats 比较两个段落中相同文字的格式，包括段落样式
+func compareFormats(oldPara, newPara types.Paragraph, segments []DiffSegment) []FormatChange {
+	var changes []FormatChange
+
+	if oldPara.Style != newPara.Style {
+		changes = append(changes, FormatChange{
+			Property: "样式",
+			Old:      valueOrDefault(oldPara.Style),
+			New:      valueOrDefault(newPara.Style),
+		})
+	}
+
+	oldRunes, oldFormats := runeFormats(oldPara)
+	newRunes, newFormats := runeFormats(newPara)
+
+	// 只比较未被修改的文字，按差异片段同步两侧的字符位置
+	type alignedRune struct {
+		r        rune
+		old, new runFormat
+	}
+	var aligned []alignedRune
+	breakAlignment := func() {
+		aligned = append(aligned, alignedRune{r: -1})
+	}
+
+	oldPos, newPos := 0, 0
+	for _, seg := range segments {
+		n := utf8.RuneCountInString(seg.Text)
+		switch seg.Op {
+		case DiffEqual:
+			for k := 0; k < n && oldPos < len(oldRunes) && newPos < len(newRunes); k++ {
+				aligned = append(aligned, alignedRune{
+					r:   newRunes[newPos],
+					old: oldFormats[oldPos],
+					new: newFormats[newPos],
+				})
+				oldPos++
+				newPos++
+			}
+		case DiffDelete:
+			oldPos += n
+			breakAlignment()
+		case DiffInsert:
+			newPos += n
+			breakAlignment()
+		}
+	}
+
+	for _, prop := range formatProperties {
+		var text []rune
+		var oldVal, newVal string
+		flush := func() {
+			if len(text) > 0 {
+				changes = append(changes, FormatChange{
+					Text:     string(text),
+					Property: prop.name,
+					Old:      oldVal,
+					New:      newVal,
+				})
+				text = text[:0]
+			}
+		}
+
+		for _, a := range aligned {
+			if a.r < 0 {
+				flush()
+				continue
+			}
+			o, n := prop.value(a.old), prop.value(a.new)
+			if o == n {
+				flush()
+				continue
+			}
+			if len(text) > 0 && (o != oldVal || n != newVal) {
+				flush()
+			}
+			oldVal, newVal = o, n
+			text = append(text, a.r)
+		}
+		flush()
+	}
+
+	return changes
+}
+
+// runeFormats 展开段落中每个字符的格式
+func runeFormats(p types.Paragraph) ([]rune, []runFormat) {
+	var runes []rune
+	var formats []runFormat
+	if len(p.Runs) == 0 {
+		runes = []rune(p.Text)
+		formats = make([]runFormat, len(runes))
+		return runes, formats
+	}
+	for _, run := range p.Runs {
+		f := runFormat{
+			Bold:      run.Bold,
+			Italic:    run.Italic,
+			Underline: run.Underline,
+			FontSize:  run.FontSize,
+			FontName:  run.FontName,
+			Color:     run.Color,
+		}
+		for _, r := range run.Text {
+			runes = append(runes, r)
+			formats = append(formats, f)
+		}
+	}
+	return runes, formats
+}
+
+// paragraphText 获取段落文本，优先拼接各个Run的文本
+func paragraphText(p types.Paragraph) string {
+	if len(p.Runs) == 0 {
+		return p.Text
+	}
+	var builder strings.Builder
+	for _, run := range p.Runs {
+		builder.WriteString(run.Text)
+	}
+	return builder.String()
+}
+
+// yesNo 将布尔值转换为"是/否"
+func yesNo(b bool) string {
+	if b {
+		return "是"
+	}
+	return "否"
+}
+
+// valueOrDefault 空值显示为"默认"
+func valueOrDefault(s string) string {
+	if s == "" {
+		return "默认"
+	}
+	return s
+}
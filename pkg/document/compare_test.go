@@ -0,0 +1,183 @@
+package document
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// textParagraphs 用文字创建没有格式的段落
+func textParagraphs(texts ...string) []types.Paragraph {
+	paras := make([]types.Paragraph, len(texts))
+	for i, text := range texts {
+		paras[i] = types.Paragraph{Text: text}
+	}
+	return paras
+}
+
+// changeString 将比较结果转换为变化序列，每个段落一个字符:
+// = 未变化, + 插入, - 删除, ~ 修改, f 格式
+func changeString(c *Comparison) string {
+	var sb strings.Builder
+	for _, change := range c.Changes {
+		sb.WriteByte("=+-~f"[change.Type])
+	}
+	return sb.String()
+}
+
+func TestSplitDiffTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"hello world", []string{"hello", " ", "world"}},
+		{"Go语言v2", []string{"Go", "语", "言", "v2"}},
+		{"a,b", []string{"a", ",", "b"}},
+		{"  两个空格", []string{"  ", "两", "个", "空", "格"}},
+		{"café Привет", []string{"café", " ", "Привет"}},
+	}
+	for _, tt := range tests {
+		if got := splitDiffTokens(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitDiffTokens(%q) = %q, 期望 %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCompareParagraphsAlignment(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new []string
+		want     string
+	}{
+		{"空文档", nil, nil, ""},
+		{"相同", []string{"甲", "乙"}, []string{"甲", "乙"}, "=="},
+		{"新增文档", nil, []string{"甲", "乙"}, "++"},
+		{"清空文档", []string{"甲", "乙"}, nil, "--"},
+		{"中间插入", []string{"第一段", "第三段"}, []string{"第一段", "第二段", "第三段"}, "=+="},
+		{"中间删除", []string{"第一段", "第二段", "第三段"}, []string{"第一段", "第三段"}, "=-="},
+		{"相似段落配对为修改", []string{"今天天气很好"}, []string{"今天天气不错"}, "~"},
+		{"不相似段落为删除和插入", []string{"今天天气很好"}, []string{"明年再说吧"}, "-+"},
+		{"修改前插入", []string{"保留", "the quick brown fox"},
+			[]string{"保留", "全新的段落", "the quick red fox"}, "=+~"},
+		{"修改后删除", []string{"the quick brown fox", "多余", "结尾"},
+			[]string{"the quick red fox", "结尾"}, "~-="},
+		{"段落移动", []string{"甲", "乙", "丙"}, []string{"乙", "丙", "甲"}, "-==+"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison := CompareParagraphs(textParagraphs(tt.old...), textParagraphs(tt.new...))
+			if got := changeString(comparison); got != tt.want {
+				t.Fatalf("变化序列 %q, 期望 %q", got, tt.want)
+			}
+
+			// 索引依次覆盖两个文档的所有段落
+			oldPos, newPos := 0, 0
+			for _, change := range comparison.Changes {
+				if change.OldIndex >= 0 {
+					if change.OldIndex != oldPos || change.OldText != tt.old[oldPos] {
+						t.Fatalf("原段落索引 %d, 期望 %d", change.OldIndex, oldPos)
+					}
+					oldPos++
+				}
+				if change.NewIndex >= 0 {
+					if change.NewIndex != newPos || change.NewText != tt.new[newPos] {
+						t.Fatalf("新段落索引 %d, 期望 %d", change.NewIndex, newPos)
+					}
+					newPos++
+				}
+			}
+			if oldPos != len(tt.old) || newPos != len(tt.new) {
+				t.Fatalf("比较结果覆盖了 %d/%d 个原段落和 %d/%d 个新段落", oldPos, len(tt.old), newPos, len(tt.new))
+			}
+			if comparison.HasChanges() != (strings.Trim(tt.want, "=") != "") {
+				t.Errorf("HasChanges() = %v", comparison.HasChanges())
+			}
+		})
+	}
+}
+
+func TestCompareParagraphsSegments(t *testing.T) {
+	comparison := CompareParagraphs(textParagraphs("the quick brown fox"), textParagraphs("the quick red fox"))
+	want := []DiffSegment{
+		{DiffEqual, "the quick "},
+		{DiffDelete, "brown"},
+		{DiffInsert, "red"},
+		{DiffEqual, " fox"},
+	}
+	if got := comparison.Changes[0].Segments; !reflect.DeepEqual(got, want) {
+		t.Errorf("差异片段 %+v, 期望 %+v", got, want)
+	}
+}
+
+func TestCompareParagraphsFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new types.Paragraph
+		want     []FormatChange
+	}{
+		{
+			"段落样式",
+			types.Paragraph{Text: "标题", Style: "Heading1"},
+			types.Paragraph{Text: "标题", Style: "Heading2"},
+			[]FormatChange{{Property: "样式", Old: "Heading1", New: "Heading2"}},
+		},
+		{
+			"部分文字加粗",
+			types.Paragraph{Runs: []types.Run{{Text: "加粗的文字"}}},
+			types.Paragraph{Runs: []types.Run{{Text: "加粗", Bold: true}, {Text: "的文字"}}},
+			[]FormatChange{{Text: "加粗", Property: "加粗", Old: "否", New: "是"}},
+		},
+		{
+			"字号",
+			types.Paragraph{Runs: []types.Run{{Text: "文字", FontSize: 21}}},
+			types.Paragraph{Runs: []types.Run{{Text: "文字", FontSize: 28}}},
+			[]FormatChange{{Text: "文字", Property: "字号", Old: "10.5磅", New: "14磅"}},
+		},
+		{
+			"格式相同",
+			types.Paragraph{Runs: []types.Run{{Text: "文", Italic: true}, {Text: "字", Italic: true}}},
+			types.Paragraph{Runs: []types.Run{{Text: "文字", Italic: true}}},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison := CompareParagraphs([]types.Paragraph{tt.old}, []types.Paragraph{tt.new})
+			change := comparison.Changes[0]
+			wantType := ChangeFormatted
+			if tt.want == nil {
+				wantType = ChangeUnchanged
+			}
+			if change.Type != wantType {
+				t.Errorf("变化类型 %v, 期望 %v", change.Type, wantType)
+			}
+			if !reflect.DeepEqual(change.Formats, tt.want) {
+				t.Errorf("格式变化 %+v, 期望 %+v", change.Formats, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareSavedDocument(t *testing.T) {
+	doc := newBodyTestDocument(t, `<w:p><w:r><w:rPr><w:rFonts w:ascii="Arial" w:eastAsia="宋体"/><w:color w:val="auto"/></w:rPr><w:t>正文</w:t></w:r>`+
+		`<w:hyperlink w:anchor="书签"><w:r><w:t>链接文字</w:t></w:r></w:hyperlink></w:p>`)
+	path := filepath.Join(t.TempDir(), "保存.docx")
+	if err := doc.Package.Save(path); err != nil {
+		t.Fatalf("保存测试文档失败: %v", err)
+	}
+
+	comparison, err := Compare(CompareSource{Doc: doc}, CompareSource{Path: path})
+	if err != nil {
+		t.Fatalf("比较文档失败: %v", err)
+	}
+	if comparison.HasChanges() {
+		t.Errorf("比较打开的文档和它保存的文件得到变化 %+v", comparison.Changes)
+	}
+	if got := comparison.Changes[0].NewText; got != "正文链接文字" {
+		t.Errorf("文件中的段落文字 %q, 期望 %q", got, "正文链接文字")
+	}
+}
@@ -0,0 +1,495 @@
+package document
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 电子表格中查找部件使用的关系类型后缀，兼容严格模式的命名空间
+const (
+	relSuffixWorksheet     = "/worksheet"
+	relSuffixSharedStrings = "/sharedStrings"
+	relSuffixStyles        = "/styles"
+)
+
+// utf8BOM Excel等程序保存的UTF-8文本开头的字节顺序标记
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// csvDelimiters 自动识别的CSV分隔符
+var csvDelimiters = []rune{',', ';', '\t'}
+
+// DataSource 邮件合并的数据源，第一行为列名，其余每行为一条记录
+type DataSource struct {
+	Name    string     // 文件名，电子表格还包括工作表名称
+	Columns []string   // 列名
+	Records [][]string // 记录，每条记录的值与列一一对应
+}
+
+// Len 返回记录数量
+func (s *DataSource) Len() int {
+	return len(s.Records)
+}
+
+// Record 返回一条记录中列名到值的映射，index 从0开始
+func (s *DataSource) Record(index int) map[string]string {
+	values := make(map[string]string, len(s.Columns))
+	if index < 0 || index >= len(s.Records) {
+		return values
+	}
+	for i, column := range s.Columns {
+		if i < len(s.Records[index]) {
+			values[column] = s.Records[index][i]
+		}
+	}
+	return values
+}
+
+// MissingColumns 返回数据源中没有对应列的合并域名称，列名不区分大小写
+func (s *DataSource) MissingColumns(names []string) []string {
+	var missing []string
+	for _, name := range names {
+		found := false
+		for _, column := range s.Columns {
+			if strings.EqualFold(column, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// LoadDataSource 读取CSV或XLSX文件作为邮件合并的数据源
+//
+// CSV文件可以是UTF-8（带或不带BOM）或GB18030编码，分隔符自动识别为逗号、分号或制表符。
+// XLSX文件读取 sheet 指定的工作表，为空时读取第一个工作表，日期单元格转换为日期文字。
+func LoadDataSource(path, sheet string) (*DataSource, error) {
+	var (
+		source *DataSource
+		err    error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".txt":
+		source, err = loadCSV(path)
+	case ".xlsx", ".xlsm":
+		source, err = loadWorkbook(path, sheet)
+	default:
+		return nil, fmt.Errorf("不支持的数据源格式: %s", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("已读取数据源: %s，%d 列，%d 条记录", source.Name, len(source.Columns), len(source.Records))
+	return source, nil
+}
+
+// loadCSV 读取CSV文件
+func loadCSV(path string) (*DataSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取数据源失败: %v", err)
+	}
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if !utf8.Valid(data) {
+		if data, err = simplifiedchinese.GB18030.NewDecoder().Bytes(data); err != nil {
+			return nil, fmt.Errorf("无法识别CSV文件的编码: %v", err)
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析CSV失败: %v", err)
+		}
+		rows = append(rows, row)
+	}
+	return newDataSource(filepath.Base(path), rows)
+}
+
+// detectDelimiter 按第一行中出现次数最多的分隔符识别CSV的分隔符，引号中的字符不计
+func detectDelimiter(data []byte) rune {
+	counts := make(map[rune]int)
+	quoted := false
+	for _, r := range string(data) {
+		if r == '"' {
+			quoted = !quoted
+		} else if !quoted && (r == '\n' || r == '\r') {
+			break
+		} else if !quoted {
+			counts[r]++
+		}
+	}
+	best := csvDelimiters[0]
+	for _, d := range csvDelimiters[1:] {
+		if counts[d] > counts[best] {
+			best = d
+		}
+	}
+	return best
+}
+
+// newDataSource 以第一个非空行为列名创建数据源，忽略空行，没有列名的列以列号命名
+func newDataSource(name string, rows [][]string) (*DataSource, error) {
+	blank := func(row []string) bool {
+		for _, value := range row {
+			if strings.TrimSpace(value) != "" {
+				return false
+			}
+		}
+		return true
+	}
+	source := &DataSource{Name: name}
+	for _, row := range rows {
+		if blank(row) {
+			continue
+		}
+		if source.Columns == nil {
+			for i, column := range row {
+				column = strings.TrimSpace(column)
+				if column == "" {
+					column = fmt.Sprintf("列%d", i+1)
+				}
+				source.Columns = append(source.Columns, column)
+			}
+			continue
+		}
+		record := make([]string, len(source.Columns))
+		copy(record, row)
+		source.Records = append(source.Records, record)
+	}
+	if source.Columns == nil {
+		return nil, fmt.Errorf("数据源 %s 没有内容", name)
+	}
+	return source, nil
+}
+
+// workbook 打开的XLSX工作簿
+type workbook struct {
+	pkg      *Package
+	part     string
+	sheets   []workbookSheet
+	strings  []string
+	dates    map[int]bool // 日期格式的单元格样式序号
+	date1904 bool
+}
+
+// workbookSheet 工作簿中的工作表
+type workbookSheet struct {
+	name, part string
+}
+
+// WorkbookSheets 返回XLSX文件中工作表的名称，按工作簿中的顺序排列
+func WorkbookSheets(path string) ([]string, error) {
+	book, err := openWorkbook(path)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, sheet := range book.sheets {
+		names = append(names, sheet.name)
+	}
+	return names, nil
+}
+
+// openWorkbook 读取工作簿的工作表列表、共享字符串和日期样式
+func openWorkbook(path string) (*workbook, error) {
+	pkg, err := OpenPackage(path)
+	if err != nil {
+		return nil, err
+	}
+	book := &workbook{pkg: pkg, dates: make(map[int]bool)}
+	for _, rel := range pkg.Relationships("") {
+		if rel.Type == relTypeOfficeDocument || strings.HasSuffix(rel.Type, "/officeDocument") {
+			book.part = strings.TrimPrefix(rel.Target, "/")
+			break
+		}
+	}
+	if book.part == "" {
+		return nil, fmt.Errorf("%s 不是有效的Excel工作簿", filepath.Base(path))
+	}
+	root, err := pkg.XML(book.part)
+	if err != nil {
+		return nil, fmt.Errorf("读取工作簿失败: %v", err)
+	}
+
+	for _, n := range root.Elements() {
+		switch localName(n.Name) {
+		case "workbookPr":
+			book.date1904 = n.Attr("date1904") == "1" || n.Attr("date1904") == "true"
+		case "sheets":
+			for _, s := range n.Elements() {
+				var id string
+				for _, attr := range s.Attrs {
+					if localName(attr.Name) == "id" && attr.Name != "id" {
+						id = attr.Value
+					}
+				}
+				if rel, ok := pkg.Relationship(book.part, id); ok && strings.HasSuffix(rel.Type, relSuffixWorksheet) {
+					book.sheets = append(book.sheets, workbookSheet{s.Attr("name"), pkg.ResolveTarget(book.part, rel.Target)})
+				}
+			}
+		}
+	}
+	if len(book.sheets) == 0 {
+		return nil, fmt.Errorf("工作簿中没有工作表")
+	}
+
+	for _, rel := range pkg.Relationships(book.part) {
+		if rel.External {
+			continue
+		}
+		part, err := pkg.XML(pkg.ResolveTarget(book.part, rel.Target))
+		if err != nil {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(rel.Type, relSuffixSharedStrings):
+			for _, si := range part.Elements() {
+				book.strings = append(book.strings, cellText(si))
+			}
+		case strings.HasSuffix(rel.Type, relSuffixStyles):
+			book.readDateStyles(part)
+		}
+	}
+	return book, nil
+}
+
+// cellText 返回共享字符串或内联字符串中的文字，不包括拼音注音
+func cellText(n *Node) string {
+	var builder strings.Builder
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for _, c := range n.Elements() {
+			switch localName(c.Name) {
+			case "t":
+				builder.WriteString(c.InnerText())
+			case "rPh":
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return builder.String()
+}
+
+// builtinDateFormats Excel内置的日期和时间数字格式，包括中文区域的格式
+var builtinDateFormats = map[int]bool{
+	14: true, 15: true, 16: true, 17: true, 18: true, 19: true, 20: true, 21: true, 22: true,
+	27: true, 28: true, 29: true, 30: true, 31: true, 32: true, 33: true, 34: true, 35: true, 36: true,
+	45: true, 46: true, 47: true,
+	50: true, 51: true, 52: true, 53: true, 54: true, 55: true, 56: true, 57: true, 58: true,
+}
+
+// formatLiteralPattern 数字格式中不影响类型判断的部分：引号中的文字、方括号中的颜色和区域、转义字符
+var formatLiteralPattern = regexp.MustCompile(`"[^"]*"|\[[^\]]*\]|\\.`)
+
+// readDateStyles 找出数字格式为日期或时间的单元格样式
+func (b *workbook) readDateStyles(styles *Node) {
+	custom := make(map[int]bool)
+	for _, n := range styles.Elements() {
+		switch localName(n.Name) {
+		case "numFmts":
+			for _, f := range n.Elements() {
+				id, err := strconv.Atoi(f.Attr("numFmtId"))
+				if err != nil {
+					continue
+				}
+				code := strings.ToLower(formatLiteralPattern.ReplaceAllString(f.Attr("formatCode"), ""))
+				custom[id] = strings.ContainsAny(code, "ymdhs")
+			}
+		case "cellXfs":
+			for i, xf := range n.Elements() {
+				id, err := strconv.Atoi(xf.Attr("numFmtId"))
+				if err != nil {
+					continue
+				}
+				if isDate, ok := custom[id]; ok {
+					b.dates[i] = isDate
+				} else {
+					b.dates[i] = builtinDateFormats[id]
+				}
+			}
+		}
+	}
+}
+
+// loadWorkbook 读取XLSX文件中的工作表
+func loadWorkbook(path, sheet string) (*DataSource, error) {
+	book, err := openWorkbook(path)
+	if err != nil {
+		return nil, err
+	}
+	target := book.sheets[0]
+	if sheet != "" {
+		found := false
+		for _, s := range book.sheets {
+			if s.name == sheet {
+				target, found = s, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("工作簿中没有工作表: %s", sheet)
+		}
+	}
+	root, err := book.pkg.XML(target.part)
+	if err != nil {
+		return nil, fmt.Errorf("读取工作表失败: %v", err)
+	}
+
+	var rows [][]string
+	for _, data := range root.Elements() {
+		if localName(data.Name) != "sheetData" {
+			continue
+		}
+		for _, row := range data.Elements() {
+			var values []string
+			for _, c := range row.Elements() {
+				column := len(values)
+				if ref := c.Attr("r"); ref != "" {
+					column = columnIndex(ref)
+				}
+				for len(values) <= column {
+					values = append(values, "")
+				}
+				values[column] = book.cellValue(c)
+			}
+			rows = append(rows, values)
+		}
+	}
+	return newDataSource(fmt.Sprintf("%s [%s]", filepath.Base(path), target.name), rows)
+}
+
+// columnIndex 返回单元格引用（如 AB12）中列字母对应的序号，从0开始
+func columnIndex(ref string) int {
+	column := 0
+	for _, r := range strings.ToUpper(ref) {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A'+1)
+	}
+	return column - 1
+}
+
+// cellValue 返回单元格显示的值：字符串、布尔值、数字或日期
+func (b *workbook) cellValue(c *Node) string {
+	var value string
+	for _, n := range c.Elements() {
+		switch localName(n.Name) {
+		case "v":
+			value = n.InnerText()
+		case "is":
+			value = cellText(n)
+		}
+	}
+	switch c.Attr("t") {
+	case "s":
+		if i, err := strconv.Atoi(value); err == nil && i >= 0 && i < len(b.strings) {
+			return b.strings[i]
+		}
+		return ""
+	case "b":
+		if value == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	case "inlineStr", "str", "e":
+		return value
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	if style, err := strconv.Atoi(c.Attr("s")); err == nil && b.dates[style] {
+		return b.dateValue(number)
+	}
+	// 按15位有效数字输出，避免二进制浮点数的误差，例如 0.30000000000000004
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(number, 'g', 15, 64), 64)
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
+
+// dateValue 将Excel的日期序列数转换为日期文字，带时间的值包括时间，只有时间的值只输出时间
+func (b *workbook) dateValue(serial float64) string {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if b.date1904 {
+		base = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 24 * 60 * 60)
+	t := base.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+	switch {
+	case days == 0 && !b.date1904:
+		return t.Format("15:04:05")
+	case seconds == 0:
+		return t.Format("2006-01-02")
+	default:
+		return t.Format("2006-01-02 15:04:05")
+	}
+}
+
+// ParseRecordRange 解析以逗号分隔的记录范围，如 "1-10,15"，返回从0开始的记录序号
+//
+// 记录号从1开始，超出 total 的部分被忽略，spec 为空时返回所有记录。
+func ParseRecordRange(spec string, total int) ([]int, error) {
+	var records []int
+	if strings.TrimSpace(spec) == "" {
+		for i := 0; i < total; i++ {
+			records = append(records, i)
+		}
+		return records, nil
+	}
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		from, to := part, part
+		if i := strings.Index(part, "-"); i >= 0 {
+			from, to = strings.TrimSpace(part[:i]), strings.TrimSpace(part[i+1:])
+		}
+		start, err := strconv.Atoi(from)
+		if err != nil || start < 1 {
+			return nil, fmt.Errorf("无效的记录范围: %s", part)
+		}
+		end := total
+		if to != "" {
+			if end, err = strconv.Atoi(to); err != nil || end < start {
+				return nil, fmt.Errorf("无效的记录范围: %s", part)
+			}
+		}
+		for n := start; n <= end && n <= total; n++ {
+			if !seen[n-1] {
+				seen[n-1] = true
+				records = append(records, n-1)
+			}
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,279 @@
+package document
+
+import (
+	"unicode"
+)
+
+// diffOp 序列差异中的单步操作
+type diffOp int
+
+const (
+	opEqual diffOp = iota
+	opDelete
+	opInsert
+)
+
+// diffEdit 序列差异中的一步编辑，索引指向原序列中的元素
+type diffEdit struct {
+	op       diffOp
+	oldIndex int
+	newIndex int
+}
+
+// diffStrings 使用Myers算法计算两个字符串序列之间的最短编辑脚本
+func diffStrings(a, b []string) []diffEdit {
+	// 先去掉公共前缀和后缀，减少算法处理的规模
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var edits []diffEdit
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, diffEdit{op: opEqual, oldIndex: i, newIndex: i})
+	}
+
+	middle := myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	for _, e := range middle {
+		e.oldIndex += prefix
+		e.newIndex += prefix
+		edits = append(edits, e)
+	}
+
+	for i := 0; i < suffix; i++ {
+		edits = append(edits, diffEdit{
+			op:       opEqual,
+			oldIndex: len(a) - suffix + i,
+			newIndex: len(b) - suffix + i,
+		})
+	}
+
+	return edits
+}
+
+// myers Myers O(ND) 差异算法的线性空间版本，返回按顺序排列的编辑操作
+//
+// 每次找出最短编辑路径中间的一段（middle snake），再分别处理两侧，
+// 内存只与序列长度成正比，两个毫不相关的长文档也不会占用大量内存。
+// 同一处修改中的删除总是排在插入前面。
+func myers(a, b []string) []diffEdit {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	d := &differ{a: a, b: b}
+	d.compare(0, len(a), 0, len(b))
+	return groupChanges(d.edits)
+}
+
+// differ 线性空间Myers算法的状态
+type differ struct {
+	a, b  []string
+	edits []diffEdit
+}
+
+// compare 计算 a[aLo:aHi] 与 b[bLo:bHi] 之间的编辑操作
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.edits = append(d.edits, diffEdit{op: opEqual, oldIndex: aLo, newIndex: bLo})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.a[aHi-1-suffix] == d.b[bHi-1-suffix] {
+		suffix++
+	}
+	aHi -= suffix
+	bHi -= suffix
+
+	switch {
+	case aLo == aHi || bLo == bHi:
+		d.replace(aLo, aHi, bLo, bHi)
+	default:
+		x, y, ok := d.bisect(aLo, aHi, bLo, bHi)
+		if ok && (x > aLo || y > bLo) && (x < aHi || y < bHi) {
+			d.compare(aLo, x, bLo, y)
+			d.compare(x, aHi, y, bHi)
+		} else {
+			d.replace(aLo, aHi, bLo, bHi)
+		}
+	}
+
+	for i := 0; i < suffix; i++ {
+		d.edits = append(d.edits, diffEdit{op: opEqual, oldIndex: aHi + i, newIndex: bHi + i})
+	}
+}
+
+// replace 将 a[aLo:aHi] 全部删除，再插入 b[bLo:bHi]
+func (d *differ) replace(aLo, aHi, bLo, bHi int) {
+	for i := aLo; i < aHi; i++ {
+		d.edits = append(d.edits, diffEdit{op: opDelete, oldIndex: i, newIndex: bLo})
+	}
+	for j := bLo; j < bHi; j++ {
+		d.edits = append(d.edits, diffEdit{op: opInsert, oldIndex: aHi, newIndex: j})
+	}
+}
+
+// bisect 同时从两端搜索最短编辑路径，返回两个方向相遇处的分割点（绝对索引）
+func (d *differ) bisect(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	a, b := d.a[aLo:aHi], d.b[bLo:bHi]
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	size := 2*maxD + 2
+	forward := make([]int, size)
+	backward := make([]int, size)
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	delta := n - m
+	// 差值为奇数时两个方向在正向搜索中相遇，否则在反向搜索中相遇
+	front := delta%2 != 0
+	// 越过编辑图边界的对角线不再搜索
+	k1Start, k1End, k2Start, k2End := 0, 0, 0, 0
+
+	for step := 0; step < maxD; step++ {
+		for k1 := -step + k1Start; k1 <= step-k1End; k1 += 2 {
+			i := offset + k1
+			var x1 int
+			if k1 == -step || (k1 != step && forward[i-1] < forward[i+1]) {
+				x1 = forward[i+1]
+			} else {
+				x1 = forward[i-1] + 1
+			}
+			y1 := x1 - k1
+			for x1 < n && y1 < m && a[x1] == b[y1] {
+				x1++
+				y1++
+			}
+			forward[i] = x1
+			switch {
+			case x1 > n:
+				k1End += 2
+			case y1 > m:
+				k1Start += 2
+			case front:
+				j := offset + delta - k1
+				if j >= 0 && j < size && backward[j] != -1 && x1 >= n-backward[j] {
+					return aLo + x1, bLo + y1, true
+				}
+			}
+		}
+
+		for k2 := -step + k2Start; k2 <= step-k2End; k2 += 2 {
+			i := offset + k2
+			var x2 int
+			if k2 == -step || (k2 != step && backward[i-1] < backward[i+1]) {
+				x2 = backward[i+1]
+			} else {
+				x2 = backward[i-1] + 1
+			}
+			y2 := x2 - k2
+			for x2 < n && y2 < m && a[n-x2-1] == b[m-y2-1] {
+				x2++
+				y2++
+			}
+			backward[i] = x2
+			switch {
+			case x2 > n:
+				k2End += 2
+			case y2 > m:
+				k2Start += 2
+			case !front:
+				j := offset + delta - k2
+				if j >= 0 && j < size && forward[j] != -1 {
+					x1 := forward[j]
+					y1 := offset + x1 - j
+					if x1 >= n-x2 {
+						return aLo + x1, bLo + y1, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// groupChanges 将每处连续修改中的删除排在插入前面，并相应调整它们的位置
+func groupChanges(edits []diffEdit) []diffEdit {
+	result := make([]diffEdit, 0, len(edits))
+	for start := 0; start < len(edits); {
+		if edits[start].op == opEqual {
+			result = append(result, edits[start])
+			start++
+			continue
+		}
+		end := start
+		var deletes, inserts []diffEdit
+		for end < len(edits) && edits[end].op != opEqual {
+			if edits[end].op == opDelete {
+				deletes = append(deletes, edits[end])
+			} else {
+				inserts = append(inserts, edits[end])
+			}
+			end++
+		}
+		// 修改开始处在两个序列中的位置
+		oldPos, newPos := edits[start].oldIndex, edits[start].newIndex
+		for _, e := range deletes {
+			result = append(result, diffEdit{op: opDelete, oldIndex: e.oldIndex, newIndex: newPos})
+		}
+		for _, e := range inserts {
+			result = append(result, diffEdit{op: opInsert, oldIndex: oldPos + len(deletes), newIndex: e.newIndex})
+		}
+		start = end
+	}
+	return result
+}
+
+// splitDiffTokens 将文本切分为字词级差异的比较单元
+//
+// 拉丁字母和数字按单词切分，空白按连续片段切分，
+// 中文等其他字符（包括标点）逐字切分。
+func splitDiffTokens(text string) []string {
+	var tokens []string
+	var current []rune
+	currentKind := 0
+
+	flush := func() {
+		if len(current) > 0 {
+			tokens = append(tokens, string(current))
+			current = current[:0]
+		}
+	}
+
+	for _, r := range text {
+		kind := 0
+		switch {
+		case unicode.IsSpace(r):
+			kind = 1
+		case r < unicode.MaxLatin1 && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			kind = 2
+		case unicode.Is(unicode.Latin, r) || unicode.Is(unicode.Cyrillic, r) || unicode.Is(unicode.Greek, r):
+			kind = 2
+		}
+
+		if kind == 0 {
+			flush()
+			tokens = append(tokens, string(r))
+			currentKind = 0
+			continue
+		}
+		if kind != currentKind {
+			flush()
+		}
+		current = append(current, r)
+		currentKind = kind
+	}
+	flush()
+
+	return tokens
+}
@@ -0,0 +1,168 @@
+package document
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// applyEdits 按编辑脚本从 a 重建 b，同时检查索引是否连续
+func applyEdits(t *testing.T, a, b []string, edits []diffEdit) []string {
+	t.Helper()
+	var result []string
+	oldPos, newPos := 0, 0
+	for _, e := range edits {
+		switch e.op {
+		case opEqual:
+			if e.oldIndex != oldPos || e.newIndex != newPos || a[e.oldIndex] != b[e.newIndex] {
+				t.Fatalf("相同操作位置错误: %+v (期望 %d,%d)", e, oldPos, newPos)
+			}
+			result = append(result, a[e.oldIndex])
+			oldPos++
+			newPos++
+		case opDelete:
+			if e.oldIndex != oldPos || e.newIndex != newPos {
+				t.Fatalf("删除操作位置错误: %+v (期望 %d,%d)", e, oldPos, newPos)
+			}
+			oldPos++
+		case opInsert:
+			if e.oldIndex != oldPos || e.newIndex != newPos {
+				t.Fatalf("插入操作位置错误: %+v (期望 %d,%d)", e, oldPos, newPos)
+			}
+			result = append(result, b[e.newIndex])
+			newPos++
+		}
+	}
+	if oldPos != len(a) || newPos != len(b) {
+		t.Fatalf("编辑脚本没有覆盖整个序列: %d/%d %d/%d", oldPos, len(a), newPos, len(b))
+	}
+	return result
+}
+
+// lcsLength 用动态规划计算最长公共子序列的长度
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func countEqual(edits []diffEdit) int {
+	n := 0
+	for _, e := range edits {
+		if e.op == opEqual {
+			n++
+		}
+	}
+	return n
+}
+
+func TestDiffStrings(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string // 每个元素一个字符: = 相同, - 删除, + 插入
+	}{
+		{"空序列", "", "", ""},
+		{"全部插入", "", "abc", "+++"},
+		{"全部删除", "abc", "", "---"},
+		{"相同", "abc", "abc", "==="},
+		{"中间替换", "abcd", "axyd", "=--++="},
+		{"前面插入", "bc", "abc", "+=="},
+		{"末尾删除", "abc", "ab", "==-"},
+		{"毫不相关", "abc", "xyz", "---+++"},
+		{"交错", "abcabba", "cbabac", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := splitChars(tt.a), splitChars(tt.b)
+			edits := diffStrings(a, b)
+			if got := applyEdits(t, a, b, edits); !reflect.DeepEqual(got, b) {
+				t.Fatalf("重建结果 %v, 期望 %v", got, b)
+			}
+			if got, want := countEqual(edits), lcsLength(a, b); got != want {
+				t.Fatalf("相同元素 %d 个, 最长公共子序列 %d", got, want)
+			}
+			if tt.want != "" {
+				if got := editString(edits); got != tt.want {
+					t.Fatalf("编辑脚本 %q, 期望 %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffStringsRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a := randomSequence(rng, rng.Intn(40), 4)
+		b := randomSequence(rng, rng.Intn(40), 4)
+		edits := diffStrings(a, b)
+		applyEdits(t, a, b, edits)
+		if got, want := countEqual(edits), lcsLength(a, b); got != want {
+			t.Fatalf("%v -> %v: 相同元素 %d 个, 最长公共子序列 %d", a, b, got, want)
+		}
+	}
+}
+
+func TestDiffStringsDeletesBeforeInserts(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 200; i++ {
+		a := randomSequence(rng, 30, 3)
+		b := randomSequence(rng, 30, 3)
+		script := editString(diffStrings(a, b))
+		if strings.Contains(script, "+-") {
+			t.Fatalf("同一处修改中插入排在删除前面: %s", script)
+		}
+	}
+}
+
+func TestDiffStringsLarge(t *testing.T) {
+	// 两个毫不相关的长序列，线性空间算法不应占用大量内存
+	a := make([]string, 5000)
+	b := make([]string, 5000)
+	for i := range a {
+		a[i] = "a" + string(rune('0'+i%10)) + strings.Repeat("x", i%7)
+		b[i] = "b" + string(rune('0'+i%10))
+	}
+	edits := diffStrings(a, b)
+	if len(edits) != 10000 || countEqual(edits) != 0 {
+		t.Fatalf("编辑脚本长度 %d, 相同 %d", len(edits), countEqual(edits))
+	}
+}
+
+func splitChars(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "")
+}
+
+func randomSequence(rng *rand.Rand, n, alphabet int) []string {
+	seq := make([]string, n)
+	for i := range seq {
+		seq[i] = string(rune('a' + rng.Intn(alphabet)))
+	}
+	return seq
+}
+
+func editString(edits []diffEdit) string {
+	var sb strings.Builder
+	for _, e := range edits {
+		sb.WriteByte("=-+"[e.op])
+	}
+	return sb.String()
+}
@@ -16,8 +16,11 @@ type Document struct {
 	Title       string           // 文档标题
 	WordDoc     *word.Document  // 直接使用go-word库的Document类型
 	DocWriter   *writer.DocumentWriter // 使用DocumentWriter进行写入操作
+	Package     *Package         // 完整的OPC包，存在时保存文档使用它而不是DocumentWriter
 	IsModified  bool
 	IsOpen      bool
+	ReadOnly    bool             // 读取文档包失败，只能查看go-word读出的内容，不能编辑和保存
+	history     *history         // 撤销和重做记录
 }
 
 // Manager 基于go-word库的文档管理器
@@ -54,13 +57,22 @@ func (m *Manager) OpenDocument(filePath string) (*Document, error) {
 		return nil, fmt.Errorf("无法打开文档: %v", err)
 	}
 	
+	// 同时读取完整的OPC包，修订、批注等go-word不支持的内容通过它访问和保存
+	pkg, err := OpenPackage(filePath)
+	if err != nil {
+		log.Printf("读取文档包失败，文档以只读方式打开，不能编辑和保存: %v", err)
+		pkg = nil
+	}
+	
 	// 创建新文档实例
 	doc := &Document{
 		FilePath:   filePath,
 		FileName:   filepath.Base(filePath),
 		WordDoc:    wordDoc,
+		Package:    pkg,
 		IsOpen:     true,
 		IsModified: false,
+		ReadOnly:   pkg == nil,
 	}
 	
 	m.documents[filePath] = doc
@@ -72,30 +84,11 @@ func (m *Manager) OpenDocument(filePath string) (*Document, error) {
 
 // SaveDocument 使用DocumentWriter保存文档
 func (m *Manager) SaveDocument(doc *Document) error {
-	if doc == nil {
-		return fmt.Errorf("没有要保存的文档")
-	}
-	
-	if doc.DocWriter == nil {
-		return fmt.Errorf("文档写入器未初始化")
-	}
-	
-	// 检查文件路径是否为空
-	if doc.FilePath == "" {
-		return &SavePathNotSetError{}
-	}
-	
-	log.Printf("正在保存文档: %s", doc.FilePath)
-	
-	// 使用DocumentWriter保存文档
-	err := doc.DocWriter.Save(doc.FilePath)
+	task, err := m.BeginSave(doc, "")
 	if err != nil {
-		return fmt.Errorf("保存文档失败: %v", err)
+		return err
 	}
-	
-	doc.IsModified = false
-	log.Printf("文档保存成功: %s", doc.FilePath)
-	return nil
+	return task.Finish(task.Write())
 }
 
 // SavePathNotSetError 表示保存路径未设置的错误
@@ -113,38 +106,117 @@ func IsSavePathNotSetError(err error) bool {
 
 // SaveDocumentAs 使用DocumentWriter另存为
 func (m *Manager) SaveDocumentAs(doc *Document, newPath string) error {
+	if newPath == "" {
+		return &SavePathNotSetError{}
+	}
+	task, err := m.BeginSave(doc, newPath)
+	if err != nil {
+		return err
+	}
+	return task.Finish(task.Write())
+}
+
+// SaveTask 一次保存操作，用于在后台写入文件
+//
+// BeginSave 复制文档当前的内容，Write 可以在其它goroutine中执行，
+// 写入完成后在调用 BeginSave 的goroutine中调用 Finish 更新文档的路径和修改状态。
+// 写入期间对文档的修改不影响写入的内容，文档仍标记为已修改。
+type SaveTask struct {
+	manager *Manager
+	doc     *Document
+	Path    string // 保存的路径
+	saveAs  bool
+	pkg     *Package // 文档内容的副本，为nil时已在 BeginSave 中用DocumentWriter写入
+	err     error    // 在 BeginSave 中写入的结果
+}
+
+// BeginSave 开始保存文档，path 为空时保存到文档原来的路径
+func (m *Manager) BeginSave(doc *Document, path string) (*SaveTask, error) {
 	if doc == nil {
-		return fmt.Errorf("没有要保存的文档")
+		return nil, fmt.Errorf("没有要保存的文档")
 	}
 	
-	if doc.DocWriter == nil {
-		return fmt.Errorf("文档写入器未初始化")
+	if doc.ReadOnly {
+		return nil, fmt.Errorf("文档以只读方式打开，无法保存")
 	}
-	
-	// 检查新路径的扩展名
-	if !isWordDocument(newPath) {
-		return fmt.Errorf("不支持的文件格式: %s", filepath.Ext(newPath))
+	if doc.DocWriter == nil && doc.Package == nil {
+		return nil, fmt.Errorf("文档写入器未初始化")
 	}
 	
-	log.Printf("正在另存为: %s", newPath)
-	
-	// 使用DocumentWriter保存到新路径
-	err := doc.DocWriter.Save(newPath)
+	task := &SaveTask{manager: m, doc: doc, Path: path, saveAs: path != ""}
+	if path == "" {
+		// 检查文件路径是否为空
+		if doc.FilePath == "" {
+			return nil, &SavePathNotSetError{}
+		}
+		task.Path = doc.FilePath
+	} else if !isWordDocument(path) {
+		// 检查新路径的扩展名
+		return nil, fmt.Errorf("不支持的文件格式: %s", filepath.Ext(path))
+	} else if other := m.openDocumentAt(path); other != nil && other != doc {
+		return nil, fmt.Errorf("另存为失败: 文件已经作为另一个文档打开，请先关闭它: %s", path)
+	}
+	
+	log.Printf("正在保存文档: %s", task.Path)
+	if doc.Package != nil {
+		task.pkg = doc.Package.Clone()
+	} else {
+		// DocumentWriter无法复制，只能在调用者的goroutine中直接写入，避免与编辑同时访问
+		task.err = doc.DocWriter.Save(task.Path)
+	}
+	// 写入期间的修改会重新标记文档为已修改
+	doc.IsModified = false
+	return task, nil
+}
+
+// Write 将文档内容写入文件
+func (t *SaveTask) Write() error {
+	if t.pkg != nil {
+		return t.pkg.Save(t.Path)
+	}
+	return t.err
+}
+
+// Finish 完成保存：写入成功时更新文档的路径，失败时恢复文档的修改状态并返回错误
+func (t *SaveTask) Finish(err error) error {
+	doc := t.doc
 	if err != nil {
-		return fmt.Errorf("另存为失败: %v", err)
+		doc.IsModified = true
+		if t.saveAs {
+			return fmt.Errorf("另存为失败: %v", err)
+		}
+		return fmt.Errorf("保存文档失败: %v", err)
 	}
 	
-	// 更新文档路径
-	oldPath := doc.FilePath
-	doc.FilePath = newPath
-	doc.FileName = filepath.Base(newPath)
-	doc.IsModified = false
-	
-	// 更新管理器中的文档映射
-	delete(m.documents, oldPath)
-	m.documents[newPath] = doc
-	
-	log.Printf("文档另存为成功: %s", newPath)
+	if t.saveAs {
+		// 更新文档路径和管理器中的文档映射
+		m := t.manager
+		if other := m.openDocumentAt(t.Path); other != nil && other != doc {
+			// 写入期间同一路径又被打开，不能让两个文档共用一个路径
+			doc.IsModified = true
+			return fmt.Errorf("另存为失败: 文件已经作为另一个文档打开: %s", t.Path)
+		}
+		for key, d := range m.documents {
+			if d == doc {
+				delete(m.documents, key)
+			}
+		}
+		doc.FilePath = t.Path
+		doc.FileName = filepath.Base(t.Path)
+		m.documents[t.Path] = doc
+	}
+	log.Printf("文档保存成功: %s", t.Path)
+	return nil
+}
+
+// openDocumentAt 返回已经打开的指定路径的文档，没有时返回nil
+func (m *Manager) openDocumentAt(path string) *Document {
+	path = filepath.Clean(path)
+	for key, doc := range m.documents {
+		if doc.FilePath != "" && filepath.Clean(key) == path {
+			return doc
+		}
+	}
 	return nil
 }
 
@@ -223,35 +295,43 @@ func (m *Manager) CloseDocument(doc *Document) error {
 	return nil
 }
 
-// NewDocument 创建新的Word文档
-func (m *Manager) NewDocument() (*Document, error) {
+// NewDocument 创建新的Word文档，指定模板时以模板的内容和样式为起点
+func (m *Manager) NewDocument(opts NewDocumentOptions) (*Document, error) {
+	if opts.Template != "" {
+		return m.newDocumentFromTemplate(opts.Template)
+	}
 	log.Println("正在创建新文档")
 	
-	// 使用DocumentWriter创建新文档
-	docWriter := writer.NewDocumentWriter()
-	err := docWriter.CreateNewDocument()
-	if err != nil {
-		return nil, fmt.Errorf("创建新文档失败: %v", err)
-	}
+	// 新文档基于空白的OPC包，与打开的文档一样支持编辑、撤销和格式设置
+	doc := m.NewDocumentFromPackage(NewPackage(), "未命名文档")
 	
-	// 创建新文档实例
+	log.Println("新文档创建成功")
+	return doc, nil
+}
+
+// NewDocumentFromPackage 以生成的文档包创建未保存的新文档，name 为不含扩展名的文档名称
+func (m *Manager) NewDocumentFromPackage(pkg *Package, name string) *Document {
 	doc := &Document{
-		FilePath:   "", // 新文档还没有保存路径
-		FileName:   "未命名文档.docx",
-		Title:      "未命名文档", // 设置默认标题
-		WordDoc:    nil, // 暂时设为nil，DocumentWriter会管理文档
-		DocWriter:  docWriter,
+		FileName:   name + ".docx",
+		Title:      name,
+		Package:    pkg,
 		IsOpen:     true,
-		IsModified: true, // 新文档需要保存
+		IsModified: true,
 	}
-	
+	m.addUnsaved(doc)
+	log.Printf("已创建新文档: %s", doc.FileName)
+	return doc
+}
+
+// addUnsaved 将尚未保存的文档加入管理器并设为当前文档
+func (m *Manager) addUnsaved(doc *Document) {
 	// 生成临时ID用于管理
 	tempID := fmt.Sprintf("temp_%d", len(m.documents)+1)
+	for i := len(m.documents) + 2; m.documents[tempID] != nil; i++ {
+		tempID = fmt.Sprintf("temp_%d", i)
+	}
 	m.documents[tempID] = doc
 	m.currentDoc = doc
-	
-	log.Println("新文档创建成功")
-	return doc, nil
 }
 
 // GetText 获取文档文本内容
@@ -264,6 +344,42 @@ func (doc *Document) GetText() (string, error) {
 	return doc.WordDoc.GetText()
 }
 
+// ExtractText 读取磁盘上的Word文档并返回纯文本（包括表格内容），文档不会加入管理器
+func ExtractText(filePath string) (string, error) {
+	if !isWordDocument(filePath) {
+		return "", fmt.Errorf("不支持的文件格式: %s", filepath.Ext(filePath))
+	}
+
+	wordDoc, err := word.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("无法打开文档: %v", err)
+	}
+	defer wordDoc.Close()
+
+	text, err := wordDoc.GetText()
+	if err != nil {
+		return "", fmt.Errorf("获取文本失败: %v", err)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(text)
+
+	tables, err := wordDoc.GetTables()
+	if err == nil {
+		for _, table := range tables {
+			for _, row := range table.Rows {
+				for _, cell := range row.Cells {
+					builder.WriteString(cell.Text)
+					builder.WriteString("\t")
+				}
+				builder.WriteString("\n")
+			}
+		}
+	}
+
+	return builder.String(), nil
+}
+
 // GetParagraphs 获取文档段落
 func (doc *Document) GetParagraphs() (interface{}, error) {
 	if doc.WordDoc == nil {
@@ -322,6 +438,9 @@ func (doc *Document) GetMetadata() (interface{}, error) {
 
 // AddParagraph 向文档添加新段落
 func (doc *Document) AddParagraph(text string) error {
+	if doc.Package != nil {
+		return doc.appendParagraph(text)
+	}
 	if doc.DocWriter == nil {
 		return fmt.Errorf("文档未打开")
 	}
@@ -341,6 +460,9 @@ func (doc *Document) AddParagraph(text string) error {
 
 // AddText 向文档添加文本
 func (doc *Document) AddText(text string) error {
+	if doc.Package != nil {
+		return doc.appendParagraph(text)
+	}
 	if doc.DocWriter == nil {
 		return fmt.Errorf("文档未打开")
 	}
@@ -358,6 +480,27 @@ func (doc *Document) AddText(text string) error {
 	return nil
 }
 
+// appendParagraph 在基于OPC包的文档末尾添加正文段落
+func (doc *Document) appendParagraph(text string) error {
+	body, err := doc.body()
+	if err != nil {
+		return err
+	}
+	doc.Checkpoint("添加段落")
+	p := newParagraphNode("")
+	if text != "" {
+		p.AppendChild(newRunNode(text, nil))
+	}
+	if sectPr := body.Child("w:sectPr"); sectPr != nil {
+		body.InsertChild(body.IndexOf(sectPr), p)
+	} else {
+		body.AppendChild(p)
+	}
+	doc.IsModified = true
+	log.Printf("段落添加成功: %s", truncateText(text, 30))
+	return nil
+}
+
 // SetTitle 设置文档标题
 func (doc *Document) SetTitle(title string) error {
 	if doc == nil {
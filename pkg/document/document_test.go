@@ -0,0 +1,153 @@
+package document
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestManager 创建管理器并加入一个未保存的测试文档
+func newTestManager(t *testing.T) (*Manager, *Document) {
+	t.Helper()
+	m := NewManager()
+	doc := newTestDocument(t, "第一段")
+	doc.IsModified = true
+	m.documents["temp_1"] = doc
+	m.currentDoc = doc
+	return m, doc
+}
+
+// savedParagraphs 读取保存的文件中的段落文字
+func savedParagraphs(t *testing.T, path string) []string {
+	t.Helper()
+	pkg, err := OpenPackage(path)
+	if err != nil {
+		t.Fatalf("读取保存的文件失败: %v", err)
+	}
+	return paragraphTexts(&Document{Package: pkg, IsOpen: true})
+}
+
+func TestBeginSaveErrors(t *testing.T) {
+	m, doc := newTestManager(t)
+	if _, err := m.BeginSave(nil, ""); err == nil {
+		t.Errorf("保存空文档没有返回错误")
+	}
+	if _, err := m.BeginSave(&Document{}, "a.docx"); err == nil {
+		t.Errorf("保存没有内容的文档没有返回错误")
+	}
+	if _, err := m.BeginSave(doc, ""); !IsSavePathNotSetError(err) {
+		t.Errorf("保存没有路径的文档返回 %v", err)
+	}
+	if _, err := m.BeginSave(doc, filepath.Join(t.TempDir(), "a.txt")); err == nil {
+		t.Errorf("另存为不支持的格式没有返回错误")
+	}
+	if !doc.IsModified {
+		t.Errorf("保存失败后文档的修改状态被清除")
+	}
+}
+
+func TestSaveTask(t *testing.T) {
+	m, doc := newTestManager(t)
+	path := filepath.Join(t.TempDir(), "报告.docx")
+	task, err := m.BeginSave(doc, path)
+	if err != nil {
+		t.Fatalf("开始保存失败: %v", err)
+	}
+	if doc.IsModified {
+		t.Errorf("开始保存后文档仍标记为已修改")
+	}
+
+	// 写入期间的修改不影响写入的内容，文档重新标记为已修改
+	if _, err := doc.InsertText(TextPosition{0, 3}, "修改"); err != nil {
+		t.Fatalf("插入文字失败: %v", err)
+	}
+	if err := task.Finish(task.Write()); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if got := savedParagraphs(t, path); !reflect.DeepEqual(got, []string{"第一段"}) {
+		t.Errorf("保存的段落 %q", got)
+	}
+	if !doc.IsModified {
+		t.Errorf("写入期间修改的文档没有标记为已修改")
+	}
+
+	// 另存为后更新文档的路径和管理器中的映射
+	if doc.FilePath != path || doc.FileName != "报告.docx" {
+		t.Errorf("另存为后的路径 %q, 文件名 %q", doc.FilePath, doc.FileName)
+	}
+	if len(m.documents) != 1 || m.documents[path] != doc {
+		t.Errorf("另存为后管理器中的文档 %v", m.documents)
+	}
+
+	// 保存到原来的路径
+	if err := m.SaveDocument(doc); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if got := savedParagraphs(t, path); !reflect.DeepEqual(got, []string{"第一段修改"}) {
+		t.Errorf("再次保存的段落 %q", got)
+	}
+	if doc.IsModified {
+		t.Errorf("保存后文档仍标记为已修改")
+	}
+}
+
+func TestSaveTaskFailure(t *testing.T) {
+	m, doc := newTestManager(t)
+	path := filepath.Join(t.TempDir(), "a.docx")
+	task, err := m.BeginSave(doc, path)
+	if err != nil {
+		t.Fatalf("开始保存失败: %v", err)
+	}
+	err = task.Finish(errors.New("磁盘已满"))
+	if err == nil || !strings.Contains(err.Error(), "另存为失败") {
+		t.Errorf("写入失败时返回 %v", err)
+	}
+	if !doc.IsModified || doc.FilePath != "" || m.documents["temp_1"] != doc {
+		t.Errorf("写入失败后文档被修改: %+v", doc)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("没有写入时文件存在: %v", err)
+	}
+}
+
+func TestParagraphStyleName(t *testing.T) {
+	doc := newBodyTestDocument(t, styledParagraph("Heading1", "标题")+`<w:p><w:r><w:t>正文</w:t></w:r></w:p>`+
+		styledParagraph("Missing", "未定义的样式"))
+	for i, want := range []string{"heading 1", "Normal", "Missing"} {
+		if got, err := doc.ParagraphStyleName(i); err != nil || got != want {
+			t.Errorf("段落 %d 的样式名称 %q (%v), 期望 %q", i+1, got, err, want)
+		}
+	}
+	if _, err := doc.ParagraphStyleName(3); err == nil {
+		t.Errorf("段落索引超出范围没有返回错误")
+	}
+}
+
+func TestSaveAsOpenPath(t *testing.T) {
+	m, doc := newTestManager(t)
+	path := filepath.Join(t.TempDir(), "a.docx")
+	other := newTestDocument(t, "另一个文档")
+	other.FilePath = path
+	m.documents[path] = other
+
+	if _, err := m.BeginSave(doc, path); err == nil {
+		t.Errorf("另存为到另一个打开的文档的路径没有返回错误")
+	}
+
+	// 写入期间同一路径被打开
+	delete(m.documents, path)
+	task, err := m.BeginSave(doc, path)
+	if err != nil {
+		t.Fatalf("开始保存失败: %v", err)
+	}
+	m.documents[path] = other
+	if err := task.Finish(task.Write()); err == nil {
+		t.Errorf("完成保存时没有检查路径被另一个文档使用")
+	}
+	if m.documents[path] != other || doc.FilePath != "" || !doc.IsModified {
+		t.Errorf("保存失败后文档的路径 %q, 修改状态 %v", doc.FilePath, doc.IsModified)
+	}
+}
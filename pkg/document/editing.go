@@ -0,0 +1,296 @@
+package document
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TextPosition 正文中的位置，Offset 按段落最终文本的字符计算
+type TextPosition struct {
+	Paragraph int
+	Offset    int
+}
+
+// Before 判断位置是否在 other 之前
+func (p TextPosition) Before(other TextPosition) bool {
+	return p.Paragraph < other.Paragraph || (p.Paragraph == other.Paragraph && p.Offset < other.Offset)
+}
+
+// fieldRunElements 复杂域的结构元素，删除文字时保留包含它们的Run
+var fieldRunElements = []string{"w:fldChar", "w:instrText"}
+
+// ParagraphText 返回段落的最终文本，编辑操作的位置按它计算
+func (doc *Document) ParagraphText(paragraph int) (string, error) {
+	p, err := doc.paragraphNode(paragraph)
+	if err != nil {
+		return "", err
+	}
+	return paragraphNodeText(p), nil
+}
+
+// paragraphNode 返回正文中的第 paragraph 个段落节点
+func (doc *Document) paragraphNode(paragraph int) (*Node, error) {
+	if _, err := doc.body(); err != nil {
+		return nil, err
+	}
+	paragraphs := doc.paragraphNodes()
+	if paragraph < 0 || paragraph >= len(paragraphs) {
+		return nil, fmt.Errorf("段落索引超出范围: %d", paragraph+1)
+	}
+	return paragraphs[paragraph], nil
+}
+
+// InsertText 在指定位置插入文字，返回插入的文字之后的位置
+//
+// 新文字使用前面文字的字符格式，位于段落开头时使用后面文字的格式。
+// 文字中的换行符 \n 拆分段落，垂直制表符 \v 插入手动换行。
+func (doc *Document) InsertText(pos TextPosition, text string) (TextPosition, error) {
+	p, err := doc.paragraphNode(pos.Paragraph)
+	if err != nil {
+		return pos, err
+	}
+	if length := len([]rune(paragraphNodeText(p))); pos.Offset < 0 || pos.Offset > length {
+		return pos, fmt.Errorf("文字位置超出段落范围: %d", pos.Offset)
+	}
+
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(text)
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			if p, err = doc.splitParagraphNode(p, pos.Offset); err != nil {
+				return pos, err
+			}
+			pos = TextPosition{Paragraph: pos.Paragraph + 1}
+		}
+		if line == "" {
+			continue
+		}
+		ip := splitRunsAt(p, pos.Offset)
+		ip.insert(newRunNode(strings.ReplaceAll(line, "\v", "\n"), neighbourRunProperties(p, ip)))
+		mergeRuns(ip.parent)
+		pos.Offset += len([]rune(line))
+	}
+	doc.IsModified = true
+	return pos, nil
+}
+
+// neighbourRunProperties 返回插入点前面（没有时为后面）包含文字的Run的字符格式副本，
+// 都没有时使用段落标记的格式
+func neighbourRunProperties(p *Node, ip insertPoint) *Node {
+	candidates := make([]*Node, 0, len(ip.parent.Children))
+	for i := ip.index - 1; i >= 0; i-- {
+		candidates = append(candidates, ip.parent.Children[i])
+	}
+	candidates = append(candidates, ip.parent.Children[ip.index:]...)
+	for _, c := range candidates {
+		if c.Name == "w:r" && runTextLength(c) > 0 {
+			if rPr := c.Child("w:rPr"); rPr != nil {
+				return rPr.Clone()
+			}
+			return nil
+		}
+	}
+	if pPr := p.Child("w:pPr"); pPr != nil {
+		if mark := pPr.Child("w:rPr"); mark != nil {
+			rPr := mark.Clone()
+			// 段落标记的修订信息不属于文字格式
+			for _, name := range []string{"w:ins", "w:del", "w:moveFrom", "w:moveTo", "w:rPrChange"} {
+				rPr.RemoveChildrenNamed(name)
+			}
+			if len(rPr.Children) > 0 {
+				return rPr
+			}
+		}
+	}
+	return nil
+}
+
+// SplitParagraph 在指定位置将段落拆分为两段，后一段使用相同的段落格式
+//
+// 在段落末尾拆分时，如果段落样式指定了后续段落样式，新段落使用后续样式；
+// 段落中的分节符属性留在后一段。
+func (doc *Document) SplitParagraph(pos TextPosition) error {
+	p, err := doc.paragraphNode(pos.Paragraph)
+	if err != nil {
+		return err
+	}
+	if length := len([]rune(paragraphNodeText(p))); pos.Offset < 0 || pos.Offset > length {
+		return fmt.Errorf("文字位置超出段落范围: %d", pos.Offset)
+	}
+	if _, err := doc.splitParagraphNode(p, pos.Offset); err != nil {
+		return err
+	}
+	doc.IsModified = true
+	return nil
+}
+
+// splitParagraphNode 在段落的第 offset 个字符处拆分段落，返回新的后一段
+func (doc *Document) splitParagraphNode(p *Node, offset int) (*Node, error) {
+	body, err := doc.body()
+	if err != nil {
+		return nil, err
+	}
+	parent := findParent(body, p)
+	if parent == nil {
+		return nil, fmt.Errorf("段落不在正文中")
+	}
+	atEnd := offset >= len([]rune(paragraphNodeText(p)))
+	ip := splitRunsAt(p, offset)
+
+	next := NewNode("w:p")
+	if pPr := p.Child("w:pPr"); pPr != nil {
+		next.AppendChild(pPr.Clone())
+		pPr.RemoveChildrenNamed("w:sectPr")
+	}
+
+	// 插入点位于超链接、修订等容器中时，容器也在插入点处拆分
+	current, index := ip.parent, ip.index
+	for current != p {
+		up := findParent(p, current)
+		at := up.IndexOf(current) + 1
+		if index < len(current.Children) {
+			tail := NewNode(current.Name)
+			tail.Attrs = append(tail.Attrs, current.Attrs...)
+			tail.Children = append(tail.Children, current.Children[index:]...)
+			current.Children = current.Children[:index]
+			up.InsertChild(at, tail)
+		}
+		current, index = up, at
+	}
+	next.AppendChild(p.Children[index:]...)
+	p.Children = p.Children[:index]
+
+	if atEnd {
+		if style := doc.styleSheet().styles[paragraphStyle(p)]; style != nil {
+			if following := style.Child("w:next"); following != nil && following.Attr("w:val") != "" {
+				pPr := paragraphProperties(next)
+				pPr.EnsureChild("w:pStyle", pPrOrder).SetAttr("w:val", following.Attr("w:val"))
+			}
+		}
+	}
+	parent.InsertChild(parent.IndexOf(p)+1, next)
+	return next, nil
+}
+
+// DeleteText 删除范围内的文字，跨段落的范围删除中间的段落和表格并合并首尾两段
+//
+// 合并后的段落保留第一段的段落格式（第一段为空时保留最后一段的格式），
+// 最后一段的分节符属性移到合并后的段落。
+func (doc *Document) DeleteText(r TextRange) error {
+	body, err := doc.body()
+	if err != nil {
+		return err
+	}
+	paragraphs := doc.paragraphNodes()
+	if r.StartParagraph < 0 || r.EndParagraph >= len(paragraphs) || r.StartParagraph > r.EndParagraph {
+		return fmt.Errorf("段落范围无效: %d-%d", r.StartParagraph+1, r.EndParagraph+1)
+	}
+	if r.StartOffset < 0 || (r.StartParagraph == r.EndParagraph && r.EndOffset >= 0 && r.EndOffset < r.StartOffset) {
+		return fmt.Errorf("文字范围无效: %d-%d", r.StartOffset, r.EndOffset)
+	}
+
+	first, last := paragraphs[r.StartParagraph], paragraphs[r.EndParagraph]
+	if first == last {
+		deleteRunText(first, r.StartOffset, r.EndOffset)
+		doc.IsModified = true
+		return nil
+	}
+
+	deleteRunText(first, r.StartOffset, -1)
+	deleteRunText(last, 0, r.EndOffset)
+	for _, p := range paragraphs[r.StartParagraph+1 : r.EndParagraph] {
+		if parent := findParent(body, p); parent != nil {
+			parent.RemoveChild(p)
+		}
+	}
+	// 首尾两段在同一容器中时，删除它们之间的表格等其他内容
+	parent := findParent(body, first)
+	if parent != nil && parent == findParent(body, last) {
+		from, to := parent.IndexOf(first), parent.IndexOf(last)
+		if to > from+1 {
+			parent.Children = append(parent.Children[:from+1], parent.Children[to:]...)
+		}
+	}
+	joinParagraphs(body, first, last)
+	doc.IsModified = true
+	return nil
+}
+
+// deleteRunText 删除段落最终文本中 start 到 end（不包含，-1表示段落末尾）之间的Run
+//
+// 范围内部的图片、注释引用等不占位置的Run一起删除，复杂域的结构Run保留。
+func deleteRunText(p *Node, start, end int) {
+	if end >= 0 {
+		splitRunsAt(p, end)
+	}
+	splitRunsAt(p, start)
+
+	pos := 0
+	var walk func(n *Node) bool
+	walk = func(n *Node) bool {
+		removed := false
+		for i := 0; i < len(n.Children); i++ {
+			c := n.Children[i]
+			remove := false
+			switch {
+			case c.Name == "w:r":
+				length := runTextLength(c)
+				if length > 0 {
+					remove = pos >= start && (end < 0 || pos+length <= end)
+				} else {
+					remove = pos > start && (end < 0 || pos < end) && !containsAny(c, fieldRunElements)
+				}
+				pos += length
+			case c.Name == "w:ins" || c.Name == "w:moveTo" || containerElements[c.Name]:
+				// 内容全部删除后的容器一起删除
+				remove = walk(c) && len(c.Elements()) == 0
+			}
+			if remove {
+				n.Children = append(n.Children[:i], n.Children[i+1:]...)
+				i--
+				removed = true
+			}
+		}
+		return removed
+	}
+	walk(p)
+	mergeRuns(p)
+}
+
+// joinParagraphs 将段落 second 的内容移到 first 的末尾并删除 second
+func joinParagraphs(body, first, second *Node) {
+	firstPr, secondPr := first.Child("w:pPr"), second.Child("w:pPr")
+	if paragraphNodeText(first) == "" && len(first.Elements()) <= 1 {
+		// 第一段为空时使用第二段的格式
+		if firstPr != nil {
+			first.RemoveChild(firstPr)
+		}
+		firstPr = nil
+		if secondPr != nil {
+			firstPr = secondPr.Clone()
+			first.InsertChild(0, firstPr)
+		}
+	} else if firstPr != nil {
+		firstPr.RemoveChildrenNamed("w:sectPr")
+		if secondPr != nil {
+			if sectPr := secondPr.Child("w:sectPr"); sectPr != nil {
+				firstPr.InsertOrdered(sectPr.Clone(), pPrOrder)
+			}
+		}
+	} else if secondPr != nil {
+		if sectPr := secondPr.Child("w:sectPr"); sectPr != nil {
+			firstPr = NewNode("w:pPr")
+			firstPr.AppendChild(sectPr.Clone())
+			first.InsertChild(0, firstPr)
+		}
+	}
+
+	for _, c := range second.Children {
+		if c.Name != "w:pPr" {
+			first.AppendChild(c)
+		}
+	}
+	if parent := findParent(body, second); parent != nil {
+		parent.RemoveChild(second)
+	}
+	mergeRuns(first)
+}
@@ -0,0 +1,189 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+// describeParagraphs 描述文档中每个段落的Run
+func describeParagraphs(doc *Document) [][]string {
+	var result [][]string
+	for _, p := range doc.paragraphNodes() {
+		result = append(result, describeRuns(p))
+	}
+	return result
+}
+
+func TestInsertText(t *testing.T) {
+	const bold = `<w:rPr><w:b/></w:rPr>`
+	tests := []struct {
+		name    string
+		body    string
+		pos     TextPosition
+		text    string
+		want    [][]string
+		wantPos TextPosition
+	}{
+		{"使用前面文字的格式", `<w:p><w:r>` + bold + `<w:t>加粗</w:t></w:r><w:r><w:t>普通</w:t></w:r></w:p>`,
+			TextPosition{0, 2}, "新", [][]string{{"*加粗新", "普通"}}, TextPosition{0, 3}},
+		{"段落开头使用后面文字的格式", `<w:p><w:r>` + bold + `<w:t>加粗</w:t></w:r></w:p>`,
+			TextPosition{0, 0}, "新", [][]string{{"*新加粗"}}, TextPosition{0, 1}},
+		{"空段落使用段落标记的格式", `<w:p><w:pPr><w:rPr><w:ins ` + testIns + `/><w:i/></w:rPr></w:pPr></w:p>`,
+			TextPosition{0, 0}, "新", [][]string{{"/新"}}, TextPosition{0, 1}},
+		{"换行符拆分段落", `<w:p><w:r><w:t>前后</w:t></w:r></w:p>`,
+			TextPosition{0, 1}, "一\r\n二\n三", [][]string{{"前一"}, {"二"}, {"三后"}}, TextPosition{2, 1}},
+		{"末尾的换行符", `<w:p><w:r><w:t>正文</w:t></w:r></w:p>`,
+			TextPosition{0, 2}, "\n", [][]string{{"正文"}, nil}, TextPosition{1, 0}},
+		{"超链接中插入", `<w:p><w:hyperlink w:anchor="a"><w:r><w:t>链接</w:t></w:r></w:hyperlink></w:p>`,
+			TextPosition{0, 1}, "新", [][]string{{"w:hyperlink(链新接)"}}, TextPosition{0, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := newBodyTestDocument(t, tt.body)
+			pos, err := doc.InsertText(tt.pos, tt.text)
+			if err != nil {
+				t.Fatalf("插入文字失败: %v", err)
+			}
+			if got := describeParagraphs(doc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("插入后的Run %q, 期望 %q", got, tt.want)
+			}
+			if pos != tt.wantPos {
+				t.Errorf("插入后的位置 %+v, 期望 %+v", pos, tt.wantPos)
+			}
+		})
+	}
+}
+
+func TestInsertTextLineBreak(t *testing.T) {
+	doc := newTestDocument(t, "正文")
+	if _, err := doc.InsertText(TextPosition{0, 1}, "一\v二"); err != nil {
+		t.Fatalf("插入文字失败: %v", err)
+	}
+	if got := paragraphTexts(doc); !reflect.DeepEqual(got, []string{"正一\n二文"}) {
+		t.Errorf("插入手动换行后的段落 %q", got)
+	}
+	if len(doc.paragraphNodes()[0].Find("w:br")) != 1 {
+		t.Errorf("没有插入换行元素")
+	}
+
+	for _, pos := range []TextPosition{{1, 0}, {0, -1}, {0, 6}} {
+		if _, err := doc.InsertText(pos, "文字"); err == nil {
+			t.Errorf("在 %+v 插入文字没有返回错误", pos)
+		}
+	}
+}
+
+func TestSplitParagraph(t *testing.T) {
+	doc := newBodyTestDocument(t, `<w:p><w:pPr><w:jc w:val="center"/><w:sectPr><w:type w:val="continuous"/></w:sectPr></w:pPr>`+
+		`<w:r><w:t>第一</w:t></w:r><w:hyperlink w:anchor="a"><w:r><w:t>链接</w:t></w:r></w:hyperlink></w:p>`+
+		styledParagraph("Heading1", "标题"))
+	if err := doc.SplitParagraph(TextPosition{0, 3}); err != nil {
+		t.Fatalf("拆分段落失败: %v", err)
+	}
+	want := [][]string{{"第一", "w:hyperlink(链)"}, {"w:hyperlink(接)"}, {"标题"}}
+	if got := describeParagraphs(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("拆分后的Run %q, 期望 %q", got, want)
+	}
+	// 两段使用相同的段落格式，分节符留在后一段
+	paragraphs := doc.paragraphNodes()
+	first, second := paragraphs[0].Child("w:pPr"), paragraphs[1].Child("w:pPr")
+	if first.String() != `<w:pPr><w:jc w:val="center"/></w:pPr>` || second.Child("w:jc") == nil || second.Child("w:sectPr") == nil {
+		t.Errorf("拆分后的段落属性 %s, %s", first, second)
+	}
+	if got := doc.SectionCount(); got != 2 {
+		t.Errorf("拆分后有 %d 节, 期望 2 节", got)
+	}
+
+	// 在标题末尾拆分时新段落使用后续样式
+	if err := doc.SplitParagraph(TextPosition{2, 2}); err != nil {
+		t.Fatalf("拆分段落失败: %v", err)
+	}
+	if got := paragraphStyle(doc.paragraphNodes()[3]); got != "Normal" {
+		t.Errorf("标题之后的新段落样式 %q, 期望 Normal", got)
+	}
+	if err := doc.SplitParagraph(TextPosition{2, 1}); err != nil {
+		t.Fatalf("拆分段落失败: %v", err)
+	}
+	if got := paragraphStyle(doc.paragraphNodes()[3]); got != "Heading1" {
+		t.Errorf("标题中间拆分后的样式 %q, 期望 Heading1", got)
+	}
+}
+
+func TestDeleteText(t *testing.T) {
+	field := `<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText> PAGE </w:instrText></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>1</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r>`
+	tests := []struct {
+		name string
+		body string
+		r    TextRange
+		want [][]string
+	}{
+		{"段落中间", `<w:p><w:r><w:t>一二</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>三四</w:t></w:r></w:p>`,
+			TextRange{0, 1, 0, 3}, [][]string{{"一", "*四"}}},
+		{"删除到段落末尾", `<w:p><w:r><w:t>一二三</w:t></w:r></w:p>`,
+			TextRange{0, 1, 0, -1}, [][]string{{"一"}}},
+		{"删除整个超链接", `<w:p><w:r><w:t>前</w:t></w:r><w:hyperlink w:anchor="a"><w:r><w:t>链接</w:t></w:r></w:hyperlink><w:r><w:t>后</w:t></w:r></w:p>`,
+			TextRange{0, 1, 0, 3}, [][]string{{"前后"}}},
+		{"保留域结构", `<w:p><w:r><w:t>第</w:t></w:r>` + field + `<w:r><w:t>页</w:t></w:r></w:p>`,
+			TextRange{0, 0, 0, 3}, [][]string{{"", "", "", ""}}},
+		{"跨越段落", `<w:p><w:r><w:t>第一段</w:t></w:r></w:p><w:p><w:r><w:t>第二段</w:t></w:r></w:p>` +
+			`<w:tbl><w:tr><w:tc><w:p/></w:tc></w:tr></w:tbl><w:p><w:r><w:t>第三段</w:t></w:r></w:p>`,
+			TextRange{0, 1, 2, 2}, [][]string{{"第段"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := newBodyTestDocument(t, tt.body)
+			if err := doc.DeleteText(tt.r); err != nil {
+				t.Fatalf("删除文字失败: %v", err)
+			}
+			if got := describeParagraphs(doc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("删除后的Run %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+
+	doc := newTestDocument(t, "一", "二")
+	for _, r := range []TextRange{{1, 0, 0, 0}, {0, 0, 2, 0}, {0, 1, 0, 0}, {0, -1, 0, 1}} {
+		if err := doc.DeleteText(r); err == nil {
+			t.Errorf("删除 %+v 没有返回错误", r)
+		}
+	}
+}
+
+func TestJoinParagraphs(t *testing.T) {
+	const sectPr = `<w:sectPr><w:type w:val="continuous"/></w:sectPr>`
+	tests := []struct {
+		name          string
+		first, second string
+		want          string // 合并后的段落属性
+	}{
+		{"保留第一段的格式", `<w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t>一</w:t></w:r>`, `<w:pPr><w:jc w:val="right"/></w:pPr><w:r><w:t>二</w:t></w:r>`,
+			`<w:pPr><w:jc w:val="center"/></w:pPr>`},
+		{"第一段为空时使用第二段的格式", `<w:pPr><w:jc w:val="center"/></w:pPr>`, `<w:pPr><w:jc w:val="right"/></w:pPr><w:r><w:t>二</w:t></w:r>`,
+			`<w:pPr><w:jc w:val="right"/></w:pPr>`},
+		{"分节符移到合并后的段落", `<w:pPr><w:jc w:val="center"/>` + sectPr + `</w:pPr><w:r><w:t>一</w:t></w:r>`, `<w:pPr><w:jc w:val="right"/>` + sectPr + `</w:pPr><w:r><w:t>二</w:t></w:r>`,
+			`<w:pPr><w:jc w:val="center"/>` + sectPr + `</w:pPr>`},
+		{"删除第一段的分节符", `<w:pPr>` + sectPr + `</w:pPr><w:r><w:t>一</w:t></w:r>`, `<w:r><w:t>二</w:t></w:r>`,
+			`<w:pPr/>`},
+		{"第一段没有段落属性", `<w:r><w:t>一</w:t></w:r>`, `<w:pPr><w:jc w:val="right"/>` + sectPr + `</w:pPr><w:r><w:t>二</w:t></w:r>`,
+			`<w:pPr>` + sectPr + `</w:pPr>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := newBodyTestDocument(t, "<w:p>"+tt.first+"</w:p><w:p>"+tt.second+"</w:p>")
+			body := testBody(t, doc)
+			paragraphs := doc.paragraphNodes()
+			joinParagraphs(body, paragraphs[0], paragraphs[1])
+			if got := paragraphTexts(doc); len(got) != 1 || got[0] != "一二" && got[0] != "二" {
+				t.Fatalf("合并后的段落 %q", got)
+			}
+			var got string
+			if pPr := doc.paragraphNodes()[0].Child("w:pPr"); pPr != nil {
+				got = pPr.String()
+			}
+			if got != tt.want {
+				t.Errorf("合并后的段落属性 %s, 期望 %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,725 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FieldType 域类型，为域代码中大写的域名称
+type FieldType string
+
+// 支持计算结果的域类型
+const (
+	FieldPage        FieldType = "PAGE"        // 当前页码
+	FieldNumPages    FieldType = "NUMPAGES"    // 总页数
+	FieldDate        FieldType = "DATE"        // 当前日期
+	FieldAuthor      FieldType = "AUTHOR"      // 文档作者
+	FieldTitle       FieldType = "TITLE"       // 文档标题
+	FieldRef         FieldType = "REF"         // 书签文字的交叉引用
+	FieldSeq         FieldType = "SEQ"         // 题注等使用的顺序编号
+	FieldDocProperty FieldType = "DOCPROPERTY" // 文档属性
+)
+
+// FieldTypes 可以插入和更新的域类型
+var FieldTypes = []FieldType{
+	FieldPage, FieldNumPages, FieldDate, FieldAuthor, FieldTitle, FieldRef, FieldSeq, FieldDocProperty,
+}
+
+// defaultDateFormat 日期域没有指定格式时使用的格式
+const defaultDateFormat = "yyyy/M/d"
+
+// 无法计算结果时Word显示的错误文字
+const (
+	errorBookmarkNotDefined = "错误! 未定义书签。"
+	errorUnknownProperty    = "错误! 未知的文档属性名。"
+)
+
+// Supported 判断域类型是否支持计算结果
+func (t FieldType) Supported() bool {
+	for _, supported := range FieldTypes {
+		if t == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsArgument 判断域类型是否需要参数
+func (t FieldType) NeedsArgument() bool {
+	return t == FieldRef || t == FieldSeq || t == FieldDocProperty
+}
+
+// FieldCode 解析后的域代码
+type FieldCode struct {
+	Type     FieldType
+	Argument string   // 第一个参数：REF 的书签名、SEQ 的序列名、DOCPROPERTY 的属性名
+	Format   string   // 日期格式（\@ 开关），为空时使用默认格式
+	Switches []string // 其它开关及其参数，保持原样，例如 \h、\* MERGEFORMAT
+}
+
+// ParseFieldCode 解析域代码，支持用引号包含带空格的参数
+func ParseFieldCode(instr string) FieldCode {
+	tokens := fieldTokens(instr)
+	var code FieldCode
+	if len(tokens) == 0 {
+		return code
+	}
+	code.Type = FieldType(strings.ToUpper(tokens[0]))
+	for i := 1; i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case token == `\@` && i+1 < len(tokens):
+			code.Format = tokens[i+1]
+			i++
+		case strings.HasPrefix(token, `\`):
+			code.Switches = append(code.Switches, token)
+		case code.Argument == "" && len(code.Switches) == 0:
+			code.Argument = token
+		default:
+			code.Switches = append(code.Switches, token)
+		}
+	}
+	return code
+}
+
+// fieldTokens 将域代码拆分为单词，引号中的内容作为一个单词
+func fieldTokens(instr string) []string {
+	var tokens []string
+	var current strings.Builder
+	quoted, started := false, false
+	for _, r := range instr {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case !quoted && unicode.IsSpace(r):
+			if started {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// String 返回域代码文本
+func (c FieldCode) String() string {
+	parts := []string{string(c.Type)}
+	if c.Argument != "" {
+		parts = append(parts, quoteFieldToken(c.Argument))
+	}
+	if c.Format != "" {
+		parts = append(parts, `\@`, quoteFieldToken(c.Format))
+	}
+	for _, s := range c.Switches {
+		parts = append(parts, quoteFieldToken(s))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteFieldToken 参数含有空格时加上引号
+func quoteFieldToken(token string) string {
+	if token == "" || strings.ContainsAny(token, " \t") {
+		return `"` + token + `"`
+	}
+	return token
+}
+
+// switchValue 返回开关的参数，开关不存在时返回 false
+func (c FieldCode) switchValue(name string) (string, bool) {
+	for i, s := range c.Switches {
+		if strings.EqualFold(s, name) {
+			if i+1 < len(c.Switches) && !strings.HasPrefix(c.Switches[i+1], `\`) {
+				return c.Switches[i+1], true
+			}
+			return "", true
+		}
+	}
+	return "", false
+}
+
+// Field 正文中的域
+type Field struct {
+	FieldCode
+	Index       int    // 在 Fields 结果中的序号
+	Instruction string // 原始域代码
+	Result      string // 当前显示的结果
+	Simple      bool   // 以 w:fldSimple 保存的简单域
+	Paragraph   int    // 所在的正文段落，位于表格中时为-1
+	Offset      int    // 结果在段落最终文本中的起始位置
+	Length      int    // 结果的字符数
+}
+
+// fieldInstance 解析正文时找到的域及其节点
+type fieldInstance struct {
+	Field
+	simple     *Node   // 简单域元素
+	begin, end *Node   // 复杂域的 begin 和 end 标记Run
+	separate   *Node   // 复杂域的 separate 标记Run，没有时为nil
+	results    []*Node // 复杂域 separate 与 end 之间的Run，包括嵌套域
+	anchor     int     // 所在或之前最近的正文段落，用于计算页码
+}
+
+// openField 解析复杂域时尚未结束的域
+type openField struct {
+	instance  *fieldInstance
+	instr     strings.Builder
+	separated bool
+	paragraph *Node
+}
+
+// collectFields 按文档顺序收集正文中结果位于一个段落内的最外层域，目录域除外
+func collectFields(body *Node) []*fieldInstance {
+	var fields []*fieldInstance
+	var stack []*openField
+	index, anchor, paragraph, offset := 0, -1, -1, 0
+	var current *Node
+
+	finish := func(instance *fieldInstance, instr string) {
+		instance.Instruction = strings.TrimSpace(instr)
+		instance.FieldCode = ParseFieldCode(instance.Instruction)
+		if instance.Type == "TOC" || instance.Type == "" {
+			return
+		}
+		instance.Length = offset - instance.Offset
+		instance.Index = len(fields)
+		fields = append(fields, instance)
+	}
+	open := func() *fieldInstance {
+		return &fieldInstance{Field: Field{Paragraph: paragraph, Offset: offset}, anchor: anchor}
+	}
+
+	var inline func(n *Node)
+	inline = func(n *Node) {
+		for _, c := range n.Children {
+			switch {
+			case c.Name == "w:fldSimple":
+				if len(stack) > 0 {
+					inline(c)
+					continue
+				}
+				instance := open()
+				instance.simple = c
+				instance.Simple = true
+				instance.Result = paragraphNodeText(c)
+				inline(c)
+				finish(instance, c.Attr("w:instr"))
+			case c.Name == "w:r":
+				fieldRun(c, &stack, &offset, current, open, func(f *openField) {
+					if f.paragraph == current {
+						finish(f.instance, f.instr.String())
+					}
+				})
+			case c.Name == "w:ins" || c.Name == "w:moveTo" || containerElements[c.Name]:
+				inline(c)
+			}
+		}
+	}
+
+	var blocks func(container *Node, top bool)
+	blocks = func(container *Node, top bool) {
+		for _, c := range container.Children {
+			switch c.Name {
+			case "w:p":
+				current, offset, paragraph = c, 0, -1
+				if top {
+					anchor, paragraph = index, index
+					index++
+				}
+				inline(c)
+			case "w:tbl":
+				for _, tc := range c.Find("w:tc") {
+					blocks(tc, false)
+				}
+			case "w:sdt", "w:sdtContent", "w:customXml":
+				blocks(c, top)
+			}
+		}
+	}
+	blocks(body, true)
+	for _, f := range fields {
+		if !f.Simple {
+			f.Result = fieldResultText(f.results)
+		}
+	}
+	return fields
+}
+
+// fieldRun 处理Run中的复杂域标记，更新域的嵌套状态和段落中的位置
+//
+// open 创建最外层的域，closed 在最外层的域结束时调用。
+func fieldRun(r *Node, stack *[]*openField, offset *int, paragraph *Node,
+	open func() *fieldInstance, closed func(*openField)) {
+	var outer *openField
+	if len(*stack) > 0 && (*stack)[0].separated {
+		outer = (*stack)[0]
+	}
+	for _, c := range r.Children {
+		switch c.Name {
+		case "w:fldChar":
+			switch c.Attr("w:fldCharType") {
+			case "begin":
+				f := &openField{paragraph: paragraph}
+				if len(*stack) == 0 {
+					f.instance = open()
+					f.instance.begin = r
+				}
+				*stack = append(*stack, f)
+			case "separate":
+				if n := len(*stack); n > 0 {
+					top := (*stack)[n-1]
+					top.separated = true
+					if top.instance != nil {
+						top.instance.separate = r
+					}
+				}
+			case "end":
+				if n := len(*stack); n > 0 {
+					top := (*stack)[n-1]
+					*stack = (*stack)[:n-1]
+					if top.instance != nil {
+						top.instance.end = r
+						closed(top)
+					}
+				}
+			}
+		case "w:instrText":
+			if n := len(*stack); n > 0 && !(*stack)[n-1].separated {
+				(*stack)[n-1].instr.WriteString(c.InnerText())
+			}
+		}
+		*offset += runChildLength(c)
+	}
+	if outer != nil && outer.instance.end != r {
+		outer.instance.results = append(outer.instance.results, r)
+	}
+}
+
+// fieldResultText 返回复杂域结果Run中的文字
+func fieldResultText(runs []*Node) string {
+	var builder strings.Builder
+	for _, r := range runs {
+		for _, c := range r.Children {
+			switch c.Name {
+			case "w:t":
+				builder.WriteString(c.InnerText())
+			case "w:tab", "w:ptab":
+				builder.WriteString("\t")
+			case "w:br", "w:cr":
+				builder.WriteString("\n")
+			}
+		}
+	}
+	return builder.String()
+}
+
+// Fields 返回正文中的域，包括表格中的域；目录域和结果跨越多个段落的域除外
+func (doc *Document) Fields() ([]Field, error) {
+	body, err := doc.body()
+	if err != nil {
+		return nil, err
+	}
+	var fields []Field
+	for _, f := range collectFields(body) {
+		fields = append(fields, f.Field)
+	}
+	return fields, nil
+}
+
+// FieldUpdateOptions 计算域结果的选项
+type FieldUpdateOptions struct {
+	Pages PageLocator // 排版引擎，为nil时页码按1计算
+	Now   time.Time   // 日期域使用的时间，为零值时使用当前时间
+}
+
+// fieldEvaluator 按文档顺序计算域的结果
+type fieldEvaluator struct {
+	doc        *Document
+	now        time.Time
+	pages      []int
+	properties map[string]string
+	sequences  map[string]int
+}
+
+// newFieldEvaluator 读取计算域结果需要的文档属性和页码
+func (doc *Document) newFieldEvaluator(opts FieldUpdateOptions) *fieldEvaluator {
+	e := &fieldEvaluator{
+		doc:        doc,
+		now:        opts.Now,
+		properties: doc.DocumentProperties(),
+		sequences:  make(map[string]int),
+	}
+	if e.now.IsZero() {
+		e.now = time.Now()
+	}
+	if opts.Pages != nil {
+		if pages, err := opts.Pages.ParagraphPages(doc); err == nil {
+			e.pages = pages
+		} else {
+			log.Printf("计算页码失败: %v", err)
+		}
+	}
+	return e
+}
+
+// value 计算域的结果，不支持的域返回 false
+//
+// 顺序编号域每次调用都会推进编号，需要按文档顺序对所有域调用。
+func (e *fieldEvaluator) value(f *fieldInstance) (string, bool) {
+	switch f.Type {
+	case FieldPage:
+		if f.anchor >= 0 && f.anchor < len(e.pages) {
+			return strconv.Itoa(e.pages[f.anchor]), true
+		}
+		return "1", true
+	case FieldNumPages:
+		total := 1
+		for _, page := range e.pages {
+			if page > total {
+				total = page
+			}
+		}
+		return strconv.Itoa(total), true
+	case FieldDate:
+		format := f.Format
+		if format == "" {
+			format = defaultDateFormat
+		}
+		return FormatFieldDate(e.now, format), true
+	case FieldAuthor:
+		return e.properties["Author"], true
+	case FieldTitle:
+		return e.properties["Title"], true
+	case FieldDocProperty:
+		if value, ok := e.property(f.Argument); ok {
+			return value, true
+		}
+		return errorUnknownProperty, true
+	case FieldRef:
+		return e.bookmarkText(f.Argument), true
+	case FieldSeq:
+		return e.sequence(f.FieldCode), true
+	}
+	return "", false
+}
+
+// property 按名称查找文档属性，名称不区分大小写
+func (e *fieldEvaluator) property(name string) (string, bool) {
+	if value, ok := e.properties[name]; ok {
+		return value, true
+	}
+	for key, value := range e.properties {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// bookmarkText 返回书签范围内的文字，跨段落的书签只取第一段中的部分
+func (e *fieldEvaluator) bookmarkText(name string) string {
+	bookmark, err := e.doc.FindBookmark(name)
+	if err != nil || bookmark.Paragraph < 0 {
+		return errorBookmarkNotDefined
+	}
+	text, err := e.doc.ParagraphText(bookmark.Paragraph)
+	if err != nil {
+		return errorBookmarkNotDefined
+	}
+	runes := []rune(text)
+	start, end := bookmark.Offset, len(runes)
+	if bookmark.EndParagraph == bookmark.Paragraph && bookmark.EndOffset < end {
+		end = bookmark.EndOffset
+	}
+	if start < 0 || start > end {
+		return ""
+	}
+	return string(runes[start:end])
+}
+
+// sequence 计算顺序编号，支持 \r 重新编号、\c 重复上一个编号和 \h 隐藏结果
+func (e *fieldEvaluator) sequence(code FieldCode) string {
+	key := strings.ToUpper(code.Argument)
+	if value, ok := code.switchValue(`\r`); ok {
+		if n, err := strconv.Atoi(value); err == nil {
+			e.sequences[key] = n
+		}
+	} else if _, ok := code.switchValue(`\c`); !ok {
+		e.sequences[key]++
+	}
+	if _, ok := code.switchValue(`\h`); ok {
+		return ""
+	}
+	return strconv.Itoa(e.sequences[key])
+}
+
+// UpdateFields 按文档属性和结构重新计算正文中所有支持的域，返回更新的域数量
+func (doc *Document) UpdateFields(opts FieldUpdateOptions) (int, error) {
+	body, err := doc.body()
+	if err != nil {
+		return 0, err
+	}
+	evaluator := doc.newFieldEvaluator(opts)
+	updated := 0
+	for _, f := range collectFields(body) {
+		value, ok := evaluator.value(f)
+		if !ok {
+			continue
+		}
+		if value != f.Result {
+			setFieldResult(body, f, value)
+			doc.IsModified = true
+		}
+		updated++
+	}
+	log.Printf("已更新域: %d 个", updated)
+	return updated, nil
+}
+
+// EvaluateFields 计算正文中支持的域按当前文档应显示的结果，键为域在 Fields 中的序号，不修改文档
+func (doc *Document) EvaluateFields(opts FieldUpdateOptions) (map[int]string, error) {
+	body, err := doc.body()
+	if err != nil {
+		return nil, err
+	}
+	evaluator := doc.newFieldEvaluator(opts)
+	values := make(map[int]string)
+	for _, f := range collectFields(body) {
+		if value, ok := evaluator.value(f); ok {
+			values[f.Index] = value
+		}
+	}
+	return values, nil
+}
+
+// fieldResultProperties 返回域结果第一段文字的字符格式的副本，没有结果时使用 begin 标记的格式
+func fieldResultProperties(f *fieldInstance) *Node {
+	if f.simple != nil {
+		for _, r := range f.simple.Find("w:r") {
+			if props := r.Child("w:rPr"); props != nil {
+				return props.Clone()
+			}
+		}
+		return nil
+	}
+	for _, r := range f.results {
+		if runTextLength(r) > 0 {
+			if props := r.Child("w:rPr"); props != nil {
+				return props.Clone()
+			}
+			break
+		}
+	}
+	if props := f.begin.Child("w:rPr"); props != nil {
+		return props.Clone()
+	}
+	return nil
+}
+
+// setFieldResult 用新的结果替换域原来的结果，新结果使用原结果第一段文字的格式
+func setFieldResult(body *Node, f *fieldInstance, value string) {
+	rPr := fieldResultProperties(f)
+	if f.simple != nil {
+		f.simple.Children = nil
+		if value != "" {
+			f.simple.AppendChild(newRunNode(value, rPr))
+		}
+		return
+	}
+
+	for _, r := range f.results {
+		if r != f.end {
+			if parent := findParent(body, r); parent != nil {
+				parent.RemoveChild(r)
+			}
+		}
+	}
+	parent := findParent(body, f.end)
+	if parent == nil {
+		return
+	}
+	var nodes []*Node
+	if f.separate == nil {
+		nodes = append(nodes, newFieldCharRun("separate", rPr))
+	}
+	if value != "" {
+		var props *Node
+		if rPr != nil {
+			props = rPr.Clone()
+		}
+		nodes = append(nodes, newRunNode(value, props))
+	}
+	parent.InsertChild(parent.IndexOf(f.end), nodes...)
+}
+
+// InsertField 在指定位置插入域并计算它的结果，返回域之后的位置
+//
+// 域以复杂域保存，使用插入位置前面文字的字符格式。
+func (doc *Document) InsertField(pos TextPosition, code FieldCode, opts FieldUpdateOptions) (TextPosition, error) {
+	if !code.Type.Supported() {
+		return pos, fmt.Errorf("不支持的域类型: %s", code.Type)
+	}
+	code.Argument = strings.TrimSpace(code.Argument)
+	if code.Type.NeedsArgument() && code.Argument == "" {
+		return pos, fmt.Errorf("%s 域需要参数", code.Type)
+	}
+	if code.Type == FieldRef {
+		if _, err := doc.FindBookmark(code.Argument); err != nil {
+			return pos, err
+		}
+	}
+	body, err := doc.body()
+	if err != nil {
+		return pos, err
+	}
+	p, err := doc.paragraphNode(pos.Paragraph)
+	if err != nil {
+		return pos, err
+	}
+	if length := len([]rune(paragraphNodeText(p))); pos.Offset < 0 || pos.Offset > length {
+		return pos, fmt.Errorf("文字位置超出段落范围: %d", pos.Offset)
+	}
+
+	ip := outsideField(splitRunsAt(p, pos.Offset))
+	nodes := newComplexField(" "+code.String()+" ", "", neighbourRunProperties(p, ip))
+	ip.insert(nodes...)
+
+	// 顺序编号等域的结果与前面的域有关，按文档顺序计算到新插入的域为止
+	evaluator := doc.newFieldEvaluator(opts)
+	for _, f := range collectFields(body) {
+		value, ok := evaluator.value(f)
+		if f.begin != nodes[0] {
+			continue
+		}
+		if ok {
+			setFieldResult(body, f, value)
+			pos.Offset += len([]rune(value))
+		}
+		break
+	}
+	doc.IsModified = true
+	log.Printf("已插入域: %s", code)
+	return pos, nil
+}
+
+// outsideField 插入点位于复杂域的结果开头时移到域的 begin 标记之前，避免新内容成为域结果的一部分
+func outsideField(ip insertPoint) insertPoint {
+	depth := 0
+	for i := ip.index - 1; i >= 0; i-- {
+		for _, fc := range ip.parent.Children[i].ChildrenNamed("w:fldChar") {
+			switch fc.Attr("w:fldCharType") {
+			case "begin":
+				depth--
+			case "end":
+				depth++
+			}
+		}
+		if depth < 0 {
+			ip.index, depth = i, 0
+		}
+	}
+	return ip
+}
+
+// chineseWeekdays 日期格式 dddd 使用的星期名称
+var chineseWeekdays = []string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"}
+
+// chineseMonths 日期格式 MMMM 使用的月份名称
+var chineseMonths = []string{"一月", "二月", "三月", "四月", "五月", "六月", "七月", "八月", "九月", "十月", "十一月", "十二月"}
+
+// FormatFieldDate 按Word日期格式（如 yyyy-MM-dd、yyyy年M月d日 dddd、HH:mm）格式化时间
+//
+// 单引号中的内容原样输出。
+func FormatFieldDate(t time.Time, format string) string {
+	var builder strings.Builder
+	runes := []rune(format)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if r == '\'' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '\'' {
+				end++
+			}
+			builder.WriteString(string(runes[i+1 : end]))
+			i = end + 1
+			continue
+		}
+		if strings.HasPrefix(string(runes[i:]), "AM/PM") || strings.HasPrefix(string(runes[i:]), "am/pm") {
+			marker := "AM"
+			if t.Hour() >= 12 {
+				marker = "PM"
+			}
+			if r == 'a' {
+				marker = strings.ToLower(marker)
+			}
+			builder.WriteString(marker)
+			i += 5
+			continue
+		}
+		count := 1
+		for i+count < len(runes) && runes[i+count] == r {
+			count++
+		}
+		i += count
+		switch r {
+		case 'y', 'Y':
+			if count <= 2 {
+				builder.WriteString(fmt.Sprintf("%02d", t.Year()%100))
+			} else {
+				builder.WriteString(strconv.Itoa(t.Year()))
+			}
+		case 'M':
+			switch {
+			case count >= 4:
+				builder.WriteString(chineseMonths[t.Month()-1])
+			case count == 3:
+				builder.WriteString(t.Month().String()[:3])
+			default:
+				builder.WriteString(padNumber(int(t.Month()), count))
+			}
+		case 'd', 'D':
+			switch {
+			case count >= 4:
+				builder.WriteString(chineseWeekdays[t.Weekday()])
+			case count == 3:
+				builder.WriteString(t.Weekday().String()[:3])
+			default:
+				builder.WriteString(padNumber(t.Day(), count))
+			}
+		case 'H':
+			builder.WriteString(padNumber(t.Hour(), count))
+		case 'h':
+			hour := t.Hour() % 12
+			if hour == 0 {
+				hour = 12
+			}
+			builder.WriteString(padNumber(hour, count))
+		case 'm':
+			builder.WriteString(padNumber(t.Minute(), count))
+		case 's', 'S':
+			builder.WriteString(padNumber(t.Second(), count))
+		default:
+			builder.WriteString(strings.Repeat(string(r), count))
+		}
+	}
+	return builder.String()
+}
+
+// padNumber 格式字母重复两次时补足两位数
+func padNumber(n, count int) string {
+	if count >= 2 {
+		return fmt.Sprintf("%02d", n)
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,162 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFieldCode(t *testing.T) {
+	tests := []struct {
+		instr string
+		want  FieldCode
+	}{
+		{"", FieldCode{}},
+		{" PAGE ", FieldCode{Type: FieldPage}},
+		{"page \\* MERGEFORMAT", FieldCode{Type: FieldPage, Switches: []string{`\*`, "MERGEFORMAT"}}},
+		{`DATE \@ "yyyy年M月d日"`, FieldCode{Type: FieldDate, Format: "yyyy年M月d日"}},
+		{`REF _Ref123 \h`, FieldCode{Type: FieldRef, Argument: "_Ref123", Switches: []string{`\h`}}},
+		{`SEQ Figure \* ARABIC \r 3`, FieldCode{Type: FieldSeq, Argument: "Figure", Switches: []string{`\*`, "ARABIC", `\r`, "3"}}},
+		{`DOCPROPERTY "Last Saved By"`, FieldCode{Type: FieldDocProperty, Argument: "Last Saved By"}},
+		{`DATE \@ "HH:mm" \* MERGEFORMAT`, FieldCode{Type: FieldDate, Format: "HH:mm", Switches: []string{`\*`, "MERGEFORMAT"}}},
+		{`DOCPROPERTY ""`, FieldCode{Type: FieldDocProperty}},
+	}
+	for _, tt := range tests {
+		if got := ParseFieldCode(tt.instr); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseFieldCode(%q) = %+v, 期望 %+v", tt.instr, got, tt.want)
+		}
+	}
+}
+
+func TestFieldCodeStringRoundTrip(t *testing.T) {
+	tests := []FieldCode{
+		{Type: FieldPage},
+		{Type: FieldDate, Format: "yyyy-MM-dd HH:mm"},
+		{Type: FieldDocProperty, Argument: "Last Saved By"},
+		{Type: FieldSeq, Argument: "表", Switches: []string{`\r`, "2", `\h`}},
+	}
+	for _, code := range tests {
+		if got := ParseFieldCode(code.String()); !reflect.DeepEqual(got, code) {
+			t.Errorf("ParseFieldCode(%q) = %+v, 期望 %+v", code.String(), got, code)
+		}
+	}
+}
+
+func TestFormatFieldDate(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 14, 7, 9, 0, time.UTC)
+	tests := []struct {
+		format, want string
+	}{
+		{"yyyy/M/d", "2024/3/5"},
+		{"yyyy-MM-dd", "2024-03-05"},
+		{"yy.M.d", "24.3.5"},
+		{"yyyy年M月d日 dddd", "2024年3月5日 星期二"},
+		{"MMMM", "三月"},
+		{"MMM ddd", "Mar Tue"},
+		{"HH:mm:ss", "14:07:09"},
+		{"h:mm AM/PM", "2:07 PM"},
+		{"hh am/pm", "02 pm"},
+		{"'第' d '天'", "第 5 天"},
+	}
+	for _, tt := range tests {
+		if got := FormatFieldDate(date, tt.format); got != tt.want {
+			t.Errorf("FormatFieldDate(%q) = %q, 期望 %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+// simpleField 返回以 w:fldSimple 保存的域
+func simpleField(instr, result string) string {
+	return `<w:fldSimple w:instr="` + instr + `"><w:r><w:t>` + result + `</w:t></w:r></w:fldSimple>`
+}
+
+// complexField 返回以 w:fldChar 保存的复杂域
+func complexField(instr, result string) string {
+	return `<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText xml:space="preserve"> ` + instr +
+		` </w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>` + result +
+		`</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r>`
+}
+
+func TestUpdateFields(t *testing.T) {
+	body := `<w:p><w:bookmarkStart w:id="0" w:name="目标"/><w:r><w:t>引用的文字</w:t></w:r><w:bookmarkEnd w:id="0"/></w:p>` +
+		`<w:p><w:r><w:t>图</w:t></w:r>` + simpleField(`SEQ 图`, "9") + `</w:p>` +
+		`<w:p><w:r><w:t>图</w:t></w:r>` + complexField(`SEQ 图 \* ARABIC`, "9") + `</w:p>` +
+		`<w:p>` + complexField(`SEQ 图 \c`, "9") + complexField(`SEQ 图 \h`, "9") + `</w:p>` +
+		`<w:p>` + complexField(`SEQ 图 \r 10`, "9") + simpleField(`SEQ 表`, "9") + `</w:p>` +
+		`<w:p>` + complexField(`DATE \@ "yyyy-MM-dd"`, "旧日期") + `</w:p>` +
+		`<w:p>` + complexField(`REF 目标 \h`, "旧") + simpleField(`REF 不存在`, "旧") + `</w:p>` +
+		`<w:p>` + complexField(`DOCPROPERTY 不存在的属性`, "旧") + complexField(`HYPERLINK "https://example.com"`, "链接") + `</w:p>`
+	want := []string{"1", "2", "2", "", "10", "1", "2024-03-05", "引用的文字",
+		errorBookmarkNotDefined, errorUnknownProperty, "链接"}
+
+	doc := newBodyTestDocument(t, body)
+	opts := FieldUpdateOptions{Now: time.Date(2024, time.March, 5, 0, 0, 0, 0, time.Local)}
+
+	values, err := doc.EvaluateFields(opts)
+	if err != nil {
+		t.Fatalf("计算域失败: %v", err)
+	}
+	if len(values) != len(want)-1 {
+		t.Errorf("计算了 %d 个域, 期望 %d 个", len(values), len(want)-1)
+	}
+	if _, ok := values[len(want)-1]; ok {
+		t.Errorf("不支持的域不应计算结果")
+	}
+
+	updated, err := doc.UpdateFields(opts)
+	if err != nil {
+		t.Fatalf("更新域失败: %v", err)
+	}
+	if updated != len(want)-1 {
+		t.Errorf("更新了 %d 个域, 期望 %d 个", updated, len(want)-1)
+	}
+	fields, err := doc.Fields()
+	if err != nil {
+		t.Fatalf("读取域失败: %v", err)
+	}
+	var results []string
+	for _, f := range fields {
+		results = append(results, f.Result)
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("更新后的域结果 %q, 期望 %q", results, want)
+	}
+	if got := paragraphTexts(doc)[2]; got != "图2" {
+		t.Errorf("更新后段落文字 %q, 期望 %q", got, "图2")
+	}
+}
+
+func TestInsertField(t *testing.T) {
+	doc := newTestDocument(t, "图：说明", "表")
+	opts := FieldUpdateOptions{Now: time.Date(2024, time.March, 5, 0, 0, 0, 0, time.Local)}
+
+	pos, err := doc.InsertField(TextPosition{Offset: 1}, FieldCode{Type: FieldSeq, Argument: "图"}, opts)
+	if err != nil {
+		t.Fatalf("插入域失败: %v", err)
+	}
+	if pos.Offset != 2 {
+		t.Errorf("插入后的位置 %d, 期望 2", pos.Offset)
+	}
+	if _, err := doc.InsertField(TextPosition{Paragraph: 1, Offset: 1}, FieldCode{Type: FieldDate, Format: "M月d日"}, opts); err != nil {
+		t.Fatalf("插入日期域失败: %v", err)
+	}
+	if got, want := paragraphTexts(doc), []string{"图1：说明", "表3月5日"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("插入域后 %q, 期望 %q", got, want)
+	}
+
+	errorTests := []struct {
+		name string
+		pos  TextPosition
+		code FieldCode
+	}{
+		{"不支持的域", TextPosition{}, FieldCode{Type: "HYPERLINK"}},
+		{"缺少参数", TextPosition{}, FieldCode{Type: FieldSeq, Argument: " "}},
+		{"书签不存在", TextPosition{}, FieldCode{Type: FieldRef, Argument: "不存在"}},
+		{"位置超出段落", TextPosition{Offset: 100}, FieldCode{Type: FieldPage}},
+	}
+	for _, tt := range errorTests {
+		if _, err := doc.InsertField(tt.pos, tt.code, opts); err == nil {
+			t.Errorf("%s: 没有返回错误", tt.name)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// 页眉页脚相关的部件类型
+const (
+	relTypeHeader       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/header"
+	relTypeFooter       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer"
+	relTypeSettings     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/settings"
+	contentTypeHeader   = "application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"
+	contentTypeFooter   = "application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"
+	contentTypeSettings = "application/vnd.openxmlformats-officedocument.wordprocessingml.settings+xml"
+)
+
+// 页眉页脚类型，对应 w:headerReference 的 w:type
+const (
+	HeaderFooterDefault = "default" // 默认
+	HeaderFooterFirst   = "first"   // 首页
+	HeaderFooterEven    = "even"    // 偶数页
+)
+
+// HeaderFooterTypeName 返回页眉页脚类型的中文名称
+func HeaderFooterTypeName(hfType string) string {
+	switch hfType {
+	case HeaderFooterFirst:
+		return "首页"
+	case HeaderFooterEven:
+		return "偶数页"
+	}
+	return "默认"
+}
+
+// HeaderFooterKind 区分页眉和页脚
+type HeaderFooterKind int
+
+const (
+	HeaderKind HeaderFooterKind = iota // 页眉
+	FooterKind                         // 页脚
+)
+
+// String 返回页眉或页脚的中文名称
+func (k HeaderFooterKind) String() string {
+	if k == FooterKind {
+		return "页脚"
+	}
+	return "页眉"
+}
+
+// referenceName 返回节属性中引用该类部件的元素名
+func (k HeaderFooterKind) referenceName() string {
+	if k == FooterKind {
+		return "w:footerReference"
+	}
+	return "w:headerReference"
+}
+
+// HeaderFooter 节中的一个页眉或页脚
+type HeaderFooter struct {
+	Section    int              // 节索引
+	Kind       HeaderFooterKind // 页眉或页脚
+	Type       string           // default、first 或 even
+	Part       string           // 部件名称，如 word/header1.xml
+	Paragraphs []string         // 段落的可编辑文本，页码域显示为 {PAGE}、{NUMPAGES}
+}
+
+// sectionNodes 返回文档中所有节的属性节点，按节的顺序排列
+//
+// 除最后一节外，每节的属性位于该节最后一个段落的 w:pPr/w:sectPr 中，
+// 最后一节的属性是正文的 w:sectPr，create 为真时在正文缺少 w:sectPr 时创建。
+func (doc *Document) sectionNodes(create bool) ([]*Node, error) {
+	body, err := doc.body()
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []*Node
+	for _, p := range collectParagraphs(body) {
+		if pPr := p.Child("w:pPr"); pPr != nil {
+			if sectPr := pPr.Child("w:sectPr"); sectPr != nil {
+				sections = append(sections, sectPr)
+			}
+		}
+	}
+	sectPr := body.Child("w:sectPr")
+	if sectPr == nil {
+		if !create {
+			return sections, nil
+		}
+		sectPr = NewNode("w:sectPr")
+		body.AppendChild(sectPr)
+	}
+	return append(sections, sectPr), nil
+}
+
+// SectionCount 返回文档的节数
+func (doc *Document) SectionCount() int {
+	sections, err := doc.sectionNodes(false)
+	if err != nil {
+		return 0
+	}
+	return len(sections)
+}
+
+// HeadersFooters 返回所有节中直接引用的页眉和页脚
+//
+// 没有引用某类页眉页脚的节沿用前一节的设置，这种继承关系不在结果中展开。
+func (doc *Document) HeadersFooters() ([]HeaderFooter, error) {
+	sections, err := doc.sectionNodes(false)
+	if err != nil {
+		return nil, err
+	}
+
+	main := doc.Package.MainPartName()
+	var result []HeaderFooter
+	for i, sectPr := range sections {
+		for _, kind := range []HeaderFooterKind{HeaderKind, FooterKind} {
+			for _, ref := range sectPr.ChildrenNamed(kind.referenceName()) {
+				rel, ok := doc.Package.Relationship(main, ref.Attr("r:id"))
+				if !ok {
+					continue
+				}
+				hf := HeaderFooter{
+					Section: i,
+					Kind:    kind,
+					Type:    referenceType(ref),
+					Part:    doc.Package.ResolveTarget(main, rel.Target),
+				}
+				if root, err := doc.Package.XML(hf.Part); err == nil {
+					for _, p := range collectParagraphs(root) {
+						hf.Paragraphs = append(hf.Paragraphs, paragraphEditText(p))
+					}
+				}
+				result = append(result, hf)
+			}
+		}
+	}
+	return result, nil
+}
+
+// SetHeaderFooterParagraph 修改页眉页脚部件中指定段落的文字，{PAGE}、{NUMPAGES} 转换为页码域
+func (doc *Document) SetHeaderFooterParagraph(part string, index int, text string) error {
+	if doc.Package == nil {
+		return fmt.Errorf("文档不支持页眉页脚")
+	}
+	root, err := doc.Package.XML(part)
+	if err != nil {
+		return err
+	}
+	paragraphs := collectParagraphs(root)
+	if index < 0 || index >= len(paragraphs) {
+		return fmt.Errorf("段落索引超出范围: %d", index+1)
+	}
+
+	p := paragraphs[index]
+	if paragraphEditText(p) == text {
+		return nil
+	}
+	if err := setParagraphEditText(p, text); err != nil {
+		return err
+	}
+	doc.IsModified = true
+	log.Printf("已修改 %s 的段落 %d", part, index+1)
+	return nil
+}
+
+// AddHeaderFooter 为节添加页眉或页脚，text 中的换行拆分为多个段落
+//
+// 该节已有同类型的页眉页脚时返回错误。首页页眉页脚会同时启用节的首页不同，
+// 偶数页页眉页脚会同时启用文档的奇偶页不同。
+func (doc *Document) AddHeaderFooter(section int, kind HeaderFooterKind, hfType, text string) (HeaderFooter, error) {
+	sections, err := doc.sectionNodes(true)
+	if err != nil {
+		return HeaderFooter{}, err
+	}
+	if section < 0 || section >= len(sections) {
+		return HeaderFooter{}, fmt.Errorf("节索引超出范围: %d", section+1)
+	}
+	sectPr := sections[section]
+	for _, ref := range sectPr.ChildrenNamed(kind.referenceName()) {
+		if referenceType(ref) == hfType {
+			return HeaderFooter{}, fmt.Errorf("第 %d 节已有%s%s", section+1, HeaderFooterTypeName(hfType), kind)
+		}
+	}
+
+	// 创建部件
+	rootName, relType, contentType, prefix := "w:hdr", relTypeHeader, contentTypeHeader, "header"
+	if kind == FooterKind {
+		rootName, relType, contentType, prefix = "w:ftr", relTypeFooter, contentTypeFooter, "footer"
+	}
+	part := ""
+	for i := 1; part == "" || doc.Package.HasPart(part); i++ {
+		part = fmt.Sprintf("word/%s%d.xml", prefix, i)
+	}
+	root := NewNode(rootName, "xmlns:w", nsWordML, "xmlns:r", nsRelationships)
+	for _, line := range strings.Split(text, "\n") {
+		p := NewNode("w:p")
+		p.AppendChild(textWithFields(line, nil)...)
+		root.AppendChild(p)
+	}
+	doc.Package.SetXMLPart(part, root, contentType)
+
+	main := doc.Package.MainPartName()
+	id := doc.Package.AddRelationship(main, relType, relativeTarget(main, part), false)
+
+	// 页眉页脚引用必须位于节属性的最前面
+	ref := NewNode(kind.referenceName(), "w:type", hfType, "r:id", id)
+	position := 0
+	for i, c := range sectPr.Children {
+		if c.Name == "w:headerReference" || c.Name == "w:footerReference" {
+			position = i + 1
+		}
+	}
+	sectPr.InsertChild(position, ref)
+
+	switch hfType {
+	case HeaderFooterFirst:
+		sectPr.EnsureChild("w:titlePg", sectPrOrder)
+	case HeaderFooterEven:
+		if err := doc.enableEvenAndOddHeaders(); err != nil {
+			return HeaderFooter{}, err
+		}
+	}
+
+	doc.IsModified = true
+	log.Printf("已为第 %d 节添加%s%s: %s", section+1, HeaderFooterTypeName(hfType), kind, part)
+	return HeaderFooter{
+		Section:    section,
+		Kind:       kind,
+		Type:       hfType,
+		Part:       part,
+		Paragraphs: strings.Split(text, "\n"),
+	}, nil
+}
+
+// RemoveHeaderFooter 删除节中的页眉或页脚，部件不再被其它节引用时一并删除
+func (doc *Document) RemoveHeaderFooter(section int, kind HeaderFooterKind, hfType string) error {
+	sections, err := doc.sectionNodes(false)
+	if err != nil {
+		return err
+	}
+	if section < 0 || section >= len(sections) {
+		return fmt.Errorf("节索引超出范围: %d", section+1)
+	}
+
+	sectPr := sections[section]
+	var ref *Node
+	for _, c := range sectPr.ChildrenNamed(kind.referenceName()) {
+		if referenceType(c) == hfType {
+			ref = c
+		}
+	}
+	if ref == nil {
+		return fmt.Errorf("第 %d 节没有%s%s", section+1, HeaderFooterTypeName(hfType), kind)
+	}
+	sectPr.RemoveChild(ref)
+
+	// 没有首页页眉页脚后取消首页不同，避免首页显示空白页眉页脚
+	if hfType == HeaderFooterFirst && !hasReferenceType(sectPr, HeaderFooterFirst) {
+		sectPr.RemoveChildrenNamed("w:titlePg")
+	}
+
+	id := ref.Attr("r:id")
+	for _, other := range sections {
+		for _, c := range other.ChildrenNamed(kind.referenceName()) {
+			if c.Attr("r:id") == id {
+				doc.IsModified = true
+				return nil
+			}
+		}
+	}
+
+	main := doc.Package.MainPartName()
+	if rel, ok := doc.Package.Relationship(main, id); ok {
+		doc.Package.RemovePart(doc.Package.ResolveTarget(main, rel.Target))
+	}
+	doc.Package.RemoveRelationship(main, id)
+
+	doc.IsModified = true
+	log.Printf("已删除第 %d 节的%s%s", section+1, HeaderFooterTypeName(hfType), kind)
+	return nil
+}
+
+// hasReferenceType 判断节属性中是否有指定类型的页眉或页脚引用
+func hasReferenceType(sectPr *Node, hfType string) bool {
+	for _, c := range sectPr.Children {
+		if (c.Name == "w:headerReference" || c.Name == "w:footerReference") && referenceType(c) == hfType {
+			return true
+		}
+	}
+	return false
+}
+
+// referenceType 返回页眉页脚引用的类型，未指定时为默认
+func referenceType(ref *Node) string {
+	if hfType := ref.Attr("w:type"); hfType != "" {
+		return hfType
+	}
+	return HeaderFooterDefault
+}
+
+// enableEvenAndOddHeaders 在文档设置中启用奇偶页不同
+func (doc *Document) enableEvenAndOddHeaders() error {
+	settings, err := doc.settingsRoot()
+	if err != nil {
+		return err
+	}
+	settings.EnsureChild("w:evenAndOddHeaders", settingsOrder)
+	return nil
+}
+
+// settingsRoot 返回文档设置部件的根节点，不存在时创建
+func (doc *Document) settingsRoot() (*Node, error) {
+	_, root, err := doc.Package.ensureRelatedPart(relTypeSettings, "word/settings.xml", contentTypeSettings,
+		func() *Node {
+			return NewNode("w:settings", "xmlns:w", nsWordML)
+		})
+	return root, err
+}
@@ -0,0 +1,133 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+// sectionBreakBody 两节文档的正文，第一节的节属性位于第一段中
+const sectionBreakBody = `<w:p><w:pPr><w:sectPr><w:pgSz w:w="11906" w:h="16838"/></w:sectPr></w:pPr><w:r><w:t>第一节</w:t></w:r></w:p>` +
+	`<w:p><w:r><w:t>第二节</w:t></w:r></w:p>`
+
+// elementNames 返回节点子元素的名称
+func elementNames(n *Node) []string {
+	var names []string
+	for _, c := range n.Elements() {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestAddHeaderFooter(t *testing.T) {
+	doc := newTestDocument(t, "正文")
+	hf, err := doc.AddHeaderFooter(0, HeaderKind, HeaderFooterDefault, "公司名称\n第{PAGE}页")
+	if err != nil {
+		t.Fatalf("添加页眉失败: %v", err)
+	}
+	if hf.Part != "word/header1.xml" || !doc.Package.HasPart(hf.Part) {
+		t.Fatalf("页眉部件 %q", hf.Part)
+	}
+	if _, err := doc.AddHeaderFooter(0, HeaderKind, HeaderFooterDefault, "重复"); err == nil {
+		t.Errorf("重复添加默认页眉没有返回错误")
+	}
+	if _, err := doc.AddHeaderFooter(1, HeaderKind, HeaderFooterDefault, "页眉"); err == nil {
+		t.Errorf("为不存在的节添加页眉没有返回错误")
+	}
+	if _, err := doc.AddHeaderFooter(0, FooterKind, HeaderFooterFirst, "首页页脚"); err != nil {
+		t.Fatalf("添加首页页脚失败: %v", err)
+	}
+	if _, err := doc.AddHeaderFooter(0, HeaderKind, HeaderFooterEven, "偶数页页眉"); err != nil {
+		t.Fatalf("添加偶数页页眉失败: %v", err)
+	}
+
+	got, err := doc.HeadersFooters()
+	if err != nil {
+		t.Fatalf("读取页眉页脚失败: %v", err)
+	}
+	want := []HeaderFooter{
+		{Kind: HeaderKind, Type: HeaderFooterDefault, Part: "word/header1.xml", Paragraphs: []string{"公司名称", "第{PAGE}页"}},
+		{Kind: HeaderKind, Type: HeaderFooterEven, Part: "word/header2.xml", Paragraphs: []string{"偶数页页眉"}},
+		{Kind: FooterKind, Type: HeaderFooterFirst, Part: "word/footer1.xml", Paragraphs: []string{"首页页脚"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("页眉页脚 %+v, 期望 %+v", got, want)
+	}
+
+	// 页码写为域，引用位于节属性的最前面
+	root, _ := doc.Package.XML("word/header1.xml")
+	if fields := root.Find("w:fldSimple"); len(fields) != 1 || fields[0].Attr("w:instr") != " PAGE " {
+		t.Errorf("页眉中的页码域: %s", root)
+	}
+	sectPr := testBody(t, doc).Child("w:sectPr")
+	names := elementNames(sectPr)
+	if len(names) < 4 || names[0] != "w:headerReference" || names[1] != "w:footerReference" || names[2] != "w:headerReference" {
+		t.Errorf("节属性的子元素 %q", names)
+	}
+	if sectPr.Child("w:titlePg") == nil {
+		t.Errorf("添加首页页脚后没有启用首页不同")
+	}
+	settings, err := doc.settingsRoot()
+	if err != nil || settings.Child("w:evenAndOddHeaders") == nil {
+		t.Errorf("添加偶数页页眉后没有启用奇偶页不同 (%v)", err)
+	}
+}
+
+func TestRemoveHeaderFooter(t *testing.T) {
+	doc := newBodyTestDocument(t, sectionBreakBody)
+	hf, err := doc.AddHeaderFooter(0, HeaderKind, HeaderFooterDefault, "共用页眉")
+	if err != nil {
+		t.Fatalf("添加页眉失败: %v", err)
+	}
+	// 第二节引用同一个页眉部件
+	sections, _ := doc.sectionNodes(false)
+	ref := sections[0].Child("w:headerReference").Clone()
+	sections[1].InsertChild(0, ref)
+	id := ref.Attr("r:id")
+
+	if err := doc.RemoveHeaderFooter(0, HeaderKind, HeaderFooterDefault); err != nil {
+		t.Fatalf("删除页眉失败: %v", err)
+	}
+	if !doc.Package.HasPart(hf.Part) {
+		t.Errorf("部件仍被第二节引用时被删除")
+	}
+	if err := doc.RemoveHeaderFooter(0, HeaderKind, HeaderFooterDefault); err == nil {
+		t.Errorf("删除不存在的页眉没有返回错误")
+	}
+	if err := doc.RemoveHeaderFooter(1, HeaderKind, HeaderFooterDefault); err != nil {
+		t.Fatalf("删除页眉失败: %v", err)
+	}
+	if doc.Package.HasPart(hf.Part) {
+		t.Errorf("不再被引用的页眉部件没有删除")
+	}
+	if _, ok := doc.Package.Relationship(doc.Package.MainPartName(), id); ok {
+		t.Errorf("不再被引用的页眉关系没有删除")
+	}
+	if got, _ := doc.HeadersFooters(); len(got) != 0 {
+		t.Errorf("删除后仍有页眉页脚 %+v", got)
+	}
+}
+
+func TestRemoveFirstPageHeaderFooter(t *testing.T) {
+	doc := newBodyTestDocument(t, sectionBreakBody)
+	for _, kind := range []HeaderFooterKind{HeaderKind, FooterKind} {
+		if _, err := doc.AddHeaderFooter(1, kind, HeaderFooterFirst, "首页"); err != nil {
+			t.Fatalf("添加首页%s失败: %v", kind, err)
+		}
+	}
+	sectPr := testBody(t, doc).Child("w:sectPr")
+	if err := doc.RemoveHeaderFooter(1, HeaderKind, HeaderFooterFirst); err != nil {
+		t.Fatalf("删除首页页眉失败: %v", err)
+	}
+	if sectPr.Child("w:titlePg") == nil {
+		t.Errorf("还有首页页脚时取消了首页不同")
+	}
+	if err := doc.RemoveHeaderFooter(1, FooterKind, HeaderFooterFirst); err != nil {
+		t.Fatalf("删除首页页脚失败: %v", err)
+	}
+	if sectPr.Child("w:titlePg") != nil {
+		t.Errorf("没有首页页眉页脚后仍启用首页不同")
+	}
+	if sections, _ := doc.sectionNodes(false); len(sections[0].Elements()) != 1 {
+		t.Errorf("修改第二节改变了第一节的属性: %s", sections[0])
+	}
+}
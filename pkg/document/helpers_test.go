@@ -0,0 +1,105 @@
+package document
+
+import "testing"
+
+// newTestDocument 创建基于空白OPC包的文档，每个参数为一个正文段落
+func newTestDocument(t *testing.T, paragraphs ...string) *Document {
+	t.Helper()
+	doc := &Document{FileName: "测试.docx", Package: NewPackage(), IsOpen: true}
+	if len(paragraphs) == 0 {
+		return doc
+	}
+	body := testBody(t, doc)
+	body.RemoveChildrenNamed("w:p")
+	sectPr := body.Child("w:sectPr")
+	for _, text := range paragraphs {
+		p := newParagraphNode("")
+		if text != "" {
+			p.AppendChild(newRunNode(text, nil))
+		}
+		body.InsertChild(body.IndexOf(sectPr), p)
+	}
+	return doc
+}
+
+// newBodyTestDocument 创建正文为指定WordprocessingML片段的测试文档
+func newBodyTestDocument(t *testing.T, bodyXML string) *Document {
+	t.Helper()
+	doc := newTestDocument(t)
+	parsed, err := ParseXML([]byte("<w:body>" + bodyXML + "</w:body>"))
+	if err != nil {
+		t.Fatalf("解析测试正文失败: %v", err)
+	}
+	body := testBody(t, doc)
+	body.Children = append(parsed.Children, body.ChildrenNamed("w:sectPr")...)
+	return doc
+}
+
+// testBody 返回测试文档的正文节点
+func testBody(t *testing.T, doc *Document) *Node {
+	t.Helper()
+	body, err := doc.body()
+	if err != nil {
+		t.Fatalf("读取正文失败: %v", err)
+	}
+	return body
+}
+
+// paragraphTexts 返回文档所有正文段落的文字
+func paragraphTexts(doc *Document) []string {
+	var texts []string
+	for _, p := range doc.paragraphNodes() {
+		texts = append(texts, paragraphNodeText(p))
+	}
+	return texts
+}
+
+// styledParagraph 返回使用指定样式的段落XML
+func styledParagraph(style, text string) string {
+	return `<w:p><w:pPr><w:pStyle w:val="` + style + `"/></w:pPr><w:r><w:t>` + text + `</w:t></w:r></w:p>`
+}
+
+// parseTestParagraph 解析测试用的段落XML
+func parseTestParagraph(t *testing.T, xml string) *Node {
+	t.Helper()
+	p, err := ParseXML([]byte(xml))
+	if err != nil {
+		t.Fatalf("解析测试段落失败: %v", err)
+	}
+	return p
+}
+
+// testStyles 返回测试文档中按ID索引的样式
+func testStyles(t *testing.T, doc *Document) map[string]*Node {
+	t.Helper()
+	part, _ := doc.Package.RelatedPart(doc.Package.MainPartName(), relTypeStyles)
+	root, err := doc.Package.XML(part)
+	if err != nil {
+		t.Fatal(err)
+	}
+	styles := make(map[string]*Node)
+	for _, style := range root.ChildrenNamed("w:style") {
+		styles[style.Attr("w:styleId")] = style
+	}
+	return styles
+}
+
+// testNumbering 测试用的编号定义：
+// 抽象编号 1 为两级编号（1. 和 1.1）；抽象编号 2 为项目符号；
+// 抽象编号 3 为中文章节编号，第二级使用 w:isLgl；
+// 实例 4 引用抽象编号 1 并从 5 开始。
+const testNumbering = `<w:abstractNum w:abstractNumId="1">
+	<w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="decimal"/><w:lvlText w:val="%1."/></w:lvl>
+	<w:lvl w:ilvl="1"><w:start w:val="1"/><w:numFmt w:val="lowerLetter"/><w:lvlText w:val="%1.%2)"/></w:lvl>
+</w:abstractNum>
+<w:abstractNum w:abstractNumId="2">
+	<w:lvl w:ilvl="0"><w:numFmt w:val="bullet"/><w:lvlText w:val="` + "" + `"/></w:lvl>
+</w:abstractNum>
+<w:abstractNum w:abstractNumId="3">
+	<w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="chineseCounting"/><w:lvlText w:val="第%1章"/></w:lvl>
+	<w:lvl w:ilvl="1"><w:start w:val="1"/><w:numFmt w:val="decimal"/><w:isLgl/><w:lvlText w:val="%1.%2"/></w:lvl>
+</w:abstractNum>
+<w:num w:numId="1"><w:abstractNumId w:val="1"/></w:num>
+<w:num w:numId="2"><w:abstractNumId w:val="2"/></w:num>
+<w:num w:numId="3"><w:abstractNumId w:val="3"/></w:num>
+<w:num w:numId="4"><w:abstractNumId w:val="1"/><w:lvlOverride w:ilvl="0"><w:startOverride w:val="5"/></w:lvlOverride></w:num>`
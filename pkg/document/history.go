@@ -0,0 +1,85 @@
+package document
+
+import (
+	"fmt"
+	"log"
+)
+
+// historyLimit 最多保留的撤销步数
+const historyLimit = 100
+
+// historyStep 一步可以撤销或重做的操作
+type historyStep struct {
+	label string   // 操作名称，显示在撤销菜单中
+	state *Package // 撤销记录中为操作前的内容，重做记录中为撤销前的内容
+}
+
+// history 文档的撤销和重做记录，每一步保存整个OPC包的副本
+type history struct {
+	undo []historyStep
+	redo []historyStep
+}
+
+// Checkpoint 在修改文档之前调用，记录当前内容，之后的修改作为名为 label 的一步撤销
+//
+// 新的记录会清空重做记录。只有基于OPC包的文档支持撤销。
+func (doc *Document) Checkpoint(label string) {
+	if doc.Package == nil {
+		return
+	}
+	if doc.history == nil {
+		doc.history = &history{}
+	}
+	h := doc.history
+	h.undo = append(h.undo, historyStep{label: label, state: doc.Package.Clone()})
+	if len(h.undo) > historyLimit {
+		h.undo = h.undo[len(h.undo)-historyLimit:]
+	}
+	h.redo = nil
+}
+
+// UndoLabel 返回可以撤销的操作名称，没有可撤销的操作时返回 false
+func (doc *Document) UndoLabel() (string, bool) {
+	if doc.history == nil || len(doc.history.undo) == 0 {
+		return "", false
+	}
+	return doc.history.undo[len(doc.history.undo)-1].label, true
+}
+
+// RedoLabel 返回可以重做的操作名称，没有可重做的操作时返回 false
+func (doc *Document) RedoLabel() (string, bool) {
+	if doc.history == nil || len(doc.history.redo) == 0 {
+		return "", false
+	}
+	return doc.history.redo[len(doc.history.redo)-1].label, true
+}
+
+// Undo 撤销最近一步操作，返回操作名称
+func (doc *Document) Undo() (string, error) {
+	if _, ok := doc.UndoLabel(); !ok {
+		return "", fmt.Errorf("没有可以撤销的操作")
+	}
+	h := doc.history
+	step := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, historyStep{label: step.label, state: doc.Package.Clone()})
+	doc.Package.restore(step.state)
+	doc.IsModified = true
+	log.Printf("已撤销: %s", step.label)
+	return step.label, nil
+}
+
+// Redo 重做最近撤销的操作，返回操作名称
+func (doc *Document) Redo() (string, error) {
+	if _, ok := doc.RedoLabel(); !ok {
+		return "", fmt.Errorf("没有可以重做的操作")
+	}
+	h := doc.history
+	step := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, historyStep{label: step.label, state: doc.Package.Clone()})
+	doc.Package.restore(step.state)
+	doc.IsModified = true
+	log.Printf("已重做: %s", step.label)
+	return step.label, nil
+}
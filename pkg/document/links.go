@@ -0,0 +1,601 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// relTypeHyperlink 外部超链接的关系类型
+const relTypeHyperlink = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+
+// hyperlinkStyleID 新建的超链接字符样式ID
+const hyperlinkStyleID = "Hyperlink"
+
+// bookmarkNamePattern Word允许的书签名称：以字母开头，由字母、数字和下划线组成，最多40个字符
+var bookmarkNamePattern = regexp.MustCompile(`^\pL[\pL\pN_]{0,39}$`)
+
+// LinkTarget 超链接的目标
+type LinkTarget struct {
+	URL     string // 外部链接地址，链接到文档中的书签时为空
+	Anchor  string // 书签名称；与 URL 同时存在时是地址中 # 之后的部分
+	Tooltip string // 屏幕提示
+}
+
+// Link 返回链接目标的字符串形式：外部地址，或 # 加书签名称
+func (t LinkTarget) Link() string {
+	if t.URL == "" {
+		return "#" + t.Anchor
+	}
+	if t.Anchor != "" {
+		return t.URL + "#" + t.Anchor
+	}
+	return t.URL
+}
+
+// validate 检查链接目标
+func (t LinkTarget) validate() error {
+	if strings.TrimSpace(t.URL) == "" && strings.TrimSpace(t.Anchor) == "" {
+		return fmt.Errorf("请输入链接地址或选择书签")
+	}
+	return nil
+}
+
+// Hyperlink 正文中的超链接
+type Hyperlink struct {
+	LinkTarget
+	Index     int    // 在 Hyperlinks 结果中的序号，修改和删除超链接时使用
+	Paragraph int    // 所在的正文段落，位于表格中时为-1
+	Offset    int    // 在段落最终文本中的起始位置
+	Length    int    // 显示文字的字符数
+	Text      string // 显示的文字
+}
+
+// Bookmark 正文中的书签
+type Bookmark struct {
+	ID           string
+	Name         string
+	Paragraph    int  // 书签开始所在的正文段落，位于表格中时为-1
+	Offset       int  // 书签开始在段落最终文本中的位置
+	EndParagraph int  // 书签结束所在的正文段落，位于表格中或没有结束标记时为-1
+	EndOffset    int  // 书签结束在段落最终文本中的位置
+	Hidden       bool // 以下划线开头的隐藏书签，例如目录使用的 _Toc 书签
+}
+
+// markerPosition 超链接或书签标记在正文段落中的位置
+type markerPosition struct {
+	paragraph  int
+	start, end int
+}
+
+// markerPositions 返回正文段落中超链接和书签标记的位置
+//
+// 位于段落之间的书签开始标记属于下一段的开头，结束标记属于上一段的末尾。
+func markerPositions(body *Node) map[*Node]markerPosition {
+	positions := make(map[*Node]markerPosition)
+	index, lastLength := 0, 0
+	var pending []*Node
+
+	var inline func(n *Node, offset int) int
+	inline = func(n *Node, offset int) int {
+		for _, c := range n.Children {
+			switch {
+			case c.Name == "w:r":
+				offset += runTextLength(c)
+			case c.Name == "w:bookmarkStart" || c.Name == "w:bookmarkEnd":
+				positions[c] = markerPosition{paragraph: index, start: offset, end: offset}
+			case c.Name == "w:ins" || c.Name == "w:moveTo" || containerElements[c.Name]:
+				start := offset
+				offset = inline(c, offset)
+				if c.Name == "w:hyperlink" {
+					positions[c] = markerPosition{paragraph: index, start: start, end: offset}
+				}
+			}
+		}
+		return offset
+	}
+
+	var blocks func(container *Node)
+	blocks = func(container *Node) {
+		for _, c := range container.Children {
+			switch c.Name {
+			case "w:p":
+				for _, marker := range pending {
+					positions[marker] = markerPosition{paragraph: index}
+				}
+				pending = nil
+				lastLength = inline(c, 0)
+				index++
+			case "w:sdt", "w:sdtContent", "w:customXml":
+				blocks(c)
+			case "w:bookmarkStart":
+				pending = append(pending, c)
+			case "w:bookmarkEnd":
+				if index > 0 {
+					positions[c] = markerPosition{paragraph: index - 1, start: lastLength, end: lastLength}
+				}
+			}
+		}
+	}
+	blocks(body)
+	return positions
+}
+
+// hyperlinkTarget 读取超链接元素的目标，外部地址通过主文档的关系解析
+func (doc *Document) hyperlinkTarget(n *Node) LinkTarget {
+	target := LinkTarget{Anchor: n.Attr("w:anchor"), Tooltip: n.Attr("w:tooltip")}
+	if id := n.Attr("r:id"); id != "" && doc.Package != nil {
+		if rel, ok := doc.Package.Relationship(doc.Package.MainPartName(), id); ok {
+			target.URL = rel.Target
+		}
+	}
+	return target
+}
+
+// Hyperlinks 返回正文中的所有超链接，包括表格和目录中的超链接
+func (doc *Document) Hyperlinks() ([]Hyperlink, error) {
+	body, err := doc.body()
+	if err != nil {
+		return nil, err
+	}
+	positions := markerPositions(body)
+	var links []Hyperlink
+	for i, n := range body.Find("w:hyperlink") {
+		link := Hyperlink{
+			LinkTarget: doc.hyperlinkTarget(n),
+			Index:      i,
+			Paragraph:  -1,
+			Text:       paragraphNodeText(n),
+		}
+		if pos, ok := positions[n]; ok {
+			link.Paragraph, link.Offset, link.Length = pos.paragraph, pos.start, pos.end-pos.start
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// HyperlinkAt 返回包含段落中指定位置的超链接，位置在超链接的文字之后时也算包含
+func (doc *Document) HyperlinkAt(paragraph, offset int) (Hyperlink, bool) {
+	links, err := doc.Hyperlinks()
+	if err != nil {
+		return Hyperlink{}, false
+	}
+	for _, link := range links {
+		if link.Paragraph == paragraph && link.Length > 0 && offset >= link.Offset && offset <= link.Offset+link.Length {
+			return link, true
+		}
+	}
+	return Hyperlink{}, false
+}
+
+// InsertHyperlink 将段落中范围内的文字设置为超链接，并使用超链接字符样式
+//
+// 范围必须在同一段落中且不为空，与范围重叠的已有超链接先被取消。
+// 链接到书签时书签必须存在。
+func (doc *Document) InsertHyperlink(r TextRange, target LinkTarget) error {
+	if err := target.validate(); err != nil {
+		return err
+	}
+	if target.URL == "" {
+		if _, err := doc.FindBookmark(target.Anchor); err != nil {
+			return err
+		}
+	}
+	body, err := doc.body()
+	if err != nil {
+		return err
+	}
+	if r.StartParagraph != r.EndParagraph {
+		return fmt.Errorf("超链接不能跨越多个段落")
+	}
+	p, err := doc.paragraphNode(r.StartParagraph)
+	if err != nil {
+		return err
+	}
+	length := len([]rune(paragraphNodeText(p)))
+	start, end := r.StartOffset, r.EndOffset
+	if end < 0 || end > length {
+		end = length
+	}
+	if start < 0 || start >= end {
+		return fmt.Errorf("请先选择要设置为超链接的文字")
+	}
+
+	// 取消与范围重叠的超链接
+	positions := markerPositions(body)
+	for _, n := range p.Find("w:hyperlink") {
+		if pos, ok := positions[n]; ok && pos.start < end && pos.end > start {
+			doc.unwrapHyperlink(body, n)
+		}
+	}
+
+	styleID, err := doc.hyperlinkStyle()
+	if err != nil {
+		return err
+	}
+	splitRunsAt(p, end)
+	splitRunsAt(p, start)
+
+	// 按所在容器分组，跨越修订等容器的范围在每个容器中各建一个目标相同的超链接
+	type group struct {
+		parent      *Node
+		depth       int
+		first, last int
+	}
+	var groups []*group
+	byParent := make(map[*Node]*group)
+	pos := 0
+	var walk func(n *Node, depth int)
+	walk = func(n *Node, depth int) {
+		for i, c := range n.Children {
+			switch {
+			case c.Name == "w:r":
+				length := runTextLength(c)
+				if length > 0 && pos >= start && pos+length <= end {
+					runProperties(c).EnsureChild("w:rStyle", rPrOrder).SetAttr("w:val", styleID)
+					g := byParent[n]
+					if g == nil {
+						g = &group{parent: n, depth: depth, first: i}
+						byParent[n] = g
+						groups = append(groups, g)
+					}
+					g.last = i
+				}
+				pos += length
+			case c.Name == "w:ins" || c.Name == "w:moveTo" || containerElements[c.Name]:
+				walk(c, depth+1)
+			}
+		}
+	}
+	walk(p, 0)
+
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].depth < groups[j].depth })
+	wrapped := make(map[*Node]bool)
+	for _, g := range groups {
+		if wrapped[g.parent] {
+			continue
+		}
+		link := doc.newHyperlinkNode(target)
+		link.Children = append(link.Children, g.parent.Children[g.first:g.last+1]...)
+		tail := append([]*Node{link}, g.parent.Children[g.last+1:]...)
+		g.parent.Children = append(g.parent.Children[:g.first], tail...)
+		link.Walk(func(node, _ *Node) bool {
+			wrapped[node] = true
+			return true
+		})
+	}
+	doc.IsModified = true
+	log.Printf("已插入超链接: %s", target.Link())
+	return nil
+}
+
+// newHyperlinkNode 创建超链接元素，外部地址添加为主文档的关系
+func (doc *Document) newHyperlinkNode(target LinkTarget) *Node {
+	link := NewNode("w:hyperlink")
+	doc.setHyperlinkTarget(link, target)
+	link.SetAttr("w:history", "1")
+	return link
+}
+
+// setHyperlinkTarget 设置超链接元素的目标
+func (doc *Document) setHyperlinkTarget(link *Node, target LinkTarget) {
+	link.RemoveAttr("r:id")
+	link.RemoveAttr("w:anchor")
+	link.RemoveAttr("w:tooltip")
+	if url := strings.TrimSpace(target.URL); url != "" {
+		link.SetAttr("r:id", doc.Package.AddRelationship(doc.Package.MainPartName(), relTypeHyperlink, url, true))
+	}
+	if anchor := strings.TrimSpace(target.Anchor); anchor != "" {
+		link.SetAttr("w:anchor", anchor)
+	}
+	if target.Tooltip != "" {
+		link.SetAttr("w:tooltip", target.Tooltip)
+	}
+}
+
+// releaseHyperlinkRelationship 没有超链接再使用关系时删除它
+func (doc *Document) releaseHyperlinkRelationship(body *Node, id string) {
+	if id == "" {
+		return
+	}
+	for _, n := range body.Find("w:hyperlink") {
+		if n.Attr("r:id") == id {
+			return
+		}
+	}
+	doc.Package.RemoveRelationship(doc.Package.MainPartName(), id)
+}
+
+// findHyperlinkStyle 查找超链接字符样式的ID
+func (doc *Document) findHyperlinkStyle() (string, bool) {
+	for id, style := range doc.styleSheet().styles {
+		if style.Attr("w:type") != "character" {
+			continue
+		}
+		if name := style.Child("w:name"); id == hyperlinkStyleID || (name != nil && strings.EqualFold(name.Attr("w:val"), "hyperlink")) {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// hyperlinkStyle 返回超链接字符样式的ID，样式不存在时添加
+func (doc *Document) hyperlinkStyle() (string, error) {
+	if id, ok := doc.findHyperlinkStyle(); ok {
+		return id, nil
+	}
+	part, ok := doc.Package.RelatedPart(doc.Package.MainPartName(), relTypeStyles)
+	if !ok {
+		return hyperlinkStyleID, nil
+	}
+	root, err := doc.Package.XML(part)
+	if err != nil {
+		return "", err
+	}
+	style := NewNode("w:style", "w:type", "character", "w:styleId", hyperlinkStyleID)
+	style.AppendChild(NewNode("w:name", "w:val", "Hyperlink"))
+	style.AppendChild(NewNode("w:uiPriority", "w:val", "99"))
+	style.AppendChild(NewNode("w:unhideWhenUsed"))
+	rPr := NewNode("w:rPr")
+	rPr.AppendChild(NewNode("w:color", "w:val", "0563C1"))
+	rPr.AppendChild(NewNode("w:u", "w:val", "single"))
+	style.AppendChild(rPr)
+	root.AppendChild(style)
+	log.Printf("已添加超链接样式: %s", hyperlinkStyleID)
+	return hyperlinkStyleID, nil
+}
+
+// findHyperlink 按 Hyperlinks 中的序号查找超链接元素
+func (doc *Document) findHyperlink(index int) (*Node, *Node, error) {
+	body, err := doc.body()
+	if err != nil {
+		return nil, nil, err
+	}
+	links := body.Find("w:hyperlink")
+	if index < 0 || index >= len(links) {
+		return nil, nil, fmt.Errorf("超链接索引超出范围: %d", index+1)
+	}
+	return body, links[index], nil
+}
+
+// EditHyperlink 修改超链接的目标，text 不为空且与原文字不同时替换显示的文字
+//
+// 新文字使用原来第一段文字的字符格式。
+func (doc *Document) EditHyperlink(index int, target LinkTarget, text string) error {
+	if err := target.validate(); err != nil {
+		return err
+	}
+	body, link, err := doc.findHyperlink(index)
+	if err != nil {
+		return err
+	}
+	oldID := link.Attr("r:id")
+	doc.setHyperlinkTarget(link, target)
+	doc.releaseHyperlinkRelationship(body, oldID)
+
+	if text != "" && text != paragraphNodeText(link) {
+		var rPr *Node
+		for _, r := range link.Find("w:r") {
+			if runTextLength(r) > 0 {
+				if props := r.Child("w:rPr"); props != nil {
+					rPr = props.Clone()
+				}
+				break
+			}
+		}
+		link.Children = nil
+		link.AppendChild(newRunNode(text, rPr))
+	}
+	doc.IsModified = true
+	log.Printf("已修改超链接: %s", target.Link())
+	return nil
+}
+
+// RemoveHyperlink 取消超链接，保留显示的文字并去掉超链接字符样式
+func (doc *Document) RemoveHyperlink(index int) error {
+	body, link, err := doc.findHyperlink(index)
+	if err != nil {
+		return err
+	}
+	// 取消后关系可能被删除，先读取链接目标
+	target := doc.hyperlinkTarget(link)
+	doc.unwrapHyperlink(body, link)
+	doc.IsModified = true
+	log.Printf("已取消超链接: %s", target.Link())
+	return nil
+}
+
+// unwrapHyperlink 将超链接的内容移到超链接所在的位置
+func (doc *Document) unwrapHyperlink(body, link *Node) {
+	parent := findParent(body, link)
+	if parent == nil {
+		return
+	}
+	// 只查找不添加样式，文档中没有超链接样式时去掉默认的样式ID
+	styleID, ok := doc.findHyperlinkStyle()
+	if !ok {
+		styleID = hyperlinkStyleID
+	}
+	for _, r := range link.Find("w:r") {
+		if rPr := r.Child("w:rPr"); rPr != nil {
+			if rStyle := rPr.Child("w:rStyle"); rStyle != nil && rStyle.Attr("w:val") == styleID {
+				rPr.RemoveChild(rStyle)
+			}
+			if len(rPr.Children) == 0 {
+				r.RemoveChild(rPr)
+			}
+		}
+	}
+	index := parent.IndexOf(link)
+	tail := append(append([]*Node{}, link.Children...), parent.Children[index+1:]...)
+	parent.Children = append(parent.Children[:index], tail...)
+	doc.releaseHyperlinkRelationship(body, link.Attr("r:id"))
+	mergeRuns(parent)
+}
+
+// Bookmarks 返回正文中的所有书签，按在文档中出现的顺序排列
+func (doc *Document) Bookmarks() ([]Bookmark, error) {
+	body, err := doc.body()
+	if err != nil {
+		return nil, err
+	}
+	positions := markerPositions(body)
+	ends := make(map[string]*Node)
+	for _, n := range body.Find("w:bookmarkEnd") {
+		ends[n.Attr("w:id")] = n
+	}
+	var bookmarks []Bookmark
+	for _, n := range body.Find("w:bookmarkStart") {
+		bookmark := Bookmark{
+			ID:           n.Attr("w:id"),
+			Name:         n.Attr("w:name"),
+			Paragraph:    -1,
+			EndParagraph: -1,
+		}
+		bookmark.Hidden = strings.HasPrefix(bookmark.Name, "_")
+		if pos, ok := positions[n]; ok {
+			bookmark.Paragraph, bookmark.Offset = pos.paragraph, pos.start
+		}
+		if end := ends[bookmark.ID]; end != nil {
+			if pos, ok := positions[end]; ok {
+				bookmark.EndParagraph, bookmark.EndOffset = pos.paragraph, pos.start
+			}
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+	return bookmarks, nil
+}
+
+// FindBookmark 按名称查找书签，名称不区分大小写
+func (doc *Document) FindBookmark(name string) (Bookmark, error) {
+	bookmarks, err := doc.Bookmarks()
+	if err != nil {
+		return Bookmark{}, err
+	}
+	for _, bookmark := range bookmarks {
+		if strings.EqualFold(bookmark.Name, name) {
+			return bookmark, nil
+		}
+	}
+	return Bookmark{}, fmt.Errorf("未找到书签: %s", name)
+}
+
+// validateBookmarkName 检查书签名称是否有效且未被使用
+func (doc *Document) validateBookmarkName(name string) error {
+	if !bookmarkNamePattern.MatchString(name) {
+		return fmt.Errorf("无效的书签名称: %s（须以字母开头，只能包含字母、数字和下划线，最多40个字符）", name)
+	}
+	if _, err := doc.FindBookmark(name); err == nil {
+		return fmt.Errorf("书签名称已存在: %s", name)
+	}
+	return nil
+}
+
+// AddBookmark 为范围内的文字添加书签，范围为空时在该位置添加书签
+func (doc *Document) AddBookmark(name string, r TextRange) error {
+	if err := doc.validateBookmarkName(name); err != nil {
+		return err
+	}
+	body, err := doc.body()
+	if err != nil {
+		return err
+	}
+	paragraphs := doc.paragraphNodes()
+	if r.StartParagraph < 0 || r.EndParagraph >= len(paragraphs) || r.StartParagraph > r.EndParagraph {
+		return fmt.Errorf("段落范围无效: %d-%d", r.StartParagraph+1, r.EndParagraph+1)
+	}
+	first, last := paragraphs[r.StartParagraph], paragraphs[r.EndParagraph]
+	end := r.EndOffset
+	if end < 0 {
+		end = len([]rune(paragraphNodeText(last)))
+	}
+
+	id := strconv.Itoa(nextBookmarkID(body))
+	ip := splitRunsAt(first, r.StartOffset)
+	ip.insert(NewNode("w:bookmarkStart", "w:id", id, "w:name", name))
+	// 插入点在该位置的文字之前，范围为空时结束标记位于开始标记之后
+	ip = splitRunsAt(last, end)
+	ip.insert(NewNode("w:bookmarkEnd", "w:id", id))
+	doc.IsModified = true
+	log.Printf("已添加书签: %s", name)
+	return nil
+}
+
+// nextBookmarkID 返回正文中未使用的书签ID
+func nextBookmarkID(body *Node) int {
+	next := 0
+	for _, n := range append(body.Find("w:bookmarkStart"), body.Find("w:bookmarkEnd")...) {
+		if id, err := strconv.Atoi(n.Attr("w:id")); err == nil && id >= next {
+			next = id + 1
+		}
+	}
+	return next
+}
+
+// findBookmarkNodes 按名称查找书签的开始和结束元素
+func (doc *Document) findBookmarkNodes(name string) (*Node, *Node, *Node, error) {
+	body, err := doc.body()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, start := range body.Find("w:bookmarkStart") {
+		if start.Attr("w:name") != name {
+			continue
+		}
+		for _, end := range body.Find("w:bookmarkEnd") {
+			if end.Attr("w:id") == start.Attr("w:id") {
+				return body, start, end, nil
+			}
+		}
+		return body, start, nil, nil
+	}
+	return nil, nil, nil, fmt.Errorf("未找到书签: %s", name)
+}
+
+// RemoveBookmark 删除书签，书签中的文字保留
+func (doc *Document) RemoveBookmark(name string) error {
+	body, start, end, err := doc.findBookmarkNodes(name)
+	if err != nil {
+		return err
+	}
+	for _, n := range []*Node{start, end} {
+		if n == nil {
+			continue
+		}
+		if parent := findParent(body, n); parent != nil {
+			parent.RemoveChild(n)
+		}
+	}
+	doc.IsModified = true
+	log.Printf("已删除书签: %s", name)
+	return nil
+}
+
+// RenameBookmark 修改书签名称，链接到该书签的超链接一起修改
+func (doc *Document) RenameBookmark(name, newName string) error {
+	if strings.EqualFold(name, newName) && name != newName {
+		// 只修改大小写时不检查重名
+		if !bookmarkNamePattern.MatchString(newName) {
+			return fmt.Errorf("无效的书签名称: %s", newName)
+		}
+	} else if err := doc.validateBookmarkName(newName); err != nil {
+		return err
+	}
+	body, start, _, err := doc.findBookmarkNodes(name)
+	if err != nil {
+		return err
+	}
+	start.SetAttr("w:name", newName)
+	for _, link := range body.Find("w:hyperlink") {
+		if link.Attr("w:anchor") == name && link.Attr("r:id") == "" {
+			link.SetAttr("w:anchor", newName)
+		}
+	}
+	doc.IsModified = true
+	log.Printf("已重命名书签: %s -> %s", name, newName)
+	return nil
+}
@@ -0,0 +1,317 @@
+package document
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// hyperlinkRelationships 返回主文档中超链接关系的数量
+func hyperlinkRelationships(doc *Document) int {
+	n := 0
+	for _, rel := range doc.Package.Relationships(doc.Package.MainPartName()) {
+		if rel.Type == relTypeHyperlink {
+			n++
+		}
+	}
+	return n
+}
+
+func TestInsertHyperlink(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		r      TextRange
+		target LinkTarget
+		want   []Hyperlink
+		rels   int
+	}{
+		{
+			"外部地址",
+			`<w:p><w:r><w:t>访问官方网站了解</w:t></w:r></w:p>`,
+			TextRange{0, 2, 0, 6},
+			LinkTarget{URL: "https://example.com"},
+			[]Hyperlink{{LinkTarget: LinkTarget{URL: "https://example.com"}, Offset: 2, Length: 4, Text: "官方网站"}},
+			1,
+		},
+		{
+			"链接到书签",
+			`<w:p><w:bookmarkStart w:id="0" w:name="第一章"/><w:r><w:t>第一章</w:t></w:r><w:bookmarkEnd w:id="0"/></w:p>` +
+				`<w:p><w:r><w:t>见第一章</w:t></w:r></w:p>`,
+			TextRange{1, 1, 1, -1},
+			LinkTarget{Anchor: "第一章", Tooltip: "跳转"},
+			[]Hyperlink{{LinkTarget: LinkTarget{Anchor: "第一章", Tooltip: "跳转"}, Paragraph: 1, Offset: 1, Length: 3, Text: "第一章"}},
+			0,
+		},
+		{
+			"跨越多个文字段",
+			`<w:p><w:r><w:t>普通</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>加粗</w:t></w:r></w:p>`,
+			TextRange{0, 1, 0, 3},
+			LinkTarget{URL: "https://example.com"},
+			[]Hyperlink{{LinkTarget: LinkTarget{URL: "https://example.com"}, Offset: 1, Length: 2, Text: "通加"}},
+			1,
+		},
+		{
+			"取消重叠的超链接",
+			`<w:p><w:r><w:t>前</w:t></w:r><w:hyperlink r:id="rId9"><w:r><w:t>旧链接</w:t></w:r></w:hyperlink><w:r><w:t>后</w:t></w:r></w:p>`,
+			TextRange{0, 2, 0, 5},
+			LinkTarget{URL: "https://example.com/new"},
+			[]Hyperlink{{LinkTarget: LinkTarget{URL: "https://example.com/new"}, Offset: 2, Length: 3, Text: "链接后"}},
+			1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := newBodyTestDocument(t, tt.body)
+			if err := doc.InsertHyperlink(tt.r, tt.target); err != nil {
+				t.Fatalf("插入超链接失败: %v", err)
+			}
+			links, err := doc.Hyperlinks()
+			if err != nil {
+				t.Fatalf("读取超链接失败: %v", err)
+			}
+			for i := range tt.want {
+				tt.want[i].Index = i
+			}
+			if !reflect.DeepEqual(links, tt.want) {
+				t.Errorf("超链接 %+v, 期望 %+v", links, tt.want)
+			}
+			if got := hyperlinkRelationships(doc); got != tt.rels {
+				t.Errorf("超链接关系 %d 个, 期望 %d 个", got, tt.rels)
+			}
+			for _, r := range testBody(t, doc).Find("w:hyperlink")[0].Find("w:r") {
+				if rStyle := r.Find("w:rStyle"); len(rStyle) == 0 || rStyle[0].Attr("w:val") != hyperlinkStyleID {
+					t.Errorf("超链接中的文字没有使用超链接样式: %s", r)
+				}
+			}
+			if _, ok := testStyles(t, doc)[hyperlinkStyleID]; !ok {
+				t.Errorf("没有添加超链接样式")
+			}
+		})
+	}
+}
+
+func TestInsertHyperlinkErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		r      TextRange
+		target LinkTarget
+		err    string
+	}{
+		{"没有目标", TextRange{0, 0, 0, 2}, LinkTarget{URL: " "}, "请输入链接地址"},
+		{"书签不存在", TextRange{0, 0, 0, 2}, LinkTarget{Anchor: "不存在"}, "未找到书签"},
+		{"跨越段落", TextRange{0, 0, 1, 2}, LinkTarget{URL: "https://example.com"}, "跨越多个段落"},
+		{"范围为空", TextRange{0, 2, 0, 2}, LinkTarget{URL: "https://example.com"}, "请先选择"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := newTestDocument(t, "第一段", "第二段")
+			err := doc.InsertHyperlink(tt.r, tt.target)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("错误 %v, 期望包含 %q", err, tt.err)
+			}
+			if links, _ := doc.Hyperlinks(); len(links) != 0 {
+				t.Errorf("出错后仍插入了超链接")
+			}
+		})
+	}
+}
+
+func TestHyperlinkAt(t *testing.T) {
+	doc := newTestDocument(t, "正文链接文字")
+	if err := doc.InsertHyperlink(TextRange{0, 2, 0, 4}, LinkTarget{URL: "https://example.com"}); err != nil {
+		t.Fatalf("插入超链接失败: %v", err)
+	}
+	for offset, want := range []bool{false, false, true, true, true, false, false} {
+		if _, ok := doc.HyperlinkAt(0, offset); ok != want {
+			t.Errorf("HyperlinkAt(0, %d) = %v, 期望 %v", offset, ok, want)
+		}
+	}
+}
+
+func TestEditHyperlink(t *testing.T) {
+	doc := newTestDocument(t, "正文链接文字")
+	if err := doc.InsertHyperlink(TextRange{0, 2, 0, 4}, LinkTarget{URL: "https://example.com"}); err != nil {
+		t.Fatalf("插入超链接失败: %v", err)
+	}
+	if err := doc.EditHyperlink(0, LinkTarget{URL: "https://example.org"}, "新链接"); err != nil {
+		t.Fatalf("修改超链接失败: %v", err)
+	}
+	links, _ := doc.Hyperlinks()
+	if len(links) != 1 || links[0].URL != "https://example.org" || links[0].Text != "新链接" {
+		t.Fatalf("修改后的超链接 %+v", links)
+	}
+	if got := hyperlinkRelationships(doc); got != 1 {
+		t.Errorf("修改地址后超链接关系 %d 个, 期望 1 个", got)
+	}
+	if rStyle := testBody(t, doc).Find("w:hyperlink")[0].Find("w:rStyle"); len(rStyle) == 0 {
+		t.Errorf("新文字没有保留超链接样式")
+	}
+
+	if err := doc.EditHyperlink(0, LinkTarget{Anchor: "书签"}, ""); err != nil {
+		t.Fatalf("修改超链接失败: %v", err)
+	}
+	if got := hyperlinkRelationships(doc); got != 0 {
+		t.Errorf("改为链接到书签后超链接关系 %d 个, 期望 0 个", got)
+	}
+	if got := paragraphTexts(doc); !reflect.DeepEqual(got, []string{"正文新链接文字"}) {
+		t.Errorf("修改后的段落 %q", got)
+	}
+	if err := doc.EditHyperlink(1, LinkTarget{Anchor: "书签"}, ""); err == nil {
+		t.Errorf("修改不存在的超链接没有返回错误")
+	}
+}
+
+func TestRemoveHyperlink(t *testing.T) {
+	doc := newTestDocument(t, "正文链接文字")
+	if err := doc.InsertHyperlink(TextRange{0, 2, 0, 4}, LinkTarget{URL: "https://example.com"}); err != nil {
+		t.Fatalf("插入超链接失败: %v", err)
+	}
+	if err := doc.RemoveHyperlink(0); err != nil {
+		t.Fatalf("取消超链接失败: %v", err)
+	}
+	if links, _ := doc.Hyperlinks(); len(links) != 0 {
+		t.Errorf("取消后仍有 %d 个超链接", len(links))
+	}
+	if got := paragraphTexts(doc); !reflect.DeepEqual(got, []string{"正文链接文字"}) {
+		t.Errorf("取消后的段落 %q", got)
+	}
+	if got := hyperlinkRelationships(doc); got != 0 {
+		t.Errorf("取消后超链接关系 %d 个, 期望 0 个", got)
+	}
+	p := doc.paragraphNodes()[0]
+	if len(p.Find("w:rStyle")) != 0 {
+		t.Errorf("取消后文字仍使用超链接样式: %s", p)
+	}
+	if runs := p.ChildrenNamed("w:r"); len(runs) != 1 {
+		t.Errorf("取消后没有合并格式相同的文字段: %s", p)
+	}
+}
+
+func TestRemoveHyperlinkWithoutStyle(t *testing.T) {
+	doc := newTestDocument(t)
+	id := doc.Package.AddRelationship(doc.Package.MainPartName(), relTypeHyperlink, "https://example.com", true)
+	body := testBody(t, doc)
+	parsed, err := ParseXML([]byte(`<w:body><w:p><w:hyperlink r:id="` + id + `"><w:r><w:t>链接</w:t></w:r></w:hyperlink></w:p></w:body>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body.Children = append(parsed.Children, body.ChildrenNamed("w:sectPr")...)
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	if err := doc.RemoveHyperlink(0); err != nil {
+		t.Fatalf("取消超链接失败: %v", err)
+	}
+	if _, ok := testStyles(t, doc)[hyperlinkStyleID]; ok {
+		t.Errorf("取消超链接时添加了超链接样式")
+	}
+	if !strings.Contains(buf.String(), "已取消超链接: https://example.com") {
+		t.Errorf("取消超链接的日志没有链接地址: %q", buf.String())
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	doc := newTestDocument(t, "第一段文字", "第二段")
+	if err := doc.AddBookmark("开头", TextRange{0, 0, 0, 2}); err != nil {
+		t.Fatalf("添加书签失败: %v", err)
+	}
+	if err := doc.AddBookmark("跨段", TextRange{0, 3, 1, -1}); err != nil {
+		t.Fatalf("添加书签失败: %v", err)
+	}
+	if err := doc.AddBookmark("位置", TextRange{1, 1, 1, 1}); err != nil {
+		t.Fatalf("添加书签失败: %v", err)
+	}
+	want := []Bookmark{
+		{ID: "0", Name: "开头", Paragraph: 0, Offset: 0, EndParagraph: 0, EndOffset: 2},
+		{ID: "1", Name: "跨段", Paragraph: 0, Offset: 3, EndParagraph: 1, EndOffset: 3},
+		{ID: "2", Name: "位置", Paragraph: 1, Offset: 1, EndParagraph: 1, EndOffset: 1},
+	}
+	if got, _ := doc.Bookmarks(); !reflect.DeepEqual(got, want) {
+		t.Errorf("书签 %+v, 期望 %+v", got, want)
+	}
+	if got := paragraphTexts(doc); !reflect.DeepEqual(got, []string{"第一段文字", "第二段"}) {
+		t.Errorf("添加书签后的段落 %q", got)
+	}
+
+	if err := doc.InsertHyperlink(TextRange{1, 0, 1, 2}, LinkTarget{Anchor: "开头"}); err != nil {
+		t.Fatalf("插入链接到书签的超链接失败: %v", err)
+	}
+	if err := doc.RenameBookmark("开头", "首段"); err != nil {
+		t.Fatalf("重命名书签失败: %v", err)
+	}
+	if links, _ := doc.Hyperlinks(); len(links) != 1 || links[0].Anchor != "首段" {
+		t.Errorf("重命名书签后超链接 %+v", links)
+	}
+	if err := doc.RemoveBookmark("跨段"); err != nil {
+		t.Fatalf("删除书签失败: %v", err)
+	}
+	body := testBody(t, doc)
+	if n := len(body.Find("w:bookmarkStart")) + len(body.Find("w:bookmarkEnd")); n != 4 {
+		t.Errorf("删除书签后剩余 %d 个书签标记, 期望 4 个", n)
+	}
+	if err := doc.RemoveBookmark("跨段"); err == nil {
+		t.Errorf("删除不存在的书签没有返回错误")
+	}
+}
+
+func TestValidateBookmarkName(t *testing.T) {
+	doc := newBodyTestDocument(t, `<w:p><w:bookmarkStart w:id="0" w:name="Intro"/><w:r><w:t>文字</w:t></w:r><w:bookmarkEnd w:id="0"/></w:p>`)
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"第二章", true},
+		{"section_2", true},
+		{"2章", false},
+		{"_Toc123", false},
+		{"有 空格", false},
+		{strings.Repeat("长", 41), false},
+		{"intro", false},
+	}
+	for _, tt := range tests {
+		if err := doc.validateBookmarkName(tt.name); (err == nil) != tt.ok {
+			t.Errorf("validateBookmarkName(%q) = %v", tt.name, err)
+		}
+	}
+	if err := doc.RenameBookmark("Intro", "INTRO"); err != nil {
+		t.Errorf("只修改书签名称的大小写失败: %v", err)
+	}
+}
+
+func TestParseHTMLLinks(t *testing.T) {
+	got, err := parseHTMLParagraphs(`<p>看<a href="https://example.com">链接</a><a href="javascript:void(0)">脚本</a></p>`)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	want := []htmlParagraph{{runs: []htmlRun{{text: "看"}, {text: "链接", link: "https://example.com"}, {text: "脚本"}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("解析结果 %+v, 期望 %+v", got, want)
+	}
+}
+
+func TestInsertHTMLLinks(t *testing.T) {
+	doc := newTestDocument(t, "正文")
+	if _, err := doc.InsertHTML(TextPosition{0, 2}, `<a href="https://example.com">链接</a>和<a href="#书签">书签</a>`); err != nil {
+		t.Fatalf("粘贴失败: %v", err)
+	}
+	links, _ := doc.Hyperlinks()
+	if len(links) != 2 || links[0].URL != "https://example.com" || links[1].Anchor != "书签" {
+		t.Fatalf("粘贴后的超链接 %+v", links)
+	}
+	if got, want := describeParagraphs(doc), [][]string{{"正文", "w:hyperlink(链接)", "和", "w:hyperlink(书签)"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("粘贴后的Run %q, 期望 %q", got, want)
+	}
+
+	// 超链接不能嵌套，粘贴到超链接中时只保留文字
+	if _, err := doc.InsertHTML(TextPosition{0, 3}, `<a href="https://example.org">新</a>`); err != nil {
+		t.Fatalf("粘贴失败: %v", err)
+	}
+	if got, _ := doc.Hyperlinks(); len(got) != 2 || got[0].Text != "链新接" {
+		t.Errorf("粘贴到超链接中之后的超链接 %+v", got)
+	}
+}
@@ -0,0 +1,426 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 邮件合并使用的域类型，合并时替换为记录中的值
+const (
+	FieldMergeField FieldType = "MERGEFIELD" // 合并域，参数为数据源的列名
+	FieldMergeRec   FieldType = "MERGEREC"   // 当前记录的记录号
+)
+
+// mergeDateLayouts 合并域使用日期格式（\@ 开关）时识别的日期文字格式
+var mergeDateLayouts = []string{
+	"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02", "2006/1/2 15:04:05", "2006/1/2 15:04", "2006/1/2",
+	"2006年1月2日", "2006.1.2",
+}
+
+// invalidFileNameChars 文件名中不允许使用的字符
+const invalidFileNameChars = `\/:*?"<>|`
+
+// MailMergeOptions 邮件合并的选项
+type MailMergeOptions struct {
+	Records  []int                 // 要合并的记录，从0开始的序号，为空时合并所有记录
+	Progress func(done, total int) // 每合并一条记录后调用，可以为nil
+}
+
+// records 返回要合并的记录序号，忽略超出数据源范围的序号
+func (o MailMergeOptions) records(source *DataSource) []int {
+	if len(o.Records) == 0 {
+		records, _ := ParseRecordRange("", source.Len())
+		return records
+	}
+	var records []int
+	for _, index := range o.Records {
+		if index >= 0 && index < source.Len() {
+			records = append(records, index)
+		}
+	}
+	return records
+}
+
+// progress 报告合并进度
+func (o MailMergeOptions) progress(done, total int) {
+	if o.Progress != nil {
+		o.Progress(done, total)
+	}
+}
+
+// mergeContainers 返回可以查找域的容器：正文、页眉页脚以及每个脚注和尾注
+func mergeContainers(roots []*Node) []*Node {
+	var containers []*Node
+	for _, root := range roots {
+		switch root.Name {
+		case "w:document":
+			if body := root.Child("w:body"); body != nil {
+				containers = append(containers, body)
+			}
+		case "w:footnotes", "w:endnotes":
+			containers = append(containers, root.Elements()...)
+		default:
+			containers = append(containers, root)
+		}
+	}
+	return containers
+}
+
+// MergeFieldNames 返回主文档中合并域和 {{名称}} 占位符引用的名称，按第一次出现的顺序排列，不重复
+func (doc *Document) MergeFieldNames() ([]string, error) {
+	roots, err := doc.placeholderParts()
+	if err != nil {
+		return nil, err
+	}
+	placeholders, err := doc.Placeholders()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		if name != "" && !seen[strings.ToLower(name)] {
+			seen[strings.ToLower(name)] = true
+			names = append(names, name)
+		}
+	}
+	for _, container := range mergeContainers(roots) {
+		for _, f := range collectFields(container) {
+			if f.Type == FieldMergeField {
+				add(f.Argument)
+			}
+		}
+	}
+	for _, name := range placeholders {
+		add(name)
+	}
+	return names, nil
+}
+
+// MergeRecord 用数据源中的一条记录填写主文档的副本，返回新的未保存文档，主文档不变
+func (doc *Document) MergeRecord(source *DataSource, index int) (*Document, error) {
+	if doc.Package == nil {
+		return nil, fmt.Errorf("文档不支持邮件合并")
+	}
+	if index < 0 || index >= source.Len() {
+		return nil, fmt.Errorf("记录号超出范围: %d", index+1)
+	}
+	merged := &Document{
+		FileName:   doc.FileName,
+		Title:      doc.Title,
+		Package:    doc.Package.Clone(),
+		IsOpen:     true,
+		IsModified: true,
+	}
+	roots, err := merged.placeholderParts()
+	if err != nil {
+		return nil, err
+	}
+	fillRecord(roots, source.Record(index), index+1)
+	merged.removeMailMergeSettings()
+	return merged, nil
+}
+
+// fillRecord 用记录的值替换 roots 中的占位符和合并域，number 为记录号
+//
+// 先替换占位符，记录的值中出现的 {{名称}} 不会再被替换。只处理最外层的合并域，
+// 数据源中没有对应列的合并域替换为空。
+func fillRecord(roots []*Node, values map[string]string, number int) {
+	fillPlaceholders(roots, values)
+	for _, container := range mergeContainers(roots) {
+		for _, f := range collectFields(container) {
+			switch f.Type {
+			case FieldMergeField:
+				value, _ := lookupValue(values, f.Argument)
+				replaceField(container, f, mergeFieldValue(f.FieldCode, value))
+			case FieldMergeRec:
+				replaceField(container, f, strconv.Itoa(number))
+			}
+		}
+	}
+}
+
+// replaceField 用普通文字替换整个域，文字使用域结果的格式
+//
+// 域的开始和结束不在同一个父元素中时只替换域的结果。
+func replaceField(container *Node, f *fieldInstance, value string) {
+	first, last := f.begin, f.end
+	if f.simple != nil {
+		first, last = f.simple, f.simple
+	}
+	parent := findParent(container, first)
+	if parent == nil || findParent(container, last) != parent {
+		setFieldResult(container, f, value)
+		return
+	}
+	rPr := fieldResultProperties(f)
+	start, end := parent.IndexOf(first), parent.IndexOf(last)
+	children := append([]*Node{}, parent.Children[:start]...)
+	if value != "" {
+		children = append(children, newRunNode(value, rPr))
+	}
+	parent.Children = append(children, parent.Children[end+1:]...)
+}
+
+// mergeFieldValue 按合并域的开关格式化值：\* Upper/Lower/FirstCap/Caps 转换大小写，
+// \@ 格式化日期，\b 和 \f 在值不为空时加上前后的文字
+func mergeFieldValue(code FieldCode, value string) string {
+	if value == "" {
+		return ""
+	}
+	if code.Format != "" {
+		for _, layout := range mergeDateLayouts {
+			if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				value = FormatFieldDate(t, code.Format)
+				break
+			}
+		}
+	}
+	for i := 0; i+1 < len(code.Switches); i++ {
+		if code.Switches[i] != `\*` {
+			continue
+		}
+		switch strings.ToLower(code.Switches[i+1]) {
+		case "upper":
+			value = strings.ToUpper(value)
+		case "lower":
+			value = strings.ToLower(value)
+		case "firstcap":
+			runes := []rune(value)
+			runes[0] = unicode.ToUpper(runes[0])
+			value = string(runes)
+		case "caps":
+			runes := []rune(value)
+			for j := range runes {
+				if j == 0 || unicode.IsSpace(runes[j-1]) {
+					runes[j] = unicode.ToUpper(runes[j])
+				}
+			}
+			value = string(runes)
+		}
+	}
+	if before, ok := code.switchValue(`\b`); ok {
+		value = before + value
+	}
+	if after, ok := code.switchValue(`\f`); ok {
+		value += after
+	}
+	return value
+}
+
+// removeMailMergeSettings 删除设置中的邮件合并数据源，合并结果不再是邮件合并主文档
+func (doc *Document) removeMailMergeSettings() {
+	part, ok := doc.Package.RelatedPart(doc.Package.MainPartName(), relTypeSettings)
+	if !ok || !doc.Package.HasPart(part) {
+		return
+	}
+	if settings, err := doc.Package.XML(part); err == nil {
+		settings.RemoveChildrenNamed("w:mailMerge")
+	}
+}
+
+// MailMergeDocument 将选择的记录依次合并为一个新的文档包，每条记录从新的一页开始，主文档不变
+//
+// 页眉页脚使用第一条记录的值。从第二条记录开始，书签和批注标记被删除，避免重名；
+// 脚注和尾注为每条记录复制一份，用这条记录的值填写。
+func (doc *Document) MailMergeDocument(source *DataSource, opts MailMergeOptions) (*Package, error) {
+	records := opts.records(source)
+	if len(records) == 0 {
+		return nil, fmt.Errorf("没有要合并的记录")
+	}
+	result, err := doc.MergeRecord(source, records[0])
+	if err != nil {
+		return nil, err
+	}
+	opts.progress(1, len(records))
+	body, err := result.body()
+	if err != nil {
+		return nil, err
+	}
+	template, err := doc.body()
+	if err != nil {
+		return nil, err
+	}
+	final := body.Child("w:sectPr")
+
+	for i, index := range records[1:] {
+		copied := template.Clone()
+		copied.RemoveChildrenNamed("w:sectPr")
+		fillRecord([]*Node{copied}, source.Record(index), index+1)
+		removeCopyMarkers(copied)
+		if err := doc.copyRecordNotes(result, copied, source.Record(index), index+1); err != nil {
+			return nil, err
+		}
+
+		at := len(body.Children)
+		if final != nil {
+			at = body.IndexOf(final)
+		}
+		at = insertPageBreak(body, at)
+		body.InsertChild(at, copied.Children...)
+		opts.progress(i+2, len(records))
+	}
+	log.Printf("邮件合并完成: %d 条记录", len(records))
+	return result.Package, nil
+}
+
+// copyRecordNotes 为记录副本中的脚注和尾注引用复制主文档中原来的注释，
+// 用记录的值填写后加入合并结果，并让引用指向新的注释ID
+func (doc *Document) copyRecordNotes(result *Document, copied *Node, values map[string]string, number int) error {
+	for _, kind := range []NoteKind{FootnoteKind, EndnoteKind} {
+		spec := kind.spec()
+		refs := copied.Find(spec.reference)
+		if len(refs) == 0 {
+			continue
+		}
+		template, err := doc.notesRoot(kind, false)
+		if err != nil {
+			return err
+		}
+		if template == nil {
+			continue
+		}
+		root, err := result.notesRoot(kind, true)
+		if err != nil {
+			return err
+		}
+		for _, ref := range refs {
+			for _, n := range template.ChildrenNamed(spec.element) {
+				if n.Attr("w:id") != ref.Attr("w:id") {
+					continue
+				}
+				note := n.Clone()
+				fillRecord([]*Node{note}, values, number)
+				removeCopyMarkers(note)
+				id := nextNoteID(root, spec.element)
+				note.SetAttr("w:id", id)
+				root.AppendChild(note)
+				ref.SetAttr("w:id", id)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// insertPageBreak 在正文 at 位置之前的内容后插入分页符，返回之后内容的插入位置
+//
+// 前面是普通段落时分页符加在段落末尾，否则插入只有分页符的段落。
+func insertPageBreak(body *Node, at int) int {
+	pageBreak := NewNode("w:r")
+	pageBreak.AppendChild(NewNode("w:br", "w:type", "page"))
+	if at > 0 {
+		if prev := body.Children[at-1]; prev.Name == "w:p" {
+			if pPr := prev.Child("w:pPr"); pPr == nil || pPr.Child("w:sectPr") == nil {
+				prev.AppendChild(pageBreak)
+				return at
+			}
+		}
+	}
+	p := NewNode("w:p")
+	p.AppendChild(pageBreak)
+	body.InsertChild(at, p)
+	return at + 1
+}
+
+// removeCopyMarkers 删除记录副本中的书签、批注标记和段落标识，它们在合并结果中必须唯一
+func removeCopyMarkers(n *Node) {
+	children := n.Children[:0]
+	for _, c := range n.Children {
+		switch c.Name {
+		case "w:bookmarkStart", "w:bookmarkEnd", "w:commentRangeStart", "w:commentRangeEnd", "w:commentReference":
+			continue
+		}
+		c.RemoveAttr("w14:paraId")
+		c.RemoveAttr("w14:textId")
+		removeCopyMarkers(c)
+		children = append(children, c)
+	}
+	n.Children = children
+}
+
+// MergeFileName 按文件名模式生成一条记录的文件名，{{列名}} 替换为记录中的值，{{#}} 替换为记录号
+//
+// 文件名中不允许的字符替换为下划线，没有扩展名时加上 .docx。
+func MergeFileName(pattern string, values map[string]string, number int) string {
+	name := strings.ReplaceAll(pattern, "{{#}}", strconv.Itoa(number))
+	name = placeholderPattern.ReplaceAllStringFunc(name, func(match string) string {
+		value, _ := lookupValue(values, placeholderPattern.FindStringSubmatch(match)[1])
+		return value
+	})
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(invalidFileNameChars, r) || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, name)
+	ext := ".docx"
+	if isWordDocument(name) {
+		ext = filepath.Ext(name)
+		name = strings.TrimSuffix(name, ext)
+	}
+	name = strings.Trim(name, " .")
+	if name == "" {
+		name = strconv.Itoa(number)
+	}
+	return name + ext
+}
+
+// MailMergeFiles 将选择的记录分别合并并保存到 dir 中，返回保存的文件路径，主文档不变
+//
+// 文件名由 pattern 生成（见 MergeFileName），同一次合并中重名的文件加上序号，已存在的文件被覆盖。
+func (doc *Document) MailMergeFiles(source *DataSource, dir, pattern string, opts MailMergeOptions) ([]string, error) {
+	records := opts.records(source)
+	if len(records) == 0 {
+		return nil, fmt.Errorf("没有要合并的记录")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建输出文件夹失败: %v", err)
+	}
+	used := make(map[string]bool)
+	var paths []string
+	for i, index := range records {
+		merged, err := doc.MergeRecord(source, index)
+		if err != nil {
+			return paths, err
+		}
+		name := MergeFileName(pattern, source.Record(index), index+1)
+		ext := filepath.Ext(name)
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			name = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(MergeFileName(pattern, source.Record(index), index+1), ext), n, ext)
+		}
+		used[strings.ToLower(name)] = true
+		path := filepath.Join(dir, name)
+		if err := merged.Package.Save(path); err != nil {
+			return paths, fmt.Errorf("保存第 %d 条记录失败: %v", index+1, err)
+		}
+		paths = append(paths, path)
+		opts.progress(i+1, len(records))
+	}
+	log.Printf("邮件合并完成: 已保存 %d 个文档到 %s", len(paths), dir)
+	return paths, nil
+}
+
+// MailMerge 将主文档与数据源合并为一个新文档，新文档作为未保存的文档打开并设为当前文档
+func (m *Manager) MailMerge(template *Document, source *DataSource, opts MailMergeOptions) (*Document, error) {
+	pkg, err := template.MailMergeDocument(source, opts)
+	if err != nil {
+		return nil, fmt.Errorf("邮件合并失败: %v", err)
+	}
+	return m.NewDocumentFromPackage(pkg, MailMergeDocumentName(template)), nil
+}
+
+// MailMergeDocumentName 返回合并结果文档的名称（不含扩展名）
+func MailMergeDocumentName(template *Document) string {
+	baseName := strings.TrimSuffix(template.FileName, filepath.Ext(template.FileName))
+	if baseName == "" {
+		baseName = "邮件合并"
+	}
+	return baseName + "-合并"
+}
@@ -0,0 +1,170 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRecordRange(t *testing.T) {
+	tests := []struct {
+		spec    string
+		total   int
+		want    []int
+		wantErr bool
+	}{
+		{"", 3, []int{0, 1, 2}, false},
+		{"  ", 0, nil, false},
+		{"2", 5, []int{1}, false},
+		{"1-3,5", 10, []int{0, 1, 2, 4}, false},
+		{"4-", 6, []int{3, 4, 5}, false},
+		{"3-8", 5, []int{2, 3, 4}, false},
+		{"2,1-3,2", 5, []int{1, 0, 2}, false},
+		{" 1 - 2 , ", 5, []int{0, 1}, false},
+		{"9", 5, nil, false},
+		{"0", 5, nil, true},
+		{"3-1", 5, nil, true},
+		{"a-b", 5, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRecordRange(tt.spec, tt.total)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRecordRange(%q, %d) 错误 %v, 期望出错 %v", tt.spec, tt.total, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseRecordRange(%q, %d) = %v, 期望 %v", tt.spec, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestMergeFileName(t *testing.T) {
+	values := map[string]string{"姓名": "张三", "公司": "A/B 公司", "空": ""}
+	tests := []struct {
+		pattern string
+		number  int
+		want    string
+	}{
+		{"{{姓名}}", 1, "张三.docx"},
+		{"{{#}}-{{姓名}}.docx", 7, "7-张三.docx"},
+		{"{{公司}}", 2, "A_B 公司.docx"},
+		{"{{空}}", 3, "3.docx"},
+		{"{{不存在}}.docx", 4, "4.docx"},
+		{"合同 {{ 姓名 }}.doc", 5, "合同 张三.doc"},
+		{"  {{姓名}}. ", 6, "张三.docx"},
+	}
+	for _, tt := range tests {
+		if got := MergeFileName(tt.pattern, values, tt.number); got != tt.want {
+			t.Errorf("MergeFileName(%q, %d) = %q, 期望 %q", tt.pattern, tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestDetectDelimiter(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want rune
+	}{
+		{"逗号", "姓名,电话,地址\n张三,1,2", ','},
+		{"分号", "姓名;电话;地址\n张三;1,5;2", ';'},
+		{"制表符", "姓名\t电话\n张三\t1", '\t'},
+		{"只看第一行", "姓名;电话\na,b,c,d,e", ';'},
+		{"忽略引号中的分隔符", "\"a,b,c\";\"d\";e\n", ';'},
+		{"引号中的换行", "\"a\nb\",c,d\n", ','},
+		{"没有分隔符时使用逗号", "姓名\n张三", ','},
+	}
+	for _, tt := range tests {
+		if got := detectDelimiter([]byte(tt.data)); got != tt.want {
+			t.Errorf("%s: detectDelimiter = %q, 期望 %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDateValue(t *testing.T) {
+	tests := []struct {
+		serial   float64
+		date1904 bool
+		want     string
+	}{
+		{44927, false, "2023-01-01"},
+		{44927.5, false, "2023-01-01 12:00:00"},
+		{44927.75, false, "2023-01-01 18:00:00"},
+		{0.25, false, "06:00:00"},
+		{1, false, "1899-12-31"},
+		{43465, true, "2023-01-01"},
+		{0, true, "1904-01-01"},
+		{44927.000011574, false, "2023-01-01 00:00:01"},
+	}
+	for _, tt := range tests {
+		b := &workbook{date1904: tt.date1904}
+		if got := b.dateValue(tt.serial); got != tt.want {
+			t.Errorf("dateValue(%v, 1904=%v) = %q, 期望 %q", tt.serial, tt.date1904, got, tt.want)
+		}
+	}
+}
+
+func TestMailMergeDocumentNotes(t *testing.T) {
+	template := newTestDocument(t, "尊敬的{{姓名}}：", "正文")
+	if _, err := template.InsertFootnote(0, 3, "{{姓名}}的备注"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := template.InsertEndnote(1, -1, "记录 {{编号}}"); err != nil {
+		t.Fatal(err)
+	}
+	source := &DataSource{
+		Columns: []string{"姓名", "编号"},
+		Records: [][]string{{"张三", "1"}, {"李四", "2"}, {"王五", "3"}},
+	}
+
+	pkg, err := template.MailMergeDocument(source, MailMergeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := &Document{Package: pkg}
+
+	for _, tt := range []struct {
+		kind NoteKind
+		want []string
+	}{
+		{FootnoteKind, []string{"张三的备注", "李四的备注", "王五的备注"}},
+		{EndnoteKind, []string{"记录 1", "记录 2", "记录 3"}},
+	} {
+		notes, err := merged.Notes(tt.kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var texts []string
+		ids := make(map[string]bool)
+		for _, note := range notes {
+			if note.Number == 0 {
+				t.Errorf("%s %s 没有被正文引用", tt.kind, note.ID)
+			}
+			if ids[note.ID] {
+				t.Errorf("%s ID %s 重复", tt.kind, note.ID)
+			}
+			ids[note.ID] = true
+			texts = append(texts, note.Text)
+		}
+		if !reflect.DeepEqual(texts, tt.want) {
+			t.Errorf("%s = %q, 期望 %q", tt.kind, texts, tt.want)
+		}
+
+		refs := make(map[string]bool)
+		body, _ := merged.body()
+		for _, ref := range body.Find(tt.kind.spec().reference) {
+			if refs[ref.Attr("w:id")] {
+				t.Errorf("%s 引用 %s 重复", tt.kind, ref.Attr("w:id"))
+			}
+			refs[ref.Attr("w:id")] = true
+		}
+		if len(refs) != len(tt.want) {
+			t.Errorf("%s 引用 %d 个, 期望 %d 个", tt.kind, len(refs), len(tt.want))
+		}
+	}
+
+	// 主文档不变
+	notes, _ := template.Notes(FootnoteKind)
+	if len(notes) != 1 || notes[0].Text != "{{姓名}}的备注" {
+		t.Errorf("主文档的脚注被修改: %+v", notes)
+	}
+}
@@ -0,0 +1,819 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// 图片等对象的 wp:docPr 元素，ID在文档中必须唯一
+const drawingPropertiesElement = "wp:docPr"
+
+// bookmarkFieldTypes 以书签名称为参数的域
+var bookmarkFieldTypes = map[FieldType]bool{FieldRef: true, "PAGEREF": true, "NOTEREF": true}
+
+// styleReferenceElements 引用样式ID的元素
+var styleReferenceElements = map[string]bool{
+	"w:pStyle": true, "w:rStyle": true, "w:tblStyle": true,
+	"w:basedOn": true, "w:next": true, "w:link": true, "w:styleLink": true, "w:numStyleLink": true,
+}
+
+// MergeDocumentsOptions 合并文档的选项
+type MergeDocumentsOptions struct {
+	SectionBreak SectionBreak          // 各部分之间的分节符类型，为空时为下一页
+	Progress     func(done, total int) // 每合并一个文档后调用，可以为nil
+}
+
+// MergeDocuments 按顺序将多个 .docx 文档合并为一个文档包
+//
+// 合并结果以第一个文档为基础，沿用它的文档默认格式、设置和属性，
+// 之后的每个文档作为新的一节追加到末尾（见 AppendDocument）。
+func MergeDocuments(paths []string, opts MergeDocumentsOptions) (*Package, error) {
+	if len(paths) < 2 {
+		return nil, fmt.Errorf("至少需要两个文档才能合并")
+	}
+	for _, p := range paths {
+		if strings.ToLower(filepath.Ext(p)) != ".docx" {
+			return nil, fmt.Errorf("只能合并 .docx 文档: %s", filepath.Base(p))
+		}
+	}
+	base, err := OpenPackage(paths[0])
+	if err != nil {
+		return nil, fmt.Errorf("打开 %s 失败: %v", filepath.Base(paths[0]), err)
+	}
+	result := &Document{Package: base}
+	if opts.Progress != nil {
+		opts.Progress(1, len(paths))
+	}
+	for i, p := range paths[1:] {
+		src, err := OpenPackage(p)
+		if err != nil {
+			return nil, fmt.Errorf("打开 %s 失败: %v", filepath.Base(p), err)
+		}
+		if err := result.AppendDocument(src, opts.SectionBreak); err != nil {
+			return nil, fmt.Errorf("合并 %s 失败: %v", filepath.Base(p), err)
+		}
+		if opts.Progress != nil {
+			opts.Progress(i+2, len(paths))
+		}
+	}
+	log.Printf("已合并 %d 个文档", len(paths))
+	return base, nil
+}
+
+// MergeDocuments 按顺序合并多个 .docx 文档，结果作为未保存的新文档打开并设为当前文档
+func (m *Manager) MergeDocuments(paths []string, opts MergeDocumentsOptions) (*Document, error) {
+	pkg, err := MergeDocuments(paths, opts)
+	if err != nil {
+		return nil, err
+	}
+	return m.NewDocumentFromPackage(pkg, MergedDocumentName(paths)), nil
+}
+
+// MergedDocumentName 返回合并结果的默认文档名称：第一个文档的名称加"-合并"
+func MergedDocumentName(paths []string) string {
+	return strings.TrimSuffix(filepath.Base(paths[0]), filepath.Ext(paths[0])) + "-合并"
+}
+
+// AppendDocument 将另一个文档包的正文作为新的一节追加到文档末尾，kind 为新节的开始方式
+//
+// 正文用到的样式、编号、图片、超链接、页眉页脚、脚注尾注和批注一并复制：
+// 与已有样式ID相同但定义不同的样式改名后导入，默认样式使用本文档的默认样式；
+// 编号定义总是作为新的列表导入；重名的书签改名，引用它的超链接和域随之修改。
+// 追加的节没有页眉页脚时沿用前一节的页眉页脚。
+func (doc *Document) AppendDocument(src *Package, kind SectionBreak) error {
+	if kind == "" {
+		kind = SectionNextPage
+	}
+	body, err := doc.body()
+	if err != nil {
+		return err
+	}
+	srcBody, err := src.Body()
+	if err != nil {
+		return err
+	}
+
+	im, err := newDocumentImporter(doc, src)
+	if err != nil {
+		return err
+	}
+	content := NewNode("w:body")
+	for _, c := range srcBody.Children {
+		content.AppendChild(c.Clone())
+	}
+	if err := im.importDefinitions(content); err != nil {
+		return err
+	}
+	if err := im.importAnnotations(content); err != nil {
+		return err
+	}
+	im.remap(content, im.srcMain, im.dstMain)
+	mergeNamespaces(im.dstRoot, im.srcRoot)
+
+	// 本文档最后一节的属性移到最后一个段落中，追加内容的节属性成为正文的最后一节
+	final := body.Child("w:sectPr")
+	if final == nil {
+		final = NewNode("w:sectPr")
+	} else {
+		body.RemoveChild(final)
+	}
+	var last *Node
+	if n := len(body.Children); n > 0 && body.Children[n-1].Name == "w:p" {
+		last = body.Children[n-1]
+		if pPr := last.Child("w:pPr"); pPr != nil && pPr.Child("w:sectPr") != nil {
+			last = nil
+		}
+	}
+	if last == nil {
+		last = NewNode("w:p")
+		body.AppendChild(last)
+	}
+	paragraphProperties(last).InsertOrdered(final, pPrOrder)
+
+	appended := content.Child("w:sectPr")
+	if appended != nil {
+		content.RemoveChild(appended)
+	} else {
+		appended = final.Clone()
+		appended.RemoveChildrenNamed("w:headerReference")
+		appended.RemoveChildrenNamed("w:footerReference")
+	}
+	appended.EnsureChild("w:type", sectPrOrder).SetAttr("w:val", string(kind))
+	body.AppendChild(content.Children...)
+	body.AppendChild(appended)
+
+	doc.IsModified = true
+	log.Printf("已追加文档: %d 个块，%d 个样式，%d 个部件", len(content.Children), len(im.styles), len(im.parts))
+	return nil
+}
+
+// documentImporter 将另一个文档包中的内容复制到文档中，记录来源ID与新ID的对应关系
+type documentImporter struct {
+	doc              *Document
+	dst, src         *Package
+	dstMain, srcMain string
+	dstRoot, srcRoot *Node
+
+	styles    map[string]string // 样式ID
+	numbers   map[string]string // 编号实例ID
+	parts     map[string]string // 部件名称
+	rels      map[string]string // 来源部件和关系ID到新关系ID
+	footnotes map[string]string // 脚注ID
+	endnotes  map[string]string // 尾注ID
+	comments  map[string]string // 批注ID
+
+	bookmarkNames  map[string]string // 改名的书签
+	bookmarkIDs    map[string]string // 书签ID
+	usedBookmarks  map[string]bool   // 本文档已有的书签名称（小写）
+	nextBookmarkID int
+	nextDrawingID  int
+}
+
+// newDocumentImporter 读取本文档已有的书签和图片ID，准备复制 src 中的内容
+func newDocumentImporter(doc *Document, src *Package) (*documentImporter, error) {
+	im := &documentImporter{
+		doc:           doc,
+		dst:           doc.Package,
+		src:           src,
+		dstMain:       doc.Package.MainPartName(),
+		srcMain:       src.MainPartName(),
+		styles:        make(map[string]string),
+		numbers:       make(map[string]string),
+		parts:         make(map[string]string),
+		rels:          make(map[string]string),
+		footnotes:     make(map[string]string),
+		endnotes:      make(map[string]string),
+		comments:      make(map[string]string),
+		bookmarkNames: make(map[string]string),
+		bookmarkIDs:   make(map[string]string),
+		usedBookmarks: make(map[string]bool),
+		nextDrawingID: 1,
+	}
+	var err error
+	if im.dstRoot, err = im.dst.XML(im.dstMain); err != nil {
+		return nil, err
+	}
+	if im.srcRoot, err = src.XML(im.srcMain); err != nil {
+		return nil, err
+	}
+	roots, err := doc.placeholderParts()
+	if err != nil {
+		return nil, err
+	}
+	for _, root := range roots {
+		root.Walk(func(n, parent *Node) bool {
+			switch n.Name {
+			case "w:bookmarkStart":
+				im.usedBookmarks[strings.ToLower(n.Attr("w:name"))] = true
+				if id, err := strconv.Atoi(n.Attr("w:id")); err == nil && id >= im.nextBookmarkID {
+					im.nextBookmarkID = id + 1
+				}
+			case drawingPropertiesElement:
+				if id, err := strconv.Atoi(n.Attr("id")); err == nil && id >= im.nextDrawingID {
+					im.nextDrawingID = id + 1
+				}
+			}
+			return true
+		})
+	}
+	return im, nil
+}
+
+// relatedRoot 返回来源文档主文档指定类型关系的部件名称和节点树，部件不存在时返回空
+func (im *documentImporter) relatedRoot(relType string) (string, *Node) {
+	part, ok := im.src.RelatedPart(im.srcMain, relType)
+	if !ok || !im.src.HasPart(part) {
+		return "", nil
+	}
+	root, err := im.src.XML(part)
+	if err != nil {
+		log.Printf("读取部件 %s 失败: %v", part, err)
+		return "", nil
+	}
+	return part, root
+}
+
+// importDefinitions 导入 content 及其页眉页脚、脚注尾注和批注用到的样式和编号定义
+func (im *documentImporter) importDefinitions(content *Node) error {
+	_, srcStyles := im.relatedRoot(relTypeStyles)
+	_, srcNumbering := im.relatedRoot(relTypeNumbering)
+	styleNodes := make(map[string]*Node)
+	if srcStyles != nil {
+		for _, style := range srcStyles.ChildrenNamed("w:style") {
+			styleNodes[style.Attr("w:styleId")] = style
+		}
+	}
+	nums := make(map[string]*Node)
+	abstracts := make(map[string]*Node)
+	if srcNumbering != nil {
+		for _, num := range srcNumbering.ChildrenNamed("w:num") {
+			nums[num.Attr("w:numId")] = num
+		}
+		for _, abstract := range srcNumbering.ChildrenNamed("w:abstractNum") {
+			abstracts[abstract.Attr("w:abstractNumId")] = abstract
+		}
+	}
+
+	// 找出所有直接或间接用到的样式和编号
+	usedStyles := make(map[string]bool)
+	usedNums := make(map[string]bool)
+	var styleOrder, numOrder []string
+	var pending []*Node
+	scan := func(n *Node) {
+		n.Walk(func(node, parent *Node) bool {
+			switch {
+			case styleReferenceElements[node.Name]:
+				if id := node.Attr("w:val"); id != "" && !usedStyles[id] && styleNodes[id] != nil {
+					usedStyles[id] = true
+					styleOrder = append(styleOrder, id)
+					pending = append(pending, styleNodes[id])
+				}
+			case node.Name == "w:numId":
+				if id := node.Attr("w:val"); id != "0" && !usedNums[id] && nums[id] != nil {
+					usedNums[id] = true
+					numOrder = append(numOrder, id)
+					if ref := nums[id].Child("w:abstractNumId"); ref != nil && abstracts[ref.Attr("w:val")] != nil {
+						pending = append(pending, abstracts[ref.Attr("w:val")])
+					}
+				}
+			}
+			return true
+		})
+	}
+	scan(content)
+	for _, root := range im.annotationSources(content) {
+		scan(root)
+	}
+	for len(pending) > 0 {
+		n := pending[0]
+		pending = pending[1:]
+		scan(n)
+	}
+	// 段落没有指定样式时使用默认段落样式
+	for id, style := range styleNodes {
+		if style.Attr("w:default") == "1" && !usedStyles[id] {
+			usedStyles[id] = true
+			styleOrder = append(styleOrder, id)
+		}
+	}
+
+	var imported []*Node
+	if len(styleOrder) > 0 {
+		dstStyles, err := im.stylesRoot()
+		if err != nil {
+			return err
+		}
+		imported = im.mapStyles(dstStyles, styleOrder, styleNodes)
+	}
+	if len(numOrder) > 0 {
+		if err := im.importNumbering(numOrder, nums, abstracts); err != nil {
+			return err
+		}
+	}
+	// 编号导入之后才能修改样式中引用的编号
+	for _, style := range imported {
+		im.remapDefinitions(style)
+	}
+	return nil
+}
+
+// annotationSources 返回 content 引用的页眉页脚部件，以及来源文档的脚注尾注和批注部件
+func (im *documentImporter) annotationSources(content *Node) []*Node {
+	var roots []*Node
+	for _, relType := range []string{relTypeFootnotes, relTypeEndnotes, relTypeComments} {
+		if _, root := im.relatedRoot(relType); root != nil {
+			roots = append(roots, root)
+		}
+	}
+	for _, ref := range append(content.Find("w:headerReference"), content.Find("w:footerReference")...) {
+		rel, ok := im.src.Relationship(im.srcMain, ref.Attr("r:id"))
+		if !ok || rel.External {
+			continue
+		}
+		if root, err := im.src.XML(im.src.ResolveTarget(im.srcMain, rel.Target)); err == nil {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// stylesRoot 返回本文档的样式部件，不存在时使用基本样式创建
+func (im *documentImporter) stylesRoot() (*Node, error) {
+	_, root, err := im.dst.ensureRelatedPart(relTypeStyles, "word/styles.xml", contentTypeStyles, func() *Node {
+		root, _ := ParseXML([]byte(defaultStylesXML))
+		return root
+	})
+	return root, err
+}
+
+// mapStyles 决定来源样式在本文档中的ID，返回需要添加到本文档的样式副本
+//
+// 默认样式使用本文档同类型的默认样式；ID相同且定义相同的样式直接使用；
+// 定义不同或名称冲突的样式以新的ID和名称导入。
+func (im *documentImporter) mapStyles(root *Node, order []string, styleNodes map[string]*Node) []*Node {
+	existing := make(map[string]*Node)
+	usedNames := make(map[string]bool)
+	defaults := make(map[string]string)
+	for _, style := range root.ChildrenNamed("w:style") {
+		existing[style.Attr("w:styleId")] = style
+		if name := style.Child("w:name"); name != nil {
+			usedNames[strings.ToLower(name.Attr("w:val"))] = true
+		}
+		if style.Attr("w:default") == "1" {
+			defaults[style.Attr("w:type")] = style.Attr("w:styleId")
+		}
+	}
+
+	var added []*Node
+	for _, id := range order {
+		style := styleNodes[id]
+		if style.Attr("w:default") == "1" {
+			if target, ok := defaults[style.Attr("w:type")]; ok {
+				im.styles[id] = target
+				continue
+			}
+		}
+		name := ""
+		if n := style.Child("w:name"); n != nil {
+			name = n.Attr("w:val")
+		}
+		if current, ok := existing[id]; ok && sameStyle(current, style) {
+			im.styles[id] = id
+			// 直接使用的样式沿用本文档中的列表，来源文档中同一列表的其它样式和段落也接着编号
+			if numID := current.Find("w:numId"); len(numID) > 0 {
+				if val := numID[0].Attr("w:val"); val != "0" {
+					if _, mapped := im.numbers[val]; !mapped {
+						im.numbers[val] = val
+					}
+				}
+			}
+			continue
+		}
+
+		copied := style.Clone()
+		copied.RemoveAttr("w:default")
+		newID, newName := id, name
+		if existing[id] != nil || (name != "" && usedNames[strings.ToLower(name)]) {
+			for n := 2; ; n++ {
+				newID = fmt.Sprintf("%s%d", id, n)
+				if name != "" {
+					newName = fmt.Sprintf("%s (%d)", name, n)
+				}
+				if existing[newID] == nil && (name == "" || !usedNames[strings.ToLower(newName)]) {
+					break
+				}
+			}
+			// 改名后的标题样式不再被识别为标题，明确设置大纲级别以保持文档结构
+			if m := headingNamePattern.FindStringSubmatch(name); m != nil {
+				level, _ := strconv.Atoi(m[1])
+				pPr := copied.EnsureChild("w:pPr", styleOrder)
+				if pPr.Child("w:outlineLvl") == nil {
+					pPr.InsertOrdered(NewNode("w:outlineLvl", "w:val", strconv.Itoa(level-1)), pPrOrder)
+				}
+			}
+		}
+		copied.SetAttr("w:styleId", newID)
+		if n := copied.Child("w:name"); n != nil {
+			n.SetAttr("w:val", newName)
+		}
+		existing[newID] = copied
+		usedNames[strings.ToLower(newName)] = true
+		im.styles[id] = newID
+		root.AppendChild(copied)
+		added = append(added, copied)
+	}
+	return added
+}
+
+// sameStyle 判断两个样式的定义是否相同，忽略修订标识
+func sameStyle(a, b *Node) bool {
+	clean := func(n *Node) string {
+		c := n.Clone()
+		c.RemoveChildrenNamed("w:rsid")
+		return string(c.Bytes())
+	}
+	return clean(a) == clean(b)
+}
+
+// importNumbering 将用到的编号实例及其抽象编号作为新的列表导入
+func (im *documentImporter) importNumbering(order []string, nums, abstracts map[string]*Node) error {
+	root, err := im.doc.numberingRoot(true)
+	if err != nil {
+		return err
+	}
+	_, srcRoot := im.relatedRoot(relTypeNumbering)
+	mergeNamespaces(root, srcRoot)
+
+	abstractIDs := make(map[string]string)
+	for _, id := range order {
+		if _, mapped := im.numbers[id]; mapped {
+			continue
+		}
+		num := nums[id].Clone()
+		ref := num.Child("w:abstractNumId")
+		if ref == nil || abstracts[ref.Attr("w:val")] == nil {
+			// 编号定义不完整，复制的段落不使用编号
+			im.numbers[id] = "0"
+			continue
+		}
+		srcAbstract := ref.Attr("w:val")
+		abstractID, ok := abstractIDs[srcAbstract]
+		if !ok {
+			abstract := abstracts[srcAbstract].Clone()
+			abstractID = nextNumberingID(root, "w:abstractNum", "w:abstractNumId")
+			abstract.SetAttr("w:abstractNumId", abstractID)
+			// 相同的 nsid 会使Word把两个列表当作同一个列表
+			if nsid := abstract.Child("w:nsid"); nsid != nil {
+				nsid.SetAttr("w:val", fmt.Sprintf("%08X", rand.Uint32()))
+			}
+			for _, lvl := range abstract.ChildrenNamed("w:lvl") {
+				lvl.RemoveChildrenNamed("w:lvlPicBulletId")
+			}
+			im.remapDefinitions(abstract)
+			position := len(root.Children)
+			for i, c := range root.Children {
+				if c.Name == "w:num" || c.Name == "w:numIdMacAtCleanup" {
+					position = i
+					break
+				}
+			}
+			root.InsertChild(position, abstract)
+			abstractIDs[srcAbstract] = abstractID
+		}
+		numID := nextNumberingID(root, "w:num", "w:numId")
+		num.SetAttr("w:numId", numID)
+		ref.SetAttr("w:val", abstractID)
+		position := len(root.Children)
+		if cleanup := root.Child("w:numIdMacAtCleanup"); cleanup != nil {
+			position = root.IndexOf(cleanup)
+		}
+		root.InsertChild(position, num)
+		im.numbers[id] = numID
+	}
+	return nil
+}
+
+// remapDefinitions 修改样式或编号定义中引用的样式ID和编号ID
+func (im *documentImporter) remapDefinitions(n *Node) {
+	n.Walk(func(node, parent *Node) bool {
+		im.remapReference(node)
+		return true
+	})
+}
+
+// remapReference 修改引用样式或编号的元素中的ID
+func (im *documentImporter) remapReference(node *Node) {
+	switch {
+	case styleReferenceElements[node.Name]:
+		if id, ok := im.styles[node.Attr("w:val")]; ok {
+			node.SetAttr("w:val", id)
+		}
+	case node.Name == "w:numId":
+		if id, ok := im.numbers[node.Attr("w:val")]; ok {
+			node.SetAttr("w:val", id)
+		}
+	}
+}
+
+// importAnnotations 导入 content 引用的脚注、尾注和批注，并记录它们的新ID
+func (im *documentImporter) importAnnotations(content *Node) error {
+	notes := []struct {
+		kind      NoteKind
+		reference string
+		ids       map[string]string
+	}{
+		{FootnoteKind, "w:footnoteReference", im.footnotes},
+		{EndnoteKind, "w:endnoteReference", im.endnotes},
+	}
+	for _, note := range notes {
+		refs := content.Find(note.reference)
+		if len(refs) == 0 {
+			continue
+		}
+		spec := note.kind.spec()
+		srcPart, srcRoot := im.relatedRoot(spec.relType)
+		if srcRoot == nil {
+			continue
+		}
+		root, err := im.doc.notesRoot(note.kind, true)
+		if err != nil {
+			return err
+		}
+		dstPart, _ := im.dst.RelatedPart(im.dstMain, spec.relType)
+		mergeNamespaces(root, srcRoot)
+		for _, ref := range refs {
+			id := ref.Attr("w:id")
+			if _, done := note.ids[id]; done {
+				continue
+			}
+			for _, n := range srcRoot.ChildrenNamed(spec.element) {
+				if n.Attr("w:id") == id {
+					copied := n.Clone()
+					newID := nextNoteID(root, spec.element)
+					note.ids[id] = newID
+					copied.SetAttr("w:id", newID)
+					im.remap(copied, srcPart, dstPart)
+					root.AppendChild(copied)
+					break
+				}
+			}
+		}
+	}
+
+	refs := content.Find("w:commentReference")
+	if len(refs) == 0 {
+		return nil
+	}
+	srcPart, srcRoot := im.relatedRoot(relTypeComments)
+	if srcRoot == nil {
+		return nil
+	}
+	root, err := im.doc.commentsRoot(true)
+	if err != nil {
+		return err
+	}
+	dstPart, _ := im.dst.RelatedPart(im.dstMain, relTypeComments)
+	mergeNamespaces(root, srcRoot)
+	for _, ref := range refs {
+		id := ref.Attr("w:id")
+		if _, done := im.comments[id]; done {
+			continue
+		}
+		if comment := findComment(srcRoot, id); comment != nil {
+			copied := comment.Clone()
+			newID := nextCommentID(root)
+			im.comments[id] = newID
+			copied.SetAttr("w:id", newID)
+			im.remap(copied, srcPart, dstPart)
+			root.AppendChild(copied)
+		}
+	}
+	return nil
+}
+
+// remap 修改复制的内容中引用的样式、编号、关系、注释、书签和图片ID，
+// srcPart 和 dstPart 为内容原来和现在所在的部件
+func (im *documentImporter) remap(n *Node, srcPart, dstPart string) {
+	n.Walk(func(node, parent *Node) bool {
+		switch node.Name {
+		case "w:footnoteReference":
+			if id, ok := im.footnotes[node.Attr("w:id")]; ok {
+				node.SetAttr("w:id", id)
+			}
+		case "w:endnoteReference":
+			if id, ok := im.endnotes[node.Attr("w:id")]; ok {
+				node.SetAttr("w:id", id)
+			}
+		case "w:commentRangeStart", "w:commentRangeEnd", "w:commentReference":
+			if id, ok := im.comments[node.Attr("w:id")]; ok {
+				node.SetAttr("w:id", id)
+			}
+		case "w:bookmarkStart":
+			name := node.Attr("w:name")
+			if im.usedBookmarks[strings.ToLower(name)] {
+				renamed := name
+				for i := 2; im.usedBookmarks[strings.ToLower(renamed)]; i++ {
+					renamed = fmt.Sprintf("%s_%d", name, i)
+				}
+				im.bookmarkNames[name] = renamed
+				node.SetAttr("w:name", renamed)
+			}
+			im.usedBookmarks[strings.ToLower(node.Attr("w:name"))] = true
+			im.bookmarkIDs[node.Attr("w:id")] = strconv.Itoa(im.nextBookmarkID)
+			node.SetAttr("w:id", strconv.Itoa(im.nextBookmarkID))
+			im.nextBookmarkID++
+		case "w:bookmarkEnd":
+			if id, ok := im.bookmarkIDs[node.Attr("w:id")]; ok {
+				node.SetAttr("w:id", id)
+			}
+		case drawingPropertiesElement:
+			node.SetAttr("id", strconv.Itoa(im.nextDrawingID))
+			im.nextDrawingID++
+		}
+		im.remapReference(node)
+		node.RemoveAttr("w14:paraId")
+		node.RemoveAttr("w14:textId")
+		for i, attr := range node.Attrs {
+			if strings.HasPrefix(attr.Name, "r:") {
+				node.Attrs[i].Value = im.importRelationship(srcPart, dstPart, attr.Value)
+			}
+		}
+		return true
+	})
+
+	// 书签改名后修改引用它的超链接和域，书签可能出现在引用之后，因此最后处理
+	if len(im.bookmarkNames) == 0 {
+		return
+	}
+	n.Walk(func(node, parent *Node) bool {
+		switch node.Name {
+		case "w:hyperlink":
+			if renamed, ok := im.bookmarkNames[node.Attr("w:anchor")]; ok {
+				node.SetAttr("w:anchor", renamed)
+			}
+		case "w:instrText":
+			if instr, ok := im.renameFieldBookmark(node.InnerText()); ok {
+				node.SetText(instr)
+			}
+		case "w:fldSimple":
+			if instr, ok := im.renameFieldBookmark(node.Attr("w:instr")); ok {
+				node.SetAttr("w:instr", instr)
+			}
+		}
+		return true
+	})
+}
+
+// renameFieldBookmark 域引用的书签改名时返回修改后的域代码
+func (im *documentImporter) renameFieldBookmark(instr string) (string, bool) {
+	code := ParseFieldCode(instr)
+	renamed, ok := im.bookmarkNames[code.Argument]
+	if !ok || !bookmarkFieldTypes[code.Type] {
+		return "", false
+	}
+	return strings.Replace(instr, code.Argument, renamed, 1), true
+}
+
+// importRelationship 为复制到 dstPart 的内容建立与来源关系相同的关系，返回新的关系ID
+//
+// 包内的目标部件（图片、页眉页脚、嵌入对象等）连同它们的关系一起复制。
+func (im *documentImporter) importRelationship(srcPart, dstPart, id string) string {
+	key := srcPart + "#" + id
+	if newID, ok := im.rels[key]; ok {
+		return newID
+	}
+	rel, ok := im.src.Relationship(srcPart, id)
+	if !ok {
+		log.Printf("找不到关系 %s: %s", srcPart, id)
+		return id
+	}
+	var newID string
+	if rel.External {
+		newID = im.dst.AddRelationship(dstPart, rel.Type, rel.Target, true)
+	} else {
+		remap := rel.Type == relTypeHeader || rel.Type == relTypeFooter
+		name := im.importPart(im.src.ResolveTarget(srcPart, rel.Target), remap)
+		newID = im.dst.AddRelationship(dstPart, rel.Type, partTarget(dstPart, name), false)
+	}
+	im.rels[key] = newID
+	return newID
+}
+
+// partTarget 返回从 source 部件指向 name 部件的关系目标，不在同一目录下时使用绝对路径
+func partTarget(source, name string) string {
+	if strings.HasPrefix(name, path.Dir(source)+"/") {
+		return relativeTarget(source, name)
+	}
+	return "/" + name
+}
+
+// importPart 将来源包中的部件复制到本文档，返回新的部件名称，重名时使用新的名称
+//
+// remap 为真时部件是页眉页脚等正文内容，复制后修改其中的ID；否则原样复制部件和它的关系。
+func (im *documentImporter) importPart(name string, remap bool) string {
+	if imported, ok := im.parts[name]; ok {
+		return imported
+	}
+	newName := name
+	if im.dst.HasPart(newName) {
+		newName = uniquePartName(im.dst, name)
+	}
+	im.parts[name] = newName
+
+	// 单独登记的内容类型随部件写入，按扩展名的默认内容类型在本文档中没有时添加
+	contentType, override := im.src.partContentType(name)
+	if !override {
+		if contentType != "" {
+			im.dst.EnsureDefaultContentType(path.Ext(newName), contentType)
+		}
+		contentType = ""
+	}
+	if remap {
+		if root, err := im.src.XML(name); err == nil {
+			copied := root.Clone()
+			im.dst.SetXMLPart(newName, copied, contentType)
+			im.remap(copied, name, newName)
+			return newName
+		}
+	}
+	data, _ := im.src.Part(name)
+	im.dst.SetPart(newName, data, contentType)
+
+	rels := im.src.Relationships(name)
+	if len(rels) == 0 {
+		return newName
+	}
+	relsRoot := NewNode("Relationships", "xmlns", nsPackageRels)
+	for _, rel := range rels {
+		target := rel.Target
+		if !rel.External {
+			target = partTarget(newName, im.importPart(im.src.ResolveTarget(name, rel.Target), false))
+		}
+		n := NewNode("Relationship", "Id", rel.ID, "Type", rel.Type, "Target", target)
+		if rel.External {
+			n.SetAttr("TargetMode", "External")
+		}
+		relsRoot.AppendChild(n)
+	}
+	im.dst.SetXMLPart(relationshipsPartName(newName), relsRoot, "")
+	return newName
+}
+
+// partContentType 返回部件的内容类型，override 表示内容类型单独登记而不是按扩展名的默认类型
+func (p *Package) partContentType(name string) (contentType string, override bool) {
+	types, err := p.XML(contentTypesPart)
+	if err != nil {
+		return "", false
+	}
+	for _, o := range types.ChildrenNamed("Override") {
+		if o.Attr("PartName") == "/"+name {
+			return o.Attr("ContentType"), true
+		}
+	}
+	ext := strings.TrimPrefix(strings.ToLower(path.Ext(name)), ".")
+	for _, d := range types.ChildrenNamed("Default") {
+		if strings.EqualFold(d.Attr("Extension"), ext) {
+			return d.Attr("ContentType"), false
+		}
+	}
+	return "", false
+}
+
+// uniquePartName 返回与 name 在同一目录、名称末尾的序号不同的未使用部件名称，如 word/media/image3.png
+func uniquePartName(p *Package, name string) string {
+	dir, ext := path.Dir(name), path.Ext(name)
+	stem := strings.TrimRight(strings.TrimSuffix(path.Base(name), ext), "0123456789")
+	for i := 1; ; i++ {
+		candidate := path.Join(dir, fmt.Sprintf("%s%d%s", stem, i, ext))
+		if !p.HasPart(candidate) {
+			return candidate
+		}
+	}
+}
+
+// mergeNamespaces 将 src 根元素声明而 dst 根元素没有声明的命名空间前缀添加到 dst，
+// 并合并 mc:Ignorable 中可忽略的前缀
+func mergeNamespaces(dst, src *Node) {
+	if dst == nil || src == nil {
+		return
+	}
+	for _, attr := range src.Attrs {
+		if strings.HasPrefix(attr.Name, "xmlns:") && !dst.HasAttr(attr.Name) {
+			dst.SetAttr(attr.Name, attr.Value)
+		}
+	}
+	ignorable := strings.Fields(dst.Attr("mc:Ignorable"))
+	for _, prefix := range strings.Fields(src.Attr("mc:Ignorable")) {
+		if indexOfString(ignorable, prefix) < 0 && dst.HasAttr("xmlns:"+prefix) {
+			ignorable = append(ignorable, prefix)
+		}
+	}
+	if len(ignorable) > 0 {
+		dst.SetAttr("mc:Ignorable", strings.Join(ignorable, " "))
+	}
+}
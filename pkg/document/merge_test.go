@@ -0,0 +1,198 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+// addTestDefinitions 向测试文档的样式部件添加样式，numbering 不为空时向编号部件添加编号定义
+func addTestDefinitions(t *testing.T, doc *Document, styles, numbering string) {
+	t.Helper()
+	part, ok := doc.Package.RelatedPart(doc.Package.MainPartName(), relTypeStyles)
+	if !ok {
+		t.Fatalf("测试文档没有样式部件")
+	}
+	root, err := doc.Package.XML(part)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseXML([]byte("<w:styles>" + styles + "</w:styles>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, style := range parsed.Elements() {
+		// 同ID的样式以测试中的定义为准
+		for _, old := range root.ChildrenNamed("w:style") {
+			if old.Attr("w:styleId") == style.Attr("w:styleId") {
+				root.RemoveChild(old)
+			}
+		}
+		root.AppendChild(style)
+	}
+	if numbering == "" {
+		return
+	}
+	numRoot, err := doc.numberingRoot(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defs, err := ParseXML([]byte("<w:numbering>" + numbering + "</w:numbering>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	numRoot.AppendChild(defs.Elements()...)
+}
+
+// testStyle 返回段落样式的XML，extra 为样式中的其它元素
+func testStyle(id, extra string) string {
+	return `<w:style w:type="paragraph" w:styleId="` + id + `"><w:name w:val="` + id + `"/>` + extra + `</w:style>`
+}
+
+func TestAppendDocumentStyles(t *testing.T) {
+	tests := []struct {
+		name           string
+		dstStyles      string
+		srcStyles      string
+		style          string // 来源段落使用的样式
+		want           string // 追加后段落使用的样式
+		wantName       string
+		wantBasedOn    string
+		wantOutlineLvl string
+		added          int // 导入的样式数量
+	}{
+		{"相同的样式直接使用",
+			testStyle("Quote", `<w:rPr><w:i/></w:rPr>`), testStyle("Quote", `<w:rPr><w:i/></w:rPr>`),
+			"Quote", "Quote", "Quote", "", "", 0},
+		{"只在来源文档中的样式",
+			"", testStyle("Note", `<w:rPr><w:b/></w:rPr>`),
+			"Note", "Note", "Note", "", "", 1},
+		{"定义不同的样式改名导入",
+			testStyle("Quote", `<w:rPr><w:i/></w:rPr>`), testStyle("Quote", `<w:rPr><w:b/></w:rPr>`),
+			"Quote", "Quote2", "Quote (2)", "", "", 1},
+		{"改名的标题样式保留大纲级别",
+			"", `<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:rPr><w:color w:val="FF0000"/></w:rPr></w:style>`,
+			"Heading1", "Heading12", "heading 1 (2)", "", "0", 1},
+		{"基于的样式随之改名",
+			testStyle("Base", `<w:rPr><w:i/></w:rPr>`),
+			testStyle("Base", `<w:rPr><w:b/></w:rPr>`) + testStyle("Child", `<w:basedOn w:val="Base"/>`),
+			"Child", "Child", "Child", "Base2", "", 2},
+		{"默认样式使用本文档的默认样式",
+			"", `<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:rPr><w:sz w:val="40"/></w:rPr></w:style>`,
+			"Normal", "Normal", "Normal", "", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := newBodyTestDocument(t, styledParagraph("Normal", "原文"))
+			addTestDefinitions(t, dst, tt.dstStyles, "")
+			src := newBodyTestDocument(t, styledParagraph(tt.style, "追加"))
+			addTestDefinitions(t, src, tt.srcStyles, "")
+			before := len(testStyles(t, dst))
+
+			if err := dst.AppendDocument(src.Package, SectionContinuous); err != nil {
+				t.Fatalf("追加文档失败: %v", err)
+			}
+			paragraphs := dst.paragraphNodes()
+			if got := paragraphTexts(dst); !reflect.DeepEqual(got, []string{"原文", "追加"}) {
+				t.Fatalf("追加后的段落 %q", got)
+			}
+			if got := paragraphStyle(paragraphs[1]); got != tt.want {
+				t.Errorf("追加的段落样式 %q, 期望 %q", got, tt.want)
+			}
+
+			styles := testStyles(t, dst)
+			style := styles[tt.want]
+			if style == nil {
+				t.Fatalf("本文档中没有样式 %q", tt.want)
+			}
+			if got := style.Child("w:name").Attr("w:val"); got != tt.wantName {
+				t.Errorf("样式名称 %q, 期望 %q", got, tt.wantName)
+			}
+			if tt.wantBasedOn != "" {
+				if got := style.Child("w:basedOn").Attr("w:val"); got != tt.wantBasedOn {
+					t.Errorf("基于的样式 %q, 期望 %q", got, tt.wantBasedOn)
+				}
+				if styles[tt.wantBasedOn] == nil {
+					t.Errorf("没有导入基于的样式 %q", tt.wantBasedOn)
+				}
+			}
+			if tt.wantOutlineLvl != "" {
+				lvl := style.Find("w:outlineLvl")
+				if len(lvl) == 0 || lvl[0].Attr("w:val") != tt.wantOutlineLvl {
+					t.Errorf("改名的标题样式没有大纲级别 %s", tt.wantOutlineLvl)
+				}
+				if rPr := style.Child("w:rPr"); rPr != nil && style.IndexOf(style.Child("w:pPr")) > style.IndexOf(rPr) {
+					t.Errorf("样式中的 w:pPr 位于 w:rPr 之后: %s", style)
+				}
+			}
+			if added := len(styles) - before; added != tt.added {
+				t.Errorf("导入了 %d 个样式, 期望 %d 个", added, tt.added)
+			}
+			if len(styles["Normal"].Find("w:sz")) > 0 {
+				t.Errorf("来源文档的默认样式覆盖了本文档的默认样式")
+			}
+		})
+	}
+}
+
+func TestAppendDocumentNumbering(t *testing.T) {
+	list := func(numID, text string) string {
+		return `<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="` + numID + `"/></w:numPr></w:pPr><w:r><w:t>` +
+			text + `</w:t></w:r></w:p>`
+	}
+	listStyle := testStyle("ListItem", `<w:pPr><w:numPr><w:numId w:val="1"/></w:numPr></w:pPr>`)
+
+	tests := []struct {
+		name       string
+		dst, src   string
+		dstStyles  string
+		srcStyles  string
+		wantLabels []string
+	}{
+		{"来源文档的列表作为新列表导入",
+			list("1", "甲") + list("1", "乙"), list("1", "丙") + list("1", "丁"), "", "",
+			[]string{"1.", "2.", "1.", "2."}},
+		{"起始值覆盖随编号实例导入",
+			list("1", "甲"), list("4", "丙") + list("4", "丁"), "", "",
+			[]string{"1.", "5.", "6."}},
+		{"项目符号",
+			list("1", "甲"), list("2", "丙"), "", "",
+			[]string{"1.", "•"}},
+		{"相同的列表样式接着编号",
+			styledParagraph("ListItem", "甲") + styledParagraph("ListItem", "乙"),
+			styledParagraph("ListItem", "丙"), listStyle, listStyle,
+			[]string{"1.", "2.", "3."}},
+		{"不同的列表样式重新编号",
+			styledParagraph("ListItem", "甲"),
+			styledParagraph("ListItem", "丙"), listStyle, testStyle("ListItem", `<w:pPr><w:numPr><w:numId w:val="1"/></w:numPr><w:jc w:val="center"/></w:pPr>`),
+			[]string{"1.", "1."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := newBodyTestDocument(t, tt.dst)
+			addTestDefinitions(t, dst, tt.dstStyles, testNumbering)
+			src := newBodyTestDocument(t, tt.src)
+			addTestDefinitions(t, src, tt.srcStyles, testNumbering)
+			numRoot, _ := dst.numberingRoot(false)
+			abstracts, nums := len(numRoot.ChildrenNamed("w:abstractNum")), len(numRoot.ChildrenNamed("w:num"))
+
+			if err := dst.AppendDocument(src.Package, SectionContinuous); err != nil {
+				t.Fatalf("追加文档失败: %v", err)
+			}
+			if got := dst.ListLabels(); !reflect.DeepEqual(got, tt.wantLabels) {
+				t.Errorf("追加后的编号 %q, 期望 %q", got, tt.wantLabels)
+			}
+
+			// 导入的编号使用新的ID，不与本文档已有的编号冲突
+			ids := make(map[string]bool)
+			for _, num := range numRoot.ChildrenNamed("w:num") {
+				if ids[num.Attr("w:numId")] {
+					t.Errorf("编号实例ID %s 重复", num.Attr("w:numId"))
+				}
+				ids[num.Attr("w:numId")] = true
+			}
+			if len(numRoot.ChildrenNamed("w:abstractNum")) < abstracts || len(numRoot.ChildrenNamed("w:num")) < nums {
+				t.Errorf("追加后编号定义减少")
+			}
+		})
+	}
+}
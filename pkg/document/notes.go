@@ -0,0 +1,508 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 脚注和尾注相关的部件类型
+const (
+	relTypeFootnotes     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/footnotes"
+	relTypeEndnotes      = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/endnotes"
+	contentTypeFootnotes = "application/vnd.openxmlformats-officedocument.wordprocessingml.footnotes+xml"
+	contentTypeEndnotes  = "application/vnd.openxmlformats-officedocument.wordprocessingml.endnotes+xml"
+)
+
+// NoteKind 区分脚注和尾注
+type NoteKind int
+
+const (
+	FootnoteKind NoteKind = iota // 脚注
+	EndnoteKind                  // 尾注
+)
+
+// String 返回脚注或尾注的中文名称
+func (k NoteKind) String() string {
+	if k == EndnoteKind {
+		return "尾注"
+	}
+	return "脚注"
+}
+
+// noteSpec 描述一类注释在WordprocessingML中使用的元素和部件
+type noteSpec struct {
+	relType     string
+	contentType string
+	part        string
+	root        string // 部件根元素
+	element     string // 单个注释元素
+	reference   string // 正文中的引用元素
+	mark        string // 注释内容中的编号标记
+	textStyle   string // 注释文字的段落样式
+	refStyle    string // 引用编号的字符样式
+	property    string // 设置中的注释属性元素
+}
+
+// spec 返回该类注释的元素和部件描述
+func (k NoteKind) spec() noteSpec {
+	if k == EndnoteKind {
+		return noteSpec{
+			relType:     relTypeEndnotes,
+			contentType: contentTypeEndnotes,
+			part:        "word/endnotes.xml",
+			root:        "w:endnotes",
+			element:     "w:endnote",
+			reference:   "w:endnoteReference",
+			mark:        "w:endnoteRef",
+			textStyle:   "EndnoteText",
+			refStyle:    "EndnoteReference",
+			property:    "w:endnotePr",
+		}
+	}
+	return noteSpec{
+		relType:     relTypeFootnotes,
+		contentType: contentTypeFootnotes,
+		part:        "word/footnotes.xml",
+		root:        "w:footnotes",
+		element:     "w:footnote",
+		reference:   "w:footnoteReference",
+		mark:        "w:footnoteRef",
+		textStyle:   "FootnoteText",
+		refStyle:    "FootnoteReference",
+		property:    "w:footnotePr",
+	}
+}
+
+// Note 文档中的一个脚注或尾注
+type Note struct {
+	Kind      NoteKind
+	ID        string
+	Number    int    // 按引用在正文中的顺序自动编号，未被引用时为0
+	Text      string // 注释文字，多个段落以换行分隔
+	Paragraph int    // 引用所在的正文段落索引，未找到时为-1
+}
+
+// noteKey 返回注释在编号表中的键
+func noteKey(endnote bool, id string) string {
+	if endnote {
+		return "e" + id
+	}
+	return "f" + id
+}
+
+// noteNumbers 按引用在正文中出现的顺序为脚注和尾注分别编号
+//
+// 使用自定义标记（w:customMarkFollows）的引用不参与自动编号。
+func (doc *Document) noteNumbers() map[string]int {
+	numbers := make(map[string]int)
+	body, err := doc.body()
+	if err != nil {
+		return numbers
+	}
+	footnotes, endnotes := 0, 0
+	body.Walk(func(n, parent *Node) bool {
+		switch n.Name {
+		case "w:footnoteReference", "w:endnoteReference":
+			if onOffAttr(n, "w:customMarkFollows") {
+				return true
+			}
+			endnote := n.Name == "w:endnoteReference"
+			key := noteKey(endnote, n.Attr("w:id"))
+			if _, ok := numbers[key]; ok {
+				return true
+			}
+			if endnote {
+				endnotes++
+				numbers[key] = endnotes
+			} else {
+				footnotes++
+				numbers[key] = footnotes
+			}
+		}
+		return true
+	})
+	return numbers
+}
+
+// onOffAttr 判断开关类型的属性是否开启
+func onOffAttr(n *Node, name string) bool {
+	switch n.Attr(name) {
+	case "1", "true", "on":
+		return true
+	}
+	return false
+}
+
+// numberNoteSpans 将片段中的注释引用显示为自动编号，尾注编号使用小写罗马数字
+func numberNoteSpans(spans []TextSpan, numbers map[string]int) {
+	for i := range spans {
+		if spans[i].NoteID == "" {
+			continue
+		}
+		if number, ok := numbers[noteKey(spans[i].Endnote, spans[i].NoteID)]; ok {
+			spans[i].Text = noteLabel(spans[i].Endnote, number)
+		}
+	}
+}
+
+// noteLabel 返回注释编号的显示文本
+func noteLabel(endnote bool, number int) string {
+	if endnote {
+		return "[" + romanNumeral(number) + "]"
+	}
+	return "[" + strconv.Itoa(number) + "]"
+}
+
+// romanNumeral 返回小写罗马数字，Word 中尾注的默认编号格式
+func romanNumeral(n int) string {
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"m", "cm", "d", "cd", "c", "xc", "l", "xl", "x", "ix", "v", "iv", "i"}
+	var builder strings.Builder
+	for i, v := range values {
+		for n >= v {
+			builder.WriteString(symbols[i])
+			n -= v
+		}
+	}
+	return builder.String()
+}
+
+// notesRoot 返回注释部件的根节点，create 为真时在部件不存在时创建
+func (doc *Document) notesRoot(kind NoteKind, create bool) (*Node, error) {
+	if doc.Package == nil {
+		return nil, fmt.Errorf("文档不支持%s", kind)
+	}
+	spec := kind.spec()
+	if !create {
+		part, ok := doc.Package.RelatedPart(doc.Package.MainPartName(), spec.relType)
+		if !ok || !doc.Package.HasPart(part) {
+			return nil, nil
+		}
+		return doc.Package.XML(part)
+	}
+
+	created := false
+	_, root, err := doc.Package.ensureRelatedPart(spec.relType, spec.part, spec.contentType, func() *Node {
+		created = true
+		return newNotesRoot(spec)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		// 分隔符注释需要在设置中登记
+		settings, err := doc.settingsRoot()
+		if err != nil {
+			return nil, err
+		}
+		property := settings.EnsureChild(spec.property, settingsOrder)
+		property.RemoveChildrenNamed(spec.element)
+		property.AppendChild(NewNode(spec.element, "w:id", "-1"), NewNode(spec.element, "w:id", "0"))
+	}
+	return root, nil
+}
+
+// newNotesRoot 创建包含分隔符和延续分隔符的注释部件
+func newNotesRoot(spec noteSpec) *Node {
+	root := NewNode(spec.root, "xmlns:w", nsWordML, "xmlns:r", nsRelationships)
+	for _, sep := range []struct{ id, kind, mark string }{
+		{"-1", "separator", "w:separator"},
+		{"0", "continuationSeparator", "w:continuationSeparator"},
+	} {
+		p := NewNode("w:p")
+		pPr := paragraphProperties(p)
+		pPr.EnsureChild("w:spacing", pPrOrder).SetAttr("w:after", "0")
+		r := NewNode("w:r")
+		r.AppendChild(NewNode(sep.mark))
+		p.AppendChild(r)
+		note := NewNode(spec.element, "w:type", sep.kind, "w:id", sep.id)
+		note.AppendChild(p)
+		root.AppendChild(note)
+	}
+	return root
+}
+
+// isSeparatorNote 判断注释是否为分隔符等特殊注释
+func isSeparatorNote(note *Node) bool {
+	switch note.Attr("w:type") {
+	case "separator", "continuationSeparator", "continuationNotice":
+		return true
+	}
+	return false
+}
+
+// noteText 返回注释的文字，多个段落以换行分隔
+func noteText(note *Node) string {
+	var lines []string
+	for _, p := range collectParagraphs(note) {
+		lines = append(lines, strings.TrimPrefix(paragraphNodeText(p), " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// noteReferenceParagraphs 返回每个注释引用所在的正文段落索引
+func (doc *Document) noteReferenceParagraphs(kind NoteKind) map[string]int {
+	paragraphs := make(map[string]int)
+	reference := kind.spec().reference
+	for i, p := range doc.paragraphNodes() {
+		for _, ref := range p.Find(reference) {
+			if _, ok := paragraphs[ref.Attr("w:id")]; !ok {
+				paragraphs[ref.Attr("w:id")] = i
+			}
+		}
+	}
+	return paragraphs
+}
+
+// Notes 返回指定类型的所有注释，按编号排列，未被引用的注释排在最后
+func (doc *Document) Notes(kind NoteKind) ([]Note, error) {
+	root, err := doc.notesRoot(kind, false)
+	if err != nil || root == nil {
+		return nil, err
+	}
+
+	numbers := doc.noteNumbers()
+	paragraphs := doc.noteReferenceParagraphs(kind)
+	var numbered, unreferenced []Note
+	for _, n := range root.ChildrenNamed(kind.spec().element) {
+		if isSeparatorNote(n) {
+			continue
+		}
+		note := Note{
+			Kind:      kind,
+			ID:        n.Attr("w:id"),
+			Text:      noteText(n),
+			Paragraph: -1,
+		}
+		note.Number = numbers[noteKey(kind == EndnoteKind, note.ID)]
+		if index, ok := paragraphs[note.ID]; ok {
+			note.Paragraph = index
+		}
+		if note.Number > 0 {
+			numbered = append(numbered, note)
+		} else {
+			unreferenced = append(unreferenced, note)
+		}
+	}
+
+	sort.SliceStable(numbered, func(i, j int) bool {
+		return numbered[i].Number < numbered[j].Number
+	})
+	return append(numbered, unreferenced...), nil
+}
+
+// AllNotes 返回所有脚注和尾注，脚注在前
+func (doc *Document) AllNotes() ([]Note, error) {
+	footnotes, err := doc.Notes(FootnoteKind)
+	if err != nil {
+		return nil, err
+	}
+	endnotes, err := doc.Notes(EndnoteKind)
+	if err != nil {
+		return nil, err
+	}
+	return append(footnotes, endnotes...), nil
+}
+
+// NotesByParagraph 按引用所在的段落对脚注和尾注分组
+func (doc *Document) NotesByParagraph() map[int][]Note {
+	result := make(map[int][]Note)
+	notes, err := doc.AllNotes()
+	if err != nil {
+		log.Printf("读取脚注和尾注失败: %v", err)
+		return result
+	}
+	for _, note := range notes {
+		if note.Paragraph >= 0 {
+			result[note.Paragraph] = append(result[note.Paragraph], note)
+		}
+	}
+	return result
+}
+
+// InsertFootnote 在段落第 offset 个字符后插入脚注，offset 为负数时插入到段落末尾
+func (doc *Document) InsertFootnote(paragraph, offset int, text string) (Note, error) {
+	return doc.InsertNote(FootnoteKind, paragraph, offset, text)
+}
+
+// InsertEndnote 在段落第 offset 个字符后插入尾注，offset 为负数时插入到段落末尾
+func (doc *Document) InsertEndnote(paragraph, offset int, text string) (Note, error) {
+	return doc.InsertNote(EndnoteKind, paragraph, offset, text)
+}
+
+// InsertNote 在段落第 offset 个字符后插入脚注或尾注
+//
+// 位置按段落的最终文本计算，offset 为负数或超出段落长度时插入到段落末尾。
+// 注释编号由引用在正文中的顺序自动决定，插入后其后的注释编号随之顺延。
+func (doc *Document) InsertNote(kind NoteKind, paragraph, offset int, text string) (Note, error) {
+	if strings.TrimSpace(text) == "" {
+		return Note{}, fmt.Errorf("%s内容不能为空", kind)
+	}
+	paragraphs := doc.paragraphNodes()
+	if paragraph < 0 || paragraph >= len(paragraphs) {
+		return Note{}, fmt.Errorf("段落索引超出范围: %d", paragraph+1)
+	}
+	root, err := doc.notesRoot(kind, true)
+	if err != nil {
+		return Note{}, err
+	}
+	spec := kind.spec()
+
+	id := nextNoteID(root, spec.element)
+	note := NewNode(spec.element, "w:id", id)
+	note.AppendChild(newNoteParagraphs(spec, text)...)
+	root.AppendChild(note)
+
+	p := paragraphs[paragraph]
+	if offset < 0 {
+		offset = len([]rune(paragraphNodeText(p)))
+	}
+	point := splitRunsAt(p, offset)
+	point.insert(newNoteReferenceRun(spec, id))
+
+	doc.IsModified = true
+	number := doc.noteNumbers()[noteKey(kind == EndnoteKind, id)]
+	log.Printf("已在段落 %d 插入%s %d", paragraph+1, kind, number)
+	return Note{
+		Kind:      kind,
+		ID:        id,
+		Number:    number,
+		Text:      text,
+		Paragraph: paragraph,
+	}, nil
+}
+
+// SetNoteText 修改脚注或尾注的文字
+func (doc *Document) SetNoteText(kind NoteKind, id, text string) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("%s内容不能为空", kind)
+	}
+	note, _, err := doc.findNote(kind, id)
+	if err != nil {
+		return err
+	}
+
+	spec := kind.spec()
+	paragraphs := newNoteParagraphs(spec, text)
+	// 保留原有的段落格式
+	if old := collectParagraphs(note); len(old) > 0 {
+		if pPr := old[0].Child("w:pPr"); pPr != nil {
+			for _, p := range paragraphs {
+				p.RemoveChildrenNamed("w:pPr")
+				p.InsertChild(0, pPr.Clone())
+			}
+		}
+	}
+	note.Children = nil
+	note.AppendChild(paragraphs...)
+
+	doc.IsModified = true
+	log.Printf("已修改%s %s", kind, id)
+	return nil
+}
+
+// DeleteNote 删除脚注或尾注及正文中的引用
+func (doc *Document) DeleteNote(kind NoteKind, id string) error {
+	note, root, err := doc.findNote(kind, id)
+	if err != nil {
+		return err
+	}
+	body, err := doc.body()
+	if err != nil {
+		return err
+	}
+
+	spec := kind.spec()
+	var runs []nodeRef
+	body.Walk(func(n, parent *Node) bool {
+		if n.Name == "w:r" {
+			for _, c := range n.ChildrenNamed(spec.reference) {
+				if c.Attr("w:id") == id {
+					runs = append(runs, nodeRef{node: n, parent: parent})
+				}
+			}
+			return false
+		}
+		return true
+	})
+	for _, ref := range runs {
+		for _, c := range ref.node.ChildrenNamed(spec.reference) {
+			if c.Attr("w:id") == id {
+				ref.node.RemoveChild(c)
+			}
+		}
+		// 只包含引用的Run整个删除
+		if runTextLength(ref.node) == 0 && !containsAny(ref.node, objectElements) {
+			ref.parent.RemoveChild(ref.node)
+		}
+	}
+	root.RemoveChild(note)
+
+	doc.IsModified = true
+	log.Printf("已删除%s %s", kind, id)
+	return nil
+}
+
+// findNote 查找注释节点，返回注释和部件根节点
+func (doc *Document) findNote(kind NoteKind, id string) (*Node, *Node, error) {
+	root, err := doc.notesRoot(kind, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if root != nil {
+		for _, n := range root.ChildrenNamed(kind.spec().element) {
+			if n.Attr("w:id") == id && !isSeparatorNote(n) {
+				return n, root, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("未找到%s: %s", kind, id)
+}
+
+// nextNoteID 返回比部件中最大注释ID大一的新ID
+func nextNoteID(root *Node, element string) string {
+	next := 1
+	for _, n := range root.ChildrenNamed(element) {
+		if id, err := strconv.Atoi(n.Attr("w:id")); err == nil && id >= next {
+			next = id + 1
+		}
+	}
+	return strconv.Itoa(next)
+}
+
+// newNoteReferenceRun 创建正文中的注释引用Run，显示为上标编号
+func newNoteReferenceRun(spec noteSpec, id string) *Node {
+	r := NewNode("w:r")
+	r.AppendChild(newNoteMarkProperties(spec))
+	r.AppendChild(NewNode(spec.reference, "w:id", id))
+	return r
+}
+
+// newNoteMarkProperties 创建注释编号的字符格式，样式缺失时依靠上标显示
+func newNoteMarkProperties(spec noteSpec) *Node {
+	rPr := NewNode("w:rPr")
+	rPr.EnsureChild("w:rStyle", rPrOrder).SetAttr("w:val", spec.refStyle)
+	rPr.EnsureChild("w:vertAlign", rPrOrder).SetAttr("w:val", "superscript")
+	return rPr
+}
+
+// newNoteParagraphs 创建注释内容段落，第一个段落以注释编号开头
+func newNoteParagraphs(spec noteSpec, text string) []*Node {
+	var paragraphs []*Node
+	for i, line := range strings.Split(text, "\n") {
+		p := newParagraphNode(spec.textStyle)
+		if i == 0 {
+			mark := NewNode("w:r")
+			mark.AppendChild(newNoteMarkProperties(spec))
+			mark.AppendChild(NewNode(spec.mark))
+			p.AppendChild(mark)
+			line = " " + line
+		}
+		p.AppendChild(newRunNode(line, nil))
+		paragraphs = append(paragraphs, p)
+	}
+	return paragraphs
+}
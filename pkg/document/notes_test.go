@@ -0,0 +1,189 @@
+package document
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// describeNoteRuns 描述段落中的Run：注释引用显示为 ^ID，加粗的文字以 * 开头
+func describeNoteRuns(p *Node) []string {
+	var result []string
+	for _, r := range p.ChildrenNamed("w:r") {
+		if ref := r.Find("w:footnoteReference"); len(ref) > 0 {
+			result = append(result, "^"+ref[0].Attr("w:id"))
+			continue
+		}
+		var text string
+		for _, t := range r.ChildrenNamed("w:t") {
+			text += t.InnerText()
+		}
+		if rPr := r.Child("w:rPr"); rPr != nil && rPr.Child("w:b") != nil {
+			text = "*" + text
+		}
+		result = append(result, text)
+	}
+	return result
+}
+
+func TestInsertNote(t *testing.T) {
+	body := `<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>加粗文字</w:t></w:r><w:r><w:t>普通</w:t></w:r></w:p>`
+	tests := []struct {
+		name   string
+		offset int
+		want   []string
+	}{
+		{"拆分文字段", 2, []string{"*加粗", "^1", "*文字", "普通"}},
+		{"文字段之间", 4, []string{"*加粗文字", "^1", "普通"}},
+		{"段落开头", 0, []string{"^1", "*加粗文字", "普通"}},
+		{"负数插入到段落末尾", -1, []string{"*加粗文字", "普通", "^1"}},
+		{"超出长度插入到段落末尾", 100, []string{"*加粗文字", "普通", "^1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := newBodyTestDocument(t, body)
+			note, err := doc.InsertFootnote(0, tt.offset, "注释内容")
+			if err != nil {
+				t.Fatalf("插入脚注失败: %v", err)
+			}
+			if note.ID != "1" || note.Number != 1 || note.Paragraph != 0 {
+				t.Errorf("插入的脚注 %+v", note)
+			}
+			p := doc.paragraphNodes()[0]
+			if got := describeNoteRuns(p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("插入后的Run %q, 期望 %q", got, tt.want)
+			}
+			if got := paragraphNodeText(p); got != "加粗文字普通" {
+				t.Errorf("插入后的段落文字 %q", got)
+			}
+		})
+	}
+}
+
+func TestNotesNumbering(t *testing.T) {
+	doc := newTestDocument(t, "第一段", "第二段")
+	if _, err := doc.InsertFootnote(1, -1, "后插入"); err != nil {
+		t.Fatalf("插入脚注失败: %v", err)
+	}
+	first, err := doc.InsertFootnote(0, 1, "第一行\n第二行")
+	if err != nil {
+		t.Fatalf("插入脚注失败: %v", err)
+	}
+	if first.Number != 1 {
+		t.Errorf("插在前面的脚注编号 %d, 期望 1", first.Number)
+	}
+	if _, err := doc.InsertEndnote(1, 0, "尾注"); err != nil {
+		t.Fatalf("插入尾注失败: %v", err)
+	}
+
+	notes, err := doc.AllNotes()
+	if err != nil {
+		t.Fatalf("读取注释失败: %v", err)
+	}
+	want := []Note{
+		{Kind: FootnoteKind, ID: "2", Number: 1, Text: "第一行\n第二行", Paragraph: 0},
+		{Kind: FootnoteKind, ID: "1", Number: 2, Text: "后插入", Paragraph: 1},
+		{Kind: EndnoteKind, ID: "1", Number: 1, Text: "尾注", Paragraph: 1},
+	}
+	if !reflect.DeepEqual(notes, want) {
+		t.Errorf("注释 %+v, 期望 %+v", notes, want)
+	}
+	if got := doc.NotesByParagraph(); len(got[0]) != 1 || len(got[1]) != 2 {
+		t.Errorf("按段落分组的注释 %+v", got)
+	}
+	if got, want := noteLabel(true, 4), "[iv]"; got != want {
+		t.Errorf("尾注编号 %q, 期望 %q", got, want)
+	}
+}
+
+func TestNewNotesRoot(t *testing.T) {
+	doc := newTestDocument(t, "正文")
+	if _, err := doc.InsertFootnote(0, -1, "脚注"); err != nil {
+		t.Fatalf("插入脚注失败: %v", err)
+	}
+	root, err := doc.notesRoot(FootnoteKind, false)
+	if err != nil || root == nil {
+		t.Fatalf("没有创建脚注部件 (%v)", err)
+	}
+	notes := root.ChildrenNamed("w:footnote")
+	if len(notes) != 3 {
+		t.Fatalf("脚注部件中有 %d 个脚注, 期望 3 个", len(notes))
+	}
+	for i, want := range []struct{ id, kind, mark string }{
+		{"-1", "separator", "w:separator"},
+		{"0", "continuationSeparator", "w:continuationSeparator"},
+	} {
+		n := notes[i]
+		if n.Attr("w:id") != want.id || n.Attr("w:type") != want.kind || len(n.Find(want.mark)) != 1 {
+			t.Errorf("第 %d 个脚注 %s, 期望 %s 分隔符", i+1, n, want.kind)
+		}
+	}
+
+	// 分隔符登记在设置中
+	settings, err := doc.settingsRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, n := range settings.Find("w:footnotePr") {
+		for _, c := range n.ChildrenNamed("w:footnote") {
+			ids = append(ids, c.Attr("w:id"))
+		}
+	}
+	if !reflect.DeepEqual(ids, []string{"-1", "0"}) {
+		t.Errorf("设置中登记的分隔符 %q", ids)
+	}
+	if err := doc.DeleteNote(FootnoteKind, "0"); err == nil {
+		t.Errorf("删除分隔符没有返回错误")
+	}
+}
+
+func TestSetNoteText(t *testing.T) {
+	doc := newTestDocument(t, "正文")
+	note, err := doc.InsertFootnote(0, -1, "原来的内容")
+	if err != nil {
+		t.Fatalf("插入脚注失败: %v", err)
+	}
+	if err := doc.SetNoteText(FootnoteKind, note.ID, "新内容\n第二段"); err != nil {
+		t.Fatalf("修改脚注失败: %v", err)
+	}
+	notes, _ := doc.Notes(FootnoteKind)
+	if len(notes) != 1 || notes[0].Text != "新内容\n第二段" {
+		t.Fatalf("修改后的脚注 %+v", notes)
+	}
+	n, _, _ := doc.findNote(FootnoteKind, note.ID)
+	for _, p := range collectParagraphs(n) {
+		if paragraphStyle(p) != "FootnoteText" {
+			t.Errorf("修改后的段落没有保留脚注文字样式: %s", p)
+		}
+	}
+	if err := doc.SetNoteText(FootnoteKind, note.ID, " "); err == nil {
+		t.Errorf("将脚注内容改为空白没有返回错误")
+	}
+}
+
+func TestDeleteNote(t *testing.T) {
+	doc := newBodyTestDocument(t, `<w:p><w:r><w:t>前文</w:t></w:r><w:r><w:t>后文</w:t></w:r></w:p>`)
+	first, _ := doc.InsertFootnote(0, 2, "第一个")
+	second, _ := doc.InsertFootnote(0, -1, "第二个")
+	// 引用与文字位于同一个Run时只删除引用
+	p := doc.paragraphNodes()[0]
+	runs := p.ChildrenNamed("w:r")
+	runs[len(runs)-1].AppendChild(newRunNode("尾", nil).Child("w:t"))
+
+	if err := doc.DeleteNote(FootnoteKind, first.ID); err != nil {
+		t.Fatalf("删除脚注失败: %v", err)
+	}
+	if err := doc.DeleteNote(FootnoteKind, second.ID); err != nil {
+		t.Fatalf("删除脚注失败: %v", err)
+	}
+	if got, want := describeNoteRuns(p), []string{"前文", "后文", "尾"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("删除后的Run %q, 期望 %q", got, want)
+	}
+	if notes, _ := doc.Notes(FootnoteKind); len(notes) != 0 {
+		t.Errorf("删除后仍有脚注 %+v", notes)
+	}
+	if err := doc.DeleteNote(FootnoteKind, first.ID); err == nil || !strings.Contains(err.Error(), "未找到脚注") {
+		t.Errorf("删除不存在的脚注返回 %v", err)
+	}
+}
@@ -0,0 +1,702 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 编号相关的部件类型
+const (
+	relTypeNumbering     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering"
+	contentTypeNumbering = "application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"
+)
+
+// maxListLevel 列表的最大级别数
+const maxListLevel = 9
+
+// listIndent 新建列表每一级的缩进，单位为缇（约两个五号字）
+const listIndent = 420
+
+// levelPlaceholder 级别文本中引用各级编号的占位符，如 %1
+var levelPlaceholder = regexp.MustCompile(`%([1-9])`)
+
+// ListKind 列表类型
+type ListKind int
+
+const (
+	BulletList   ListKind = iota // 项目符号
+	NumberedList                 // 编号
+)
+
+// String 返回列表类型的中文名称
+func (k ListKind) String() string {
+	if k == NumberedList {
+		return "编号"
+	}
+	return "项目符号"
+}
+
+// ListItem 段落的列表信息
+type ListItem struct {
+	NumID string   // 编号实例ID
+	Level int      // 列表级别，0为第一级
+	Kind  ListKind // 项目符号或编号
+	Label string   // 计算后的编号文本，如 "1."、"a)"、"•"、"第一章"
+}
+
+// numberingLevel 编号定义中的一个级别
+type numberingLevel struct {
+	start   int
+	format  string // w:numFmt
+	text    string // w:lvlText
+	restart int    // w:lvlRestart，-1表示任何更高级别出现后重新开始
+	legal   bool   // w:isLgl，引用的编号都使用阿拉伯数字
+	pPr     *Node  // 级别的段落属性，主要是列表缩进
+}
+
+// numberingInstance 编号实例，引用抽象编号定义并可以覆盖起始值
+type numberingInstance struct {
+	abstract  string
+	overrides map[int]int            // 级别到起始值的覆盖
+	levels    map[int]numberingLevel // 级别定义的覆盖
+}
+
+// numberingDefinitions numbering.xml 中的编号定义
+type numberingDefinitions struct {
+	abstracts map[string]map[int]numberingLevel
+	instances map[string]numberingInstance
+}
+
+// level 返回编号实例指定级别的定义
+func (d *numberingDefinitions) level(numID string, ilvl int) (numberingLevel, bool) {
+	inst, ok := d.instances[numID]
+	if !ok {
+		return numberingLevel{}, false
+	}
+	if lvl, ok := inst.levels[ilvl]; ok {
+		return lvl, true
+	}
+	lvl, ok := d.abstracts[inst.abstract][ilvl]
+	return lvl, ok
+}
+
+// numberingRoot 返回编号部件的根节点，create 为真时在部件不存在时创建
+func (doc *Document) numberingRoot(create bool) (*Node, error) {
+	if doc.Package == nil {
+		return nil, fmt.Errorf("文档不支持列表")
+	}
+	if !create {
+		part, ok := doc.Package.RelatedPart(doc.Package.MainPartName(), relTypeNumbering)
+		if !ok || !doc.Package.HasPart(part) {
+			return nil, nil
+		}
+		return doc.Package.XML(part)
+	}
+	_, root, err := doc.Package.ensureRelatedPart(relTypeNumbering, "word/numbering.xml", contentTypeNumbering,
+		func() *Node {
+			return NewNode("w:numbering", "xmlns:w", nsWordML)
+		})
+	return root, err
+}
+
+// parseNumbering 解析编号部件中的抽象编号和编号实例
+func parseNumbering(root *Node) *numberingDefinitions {
+	defs := &numberingDefinitions{
+		abstracts: make(map[string]map[int]numberingLevel),
+		instances: make(map[string]numberingInstance),
+	}
+	if root == nil {
+		return defs
+	}
+	for _, abstract := range root.ChildrenNamed("w:abstractNum") {
+		levels := make(map[int]numberingLevel)
+		for _, lvl := range abstract.ChildrenNamed("w:lvl") {
+			if ilvl, err := strconv.Atoi(lvl.Attr("w:ilvl")); err == nil {
+				levels[ilvl] = parseNumberingLevel(lvl)
+			}
+		}
+		defs.abstracts[abstract.Attr("w:abstractNumId")] = levels
+	}
+	for _, num := range root.ChildrenNamed("w:num") {
+		inst := numberingInstance{
+			overrides: make(map[int]int),
+			levels:    make(map[int]numberingLevel),
+		}
+		if abstract := num.Child("w:abstractNumId"); abstract != nil {
+			inst.abstract = abstract.Attr("w:val")
+		}
+		for _, override := range num.ChildrenNamed("w:lvlOverride") {
+			ilvl, err := strconv.Atoi(override.Attr("w:ilvl"))
+			if err != nil {
+				continue
+			}
+			if start := override.Child("w:startOverride"); start != nil {
+				if value, err := strconv.Atoi(start.Attr("w:val")); err == nil {
+					inst.overrides[ilvl] = value
+				}
+			}
+			if lvl := override.Child("w:lvl"); lvl != nil {
+				inst.levels[ilvl] = parseNumberingLevel(lvl)
+			}
+		}
+		defs.instances[num.Attr("w:numId")] = inst
+	}
+	return defs
+}
+
+// parseNumberingLevel 解析 w:lvl 元素
+func parseNumberingLevel(lvl *Node) numberingLevel {
+	level := numberingLevel{start: 1, format: "decimal", restart: -1}
+	if start := lvl.Child("w:start"); start != nil {
+		if value, err := strconv.Atoi(start.Attr("w:val")); err == nil {
+			level.start = value
+		}
+	}
+	if format := lvl.Child("w:numFmt"); format != nil {
+		level.format = format.Attr("w:val")
+	}
+	if text := lvl.Child("w:lvlText"); text != nil {
+		level.text = text.Attr("w:val")
+	}
+	if restart := lvl.Child("w:lvlRestart"); restart != nil {
+		if value, err := strconv.Atoi(restart.Attr("w:val")); err == nil {
+			level.restart = value
+		}
+	}
+	if legal := lvl.Child("w:isLgl"); legal != nil {
+		level.legal = onOffValue(legal)
+	}
+	level.pPr = lvl.Child("w:pPr")
+	return level
+}
+
+// paragraphNumbering 返回段落的编号实例和级别，段落直接设置的编号优先于样式中的编号
+func paragraphNumbering(p *Node, styleNumbering map[string]ListItem) (string, int) {
+	numID, ilvl := "", 0
+	if item, ok := styleNumbering[paragraphStyle(p)]; ok {
+		numID, ilvl = item.NumID, item.Level
+	}
+	if pPr := p.Child("w:pPr"); pPr != nil {
+		if numPr := pPr.Child("w:numPr"); numPr != nil {
+			if id := numPr.Child("w:numId"); id != nil {
+				numID = id.Attr("w:val")
+			}
+			if lvl := numPr.Child("w:ilvl"); lvl != nil {
+				if value, err := strconv.Atoi(lvl.Attr("w:val")); err == nil {
+					ilvl = value
+				}
+			}
+		}
+	}
+	if numID == "0" {
+		numID = ""
+	}
+	return numID, ilvl
+}
+
+// styleNumbering 解析段落样式中设置的编号，沿 basedOn 继承
+func (doc *Document) styleNumbering() map[string]ListItem {
+	result := make(map[string]ListItem)
+	part, ok := doc.Package.RelatedPart(doc.Package.MainPartName(), relTypeStyles)
+	if !ok {
+		return result
+	}
+	root, err := doc.Package.XML(part)
+	if err != nil {
+		return result
+	}
+
+	styles := make(map[string]*Node)
+	for _, s := range root.ChildrenNamed("w:style") {
+		if s.Attr("w:type") == "paragraph" {
+			styles[s.Attr("w:styleId")] = s
+		}
+	}
+	for id := range styles {
+		item, found := ListItem{}, false
+		current := id
+		for depth := 0; current != "" && depth < 20 && !found; depth++ {
+			s, ok := styles[current]
+			if !ok {
+				break
+			}
+			if pPr := s.Child("w:pPr"); pPr != nil {
+				if numPr := pPr.Child("w:numPr"); numPr != nil {
+					if numID := numPr.Child("w:numId"); numID != nil {
+						item.NumID = numID.Attr("w:val")
+						found = true
+					}
+					if lvl := numPr.Child("w:ilvl"); lvl != nil {
+						item.Level, _ = strconv.Atoi(lvl.Attr("w:val"))
+					}
+				}
+			}
+			current = ""
+			if basedOn := s.Child("w:basedOn"); basedOn != nil {
+				current = basedOn.Attr("w:val")
+			}
+		}
+		if found {
+			result[id] = item
+		}
+	}
+	return result
+}
+
+// ListItems 返回每个正文段落的列表信息，顺序与段落索引一致，不在列表中的段落 NumID 为空
+//
+// 编号按段落在正文中的顺序计算：共用同一抽象编号的实例连续编号，
+// 设置了起始值覆盖的实例在第一次出现时重新开始。
+func (doc *Document) ListItems() ([]ListItem, error) {
+	body, err := doc.body()
+	if err != nil {
+		return nil, err
+	}
+	root, err := doc.numberingRoot(false)
+	if err != nil {
+		return nil, err
+	}
+	defs := parseNumbering(root)
+	styleNumbering := doc.styleNumbering()
+
+	counters := make(map[string][]int) // 抽象编号ID到各级当前编号
+	started := make(map[string]bool)   // 已应用起始值覆盖的编号实例
+	paragraphs := collectParagraphs(body)
+	items := make([]ListItem, len(paragraphs))
+	for i, p := range paragraphs {
+		numID, ilvl := paragraphNumbering(p, styleNumbering)
+		if numID == "" || ilvl < 0 || ilvl >= maxListLevel {
+			continue
+		}
+		level, ok := defs.level(numID, ilvl)
+		if !ok {
+			continue
+		}
+		inst := defs.instances[numID]
+		key := inst.abstract
+		if key == "" {
+			key = "num" + numID
+		}
+
+		values, ok := counters[key]
+		if !ok {
+			values = make([]int, maxListLevel)
+			for l := range values {
+				values[l] = -1
+			}
+		}
+		if !started[numID] {
+			started[numID] = true
+			for l, start := range inst.overrides {
+				if l >= 0 && l < maxListLevel {
+					values[l] = start - 1
+				}
+			}
+		}
+
+		// 递增当前级别，重置更低的级别
+		if values[ilvl] < 0 {
+			values[ilvl] = level.start - 1
+		}
+		values[ilvl]++
+		for l := ilvl + 1; l < maxListLevel; l++ {
+			lower, _ := defs.level(numID, l)
+			if lower.restart == 0 {
+				continue
+			}
+			if lower.restart < 0 || lower.restart > ilvl {
+				values[l] = -1
+			}
+		}
+		counters[key] = values
+
+		items[i] = ListItem{
+			NumID: numID,
+			Level: ilvl,
+			Kind:  NumberedList,
+			Label: numberingLabel(defs, numID, level, values),
+		}
+		if level.format == "bullet" {
+			items[i].Kind = BulletList
+		}
+	}
+	return items, nil
+}
+
+// ListLabels 返回每个正文段落的编号文本，不在列表中的段落为空字符串
+func (doc *Document) ListLabels() []string {
+	items, err := doc.ListItems()
+	if err != nil {
+		return nil
+	}
+	labels := make([]string, len(items))
+	for i, item := range items {
+		labels[i] = item.Label
+	}
+	return labels
+}
+
+// numberingLabel 按级别文本和各级编号计算编号文本
+func numberingLabel(defs *numberingDefinitions, numID string, level numberingLevel, values []int) string {
+	if level.format == "bullet" {
+		return bulletSymbol(level.text)
+	}
+	return levelPlaceholder.ReplaceAllStringFunc(level.text, func(m string) string {
+		l := int(m[1] - '1')
+		value := values[l]
+		if value < 0 {
+			// 更高级别尚未出现时使用其起始值
+			ref, _ := defs.level(numID, l)
+			value = ref.start
+		}
+		ref, ok := defs.level(numID, l)
+		format := ref.format
+		if !ok {
+			format = "decimal"
+		}
+		if level.legal {
+			format = "decimal"
+		}
+		return formatNumber(value, format)
+	})
+}
+
+// bulletSymbol 将项目符号中Symbol、Wingdings字体的私用区字符转换为常见符号
+func bulletSymbol(text string) string {
+	var builder strings.Builder
+	for _, r := range text {
+		switch r {
+		case '\uf0b7', '\uf06c':
+			builder.WriteRune('•')
+		case '\uf0a7', '\uf06e':
+			builder.WriteRune('▪')
+		case '\uf0d8':
+			builder.WriteRune('➢')
+		case '\uf0fc':
+			builder.WriteRune('✓')
+		case '\uf076':
+			builder.WriteRune('❖')
+		default:
+			if r >= '\uf000' && r <= '\uf0ff' {
+				builder.WriteRune('•')
+			} else {
+				builder.WriteRune(r)
+			}
+		}
+	}
+	if builder.Len() == 0 {
+		return "•"
+	}
+	return builder.String()
+}
+
+// formatNumber 按 w:numFmt 格式化编号
+func formatNumber(n int, format string) string {
+	switch format {
+	case "none":
+		return ""
+	case "decimalZero":
+		return fmt.Sprintf("%02d", n)
+	case "upperRoman":
+		return strings.ToUpper(romanNumeral(n))
+	case "lowerRoman":
+		return romanNumeral(n)
+	case "upperLetter":
+		return letterNumber(n, 'A')
+	case "lowerLetter":
+		return letterNumber(n, 'a')
+	case "decimalEnclosedCircle", "decimalEnclosedCircleChinese":
+		if n >= 1 && n <= 20 {
+			return string(rune('①' + n - 1))
+		}
+	case "chineseCounting", "chineseCountingThousand", "taiwaneseCounting", "taiwaneseCountingThousand",
+		"japaneseCounting", "ideographDigital":
+		return chineseNumber(n, format == "ideographDigital")
+	case "chineseLegalSimplified":
+		return chineseLegalNumber(n)
+	case "ideographTraditional":
+		if n >= 1 && n <= 10 {
+			return string([]rune("甲乙丙丁戊己庚辛壬癸")[n-1])
+		}
+	case "ideographZodiac":
+		if n >= 1 && n <= 12 {
+			return string([]rune("子丑寅卯辰巳午未申酉戌亥")[n-1])
+		}
+	}
+	return strconv.Itoa(n)
+}
+
+// letterNumber 返回字母编号，超过26后重复字母，与Word一致（aa、bb）
+func letterNumber(n int, base rune) string {
+	if n <= 0 || n > 26*20 {
+		return strconv.Itoa(n)
+	}
+	letter := string(base + rune((n-1)%26))
+	return strings.Repeat(letter, (n-1)/26+1)
+}
+
+// chineseNumber 返回中文小写数字，digital 为真时逐位转换（如 一〇），否则按计数法转换（如 一百零五）
+func chineseNumber(n int, digital bool) string {
+	if n < 0 {
+		return strconv.Itoa(n)
+	}
+	if digital {
+		digits := []rune("〇一二三四五六七八九")
+		var builder strings.Builder
+		for _, d := range strconv.Itoa(n) {
+			builder.WriteRune(digits[d-'0'])
+		}
+		return builder.String()
+	}
+	return chineseCounting(n, []rune("零一二三四五六七八九"), []string{"", "十", "百", "千"}, "万")
+}
+
+// chineseLegalNumber 返回中文大写数字
+func chineseLegalNumber(n int) string {
+	if n < 0 {
+		return strconv.Itoa(n)
+	}
+	return chineseCounting(n, []rune("零壹贰叁肆伍陆柒捌玖"), []string{"", "拾", "佰", "仟"}, "万")
+}
+
+// chineseCounting 按中文计数法转换一万亿以内的数字
+func chineseCounting(n int, digits []rune, units []string, wan string) string {
+	if n == 0 {
+		return string(digits[0])
+	}
+	if n >= 10000 {
+		high := chineseCounting(n/10000, digits, units, wan) + wan
+		low := n % 10000
+		if low == 0 {
+			return high
+		}
+		if low < 1000 {
+			return high + string(digits[0]) + chineseCounting(low, digits, units, wan)
+		}
+		return high + chineseCounting(low, digits, units, wan)
+	}
+
+	var builder strings.Builder
+	s := strconv.Itoa(n)
+	zero := false
+	for i, d := range s {
+		unit := len(s) - i - 1
+		if d == '0' {
+			zero = true
+			continue
+		}
+		if zero {
+			builder.WriteRune(digits[0])
+			zero = false
+		}
+		// 十到十九省略“一”
+		if !(d == '1' && unit == 1 && i == 0) || units[1] != "十" {
+			builder.WriteRune(digits[d-'0'])
+		}
+		builder.WriteString(units[unit])
+	}
+	return builder.String()
+}
+
+// ToggleList 为 start 到 end（包含）的段落切换项目符号或编号
+//
+// 所有段落都已是该类型的列表时取消列表，否则应用列表。紧接在同类型列表之后的段落
+// 延续前面的列表，否则新建一个列表。
+func (doc *Document) ToggleList(kind ListKind, start, end int) error {
+	paragraphs := doc.paragraphNodes()
+	if start < 0 || end >= len(paragraphs) || start > end {
+		return fmt.Errorf("段落范围无效: %d-%d", start+1, end+1)
+	}
+	items, err := doc.ListItems()
+	if err != nil {
+		return err
+	}
+
+	all := true
+	for i := start; i <= end; i++ {
+		if items[i].NumID == "" || items[i].Kind != kind {
+			all = false
+		}
+	}
+	if all {
+		styleNumbering := doc.styleNumbering()
+		for i := start; i <= end; i++ {
+			if _, ok := styleNumbering[paragraphStyle(paragraphs[i])]; ok {
+				// 样式中的编号需要用 numId 0 覆盖
+				setParagraphNumbering(paragraphs[i], "0", -1)
+			} else if pPr := paragraphs[i].Child("w:pPr"); pPr != nil {
+				pPr.RemoveChildrenNamed("w:numPr")
+			}
+		}
+		doc.IsModified = true
+		log.Printf("已取消段落 %d-%d 的%s", start+1, end+1, kind)
+		return nil
+	}
+
+	numID := ""
+	if start > 0 && items[start-1].NumID != "" && items[start-1].Kind == kind {
+		numID = items[start-1].NumID
+	} else if numID, err = doc.addNumbering(kind); err != nil {
+		return err
+	}
+	for i := start; i <= end; i++ {
+		level := 0
+		if items[i].NumID != "" {
+			level = items[i].Level
+		}
+		setParagraphNumbering(paragraphs[i], numID, level)
+	}
+	doc.IsModified = true
+	log.Printf("已为段落 %d-%d 应用%s", start+1, end+1, kind)
+	return nil
+}
+
+// SetListLevel 设置列表段落的级别，level 从0开始
+func (doc *Document) SetListLevel(paragraph, level int) error {
+	if level < 0 || level >= maxListLevel {
+		return fmt.Errorf("无效的列表级别: %d", level+1)
+	}
+	paragraphs := doc.paragraphNodes()
+	if paragraph < 0 || paragraph >= len(paragraphs) {
+		return fmt.Errorf("段落索引超出范围: %d", paragraph+1)
+	}
+	items, err := doc.ListItems()
+	if err != nil {
+		return err
+	}
+	if items[paragraph].NumID == "" {
+		return fmt.Errorf("段落 %d 不在列表中", paragraph+1)
+	}
+	setParagraphNumbering(paragraphs[paragraph], items[paragraph].NumID, level)
+	doc.IsModified = true
+	log.Printf("已将段落 %d 的列表级别设置为 %d", paragraph+1, level+1)
+	return nil
+}
+
+// RestartNumbering 从段落开始重新编号，段落所在列表中其后的段落随之延续新的编号
+func (doc *Document) RestartNumbering(paragraph int) error {
+	paragraphs := doc.paragraphNodes()
+	if paragraph < 0 || paragraph >= len(paragraphs) {
+		return fmt.Errorf("段落索引超出范围: %d", paragraph+1)
+	}
+	items, err := doc.ListItems()
+	if err != nil {
+		return err
+	}
+	item := items[paragraph]
+	if item.NumID == "" {
+		return fmt.Errorf("段落 %d 不在列表中", paragraph+1)
+	}
+
+	root, err := doc.numberingRoot(false)
+	if err != nil {
+		return err
+	}
+	defs := parseNumbering(root)
+	inst := defs.instances[item.NumID]
+	level, _ := defs.level(item.NumID, item.Level)
+
+	numID := nextNumberingID(root, "w:num", "w:numId")
+	num := NewNode("w:num", "w:numId", numID)
+	num.AppendChild(NewNode("w:abstractNumId", "w:val", inst.abstract))
+	override := NewNode("w:lvlOverride", "w:ilvl", strconv.Itoa(item.Level))
+	override.AppendChild(NewNode("w:startOverride", "w:val", strconv.Itoa(level.start)))
+	num.AppendChild(override)
+	insertNumberingInstance(root, num)
+
+	for i := paragraph; i < len(paragraphs); i++ {
+		if items[i].NumID == item.NumID {
+			setParagraphNumbering(paragraphs[i], numID, items[i].Level)
+		}
+	}
+	doc.IsModified = true
+	log.Printf("已从段落 %d 重新编号", paragraph+1)
+	return nil
+}
+
+// setParagraphNumbering 设置段落的编号，level 为负数时只设置编号实例（numId 为0表示取消编号）
+func setParagraphNumbering(p *Node, numID string, level int) {
+	pPr := paragraphProperties(p)
+	pPr.RemoveChildrenNamed("w:numPr")
+	numPr := NewNode("w:numPr")
+	if level >= 0 {
+		numPr.AppendChild(NewNode("w:ilvl", "w:val", strconv.Itoa(level)))
+	}
+	numPr.AppendChild(NewNode("w:numId", "w:val", numID))
+	pPr.InsertOrdered(numPr, pPrOrder)
+}
+
+// addNumbering 添加一个新的项目符号或编号列表定义，返回编号实例ID
+func (doc *Document) addNumbering(kind ListKind) (string, error) {
+	root, err := doc.numberingRoot(true)
+	if err != nil {
+		return "", err
+	}
+	abstractID := nextNumberingID(root, "w:abstractNum", "w:abstractNumId")
+	abstract := NewNode("w:abstractNum", "w:abstractNumId", abstractID)
+	abstract.AppendChild(NewNode("w:multiLevelType", "w:val", "hybridMultilevel"))
+
+	bullets := []string{"•", "◦", "▪"}
+	formats := []struct{ format, text string }{
+		{"decimal", "%%%d."}, {"lowerLetter", "%%%d)"}, {"lowerRoman", "%%%d."},
+	}
+	for l := 0; l < maxListLevel; l++ {
+		lvl := NewNode("w:lvl", "w:ilvl", strconv.Itoa(l))
+		lvl.AppendChild(NewNode("w:start", "w:val", "1"))
+		if kind == BulletList {
+			lvl.AppendChild(NewNode("w:numFmt", "w:val", "bullet"))
+			lvl.AppendChild(NewNode("w:lvlText", "w:val", bullets[l%len(bullets)]))
+		} else {
+			f := formats[l%len(formats)]
+			lvl.AppendChild(NewNode("w:numFmt", "w:val", f.format))
+			lvl.AppendChild(NewNode("w:lvlText", "w:val", fmt.Sprintf(f.text, l+1)))
+		}
+		lvl.AppendChild(NewNode("w:lvlJc", "w:val", "left"))
+		pPr := NewNode("w:pPr")
+		pPr.AppendChild(NewNode("w:ind", "w:left", strconv.Itoa(listIndent*(l+1)), "w:hanging", strconv.Itoa(listIndent)))
+		lvl.AppendChild(pPr)
+		abstract.AppendChild(lvl)
+	}
+
+	// 抽象编号必须位于所有编号实例之前
+	position := len(root.Children)
+	for i, c := range root.Children {
+		if c.Name == "w:num" || c.Name == "w:numIdMacAtCleanup" {
+			position = i
+			break
+		}
+	}
+	root.InsertChild(position, abstract)
+
+	numID := nextNumberingID(root, "w:num", "w:numId")
+	num := NewNode("w:num", "w:numId", numID)
+	num.AppendChild(NewNode("w:abstractNumId", "w:val", abstractID))
+	insertNumberingInstance(root, num)
+	return numID, nil
+}
+
+// insertNumberingInstance 添加编号实例，w:numIdMacAtCleanup 必须位于所有编号实例之后
+func insertNumberingInstance(root, num *Node) {
+	position := len(root.Children)
+	if cleanup := root.Child("w:numIdMacAtCleanup"); cleanup != nil {
+		position = root.IndexOf(cleanup)
+	}
+	root.InsertChild(position, num)
+}
+
+// nextNumberingID 返回编号部件中指定元素未使用的ID，编号实例ID从1开始
+func nextNumberingID(root *Node, element, attr string) string {
+	next := 0
+	if element == "w:num" {
+		next = 1
+	}
+	for _, n := range root.ChildrenNamed(element) {
+		if id, err := strconv.Atoi(n.Attr(attr)); err == nil && id >= next {
+			next = id + 1
+		}
+	}
+	return strconv.Itoa(next)
+}
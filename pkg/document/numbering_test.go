@@ -0,0 +1,168 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		n      int
+		format string
+		want   string
+	}{
+		{3, "decimal", "3"},
+		{7, "decimalZero", "07"},
+		{12, "decimalZero", "12"},
+		{4, "upperRoman", "IV"},
+		{1994, "lowerRoman", "mcmxciv"},
+		{1, "lowerLetter", "a"},
+		{26, "upperLetter", "Z"},
+		{27, "lowerLetter", "aa"},
+		{54, "upperLetter", "BBB"},
+		{3, "decimalEnclosedCircle", "③"},
+		{21, "decimalEnclosedCircle", "21"},
+		{1, "chineseCounting", "一"},
+		{10, "chineseCounting", "十"},
+		{15, "chineseCountingThousand", "十五"},
+		{20, "chineseCounting", "二十"},
+		{105, "chineseCounting", "一百零五"},
+		{1010, "chineseCounting", "一千零一十"},
+		{20003, "chineseCounting", "二万零三"},
+		{10, "ideographDigital", "一〇"},
+		{11, "chineseLegalSimplified", "壹拾壹"},
+		{3, "ideographTraditional", "丙"},
+		{12, "ideographZodiac", "亥"},
+		{13, "ideographZodiac", "13"},
+		{5, "none", ""},
+		{5, "unknownFormat", "5"},
+	}
+	for _, tt := range tests {
+		if got := formatNumber(tt.n, tt.format); got != tt.want {
+			t.Errorf("formatNumber(%d, %q) = %q, 期望 %q", tt.n, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestBulletSymbol(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"", "•"},
+		{"", "▪"},
+		{"", "➢"},
+		{"", "✓"},
+		{"", "•"},
+		{"o", "o"},
+		{"–", "–"},
+		{"", "•"},
+	}
+	for _, tt := range tests {
+		if got := bulletSymbol(tt.text); got != tt.want {
+			t.Errorf("bulletSymbol(%q) = %q, 期望 %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// listParagraph 返回设置了编号的段落XML，numID 为空时为普通段落
+func listParagraph(numID string, level int) string {
+	if numID == "" {
+		return `<w:p><w:r><w:t>正文</w:t></w:r></w:p>`
+	}
+	return `<w:p><w:pPr><w:numPr><w:ilvl w:val="` + string(rune('0'+level)) + `"/><w:numId w:val="` + numID +
+		`"/></w:numPr></w:pPr><w:r><w:t>列表</w:t></w:r></w:p>`
+}
+
+func TestListLabels(t *testing.T) {
+	type item struct {
+		numID string
+		level int
+	}
+	tests := []struct {
+		name  string
+		items []item
+		want  []string
+	}{
+		{"连续编号", []item{{"1", 0}, {"1", 0}, {"1", 0}}, []string{"1.", "2.", "3."}},
+		{"普通段落不中断编号", []item{{"1", 0}, {"", 0}, {"1", 0}}, []string{"1.", "", "2."}},
+		{"多级编号", []item{{"1", 0}, {"1", 1}, {"1", 1}, {"1", 0}, {"1", 1}},
+			[]string{"1.", "1.a)", "1.b)", "2.", "2.a)"}},
+		{"上级未出现时使用起始值", []item{{"1", 1}}, []string{"1.a)"}},
+		{"项目符号", []item{{"2", 0}, {"2", 0}}, []string{"•", "•"}},
+		{"中文章节和正规编号", []item{{"3", 0}, {"3", 1}, {"3", 0}, {"3", 1}},
+			[]string{"第一章", "1.1", "第二章", "2.1"}},
+		{"起始值覆盖", []item{{"1", 0}, {"1", 0}, {"4", 0}, {"4", 0}}, []string{"1.", "2.", "5.", "6."}},
+		{"未定义的编号实例", []item{{"9", 0}, {"1", 0}}, []string{"", "1."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body string
+			for _, it := range tt.items {
+				body += listParagraph(it.numID, it.level)
+			}
+			doc := newBodyTestDocument(t, body)
+			root, err := doc.numberingRoot(true)
+			if err != nil {
+				t.Fatalf("创建编号部件失败: %v", err)
+			}
+			defs, err := ParseXML([]byte("<w:numbering>" + testNumbering + "</w:numbering>"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			root.AppendChild(defs.Elements()...)
+
+			if got := doc.ListLabels(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListLabels() = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToggleListLabels(t *testing.T) {
+	doc := newTestDocument(t, "第一项", "第二项", "第三项")
+	if err := doc.ToggleList(NumberedList, 0, 2); err != nil {
+		t.Fatalf("应用编号失败: %v", err)
+	}
+	if got, want := doc.ListLabels(), []string{"1.", "2.", "3."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("应用编号后 %q, 期望 %q", got, want)
+	}
+	if err := doc.SetListLevel(1, 1); err != nil {
+		t.Fatalf("设置列表级别失败: %v", err)
+	}
+	if got := doc.ListLabels(); got[2] != "2." {
+		t.Errorf("第二项降级后第三项编号 %q, 期望 %q", got[2], "2.")
+	}
+	if err := doc.ToggleList(NumberedList, 0, 2); err != nil {
+		t.Fatalf("取消编号失败: %v", err)
+	}
+	if got, want := doc.ListLabels(), []string{"", "", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("取消编号后 %q, 期望 %q", got, want)
+	}
+}
+
+func TestRestartNumbering(t *testing.T) {
+	doc := newBodyTestDocument(t, listParagraph("1", 0)+listParagraph("1", 0)+listParagraph("1", 0))
+	root, err := doc.numberingRoot(true)
+	if err != nil {
+		t.Fatalf("创建编号部件失败: %v", err)
+	}
+	defs, err := ParseXML([]byte("<w:numbering>" + testNumbering + `<w:numIdMacAtCleanup w:val="4"/></w:numbering>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.AppendChild(defs.Elements()...)
+
+	if err := doc.RestartNumbering(1); err != nil {
+		t.Fatalf("重新编号失败: %v", err)
+	}
+	if got, want := doc.ListLabels(), []string{"1.", "1.", "2."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("重新编号后 %q, 期望 %q", got, want)
+	}
+	elements := root.Elements()
+	if last := elements[len(elements)-1]; last.Name != "w:numIdMacAtCleanup" {
+		t.Errorf("编号部件的最后一个元素为 %s, 期望 w:numIdMacAtCleanup", last.Name)
+	}
+	if added := elements[len(elements)-2]; added.Name != "w:num" || added.Attr("w:numId") != "5" {
+		t.Errorf("新的编号实例 %s 没有位于 w:numIdMacAtCleanup 之前", added)
+	}
+}
@@ -0,0 +1,279 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+)
+
+// headingLevel 返回段落的标题级别（1为一级标题），正文返回0
+func headingLevel(p *Node, styleLevels map[string]int) int {
+	level := paragraphOutlineLevel(p, styleLevels)
+	if level < 0 || level >= bodyTextLevel {
+		return 0
+	}
+	return level + 1
+}
+
+// HeadingLevel 返回段落的标题级别（1为一级标题），正文返回0
+func (doc *Document) HeadingLevel(paragraph int) (int, error) {
+	paragraphs := doc.paragraphNodes()
+	if paragraph < 0 || paragraph >= len(paragraphs) {
+		return 0, fmt.Errorf("段落索引超出范围: %d", paragraph+1)
+	}
+	return headingLevel(paragraphs[paragraph], doc.styleOutlineLevels()), nil
+}
+
+// SetHeadingLevel 设置段落的标题级别，level 为0时改为正文
+//
+// 段落直接设置了大纲级别时只修改大纲级别，否则改用对应级别的标题样式，
+// 样式部件中没有该样式时自动添加。
+func (doc *Document) SetHeadingLevel(paragraph, level int) error {
+	if level < 0 || level > bodyTextLevel {
+		return fmt.Errorf("无效的标题级别: %d", level)
+	}
+	paragraphs := doc.paragraphNodes()
+	if paragraph < 0 || paragraph >= len(paragraphs) {
+		return fmt.Errorf("段落索引超出范围: %d", paragraph+1)
+	}
+	p := paragraphs[paragraph]
+	styleLevels := doc.styleOutlineLevels()
+
+	if pPr := p.Child("w:pPr"); pPr != nil && outlineValue(pPr) >= 0 {
+		value := bodyTextLevel
+		if level > 0 {
+			value = level - 1
+		}
+		pPr.Child("w:outlineLvl").SetAttr("w:val", strconv.Itoa(value))
+	} else if level == 0 {
+		if pPr != nil && headingLevel(p, styleLevels) > 0 {
+			pPr.RemoveChildrenNamed("w:pStyle")
+		}
+	} else {
+		style, err := doc.headingStyle(level, styleLevels)
+		if err != nil {
+			return err
+		}
+		paragraphProperties(p).EnsureChild("w:pStyle", pPrOrder).SetAttr("w:val", style)
+	}
+
+	doc.IsModified = true
+	log.Printf("已将段落 %d 设置为 %d 级标题", paragraph+1, level)
+	return nil
+}
+
+// PromoteHeading 将标题提升一级
+func (doc *Document) PromoteHeading(paragraph int) error {
+	level, err := doc.HeadingLevel(paragraph)
+	if err != nil {
+		return err
+	}
+	switch level {
+	case 0:
+		return fmt.Errorf("段落 %d 不是标题", paragraph+1)
+	case 1:
+		return fmt.Errorf("段落 %d 已经是一级标题", paragraph+1)
+	}
+	return doc.SetHeadingLevel(paragraph, level-1)
+}
+
+// DemoteHeading 将标题降低一级
+func (doc *Document) DemoteHeading(paragraph int) error {
+	level, err := doc.HeadingLevel(paragraph)
+	if err != nil {
+		return err
+	}
+	switch level {
+	case 0:
+		return fmt.Errorf("段落 %d 不是标题", paragraph+1)
+	case bodyTextLevel:
+		return fmt.Errorf("段落 %d 已经是最低级别的标题", paragraph+1)
+	}
+	return doc.SetHeadingLevel(paragraph, level+1)
+}
+
+// headingStyle 返回指定级别标题使用的样式ID，样式部件中没有时添加内置标题样式
+func (doc *Document) headingStyle(level int, styleLevels map[string]int) (string, error) {
+	id := fmt.Sprintf("Heading%d", level)
+	if styleLevel, ok := styleLevels[id]; ok && styleLevel == level-1 {
+		return id, nil
+	}
+	styles := doc.paragraphStyles()
+	for styleID, styleLevel := range styleLevels {
+		if styleLevel == level-1 && headingNamePattern.MatchString(styles[styleID].name) {
+			return styleID, nil
+		}
+	}
+
+	part, ok := doc.Package.RelatedPart(doc.Package.MainPartName(), relTypeStyles)
+	if !ok {
+		// 没有样式部件时样式ID仍可识别为标题
+		return id, nil
+	}
+	root, err := doc.Package.XML(part)
+	if err != nil {
+		return "", err
+	}
+	style := NewNode("w:style", "w:type", "paragraph", "w:styleId", id)
+	style.AppendChild(NewNode("w:name", "w:val", fmt.Sprintf("heading %d", level)))
+	style.AppendChild(NewNode("w:basedOn", "w:val", "Normal"))
+	style.AppendChild(NewNode("w:next", "w:val", "Normal"))
+	style.AppendChild(NewNode("w:qFormat"))
+	pPr := NewNode("w:pPr")
+	pPr.AppendChild(NewNode("w:keepNext"), NewNode("w:keepLines"))
+	pPr.AppendChild(NewNode("w:outlineLvl", "w:val", strconv.Itoa(level-1)))
+	style.AppendChild(pPr)
+	rPr := NewNode("w:rPr")
+	rPr.AppendChild(NewNode("w:b"))
+	style.AppendChild(rPr)
+	root.AppendChild(style)
+	log.Printf("已添加标题样式: %s", id)
+	return id, nil
+}
+
+// SectionRange 返回标题所在章节的段落范围 [start, end)，
+// 章节包括标题和其后直到下一个同级或更高级标题之前的所有段落
+func (doc *Document) SectionRange(heading int) (int, int, error) {
+	paragraphs := doc.paragraphNodes()
+	if heading < 0 || heading >= len(paragraphs) {
+		return 0, 0, fmt.Errorf("段落索引超出范围: %d", heading+1)
+	}
+	styleLevels := doc.styleOutlineLevels()
+	level := headingLevel(paragraphs[heading], styleLevels)
+	if level == 0 {
+		return 0, 0, fmt.Errorf("段落 %d 不是标题", heading+1)
+	}
+	end := heading + 1
+	for ; end < len(paragraphs); end++ {
+		if l := headingLevel(paragraphs[end], styleLevels); l > 0 && l <= level {
+			break
+		}
+	}
+	return heading, end, nil
+}
+
+// MoveSection 将标题所在的整个章节移动到 before 段落之前，返回移动后标题的段落索引
+//
+// before 等于段落数时移动到文档末尾。章节中的表格等非段落内容随章节一起移动。
+func (doc *Document) MoveSection(heading, before int) (int, error) {
+	body, err := doc.body()
+	if err != nil {
+		return 0, err
+	}
+	start, end, err := doc.SectionRange(heading)
+	if err != nil {
+		return 0, err
+	}
+	paragraphs := collectParagraphs(body)
+	if before < 0 || before > len(paragraphs) {
+		return 0, fmt.Errorf("段落索引超出范围: %d", before+1)
+	}
+	if before >= start && before <= end {
+		if before > start && before < end {
+			return 0, fmt.Errorf("不能将章节移动到其内部")
+		}
+		return heading, nil
+	}
+
+	// 按正文的直接子元素移动，内容控件中的段落随控件整体移动，
+	// 章节的起止位置和目标位置都必须在内容控件的边界上
+	for _, i := range []int{start, end, before} {
+		if i < len(paragraphs) && !startsBlock(body, paragraphs[i]) {
+			return 0, fmt.Errorf("无法移动内容控件中的部分章节")
+		}
+	}
+	from := body.IndexOf(bodyChild(body, paragraphs[start]))
+	to := len(body.Children)
+	if sectPr := body.Child("w:sectPr"); sectPr != nil {
+		to = body.IndexOf(sectPr)
+	}
+	if end < len(paragraphs) {
+		to = body.IndexOf(bodyChild(body, paragraphs[end]))
+	}
+
+	target := len(body.Children)
+	if sectPr := body.Child("w:sectPr"); sectPr != nil {
+		target = body.IndexOf(sectPr)
+	}
+	if before < len(paragraphs) {
+		target = body.IndexOf(bodyChild(body, paragraphs[before]))
+	}
+
+	moved := append([]*Node(nil), body.Children[from:to]...)
+	rest := append(append([]*Node(nil), body.Children[:from]...), body.Children[to:]...)
+	if target >= to {
+		target -= to - from
+	}
+	children := append(append([]*Node(nil), rest[:target]...), moved...)
+	body.Children = append(children, rest[target:]...)
+
+	doc.IsModified = true
+	newIndex := before
+	if before > start {
+		newIndex = before - (end - start)
+	}
+	log.Printf("已将段落 %d 开始的章节移动到段落 %d", heading+1, newIndex+1)
+	return newIndex, nil
+}
+
+// MoveSectionUp 将章节移动到前一个同级或更高级章节之前，返回移动后标题的段落索引
+func (doc *Document) MoveSectionUp(heading int) (int, error) {
+	level, err := doc.HeadingLevel(heading)
+	if err != nil {
+		return 0, err
+	}
+	paragraphs := doc.paragraphNodes()
+	styleLevels := doc.styleOutlineLevels()
+	for i := heading - 1; i >= 0; i-- {
+		if l := headingLevel(paragraphs[i], styleLevels); l > 0 && l <= level {
+			return doc.MoveSection(heading, i)
+		}
+	}
+	return 0, fmt.Errorf("已经是第一个章节")
+}
+
+// MoveSectionDown 将章节移动到后一个同级章节之后，返回移动后标题的段落索引
+//
+// 后面紧接着更高级的标题时，章节移动到该标题之后，成为其第一个子章节。
+func (doc *Document) MoveSectionDown(heading int) (int, error) {
+	level, err := doc.HeadingLevel(heading)
+	if err != nil {
+		return 0, err
+	}
+	_, end, err := doc.SectionRange(heading)
+	if err != nil {
+		return 0, err
+	}
+	if end >= len(doc.paragraphNodes()) {
+		return 0, fmt.Errorf("已经是最后一个章节")
+	}
+	next, err := doc.HeadingLevel(end)
+	if err != nil {
+		return 0, err
+	}
+	if next < level {
+		return doc.MoveSection(heading, end+1)
+	}
+	_, nextEnd, err := doc.SectionRange(end)
+	if err != nil {
+		return 0, err
+	}
+	return doc.MoveSection(heading, nextEnd)
+}
+
+// bodyChild 返回包含节点的正文直接子元素
+func bodyChild(body, n *Node) *Node {
+	for {
+		parent := findParent(body, n)
+		if parent == nil || parent == body {
+			return n
+		}
+		n = parent
+	}
+}
+
+// startsBlock 判断段落是否为包含它的正文直接子元素中的第一个段落
+func startsBlock(body, p *Node) bool {
+	paragraphs := collectParagraphs(&Node{Children: []*Node{bodyChild(body, p)}})
+	return len(paragraphs) > 0 && paragraphs[0] == p
+}
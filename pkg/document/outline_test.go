@@ -0,0 +1,142 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+// outlineBody 测试用的大纲：甲、乙、丙为一级标题，甲.1为二级标题
+var outlineBody = styledParagraph("Heading1", "甲") + `<w:p><w:r><w:t>甲正文</w:t></w:r></w:p>` +
+	styledParagraph("Heading2", "甲.1") + `<w:p><w:r><w:t>甲.1正文</w:t></w:r></w:p>` +
+	styledParagraph("Heading1", "乙") + `<w:p><w:r><w:t>乙正文</w:t></w:r></w:p>` +
+	styledParagraph("Heading1", "丙")
+
+func TestSectionRange(t *testing.T) {
+	doc := newBodyTestDocument(t, outlineBody)
+	tests := []struct {
+		heading, start, end int
+	}{
+		{0, 0, 4},
+		{2, 2, 4},
+		{4, 4, 6},
+		{6, 6, 7},
+	}
+	for _, tt := range tests {
+		start, end, err := doc.SectionRange(tt.heading)
+		if err != nil || start != tt.start || end != tt.end {
+			t.Errorf("SectionRange(%d) = %d, %d (%v), 期望 %d, %d", tt.heading, start, end, err, tt.start, tt.end)
+		}
+	}
+	if _, _, err := doc.SectionRange(1); err == nil {
+		t.Errorf("正文段落的章节范围没有返回错误")
+	}
+}
+
+func TestMoveSection(t *testing.T) {
+	tests := []struct {
+		name      string
+		move      func(doc *Document) (int, error)
+		want      []string
+		wantIndex int
+	}{
+		{"移动到开头", func(doc *Document) (int, error) { return doc.MoveSection(4, 0) },
+			[]string{"乙", "乙正文", "甲", "甲正文", "甲.1", "甲.1正文", "丙"}, 0},
+		{"移动到文档末尾", func(doc *Document) (int, error) { return doc.MoveSection(0, 7) },
+			[]string{"乙", "乙正文", "丙", "甲", "甲正文", "甲.1", "甲.1正文"}, 3},
+		{"移动到原位置", func(doc *Document) (int, error) { return doc.MoveSection(0, 4) },
+			[]string{"甲", "甲正文", "甲.1", "甲.1正文", "乙", "乙正文", "丙"}, 0},
+		{"上移", func(doc *Document) (int, error) { return doc.MoveSectionUp(6) },
+			[]string{"甲", "甲正文", "甲.1", "甲.1正文", "丙", "乙", "乙正文"}, 4},
+		{"下移", func(doc *Document) (int, error) { return doc.MoveSectionDown(0) },
+			[]string{"乙", "乙正文", "甲", "甲正文", "甲.1", "甲.1正文", "丙"}, 2},
+		{"下移到更高级标题之后", func(doc *Document) (int, error) { return doc.MoveSectionDown(2) },
+			[]string{"甲", "甲正文", "乙", "甲.1", "甲.1正文", "乙正文", "丙"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := newBodyTestDocument(t, outlineBody)
+			index, err := tt.move(doc)
+			if err != nil {
+				t.Fatalf("移动章节失败: %v", err)
+			}
+			if got := paragraphTexts(doc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("移动后的段落 %q, 期望 %q", got, tt.want)
+			}
+			if index != tt.wantIndex {
+				t.Errorf("移动后标题的索引 %d, 期望 %d", index, tt.wantIndex)
+			}
+			if body := testBody(t, doc); body.Elements()[len(body.Elements())-1].Name != "w:sectPr" {
+				t.Errorf("移动后节属性不在正文末尾")
+			}
+		})
+	}
+}
+
+func TestMoveSectionErrors(t *testing.T) {
+	doc := newBodyTestDocument(t, outlineBody)
+	if _, err := doc.MoveSection(0, 2); err == nil {
+		t.Errorf("将章节移动到其内部没有返回错误")
+	}
+	if _, err := doc.MoveSection(0, 8); err == nil {
+		t.Errorf("移动到超出范围的位置没有返回错误")
+	}
+	if _, err := doc.MoveSectionUp(0); err == nil {
+		t.Errorf("上移第一个章节没有返回错误")
+	}
+	if _, err := doc.MoveSectionDown(6); err == nil {
+		t.Errorf("下移最后一个章节没有返回错误")
+	}
+	if got := paragraphTexts(doc); got[0] != "甲" || got[6] != "丙" {
+		t.Errorf("出错后文档被修改: %q", got)
+	}
+}
+
+func TestMoveSectionBlocks(t *testing.T) {
+	table := `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>表格</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`
+	sdt := func(content string) string {
+		return `<w:sdt><w:sdtPr/><w:sdtContent>` + content + `</w:sdtContent></w:sdt>`
+	}
+
+	// 表格随所在的章节移动
+	doc := newBodyTestDocument(t, styledParagraph("Heading1", "甲")+table+styledParagraph("Heading1", "乙"))
+	if _, err := doc.MoveSection(0, 2); err != nil {
+		t.Fatalf("移动章节失败: %v", err)
+	}
+	if got := elementNames(testBody(t, doc)); !reflect.DeepEqual(got, []string{"w:p", "w:p", "w:tbl", "w:sectPr"}) {
+		t.Errorf("移动后的正文 %q", got)
+	}
+
+	// 整个章节位于内容控件中时移动内容控件
+	doc = newBodyTestDocument(t, styledParagraph("Heading1", "甲")+
+		sdt(styledParagraph("Heading1", "乙")+`<w:p><w:r><w:t>乙正文</w:t></w:r></w:p>`))
+	if _, err := doc.MoveSection(1, 0); err != nil {
+		t.Fatalf("移动内容控件中的章节失败: %v", err)
+	}
+	if got := paragraphTexts(doc); !reflect.DeepEqual(got, []string{"乙", "乙正文", "甲"}) {
+		t.Errorf("移动后的段落 %q", got)
+	}
+	if testBody(t, doc).Elements()[0].Name != "w:sdt" {
+		t.Errorf("内容控件没有随章节移动")
+	}
+
+	// 内容控件中还有其它章节时不能移动
+	doc = newBodyTestDocument(t, styledParagraph("Heading1", "甲")+
+		sdt(styledParagraph("Heading1", "乙")+styledParagraph("Heading1", "丙")))
+	if _, err := doc.MoveSection(1, 0); err == nil {
+		t.Errorf("移动内容控件中的部分章节没有返回错误")
+	}
+
+	// 内容控件中还有前一章节的段落时不能移动，也不能移动到内容控件中间
+	doc = newBodyTestDocument(t, styledParagraph("Heading1", "甲")+
+		sdt(`<w:p><w:r><w:t>甲正文</w:t></w:r></w:p>`+styledParagraph("Heading1", "乙"))+
+		styledParagraph("Heading1", "丙"))
+	if _, err := doc.MoveSection(2, 0); err == nil {
+		t.Errorf("移动与其它章节共用内容控件的章节没有返回错误")
+	}
+	if _, err := doc.MoveSection(3, 2); err == nil {
+		t.Errorf("移动到内容控件中间没有返回错误")
+	}
+	if got := paragraphTexts(doc); !reflect.DeepEqual(got, []string{"甲", "甲正文", "乙", "丙"}) {
+		t.Errorf("出错后文档被修改: %q", got)
+	}
+}
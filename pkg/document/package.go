@@ -0,0 +1,490 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 常用的部件内容类型
+const (
+	contentTypeRelationships = "application/vnd.openxmlformats-package.relationships+xml"
+	contentTypeMainDocument  = "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"
+	contentTypeStyles        = "application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"
+)
+
+// 常用的关系类型
+const (
+	relTypeOfficeDocument = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"
+	relTypeStyles         = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles"
+)
+
+// 命名空间
+const (
+	nsWordML        = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	nsRelationships = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	nsContentTypes  = "http://schemas.openxmlformats.org/package/2006/content-types"
+	nsPackageRels   = "http://schemas.openxmlformats.org/package/2006/relationships"
+)
+
+// contentTypesPart 内容类型部件名
+const contentTypesPart = "[Content_Types].xml"
+
+// Relationship 部件之间的关系
+type Relationship struct {
+	ID       string
+	Type     string
+	Target   string
+	External bool
+}
+
+// Package Word文档的OPC包
+//
+// 与 go-word 的 DocumentWriter 只写出正文不同，Package 保存文档中的所有部件，
+// 保存时原样写回未修改的部件，因此样式、页眉页脚、批注等内容不会丢失。
+// XML部件通过 XML 方法解析为节点树后缓存，修改节点树即修改部件内容。
+type Package struct {
+	parts map[string][]byte
+	trees map[string]*Node
+}
+
+// OpenPackage 从文件读取OPC包
+func OpenPackage(filePath string) (*Package, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取文档包: %v", err)
+	}
+	defer reader.Close()
+
+	pkg := newEmptyPackage()
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("无法读取部件 %s: %v", file.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("无法读取部件 %s: %v", file.Name, err)
+		}
+		pkg.parts[file.Name] = data
+	}
+
+	if _, ok := pkg.parts[contentTypesPart]; !ok {
+		return nil, fmt.Errorf("不是有效的Word文档: 缺少 %s", contentTypesPart)
+	}
+	return pkg, nil
+}
+
+// NewPackage 创建只包含一个空段落和基本样式的OPC包
+func NewPackage() *Package {
+	pkg := newEmptyPackage()
+
+	pkg.parts[contentTypesPart] = []byte(xmlHeader + `<Types xmlns="` + nsContentTypes + `">` +
+		`<Default Extension="rels" ContentType="` + contentTypeRelationships + `"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/word/document.xml" ContentType="` + contentTypeMainDocument + `"/>` +
+		`<Override PartName="/word/styles.xml" ContentType="` + contentTypeStyles + `"/>` +
+		`</Types>`)
+	pkg.parts["_rels/.rels"] = []byte(xmlHeader + `<Relationships xmlns="` + nsPackageRels + `">` +
+		`<Relationship Id="rId1" Type="` + relTypeOfficeDocument + `" Target="word/document.xml"/>` +
+		`</Relationships>`)
+	pkg.parts["word/_rels/document.xml.rels"] = []byte(xmlHeader + `<Relationships xmlns="` + nsPackageRels + `">` +
+		`<Relationship Id="rId1" Type="` + relTypeStyles + `" Target="styles.xml"/>` +
+		`</Relationships>`)
+	pkg.parts["word/document.xml"] = []byte(xmlHeader + `<w:document xmlns:w="` + nsWordML + `" xmlns:r="` + nsRelationships + `">` +
+		`<w:body><w:p/><w:sectPr>` +
+		`<w:pgSz w:w="11906" w:h="16838"/>` +
+		`<w:pgMar w:top="1440" w:right="1800" w:bottom="1440" w:left="1800" w:header="851" w:footer="992" w:gutter="0"/>` +
+		`</w:sectPr></w:body></w:document>`)
+	pkg.parts["word/styles.xml"] = []byte(defaultStylesXML)
+
+	return pkg
+}
+
+// newEmptyPackage 创建没有任何部件的OPC包
+func newEmptyPackage() *Package {
+	return &Package{
+		parts: make(map[string][]byte),
+		trees: make(map[string]*Node),
+	}
+}
+
+// HasPart 判断部件是否存在
+func (p *Package) HasPart(name string) bool {
+	if _, ok := p.trees[name]; ok {
+		return true
+	}
+	_, ok := p.parts[name]
+	return ok
+}
+
+// PartNames 返回所有部件名称（按名称排序）
+func (p *Package) PartNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for name := range p.parts {
+		seen[name] = true
+		names = append(names, name)
+	}
+	for name := range p.trees {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Part 返回部件的原始内容
+func (p *Package) Part(name string) ([]byte, bool) {
+	if tree, ok := p.trees[name]; ok {
+		return tree.Bytes(), true
+	}
+	data, ok := p.parts[name]
+	return data, ok
+}
+
+// SetPart 写入部件内容，contentType 不为空时同时登记内容类型
+func (p *Package) SetPart(name string, data []byte, contentType string) {
+	delete(p.trees, name)
+	p.parts[name] = data
+	if contentType != "" {
+		p.setContentType(name, contentType)
+	}
+}
+
+// SetXMLPart 写入XML部件的节点树，contentType 不为空时同时登记内容类型
+func (p *Package) SetXMLPart(name string, root *Node, contentType string) {
+	delete(p.parts, name)
+	p.trees[name] = root
+	if contentType != "" {
+		p.setContentType(name, contentType)
+	}
+}
+
+// RemovePart 删除部件及其关系文件和内容类型登记
+func (p *Package) RemovePart(name string) {
+	delete(p.parts, name)
+	delete(p.trees, name)
+	delete(p.parts, relationshipsPartName(name))
+	delete(p.trees, relationshipsPartName(name))
+
+	if types, err := p.XML(contentTypesPart); err == nil {
+		for _, override := range types.ChildrenNamed("Override") {
+			if override.Attr("PartName") == "/"+name {
+				types.RemoveChild(override)
+			}
+		}
+	}
+}
+
+// XML 返回XML部件的节点树，修改节点树会在保存时写回
+func (p *Package) XML(name string) (*Node, error) {
+	if tree, ok := p.trees[name]; ok {
+		return tree, nil
+	}
+	data, ok := p.parts[name]
+	if !ok {
+		return nil, fmt.Errorf("部件不存在: %s", name)
+	}
+	tree, err := ParseXML(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析部件 %s 失败: %v", name, err)
+	}
+	p.trees[name] = tree
+	delete(p.parts, name)
+	return tree, nil
+}
+
+// MainPartName 返回主文档部件名称
+func (p *Package) MainPartName() string {
+	for _, rel := range p.Relationships("") {
+		if rel.Type == relTypeOfficeDocument {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+	}
+	return "word/document.xml"
+}
+
+// Body 返回主文档的 w:body 节点
+func (p *Package) Body() (*Node, error) {
+	root, err := p.XML(p.MainPartName())
+	if err != nil {
+		return nil, err
+	}
+	body := root.Child("w:body")
+	if body == nil {
+		return nil, fmt.Errorf("文档缺少正文")
+	}
+	return body, nil
+}
+
+// Relationships 返回部件的所有关系，source 为空时返回包级关系
+func (p *Package) Relationships(source string) []Relationship {
+	root, err := p.XML(relationshipsPartName(source))
+	if err != nil {
+		return nil
+	}
+
+	var rels []Relationship
+	for _, n := range root.ChildrenNamed("Relationship") {
+		rels = append(rels, Relationship{
+			ID:       n.Attr("Id"),
+			Type:     n.Attr("Type"),
+			Target:   n.Attr("Target"),
+			External: n.Attr("TargetMode") == "External",
+		})
+	}
+	return rels
+}
+
+// Relationship 按ID查找部件的关系
+func (p *Package) Relationship(source, id string) (Relationship, bool) {
+	for _, rel := range p.Relationships(source) {
+		if rel.ID == id {
+			return rel, true
+		}
+	}
+	return Relationship{}, false
+}
+
+// AddRelationship 为部件添加关系并返回新的关系ID
+func (p *Package) AddRelationship(source, relType, target string, external bool) string {
+	relsName := relationshipsPartName(source)
+	root, err := p.XML(relsName)
+	if err != nil {
+		root = NewNode("Relationships", "xmlns", nsPackageRels)
+		p.trees[relsName] = root
+	}
+
+	used := make(map[string]bool)
+	for _, n := range root.ChildrenNamed("Relationship") {
+		used[n.Attr("Id")] = true
+	}
+	id := ""
+	for i := 1; ; i++ {
+		id = "rId" + strconv.Itoa(i)
+		if !used[id] {
+			break
+		}
+	}
+
+	rel := NewNode("Relationship", "Id", id, "Type", relType, "Target", target)
+	if external {
+		rel.SetAttr("TargetMode", "External")
+	}
+	root.AppendChild(rel)
+	return id
+}
+
+// RemoveRelationship 删除部件的关系
+func (p *Package) RemoveRelationship(source, id string) {
+	root, err := p.XML(relationshipsPartName(source))
+	if err != nil {
+		return
+	}
+	for _, n := range root.ChildrenNamed("Relationship") {
+		if n.Attr("Id") == id {
+			root.RemoveChild(n)
+		}
+	}
+}
+
+// RelatedPart 返回部件第一个指定类型关系指向的部件名称
+func (p *Package) RelatedPart(source, relType string) (string, bool) {
+	for _, rel := range p.Relationships(source) {
+		if rel.Type == relType && !rel.External {
+			return p.ResolveTarget(source, rel.Target), true
+		}
+	}
+	return "", false
+}
+
+// ensureRelatedPart 返回主文档指定类型关系的部件节点树，
+// 部件不存在时以 name 为名使用 create 创建，并登记内容类型和关系
+func (p *Package) ensureRelatedPart(relType, name, contentType string, create func() *Node) (string, *Node, error) {
+	main := p.MainPartName()
+	if existing, ok := p.RelatedPart(main, relType); ok && p.HasPart(existing) {
+		root, err := p.XML(existing)
+		return existing, root, err
+	}
+
+	root := create()
+	p.SetXMLPart(name, root, contentType)
+	p.AddRelationship(main, relType, relativeTarget(main, name), false)
+	return name, root, nil
+}
+
+// relativeTarget 返回从 source 部件指向 name 部件的相对关系目标
+func relativeTarget(source, name string) string {
+	return strings.TrimPrefix(name, path.Dir(source)+"/")
+}
+
+// ResolveTarget 将关系目标转换为包内的部件名称
+func (p *Package) ResolveTarget(source, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Clean(path.Join(path.Dir(source), target))
+}
+
+// setContentType 登记部件的内容类型
+func (p *Package) setContentType(name, contentType string) {
+	types, err := p.XML(contentTypesPart)
+	if err != nil {
+		types = NewNode("Types", "xmlns", nsContentTypes)
+		p.trees[contentTypesPart] = types
+	}
+
+	partName := "/" + name
+	for _, override := range types.ChildrenNamed("Override") {
+		if override.Attr("PartName") == partName {
+			override.SetAttr("ContentType", contentType)
+			return
+		}
+	}
+	types.AppendChild(NewNode("Override", "PartName", partName, "ContentType", contentType))
+}
+
+// EnsureDefaultContentType 登记扩展名的默认内容类型（用于图片等二进制部件）
+func (p *Package) EnsureDefaultContentType(extension, contentType string) {
+	types, err := p.XML(contentTypesPart)
+	if err != nil {
+		return
+	}
+	extension = strings.TrimPrefix(strings.ToLower(extension), ".")
+	for _, def := range types.ChildrenNamed("Default") {
+		if strings.EqualFold(def.Attr("Extension"), extension) {
+			return
+		}
+	}
+	types.InsertChild(0, NewNode("Default", "Extension", extension, "ContentType", contentType))
+}
+
+// Save 将OPC包写入文件
+func (p *Package) Save(filePath string) error {
+	var buf bytes.Buffer
+	if err := p.write(&buf); err != nil {
+		return err
+	}
+
+	// 先写临时文件再替换，避免覆盖原文件时写入失败导致文档损坏
+	tempFile, err := os.CreateTemp(filepath.Dir(filePath), ".~"+filepath.Base(filePath)+".*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	tempPath := tempFile.Name()
+
+	if _, err := tempFile.Write(buf.Bytes()); err != nil {
+		tempFile.Close()
+		os.Remove(tempPath)
+		return fmt.Errorf("写入文档失败: %v", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("写入文档失败: %v", err)
+	}
+	// 临时文件的权限是0600，替换后保留原文件的权限，新文件使用0644
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(filePath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tempPath, mode); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("设置文件权限失败: %v", err)
+	}
+	if err := os.Rename(tempPath, filePath); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("保存文档失败: %v", err)
+	}
+	return nil
+}
+
+// Clone 拷贝OPC包，解析过的节点树深拷贝
+//
+// 未解析的部件内容不会被原地修改（SetPart 总是替换整个切片），副本与原包共享这些字节，
+// 撤销记录因此不会为每一步复制图片等二进制部件。
+func (p *Package) Clone() *Package {
+	clone := newEmptyPackage()
+	for name, data := range p.parts {
+		clone.parts[name] = data
+	}
+	for name, tree := range p.trees {
+		clone.trees[name] = tree.Clone()
+	}
+	return clone
+}
+
+// restore 用另一个包的内容替换当前内容，other 之后不能再使用
+func (p *Package) restore(other *Package) {
+	p.parts = other.parts
+	p.trees = other.trees
+}
+
+// write 将OPC包写为ZIP格式，[Content_Types].xml 必须是第一个条目
+func (p *Package) write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	names := p.PartNames()
+	sort.SliceStable(names, func(i, j int) bool {
+		return names[i] == contentTypesPart && names[j] != contentTypesPart
+	})
+
+	for _, name := range names {
+		data, _ := p.Part(name)
+		fw, err := zw.Create(name)
+		if err != nil {
+			return fmt.Errorf("写入部件 %s 失败: %v", name, err)
+		}
+		if _, err := fw.Write(data); err != nil {
+			return fmt.Errorf("写入部件 %s 失败: %v", name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("写入文档失败: %v", err)
+	}
+	return nil
+}
+
+// relationshipsPartName 返回部件对应的关系文件名称，source 为空时为包级关系
+func relationshipsPartName(source string) string {
+	if source == "" {
+		return "_rels/.rels"
+	}
+	return path.Join(path.Dir(source), "_rels", path.Base(source)+".rels")
+}
+
+// defaultStylesXML 新建文档使用的基本样式
+const defaultStylesXML = xmlHeader + `<w:styles xmlns:w="` + nsWordML + `">` +
+	`<w:docDefaults><w:rPrDefault><w:rPr>` +
+	`<w:rFonts w:ascii="Calibri" w:eastAsia="宋体" w:hAnsi="Calibri" w:cs="Times New Roman"/>` +
+	`<w:sz w:val="21"/><w:szCs w:val="21"/><w:lang w:val="en-US" w:eastAsia="zh-CN"/>` +
+	`</w:rPr></w:rPrDefault><w:pPrDefault/></w:docDefaults>` +
+	`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/>` +
+	`<w:pPr><w:widowControl w:val="0"/><w:jc w:val="both"/></w:pPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>` +
+	`<w:pPr><w:spacing w:before="240" w:after="60"/><w:jc w:val="center"/><w:outlineLvl w:val="0"/></w:pPr>` +
+	`<w:rPr><w:b/><w:sz w:val="32"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>` +
+	`<w:pPr><w:keepNext/><w:keepLines/><w:spacing w:before="340" w:after="330" w:line="578" w:lineRule="auto"/><w:outlineLvl w:val="0"/></w:pPr>` +
+	`<w:rPr><w:b/><w:kern w:val="44"/><w:sz w:val="44"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>` +
+	`<w:pPr><w:keepNext/><w:keepLines/><w:spacing w:before="260" w:after="260" w:line="416" w:lineRule="auto"/><w:outlineLvl w:val="1"/></w:pPr>` +
+	`<w:rPr><w:b/><w:sz w:val="32"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading3"><w:name w:val="heading 3"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>` +
+	`<w:pPr><w:keepNext/><w:keepLines/><w:spacing w:before="260" w:after="260" w:line="416" w:lineRule="auto"/><w:outlineLvl w:val="2"/></w:pPr>` +
+	`<w:rPr><w:b/><w:sz w:val="32"/></w:rPr></w:style>` +
+	`<w:style w:type="character" w:default="1" w:styleId="DefaultParagraphFont"><w:name w:val="Default Paragraph Font"/><w:uiPriority w:val="1"/><w:semiHidden/></w:style>` +
+	`</w:styles>`
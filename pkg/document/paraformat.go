@@ -0,0 +1,349 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// Alignment 段落对齐方式，值为 w:jc 的取值
+type Alignment string
+
+const (
+	AlignLeft       Alignment = "left"       // 左对齐
+	AlignCenter     Alignment = "center"     // 居中
+	AlignRight      Alignment = "right"      // 右对齐
+	AlignJustify    Alignment = "both"       // 两端对齐
+	AlignDistribute Alignment = "distribute" // 分散对齐
+)
+
+// LineRule 行距规则，值为 w:spacing 的 w:lineRule 取值
+type LineRule string
+
+const (
+	LineAuto    LineRule = "auto"    // 多倍行距，LineSpacing 以240为单倍
+	LineExact   LineRule = "exact"   // 固定值，LineSpacing 单位为缇
+	LineAtLeast LineRule = "atLeast" // 最小值，LineSpacing 单位为缇
+)
+
+// ChineseIndentChars 中文段落首行缩进两个字符，单位为百分之一字符
+const ChineseIndentChars = 200
+
+// ParaProperty 段落格式属性，可以按位组合，指定要设置的属性
+type ParaProperty int
+
+const (
+	ParaAlignment       ParaProperty = 1 << iota // 对齐方式
+	ParaIndent                                   // 左右缩进
+	ParaFirstLine                                // 首行缩进和悬挂缩进
+	ParaSpacing                                  // 段前段后间距
+	ParaLineSpacing                              // 行距
+	ParaKeepNext                                 // 与下段同页
+	ParaKeepLines                                // 段中不分页
+	ParaPageBreakBefore                          // 段前分页
+	ParaBorder                                   // 边框
+	ParaShading                                  // 底纹
+
+	AllParaProperties = ParaAlignment | ParaIndent | ParaFirstLine | ParaSpacing | ParaLineSpacing |
+		ParaKeepNext | ParaKeepLines | ParaPageBreakBefore | ParaBorder | ParaShading
+)
+
+// BorderSide 段落边框的边，可以按位组合
+type BorderSide int
+
+const (
+	BorderTop BorderSide = 1 << iota
+	BorderLeft
+	BorderBottom
+	BorderRight
+
+	BorderBox = BorderTop | BorderLeft | BorderBottom | BorderRight
+)
+
+// borderSides 边框的边和对应的元素，顺序与架构一致
+var borderSides = []struct {
+	side BorderSide
+	name string
+}{
+	{BorderTop, "w:top"}, {BorderLeft, "w:left"}, {BorderBottom, "w:bottom"}, {BorderRight, "w:right"},
+}
+
+// ParagraphBorder 段落边框，Sides 为0时表示无边框
+type ParagraphBorder struct {
+	Sides BorderSide
+	Style string // 线型，如 single、double、dotted、dashed，空时为 single
+	Size  int    // 线宽，单位为八分之一磅，0时为4（半磅）
+	Color string // RRGGBB，空表示自动
+}
+
+// ParagraphFormat 段落格式，缩进和间距的单位为缇（二十分之一磅），Alignment 为空表示沿用样式
+type ParagraphFormat struct {
+	Alignment       Alignment
+	LeftIndent      int
+	RightIndent     int
+	FirstLine       int // 首行缩进，负值表示悬挂缩进
+	FirstLineChars  int // 以百分之一字符为单位的首行缩进，负值表示悬挂缩进，非0时优先于 FirstLine
+	SpaceBefore     int
+	SpaceAfter      int
+	LineRule        LineRule
+	LineSpacing     int // 0表示默认行距
+	KeepNext        bool
+	KeepLines       bool
+	PageBreakBefore bool
+	Border          ParagraphBorder
+	Shading         string // 底纹填充色 RRGGBB，空表示无
+}
+
+// ParagraphFormat 返回段落直接设置的段落格式
+func (doc *Document) ParagraphFormat(paragraph int) (ParagraphFormat, error) {
+	paragraphs := doc.paragraphNodes()
+	if paragraph < 0 || paragraph >= len(paragraphs) {
+		return ParagraphFormat{}, fmt.Errorf("段落索引超出范围: %d", paragraph+1)
+	}
+	return directParagraphFormat(paragraphs[paragraph].Child("w:pPr")), nil
+}
+
+// SetParagraphFormat 为 start 到 end（包含）的段落设置段落格式，props 指定要设置的属性
+func (doc *Document) SetParagraphFormat(start, end int, format ParagraphFormat, props ParaProperty) error {
+	paragraphs := doc.paragraphNodes()
+	if start < 0 || end >= len(paragraphs) || start > end {
+		return fmt.Errorf("段落范围无效: %d-%d", start+1, end+1)
+	}
+	if err := format.validate(props); err != nil {
+		return err
+	}
+	for i := start; i <= end; i++ {
+		pPr := paragraphProperties(paragraphs[i])
+		setParagraphProperties(pPr, format, props)
+	}
+	doc.IsModified = true
+	log.Printf("已设置段落 %d-%d 的段落格式", start+1, end+1)
+	return nil
+}
+
+// SetAlignment 设置段落的对齐方式
+func (doc *Document) SetAlignment(paragraph int, alignment Alignment) error {
+	return doc.SetParagraphFormat(paragraph, paragraph, ParagraphFormat{Alignment: alignment}, ParaAlignment)
+}
+
+// validate 检查格式中要设置的属性是否有效
+func (f ParagraphFormat) validate(props ParaProperty) error {
+	if props&ParaAlignment != 0 {
+		switch f.Alignment {
+		case AlignLeft, AlignCenter, AlignRight, AlignJustify, AlignDistribute, "":
+		default:
+			return fmt.Errorf("无效的对齐方式: %s", f.Alignment)
+		}
+	}
+	if props&ParaSpacing != 0 && (f.SpaceBefore < 0 || f.SpaceAfter < 0) {
+		return fmt.Errorf("段落间距不能为负数")
+	}
+	if props&ParaLineSpacing != 0 {
+		switch f.LineRule {
+		case LineAuto, LineExact, LineAtLeast, "":
+		default:
+			return fmt.Errorf("无效的行距规则: %s", f.LineRule)
+		}
+		if f.LineSpacing < 0 {
+			return fmt.Errorf("行距不能为负数")
+		}
+	}
+	if props&ParaBorder != 0 && f.Border.Color != "" && !hexColorPattern.MatchString(f.Border.Color) {
+		return fmt.Errorf("无效的边框颜色: %s", f.Border.Color)
+	}
+	if props&ParaShading != 0 && f.Shading != "" && !hexColorPattern.MatchString(f.Shading) {
+		return fmt.Errorf("无效的底纹颜色: %s", f.Shading)
+	}
+	return nil
+}
+
+// setParagraphProperties 在 w:pPr 中写入 props 指定的段落格式
+func setParagraphProperties(pPr *Node, f ParagraphFormat, props ParaProperty) {
+	if props&ParaAlignment != 0 {
+		if f.Alignment == "" {
+			pPr.RemoveChildrenNamed("w:jc")
+		} else {
+			pPr.EnsureChild("w:jc", pPrOrder).SetAttr("w:val", string(f.Alignment))
+		}
+	}
+	if props&ParaIndent != 0 {
+		ind := pPr.EnsureChild("w:ind", pPrOrder)
+		for _, name := range []string{"w:start", "w:end", "w:leftChars", "w:rightChars", "w:startChars", "w:endChars"} {
+			ind.RemoveAttr(name)
+		}
+		setTwipsAttr(ind, "w:left", f.LeftIndent)
+		setTwipsAttr(ind, "w:right", f.RightIndent)
+	}
+	if props&ParaFirstLine != 0 {
+		ind := pPr.EnsureChild("w:ind", pPrOrder)
+		for _, name := range []string{"w:firstLine", "w:hanging", "w:firstLineChars", "w:hangingChars"} {
+			ind.RemoveAttr(name)
+		}
+		switch {
+		case f.FirstLineChars > 0:
+			ind.SetAttr("w:firstLineChars", strconv.Itoa(f.FirstLineChars))
+		case f.FirstLineChars < 0:
+			ind.SetAttr("w:hangingChars", strconv.Itoa(-f.FirstLineChars))
+		case f.FirstLine > 0:
+			ind.SetAttr("w:firstLine", strconv.Itoa(f.FirstLine))
+		case f.FirstLine < 0:
+			ind.SetAttr("w:hanging", strconv.Itoa(-f.FirstLine))
+		}
+	}
+	if ind := pPr.Child("w:ind"); ind != nil && len(ind.Attrs) == 0 {
+		pPr.RemoveChild(ind)
+	}
+
+	if props&ParaSpacing != 0 {
+		spacing := pPr.EnsureChild("w:spacing", pPrOrder)
+		for _, name := range []string{"w:beforeLines", "w:afterLines", "w:beforeAutospacing", "w:afterAutospacing"} {
+			spacing.RemoveAttr(name)
+		}
+		spacing.SetAttr("w:before", strconv.Itoa(f.SpaceBefore))
+		spacing.SetAttr("w:after", strconv.Itoa(f.SpaceAfter))
+	}
+	if props&ParaLineSpacing != 0 {
+		spacing := pPr.EnsureChild("w:spacing", pPrOrder)
+		if f.LineSpacing == 0 {
+			spacing.RemoveAttr("w:line")
+			spacing.RemoveAttr("w:lineRule")
+		} else {
+			rule := f.LineRule
+			if rule == "" {
+				rule = LineAuto
+			}
+			spacing.SetAttr("w:line", strconv.Itoa(f.LineSpacing))
+			spacing.SetAttr("w:lineRule", string(rule))
+		}
+	}
+	if spacing := pPr.Child("w:spacing"); spacing != nil && len(spacing.Attrs) == 0 {
+		pPr.RemoveChild(spacing)
+	}
+
+	setParagraphOnOff := func(prop ParaProperty, name string, on bool) {
+		if props&prop == 0 {
+			return
+		}
+		// 关闭时写入 w:val="0" 以覆盖样式中的设置
+		if on {
+			pPr.EnsureChild(name, pPrOrder).RemoveAttr("w:val")
+		} else {
+			pPr.EnsureChild(name, pPrOrder).SetAttr("w:val", "0")
+		}
+	}
+	setParagraphOnOff(ParaKeepNext, "w:keepNext", f.KeepNext)
+	setParagraphOnOff(ParaKeepLines, "w:keepLines", f.KeepLines)
+	setParagraphOnOff(ParaPageBreakBefore, "w:pageBreakBefore", f.PageBreakBefore)
+
+	if props&ParaBorder != 0 {
+		pPr.RemoveChildrenNamed("w:pBdr")
+		if f.Border.Sides != 0 {
+			pPr.InsertOrdered(newBorderNode(f.Border), pPrOrder)
+		}
+	}
+	if props&ParaShading != 0 {
+		pPr.RemoveChildrenNamed("w:shd")
+		if f.Shading != "" {
+			pPr.InsertOrdered(NewNode("w:shd", "w:val", "clear", "w:color", "auto", "w:fill", strings.ToUpper(f.Shading)), pPrOrder)
+		}
+	}
+}
+
+// setTwipsAttr 设置以缇为单位的属性，值为0时删除属性
+func setTwipsAttr(n *Node, name string, value int) {
+	if value == 0 {
+		n.RemoveAttr(name)
+		return
+	}
+	n.SetAttr(name, strconv.Itoa(value))
+}
+
+// newBorderNode 创建 w:pBdr 元素
+func newBorderNode(border ParagraphBorder) *Node {
+	style := border.Style
+	if style == "" {
+		style = "single"
+	}
+	size := border.Size
+	if size <= 0 {
+		size = 4
+	}
+	color := "auto"
+	if border.Color != "" {
+		color = strings.ToUpper(border.Color)
+	}
+	pBdr := NewNode("w:pBdr")
+	for _, s := range borderSides {
+		if border.Sides&s.side != 0 {
+			pBdr.AppendChild(NewNode(s.name, "w:val", style, "w:sz", strconv.Itoa(size), "w:space", "1", "w:color", color))
+		}
+	}
+	return pBdr
+}
+
+// directParagraphFormat 读取 w:pPr 中直接设置的段落格式
+func directParagraphFormat(pPr *Node) ParagraphFormat {
+	var f ParagraphFormat
+	if pPr == nil {
+		return f
+	}
+	if jc := pPr.Child("w:jc"); jc != nil {
+		switch value := jc.Attr("w:val"); value {
+		case "start":
+			f.Alignment = AlignLeft
+		case "end":
+			f.Alignment = AlignRight
+		default:
+			f.Alignment = Alignment(value)
+		}
+	}
+	intAttr := func(n *Node, names ...string) int {
+		for _, name := range names {
+			if value, err := strconv.Atoi(n.Attr(name)); err == nil {
+				return value
+			}
+		}
+		return 0
+	}
+	if ind := pPr.Child("w:ind"); ind != nil {
+		f.LeftIndent = intAttr(ind, "w:left", "w:start")
+		f.RightIndent = intAttr(ind, "w:right", "w:end")
+		f.FirstLine = intAttr(ind, "w:firstLine") - intAttr(ind, "w:hanging")
+		f.FirstLineChars = intAttr(ind, "w:firstLineChars") - intAttr(ind, "w:hangingChars")
+	}
+	if spacing := pPr.Child("w:spacing"); spacing != nil {
+		f.SpaceBefore = intAttr(spacing, "w:before")
+		f.SpaceAfter = intAttr(spacing, "w:after")
+		f.LineSpacing = intAttr(spacing, "w:line")
+		if f.LineSpacing != 0 {
+			f.LineRule = LineRule(spacing.Attr("w:lineRule"))
+			if f.LineRule == "" {
+				f.LineRule = LineAuto
+			}
+		}
+	}
+	f.KeepNext = onOffValue(pPr.Child("w:keepNext"))
+	f.KeepLines = onOffValue(pPr.Child("w:keepLines"))
+	f.PageBreakBefore = onOffValue(pPr.Child("w:pageBreakBefore"))
+	if pBdr := pPr.Child("w:pBdr"); pBdr != nil {
+		for _, s := range borderSides {
+			side := pBdr.Child(s.name)
+			if side == nil || side.Attr("w:val") == "none" || side.Attr("w:val") == "nil" {
+				continue
+			}
+			f.Border.Sides |= s.side
+			f.Border.Style = side.Attr("w:val")
+			f.Border.Size, _ = strconv.Atoi(side.Attr("w:sz"))
+			if color := side.Attr("w:color"); color != "auto" {
+				f.Border.Color = color
+			}
+		}
+	}
+	if shd := pPr.Child("w:shd"); shd != nil {
+		if fill := shd.Attr("w:fill"); fill != "" && fill != "auto" {
+			f.Shading = fill
+		}
+	}
+	return f
+}
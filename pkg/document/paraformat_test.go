@@ -0,0 +1,151 @@
+package document
+
+import (
+	"testing"
+)
+
+// checkSchemaOrder 检查节点的子元素是否按架构顺序排列
+func checkSchemaOrder(t *testing.T, n *Node, order []string) {
+	t.Helper()
+	last := -1
+	for _, c := range n.Elements() {
+		index := indexOfString(order, c.Name)
+		if index < last {
+			t.Errorf("%s 的子元素 %s 不符合架构顺序: %s", n.Name, c.Name, n)
+		}
+		last = index
+	}
+}
+
+func TestParagraphFormatRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		format ParagraphFormat
+		props  ParaProperty
+		want   ParagraphFormat // 读取结果，为零值时与 format 相同
+	}{
+		{"对齐", ParagraphFormat{Alignment: AlignDistribute}, ParaAlignment, ParagraphFormat{}},
+		{"缩进", ParagraphFormat{LeftIndent: 420, RightIndent: 210}, ParaIndent, ParagraphFormat{}},
+		{"首行缩进", ParagraphFormat{FirstLine: 420}, ParaFirstLine, ParagraphFormat{}},
+		{"悬挂缩进", ParagraphFormat{FirstLine: -360}, ParaFirstLine, ParagraphFormat{}},
+		{"按字符缩进优先", ParagraphFormat{FirstLine: 420, FirstLineChars: ChineseIndentChars}, ParaFirstLine,
+			ParagraphFormat{FirstLineChars: ChineseIndentChars}},
+		{"按字符悬挂缩进", ParagraphFormat{FirstLineChars: -100}, ParaFirstLine, ParagraphFormat{}},
+		{"段落间距", ParagraphFormat{SpaceBefore: 120, SpaceAfter: 240}, ParaSpacing, ParagraphFormat{}},
+		{"默认行距规则", ParagraphFormat{LineSpacing: 360}, ParaLineSpacing,
+			ParagraphFormat{LineSpacing: 360, LineRule: LineAuto}},
+		{"固定行距", ParagraphFormat{LineSpacing: 400, LineRule: LineExact}, ParaLineSpacing, ParagraphFormat{}},
+		{"分页", ParagraphFormat{KeepNext: true, KeepLines: true, PageBreakBefore: true},
+			ParaKeepNext | ParaKeepLines | ParaPageBreakBefore, ParagraphFormat{}},
+		{"默认边框", ParagraphFormat{Border: ParagraphBorder{Sides: BorderTop | BorderBottom}}, ParaBorder,
+			ParagraphFormat{Border: ParagraphBorder{Sides: BorderTop | BorderBottom, Style: "single", Size: 4}}},
+		{"边框", ParagraphFormat{Border: ParagraphBorder{Sides: BorderBox, Style: "double", Size: 12, Color: "ff0000"}}, ParaBorder,
+			ParagraphFormat{Border: ParagraphBorder{Sides: BorderBox, Style: "double", Size: 12, Color: "FF0000"}}},
+		{"底纹", ParagraphFormat{Shading: "d9d9d9"}, ParaShading, ParagraphFormat{Shading: "D9D9D9"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pPr := NewNode("w:pPr")
+			setParagraphProperties(pPr, tt.format, tt.props)
+			want := tt.want
+			if want == (ParagraphFormat{}) {
+				want = tt.format
+			}
+			if got := directParagraphFormat(pPr); got != want {
+				t.Errorf("读取的段落格式 %+v, 期望 %+v (%s)", got, want, pPr)
+			}
+		})
+	}
+}
+
+func TestSetParagraphProperties(t *testing.T) {
+	pPr := NewNode("w:pPr")
+	pPr.AppendChild(NewNode("w:pStyle", "w:val", "Quote"))
+	pPr.AppendChild(NewNode("w:rPr"))
+	all := ParagraphFormat{
+		Alignment: AlignCenter, LeftIndent: 100, FirstLine: 200, SpaceBefore: 10, LineSpacing: 240,
+		KeepNext: true, PageBreakBefore: true, Border: ParagraphBorder{Sides: BorderLeft}, Shading: "FFFF00",
+	}
+	setParagraphProperties(pPr, all, AllParaProperties)
+	checkSchemaOrder(t, pPr, pPrOrder)
+	if got := pPr.Child("w:keepLines"); got == nil || got.Attr("w:val") != "0" {
+		t.Errorf("关闭的属性没有写入关闭值: %s", got)
+	}
+
+	// 清除格式时删除对应的元素，保留其它属性
+	setParagraphProperties(pPr, ParagraphFormat{}, ParaAlignment|ParaIndent|ParaFirstLine|ParaLineSpacing|ParaBorder|ParaShading)
+	for _, name := range []string{"w:jc", "w:ind", "w:pBdr", "w:shd"} {
+		if pPr.Child(name) != nil {
+			t.Errorf("清除后仍有 %s", name)
+		}
+	}
+	if spacing := pPr.Child("w:spacing"); spacing == nil || spacing.Attr("w:line") != "" || spacing.Attr("w:before") != "10" {
+		t.Errorf("清除行距后的段落间距 %s", spacing)
+	}
+	if pPr.Child("w:pStyle") == nil || pPr.Child("w:rPr") == nil {
+		t.Errorf("设置段落格式删除了其它属性: %s", pPr)
+	}
+
+	// 按字符的设置被按缇的设置替换
+	pPr = NewNode("w:pPr")
+	pPr.AppendChild(NewNode("w:spacing", "w:beforeLines", "50", "w:beforeAutospacing", "1"))
+	pPr.AppendChild(NewNode("w:ind", "w:leftChars", "200", "w:firstLineChars", "200"))
+	setParagraphProperties(pPr, ParagraphFormat{LeftIndent: 420, FirstLine: 420, SpaceBefore: 156}, ParaIndent|ParaFirstLine|ParaSpacing)
+	if got, want := pPr.String(), `<w:pPr><w:spacing w:before="156" w:after="0"/><w:ind w:left="420" w:firstLine="420"/></w:pPr>`; got != want {
+		t.Errorf("w:pPr %s, 期望 %s", got, want)
+	}
+}
+
+func TestDirectParagraphFormat(t *testing.T) {
+	pPr, err := ParseXML([]byte(`<w:pPr><w:jc w:val="end"/><w:ind w:start="100" w:end="50" w:hanging="200"/>` +
+		`<w:pBdr><w:top w:val="nil"/><w:bottom w:val="dotted" w:sz="8" w:color="auto"/></w:pBdr><w:shd w:fill="auto"/></w:pPr>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ParagraphFormat{
+		Alignment: AlignRight, LeftIndent: 100, RightIndent: 50, FirstLine: -200,
+		Border: ParagraphBorder{Sides: BorderBottom, Style: "dotted", Size: 8},
+	}
+	if got := directParagraphFormat(pPr); got != want {
+		t.Errorf("段落格式 %+v, 期望 %+v", got, want)
+	}
+	if got := directParagraphFormat(nil); got != (ParagraphFormat{}) {
+		t.Errorf("没有段落属性时的格式 %+v", got)
+	}
+}
+
+func TestSetParagraphFormat(t *testing.T) {
+	doc := newTestDocument(t, "第一段", "第二段", "第三段")
+	if err := doc.SetParagraphFormat(0, 1, ParagraphFormat{FirstLineChars: ChineseIndentChars}, ParaFirstLine); err != nil {
+		t.Fatalf("设置段落格式失败: %v", err)
+	}
+	for i, want := range []int{ChineseIndentChars, ChineseIndentChars, 0} {
+		if f, _ := doc.ParagraphFormat(i); f.FirstLineChars != want {
+			t.Errorf("段落 %d 的首行缩进 %d, 期望 %d", i+1, f.FirstLineChars, want)
+		}
+	}
+	if err := doc.SetAlignment(2, AlignRight); err != nil {
+		t.Fatalf("设置对齐方式失败: %v", err)
+	}
+	if f, _ := doc.ParagraphFormat(2); f.Alignment != AlignRight {
+		t.Errorf("对齐方式 %q, 期望 %q", f.Alignment, AlignRight)
+	}
+
+	errors := []struct {
+		name       string
+		start, end int
+		format     ParagraphFormat
+		props      ParaProperty
+	}{
+		{"段落范围无效", 2, 3, ParagraphFormat{}, ParaAlignment},
+		{"无效的对齐方式", 0, 0, ParagraphFormat{Alignment: "middle"}, ParaAlignment},
+		{"负的段落间距", 0, 0, ParagraphFormat{SpaceBefore: -1}, ParaSpacing},
+		{"无效的行距规则", 0, 0, ParagraphFormat{LineSpacing: 240, LineRule: "double"}, ParaLineSpacing},
+		{"无效的底纹颜色", 0, 0, ParagraphFormat{Shading: "yellow"}, ParaShading},
+	}
+	for _, tt := range errors {
+		if err := doc.SetParagraphFormat(tt.start, tt.end, tt.format, tt.props); err == nil {
+			t.Errorf("%s没有返回错误", tt.name)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package document
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// 文档属性部件的包级关系类型
+const (
+	relTypeCoreProperties     = "http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties"
+	relTypeExtendedProperties = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/extended-properties"
+	relTypeCustomProperties   = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/custom-properties"
+)
+
+// corePropertyNames 核心属性元素（不含前缀）对应的Word属性名称
+var corePropertyNames = map[string]string{
+	"title":          "Title",
+	"subject":        "Subject",
+	"creator":        "Author",
+	"keywords":       "Keywords",
+	"description":    "Comments",
+	"lastModifiedBy": "LastSavedBy",
+	"revision":       "RevisionNumber",
+	"category":       "Category",
+	"created":        "CreateTime",
+	"modified":       "LastSavedTime",
+	"lastPrinted":    "LastPrinted",
+}
+
+// extendedPropertyNames 扩展属性元素对应的Word属性名称
+var extendedPropertyNames = map[string]string{
+	"Company":              "Company",
+	"Manager":              "Manager",
+	"Template":             "Template",
+	"Application":          "NameOfApplication",
+	"TotalTime":            "TotalEditingTime",
+	"Pages":                "Pages",
+	"Words":                "Words",
+	"Characters":           "Characters",
+	"CharactersWithSpaces": "CharactersWithSpaces",
+	"Paragraphs":           "Paragraphs",
+	"Lines":                "Lines",
+}
+
+// localName 返回去掉命名空间前缀的元素名称
+func localName(name string) string {
+	return name[strings.LastIndex(name, ":")+1:]
+}
+
+// DocumentProperties 返回文档的核心属性、扩展属性和自定义属性，键为Word的属性名称
+//
+// 时间属性转换为本地时间，格式如 2025/8/20 10:30。
+func (doc *Document) DocumentProperties() map[string]string {
+	properties := make(map[string]string)
+	if doc.Package == nil {
+		return properties
+	}
+	if root := doc.propertiesPart(relTypeCoreProperties); root != nil {
+		for _, c := range root.Elements() {
+			if name, ok := corePropertyNames[localName(c.Name)]; ok {
+				value := strings.TrimSpace(c.InnerText())
+				if t, err := time.Parse(time.RFC3339, value); err == nil {
+					value = t.Local().Format("2006/1/2 15:04")
+				}
+				properties[name] = value
+			}
+		}
+	}
+	if root := doc.propertiesPart(relTypeExtendedProperties); root != nil {
+		for _, c := range root.Elements() {
+			if name, ok := extendedPropertyNames[localName(c.Name)]; ok {
+				properties[name] = strings.TrimSpace(c.InnerText())
+			}
+		}
+	}
+	if root := doc.propertiesPart(relTypeCustomProperties); root != nil {
+		for _, c := range root.Elements() {
+			if name := c.Attr("name"); name != "" {
+				properties[name] = strings.TrimSpace(c.InnerText())
+			}
+		}
+	}
+	return properties
+}
+
+// DocumentProperty 按名称查找文档属性，名称不区分大小写
+func (doc *Document) DocumentProperty(name string) (string, bool) {
+	properties := doc.DocumentProperties()
+	if value, ok := properties[name]; ok {
+		return value, true
+	}
+	for key, value := range properties {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// DocumentPropertyNames 返回文档中已有的属性名称，按名称排序
+func (doc *Document) DocumentPropertyNames() []string {
+	var names []string
+	for name := range doc.DocumentProperties() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// propertiesPart 返回包级关系指向的属性部件，不存在时返回nil
+func (doc *Document) propertiesPart(relType string) *Node {
+	name, ok := doc.Package.RelatedPart("", relType)
+	if !ok || !doc.Package.HasPart(name) {
+		return nil
+	}
+	root, err := doc.Package.XML(name)
+	if err != nil {
+		return nil
+	}
+	return root
+}
@@ -0,0 +1,402 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// RedlineOptions 生成修订文档的选项
+type RedlineOptions struct {
+	Author string    // 修订作者，为空时使用"fyne-word"
+	Date   time.Time // 修订时间，为零值时使用当前时间
+}
+
+// CreateRedline 比较两个文档，生成以修订标记（w:ins/w:del）显示差异的新文档
+//
+// 修订文档以修订后文档的副本为基础，表格、图片、编号、页眉页脚、分节和脚注等内容都保留，
+// 只在有变化的正文段落中加上修订标记。新文档加入管理器并成为当前文档，
+// 可以直接使用 SaveDocumentAs 保存，在Word中打开后差异显示为可接受或拒绝的修订。
+func (m *Manager) CreateRedline(oldSrc, newSrc CompareSource, opts RedlineOptions) (*Document, error) {
+	oldParas, err := oldSrc.load()
+	if err != nil {
+		return nil, fmt.Errorf("读取原文档失败: %v", err)
+	}
+	revised, err := newSrc.openPackage()
+	if err != nil {
+		return nil, fmt.Errorf("读取新文档失败: %v", err)
+	}
+
+	log.Printf("正在生成修订文档: %s → %s", oldSrc.Name(), newSrc.Name())
+
+	pkg, err := BuildRedline(oldParas, revised, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	baseName := strings.TrimSuffix(filepath.Base(newSrc.Name()), filepath.Ext(newSrc.Name()))
+	doc := &Document{
+		FileName:   baseName + "_修订.docx",
+		Title:      baseName + " 修订对比",
+		Package:    pkg,
+		IsOpen:     true,
+		IsModified: true,
+	}
+	m.addUnsaved(doc)
+
+	log.Printf("修订文档生成成功: %s", doc.FileName)
+	return doc, nil
+}
+
+// BuildRedline 比较原文档的段落和修订后的文档包，返回在修订后文档的副本上加了修订标记的OPC包
+//
+// 比较修订后文档的顶层正文段落：插入的文字放入 w:ins，删除的文字以 w:del 插回原位置，
+// 格式和段落样式的变化记录为 w:rPrChange 和 w:pPrChange，整段删除的段落插回原来的位置。
+// 接受所有修订得到修订后的文档，拒绝所有修订得到原文档的文字。revised 不会被修改。
+func BuildRedline(oldParas []types.Paragraph, revised *Package, opts RedlineOptions) (*Package, error) {
+	builder := &redlineBuilder{
+		author: opts.Author,
+		date:   opts.Date,
+	}
+	if builder.author == "" {
+		builder.author = "fyne-word"
+	}
+	if builder.date.IsZero() {
+		builder.date = time.Now()
+	}
+
+	pkg := revised.Clone()
+	doc := &Document{Package: pkg}
+	body, err := doc.body()
+	if err != nil {
+		return nil, err
+	}
+	builder.nextID = nextRevisionID(body)
+
+	nodes := doc.paragraphNodes()
+	newParas := make([]types.Paragraph, len(nodes))
+	for i, p := range nodes {
+		newParas[i] = paragraphFromNode(p)
+	}
+
+	// 整段删除的段落插在前一个修订后段落之后，没有时插在第一个段落之前
+	var previous *Node
+	insertDeleted := func(p *Node) {
+		switch {
+		case previous != nil:
+			parent := findParent(body, previous)
+			parent.InsertChild(parent.IndexOf(previous)+1, p)
+		case len(nodes) > 0:
+			parent := findParent(body, nodes[0])
+			parent.InsertChild(parent.IndexOf(nodes[0]), p)
+		default:
+			if sectPr := body.Child("w:sectPr"); sectPr != nil {
+				body.InsertChild(body.IndexOf(sectPr), p)
+			} else {
+				body.AppendChild(p)
+			}
+		}
+		previous = p
+	}
+
+	comparison := CompareParagraphs(oldParas, newParas)
+	for _, change := range comparison.Changes {
+		switch change.Type {
+		case ChangeDeleted:
+			insertDeleted(builder.deletedParagraph(oldParas[change.OldIndex]))
+		case ChangeInserted:
+			previous = nodes[change.NewIndex]
+			builder.markInserted(previous)
+		case ChangeModified, ChangeFormatted:
+			previous = nodes[change.NewIndex]
+			builder.markChanged(previous, change, &oldParas[change.OldIndex], &newParas[change.NewIndex])
+		default:
+			previous = nodes[change.NewIndex]
+		}
+	}
+
+	return pkg, nil
+}
+
+// revisionElements 使用修订ID的元素
+var revisionElements = map[string]bool{
+	"w:ins": true, "w:del": true, "w:moveFrom": true, "w:moveTo": true,
+	"w:rPrChange": true, "w:pPrChange": true, "w:sectPrChange": true,
+	"w:tblPrChange": true, "w:trPrChange": true, "w:tcPrChange": true,
+}
+
+// nextRevisionID 返回正文中已有修订之后可用的修订ID
+func nextRevisionID(body *Node) int {
+	next := 0
+	body.Walk(func(node, parent *Node) bool {
+		if revisionElements[node.Name] {
+			if id, err := strconv.Atoi(node.Attr("w:id")); err == nil && id >= next {
+				next = id + 1
+			}
+		}
+		return true
+	})
+	return next
+}
+
+// redlineBuilder 生成修订标记，负责分配修订ID
+type redlineBuilder struct {
+	author string
+	date   time.Time
+	nextID int
+}
+
+// revision 创建带作者、时间和ID的修订元素
+func (b *redlineBuilder) revision(name string) *Node {
+	id := b.nextID
+	b.nextID++
+	return NewNode(name,
+		"w:id", strconv.Itoa(id),
+		"w:author", b.author,
+		"w:date", b.date.UTC().Format("2006-01-02T15:04:05Z"))
+}
+
+// redlinePiece 段落中操作和格式都相同的一段文字
+type redlinePiece struct {
+	op       DiffOp
+	text     []rune
+	old, new runFormat
+}
+
+// markParagraphMark 将段落标记记录为插入或删除（name 为 w:ins 或 w:del）
+func (b *redlineBuilder) markParagraphMark(p *Node, name string) {
+	rPr := paragraphProperties(p).EnsureChild("w:rPr", pPrOrder)
+	if rPr.Child(name) == nil {
+		rPr.AppendChild(b.revision(name))
+	}
+}
+
+// deletedParagraph 用原文档的段落生成整段删除的段落
+func (b *redlineBuilder) deletedParagraph(para types.Paragraph) *Node {
+	p := newParagraphNode(para.Style)
+	b.markParagraphMark(p, "w:del")
+	_, formats := runeFormats(para)
+	runes := []rune(paragraphText(para))
+	for start := 0; start < len(runes); {
+		end := start + 1
+		for end < len(runes) && formats[end] == formats[start] {
+			end++
+		}
+		p.AppendChild(b.deletedRun(string(runes[start:end]), formats[start]))
+		start = end
+	}
+	return p
+}
+
+// deletedRun 创建包含删除文字的 w:del
+func (b *redlineBuilder) deletedRun(text string, format runFormat) *Node {
+	r := NewNode("w:r")
+	if rPr := runPropertiesNode(format); rPr != nil {
+		r.AppendChild(rPr)
+	}
+	appendRunText(r, text, "w:delText")
+	del := b.revision("w:del")
+	del.AppendChild(r)
+	return del
+}
+
+// markInserted 将修订后文档中新增的整个段落标记为插入
+func (b *redlineBuilder) markInserted(p *Node) {
+	b.markParagraphMark(p, "w:ins")
+	for _, run := range collectRuns(p, 0, -1) {
+		b.wrapRun(run, "w:ins")
+	}
+}
+
+// markChanged 在修订后文档的段落中标记与原段落的差异
+func (b *redlineBuilder) markChanged(p *Node, change ParagraphChange, oldPara, newPara *types.Paragraph) {
+	if oldPara.Style != newPara.Style {
+		pPr := paragraphProperties(p)
+		if pPr.Child("w:pPrChange") == nil {
+			oldPPr := pPr.Clone()
+			oldPPr.RemoveChildrenNamed("w:rPr")
+			oldPPr.RemoveChildrenNamed("w:sectPr")
+			oldPPr.RemoveChildrenNamed("w:pStyle")
+			if oldPara.Style != "" {
+				oldPPr.InsertOrdered(NewNode("w:pStyle", "w:val", oldPara.Style), pPrOrder)
+			}
+			pPrChange := b.revision("w:pPrChange")
+			pPrChange.AppendChild(oldPPr)
+			pPr.InsertOrdered(pPrChange, pPrOrder)
+		}
+	}
+
+	// 位置按修订后段落的最终文本计算，加入的 w:ins 和 w:del 不改变之后的位置
+	pos := 0
+	for _, piece := range redlinePieces(change, oldPara, newPara) {
+		length := len(piece.text)
+		switch piece.op {
+		case DiffDelete:
+			point := splitRunsAt(p, pos)
+			point.insert(b.deletedRun(string(piece.text), piece.old))
+		case DiffInsert:
+			for _, run := range collectRuns(p, pos, pos+length) {
+				b.wrapRun(run, "w:ins")
+			}
+			pos += length
+		default:
+			if piece.old != piece.new {
+				for _, run := range collectRuns(p, pos, pos+length) {
+					b.markFormatChange(run.node, piece.old)
+				}
+			}
+			pos += length
+		}
+	}
+}
+
+// wrapRun 将Run放入新的修订元素，已经在插入或删除修订中的Run不变
+func (b *redlineBuilder) wrapRun(run paragraphRun, name string) {
+	if run.revised {
+		return
+	}
+	revision := b.revision(name)
+	run.parent.ReplaceChild(run.node, revision)
+	revision.AppendChild(run.node)
+}
+
+// markFormatChange 在Run的格式中记录原来的格式
+func (b *redlineBuilder) markFormatChange(r *Node, old runFormat) {
+	rPr := runProperties(r)
+	if rPr.Child("w:rPrChange") != nil {
+		return
+	}
+	oldRPr := runPropertiesNode(old)
+	if oldRPr == nil {
+		oldRPr = NewNode("w:rPr")
+	}
+	rPrChange := b.revision("w:rPrChange")
+	rPrChange.AppendChild(oldRPr)
+	rPr.InsertOrdered(rPrChange, rPrOrder)
+}
+
+// paragraphRun 段落中的一个Run和它的父节点
+type paragraphRun struct {
+	node    *Node
+	parent  *Node
+	revised bool // 位于插入或删除修订中
+}
+
+// collectRuns 拆分Run后返回段落最终文本 [start, end) 范围内的Run，end 为负数时返回所有Run（包括没有文字的Run）
+//
+// 删除修订中的Run不计入位置，也不返回。
+func collectRuns(p *Node, start, end int) []paragraphRun {
+	if end >= 0 {
+		if start >= end {
+			return nil
+		}
+		splitRunsAt(p, end)
+		splitRunsAt(p, start)
+	}
+	var runs []paragraphRun
+	pos := 0
+	var walk func(n *Node, revised bool)
+	walk = func(n *Node, revised bool) {
+		for _, c := range n.Children {
+			switch {
+			case c.Name == "w:r":
+				length := runTextLength(c)
+				if end < 0 || (pos >= start && pos+length <= end && length > 0) {
+					runs = append(runs, paragraphRun{node: c, parent: n, revised: revised})
+				}
+				pos += length
+			case c.Name == "w:ins" || c.Name == "w:moveTo":
+				walk(c, true)
+			case containerElements[c.Name]:
+				walk(c, revised)
+			}
+		}
+	}
+	walk(p, false)
+	return runs
+}
+
+// redlinePieces 将段落变化拆分为操作和格式都相同的文字片段
+func redlinePieces(change ParagraphChange, oldPara, newPara *types.Paragraph) []redlinePiece {
+	var oldFormats, newFormats []runFormat
+	if oldPara != nil {
+		_, oldFormats = runeFormats(*oldPara)
+	}
+	if newPara != nil {
+		_, newFormats = runeFormats(*newPara)
+	}
+	formatAt := func(formats []runFormat, i int) runFormat {
+		if i >= 0 && i < len(formats) {
+			return formats[i]
+		}
+		return runFormat{}
+	}
+
+	var pieces []redlinePiece
+	add := func(op DiffOp, r rune, old, new runFormat) {
+		if n := len(pieces); n > 0 {
+			last := &pieces[n-1]
+			if last.op == op && last.old == old && last.new == new {
+				last.text = append(last.text, r)
+				return
+			}
+		}
+		pieces = append(pieces, redlinePiece{op: op, text: []rune{r}, old: old, new: new})
+	}
+
+	oldPos, newPos := 0, 0
+	for _, seg := range change.Segments {
+		for _, r := range seg.Text {
+			switch seg.Op {
+			case DiffEqual:
+				add(DiffEqual, r, formatAt(oldFormats, oldPos), formatAt(newFormats, newPos))
+				oldPos++
+				newPos++
+			case DiffDelete:
+				f := formatAt(oldFormats, oldPos)
+				add(DiffDelete, r, f, f)
+				oldPos++
+			case DiffInsert:
+				f := formatAt(newFormats, newPos)
+				add(DiffInsert, r, f, f)
+				newPos++
+			}
+		}
+	}
+
+	return pieces
+}
+
+// runPropertiesNode 将字符格式转换为 w:rPr 节点，没有任何格式时返回nil
+func runPropertiesNode(f runFormat) *Node {
+	if f == (runFormat{}) {
+		return nil
+	}
+
+	rPr := NewNode("w:rPr")
+	if f.FontName != "" {
+		rPr.InsertOrdered(NewNode("w:rFonts",
+			"w:ascii", f.FontName, "w:hAnsi", f.FontName, "w:eastAsia", f.FontName), rPrOrder)
+	}
+	if f.Bold {
+		rPr.InsertOrdered(NewNode("w:b"), rPrOrder)
+	}
+	if f.Italic {
+		rPr.InsertOrdered(NewNode("w:i"), rPrOrder)
+	}
+	if f.Color != "" {
+		rPr.InsertOrdered(NewNode("w:color", "w:val", f.Color), rPrOrder)
+	}
+	if f.FontSize > 0 {
+		rPr.InsertOrdered(NewNode("w:sz", "w:val", strconv.Itoa(f.FontSize)), rPrOrder)
+	}
+	if f.Underline {
+		rPr.InsertOrdered(NewNode("w:u", "w:val", "single"), rPrOrder)
+	}
+	return rPr
+}
@@ -0,0 +1,148 @@
+package document
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// buildTestRedline 用两个测试文档生成修订文档
+func buildTestRedline(t *testing.T, oldDoc, newDoc *Document) *Document {
+	t.Helper()
+	pkg, err := BuildRedline(packageParagraphs(oldDoc.Package), newDoc.Package, RedlineOptions{Author: "测试"})
+	if err != nil {
+		t.Fatalf("生成修订文档失败: %v", err)
+	}
+	return &Document{FileName: "修订.docx", Package: pkg, IsOpen: true}
+}
+
+func TestBuildRedlineRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new []string
+	}{
+		{"相同", []string{"第一段", "第二段"}, []string{"第一段", "第二段"}},
+		{"修改文字", []string{"今天天气很好"}, []string{"今天天气不错"}},
+		{"插入段落", []string{"开头", "结尾"}, []string{"开头", "中间", "结尾"}},
+		{"删除段落", []string{"开头", "中间", "结尾"}, []string{"开头", "结尾"}},
+		{"删除第一段", []string{"第一段", "第二段"}, []string{"第二段"}},
+		{"删除最后一段", []string{"第一段", "第二段"}, []string{"第一段"}},
+		{"在开头插入段落", []string{"原有"}, []string{"新的", "原有"}},
+		{"全部替换", []string{"甲乙丙", "丁戊"}, []string{"one", "two", "three"}},
+		{"混合修改", []string{"a b c", "保留", "删除这段", "d e"}, []string{"a x c", "保留", "d e f", "新增"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldDoc := newTestDocument(t, tt.old...)
+			newDoc := newTestDocument(t, tt.new...)
+			before := testBody(t, newDoc).String()
+
+			accepted := buildTestRedline(t, oldDoc, newDoc)
+			if _, err := accepted.AcceptAllRevisions(); err != nil {
+				t.Fatalf("接受所有修订失败: %v", err)
+			}
+			if got := paragraphTexts(accepted); !reflect.DeepEqual(got, tt.new) {
+				t.Errorf("接受所有修订后 %q, 期望 %q", got, tt.new)
+			}
+
+			rejected := buildTestRedline(t, oldDoc, newDoc)
+			if _, err := rejected.RejectAllRevisions(); err != nil {
+				t.Fatalf("拒绝所有修订失败: %v", err)
+			}
+			if got := paragraphTexts(rejected); !reflect.DeepEqual(got, tt.old) {
+				t.Errorf("拒绝所有修订后 %q, 期望 %q", got, tt.old)
+			}
+
+			if after := testBody(t, newDoc).String(); after != before {
+				t.Errorf("生成修订文档修改了修订后的文档")
+			}
+		})
+	}
+}
+
+func TestBuildRedlineRevisions(t *testing.T) {
+	oldDoc := newTestDocument(t, "保留", "删除", "旧文字")
+	newDoc := newTestDocument(t, "保留", "新文字", "插入")
+	redline := buildTestRedline(t, oldDoc, newDoc)
+	revisions, err := redline.Revisions()
+	if err != nil {
+		t.Fatalf("读取修订失败: %v", err)
+	}
+	counts := map[RevisionType]int{}
+	for _, rev := range revisions {
+		counts[rev.Type]++
+		if rev.Author != "测试" {
+			t.Errorf("修订作者 %q, 期望 %q", rev.Author, "测试")
+		}
+	}
+	if counts[RevisionInsert] == 0 || counts[RevisionDelete] == 0 {
+		t.Errorf("修订 %v 中缺少插入或删除", counts)
+	}
+}
+
+func TestBuildRedlineFormatChange(t *testing.T) {
+	oldDoc := newTestDocument(t, "加粗的文字")
+	newDoc := newTestDocument(t, "加粗的文字")
+	if err := newDoc.ApplyCharFormat(TextRange{EndOffset: 2}, CharFormat{Bold: true}, CharBold); err != nil {
+		t.Fatalf("设置格式失败: %v", err)
+	}
+
+	redline := buildTestRedline(t, oldDoc, newDoc)
+	if len(testBody(t, redline).Find("w:rPrChange")) == 0 {
+		t.Fatalf("格式变化没有记录为 w:rPrChange")
+	}
+	if _, err := redline.RejectAllRevisions(); err != nil {
+		t.Fatalf("拒绝所有修订失败: %v", err)
+	}
+	if n := len(testBody(t, redline).Find("w:b")); n != 0 {
+		t.Errorf("拒绝格式修订后仍有 %d 处加粗", n)
+	}
+}
+
+func TestBuildRedlineKeepsContent(t *testing.T) {
+	oldDoc := newTestDocument(t, "正文", "结尾")
+	newDoc := newTestDocument(t, "正文有修改", "结尾")
+	if _, err := newDoc.InsertFootnote(1, 2, "脚注内容"); err != nil {
+		t.Fatalf("插入脚注失败: %v", err)
+	}
+	body := testBody(t, newDoc)
+	table := NewNode("w:tbl")
+	cell := NewNode("w:tc")
+	cell.AppendChild(newParagraphNode(""))
+	row := NewNode("w:tr")
+	row.AppendChild(cell)
+	table.AppendChild(NewNode("w:tblPr"), NewNode("w:tblGrid"), row)
+	body.InsertChild(1, table)
+
+	redline := buildTestRedline(t, oldDoc, newDoc)
+	if len(testBody(t, redline).Find("w:tbl")) != 1 {
+		t.Errorf("修订文档中没有保留表格")
+	}
+	if len(testBody(t, redline).Find("w:footnoteReference")) != 1 {
+		t.Errorf("修订文档中没有保留脚注引用")
+	}
+	notes, err := redline.Notes(FootnoteKind)
+	if err != nil || len(notes) != 1 || notes[0].Text != "脚注内容" {
+		t.Errorf("修订文档的脚注 %+v (%v), 期望一个脚注", notes, err)
+	}
+}
+
+func TestCreateRedlineSameFile(t *testing.T) {
+	doc := newBodyTestDocument(t, `<w:p><w:r><w:rPr><w:rFonts w:ascii="Arial" w:eastAsia="宋体"/><w:color w:val="auto"/></w:rPr><w:t>正文</w:t></w:r>`+
+		`<w:hyperlink w:anchor="书签"><w:r><w:t>链接文字</w:t></w:r></w:hyperlink></w:p><w:p><w:r><w:t>结尾</w:t></w:r></w:p>`)
+	path := filepath.Join(t.TempDir(), "原文.docx")
+	if err := doc.Package.Save(path); err != nil {
+		t.Fatalf("保存测试文档失败: %v", err)
+	}
+
+	redline, err := NewManager().CreateRedline(CompareSource{Path: path}, CompareSource{Path: path}, RedlineOptions{})
+	if err != nil {
+		t.Fatalf("生成修订文档失败: %v", err)
+	}
+	if revisions, _ := redline.Revisions(); len(revisions) != 0 {
+		t.Errorf("比较同一个文件得到 %d 处修订: %+v", len(revisions), revisions)
+	}
+	if got := paragraphTexts(redline); !reflect.DeepEqual(got, []string{"正文链接文字", "结尾"}) {
+		t.Errorf("修订文档的段落 %q", got)
+	}
+}
@@ -0,0 +1,432 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RevisionType 修订类型
+type RevisionType int
+
+const (
+	RevisionInsert          RevisionType = iota // 插入的内容 w:ins
+	RevisionDelete                              // 删除的内容 w:del
+	RevisionMoveFrom                            // 移动的原位置 w:moveFrom
+	RevisionMoveTo                              // 移动的新位置 w:moveTo
+	RevisionFormat                              // 字符格式修改 w:rPrChange
+	RevisionParagraphFormat                     // 段落格式修改 w:pPrChange
+	RevisionParagraphInsert                     // 插入的段落标记
+	RevisionParagraphDelete                     // 删除的段落标记
+)
+
+// String 返回修订类型的中文名称
+func (t RevisionType) String() string {
+	switch t {
+	case RevisionInsert:
+		return "插入"
+	case RevisionDelete:
+		return "删除"
+	case RevisionMoveFrom:
+		return "移出"
+	case RevisionMoveTo:
+		return "移入"
+	case RevisionFormat:
+		return "格式"
+	case RevisionParagraphFormat:
+		return "段落格式"
+	case RevisionParagraphInsert:
+		return "插入段落"
+	case RevisionParagraphDelete:
+		return "删除段落"
+	}
+	return "未知"
+}
+
+// Revision 文档正文中的一处修订
+//
+// Revision 引用文档节点树中的元素，接受或拒绝任意修订后文档结构可能变化，
+// 需要重新调用 Revisions 获取修订列表。
+type Revision struct {
+	ID        string
+	Type      RevisionType
+	Author    string
+	Date      time.Time // 修订时间，文档未记录时为零值
+	Text      string    // 受影响的文字
+	Paragraph int       // 所在的顶层段落索引，位于表格等位置时为-1
+
+	node      *Node // 修订元素
+	parent    *Node // 修订元素的父节点
+	paragraph *Node // 修订所在的段落
+	container *Node // 段落所在的容器（正文、单元格等）
+}
+
+// Revisions 返回文档正文中的所有修订，按文档顺序排列
+//
+// 支持内容修订（插入、删除、移动）、字符格式修订、段落格式修订和段落标记修订，
+// 表格行列的修订等暂不支持。
+func (doc *Document) Revisions() ([]Revision, error) {
+	body, err := doc.body()
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make(map[*Node]int)
+	for i, p := range collectParagraphs(body) {
+		indexes[p] = i
+	}
+
+	scanner := &revisionScanner{indexes: indexes, containers: make(map[*Node]*Node)}
+	scanner.scan(body, nil)
+	return scanner.revisions, nil
+}
+
+// RevisionAuthors 返回所有修订作者，按名称排序
+func (doc *Document) RevisionAuthors() ([]string, error) {
+	revisions, err := doc.Revisions()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var authors []string
+	for _, rev := range revisions {
+		if !seen[rev.Author] {
+			seen[rev.Author] = true
+			authors = append(authors, rev.Author)
+		}
+	}
+	sort.Strings(authors)
+	return authors, nil
+}
+
+// revisionScanner 遍历正文收集修订
+type revisionScanner struct {
+	indexes    map[*Node]int
+	containers map[*Node]*Node
+	revisions  []Revision
+}
+
+// scan 递归扫描节点，paragraph 为当前所在的段落
+func (s *revisionScanner) scan(n, paragraph *Node) {
+	for _, c := range n.Children {
+		switch c.Name {
+		case "w:p":
+			s.containers[c] = n
+			s.scanParagraphMark(c)
+			s.scan(c, c)
+		case "w:ins", "w:del", "w:moveFrom", "w:moveTo":
+			if n.Name == "w:p" || containerElements[n.Name] {
+				var spans []TextSpan
+				collectSpans(c, TextSpan{}, &spans)
+				s.add(c, n, paragraph, contentRevisionTypes[c.Name], spansText(spans))
+			}
+			s.scan(c, paragraph)
+		case "w:r":
+			if rPr := c.Child("w:rPr"); rPr != nil {
+				if change := rPr.Child("w:rPrChange"); change != nil {
+					var spans []TextSpan
+					appendRunSpans(c, TextSpan{}, &spans)
+					s.add(change, rPr, paragraph, RevisionFormat, spansText(spans))
+				}
+			}
+		case "w:pPr", "w:sectPr":
+			// 段落属性在 scanParagraphMark 中处理
+		default:
+			if c.IsElement() {
+				s.scan(c, paragraph)
+			}
+		}
+	}
+}
+
+// contentRevisionTypes 内容修订元素对应的修订类型
+var contentRevisionTypes = map[string]RevisionType{
+	"w:ins":      RevisionInsert,
+	"w:del":      RevisionDelete,
+	"w:moveFrom": RevisionMoveFrom,
+	"w:moveTo":   RevisionMoveTo,
+}
+
+// scanParagraphMark 收集段落属性中的修订（段落格式和段落标记）
+func (s *revisionScanner) scanParagraphMark(p *Node) {
+	pPr := p.Child("w:pPr")
+	if pPr == nil {
+		return
+	}
+	if change := pPr.Child("w:pPrChange"); change != nil {
+		s.add(change, pPr, p, RevisionParagraphFormat, paragraphNodeText(p))
+	}
+	rPr := pPr.Child("w:rPr")
+	if rPr == nil {
+		return
+	}
+	for _, c := range rPr.Elements() {
+		switch c.Name {
+		case "w:ins", "w:moveTo":
+			s.add(c, rPr, p, RevisionParagraphInsert, paragraphNodeText(p))
+		case "w:del", "w:moveFrom":
+			s.add(c, rPr, p, RevisionParagraphDelete, paragraphNodeText(p))
+		}
+	}
+}
+
+// add 记录一处修订
+func (s *revisionScanner) add(node, parent, paragraph *Node, revType RevisionType, text string) {
+	rev := Revision{
+		ID:        node.Attr("w:id"),
+		Type:      revType,
+		Author:    node.Attr("w:author"),
+		Text:      text,
+		Paragraph: -1,
+		node:      node,
+		parent:    parent,
+		paragraph: paragraph,
+		container: s.containers[paragraph],
+	}
+	if date := node.Attr("w:date"); date != "" {
+		if t, err := time.Parse(time.RFC3339, date); err == nil {
+			rev.Date = t
+		}
+	}
+	if i, ok := s.indexes[paragraph]; ok {
+		rev.Paragraph = i
+	}
+	s.revisions = append(s.revisions, rev)
+}
+
+// spansText 拼接文字片段
+func spansText(spans []TextSpan) string {
+	var builder strings.Builder
+	for _, span := range spans {
+		builder.WriteString(span.Text)
+	}
+	return builder.String()
+}
+
+// AcceptRevision 接受单个修订
+func (doc *Document) AcceptRevision(rev Revision) error {
+	return doc.resolveRevision(rev, true)
+}
+
+// RejectRevision 拒绝单个修订
+func (doc *Document) RejectRevision(rev Revision) error {
+	return doc.resolveRevision(rev, false)
+}
+
+// AcceptRevisionsByAuthor 接受指定作者的所有修订，返回处理的修订数
+func (doc *Document) AcceptRevisionsByAuthor(author string) (int, error) {
+	return doc.resolveRevisions(true, func(rev Revision) bool { return rev.Author == author })
+}
+
+// RejectRevisionsByAuthor 拒绝指定作者的所有修订，返回处理的修订数
+func (doc *Document) RejectRevisionsByAuthor(author string) (int, error) {
+	return doc.resolveRevisions(false, func(rev Revision) bool { return rev.Author == author })
+}
+
+// AcceptAllRevisions 接受所有修订，返回处理的修订数
+func (doc *Document) AcceptAllRevisions() (int, error) {
+	return doc.resolveRevisions(true, func(Revision) bool { return true })
+}
+
+// RejectAllRevisions 拒绝所有修订，返回处理的修订数
+func (doc *Document) RejectAllRevisions() (int, error) {
+	return doc.resolveRevisions(false, func(Revision) bool { return true })
+}
+
+// resolveRevision 接受或拒绝单个修订
+func (doc *Document) resolveRevision(rev Revision, accept bool) error {
+	if rev.node == nil || rev.parent.IndexOf(rev.node) < 0 {
+		return fmt.Errorf("修订已不存在，请刷新修订列表")
+	}
+
+	applyRevision(rev, accept)
+	doc.finishRevisions()
+	log.Printf("已%s修订: %s %s", acceptVerb(accept), rev.Type, truncateText(rev.Text, 30))
+	return nil
+}
+
+// resolveRevisions 接受或拒绝所有满足条件的修订
+func (doc *Document) resolveRevisions(accept bool, match func(Revision) bool) (int, error) {
+	revisions, err := doc.Revisions()
+	if err != nil {
+		return 0, err
+	}
+
+	// 先处理内容和格式修订，再处理会合并段落的段落标记修订
+	var marks []Revision
+	count := 0
+	for _, rev := range revisions {
+		if !match(rev) {
+			continue
+		}
+		if rev.Type == RevisionParagraphInsert || rev.Type == RevisionParagraphDelete {
+			marks = append(marks, rev)
+			continue
+		}
+		applyRevision(rev, accept)
+		count++
+	}
+	for _, rev := range marks {
+		applyRevision(rev, accept)
+		count++
+	}
+
+	if count > 0 {
+		doc.finishRevisions()
+	}
+	log.Printf("已%s %d 处修订", acceptVerb(accept), count)
+	return count, nil
+}
+
+// finishRevisions 清理不再需要的移动范围标记并标记文档已修改
+func (doc *Document) finishRevisions() {
+	if body, err := doc.body(); err == nil {
+		removeOrphanMoveRanges(body)
+	}
+	doc.IsModified = true
+}
+
+// acceptVerb 返回日志中使用的动作名称
+func acceptVerb(accept bool) string {
+	if accept {
+		return "接受"
+	}
+	return "拒绝"
+}
+
+// applyRevision 在节点树上接受或拒绝修订
+func applyRevision(rev Revision, accept bool) {
+	switch rev.Type {
+	case RevisionInsert, RevisionMoveTo:
+		if accept {
+			rev.parent.ReplaceChild(rev.node, rev.node.Children...)
+		} else {
+			rev.parent.RemoveChild(rev.node)
+		}
+	case RevisionDelete, RevisionMoveFrom:
+		if accept {
+			rev.parent.RemoveChild(rev.node)
+		} else {
+			restoreDeletedText(rev.node)
+			rev.parent.ReplaceChild(rev.node, rev.node.Children...)
+		}
+	case RevisionFormat:
+		if !accept {
+			restoreProperties(rev.parent, rev.node.Child("w:rPr"), rPrOrder,
+				"w:ins", "w:del", "w:moveFrom", "w:moveTo")
+		}
+		rev.parent.RemoveChild(rev.node)
+	case RevisionParagraphFormat:
+		if !accept {
+			restoreProperties(rev.parent, rev.node.Child("w:pPr"), pPrOrder, "w:rPr", "w:sectPr")
+		}
+		rev.parent.RemoveChild(rev.node)
+	case RevisionParagraphInsert, RevisionParagraphDelete:
+		removeParagraphMark := accept == (rev.Type == RevisionParagraphDelete)
+		removeMarkRevision(rev)
+		if removeParagraphMark {
+			mergeWithNextParagraph(rev.container, rev.paragraph)
+		}
+	}
+}
+
+// restoreDeletedText 将删除修订中的 w:delText 恢复为普通文本
+func restoreDeletedText(n *Node) {
+	n.Walk(func(node, parent *Node) bool {
+		switch node.Name {
+		case "w:delText":
+			node.Name = "w:t"
+		case "w:delInstrText":
+			node.Name = "w:instrText"
+		}
+		return true
+	})
+}
+
+// restoreProperties 用修订前的属性替换当前属性，keep 中的元素保留不变
+func restoreProperties(current, old *Node, order []string, keep ...string) {
+	var kept []*Node
+	for _, c := range current.Elements() {
+		if indexOfString(keep, c.Name) >= 0 {
+			kept = append(kept, c)
+		}
+	}
+	current.Children = nil
+	if old != nil {
+		for _, c := range old.Elements() {
+			current.InsertOrdered(c, order)
+		}
+	}
+	for _, c := range kept {
+		current.InsertOrdered(c, order)
+	}
+}
+
+// removeMarkRevision 删除段落标记上的修订元素
+func removeMarkRevision(rev Revision) {
+	rPr := rev.parent
+	rPr.RemoveChild(rev.node)
+	if len(rPr.Elements()) == 0 {
+		if pPr := rev.paragraph.Child("w:pPr"); pPr != nil {
+			pPr.RemoveChild(rPr)
+		}
+	}
+}
+
+// mergeWithNextParagraph 删除段落标记：段落内容并入下一个段落，
+// 合并后的段落使用下一个段落的属性
+func mergeWithNextParagraph(container, p *Node) {
+	if container == nil || container.IndexOf(p) < 0 {
+		return
+	}
+
+	var next *Node
+	for _, c := range container.Children[container.IndexOf(p)+1:] {
+		if c.IsElement() {
+			next = c
+			break
+		}
+	}
+	if next == nil || next.Name != "w:p" {
+		// 后面没有可合并的段落，空段落直接删除
+		if len(paragraphContent(p)) == 0 && len(collectParagraphs(container)) > 1 {
+			container.RemoveChild(p)
+		}
+		return
+	}
+
+	position := 0
+	if next.Child("w:pPr") != nil {
+		position = 1
+	}
+	next.InsertChild(position, paragraphContent(p)...)
+	container.RemoveChild(p)
+}
+
+// paragraphContent 返回段落中除段落属性外的子节点
+func paragraphContent(p *Node) []*Node {
+	var content []*Node
+	for _, c := range p.Children {
+		if c.Name != "w:pPr" && c.IsElement() {
+			content = append(content, c)
+		}
+	}
+	return content
+}
+
+// removeOrphanMoveRanges 文档中不再有移动修订时删除移动范围标记
+func removeOrphanMoveRanges(body *Node) {
+	if len(body.Find("w:moveFrom")) > 0 || len(body.Find("w:moveTo")) > 0 {
+		return
+	}
+	body.Walk(func(node, parent *Node) bool {
+		for _, name := range []string{"w:moveFromRangeStart", "w:moveFromRangeEnd",
+			"w:moveToRangeStart", "w:moveToRangeEnd"} {
+			node.RemoveChildrenNamed(name)
+		}
+		return true
+	})
+}
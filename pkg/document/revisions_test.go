@@ -0,0 +1,166 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+const (
+	testIns = `w:id="1" w:author="甲" w:date="2024-01-02T03:04:05Z"`
+	testDel = `w:id="2" w:author="乙" w:date="2024-01-02T03:04:05Z"`
+)
+
+func TestResolveAllRevisions(t *testing.T) {
+	tests := []struct {
+		name             string
+		body             string
+		accepted, reject []string
+	}{
+		{
+			"插入文字",
+			`<w:p><w:r><w:t>前</w:t></w:r><w:ins ` + testIns + `><w:r><w:t>新</w:t></w:r></w:ins><w:r><w:t>后</w:t></w:r></w:p>`,
+			[]string{"前新后"}, []string{"前后"},
+		},
+		{
+			"删除文字",
+			`<w:p><w:r><w:t>前</w:t></w:r><w:del ` + testDel + `><w:r><w:delText>旧</w:delText></w:r></w:del><w:r><w:t>后</w:t></w:r></w:p>`,
+			[]string{"前后"}, []string{"前旧后"},
+		},
+		{
+			"替换文字",
+			`<w:p><w:del ` + testDel + `><w:r><w:delText>旧</w:delText></w:r></w:del><w:ins ` + testIns + `><w:r><w:t>新</w:t></w:r></w:ins></w:p>`,
+			[]string{"新"}, []string{"旧"},
+		},
+		{
+			"插入段落",
+			`<w:p><w:r><w:t>第一段</w:t></w:r></w:p>` +
+				`<w:p><w:pPr><w:rPr><w:ins ` + testIns + `/></w:rPr></w:pPr><w:ins ` + testIns + `><w:r><w:t>新段落</w:t></w:r></w:ins></w:p>` +
+				`<w:p><w:r><w:t>第二段</w:t></w:r></w:p>`,
+			[]string{"第一段", "新段落", "第二段"}, []string{"第一段", "第二段"},
+		},
+		{
+			"删除段落",
+			`<w:p><w:r><w:t>第一段</w:t></w:r></w:p>` +
+				`<w:p><w:pPr><w:rPr><w:del ` + testDel + `/></w:rPr></w:pPr><w:del ` + testDel + `><w:r><w:delText>旧段落</w:delText></w:r></w:del></w:p>` +
+				`<w:p><w:r><w:t>第二段</w:t></w:r></w:p>`,
+			[]string{"第一段", "第二段"}, []string{"第一段", "旧段落", "第二段"},
+		},
+		{
+			"删除最后一个段落",
+			`<w:p><w:r><w:t>第一段</w:t></w:r></w:p>` +
+				`<w:p><w:pPr><w:rPr><w:del ` + testDel + `/></w:rPr></w:pPr><w:del ` + testDel + `><w:r><w:delText>旧段落</w:delText></w:r></w:del></w:p>`,
+			[]string{"第一段"}, []string{"第一段", "旧段落"},
+		},
+		{
+			"合并段落",
+			`<w:p><w:pPr><w:rPr><w:del ` + testDel + `/></w:rPr></w:pPr><w:r><w:t>上</w:t></w:r></w:p>` +
+				`<w:p><w:r><w:t>下</w:t></w:r></w:p>`,
+			[]string{"上下"}, []string{"上", "下"},
+		},
+		{
+			"移动文字",
+			`<w:p><w:moveFrom ` + testDel + `><w:r><w:t>移动</w:t></w:r></w:moveFrom><w:r><w:t>文字</w:t></w:r>` +
+				`<w:moveTo ` + testIns + `><w:r><w:t>移动</w:t></w:r></w:moveTo></w:p>`,
+			[]string{"文字移动"}, []string{"移动文字"},
+		},
+		{
+			"超链接中的插入",
+			`<w:p><w:hyperlink><w:r><w:t>链接</w:t></w:r><w:ins ` + testIns + `><w:r><w:t>文字</w:t></w:r></w:ins></w:hyperlink></w:p>`,
+			[]string{"链接文字"}, []string{"链接"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := newBodyTestDocument(t, tt.body)
+			if _, err := doc.AcceptAllRevisions(); err != nil {
+				t.Fatalf("接受所有修订失败: %v", err)
+			}
+			if got := paragraphTexts(doc); !reflect.DeepEqual(got, tt.accepted) {
+				t.Errorf("接受所有修订后 %q, 期望 %q", got, tt.accepted)
+			}
+			if revisions, _ := doc.Revisions(); len(revisions) != 0 {
+				t.Errorf("接受后仍有 %d 处修订", len(revisions))
+			}
+
+			doc = newBodyTestDocument(t, tt.body)
+			if _, err := doc.RejectAllRevisions(); err != nil {
+				t.Fatalf("拒绝所有修订失败: %v", err)
+			}
+			if got := paragraphTexts(doc); !reflect.DeepEqual(got, tt.reject) {
+				t.Errorf("拒绝所有修订后 %q, 期望 %q", got, tt.reject)
+			}
+			if revisions, _ := doc.Revisions(); len(revisions) != 0 {
+				t.Errorf("拒绝后仍有 %d 处修订", len(revisions))
+			}
+		})
+	}
+}
+
+func TestResolveFormatRevisions(t *testing.T) {
+	body := `<w:p><w:pPr><w:pStyle w:val="Heading1"/><w:pPrChange ` + testDel + `><w:pPr><w:pStyle w:val="Normal"/></w:pPr></w:pPrChange></w:pPr>` +
+		`<w:r><w:rPr><w:b/><w:rPrChange ` + testIns + `><w:rPr><w:i/></w:rPr></w:rPrChange></w:rPr><w:t>文字</w:t></w:r></w:p>`
+
+	tests := []struct {
+		accept     bool
+		style, run string
+		missing    string
+	}{
+		{true, "Heading1", "w:b", "w:i"},
+		{false, "Normal", "w:i", "w:b"},
+	}
+	for _, tt := range tests {
+		doc := newBodyTestDocument(t, body)
+		if revisions, _ := doc.Revisions(); len(revisions) != 2 {
+			t.Fatalf("修订 %d 处, 期望 2 处", len(revisions))
+		}
+		var err error
+		if tt.accept {
+			_, err = doc.AcceptAllRevisions()
+		} else {
+			_, err = doc.RejectAllRevisions()
+		}
+		if err != nil {
+			t.Fatalf("%s所有修订失败: %v", acceptVerb(tt.accept), err)
+		}
+		p := doc.paragraphNodes()[0]
+		if style := p.Child("w:pPr").Child("w:pStyle").Attr("w:val"); style != tt.style {
+			t.Errorf("%s后段落样式 %q, 期望 %q", acceptVerb(tt.accept), style, tt.style)
+		}
+		rPr := p.Child("w:r").Child("w:rPr")
+		if rPr.Child(tt.run) == nil || rPr.Child(tt.missing) != nil {
+			t.Errorf("%s后字符格式 %s", acceptVerb(tt.accept), rPr)
+		}
+		if revisions, _ := doc.Revisions(); len(revisions) != 0 {
+			t.Errorf("%s后仍有 %d 处修订", acceptVerb(tt.accept), len(revisions))
+		}
+	}
+}
+
+func TestResolveRevisionsByAuthor(t *testing.T) {
+	body := `<w:p><w:ins ` + testIns + `><w:r><w:t>甲加</w:t></w:r></w:ins>` +
+		`<w:del ` + testDel + `><w:r><w:delText>乙删</w:delText></w:r></w:del><w:r><w:t>原文</w:t></w:r></w:p>`
+
+	doc := newBodyTestDocument(t, body)
+	authors, err := doc.RevisionAuthors()
+	if err != nil || !reflect.DeepEqual(authors, []string{"乙", "甲"}) {
+		t.Fatalf("修订作者 %q (%v)", authors, err)
+	}
+	if n, err := doc.RejectRevisionsByAuthor("甲"); err != nil || n != 1 {
+		t.Fatalf("拒绝作者甲的修订: %d (%v)", n, err)
+	}
+	if n, err := doc.AcceptRevisionsByAuthor("乙"); err != nil || n != 1 {
+		t.Fatalf("接受作者乙的修订: %d (%v)", n, err)
+	}
+	if got := paragraphTexts(doc); !reflect.DeepEqual(got, []string{"原文"}) {
+		t.Errorf("处理修订后 %q, 期望 %q", got, []string{"原文"})
+	}
+
+	doc = newBodyTestDocument(t, body)
+	revisions, _ := doc.Revisions()
+	if err := doc.AcceptRevision(revisions[0]); err != nil {
+		t.Fatalf("接受修订失败: %v", err)
+	}
+	if err := doc.AcceptRevision(revisions[0]); err == nil {
+		t.Errorf("再次接受已处理的修订没有返回错误")
+	}
+}
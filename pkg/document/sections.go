@@ -0,0 +1,320 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+)
+
+// SectionBreak 分节符类型，值为 w:type 的取值，表示节从哪里开始
+type SectionBreak string
+
+const (
+	SectionNextPage   SectionBreak = "nextPage"   // 下一页
+	SectionContinuous SectionBreak = "continuous" // 连续
+	SectionEvenPage   SectionBreak = "evenPage"   // 偶数页
+	SectionOddPage    SectionBreak = "oddPage"    // 奇数页
+)
+
+// String 返回分节符类型的中文名称
+func (b SectionBreak) String() string {
+	switch b {
+	case SectionContinuous:
+		return "连续"
+	case SectionEvenPage:
+		return "偶数页"
+	case SectionOddPage:
+		return "奇数页"
+	}
+	return "下一页"
+}
+
+// PaperSize 纸张大小，单位为缇
+type PaperSize struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+// PaperSizes 常用纸张大小（纵向）
+var PaperSizes = []PaperSize{
+	{"A4", 11906, 16838},
+	{"A3", 16838, 23811},
+	{"A5", 8391, 11906},
+	{"B5", 10319, 14572},
+	{"16开", 10433, 14742},
+	{"Letter", 12240, 15840},
+	{"Legal", 12240, 20160},
+}
+
+// PaperSizeName 返回纸张大小的名称，不是常用纸张时返回“自定义”
+func PaperSizeName(width, height int) string {
+	if width > height {
+		width, height = height, width
+	}
+	for _, size := range PaperSizes {
+		// 允许换算误差
+		if abs(size.Width-width) <= 20 && abs(size.Height-height) <= 20 {
+			return size.Name
+		}
+	}
+	return "自定义"
+}
+
+// abs 返回整数的绝对值
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// PageSetup 节的页面设置，单位为缇
+type PageSetup struct {
+	Width       int  // 纸张宽度，横向时宽度大于高度
+	Height      int  // 纸张高度
+	Landscape   bool // 横向
+	Top         int  // 上边距
+	Bottom      int  // 下边距
+	Left        int  // 左边距
+	Right       int  // 右边距
+	Gutter      int  // 装订线
+	Header      int  // 页眉距边界
+	Footer      int  // 页脚距边界
+	Columns     int  // 栏数
+	ColumnSpace int  // 栏间距
+}
+
+// DefaultPageSetup 默认页面设置：A4纵向，与中文版Word的默认边距一致
+var DefaultPageSetup = PageSetup{
+	Width: 11906, Height: 16838,
+	Top: 1440, Bottom: 1440, Left: 1800, Right: 1800,
+	Header: 851, Footer: 992,
+	Columns: 1, ColumnSpace: 425,
+}
+
+// TextWidth 返回版心宽度
+func (s PageSetup) TextWidth() int {
+	return s.Width - s.Left - s.Right - s.Gutter
+}
+
+// TextHeight 返回版心高度
+func (s PageSetup) TextHeight() int {
+	return s.Height - s.Top - s.Bottom
+}
+
+// Section 文档中的一节
+type Section struct {
+	Index          int          // 节的索引，从0开始
+	Break          SectionBreak // 节的开始方式
+	StartParagraph int          // 节的第一个段落
+	EndParagraph   int          // 节的最后一个段落（包含），节中没有段落时小于 StartParagraph
+	PageSetup
+}
+
+// Sections 返回文档的所有节
+func (doc *Document) Sections() ([]Section, error) {
+	body, err := doc.body()
+	if err != nil {
+		return nil, err
+	}
+	paragraphs := collectParagraphs(body)
+
+	var sections []Section
+	start := 0
+	for i, p := range paragraphs {
+		if pPr := p.Child("w:pPr"); pPr != nil {
+			if sectPr := pPr.Child("w:sectPr"); sectPr != nil {
+				sections = append(sections, newSection(len(sections), sectPr, start, i))
+				start = i + 1
+			}
+		}
+	}
+	sectPr := body.Child("w:sectPr")
+	if sectPr == nil {
+		sectPr = NewNode("w:sectPr")
+	}
+	return append(sections, newSection(len(sections), sectPr, start, len(paragraphs)-1)), nil
+}
+
+// newSection 根据 w:sectPr 创建节信息
+func newSection(index int, sectPr *Node, start, end int) Section {
+	section := Section{
+		Index:          index,
+		Break:          SectionNextPage,
+		StartParagraph: start,
+		EndParagraph:   end,
+		PageSetup:      sectionPageSetup(sectPr),
+	}
+	if t := sectPr.Child("w:type"); t != nil && t.Attr("w:val") != "" {
+		section.Break = SectionBreak(t.Attr("w:val"))
+	}
+	return section
+}
+
+// sectionPageSetup 读取 w:sectPr 中的页面设置，缺少的值使用默认页面设置
+func sectionPageSetup(sectPr *Node) PageSetup {
+	setup := DefaultPageSetup
+	readInt := func(n *Node, name string, target *int) {
+		if n == nil {
+			return
+		}
+		if value, err := strconv.Atoi(n.Attr(name)); err == nil {
+			*target = value
+		}
+	}
+	pgSz := sectPr.Child("w:pgSz")
+	readInt(pgSz, "w:w", &setup.Width)
+	readInt(pgSz, "w:h", &setup.Height)
+	if pgSz != nil {
+		setup.Landscape = pgSz.Attr("w:orient") == "landscape"
+	}
+	pgMar := sectPr.Child("w:pgMar")
+	readInt(pgMar, "w:top", &setup.Top)
+	readInt(pgMar, "w:bottom", &setup.Bottom)
+	readInt(pgMar, "w:left", &setup.Left)
+	readInt(pgMar, "w:right", &setup.Right)
+	readInt(pgMar, "w:gutter", &setup.Gutter)
+	readInt(pgMar, "w:header", &setup.Header)
+	readInt(pgMar, "w:footer", &setup.Footer)
+	cols := sectPr.Child("w:cols")
+	readInt(cols, "w:num", &setup.Columns)
+	readInt(cols, "w:space", &setup.ColumnSpace)
+	// 上下边距为负数表示正文可以与页眉页脚重叠，按绝对值计算版心
+	setup.Top, setup.Bottom = abs(setup.Top), abs(setup.Bottom)
+	return setup
+}
+
+// SetPageSetup 设置节的页面设置
+func (doc *Document) SetPageSetup(section int, setup PageSetup) error {
+	sections, err := doc.sectionNodes(true)
+	if err != nil {
+		return err
+	}
+	if section < 0 || section >= len(sections) {
+		return fmt.Errorf("节索引超出范围: %d", section+1)
+	}
+	if err := setup.validate(); err != nil {
+		return err
+	}
+	writePageSetup(sections[section], setup)
+	doc.IsModified = true
+	log.Printf("已设置第 %d 节的页面: %s %dx%d", section+1, PaperSizeName(setup.Width, setup.Height), setup.Width, setup.Height)
+	return nil
+}
+
+// validate 检查页面设置是否有效
+func (s PageSetup) validate() error {
+	if s.Width <= 0 || s.Height <= 0 || s.Width > 31680 || s.Height > 31680 {
+		return fmt.Errorf("无效的纸张大小: %dx%d", s.Width, s.Height)
+	}
+	if s.Top < 0 || s.Bottom < 0 || s.Left < 0 || s.Right < 0 || s.Gutter < 0 || s.Header < 0 || s.Footer < 0 {
+		return fmt.Errorf("页边距不能为负数")
+	}
+	if s.TextWidth() <= 0 || s.TextHeight() <= 0 {
+		return fmt.Errorf("页边距过大，没有剩余的版心")
+	}
+	if s.Columns < 1 || s.Columns > 45 {
+		return fmt.Errorf("无效的栏数: %d", s.Columns)
+	}
+	if s.Columns > 1 && s.ColumnSpace*(s.Columns-1) >= s.TextWidth() {
+		return fmt.Errorf("栏间距过大")
+	}
+	return nil
+}
+
+// writePageSetup 将页面设置写入 w:sectPr
+func writePageSetup(sectPr *Node, setup PageSetup) {
+	pgSz := sectPr.EnsureChild("w:pgSz", sectPrOrder)
+	pgSz.SetAttr("w:w", strconv.Itoa(setup.Width))
+	pgSz.SetAttr("w:h", strconv.Itoa(setup.Height))
+	if setup.Landscape {
+		pgSz.SetAttr("w:orient", "landscape")
+	} else {
+		pgSz.RemoveAttr("w:orient")
+	}
+
+	pgMar := sectPr.EnsureChild("w:pgMar", sectPrOrder)
+	for _, attr := range []struct {
+		name  string
+		value int
+	}{
+		{"w:top", setup.Top}, {"w:right", setup.Right}, {"w:bottom", setup.Bottom}, {"w:left", setup.Left},
+		{"w:header", setup.Header}, {"w:footer", setup.Footer}, {"w:gutter", setup.Gutter},
+	} {
+		pgMar.SetAttr(attr.name, strconv.Itoa(attr.value))
+	}
+
+	cols := sectPr.EnsureChild("w:cols", sectPrOrder)
+	cols.RemoveChildrenNamed("w:col")
+	cols.RemoveAttr("w:equalWidth")
+	if setup.Columns > 1 {
+		cols.SetAttr("w:num", strconv.Itoa(setup.Columns))
+	} else {
+		cols.RemoveAttr("w:num")
+	}
+	cols.SetAttr("w:space", strconv.Itoa(setup.ColumnSpace))
+}
+
+// InsertSectionBreak 在段落之后插入分节符，之后的内容成为新的一节，kind 为新节的开始方式
+//
+// 分节符之前的节沿用原来的页面设置和页眉页脚。
+func (doc *Document) InsertSectionBreak(paragraph int, kind SectionBreak) error {
+	switch kind {
+	case SectionNextPage, SectionContinuous, SectionEvenPage, SectionOddPage:
+	default:
+		return fmt.Errorf("无效的分节符类型: %s", kind)
+	}
+	paragraphs := doc.paragraphNodes()
+	if paragraph < 0 || paragraph >= len(paragraphs) {
+		return fmt.Errorf("段落索引超出范围: %d", paragraph+1)
+	}
+	p := paragraphs[paragraph]
+	if pPr := p.Child("w:pPr"); pPr != nil && pPr.Child("w:sectPr") != nil {
+		return fmt.Errorf("段落 %d 之后已有分节符", paragraph+1)
+	}
+
+	// 找到段落所在节的属性
+	sections, err := doc.sectionNodes(true)
+	if err != nil {
+		return err
+	}
+	current := sections[len(sections)-1]
+	for i := paragraph + 1; i < len(paragraphs); i++ {
+		if pPr := paragraphs[i].Child("w:pPr"); pPr != nil {
+			if sectPr := pPr.Child("w:sectPr"); sectPr != nil {
+				current = sectPr
+				break
+			}
+		}
+	}
+
+	// 新的节属性描述分节符之前的部分，原来的节属性描述之后的部分
+	before := current.Clone()
+	paragraphProperties(p).InsertOrdered(before, pPrOrder)
+	current.EnsureChild("w:type", sectPrOrder).SetAttr("w:val", string(kind))
+
+	doc.IsModified = true
+	log.Printf("已在段落 %d 之后插入分节符（%s）", paragraph+1, kind)
+	return nil
+}
+
+// DeleteSectionBreak 删除节末尾的分节符，该节与下一节合并并使用下一节的设置
+func (doc *Document) DeleteSectionBreak(section int) error {
+	sections, err := doc.sectionNodes(true)
+	if err != nil {
+		return err
+	}
+	if section < 0 || section >= len(sections)-1 {
+		return fmt.Errorf("第 %d 节之后没有分节符", section+1)
+	}
+	sectPr := sections[section]
+	for _, p := range doc.paragraphNodes() {
+		if pPr := p.Child("w:pPr"); pPr != nil && pPr.RemoveChild(sectPr) {
+			doc.IsModified = true
+			log.Printf("已删除第 %d 节的分节符", section+1)
+			return nil
+		}
+	}
+	return fmt.Errorf("找不到第 %d 节的分节符", section+1)
+}
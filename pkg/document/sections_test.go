@@ -0,0 +1,187 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPaperSizeName(t *testing.T) {
+	tests := []struct {
+		width, height int
+		want          string
+	}{
+		{11906, 16838, "A4"},
+		{16838, 11906, "A4"},
+		{11910, 16830, "A4"},
+		{12240, 15840, "Letter"},
+		{10000, 10000, "自定义"},
+	}
+	for _, tt := range tests {
+		if got := PaperSizeName(tt.width, tt.height); got != tt.want {
+			t.Errorf("PaperSizeName(%d, %d) = %q, 期望 %q", tt.width, tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestSections(t *testing.T) {
+	doc := newBodyTestDocument(t, sectionBreakBody+`<w:p><w:r><w:t>第三段</w:t></w:r></w:p>`)
+	sectPr := testBody(t, doc).Child("w:sectPr")
+	sectPr.EnsureChild("w:type", sectPrOrder).SetAttr("w:val", string(SectionContinuous))
+	sectPr.EnsureChild("w:cols", sectPrOrder).SetAttr("w:num", "2")
+
+	sections, err := doc.Sections()
+	if err != nil {
+		t.Fatalf("读取节失败: %v", err)
+	}
+	if len(sections) != 2 {
+		t.Fatalf("文档有 %d 节, 期望 2 节", len(sections))
+	}
+	first := sections[0]
+	if first.Break != SectionNextPage || first.StartParagraph != 0 || first.EndParagraph != 0 || first.Columns != 1 {
+		t.Errorf("第一节 %+v", first)
+	}
+	second := sections[1]
+	if second.Index != 1 || second.Break != SectionContinuous || second.StartParagraph != 1 || second.EndParagraph != 2 || second.Columns != 2 {
+		t.Errorf("第二节 %+v", second)
+	}
+}
+
+func TestSectionPageSetup(t *testing.T) {
+	sectPr, err := ParseXML([]byte(`<w:sectPr><w:pgSz w:w="16838" w:h="11906" w:orient="landscape"/>` +
+		`<w:pgMar w:top="-1000" w:bottom="800" w:left="1000" w:right="1000" w:gutter="200"/><w:cols w:num="3" w:space="720"/></w:sectPr>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PageSetup{
+		Width: 16838, Height: 11906, Landscape: true,
+		Top: 1000, Bottom: 800, Left: 1000, Right: 1000, Gutter: 200,
+		Header: DefaultPageSetup.Header, Footer: DefaultPageSetup.Footer,
+		Columns: 3, ColumnSpace: 720,
+	}
+	if got := sectionPageSetup(sectPr); got != want {
+		t.Errorf("页面设置 %+v, 期望 %+v", got, want)
+	}
+	if got := sectionPageSetup(NewNode("w:sectPr")); got != DefaultPageSetup {
+		t.Errorf("空节属性的页面设置 %+v", got)
+	}
+}
+
+func TestWritePageSetup(t *testing.T) {
+	sectPr, err := ParseXML([]byte(`<w:sectPr><w:headerReference w:type="default" r:id="rId5"/>` +
+		`<w:cols w:equalWidth="0" w:num="2"><w:col w:w="3000"/><w:col w:w="4000"/></w:cols><w:docGrid w:linePitch="312"/></w:sectPr>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	setup := DefaultPageSetup
+	setup.Width, setup.Height, setup.Landscape = 16838, 11906, true
+	writePageSetup(sectPr, setup)
+	if got := sectionPageSetup(sectPr); got != setup {
+		t.Errorf("读回的页面设置 %+v, 期望 %+v", got, setup)
+	}
+	want := []string{"w:headerReference", "w:pgSz", "w:pgMar", "w:cols", "w:docGrid"}
+	if got := elementNames(sectPr); !reflect.DeepEqual(got, want) {
+		t.Errorf("节属性的元素 %q, 期望 %q", got, want)
+	}
+	// 设置为一栏时删除自定义栏宽
+	if cols := sectPr.Child("w:cols"); cols.String() != `<w:cols w:space="425"/>` {
+		t.Errorf("分栏设置 %s", cols)
+	}
+
+	setup.Landscape = false
+	writePageSetup(sectPr, setup)
+	if sectPr.Child("w:pgSz").Attr("w:orient") != "" {
+		t.Errorf("改为纵向后仍有方向属性: %s", sectPr.Child("w:pgSz"))
+	}
+}
+
+func TestSetPageSetupErrors(t *testing.T) {
+	doc := newTestDocument(t, "正文")
+	modify := func(f func(s *PageSetup)) PageSetup {
+		setup := DefaultPageSetup
+		f(&setup)
+		return setup
+	}
+	tests := []struct {
+		name    string
+		section int
+		setup   PageSetup
+	}{
+		{"节索引超出范围", 1, DefaultPageSetup},
+		{"无效的纸张大小", 0, modify(func(s *PageSetup) { s.Width = 0 })},
+		{"负的页边距", 0, modify(func(s *PageSetup) { s.Left = -1 })},
+		{"没有版心", 0, modify(func(s *PageSetup) { s.Left, s.Right = 6000, 6000 })},
+		{"无效的栏数", 0, modify(func(s *PageSetup) { s.Columns = 0 })},
+		{"栏间距过大", 0, modify(func(s *PageSetup) { s.Columns, s.ColumnSpace = 3, 5000 })},
+	}
+	for _, tt := range tests {
+		if err := doc.SetPageSetup(tt.section, tt.setup); err == nil {
+			t.Errorf("%s没有返回错误", tt.name)
+		}
+	}
+	if doc.IsModified {
+		t.Errorf("设置失败后文档被标记为已修改")
+	}
+}
+
+func TestInsertSectionBreak(t *testing.T) {
+	doc := newTestDocument(t, "第一段", "第二段", "第三段")
+	if _, err := doc.AddHeaderFooter(0, HeaderKind, HeaderFooterDefault, "页眉"); err != nil {
+		t.Fatalf("添加页眉失败: %v", err)
+	}
+	if err := doc.InsertSectionBreak(0, SectionContinuous); err != nil {
+		t.Fatalf("插入分节符失败: %v", err)
+	}
+	if err := doc.InsertSectionBreak(1, SectionOddPage); err != nil {
+		t.Fatalf("插入分节符失败: %v", err)
+	}
+
+	sections, _ := doc.Sections()
+	var got [][3]interface{}
+	for _, s := range sections {
+		got = append(got, [3]interface{}{s.Break, s.StartParagraph, s.EndParagraph})
+	}
+	want := [][3]interface{}{
+		{SectionNextPage, 0, 0},
+		{SectionContinuous, 1, 1},
+		{SectionOddPage, 2, 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("插入分节符后的节 %v, 期望 %v", got, want)
+	}
+	// 分节符之前的节沿用原来的页面设置和页眉
+	nodes, _ := doc.sectionNodes(false)
+	for i, sectPr := range nodes {
+		if sectPr.Child("w:headerReference") == nil || sectPr.Child("w:pgMar") == nil {
+			t.Errorf("第 %d 节没有沿用原来的设置: %s", i+1, sectPr)
+		}
+	}
+	if pPr := doc.paragraphNodes()[0].Child("w:pPr"); elementNames(pPr)[len(elementNames(pPr))-1] != "w:sectPr" {
+		t.Errorf("节属性的位置 %s", pPr)
+	}
+
+	if err := doc.InsertSectionBreak(0, SectionNextPage); err == nil {
+		t.Errorf("在已有分节符的段落后插入没有返回错误")
+	}
+	if err := doc.InsertSectionBreak(2, "page"); err == nil {
+		t.Errorf("无效的分节符类型没有返回错误")
+	}
+	if err := doc.InsertSectionBreak(3, SectionNextPage); err == nil {
+		t.Errorf("段落索引超出范围没有返回错误")
+	}
+}
+
+func TestDeleteSectionBreak(t *testing.T) {
+	doc := newBodyTestDocument(t, sectionBreakBody)
+	if err := doc.DeleteSectionBreak(1); err == nil {
+		t.Errorf("删除最后一节的分节符没有返回错误")
+	}
+	if err := doc.DeleteSectionBreak(0); err != nil {
+		t.Fatalf("删除分节符失败: %v", err)
+	}
+	if got := doc.SectionCount(); got != 1 {
+		t.Errorf("删除后有 %d 节, 期望 1 节", got)
+	}
+	if got := paragraphTexts(doc); !reflect.DeepEqual(got, []string{"第一节", "第二节"}) {
+		t.Errorf("删除分节符后的段落 %q", got)
+	}
+}
@@ -0,0 +1,537 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SplitMode 拆分文档的位置
+type SplitMode int
+
+const (
+	SplitByHeading   SplitMode = iota // 在指定级别及以上的每个标题之前拆分
+	SplitByPageBreak                  // 在每个手动分页符处拆分
+	SplitBySection                    // 在每个分节符处拆分
+)
+
+// String 返回拆分方式的中文名称
+func (m SplitMode) String() string {
+	switch m {
+	case SplitByPageBreak:
+		return "分页符"
+	case SplitBySection:
+		return "分节符"
+	}
+	return "标题"
+}
+
+// maxSplitTitleLength 用作文件名的部分标题的最大字数
+const maxSplitTitleLength = 40
+
+// contentRelationshipTypes 正文内容引用的关系类型（关系类型的最后一段），拆分后没有被引用的关系连同部件一起删除
+var contentRelationshipTypes = map[string]bool{
+	"image": true, "hyperlink": true, "header": true, "footer": true, "chart": true,
+	"oleObject": true, "package": true, "video": true, "audio": true, "media": true, "control": true,
+	"diagramData": true, "diagramLayout": true, "diagramQuickStyle": true, "diagramColors": true, "diagramDrawing": true,
+}
+
+// SplitOptions 拆分文档的选项
+type SplitOptions struct {
+	Mode     SplitMode
+	Level    int                   // 按标题拆分时的标题级别，1到9，0表示1
+	Progress func(done, total int) // 每生成一个部分后调用，可以为nil
+}
+
+// level 返回按标题拆分的级别
+func (o SplitOptions) level() int {
+	if o.Level < 1 || o.Level > bodyTextLevel {
+		return 1
+	}
+	return o.Level
+}
+
+// SplitPart 拆分得到的一个部分
+type SplitPart struct {
+	Title   string   // 部分的标题：第一个标题或第一个非空段落的文字
+	Package *Package // 只包含这一部分正文的完整文档包
+}
+
+// Split 将文档拆分为多个部分，文档本身不变
+//
+// 每个部分是文档包的副本，只保留这一部分的正文和结束这部分内容的节属性，
+// 样式、编号和设置完整保留；没有被这部分引用的图片、超链接、页眉页脚、脚注尾注和批注被删除。
+func (doc *Document) Split(opts SplitOptions) ([]SplitPart, error) {
+	body, err := doc.body()
+	if err != nil {
+		return nil, err
+	}
+	source := body.Clone()
+	finalSectPr := source.Child("w:sectPr")
+	if finalSectPr == nil {
+		finalSectPr = NewNode("w:sectPr")
+	}
+	var blocks []*Node
+	for _, c := range source.Children {
+		if c.Name != "w:sectPr" {
+			blocks = append(blocks, c)
+		}
+	}
+
+	styleLevels := doc.styleOutlineLevels()
+	var starts []int
+	switch opts.Mode {
+	case SplitByHeading:
+		level := opts.level()
+		for i, block := range blocks {
+			if block.Name != "w:p" {
+				continue
+			}
+			if l := paragraphOutlineLevel(block, styleLevels); l < level && strings.TrimSpace(paragraphNodeText(block)) != "" {
+				starts = append(starts, i)
+			}
+		}
+	case SplitByPageBreak:
+		blocks, starts = splitAtPageBreaks(blocks)
+	case SplitBySection:
+		for i, block := range blocks {
+			if blockSectPr(block) != nil && i+1 < len(blocks) {
+				starts = append(starts, i+1)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("未知的拆分方式: %d", opts.Mode)
+	}
+
+	// 第一个拆分位置之前的内容也是一个部分
+	if len(starts) == 0 || starts[0] != 0 {
+		starts = append([]int{0}, starts...)
+	}
+	var ranges [][2]int
+	for i, start := range starts {
+		end := len(blocks)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		if start < end && hasContent(blocks[start:end]) {
+			ranges = append(ranges, [2]int{start, end})
+		}
+	}
+	if len(ranges) < 2 {
+		return nil, fmt.Errorf("文档中没有可以拆分的%s", opts.Mode)
+	}
+
+	inherited := inheritedHeaderFooters(blocks, finalSectPr)
+	parts := make([]SplitPart, 0, len(ranges))
+	for i, r := range ranges {
+		part, err := doc.splitPart(blocks, r[0], r[1], finalSectPr, inherited)
+		if err != nil {
+			return nil, err
+		}
+		part.Title = splitPartTitle(blocks[r[0]:r[1]], styleLevels)
+		if part.Title == "" {
+			part.Title = fmt.Sprintf("第%d部分", i+1)
+		}
+		parts = append(parts, part)
+		if opts.Progress != nil {
+			opts.Progress(i+1, len(ranges))
+		}
+	}
+	return parts, nil
+}
+
+// splitPart 创建只包含 blocks[start:end] 的文档包
+func (doc *Document) splitPart(blocks []*Node, start, end int, finalSectPr *Node, inherited map[*Node][]*Node) (SplitPart, error) {
+	pkg := doc.Package.Clone()
+	body, err := pkg.Body()
+	if err != nil {
+		return SplitPart{}, err
+	}
+
+	body.Children = nil
+	for _, block := range blocks[start:end] {
+		body.AppendChild(block.Clone())
+	}
+	// 部分的最后一个段落结束一节时，它的节属性成为文档的节属性；否则使用这部分内容所在节的节属性
+	var sectPr *Node
+	if last := body.Children[len(body.Children)-1]; blockSectPr(last) != nil {
+		sectPr = blockSectPr(last)
+		last.Child("w:pPr").RemoveChild(sectPr)
+	} else {
+		sectPr = nextSectPr(blocks[end:], finalSectPr).Clone()
+	}
+	body.AppendChild(sectPr)
+
+	// 页眉页脚从前面的节继承，部分中的第一节需要明确引用继承的页眉页脚
+	first := sectPr
+	for _, p := range body.Children {
+		if s := blockSectPr(p); s != nil {
+			first = s
+			break
+		}
+	}
+	for _, ref := range inherited[nextSectPr(blocks[start:], finalSectPr)] {
+		if !hasHeaderFooterReference(first, ref.Name, ref.Attr("w:type")) {
+			first.InsertOrdered(ref.Clone(), sectPrOrder)
+		}
+	}
+
+	part := &Document{Package: pkg}
+	if err := part.pruneUnused(); err != nil {
+		return SplitPart{}, err
+	}
+	return SplitPart{Package: pkg}, nil
+}
+
+// splitAtPageBreaks 在手动分页符处拆开段落，返回拆分后的正文块和每个部分开始的位置
+//
+// 分页符在段落中间时段落被拆为两个段落，分页符本身被删除；段前分页的段落从新的部分开始。
+// 只处理段落中直接的文字块里的分页符。
+func splitAtPageBreaks(blocks []*Node) ([]*Node, []int) {
+	var result []*Node
+	var starts []int
+	for _, block := range blocks {
+		if block.Name != "w:p" {
+			result = append(result, block)
+			continue
+		}
+		if pPr := block.Child("w:pPr"); pPr != nil && onOffValue(pPr.Child("w:pageBreakBefore")) {
+			starts = append(starts, len(result))
+		}
+		p := block
+		for {
+			before, after, ok := splitParagraphAtPageBreak(p)
+			if !ok {
+				break
+			}
+			if hasContent([]*Node{before}) {
+				result = append(result, before)
+			}
+			starts = append(starts, len(result))
+			p = after
+		}
+		if hasContent([]*Node{p}) || blockSectPr(p) != nil {
+			result = append(result, p)
+		}
+	}
+	return result, starts
+}
+
+// splitParagraphAtPageBreak 在段落的第一个分页符处将段落拆为两个段落，没有分页符时 ok 为假
+func splitParagraphAtPageBreak(p *Node) (before, after *Node, ok bool) {
+	for i, c := range p.Children {
+		if c.Name != "w:r" {
+			continue
+		}
+		for j, rc := range c.Children {
+			if rc.Name != "w:br" || rc.Attr("w:type") != "page" {
+				continue
+			}
+			before = p.Clone()
+			before.Children = before.Children[:i+1]
+			before.Children[i].Children = before.Children[i].Children[:j]
+			// 节属性属于段落的最后一部分
+			if pPr := before.Child("w:pPr"); pPr != nil {
+				pPr.RemoveChildrenNamed("w:sectPr")
+			}
+
+			after = p.Clone()
+			var children []*Node
+			if pPr := after.Child("w:pPr"); pPr != nil {
+				pPr.RemoveChildrenNamed("w:pageBreakBefore")
+				children = append(children, pPr)
+			}
+			run := after.Children[i]
+			var runChildren []*Node
+			if rPr := run.Child("w:rPr"); rPr != nil {
+				runChildren = append(runChildren, rPr)
+			}
+			run.Children = append(runChildren, run.Children[j+1:]...)
+			after.Children = append(append(children, run), after.Children[i+1:]...)
+			return before, after, true
+		}
+	}
+	return nil, nil, false
+}
+
+// blockSectPr 返回结束一节的段落中的节属性，其它正文块返回nil
+func blockSectPr(block *Node) *Node {
+	if block.Name != "w:p" {
+		return nil
+	}
+	if pPr := block.Child("w:pPr"); pPr != nil {
+		return pPr.Child("w:sectPr")
+	}
+	return nil
+}
+
+// nextSectPr 返回 blocks 中第一个结束一节的段落的节属性，没有时返回 finalSectPr
+func nextSectPr(blocks []*Node, finalSectPr *Node) *Node {
+	for _, block := range blocks {
+		if sectPr := blockSectPr(block); sectPr != nil {
+			return sectPr
+		}
+	}
+	return finalSectPr
+}
+
+// hasContent 判断正文块中是否有文字、表格、图片或其它对象
+func hasContent(blocks []*Node) bool {
+	for _, block := range blocks {
+		if block.Name != "w:p" {
+			return true
+		}
+		if strings.TrimSpace(paragraphNodeText(block)) != "" {
+			return true
+		}
+		for _, name := range []string{"w:drawing", "w:pict", "w:object"} {
+			if len(block.Find(name)) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inheritedHeaderFooters 返回每一节从前面的节继承的页眉页脚引用
+//
+// 节没有引用某种页眉页脚时沿用前一节的，拆分后前面的节不存在，需要把继承的引用复制过来。
+func inheritedHeaderFooters(blocks []*Node, finalSectPr *Node) map[*Node][]*Node {
+	inherited := make(map[*Node][]*Node)
+	var current []*Node
+	visit := func(sectPr *Node) {
+		inherited[sectPr] = append([]*Node(nil), current...)
+		for _, c := range sectPr.Children {
+			if c.Name != "w:headerReference" && c.Name != "w:footerReference" {
+				continue
+			}
+			replaced := false
+			for i, ref := range current {
+				if ref.Name == c.Name && ref.Attr("w:type") == c.Attr("w:type") {
+					current[i], replaced = c, true
+				}
+			}
+			if !replaced {
+				current = append(current, c)
+			}
+		}
+	}
+	for _, block := range blocks {
+		if sectPr := blockSectPr(block); sectPr != nil {
+			visit(sectPr)
+		}
+	}
+	visit(finalSectPr)
+	return inherited
+}
+
+// hasHeaderFooterReference 判断节属性中是否有指定类型的页眉或页脚引用
+func hasHeaderFooterReference(sectPr *Node, name, refType string) bool {
+	for _, ref := range sectPr.ChildrenNamed(name) {
+		if ref.Attr("w:type") == refType {
+			return true
+		}
+	}
+	return false
+}
+
+// splitPartTitle 返回部分的标题：第一个标题段落的文字，没有标题时为第一个非空段落的文字
+func splitPartTitle(blocks []*Node, styleLevels map[string]int) string {
+	var first string
+	for _, block := range blocks {
+		for _, p := range collectParagraphs(&Node{Children: []*Node{block}}) {
+			text := strings.Join(strings.Fields(paragraphNodeText(p)), " ")
+			if text == "" {
+				continue
+			}
+			if paragraphOutlineLevel(p, styleLevels) < bodyTextLevel {
+				return truncateTitle(text)
+			}
+			if first == "" {
+				first = text
+			}
+		}
+	}
+	return truncateTitle(first)
+}
+
+// truncateTitle 截断过长的标题
+func truncateTitle(text string) string {
+	if utf8.RuneCountInString(text) <= maxSplitTitleLength {
+		return text
+	}
+	return string([]rune(text)[:maxSplitTitleLength])
+}
+
+// pruneUnused 删除正文中没有引用的脚注尾注、批注、不成对的书签，以及没有引用的图片、超链接、页眉页脚等关系和部件
+func (doc *Document) pruneUnused() error {
+	body, err := doc.body()
+	if err != nil {
+		return err
+	}
+	pkg := doc.Package
+	mainPart := pkg.MainPartName()
+
+	for _, kind := range []NoteKind{FootnoteKind, EndnoteKind} {
+		root, err := doc.notesRoot(kind, false)
+		if err != nil {
+			return err
+		}
+		if root == nil {
+			continue
+		}
+		spec := kind.spec()
+		used := make(map[string]bool)
+		for _, ref := range body.Find(spec.reference) {
+			used[ref.Attr("w:id")] = true
+		}
+		for _, note := range root.ChildrenNamed(spec.element) {
+			// 分隔符注释保留
+			if t := note.Attr("w:type"); t != "" && t != "normal" {
+				continue
+			}
+			if !used[note.Attr("w:id")] {
+				root.RemoveChild(note)
+			}
+		}
+	}
+
+	// 批注的范围和引用可能被拆到不同的部分，只要有引用标记就保留批注
+	comments, err := doc.commentsRoot(false)
+	if err != nil {
+		return err
+	}
+	if comments != nil {
+		used := make(map[string]bool)
+		for _, ref := range body.Find("w:commentReference") {
+			used[ref.Attr("w:id")] = true
+		}
+		for _, comment := range comments.ChildrenNamed("w:comment") {
+			if !used[comment.Attr("w:id")] {
+				comments.RemoveChild(comment)
+			}
+		}
+		removeUnmatched(body, func(n *Node) bool {
+			return (n.Name == "w:commentRangeStart" || n.Name == "w:commentRangeEnd") && !used[n.Attr("w:id")]
+		})
+	}
+
+	starts := make(map[string]bool)
+	ends := make(map[string]bool)
+	for _, n := range body.Find("w:bookmarkStart") {
+		starts[n.Attr("w:id")] = true
+	}
+	for _, n := range body.Find("w:bookmarkEnd") {
+		ends[n.Attr("w:id")] = true
+	}
+	removeUnmatched(body, func(n *Node) bool {
+		return (n.Name == "w:bookmarkStart" && !ends[n.Attr("w:id")]) || (n.Name == "w:bookmarkEnd" && !starts[n.Attr("w:id")])
+	})
+
+	// 删除正文不再引用的关系
+	root, err := pkg.XML(mainPart)
+	if err != nil {
+		return err
+	}
+	referenced := make(map[string]bool)
+	root.Walk(func(n, parent *Node) bool {
+		for _, attr := range n.Attrs {
+			if strings.HasPrefix(attr.Name, "r:") {
+				referenced[attr.Value] = true
+			}
+		}
+		return true
+	})
+	for _, rel := range pkg.Relationships(mainPart) {
+		if contentRelationshipTypes[path.Base(rel.Type)] && !referenced[rel.ID] {
+			pkg.RemoveRelationship(mainPart, rel.ID)
+		}
+	}
+	removeUnreachableParts(pkg)
+	return nil
+}
+
+// removeUnmatched 删除节点树中满足条件的节点
+func removeUnmatched(root *Node, remove func(n *Node) bool) {
+	root.Walk(func(n, parent *Node) bool {
+		if parent != nil && remove(n) {
+			parent.RemoveChild(n)
+			return false
+		}
+		return true
+	})
+}
+
+// removeUnreachableParts 删除从包级关系出发无法通过关系到达的部件
+func removeUnreachableParts(pkg *Package) {
+	reachable := make(map[string]bool)
+	var visit func(source string)
+	visit = func(source string) {
+		for _, rel := range pkg.Relationships(source) {
+			if rel.External {
+				continue
+			}
+			target := pkg.ResolveTarget(source, rel.Target)
+			if reachable[target] {
+				continue
+			}
+			reachable[target] = true
+			visit(target)
+		}
+	}
+	visit("")
+
+	for _, name := range pkg.PartNames() {
+		if name == contentTypesPart || path.Base(path.Dir(name)) == "_rels" || reachable[name] {
+			continue
+		}
+		pkg.RemovePart(name)
+	}
+}
+
+// SplitFileName 返回拆分得到的第 number 个部分（从1开始）的文件名：序号加部分标题，
+// 序号按部分总数补零以便按名称排序
+func SplitFileName(number, total int, title string) string {
+	title = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(invalidFileNameChars, r) || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, title)
+	title = strings.Trim(title, " .")
+	prefix := fmt.Sprintf("%0*d", len(strconv.Itoa(total)), number)
+	if title == "" {
+		return prefix + ".docx"
+	}
+	return prefix + " " + title + ".docx"
+}
+
+// SplitFiles 拆分文档并将每个部分保存到 dir 中，返回保存的文件路径，文档本身不变
+//
+// 文件名见 SplitFileName，序号保证文件名不重复，已存在的文件被覆盖。
+func (doc *Document) SplitFiles(dir string, opts SplitOptions) ([]string, error) {
+	parts, err := doc.Split(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建输出文件夹失败: %v", err)
+	}
+	var paths []string
+	for i, part := range parts {
+		name := SplitFileName(i+1, len(parts), part.Title)
+		path := filepath.Join(dir, name)
+		if err := part.Package.Save(path); err != nil {
+			return paths, fmt.Errorf("保存第 %d 部分失败: %v", i+1, err)
+		}
+		paths = append(paths, path)
+	}
+	log.Printf("已将文档拆分为 %d 个部分并保存到 %s", len(paths), dir)
+	return paths, nil
+}
@@ -0,0 +1,134 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testPageBreak = `<w:br w:type="page"/>`
+
+func TestSplitParagraphAtPageBreak(t *testing.T) {
+	tests := []struct {
+		name          string
+		paragraph     string
+		ok            bool
+		before, after string
+	}{
+		{"没有分页符",
+			`<w:p><w:r><w:t>前</w:t><w:br/><w:t>后</w:t></w:r></w:p>`, false, "", ""},
+		{"Run中间的分页符",
+			`<w:p><w:r><w:t>前</w:t>` + testPageBreak + `<w:t>后</w:t></w:r></w:p>`, true, "前", "后"},
+		{"单独一个Run的分页符",
+			`<w:p><w:r><w:t>前</w:t></w:r><w:r>` + testPageBreak + `</w:r><w:r><w:t>后</w:t></w:r></w:p>`, true, "前", "后"},
+		{"段落开头的分页符",
+			`<w:p><w:r>` + testPageBreak + `<w:t>后</w:t></w:r></w:p>`, true, "", "后"},
+		{"段落末尾的分页符",
+			`<w:p><w:r><w:t>前</w:t>` + testPageBreak + `</w:r></w:p>`, true, "前", ""},
+		{"只在第一个分页符处拆分",
+			`<w:p><w:r><w:t>一</w:t>` + testPageBreak + `<w:t>二</w:t>` + testPageBreak + `<w:t>三</w:t></w:r></w:p>`, true, "一", "二\n三"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parseTestParagraph(t, tt.paragraph)
+			original := p.String()
+			before, after, ok := splitParagraphAtPageBreak(p)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, 期望 %v", ok, tt.ok)
+			}
+			if p.String() != original {
+				t.Errorf("拆分修改了原段落")
+			}
+			if !ok {
+				return
+			}
+			if got := paragraphNodeText(before); got != tt.before {
+				t.Errorf("分页符前 %q, 期望 %q", got, tt.before)
+			}
+			if got := paragraphNodeText(after); got != tt.after {
+				t.Errorf("分页符后 %q, 期望 %q", got, tt.after)
+			}
+		})
+	}
+}
+
+func TestSplitParagraphAtPageBreakProperties(t *testing.T) {
+	p := parseTestParagraph(t, `<w:p><w:pPr><w:pStyle w:val="Heading1"/><w:pageBreakBefore/><w:sectPr/></w:pPr>`+
+		`<w:r><w:rPr><w:b/></w:rPr><w:t>前</w:t>`+testPageBreak+`<w:t>后</w:t></w:r></w:p>`)
+	before, after, ok := splitParagraphAtPageBreak(p)
+	if !ok {
+		t.Fatalf("没有找到分页符")
+	}
+
+	// 两部分都保留段落样式；段前分页只属于前一部分，节属性只属于后一部分
+	if paragraphStyle(before) != "Heading1" || paragraphStyle(after) != "Heading1" {
+		t.Errorf("段落样式没有保留: %q, %q", paragraphStyle(before), paragraphStyle(after))
+	}
+	if before.Child("w:pPr").Child("w:pageBreakBefore") == nil {
+		t.Errorf("前一部分丢失了段前分页")
+	}
+	if after.Child("w:pPr").Child("w:pageBreakBefore") != nil {
+		t.Errorf("后一部分不应有段前分页")
+	}
+	if blockSectPr(before) != nil || blockSectPr(after) == nil {
+		t.Errorf("节属性应只在后一部分中")
+	}
+	// 分页符后的文字保留Run的格式
+	run := after.Child("w:r")
+	if run == nil || run.Child("w:rPr") == nil || run.Child("w:rPr").Child("w:b") == nil {
+		t.Errorf("分页符后的文字丢失了字符格式: %s", after)
+	}
+	if len(after.Find("w:br")) != 0 || len(before.Find("w:br")) != 0 {
+		t.Errorf("分页符没有被删除")
+	}
+}
+
+func TestSplitAtPageBreaks(t *testing.T) {
+	tests := []struct {
+		name       string
+		blocks     []string
+		wantTexts  []string
+		wantStarts []int
+	}{
+		{"没有分页",
+			[]string{`<w:p><w:r><w:t>甲</w:t></w:r></w:p>`, `<w:p><w:r><w:t>乙</w:t></w:r></w:p>`},
+			[]string{"甲", "乙"}, nil},
+		{"段落中的多个分页符",
+			[]string{`<w:p><w:r><w:t>甲</w:t>` + testPageBreak + `<w:t>乙</w:t>` + testPageBreak + `<w:t>丙</w:t></w:r></w:p>`},
+			[]string{"甲", "乙", "丙"}, []int{1, 2}},
+		{"段落开头的分页符不产生空段落",
+			[]string{`<w:p><w:r><w:t>甲</w:t></w:r></w:p>`, `<w:p><w:r>` + testPageBreak + `<w:t>乙</w:t></w:r></w:p>`},
+			[]string{"甲", "乙"}, []int{1}},
+		{"只有分页符的段落",
+			[]string{`<w:p><w:r><w:t>甲</w:t></w:r></w:p>`, `<w:p><w:r>` + testPageBreak + `</w:r></w:p>`, `<w:p><w:r><w:t>乙</w:t></w:r></w:p>`},
+			[]string{"甲", "乙"}, []int{1}},
+		{"段前分页",
+			[]string{`<w:p><w:r><w:t>甲</w:t></w:r></w:p>`, `<w:p><w:pPr><w:pageBreakBefore/></w:pPr><w:r><w:t>乙</w:t></w:r></w:p>`},
+			[]string{"甲", "乙"}, []int{1}},
+		{"表格不拆分",
+			[]string{`<w:tbl><w:tr><w:tc><w:p><w:r>` + testPageBreak + `</w:r></w:p></w:tc></w:tr></w:tbl>`, `<w:p><w:r><w:t>甲</w:t></w:r></w:p>`},
+			[]string{"", "甲"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var blocks []*Node
+			for _, block := range tt.blocks {
+				blocks = append(blocks, parseTestParagraph(t, block))
+			}
+			result, starts := splitAtPageBreaks(blocks)
+			var texts []string
+			for _, block := range result {
+				if block.Name == "w:p" {
+					texts = append(texts, paragraphNodeText(block))
+				} else {
+					texts = append(texts, "")
+				}
+			}
+			if !reflect.DeepEqual(texts, tt.wantTexts) {
+				t.Errorf("拆分后的正文块 %q, 期望 %q", texts, tt.wantTexts)
+			}
+			if !reflect.DeepEqual(starts, tt.wantStarts) {
+				t.Errorf("各部分开始的位置 %v, 期望 %v", starts, tt.wantStarts)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package document
+
+import (
+	"strings"
+	"unicode"
+)
+
+// PageLineCounter 排版引擎提供的页数和行数统计
+type PageLineCounter interface {
+	// CountPagesAndLines 排版文档，返回页数和有内容的行数
+	CountPagesAndLines(doc *Document) (pages, lines int, err error)
+}
+
+// StatisticsOptions 统计文档的选项
+type StatisticsOptions struct {
+	Pages        PageLineCounter // 排版引擎，为nil时不统计页数和行数
+	IncludeNotes bool            // 包括脚注和尾注
+}
+
+// Statistics 文档的字数统计，计数方法与中文版Word的字数统计一致
+type Statistics struct {
+	Pages                int  // 页数，Layout 为假时为0
+	Lines                int  // 行数，Layout 为假时为0
+	Layout               bool // 页数和行数来自排版结果
+	Words                int  // 字数：中文字符数加非中文单词数
+	EastAsianCharacters  int  // 中文字符和朝鲜语单词：汉字、假名和全角标点逐字计数，连续的谚文算一个单词
+	LatinWords           int  // 非中文单词：连续的非空白、非中文字符
+	Characters           int  // 字符数（不计空格）
+	CharactersWithSpaces int  // 字符数（计空格），不含换行符
+	Paragraphs           int  // 有内容的段落数，包括表格中的段落
+	Tables               int  // 表格数，包括嵌套的表格
+	Images               int  // 图片数
+}
+
+// Statistics 统计文档正文的字数、字符数、段落、表格和图片，排版引擎存在时同时统计页数和行数
+func (doc *Document) Statistics(opts StatisticsOptions) (Statistics, error) {
+	var stats Statistics
+	blocks, err := doc.Blocks()
+	if err != nil {
+		return stats, err
+	}
+	stats.countBlocks(blocks)
+
+	if opts.IncludeNotes {
+		notes, err := doc.AllNotes()
+		if err != nil {
+			return stats, err
+		}
+		for _, note := range notes {
+			for _, line := range strings.Split(note.Text, "\n") {
+				if strings.TrimSpace(line) != "" {
+					stats.Paragraphs++
+				}
+				stats.countText(line)
+			}
+		}
+	}
+	stats.Words = stats.EastAsianCharacters + stats.LatinWords
+
+	if opts.Pages != nil {
+		if stats.Pages, stats.Lines, err = opts.Pages.CountPagesAndLines(doc); err != nil {
+			return stats, err
+		}
+		stats.Layout = true
+	}
+	return stats, nil
+}
+
+// countBlocks 统计正文块，表格按单元格递归统计
+func (s *Statistics) countBlocks(blocks []Block) {
+	for _, b := range blocks {
+		if b.Kind == TableBlock {
+			s.Tables++
+			for _, row := range b.Rows {
+				for _, cell := range row.Cells {
+					s.countBlocks(cell.Blocks)
+				}
+			}
+			continue
+		}
+		s.Images += len(b.Images)
+		var builder strings.Builder
+		for _, span := range b.Spans {
+			// 注释编号由Word自动生成，不计入字数
+			if span.NoteID != "" {
+				continue
+			}
+			for _, r := range span.Text {
+				if r != ObjectReplacement && r != PageBreak {
+					builder.WriteRune(r)
+				}
+			}
+		}
+		text := builder.String()
+		if strings.TrimSpace(text) != "" {
+			s.Paragraphs++
+		}
+		s.countText(text)
+	}
+}
+
+// countText 统计一段文字的中文字符、朝鲜语单词、非中文单词和字符数
+func (s *Statistics) countText(text string) {
+	inWord, inHangul := false, false
+	for _, r := range text {
+		switch {
+		case r == '\n' || r == '\r':
+			inWord, inHangul = false, false
+			continue
+		case unicode.IsSpace(r):
+			inWord, inHangul = false, false
+		case unicode.Is(unicode.Hangul, r):
+			// 与Word一致，朝鲜语按单词计数
+			if !inHangul {
+				s.EastAsianCharacters++
+				inHangul = true
+			}
+			inWord = false
+			s.Characters++
+		case isEastAsian(r):
+			inWord, inHangul = false, false
+			s.EastAsianCharacters++
+			s.Characters++
+		default:
+			if !inWord {
+				s.LatinWords++
+				inWord = true
+			}
+			inHangul = false
+			s.Characters++
+		}
+		s.CharactersWithSpaces++
+	}
+}
+
+// isEastAsian 判断字符是否按中文字符逐字计数：汉字、假名、注音符号和全角标点（谚文按单词计数，见 countText）
+func isEastAsian(r rune) bool {
+	switch {
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Bopomofo):
+		return true
+	case r >= 0x3001 && r <= 0x303F: // 中日韩符号和标点，不含全角空格
+		return true
+	case r >= 0xFE30 && r <= 0xFE4F: // 中日韩兼容形式
+		return true
+	case r >= 0xFF01 && r <= 0xFF65, r >= 0xFFE0 && r <= 0xFFE6: // 全角字符
+		return true
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package document
+
+import "testing"
+
+func TestCountText(t *testing.T) {
+	tests := []struct {
+		name                  string
+		text                  string
+		eastAsian, latinWords int
+		chars, charsSpaces    int
+	}{
+		{"空文本", "", 0, 0, 0, 0},
+		{"中文", "你好世界", 4, 0, 4, 4},
+		{"全角标点", "你好，世界。", 6, 0, 6, 6},
+		{"英文单词", "hello  world", 0, 2, 10, 12},
+		{"中英混排", "使用Go语言 v2.6", 4, 2, 10, 11},
+		{"数字和标点算一个单词", "3.14, e.g.", 0, 2, 9, 10},
+		{"日文假名", "ひらがなカタカナ", 8, 0, 8, 8},
+		{"朝鲜语按单词计数", "안녕하세요 세계", 2, 0, 7, 8},
+		{"朝鲜语与中文相邻", "한국語", 2, 0, 3, 3},
+		{"换行不计字符", "ab\ncd", 0, 2, 4, 4},
+		{"全角空格", "中　文", 2, 0, 2, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Statistics
+			s.countText(tt.text)
+			if s.EastAsianCharacters != tt.eastAsian || s.LatinWords != tt.latinWords ||
+				s.Characters != tt.chars || s.CharactersWithSpaces != tt.charsSpaces {
+				t.Fatalf("countText(%q) = 中文 %d, 单词 %d, 字符 %d, 含空格 %d; 期望 %d, %d, %d, %d",
+					tt.text, s.EastAsianCharacters, s.LatinWords, s.Characters, s.CharactersWithSpaces,
+					tt.eastAsian, tt.latinWords, tt.chars, tt.charsSpaces)
+			}
+		})
+	}
+}
+
+func TestIsEastAsian(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want bool
+	}{
+		{'中', true},
+		{'あ', true},
+		{'ア', true},
+		{'ㄅ', true},
+		{'，', true},
+		{'。', true},
+		{'「', true},
+		{'！', true},
+		{'￥', true},
+		{'　', false}, // 全角空格按空白处理
+		{'한', false}, // 谚文在 countText 中按单词计数
+		{'a', false},
+		{'1', false},
+		{',', false},
+		{'é', false},
+	}
+	for _, tt := range tests {
+		if got := isEastAsian(tt.r); got != tt.want {
+			t.Errorf("isEastAsian(%q) = %v, 期望 %v", tt.r, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package document
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// bodyTextLevel 大纲级别中的正文级别，w:outlineLvl 的值为9表示正文
+const bodyTextLevel = 9
+
+// headingNamePattern 内置标题样式的名称，兼容中文版Word的样式名
+var headingNamePattern = regexp.MustCompile(`^(?i:heading|标题)\s*([1-9])$`)
+
+// styleInfo 段落样式中与大纲相关的信息
+type styleInfo struct {
+	name    string
+	basedOn string
+	outline int // 样式直接设置的大纲级别，未设置时为-1
+}
+
+// paragraphStyles 读取样式部件中的段落样式
+func (doc *Document) paragraphStyles() map[string]styleInfo {
+	styles := make(map[string]styleInfo)
+	if doc.Package == nil {
+		return styles
+	}
+	part, ok := doc.Package.RelatedPart(doc.Package.MainPartName(), relTypeStyles)
+	if !ok {
+		return styles
+	}
+	root, err := doc.Package.XML(part)
+	if err != nil {
+		return styles
+	}
+
+	for _, s := range root.ChildrenNamed("w:style") {
+		if s.Attr("w:type") != "paragraph" {
+			continue
+		}
+		info := styleInfo{outline: -1}
+		if name := s.Child("w:name"); name != nil {
+			info.name = name.Attr("w:val")
+		}
+		if basedOn := s.Child("w:basedOn"); basedOn != nil {
+			info.basedOn = basedOn.Attr("w:val")
+		}
+		if pPr := s.Child("w:pPr"); pPr != nil {
+			info.outline = outlineValue(pPr)
+		}
+		styles[s.Attr("w:styleId")] = info
+	}
+	return styles
+}
+
+// ParagraphStyleName 返回段落使用的样式名称，没有指定样式时为默认段落样式的名称
+func (doc *Document) ParagraphStyleName(paragraph int) (string, error) {
+	paragraphs := doc.paragraphNodes()
+	if paragraph < 0 || paragraph >= len(paragraphs) {
+		return "", fmt.Errorf("段落索引超出范围: %d", paragraph+1)
+	}
+	sheet := doc.styleSheet()
+	id := paragraphStyle(paragraphs[paragraph])
+	if id == "" {
+		id = sheet.defaults["paragraph"]
+	}
+	if style, ok := sheet.styles[id]; ok {
+		if name := style.Child("w:name"); name != nil && name.Attr("w:val") != "" {
+			return name.Attr("w:val"), nil
+		}
+	}
+	if id == "" {
+		return "正文", nil
+	}
+	return id, nil
+}
+
+// outlineValue 返回段落属性中直接设置的大纲级别，未设置时为-1
+func outlineValue(pPr *Node) int {
+	if lvl := pPr.Child("w:outlineLvl"); lvl != nil {
+		if value, err := strconv.Atoi(lvl.Attr("w:val")); err == nil {
+			return value
+		}
+	}
+	return -1
+}
+
+// styleOutlineLevels 解析每个段落样式的大纲级别（0为一级标题），沿 basedOn 继承
+//
+// 样式没有设置大纲级别时按内置标题样式的名称识别，例如 heading 1、标题 2。
+func (doc *Document) styleOutlineLevels() map[string]int {
+	styles := doc.paragraphStyles()
+	levels := make(map[string]int)
+	for id := range styles {
+		levels[id] = bodyTextLevel
+		current := id
+		for depth := 0; current != "" && depth < 20; depth++ {
+			info, ok := styles[current]
+			if !ok {
+				break
+			}
+			if info.outline >= 0 {
+				levels[id] = info.outline
+				break
+			}
+			if m := headingNamePattern.FindStringSubmatch(strings.TrimSpace(info.name)); m != nil {
+				level, _ := strconv.Atoi(m[1])
+				levels[id] = level - 1
+				break
+			}
+			current = info.basedOn
+		}
+	}
+	return levels
+}
+
+// paragraphOutlineLevel 返回段落的大纲级别（0为一级标题），正文返回 bodyTextLevel
+//
+// 段落属性中直接设置的大纲级别优先于样式。
+func paragraphOutlineLevel(p *Node, styleLevels map[string]int) int {
+	if pPr := p.Child("w:pPr"); pPr != nil {
+		if value := outlineValue(pPr); value >= 0 {
+			return value
+		}
+	}
+	if level, ok := styleLevels[paragraphStyle(p)]; ok {
+		return level
+	}
+	// 样式部件中没有定义时按样式ID识别
+	if m := headingNamePattern.FindStringSubmatch(paragraphStyle(p)); m != nil {
+		level, _ := strconv.Atoi(m[1])
+		return level - 1
+	}
+	return bodyTextLevel
+}
+
+// Heading 文档中的一个标题段落
+type Heading struct {
+	Paragraph int    // 正文段落索引
+	Level     int    // 标题级别，1为一级标题
+	Text      string // 标题文字
+}
+
+// Headings 返回正文中按样式或大纲级别识别的标题，目录中的段落和空标题除外
+func (doc *Document) Headings() ([]Heading, error) {
+	body, err := doc.body()
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[*Node]bool)
+	if toc := findTOC(body); toc != nil {
+		for _, p := range toc.paragraphs() {
+			skip[p] = true
+		}
+	}
+
+	styleLevels := doc.styleOutlineLevels()
+	var headings []Heading
+	for i, p := range collectParagraphs(body) {
+		if skip[p] {
+			continue
+		}
+		level := paragraphOutlineLevel(p, styleLevels)
+		if level >= bodyTextLevel || level < 0 {
+			continue
+		}
+		text := strings.TrimSpace(paragraphNodeText(p))
+		if text == "" {
+			continue
+		}
+		headings = append(headings, Heading{Paragraph: i, Level: level + 1, Text: text})
+	}
+	return headings, nil
+}
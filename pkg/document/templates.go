@@ -0,0 +1,404 @@
+package document
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// contentTypeTemplateMain Word模板（.dotx）主文档部件的内容类型
+const contentTypeTemplateMain = "application/vnd.openxmlformats-officedocument.wordprocessingml.template.main+xml"
+
+// templateExtensions 模板库中作为模板的文件扩展名
+var templateExtensions = map[string]bool{".dotx": true, ".docx": true}
+
+// placeholderPattern 模板占位符，例如 {{customer.name}}，名称由字母、数字和下划线组成，可用点号分级
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([\pL\pN_]+(?:\.[\pL\pN_]+)*)\s*\}\}`)
+
+// NewDocumentOptions 新建文档的选项
+type NewDocumentOptions struct {
+	Template string // 作为新文档起点的 .dotx 或 .docx 文件，为空时新建空白文档
+}
+
+// TemplateInfo 模板库中的模板文件
+type TemplateInfo struct {
+	Name     string // 不含扩展名的文件名
+	Path     string
+	Category string // 模板所在的子文件夹，直接位于模板库中时为空
+}
+
+// DefaultTemplateDir 返回默认的模板库目录（用户配置目录下的 fyne-word/templates）
+func DefaultTemplateDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("无法获取用户配置目录: %v", err)
+	}
+	return filepath.Join(configDir, "fyne-word", "templates"), nil
+}
+
+// ListTemplates 列出模板库目录及其子文件夹中的模板，按分类和名称排序
+//
+// 目录不存在时返回空列表，Word打开文档时生成的 ~$ 临时文件和隐藏文件被忽略。
+func ListTemplates(dir string) ([]TemplateInfo, error) {
+	var templates []TemplateInfo
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		name := entry.Name()
+		if path != dir && strings.HasPrefix(name, ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() || strings.HasPrefix(name, "~$") || !templateExtensions[strings.ToLower(filepath.Ext(name))] {
+			return nil
+		}
+		category, _ := filepath.Rel(dir, filepath.Dir(path))
+		if category == "." {
+			category = ""
+		}
+		templates = append(templates, TemplateInfo{
+			Name:     strings.TrimSuffix(name, filepath.Ext(name)),
+			Path:     path,
+			Category: filepath.ToSlash(category),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取模板库失败: %v", err)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Category != templates[j].Category {
+			return templates[i].Category < templates[j].Category
+		}
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// OpenTemplate 读取模板文件，.dotx 模板的主文档转换为普通文档的内容类型
+func OpenTemplate(path string) (*Package, error) {
+	pkg, err := OpenPackage(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := pkg.Body(); err != nil {
+		return nil, fmt.Errorf("模板不是有效的Word文档: %v", err)
+	}
+	types, err := pkg.XML(contentTypesPart)
+	if err != nil {
+		return nil, err
+	}
+	for _, override := range types.ChildrenNamed("Override") {
+		if override.Attr("ContentType") == contentTypeTemplateMain {
+			override.SetAttr("ContentType", contentTypeMainDocument)
+		}
+	}
+	return pkg, nil
+}
+
+// newDocumentFromTemplate 以模板的副本创建未保存的新文档
+func (m *Manager) newDocumentFromTemplate(path string) (*Document, error) {
+	log.Printf("正在从模板创建新文档: %s", path)
+	pkg, err := OpenTemplate(path)
+	if err != nil {
+		return nil, fmt.Errorf("创建新文档失败: %v", err)
+	}
+	baseName := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	doc := &Document{
+		FileName:   baseName + ".docx",
+		Title:      baseName,
+		Package:    pkg,
+		IsOpen:     true,
+		IsModified: true,
+	}
+	m.addUnsaved(doc)
+	log.Printf("已从模板创建新文档: %s", doc.FileName)
+	return doc, nil
+}
+
+// placeholderParts 返回可以包含占位符的部件：主文档、页眉页脚和脚注尾注
+func (doc *Document) placeholderParts() ([]*Node, error) {
+	if doc.Package == nil {
+		return nil, fmt.Errorf("文档不支持模板占位符")
+	}
+	main := doc.Package.MainPartName()
+	root, err := doc.Package.XML(main)
+	if err != nil {
+		return nil, err
+	}
+	roots := []*Node{root}
+	for _, rel := range doc.Package.Relationships(main) {
+		switch rel.Type {
+		case relTypeHeader, relTypeFooter, relTypeFootnotes, relTypeEndnotes:
+			if rel.External {
+				continue
+			}
+			if part, err := doc.Package.XML(doc.Package.ResolveTarget(main, rel.Target)); err == nil {
+				roots = append(roots, part)
+			}
+		}
+	}
+	return roots, nil
+}
+
+// textSegment 段落中一个 w:t 元素的文字
+type textSegment struct {
+	run, node *Node
+	start     int // 在段落文字中的起始位置（按字符计）
+	text      []rune
+}
+
+// paragraphSegments 收集段落中Run的文字元素和段落文字，不包括删除修订和文本框中的段落
+//
+// 制表符、换行、图片和域标记在段落文字中以空字符表示，占位符不能跨越它们。
+func paragraphSegments(p *Node) ([]*textSegment, string) {
+	var segments []*textSegment
+	var all []rune
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for _, c := range n.Children {
+			switch c.Name {
+			case "w:del", "w:moveFrom", "w:p", "w:pPr", "w:rPr":
+			case "w:r":
+				for _, rc := range c.Children {
+					switch rc.Name {
+					case "w:t":
+						text := []rune(rc.InnerText())
+						segments = append(segments, &textSegment{run: c, node: rc, start: len(all), text: text})
+						all = append(all, text...)
+					case "w:tab", "w:ptab", "w:br", "w:cr", "w:drawing", "w:fldChar":
+						all = append(all, 0)
+					}
+				}
+				walk(c)
+			default:
+				if c.IsElement() {
+					walk(c)
+				}
+			}
+		}
+	}
+	walk(p)
+	return segments, string(all)
+}
+
+// placeholderMatch 段落文字中的一个占位符
+type placeholderMatch struct {
+	start, end int // 在段落文字中的位置（按字符计）
+	name       string
+}
+
+// placeholderMatches 返回段落文字中的占位符
+func placeholderMatches(text string) []placeholderMatch {
+	var matches []placeholderMatch
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(text, -1) {
+		matches = append(matches, placeholderMatch{
+			start: utf8.RuneCountInString(text[:m[0]]),
+			end:   utf8.RuneCountInString(text[:m[1]]),
+			name:  text[m[2]:m[3]],
+		})
+	}
+	return matches
+}
+
+// Placeholders 返回文档中占位符的名称，按第一次出现的顺序排列，不重复
+//
+// 占位符被拆分到多个Run中（例如部分文字格式不同）时也能找到。
+func (doc *Document) Placeholders() ([]string, error) {
+	roots, err := doc.placeholderParts()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, root := range roots {
+		for _, p := range root.Find("w:p") {
+			_, text := paragraphSegments(p)
+			for _, m := range placeholderMatches(text) {
+				if !seen[m.name] {
+					seen[m.name] = true
+					names = append(names, m.name)
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
+// FillPlaceholders 用 values 中的值替换文档中的占位符，返回替换的数量和没有提供值的占位符名称
+//
+// 名称先按原样查找，找不到时不区分大小写。替换的文字使用占位符第一个字符的格式，
+// 值中的换行和制表符转换为Word的换行和制表符。没有值的占位符保持不变。
+func (doc *Document) FillPlaceholders(values map[string]string) (int, []string, error) {
+	roots, err := doc.placeholderParts()
+	if err != nil {
+		return 0, nil, err
+	}
+	filled, missing := fillPlaceholders(roots, values)
+	if filled > 0 {
+		doc.IsModified = true
+	}
+	log.Printf("已填写占位符: %d 处，缺少值: %d 个", filled, len(missing))
+	return filled, missing, nil
+}
+
+// lookupValue 按名称查找值，找不到时不区分大小写再查找一次
+func lookupValue(values map[string]string, name string) (string, bool) {
+	if value, ok := values[name]; ok {
+		return value, true
+	}
+	for key, value := range values {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// fillPlaceholders 替换 roots 下所有段落中的占位符，返回替换的数量和没有值的占位符名称
+func fillPlaceholders(roots []*Node, values map[string]string) (int, []string) {
+	filled := 0
+	missing := make(map[string]bool)
+	var missingNames []string
+	for _, root := range roots {
+		for _, p := range root.Find("w:p") {
+			segments, text := paragraphSegments(p)
+			matches := placeholderMatches(text)
+			for _, m := range matches {
+				if _, ok := lookupValue(values, m.name); !ok && !missing[m.name] {
+					missing[m.name] = true
+					missingNames = append(missingNames, m.name)
+				}
+			}
+			// 从后向前替换，前面占位符的位置不受影响
+			changed := make(map[*textSegment]bool)
+			for i := len(matches) - 1; i >= 0; i-- {
+				if value, ok := lookupValue(values, matches[i].name); ok {
+					replaceSegments(segments, matches[i].start, matches[i].end, value, changed)
+					filled++
+				}
+			}
+			for _, segment := range segments {
+				if changed[segment] {
+					writeSegment(segment)
+				}
+			}
+		}
+	}
+	return filled, missingNames
+}
+
+// replaceSegments 将段落文字中 start 到 end 之间的字符替换为 value，
+// 替换的文字放在第一个字符所在的文字元素中
+func replaceSegments(segments []*textSegment, start, end int, value string, changed map[*textSegment]bool) {
+	first := true
+	for _, s := range segments {
+		segStart, segEnd := s.start, s.start+len(s.text)
+		if segEnd <= start || segStart >= end {
+			continue
+		}
+		from, to := start-segStart, end-segStart
+		if from < 0 {
+			from = 0
+		}
+		if to > len(s.text) {
+			to = len(s.text)
+		}
+		text := append([]rune{}, s.text[:from]...)
+		if first {
+			text = append(text, []rune(value)...)
+			first = false
+		}
+		s.text = append(text, s.text[to:]...)
+		changed[s] = true
+	}
+}
+
+// writeSegment 将修改后的文字写回文字元素，换行和制表符转换为对应元素
+func writeSegment(s *textSegment) {
+	text := string(s.text)
+	if !strings.ContainsAny(text, "\r\n\t") {
+		s.node.SetText(text)
+		s.node.SetAttr("xml:space", "preserve")
+		return
+	}
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(text)
+	tmp := NewNode("w:r")
+	appendRunText(tmp, text, "w:t")
+	s.run.ReplaceChild(s.node, tmp.Children...)
+}
+
+// LoadTemplateData 从JSON或YAML文件读取占位符的值，嵌套的对象和数组展开为以点号连接的名称
+//
+// 例如 {"customer": {"name": "张三"}} 得到 customer.name，数组元素使用序号，如 items.0.name。
+func LoadTemplateData(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取数据文件失败: %v", err)
+	}
+	var value interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(strings.NewReader(string(data)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("解析JSON失败: %v", err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("解析YAML失败: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的数据文件格式: %s", filepath.Ext(path))
+	}
+	values := make(map[string]string)
+	flattenTemplateData("", value, values)
+	return values, nil
+}
+
+// flattenTemplateData 将嵌套的数据展开为占位符名称和值
+func flattenTemplateData(prefix string, value interface{}, values map[string]string) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			flattenTemplateData(join(key), item, values)
+		}
+	case map[interface{}]interface{}:
+		for key, item := range v {
+			flattenTemplateData(join(fmt.Sprint(key)), item, values)
+		}
+	case []interface{}:
+		for i, item := range v {
+			flattenTemplateData(join(strconv.Itoa(i)), item, values)
+		}
+	case nil:
+		if prefix != "" {
+			values[prefix] = ""
+		}
+	default:
+		if prefix != "" {
+			values[prefix] = fmt.Sprint(v)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFillPlaceholders(t *testing.T) {
+	values := map[string]string{
+		"name":          "张三",
+		"customer.City": "上海",
+		"note":          "第一行\n第二行\t结尾",
+		"empty":         "",
+	}
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		filled  int
+		missing []string
+	}{
+		{
+			"同一个Run",
+			`<w:p><w:r><w:t>您好，{{name}}！</w:t></w:r></w:p>`,
+			[]string{"您好，张三！"}, 1, nil,
+		},
+		{
+			"拆分到多个Run",
+			`<w:p><w:r><w:t>您好，{{</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>na</w:t></w:r><w:r><w:t>me}}！</w:t></w:r></w:p>`,
+			[]string{"您好，张三！"}, 1, nil,
+		},
+		{
+			"每个字符一个Run",
+			`<w:p><w:r><w:t>{</w:t></w:r><w:r><w:t>{</w:t></w:r><w:r><w:t> name </w:t></w:r><w:r><w:t>}</w:t></w:r><w:r><w:t>}</w:t></w:r></w:p>`,
+			[]string{"张三"}, 1, nil,
+		},
+		{
+			"超链接和校对标记中间",
+			`<w:p><w:r><w:t>{{na</w:t></w:r><w:proofErr w:type="spellStart"/><w:hyperlink><w:r><w:t>me}}</w:t></w:r></w:hyperlink></w:p>`,
+			[]string{"张三"}, 1, nil,
+		},
+		{
+			"删除修订中的文字不参与匹配",
+			`<w:p><w:r><w:t>{{na</w:t></w:r><w:del w:id="1" w:author="甲"><w:r><w:delText>xx</w:delText></w:r></w:del><w:r><w:t>me}}</w:t></w:r></w:p>`,
+			[]string{"张三"}, 1, nil,
+		},
+		{
+			"一个段落中的多个占位符",
+			`<w:p><w:r><w:t>{{name}}住在{{cus</w:t></w:r><w:r><w:t>tomer.city}}，{{name}}</w:t></w:r></w:p>`,
+			[]string{"张三住在上海，张三"}, 3, nil,
+		},
+		{
+			"缺少值的占位符保持不变",
+			`<w:p><w:r><w:t>{{name}}和{{未知}}及{{未知}}</w:t></w:r></w:p>`,
+			[]string{"张三和{{未知}}及{{未知}}"}, 1, []string{"未知"},
+		},
+		{
+			"空值",
+			`<w:p><w:r><w:t>[{{empty}}]</w:t></w:r></w:p>`,
+			[]string{"[]"}, 1, nil,
+		},
+		{
+			"换行和制表符",
+			`<w:p><w:r><w:t>{{note}}</w:t></w:r></w:p>`,
+			[]string{"第一行\n第二行\t结尾"}, 1, nil,
+		},
+		{
+			"表格中的占位符",
+			`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{na</w:t></w:r><w:r><w:t>me}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl><w:p/>`,
+			[]string{"张三", ""}, 1, nil,
+		},
+		{
+			"不完整的占位符",
+			`<w:p><w:r><w:t>{{name}</w:t></w:r><w:r><w:t>{name}}</w:t></w:r></w:p>`,
+			[]string{"{{name}{name}}"}, 0, nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := newBodyTestDocument(t, tt.body)
+			filled, missing, err := doc.FillPlaceholders(values)
+			if err != nil {
+				t.Fatalf("填写占位符失败: %v", err)
+			}
+			if filled != tt.filled || !reflect.DeepEqual(missing, tt.missing) {
+				t.Errorf("替换 %d 处, 缺少 %q; 期望 %d 处, 缺少 %q", filled, missing, tt.filled, tt.missing)
+			}
+			var got []string
+			for _, p := range testBody(t, doc).Find("w:p") {
+				got = append(got, paragraphNodeText(p))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("填写后 %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFillPlaceholdersKeepsFirstFormat(t *testing.T) {
+	doc := newBodyTestDocument(t, `<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>{{na</w:t></w:r>`+
+		`<w:r><w:rPr><w:i/></w:rPr><w:t>me}}后</w:t></w:r></w:p>`)
+	if _, _, err := doc.FillPlaceholders(map[string]string{"name": "张三"}); err != nil {
+		t.Fatalf("填写占位符失败: %v", err)
+	}
+	runs := doc.paragraphNodes()[0].ChildrenNamed("w:r")
+	if got := runs[0].Child("w:t").InnerText(); got != "张三" || runs[0].Child("w:rPr").Child("w:b") == nil {
+		t.Errorf("第一个Run为 %q, 期望加粗的 %q", got, "张三")
+	}
+	if got := runs[1].Child("w:t").InnerText(); got != "后" {
+		t.Errorf("第二个Run为 %q, 期望 %q", got, "后")
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	doc := newBodyTestDocument(t, `<w:p><w:r><w:t>{{ b }}{{a.x</w:t></w:r><w:r><w:t>}}</w:t></w:r></w:p>`+
+		`<w:p><w:r><w:t>{{b}}{{c_1}}{{坏 名称}}</w:t></w:r></w:p>`)
+	names, err := doc.Placeholders()
+	if err != nil {
+		t.Fatalf("读取占位符失败: %v", err)
+	}
+	if want := []string{"b", "a.x", "c_1"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("占位符 %q, 期望 %q", names, want)
+	}
+}
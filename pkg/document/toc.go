@@ -0,0 +1,409 @@
+package document
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// tocGallery 目录内容控件的文档部件库名称
+const tocGallery = "Table of Contents"
+
+// defaultTextWidth 无法读取页面设置时使用的版心宽度（A4纸、左右边距各3.17厘米），单位为缇
+const defaultTextWidth = 8306
+
+// tocLevelsPattern 目录域 \o 开关中的标题级别范围
+var tocLevelsPattern = regexp.MustCompile(`\\o\s*"(\d)-(\d)"`)
+
+// PageLocator 排版引擎提供的页码查询
+type PageLocator interface {
+	// ParagraphPages 返回正文每个段落所在的页码（从1开始），顺序与段落索引一致
+	ParagraphPages(doc *Document) ([]int, error)
+}
+
+// TOCOptions 生成目录的选项
+type TOCOptions struct {
+	Levels int         // 目录包含的标题级别，为0时插入使用3级，更新沿用原目录的设置
+	Pages  PageLocator // 排版引擎，为nil时目录只列出标题，不显示页码
+}
+
+// tocField 文档中的目录域
+type tocField struct {
+	parent     *Node  // 目录域段落所在的容器
+	start, end int    // 目录域起止段落在容器中的位置
+	instr      string // 域指令
+	sdt        *Node  // 包装目录的内容控件，没有时为nil
+}
+
+// paragraphs 返回目录占用的段落，包括内容控件中的目录标题
+func (t *tocField) paragraphs() []*Node {
+	if t.sdt != nil {
+		return collectParagraphs(t.sdt)
+	}
+	var paragraphs []*Node
+	for _, c := range t.parent.Children[t.start : t.end+1] {
+		if c.Name == "w:p" {
+			paragraphs = append(paragraphs, c)
+		}
+	}
+	return paragraphs
+}
+
+// levels 返回目录包含的标题级别
+func (t *tocField) levels() int {
+	if m := tocLevelsPattern.FindStringSubmatch(t.instr); m != nil {
+		if level, err := strconv.Atoi(m[2]); err == nil && level > 0 {
+			return level
+		}
+	}
+	return 3
+}
+
+// findTOC 查找正文中的第一个目录域，没有时返回nil
+func findTOC(body *Node) *tocField {
+	return findTOCIn(body, nil)
+}
+
+// findTOCIn 在容器中查找目录域，sdt 为容器所属的内容控件
+func findTOCIn(container, sdt *Node) *tocField {
+	for i, c := range container.Children {
+		switch c.Name {
+		case "w:p":
+			instr := tocInstruction(c)
+			if instr == "" {
+				continue
+			}
+			toc := &tocField{parent: container, start: i, end: fieldEnd(container, i), instr: instr}
+			if sdt != nil && isTOCGallery(sdt) {
+				toc.sdt = sdt
+			}
+			return toc
+		case "w:sdt":
+			if content := c.Child("w:sdtContent"); content != nil {
+				if toc := findTOCIn(content, c); toc != nil {
+					return toc
+				}
+			}
+		case "w:customXml":
+			if toc := findTOCIn(c, nil); toc != nil {
+				return toc
+			}
+		}
+	}
+	return nil
+}
+
+// tocInstruction 返回段落中以 TOC 开头的复杂域指令，没有时返回空字符串
+func tocInstruction(p *Node) string {
+	depth := 0
+	var instr strings.Builder
+	found := ""
+	p.Walk(func(n, parent *Node) bool {
+		if found != "" {
+			return false
+		}
+		switch n.Name {
+		case "w:fldChar":
+			switch n.Attr("w:fldCharType") {
+			case "begin":
+				depth++
+				if depth == 1 {
+					instr.Reset()
+				}
+			case "separate", "end":
+				if depth == 1 && isTOCInstruction(instr.String()) {
+					found = strings.TrimSpace(instr.String())
+				}
+				if n.Attr("w:fldCharType") == "end" && depth > 0 {
+					depth--
+				}
+			}
+		case "w:instrText":
+			if depth == 1 {
+				instr.WriteString(n.InnerText())
+			}
+		}
+		return true
+	})
+	if found == "" && depth > 0 && isTOCInstruction(instr.String()) {
+		found = strings.TrimSpace(instr.String())
+	}
+	return found
+}
+
+// isTOCInstruction 判断域指令是否为目录域
+func isTOCInstruction(instr string) bool {
+	fields := strings.Fields(instr)
+	return len(fields) > 0 && strings.EqualFold(fields[0], "TOC")
+}
+
+// fieldEnd 返回从 start 段落开始的复杂域结束所在段落的位置
+func fieldEnd(container *Node, start int) int {
+	depth := 0
+	end := start
+	for i := start; i < len(container.Children); i++ {
+		c := container.Children[i]
+		if c.Name != "w:p" {
+			continue
+		}
+		end = i
+		for _, fc := range c.Find("w:fldChar") {
+			switch fc.Attr("w:fldCharType") {
+			case "begin":
+				depth++
+			case "end":
+				depth--
+			}
+		}
+		if depth <= 0 {
+			return i
+		}
+	}
+	return end
+}
+
+// isTOCGallery 判断内容控件是否为Word插入的目录
+func isTOCGallery(sdt *Node) bool {
+	if sdtPr := sdt.Child("w:sdtPr"); sdtPr != nil {
+		for _, gallery := range sdtPr.Find("w:docPartGallery") {
+			if gallery.Attr("w:val") == tocGallery {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasTOC 判断文档中是否已有目录
+func (doc *Document) HasTOC() bool {
+	body, err := doc.body()
+	return err == nil && findTOC(body) != nil
+}
+
+// InsertTOC 在指定段落之前插入目录，paragraph 等于段落数时插入到文档末尾
+//
+// 目录以内容控件包装的 TOC 域保存，每个目录项都是指向标题书签的超链接，
+// 标题缺少书签时自动添加。提供排版引擎时目录项显示页码。
+func (doc *Document) InsertTOC(paragraph int, opts TOCOptions) error {
+	body, err := doc.body()
+	if err != nil {
+		return err
+	}
+	if findTOC(body) != nil {
+		return fmt.Errorf("文档中已有目录，请使用更新目录")
+	}
+	paragraphs := collectParagraphs(body)
+	if paragraph < 0 || paragraph > len(paragraphs) {
+		return fmt.Errorf("段落索引超出范围: %d", paragraph+1)
+	}
+	if opts.Levels <= 0 {
+		opts.Levels = 3
+	}
+
+	entries, err := doc.tocEntries(body, opts.Levels, nil)
+	if err != nil {
+		return err
+	}
+	sdt := newTOCControl(entries)
+
+	if paragraph < len(paragraphs) {
+		p := paragraphs[paragraph]
+		parent := findParent(body, p)
+		parent.InsertChild(parent.IndexOf(p), sdt)
+	} else if sectPr := body.Child("w:sectPr"); sectPr != nil {
+		body.InsertChild(body.IndexOf(sectPr), sdt)
+	} else {
+		body.AppendChild(sdt)
+	}
+	doc.IsModified = true
+	log.Printf("已在段落 %d 之前插入目录", paragraph+1)
+
+	// 目录本身占用版面，插入后再计算页码
+	if opts.Pages != nil {
+		return doc.UpdateTOC(opts)
+	}
+	return nil
+}
+
+// UpdateTOC 按当前的标题重新生成文档中的目录，保留目录的位置和标题
+func (doc *Document) UpdateTOC(opts TOCOptions) error {
+	body, err := doc.body()
+	if err != nil {
+		return err
+	}
+	toc := findTOC(body)
+	if toc == nil {
+		return fmt.Errorf("文档中没有目录")
+	}
+	if opts.Levels <= 0 {
+		opts.Levels = toc.levels()
+	}
+
+	replace := func(pages []int) error {
+		toc := findTOC(body)
+		entries, err := doc.tocEntries(body, opts.Levels, pages)
+		if err != nil {
+			return err
+		}
+		children := append([]*Node(nil), toc.parent.Children[:toc.start]...)
+		children = append(children, entries...)
+		toc.parent.Children = append(children, toc.parent.Children[toc.end+1:]...)
+		return nil
+	}
+
+	if err := replace(nil); err != nil {
+		return err
+	}
+	if opts.Pages != nil {
+		// 目录项数量可能变化，先更新目录再排版计算页码
+		pages, err := opts.Pages.ParagraphPages(doc)
+		if err != nil {
+			log.Printf("计算页码失败，目录不显示页码: %v", err)
+		} else if err := replace(pages); err != nil {
+			return err
+		}
+	}
+
+	doc.IsModified = true
+	log.Printf("已更新目录")
+	return nil
+}
+
+// tocEntries 为标题生成目录域段落，pages 为nil时不显示页码
+func (doc *Document) tocEntries(body *Node, levels int, pages []int) ([]*Node, error) {
+	headings, err := doc.Headings()
+	if err != nil {
+		return nil, err
+	}
+	var included []Heading
+	for _, h := range headings {
+		if h.Level <= levels {
+			included = append(included, h)
+		}
+	}
+
+	instr := fmt.Sprintf(` TOC \o "1-%d" \h \z \u `, levels)
+	if pages == nil {
+		instr = fmt.Sprintf(` TOC \o "1-%d" \n \h \z \u `, levels)
+	}
+	begin := []*Node{newFieldCharRun("begin", nil), newInstrTextRun(instr, nil), newFieldCharRun("separate", nil)}
+	end := newFieldCharRun("end", nil)
+
+	if len(included) == 0 {
+		p := NewNode("w:p")
+		p.AppendChild(begin...)
+		p.AppendChild(newRunNode("未找到目录项。", nil))
+		p.AppendChild(end)
+		return []*Node{p}, nil
+	}
+
+	bookmarks := ensureHeadingBookmarks(body, included)
+	width := doc.textWidth()
+	entries := make([]*Node, len(included))
+	for i, h := range included {
+		p := newParagraphNode(fmt.Sprintf("TOC%d", h.Level))
+		pPr := p.Child("w:pPr")
+		if pages != nil {
+			tabs := pPr.EnsureChild("w:tabs", pPrOrder)
+			tabs.AppendChild(NewNode("w:tab", "w:val", "right", "w:leader", "dot", "w:pos", strconv.Itoa(width)))
+		}
+		// 目录样式缺失时依靠缩进体现层级
+		pPr.EnsureChild("w:ind", pPrOrder).SetAttr("w:left", strconv.Itoa((h.Level-1)*420))
+
+		if i == 0 {
+			p.AppendChild(begin...)
+		}
+		link := NewNode("w:hyperlink", "w:anchor", bookmarks[i], "w:history", "1")
+		link.AppendChild(newRunNode(h.Text, nil))
+		if pages != nil && h.Paragraph < len(pages) {
+			tab := NewNode("w:r")
+			tab.AppendChild(NewNode("w:tab"))
+			link.AppendChild(tab)
+			link.AppendChild(newComplexField(fmt.Sprintf(" PAGEREF %s \\h ", bookmarks[i]), strconv.Itoa(pages[h.Paragraph]), nil)...)
+		}
+		p.AppendChild(link)
+		if i == len(included)-1 {
+			p.AppendChild(end)
+		}
+		entries[i] = p
+	}
+	return entries, nil
+}
+
+// newTOCControl 创建包装目录标题和目录域的内容控件
+func newTOCControl(entries []*Node) *Node {
+	sdt := NewNode("w:sdt")
+	sdtPr := NewNode("w:sdtPr")
+	docPart := NewNode("w:docPartObj")
+	docPart.AppendChild(NewNode("w:docPartGallery", "w:val", tocGallery), NewNode("w:docPartUnique"))
+	sdtPr.AppendChild(docPart)
+	sdt.AppendChild(sdtPr)
+
+	content := NewNode("w:sdtContent")
+	title := newParagraphNode("TOCHeading")
+	title.Child("w:pPr").EnsureChild("w:jc", pPrOrder).SetAttr("w:val", "center")
+	bold := NewNode("w:rPr")
+	bold.AppendChild(NewNode("w:b"))
+	title.AppendChild(newRunNode("目录", bold))
+	content.AppendChild(title)
+	content.AppendChild(entries...)
+	sdt.AppendChild(content)
+	return sdt
+}
+
+// ensureHeadingBookmarks 返回每个标题的目录书签名称，标题没有 _Toc 书签时添加
+func ensureHeadingBookmarks(body *Node, headings []Heading) []string {
+	names := make(map[string]bool)
+	nextID := 0
+	for _, b := range body.Find("w:bookmarkStart") {
+		names[b.Attr("w:name")] = true
+		if id, err := strconv.Atoi(b.Attr("w:id")); err == nil && id >= nextID {
+			nextID = id + 1
+		}
+	}
+
+	paragraphs := collectParagraphs(body)
+	counter := 1
+	bookmarks := make([]string, len(headings))
+	for i, h := range headings {
+		p := paragraphs[h.Paragraph]
+		for _, b := range p.ChildrenNamed("w:bookmarkStart") {
+			if strings.HasPrefix(b.Attr("w:name"), "_Toc") {
+				bookmarks[i] = b.Attr("w:name")
+				break
+			}
+		}
+		if bookmarks[i] != "" {
+			continue
+		}
+
+		name := ""
+		for name == "" || names[name] {
+			name = fmt.Sprintf("_Toc%09d", counter)
+			counter++
+		}
+		names[name] = true
+		id := strconv.Itoa(nextID)
+		nextID++
+
+		position := 0
+		if p.Child("w:pPr") != nil {
+			position = 1
+		}
+		p.InsertChild(position, NewNode("w:bookmarkStart", "w:id", id, "w:name", name))
+		p.AppendChild(NewNode("w:bookmarkEnd", "w:id", id))
+		bookmarks[i] = name
+	}
+	return bookmarks
+}
+
+// textWidth 返回最后一节的版心宽度，单位为缇
+func (doc *Document) textWidth() int {
+	sections, err := doc.Sections()
+	if err != nil || len(sections) == 0 || sections[len(sections)-1].TextWidth() <= 0 {
+		return defaultTextWidth
+	}
+	return sections[len(sections)-1].TextWidth()
+}
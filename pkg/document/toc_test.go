@@ -0,0 +1,195 @@
+package document
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testPages 测试用的排版结果：第 i 个段落位于第 i+1 页
+type testPages struct{}
+
+func (testPages) ParagraphPages(doc *Document) ([]int, error) {
+	pages := make([]int, len(doc.paragraphNodes()))
+	for i := range pages {
+		pages[i] = i + 1
+	}
+	return pages, nil
+}
+
+// tocEntryAnchors 返回目录项超链接指向的书签
+func tocEntryAnchors(t *testing.T, doc *Document) []string {
+	t.Helper()
+	toc := findTOC(testBody(t, doc))
+	if toc == nil {
+		t.Fatalf("文档中没有目录")
+	}
+	var anchors []string
+	for _, p := range toc.paragraphs() {
+		for _, link := range p.ChildrenNamed("w:hyperlink") {
+			anchors = append(anchors, link.Attr("w:anchor"))
+		}
+	}
+	return anchors
+}
+
+// headingBookmark 返回指定名称的书签的ID和所在的段落，没有找到时段落为-1
+func headingBookmark(doc *Document, name string) (string, int) {
+	for i, p := range doc.paragraphNodes() {
+		for _, b := range p.ChildrenNamed("w:bookmarkStart") {
+			if b.Attr("w:name") == name {
+				return b.Attr("w:id"), i
+			}
+		}
+	}
+	return "", -1
+}
+
+func TestTOCLevels(t *testing.T) {
+	tests := []struct {
+		instr string
+		want  int
+	}{
+		{`TOC \o "1-3" \h \z \u`, 3},
+		{`TOC \o "1-5" \n`, 5},
+		{`TOC \o"2-4"`, 4},
+		{`TOC \h \z`, 3},
+		{`TOC \o "1-0"`, 3},
+	}
+	for _, tt := range tests {
+		if got := (&tocField{instr: tt.instr}).levels(); got != tt.want {
+			t.Errorf("%q 的目录级别 %d, 期望 %d", tt.instr, got, tt.want)
+		}
+	}
+}
+
+func TestInsertTOC(t *testing.T) {
+	doc := newBodyTestDocument(t, styledParagraph("Heading1", "第一章")+
+		`<w:p><w:r><w:t>正文</w:t></w:r></w:p>`+
+		styledParagraph("Heading2", "第一节")+
+		styledParagraph("Heading4", "第四级标题"))
+	if doc.HasTOC() {
+		t.Fatalf("没有目录的文档 HasTOC() 为真")
+	}
+	if err := doc.InsertTOC(0, TOCOptions{}); err != nil {
+		t.Fatalf("插入目录失败: %v", err)
+	}
+	if !doc.HasTOC() {
+		t.Fatalf("插入后 HasTOC() 为假")
+	}
+	want := []string{"目录", "第一章", "第一节", "第一章", "正文", "第一节", "第四级标题"}
+	if got := paragraphTexts(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("插入目录后的段落 %q, 期望 %q", got, want)
+	}
+
+	// 目录包装在内容控件中，指令不显示页码
+	body := testBody(t, doc)
+	sdt := body.Elements()[0]
+	if sdt.Name != "w:sdt" || !isTOCGallery(sdt) {
+		t.Fatalf("目录没有包装在目录内容控件中: %s", sdt)
+	}
+	toc := findTOC(body)
+	if toc.sdt != sdt || !strings.Contains(toc.instr, `\o "1-3"`) || !strings.Contains(toc.instr, `\n`) {
+		t.Errorf("目录域 %+v", toc)
+	}
+
+	// 标题添加了 _Toc 书签，目录项链接到书签
+	anchors := tocEntryAnchors(t, doc)
+	if !reflect.DeepEqual(anchors, []string{"_Toc000000001", "_Toc000000002"}) {
+		t.Errorf("目录项链接 %q", anchors)
+	}
+	heading := doc.paragraphNodes()[3]
+	if names := elementNames(heading); len(names) < 3 || names[0] != "w:pPr" || names[1] != "w:bookmarkStart" {
+		t.Errorf("标题中书签的位置 %q", names)
+	}
+	if _, paragraph := headingBookmark(doc, "_Toc000000001"); paragraph != 3 {
+		t.Errorf("标题书签位于段落 %d, 期望 3", paragraph)
+	}
+
+	if err := doc.InsertTOC(0, TOCOptions{}); err == nil {
+		t.Errorf("重复插入目录没有返回错误")
+	}
+}
+
+func TestInsertTOCBookmarks(t *testing.T) {
+	doc := newBodyTestDocument(t, `<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:bookmarkStart w:id="5" w:name="_Toc123"/>`+
+		`<w:r><w:t>已有书签</w:t></w:r><w:bookmarkEnd w:id="5"/></w:p>`+
+		`<w:p><w:bookmarkStart w:id="0" w:name="_Toc000000001"/><w:r><w:t>正文</w:t></w:r><w:bookmarkEnd w:id="0"/></w:p>`+
+		styledParagraph("Heading1", "新标题"))
+	if err := doc.InsertTOC(3, TOCOptions{Levels: 1}); err != nil {
+		t.Fatalf("插入目录失败: %v", err)
+	}
+	if got := paragraphTexts(doc); got[3] != "目录" {
+		t.Errorf("目录没有插入到文档末尾: %q", got)
+	}
+	if sdt := testBody(t, doc).Elements()[3]; sdt.Name != "w:sdt" {
+		t.Errorf("目录没有位于节属性之前: %s", sdt)
+	}
+	// 已有的 _Toc 书签直接使用，新书签名称和ID不与已有的重复
+	if got, want := tocEntryAnchors(t, doc), []string{"_Toc123", "_Toc000000002"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("目录项链接 %q, 期望 %q", got, want)
+	}
+	if id, paragraph := headingBookmark(doc, "_Toc000000002"); id != "6" || paragraph != 2 {
+		t.Errorf("新标题的书签ID %q, 段落 %d", id, paragraph)
+	}
+}
+
+func TestUpdateTOC(t *testing.T) {
+	doc := newBodyTestDocument(t, styledParagraph("Heading1", "第一章")+styledParagraph("Heading2", "第一节")+
+		styledParagraph("Heading3", "第三级"))
+	if err := doc.InsertTOC(0, TOCOptions{Levels: 2}); err != nil {
+		t.Fatalf("插入目录失败: %v", err)
+	}
+	body := testBody(t, doc)
+	sectPr := body.Child("w:sectPr")
+	body.InsertChild(body.IndexOf(sectPr), parseTestParagraph(t, styledParagraph("Heading1", "第二章")))
+
+	if err := doc.UpdateTOC(TOCOptions{Pages: testPages{}}); err != nil {
+		t.Fatalf("更新目录失败: %v", err)
+	}
+	want := []string{"目录", "第一章\t5", "第一节\t6", "第二章\t8", "第一章", "第一节", "第三级", "第二章"}
+	if got := paragraphTexts(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("更新目录后的段落 %q, 期望 %q", got, want)
+	}
+	toc := findTOC(body)
+	if toc.sdt == nil || !strings.Contains(toc.instr, `\o "1-2"`) || strings.Contains(toc.instr, `\n`) {
+		t.Errorf("更新后的目录域 %+v", toc)
+	}
+	entries := toc.paragraphs()[1:]
+	for _, p := range entries {
+		if len(p.Find("w:tab")) != 2 || len(p.Find("w:instrText")) == 0 {
+			t.Errorf("目录项没有页码: %s", p)
+		}
+	}
+	if instr := entries[0].Find("w:instrText"); !strings.Contains(instr[len(instr)-1].InnerText(), "PAGEREF _Toc000000001") {
+		t.Errorf("目录项的页码域 %s", instr[len(instr)-1])
+	}
+
+	if err := doc.UpdateTOC(TOCOptions{Levels: 3}); err != nil {
+		t.Fatalf("更新目录失败: %v", err)
+	}
+	if got := tocEntryAnchors(t, doc); len(got) != 4 {
+		t.Errorf("改为3级后目录项 %q", got)
+	}
+}
+
+func TestUpdateTOCWithoutControl(t *testing.T) {
+	field := `<w:p><w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText xml:space="preserve"> TOC \o "1-1" </w:instrText></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>旧目录项</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>旧目录项</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>`
+	doc := newBodyTestDocument(t, `<w:p><w:r><w:t>封面</w:t></w:r></w:p>`+field+styledParagraph("Heading1", "标题"))
+	if err := doc.UpdateTOC(TOCOptions{}); err != nil {
+		t.Fatalf("更新目录失败: %v", err)
+	}
+	if got, want := paragraphTexts(doc), []string{"封面", "标题", "标题"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("更新目录后的段落 %q, 期望 %q", got, want)
+	}
+	if toc := findTOC(testBody(t, doc)); toc == nil || toc.sdt != nil || toc.start != 1 || toc.end != 1 {
+		t.Errorf("更新后的目录域 %+v", toc)
+	}
+
+	doc = newTestDocument(t, "正文")
+	if err := doc.UpdateTOC(TOCOptions{}); err == nil {
+		t.Errorf("更新没有目录的文档没有返回错误")
+	}
+}
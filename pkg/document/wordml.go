@@ -0,0 +1,697 @@
+package document
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// pPrOrder w:pPr 子元素的架构顺序
+var pPrOrder = []string{
+	"w:pStyle", "w:keepNext", "w:keepLines", "w:pageBreakBefore", "w:framePr",
+	"w:widowControl", "w:numPr", "w:suppressLineNumbers", "w:pBdr", "w:shd",
+	"w:tabs", "w:suppressAutoHyphens", "w:kinsoku", "w:wordWrap", "w:overflowPunct",
+	"w:topLinePunct", "w:autoSpaceDE", "w:autoSpaceDN", "w:bidi", "w:adjustRightInd",
+	"w:snapToGrid", "w:spacing", "w:ind", "w:contextualSpacing", "w:mirrorIndents",
+	"w:suppressOverlap", "w:jc", "w:textDirection", "w:textAlignment",
+	"w:textboxTightWrap", "w:outlineLvl", "w:divId", "w:cnfStyle", "w:rPr",
+	"w:sectPr", "w:pPrChange",
+}
+
+// rPrOrder w:rPr 子元素的架构顺序
+var rPrOrder = []string{
+	"w:ins", "w:del", "w:moveFrom", "w:moveTo",
+	"w:rStyle", "w:rFonts", "w:b", "w:bCs", "w:i", "w:iCs", "w:caps", "w:smallCaps",
+	"w:strike", "w:dstrike", "w:outline", "w:shadow", "w:emboss", "w:imprint",
+	"w:noProof", "w:snapToGrid", "w:vanish", "w:webHidden", "w:color", "w:spacing",
+	"w:w", "w:kern", "w:position", "w:sz", "w:szCs", "w:highlight", "w:u",
+	"w:effect", "w:bdr", "w:shd", "w:fitText", "w:vertAlign", "w:rtl", "w:cs",
+	"w:em", "w:lang", "w:eastAsianLayout", "w:specVanish", "w:oMath", "w:rPrChange",
+}
+
+// styleOrder w:style 子元素的架构顺序
+var styleOrder = []string{
+	"w:name", "w:aliases", "w:basedOn", "w:next", "w:link", "w:autoRedefine", "w:hidden",
+	"w:uiPriority", "w:semiHidden", "w:unhideWhenUsed", "w:qFormat", "w:locked",
+	"w:personal", "w:personalCompose", "w:personalReply", "w:rsid",
+	"w:pPr", "w:rPr", "w:tblPr", "w:trPr", "w:tcPr", "w:tblStylePr",
+}
+
+// sectPrOrder w:sectPr 子元素的架构顺序
+var sectPrOrder = []string{
+	"w:headerReference", "w:footerReference", "w:footnotePr", "w:endnotePr", "w:type",
+	"w:pgSz", "w:pgMar", "w:paperSrc", "w:pgBorders", "w:lnNumType", "w:pgNumType",
+	"w:cols", "w:formProt", "w:vAlign", "w:noEndnote", "w:titlePg", "w:textDirection",
+	"w:bidi", "w:rtlGutter", "w:docGrid", "w:printerSettings", "w:sectPrChange",
+}
+
+// settingsOrder w:settings 子元素的架构顺序
+var settingsOrder = []string{
+	"w:writeProtection", "w:view", "w:zoom", "w:removePersonalInformation",
+	"w:removeDateAndTime", "w:doNotDisplayPageBoundaries", "w:displayBackgroundShape",
+	"w:printPostScriptOverText", "w:printFractionalCharacterWidth", "w:printFormsData",
+	"w:embedTrueTypeFonts", "w:embedSystemFonts", "w:saveSubsetFonts", "w:saveFormsData",
+	"w:mirrorMargins", "w:alignBordersAndEdges", "w:bordersDoNotSurroundHeader",
+	"w:bordersDoNotSurroundFooter", "w:gutterAtTop", "w:hideSpellingErrors",
+	"w:hideGrammaticalErrors", "w:activeWritingStyle", "w:proofState", "w:formsDesign",
+	"w:attachedTemplate", "w:linkStyles", "w:stylePaneFormatFilter", "w:stylePaneSortMethod",
+	"w:documentType", "w:mailMerge", "w:revisionView", "w:trackRevisions", "w:doNotTrackMoves",
+	"w:doNotTrackFormatting", "w:documentProtection", "w:autoFormatOverride",
+	"w:styleLockTheme", "w:styleLockQFSet", "w:defaultTabStop", "w:autoHyphenation",
+	"w:consecutiveHyphenLimit", "w:hyphenationZone", "w:doNotHyphenateCaps", "w:showEnvelope",
+	"w:summaryLength", "w:clickAndTypeStyle", "w:defaultTableStyle", "w:evenAndOddHeaders",
+	"w:bookFoldRevPrinting", "w:bookFoldPrinting", "w:bookFoldPrintingSheets",
+	"w:drawingGridHorizontalSpacing", "w:drawingGridVerticalSpacing",
+	"w:displayHorizontalDrawingGridEvery", "w:displayVerticalDrawingGridEvery",
+	"w:doNotUseMarginsForDrawingGridOrigin", "w:drawingGridHorizontalOrigin",
+	"w:drawingGridVerticalOrigin", "w:doNotShadeFormData", "w:noPunctuationKerning",
+	"w:characterSpacingControl", "w:printTwoOnOne", "w:strictFirstAndLastChars",
+	"w:noLineBreaksAfter", "w:noLineBreaksBefore", "w:savePreviewPicture",
+	"w:doNotValidateAgainstSchema", "w:saveInvalidXml", "w:ignoreMixedContent",
+	"w:alwaysShowPlaceholderText", "w:doNotDemarcateInvalidXml", "w:saveXmlDataOnly",
+	"w:useXSLTWhenSaving", "w:saveThroughXslt", "w:showXMLTags",
+	"w:alwaysMergeEmptyNamespace", "w:updateFields", "w:hdrShapeDefaults", "w:footnotePr",
+	"w:endnotePr", "w:compat", "w:docVars", "w:rsids", "m:mathPr", "w:attachedSchema",
+	"w:themeFontLang", "w:clrSchemeMapping", "w:doNotIncludeSubdocsInStats",
+	"w:doNotAutoCompressPictures", "w:forceUpgrade", "w:captions", "w:readModeInkLockDown",
+	"w:smartTagType", "sl:schemaLibrary", "w:shapeDefaults", "w:doNotEmbedSmartTags",
+	"w:decimalSymbol", "w:listSeparator",
+}
+
+// newParagraphNode 创建段落节点，style 为空时不设置段落样式
+func newParagraphNode(style string) *Node {
+	p := NewNode("w:p")
+	if style != "" {
+		pPr := paragraphProperties(p)
+		pPr.EnsureChild("w:pStyle", pPrOrder).SetAttr("w:val", style)
+	}
+	return p
+}
+
+// paragraphProperties 返回段落的 w:pPr，没有时创建为段落的第一个子元素
+func paragraphProperties(p *Node) *Node {
+	if pPr := p.Child("w:pPr"); pPr != nil {
+		return pPr
+	}
+	pPr := NewNode("w:pPr")
+	p.InsertChild(0, pPr)
+	return pPr
+}
+
+// runProperties 返回Run的 w:rPr，没有时创建为Run的第一个子元素
+func runProperties(r *Node) *Node {
+	if rPr := r.Child("w:rPr"); rPr != nil {
+		return rPr
+	}
+	rPr := NewNode("w:rPr")
+	r.InsertChild(0, rPr)
+	return rPr
+}
+
+// newRunNode 创建包含文本的Run节点，rPr 可以为nil
+func newRunNode(text string, rPr *Node) *Node {
+	r := NewNode("w:r")
+	if rPr != nil {
+		r.AppendChild(rPr)
+	}
+	appendRunText(r, text, "w:t")
+	return r
+}
+
+// appendRunText 将文本写入Run，制表符和换行转换为对应元素
+//
+// textName 为 w:t 或删除修订中使用的 w:delText。
+func appendRunText(r *Node, text, textName string) {
+	var current strings.Builder
+	flush := func() {
+		if current.Len() == 0 {
+			return
+		}
+		s := current.String()
+		t := NewNode(textName)
+		if strings.TrimSpace(s) != s || strings.Contains(s, "  ") {
+			t.SetAttr("xml:space", "preserve")
+		}
+		t.SetText(s)
+		r.AppendChild(t)
+		current.Reset()
+	}
+
+	for _, ch := range text {
+		switch ch {
+		case '\t':
+			flush()
+			r.AppendChild(NewNode("w:tab"))
+		case '\n':
+			flush()
+			r.AppendChild(NewNode("w:br"))
+		default:
+			current.WriteRune(ch)
+		}
+	}
+	flush()
+}
+
+// paragraphStyle 返回段落节点的样式ID
+func paragraphStyle(p *Node) string {
+	if pPr := p.Child("w:pPr"); pPr != nil {
+		if style := pPr.Child("w:pStyle"); style != nil {
+			return style.Attr("w:val")
+		}
+	}
+	return ""
+}
+
+// onOffValue 解析 w:b 等开关属性，元素存在且 w:val 不为假值时为真
+func onOffValue(n *Node) bool {
+	if n == nil {
+		return false
+	}
+	switch strings.ToLower(n.Attr("w:val")) {
+	case "0", "false", "off", "none":
+		return false
+	}
+	return true
+}
+
+// fieldTokenPattern 可编辑文本中表示页码域的标记，如 {PAGE}
+var fieldTokenPattern = regexp.MustCompile(`\{(PAGE|NUMPAGES|SECTIONPAGES)\}`)
+
+// editableFields 在可编辑文本中以标记形式显示的域
+var editableFields = map[string]bool{"PAGE": true, "NUMPAGES": true, "SECTIONPAGES": true}
+
+// objectElements 编辑段落文字时需要保留的非文字内容
+var objectElements = []string{"w:drawing", "w:pict", "w:object"}
+
+// paragraphEditText 返回段落的可编辑文本，页码域显示为 {PAGE} 等标记，其它域显示为域结果
+func paragraphEditText(p *Node) string {
+	var builder strings.Builder
+	var instr strings.Builder
+	state := fieldNone
+
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for _, c := range n.Children {
+			switch c.Name {
+			case "w:pPr", "w:rPr", "w:del", "w:moveFrom":
+			case "w:fldSimple":
+				if token := fieldToken(c.Attr("w:instr")); token != "" {
+					builder.WriteString(token)
+				} else {
+					walk(c)
+				}
+			case "w:fldChar":
+				switch c.Attr("w:fldCharType") {
+				case "begin":
+					state = fieldInstruction
+					instr.Reset()
+				case "separate":
+					state = fieldResult
+					if token := fieldToken(instr.String()); token != "" {
+						builder.WriteString(token)
+					} else {
+						state = fieldNone
+					}
+				case "end":
+					if state == fieldInstruction {
+						builder.WriteString(fieldToken(instr.String()))
+					}
+					state = fieldNone
+				}
+			case "w:instrText":
+				if state == fieldInstruction {
+					instr.WriteString(c.InnerText())
+				}
+			case "w:t":
+				if state == fieldNone {
+					builder.WriteString(c.InnerText())
+				}
+			case "w:tab", "w:ptab":
+				if state == fieldNone {
+					builder.WriteString("\t")
+				}
+			case "w:br", "w:cr":
+				if state == fieldNone {
+					builder.WriteString("\n")
+				}
+			default:
+				if c.IsElement() {
+					walk(c)
+				}
+			}
+		}
+	}
+	walk(p)
+	return builder.String()
+}
+
+// 解析复杂域（w:fldChar）时的状态
+const (
+	fieldNone        = iota // 不在域中
+	fieldInstruction        // 读取域代码
+	fieldResult             // 跳过已转换为标记的域结果
+)
+
+// fieldToken 返回域代码对应的可编辑标记，不支持的域返回空字符串
+func fieldToken(instr string) string {
+	fields := strings.Fields(instr)
+	if len(fields) == 0 {
+		return ""
+	}
+	name := strings.ToUpper(fields[0])
+	if !editableFields[name] {
+		return ""
+	}
+	return "{" + name + "}"
+}
+
+// setParagraphEditText 将段落的可编辑文本改为 text，{PAGE} 等标记转换为域
+//
+// 只重写新旧文本不同的部分：相同的开头和结尾保留原来的Run和格式，修改的文字使用修改处第一个Run的字符格式。
+// 段落属性、书签、批注范围和图片等非文字内容保留。段落包含超链接、修订、智能标记或
+// 页码以外的域时返回错误，避免重写段落时丢失这些内容。
+func setParagraphEditText(p *Node, text string) error {
+	units, err := paragraphEditUnits(p)
+	if err != nil {
+		return err
+	}
+	var old []rune
+	for _, u := range units {
+		old = append(old, u.text...)
+	}
+	updated := []rune(text)
+
+	// 新旧文本相同的开头和结尾
+	start := 0
+	for start < len(old) && start < len(updated) && old[start] == updated[start] {
+		start++
+	}
+	end := len(old)
+	for end > start && len(updated)-(len(old)-end) > start && old[end-1] == updated[len(updated)-(len(old)-end)-1] {
+		end--
+	}
+	if start == end && len(old) == len(updated) {
+		return nil
+	}
+
+	// 修改的范围不能只覆盖域的一部分，文字Run在范围边界处拆分
+	pos := 0
+	for _, u := range units {
+		next := pos + len(u.text)
+		if !u.split {
+			if pos < start && start < next {
+				start = pos
+			}
+			if pos < end && end < next {
+				end = next
+			}
+		}
+		pos = next
+	}
+	units = splitEditUnits(p, units, end)
+	units = splitEditUnits(p, units, start)
+	middle := string(updated[start : len(updated)-(len(old)-end)])
+
+	// 删除范围内的文字，新文字放在范围开始处
+	var rPr, before *Node
+	position := -1
+	pos = 0
+	for _, u := range units {
+		next := pos + len(u.text)
+		switch {
+		case len(u.text) == 0:
+		case next <= start:
+			rPr, before = u.runProperties(), u.nodes[len(u.nodes)-1]
+		case pos < end:
+			if position < 0 {
+				position = p.IndexOf(u.nodes[0])
+				rPr = u.runProperties()
+			}
+			for _, n := range u.nodes {
+				p.RemoveChild(n)
+			}
+		case rPr == nil && before == nil:
+			rPr = u.runProperties()
+		}
+		pos = next
+	}
+	if position < 0 {
+		// 纯插入：放在前面的文字之后，没有时放在段落属性之后
+		position = 0
+		if before != nil {
+			position = p.IndexOf(before) + 1
+		} else if p.Child("w:pPr") != nil {
+			position = p.IndexOf(p.Child("w:pPr")) + 1
+		}
+	}
+	if rPr != nil {
+		rPr = rPr.Clone()
+	}
+	p.InsertChild(position, textWithFields(middle, rPr)...)
+	return nil
+}
+
+// editUnit 段落可编辑文本中的一段内容：一个文字Run、一个页码域或一个不含文字的节点
+type editUnit struct {
+	nodes []*Node // 复杂域包含从 begin 到 end 的所有节点
+	text  []rune
+	split bool // 文字Run，可以在任意字符处拆分
+}
+
+// runProperties 返回内容的字符格式，没有时返回nil
+func (u editUnit) runProperties() *Node {
+	for _, n := range u.nodes {
+		r := n
+		if n.Name == "w:fldSimple" {
+			r = n.Child("w:r")
+		}
+		if r != nil && r.Name == "w:r" && r.Child("w:rPr") != nil {
+			return r.Child("w:rPr")
+		}
+	}
+	return nil
+}
+
+// editProtectedElements 编辑段落文字时无法保留、因此不允许编辑的内容
+var editProtectedElements = map[string]bool{
+	"w:hyperlink": true, "w:smartTag": true, "w:ins": true, "w:del": true,
+	"w:moveFrom": true, "w:moveTo": true, "w:sdt": true, "w:customXml": true,
+}
+
+// paragraphEditUnits 将段落的子节点划分为可编辑文本中的内容，段落包含不能编辑的内容时返回错误
+func paragraphEditUnits(p *Node) ([]editUnit, error) {
+	protected := fmt.Errorf("段落包含超链接、修订或页码以外的域，不能直接修改文字")
+
+	var units []editUnit
+	var field *editUnit
+	var instr strings.Builder
+	depth, separated := 0, false
+	for _, c := range p.Children {
+		if c.Name == "w:pPr" {
+			continue
+		}
+		if editProtectedElements[c.Name] {
+			return nil, protected
+		}
+		if c.Name == "w:fldSimple" {
+			token := fieldToken(c.Attr("w:instr"))
+			if token == "" || field != nil {
+				return nil, protected
+			}
+			units = append(units, editUnit{nodes: []*Node{c}, text: []rune(token)})
+			continue
+		}
+
+		begins := field == nil && c.Name == "w:r" && len(c.Find("w:fldChar")) > 0
+		if begins {
+			field = &editUnit{}
+			instr.Reset()
+			depth, separated = 0, false
+		}
+		if field == nil {
+			text := runEditText(c)
+			units = append(units, editUnit{
+				nodes: []*Node{c},
+				text:  []rune(text),
+				split: c.Name == "w:r" && runTextLength(c) == len([]rune(text)),
+			})
+			continue
+		}
+
+		field.nodes = append(field.nodes, c)
+		for _, rc := range c.Children {
+			switch rc.Name {
+			case "w:fldChar":
+				switch rc.Attr("w:fldCharType") {
+				case "begin":
+					depth++
+				case "separate":
+					separated = true
+				case "end":
+					depth--
+				}
+			case "w:instrText":
+				if !separated {
+					instr.WriteString(rc.InnerText())
+				}
+			}
+		}
+		if depth > 1 {
+			return nil, protected
+		}
+		if depth == 0 {
+			token := fieldToken(instr.String())
+			if token == "" {
+				return nil, protected
+			}
+			field.text = []rune(token)
+			units = append(units, *field)
+			field = nil
+		}
+	}
+	if field != nil {
+		return nil, protected
+	}
+	return units, nil
+}
+
+// runEditText 返回Run在可编辑文本中的文字
+func runEditText(r *Node) string {
+	if r.Name != "w:r" {
+		return ""
+	}
+	var builder strings.Builder
+	for _, c := range r.Children {
+		switch c.Name {
+		case "w:t":
+			builder.WriteString(c.InnerText())
+		case "w:tab", "w:ptab":
+			builder.WriteString("\t")
+		case "w:br", "w:cr":
+			builder.WriteString("\n")
+		}
+	}
+	return builder.String()
+}
+
+// splitEditUnits 在可编辑文本的第 offset 个字符处拆分文字Run，返回拆分后的内容
+func splitEditUnits(p *Node, units []editUnit, offset int) []editUnit {
+	pos := 0
+	for i, u := range units {
+		next := pos + len(u.text)
+		if u.split && pos < offset && offset < next {
+			left, right := splitRun(u.nodes[0], offset-pos)
+			p.ReplaceChild(u.nodes[0], left, right)
+			result := append([]editUnit{}, units[:i]...)
+			result = append(result,
+				editUnit{nodes: []*Node{left}, text: u.text[:offset-pos], split: true},
+				editUnit{nodes: []*Node{right}, text: u.text[offset-pos:], split: true})
+			return append(result, units[i+1:]...)
+		}
+		pos = next
+	}
+	return units
+}
+
+// textWithFields 将可编辑文本转换为Run和简单域节点
+func textWithFields(text string, rPr *Node) []*Node {
+	cloneRPr := func() *Node {
+		if rPr == nil {
+			return nil
+		}
+		return rPr.Clone()
+	}
+
+	var nodes []*Node
+	last := 0
+	for _, match := range fieldTokenPattern.FindAllStringSubmatchIndex(text, -1) {
+		if match[0] > last {
+			nodes = append(nodes, newRunNode(text[last:match[0]], cloneRPr()))
+		}
+		nodes = append(nodes, newSimpleField(text[match[2]:match[3]], "1", cloneRPr()))
+		last = match[1]
+	}
+	if last < len(text) {
+		nodes = append(nodes, newRunNode(text[last:], cloneRPr()))
+	}
+	return nodes
+}
+
+// newSimpleField 创建简单域，result 为打开文档时更新前显示的结果
+func newSimpleField(instr, result string, rPr *Node) *Node {
+	field := NewNode("w:fldSimple", "w:instr", " "+instr+" ")
+	field.AppendChild(newRunNode(result, rPr))
+	return field
+}
+
+// containsAny 判断节点中是否包含任一指定名称的元素
+func containsAny(n *Node, names []string) bool {
+	for _, name := range names {
+		if len(n.Find(name)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// insertPoint 段落中可以插入Run的位置
+type insertPoint struct {
+	parent *Node
+	index  int
+}
+
+// insert 在插入点插入节点，插入点随之后移
+func (ip *insertPoint) insert(nodes ...*Node) {
+	ip.parent.InsertChild(ip.index, nodes...)
+	ip.index += len(nodes)
+}
+
+// runTextLength 返回Run中可见字符的数量，制表符和换行各计一个字符
+func runTextLength(r *Node) int {
+	length := 0
+	for _, c := range r.Children {
+		length += runChildLength(c)
+	}
+	return length
+}
+
+// runChildLength 返回Run子元素对应的字符数量
+func runChildLength(c *Node) int {
+	switch c.Name {
+	case "w:t":
+		return len([]rune(c.InnerText()))
+	case "w:tab", "w:ptab", "w:br", "w:cr", "w:noBreakHyphen":
+		return 1
+	}
+	return 0
+}
+
+// splitRunsAt 在段落最终文本的第 offset 个字符处拆分Run，返回该位置的插入点
+//
+// 删除修订中的文字不计入位置，offset 超出段落长度时返回段落末尾。
+func splitRunsAt(p *Node, offset int) insertPoint {
+	pos := 0
+	var point *insertPoint
+
+	var walk func(n *Node) bool
+	walk = func(n *Node) bool {
+		for i := 0; i < len(n.Children); i++ {
+			c := n.Children[i]
+			switch {
+			case c.Name == "w:r":
+				length := runTextLength(c)
+				if offset <= pos && length > 0 {
+					point = &insertPoint{parent: n, index: i}
+					return true
+				}
+				if offset < pos+length {
+					left, right := splitRun(c, offset-pos)
+					n.ReplaceChild(c, left, right)
+					point = &insertPoint{parent: n, index: i + 1}
+					return true
+				}
+				pos += length
+			case c.Name == "w:ins" || c.Name == "w:moveTo" || containerElements[c.Name]:
+				if walk(c) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if walk(p) {
+		return *point
+	}
+	return insertPoint{parent: p, index: len(p.Children)}
+}
+
+// splitRun 在第 k 个字符处将Run拆分为两个格式相同的Run
+func splitRun(r *Node, k int) (*Node, *Node) {
+	left, right := NewNode("w:r"), NewNode("w:r")
+	for _, attr := range r.Attrs {
+		left.SetAttr(attr.Name, attr.Value)
+		right.SetAttr(attr.Name, attr.Value)
+	}
+	if rPr := r.Child("w:rPr"); rPr != nil {
+		left.AppendChild(rPr.Clone())
+		right.AppendChild(rPr.Clone())
+	}
+
+	pos := 0
+	for _, c := range r.Children {
+		if c.Name == "w:rPr" {
+			continue
+		}
+		length := runChildLength(c)
+		switch {
+		case pos+length <= k:
+			left.AppendChild(c)
+		case pos >= k:
+			right.AppendChild(c)
+		default:
+			// 拆分 w:t
+			runes := []rune(c.InnerText())
+			cut := k - pos
+			appendRunText(left, string(runes[:cut]), c.Name)
+			appendRunText(right, string(runes[cut:]), c.Name)
+		}
+		pos += length
+	}
+	return left, right
+}
+
+// newFieldCharRun 创建复杂域的 begin、separate 或 end 标记Run
+func newFieldCharRun(fieldCharType string, rPr *Node) *Node {
+	r := NewNode("w:r")
+	if rPr != nil {
+		r.AppendChild(rPr.Clone())
+	}
+	r.AppendChild(NewNode("w:fldChar", "w:fldCharType", fieldCharType))
+	return r
+}
+
+// newInstrTextRun 创建复杂域的指令Run
+func newInstrTextRun(instr string, rPr *Node) *Node {
+	r := NewNode("w:r")
+	if rPr != nil {
+		r.AppendChild(rPr.Clone())
+	}
+	t := NewNode("w:instrText", "xml:space", "preserve")
+	t.SetText(instr)
+	r.AppendChild(t)
+	return r
+}
+
+// newComplexField 创建由 begin、指令、separate、结果和 end 组成的复杂域Run序列
+func newComplexField(instr, result string, rPr *Node) []*Node {
+	nodes := []*Node{
+		newFieldCharRun("begin", rPr),
+		newInstrTextRun(instr, rPr),
+		newFieldCharRun("separate", rPr),
+	}
+	if result != "" {
+		var props *Node
+		if rPr != nil {
+			props = rPr.Clone()
+		}
+		nodes = append(nodes, newRunNode(result, props))
+	}
+	return append(nodes, newFieldCharRun("end", rPr))
+}
+
+// findParent 返回节点在树中的父节点，不在树中时返回nil
+func findParent(root, target *Node) *Node {
+	var parent *Node
+	root.Walk(func(n, p *Node) bool {
+		if parent != nil {
+			return false
+		}
+		if n == target {
+			parent = p
+			return false
+		}
+		return true
+	})
+	return parent
+}
@@ -0,0 +1,144 @@
+package document
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// describeEditRuns 描述段落的子节点：文字Run为文字（加粗时前缀 *），域为 {域代码}，其它节点为 <名称>
+func describeEditRuns(p *Node) []string {
+	var result []string
+	for _, c := range p.Children {
+		switch {
+		case c.Name == "w:fldSimple":
+			result = append(result, "{"+strings.TrimSpace(c.Attr("w:instr"))+"}")
+		case c.Name == "w:r" && c.Child("w:fldChar") != nil:
+			result = append(result, "{"+c.Child("w:fldChar").Attr("w:fldCharType")+"}")
+		case c.Name == "w:r" && c.Child("w:instrText") != nil:
+			result = append(result, "{"+strings.TrimSpace(c.Child("w:instrText").InnerText())+"}")
+		case c.Name == "w:r":
+			text := runEditText(c)
+			if c.Child("w:rPr") != nil && c.Child("w:rPr").Child("w:b") != nil {
+				text = "*" + text
+			}
+			result = append(result, text)
+		default:
+			result = append(result, "<"+c.Name+">")
+		}
+	}
+	return result
+}
+
+func TestSetParagraphEditText(t *testing.T) {
+	const bold = `<w:rPr><w:b/></w:rPr>`
+	pageField := `<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText> PAGE </w:instrText></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>1</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r>`
+
+	tests := []struct {
+		name      string
+		paragraph string
+		text      string
+		want      []string
+	}{
+		{
+			"修改中间文字保留其它Run的格式",
+			`<w:r>` + bold + `<w:t>第</w:t></w:r><w:r><w:t>1页</w:t></w:r>`,
+			"第2页",
+			[]string{"*第", "2", "页"},
+		},
+		{
+			"插入页码标记",
+			`<w:r>` + bold + `<w:t>第页</w:t></w:r>`,
+			"第{PAGE}页",
+			[]string{"*第", "{PAGE}", "*页"},
+		},
+		{
+			"删除简单页码域",
+			`<w:r><w:t>第</w:t></w:r><w:fldSimple w:instr=" PAGE "><w:r><w:t>1</w:t></w:r></w:fldSimple><w:r><w:t>页</w:t></w:r>`,
+			"第页",
+			[]string{"第", "页"},
+		},
+		{
+			"保留复杂页码域",
+			`<w:r><w:t>第</w:t></w:r>` + pageField + `<w:r><w:t>页</w:t></w:r>`,
+			"第{PAGE}页，共",
+			[]string{"第", "{begin}", "{PAGE}", "{separate}", "1", "{end}", "页", "，共"},
+		},
+		{
+			"修改复杂域后的文字",
+			pageField + `<w:r><w:t>页</w:t></w:r>`,
+			"{PAGE}/",
+			[]string{"{begin}", "{PAGE}", "{separate}", "1", "{end}", "/"},
+		},
+		{
+			"保留书签",
+			`<w:bookmarkStart w:id="0" w:name="标记"/><w:r><w:t>旧</w:t></w:r><w:bookmarkEnd w:id="0"/>`,
+			"新",
+			[]string{"<w:bookmarkStart>", "新", "<w:bookmarkEnd>"},
+		},
+		{
+			"在末尾追加使用前面的格式",
+			`<w:r>` + bold + `<w:t>页眉</w:t></w:r>`,
+			"页眉文字",
+			[]string{"*页眉", "*文字"},
+		},
+		{
+			"在开头插入使用后面的格式",
+			`<w:pPr><w:jc w:val="center"/></w:pPr><w:r>` + bold + `<w:t>正文</w:t></w:r>`,
+			"新正文",
+			[]string{"<w:pPr>", "*新", "*正文"},
+		},
+		{
+			"保留图片",
+			`<w:r><w:t>图</w:t></w:r><w:r><w:drawing/></w:r>`,
+			"图片",
+			[]string{"图", "片", ""},
+		},
+		{
+			"清空文字",
+			`<w:r>` + bold + `<w:t>全部</w:t></w:r><w:fldSimple w:instr=" PAGE "><w:r><w:t>1</w:t></w:r></w:fldSimple>`,
+			"",
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parseTestParagraph(t, "<w:p>"+tt.paragraph+"</w:p>")
+			if err := setParagraphEditText(p, tt.text); err != nil {
+				t.Fatalf("修改段落文字失败: %v", err)
+			}
+			if got := paragraphEditText(p); got != tt.text {
+				t.Errorf("修改后的文字 %q, 期望 %q", got, tt.text)
+			}
+			if got := describeEditRuns(p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("修改后的段落 %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetParagraphEditTextProtected(t *testing.T) {
+	tests := []struct {
+		name      string
+		paragraph string
+	}{
+		{"超链接", `<w:r><w:t>访问</w:t></w:r><w:hyperlink r:id="rId1"><w:r><w:t>网站</w:t></w:r></w:hyperlink>`},
+		{"插入修订", `<w:ins w:id="1" w:author="甲"><w:r><w:t>新</w:t></w:r></w:ins>`},
+		{"删除修订", `<w:r><w:t>正文</w:t></w:r><w:del w:id="1" w:author="甲"><w:r><w:delText>旧</w:delText></w:r></w:del>`},
+		{"日期域", `<w:fldSimple w:instr=" DATE "><w:r><w:t>2024-01-02</w:t></w:r></w:fldSimple>`},
+		{"智能标记", `<w:smartTag w:element="date"><w:r><w:t>今天</w:t></w:r></w:smartTag>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parseTestParagraph(t, "<w:p>"+tt.paragraph+"</w:p>")
+			before := p.String()
+			if err := setParagraphEditText(p, "新的文字"); err == nil {
+				t.Errorf("修改包含%s的段落没有返回错误", tt.name)
+			}
+			if p.String() != before {
+				t.Errorf("修改失败后段落被改变: %s", p)
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+package document
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xmlHeader 写出XML部件时使用的声明
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// Attr XML属性，名称保留原始前缀（如 w:val）
+type Attr struct {
+	Name  string
+	Value string
+}
+
+// Node 保留原始命名空间前缀的XML节点树
+//
+// go-word 的解析器只保留段落文字和少量格式，修订、批注、书签等内容会丢失。
+// Node 按原样保存部件中的所有元素和属性，修改后可以无损写回。
+// Name 为空的节点是文本节点，内容保存在 Text 中。
+type Node struct {
+	Name     string
+	Attrs    []Attr
+	Children []*Node
+	Text     string
+}
+
+// NewNode 创建元素节点，attrs 为依次排列的属性名和属性值
+func NewNode(name string, attrs ...string) *Node {
+	n := &Node{Name: name}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		n.Attrs = append(n.Attrs, Attr{Name: attrs[i], Value: attrs[i+1]})
+	}
+	return n
+}
+
+// NewTextNode 创建文本节点
+func NewTextNode(text string) *Node {
+	return &Node{Text: text}
+}
+
+// ParseXML 解析XML内容为节点树，返回根元素
+func ParseXML(data []byte) (*Node, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	var root *Node
+	var stack []*Node
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析XML失败: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &Node{Name: qualifiedName(t.Name)}
+			for _, a := range t.Attr {
+				n.Attrs = append(n.Attrs, Attr{Name: qualifiedName(a.Name), Value: a.Value})
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("解析XML失败: 多余的结束标签 %s", qualifiedName(t.Name))
+			}
+			stack[len(stack)-1].trimWhitespace()
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, &Node{Text: string(t)})
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("解析XML失败: 没有根元素")
+	}
+	return root, nil
+}
+
+// qualifiedName 将原始名称转换为带前缀的名称
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// trimWhitespace 含有子元素时删除元素之间仅用于排版的空白文本
+func (n *Node) trimWhitespace() {
+	hasElement := false
+	for _, c := range n.Children {
+		if c.IsElement() {
+			hasElement = true
+			break
+		}
+	}
+	if !hasElement {
+		return
+	}
+
+	children := n.Children[:0]
+	for _, c := range n.Children {
+		if !c.IsElement() && strings.TrimSpace(c.Text) == "" {
+			continue
+		}
+		children = append(children, c)
+	}
+	n.Children = children
+}
+
+// Bytes 将节点树序列化为带XML声明的完整部件内容
+func (n *Node) Bytes() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xmlHeader)
+	n.write(&buf)
+	return buf.Bytes()
+}
+
+// String 将节点序列化为XML片段
+func (n *Node) String() string {
+	var buf bytes.Buffer
+	n.write(&buf)
+	return buf.String()
+}
+
+// write 递归写出节点
+func (n *Node) write(buf *bytes.Buffer) {
+	if !n.IsElement() {
+		xml.EscapeText(buf, []byte(n.Text))
+		return
+	}
+
+	buf.WriteByte('<')
+	buf.WriteString(n.Name)
+	for _, a := range n.Attrs {
+		buf.WriteByte(' ')
+		buf.WriteString(a.Name)
+		buf.WriteString(`="`)
+		xml.EscapeText(buf, []byte(a.Value))
+		buf.WriteByte('"')
+	}
+	if len(n.Children) == 0 {
+		buf.WriteString("/>")
+		return
+	}
+	buf.WriteByte('>')
+	for _, c := range n.Children {
+		c.write(buf)
+	}
+	buf.WriteString("</")
+	buf.WriteString(n.Name)
+	buf.WriteByte('>')
+}
+
+// IsElement 判断是否为元素节点
+func (n *Node) IsElement() bool {
+	return n.Name != ""
+}
+
+// Attr 获取属性值，不存在时返回空字符串
+func (n *Node) Attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// HasAttr 判断属性是否存在
+func (n *Node) HasAttr(name string) bool {
+	for _, a := range n.Attrs {
+		if a.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SetAttr 设置属性值，属性不存在时追加
+func (n *Node) SetAttr(name, value string) {
+	for i := range n.Attrs {
+		if n.Attrs[i].Name == name {
+			n.Attrs[i].Value = value
+			return
+		}
+	}
+	n.Attrs = append(n.Attrs, Attr{Name: name, Value: value})
+}
+
+// RemoveAttr 删除属性
+func (n *Node) RemoveAttr(name string) {
+	attrs := n.Attrs[:0]
+	for _, a := range n.Attrs {
+		if a.Name != name {
+			attrs = append(attrs, a)
+		}
+	}
+	n.Attrs = attrs
+}
+
+// Elements 返回所有子元素（不含文本节点）
+func (n *Node) Elements() []*Node {
+	var elements []*Node
+	for _, c := range n.Children {
+		if c.IsElement() {
+			elements = append(elements, c)
+		}
+	}
+	return elements
+}
+
+// Child 返回第一个指定名称的子元素
+func (n *Node) Child(name string) *Node {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// ChildrenNamed 返回所有指定名称的子元素
+func (n *Node) ChildrenNamed(name string) []*Node {
+	var result []*Node
+	for _, c := range n.Children {
+		if c.Name == name {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// Find 深度优先查找所有指定名称的后代元素
+func (n *Node) Find(name string) []*Node {
+	var result []*Node
+	n.Walk(func(node, parent *Node) bool {
+		if node != n && node.Name == name {
+			result = append(result, node)
+		}
+		return true
+	})
+	return result
+}
+
+// Walk 深度优先遍历节点树，fn 返回 false 时不再进入该节点的子节点
+func (n *Node) Walk(fn func(node, parent *Node) bool) {
+	n.walk(nil, fn)
+}
+
+// walk 遍历的递归实现
+func (n *Node) walk(parent *Node, fn func(node, parent *Node) bool) {
+	if !fn(n, parent) {
+		return
+	}
+	// 遍历时子节点可能被替换，使用副本
+	children := append([]*Node(nil), n.Children...)
+	for _, c := range children {
+		c.walk(n, fn)
+	}
+}
+
+// AppendChild 追加子节点
+func (n *Node) AppendChild(children ...*Node) {
+	n.Children = append(n.Children, children...)
+}
+
+// InsertChild 在指定位置插入子节点
+func (n *Node) InsertChild(index int, children ...*Node) {
+	if index < 0 {
+		index = 0
+	}
+	if index > len(n.Children) {
+		index = len(n.Children)
+	}
+	rest := append([]*Node(nil), n.Children[index:]...)
+	n.Children = append(append(n.Children[:index], children...), rest...)
+}
+
+// IndexOf 返回子节点的位置，不存在时返回-1
+func (n *Node) IndexOf(child *Node) int {
+	for i, c := range n.Children {
+		if c == child {
+			return i
+		}
+	}
+	return -1
+}
+
+// RemoveChild 删除子节点
+func (n *Node) RemoveChild(child *Node) bool {
+	i := n.IndexOf(child)
+	if i < 0 {
+		return false
+	}
+	n.Children = append(n.Children[:i], n.Children[i+1:]...)
+	return true
+}
+
+// ReplaceChild 用一组节点替换子节点
+func (n *Node) ReplaceChild(child *Node, replacements ...*Node) bool {
+	i := n.IndexOf(child)
+	if i < 0 {
+		return false
+	}
+	n.Children = append(n.Children[:i], n.Children[i+1:]...)
+	n.InsertChild(i, replacements...)
+	return true
+}
+
+// RemoveChildrenNamed 删除所有指定名称的子元素
+func (n *Node) RemoveChildrenNamed(name string) {
+	children := n.Children[:0]
+	for _, c := range n.Children {
+		if c.Name != name {
+			children = append(children, c)
+		}
+	}
+	n.Children = children
+}
+
+// EnsureChild 获取指定名称的子元素，不存在时按 order 给出的顺序插入
+//
+// WordprocessingML 对子元素顺序有严格要求，order 为父元素允许的子元素顺序，
+// 为 nil 时追加到末尾。
+func (n *Node) EnsureChild(name string, order []string) *Node {
+	if child := n.Child(name); child != nil {
+		return child
+	}
+	child := NewNode(name)
+	n.InsertOrdered(child, order)
+	return child
+}
+
+// InsertOrdered 按 order 给出的元素顺序插入子元素
+func (n *Node) InsertOrdered(child *Node, order []string) {
+	rank := indexOfString(order, child.Name)
+	if rank < 0 {
+		n.AppendChild(child)
+		return
+	}
+	for i, c := range n.Children {
+		if r := indexOfString(order, c.Name); r > rank {
+			n.InsertChild(i, child)
+			return
+		}
+	}
+	n.AppendChild(child)
+}
+
+// SetText 将子节点替换为单个文本节点
+func (n *Node) SetText(text string) {
+	n.Children = []*Node{NewTextNode(text)}
+}
+
+// InnerText 返回所有后代文本节点拼接后的内容
+func (n *Node) InnerText() string {
+	var builder strings.Builder
+	n.Walk(func(node, parent *Node) bool {
+		if !node.IsElement() {
+			builder.WriteString(node.Text)
+		}
+		return true
+	})
+	return builder.String()
+}
+
+// Clone 深拷贝节点
+func (n *Node) Clone() *Node {
+	clone := &Node{
+		Name: n.Name,
+		Text: n.Text,
+	}
+	if n.Attrs != nil {
+		clone.Attrs = append([]Attr(nil), n.Attrs...)
+	}
+	for _, c := range n.Children {
+		clone.Children = append(clone.Children, c.Clone())
+	}
+	return clone
+}
+
+// indexOfString 返回字符串在切片中的位置
+func indexOfString(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,643 @@
+// Package index 提供文档库的磁盘全文索引
+//
+// 索引以倒排表形式保存在应用数据目录中，按配置的文件夹增量更新：
+// 通过比较文件的修改时间和大小，只重新索引发生变化的文档。
+// GUI和命令行工具共用同一份索引。
+package index
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io/fs"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// indexVersion 索引文件格式版本，格式不兼容时递增
+const indexVersion = 1
+
+// indexFileName 索引文件名
+const indexFileName = "index.gob"
+
+// previewLength 每个文档保存的预览文本长度（字符数），用于生成搜索摘要
+const previewLength = 4000
+
+// BM25 排序参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// extractText 提取文档纯文本的函数
+var extractText = document.ExtractText
+
+// FileEntry 已索引文件的记录
+type FileEntry struct {
+	Path    string
+	ModTime time.Time
+	Size    int64
+	Length  int      // 词项总数，用于BM25长度归一化
+	Terms   []string // 文件包含的不重复词项，用于增量更新时删除旧倒排
+	Preview string   // 文本开头部分，用于生成摘要
+}
+
+// indexData 持久化到磁盘的索引数据
+type indexData struct {
+	Version  int
+	Folders  []string
+	Files    map[string]*FileEntry
+	Postings map[string]map[string]int // 词项 -> 文件路径 -> 词频
+}
+
+// Index 文档库全文索引
+//
+// GUI和命令行工具可能同时打开同一份索引：每次修改前在锁文件保护下重新读取磁盘上的索引，
+// 搜索前检查索引文件是否已被其它进程更新，因此不会覆盖或忽略对方的修改。
+type Index struct {
+	dir    string
+	mu     sync.RWMutex
+	data   *indexData
+	loaded os.FileInfo // 最近读取或写入的索引文件信息，用于判断文件是否被其它进程更新
+}
+
+// Result 搜索结果
+type Result struct {
+	Path    string
+	Score   float64
+	Snippet string
+}
+
+// UpdateStats 增量更新的统计信息
+type UpdateStats struct {
+	Added     int
+	Updated   int
+	Removed   int
+	Unchanged int
+	Failed    int
+}
+
+// String 返回统计信息的文字描述
+func (s UpdateStats) String() string {
+	return fmt.Sprintf("新增 %d，更新 %d，删除 %d，未变化 %d，失败 %d",
+		s.Added, s.Updated, s.Removed, s.Unchanged, s.Failed)
+}
+
+// DefaultDir 返回默认的索引目录（用户配置目录下的 fyne-word/index）
+func DefaultDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("无法获取用户配置目录: %v", err)
+	}
+	return filepath.Join(configDir, "fyne-word", "index"), nil
+}
+
+// Open 打开指定目录中的索引，不存在时创建空索引
+func Open(dir string) (*Index, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建索引目录失败: %v", err)
+	}
+
+	idx := &Index{
+		dir:  dir,
+		data: newIndexData(),
+	}
+	if err := idx.reloadLocked(); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// reloadLocked 索引文件在上次读取或写入后被修改时重新读取，调用方需持有写锁
+func (idx *Index) reloadLocked() error {
+	path := filepath.Join(idx.dir, indexFileName)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("打开索引文件失败: %v", err)
+	}
+	if idx.loaded != nil && os.SameFile(idx.loaded, info) &&
+		idx.loaded.ModTime().Equal(info.ModTime()) && idx.loaded.Size() == info.Size() {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开索引文件失败: %v", err)
+	}
+	defer file.Close()
+	idx.loaded = info
+
+	var data indexData
+	if err := gob.NewDecoder(file).Decode(&data); err != nil {
+		log.Printf("索引文件损坏，将重新建立索引: %v", err)
+		idx.data = newIndexData()
+		return nil
+	}
+	if data.Version != indexVersion {
+		log.Printf("索引格式版本不匹配 (%d)，将重新建立索引", data.Version)
+		idx.data = newIndexData()
+		idx.data.Folders = data.Folders
+		return nil
+	}
+	if data.Files == nil {
+		data.Files = make(map[string]*FileEntry)
+	}
+	if data.Postings == nil {
+		data.Postings = make(map[string]map[string]int)
+	}
+	idx.data = &data
+	return nil
+}
+
+// refresh 搜索和读取前重新读取被其它进程更新的索引，失败时继续使用内存中的索引
+func (idx *Index) refresh() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if err := idx.reloadLocked(); err != nil {
+		log.Printf("重新读取索引失败: %v", err)
+	}
+}
+
+// modify 在锁文件保护下重新读取索引、执行修改并保存，change 返回错误时不保存
+func (idx *Index) modify(change func() error) error {
+	unlock, err := idx.lockFile()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if err := idx.reloadLocked(); err != nil {
+		return err
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	return idx.saveLocked()
+}
+
+// newIndexData 创建空的索引数据
+func newIndexData() *indexData {
+	return &indexData{
+		Version:  indexVersion,
+		Files:    make(map[string]*FileEntry),
+		Postings: make(map[string]map[string]int),
+	}
+}
+
+// Dir 返回索引所在目录
+func (idx *Index) Dir() string {
+	return idx.dir
+}
+
+// Folders 返回已配置的索引文件夹
+func (idx *Index) Folders() []string {
+	idx.refresh()
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	folders := make([]string, len(idx.data.Folders))
+	copy(folders, idx.data.Folders)
+	return folders
+}
+
+// FileCount 返回已索引的文件数量
+func (idx *Index) FileCount() int {
+	idx.refresh()
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.data.Files)
+}
+
+// AddFolder 添加要索引的文件夹，需调用 Update 才会实际建立索引
+func (idx *Index) AddFolder(folder string) error {
+	folder, err := normalizeFolder(folder)
+	if err != nil {
+		return err
+	}
+
+	added := false
+	err = idx.modify(func() error {
+		for _, f := range idx.data.Folders {
+			if f == folder {
+				return nil
+			}
+		}
+		idx.data.Folders = append(idx.data.Folders, folder)
+		sort.Strings(idx.data.Folders)
+		added = true
+		return nil
+	})
+	if err == nil && added {
+		log.Printf("已添加索引文件夹: %s", folder)
+	}
+	return err
+}
+
+// RemoveFolder 移除索引文件夹，并删除不再属于任何文件夹的已索引文件
+func (idx *Index) RemoveFolder(folder string) error {
+	folder, err := normalizeFolder(folder)
+	if err != nil {
+		return err
+	}
+
+	err = idx.modify(func() error {
+		found := false
+		folders := idx.data.Folders[:0]
+		for _, f := range idx.data.Folders {
+			if f == folder {
+				found = true
+				continue
+			}
+			folders = append(folders, f)
+		}
+		idx.data.Folders = folders
+		if !found {
+			return fmt.Errorf("文件夹未被索引: %s", folder)
+		}
+		for path := range idx.data.Files {
+			if !inFolders(idx.data.Folders, path) {
+				idx.removeFileLocked(path)
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		log.Printf("已移除索引文件夹: %s", folder)
+	}
+	return err
+}
+
+// Update 扫描所有配置的文件夹，增量更新索引并保存到磁盘
+//
+// progress 在处理每个需要重新索引的文件前被调用，可以为nil。
+func (idx *Index) Update(progress func(path string)) (UpdateStats, error) {
+	var stats UpdateStats
+
+	folders := idx.Folders()
+	seen := make(map[string]bool)
+	var changed []string
+
+	for _, folder := range folders {
+		err := filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				log.Printf("扫描文件夹时出错: %v", err)
+				if d != nil && d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || !isIndexable(path) {
+				return nil
+			}
+			if seen[path] {
+				return nil
+			}
+			seen[path] = true
+
+			info, err := d.Info()
+			if err != nil {
+				stats.Failed++
+				return nil
+			}
+
+			idx.mu.RLock()
+			entry, exists := idx.data.Files[path]
+			idx.mu.RUnlock()
+
+			if exists && entry.ModTime.Equal(info.ModTime()) && entry.Size == info.Size() {
+				stats.Unchanged++
+				return nil
+			}
+			changed = append(changed, path)
+			return nil
+		})
+		if err != nil {
+			return stats, fmt.Errorf("扫描文件夹失败 %s: %v", folder, err)
+		}
+	}
+
+	// 提取变化文件的文本时不持有锁，以免阻塞搜索和其它进程
+	type builtEntry struct {
+		entry     *FileEntry
+		termFreqs map[string]int
+	}
+	var built []builtEntry
+	for _, path := range changed {
+		if progress != nil {
+			progress(path)
+		}
+
+		entry, termFreqs, err := buildEntry(path)
+		if err != nil {
+			log.Printf("索引文件失败 %s: %v", path, err)
+			stats.Failed++
+			continue
+		}
+		built = append(built, builtEntry{entry, termFreqs})
+	}
+
+	// 写入前重新读取索引，保留其它进程在扫描期间所做的修改
+	err := idx.modify(func() error {
+		for path := range idx.data.Files {
+			// 删除已不存在的文件和已移除的文件夹中的文件
+			if !inFolders(idx.data.Folders, path) || (!seen[path] && inFolders(folders, path)) {
+				idx.removeFileLocked(path)
+				stats.Removed++
+			}
+		}
+		for _, b := range built {
+			if !inFolders(idx.data.Folders, b.entry.Path) {
+				continue
+			}
+			if _, exists := idx.data.Files[b.entry.Path]; exists {
+				idx.removeFileLocked(b.entry.Path)
+				stats.Updated++
+			} else {
+				stats.Added++
+			}
+			idx.addFileLocked(b.entry, b.termFreqs)
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	log.Printf("索引更新完成: %s", stats)
+	return stats, nil
+}
+
+// Search 按BM25相关度返回与查询匹配的文档，limit<=0 表示不限制数量
+func (idx *Index) Search(query string, limit int) []Result {
+	terms := uniqueTerms(Tokenize(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	idx.refresh()
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	fileCount := float64(len(idx.data.Files))
+	if fileCount == 0 {
+		return nil
+	}
+
+	var totalLen float64
+	for _, entry := range idx.data.Files {
+		totalLen += float64(entry.Length)
+	}
+	avgLen := totalLen / fileCount
+	if avgLen == 0 {
+		avgLen = 1
+	}
+
+	scores := make(map[string]float64)
+	for _, term := range terms {
+		postings := idx.data.Postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (fileCount-df+0.5)/(df+0.5))
+		for path, tf := range postings {
+			entry := idx.data.Files[path]
+			if entry == nil {
+				continue
+			}
+			freq := float64(tf)
+			norm := 1 - bm25B + bm25B*float64(entry.Length)/avgLen
+			scores[path] += idf * freq * (bm25K1 + 1) / (freq + bm25K1*norm)
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for path, score := range scores {
+		results = append(results, Result{
+			Path:    path,
+			Score:   score,
+			Snippet: makeSnippet(idx.data.Files[path].Preview, query),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Path < results[j].Path
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// Save 将索引写入磁盘
+func (idx *Index) Save() error {
+	unlock, err := idx.lockFile()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.saveLocked()
+}
+
+// saveLocked 将索引写入磁盘，调用方需持有写锁和锁文件
+func (idx *Index) saveLocked() error {
+	tempFile, err := os.CreateTemp(idx.dir, indexFileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("创建索引临时文件失败: %v", err)
+	}
+	tempPath := tempFile.Name()
+
+	if err := gob.NewEncoder(tempFile).Encode(idx.data); err != nil {
+		tempFile.Close()
+		os.Remove(tempPath)
+		return fmt.Errorf("写入索引失败: %v", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("写入索引失败: %v", err)
+	}
+
+	// 先写临时文件再重命名，避免写入中断导致索引损坏
+	path := filepath.Join(idx.dir, indexFileName)
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("保存索引失败: %v", err)
+	}
+	if info, err := os.Stat(path); err == nil {
+		idx.loaded = info
+	}
+	return nil
+}
+
+// addFileLocked 将文件加入倒排表，调用方需持有写锁
+func (idx *Index) addFileLocked(entry *FileEntry, termFreqs map[string]int) {
+	idx.data.Files[entry.Path] = entry
+	for term, tf := range termFreqs {
+		postings := idx.data.Postings[term]
+		if postings == nil {
+			postings = make(map[string]int)
+			idx.data.Postings[term] = postings
+		}
+		postings[entry.Path] = tf
+	}
+}
+
+// removeFileLocked 从倒排表中删除文件，调用方需持有写锁
+func (idx *Index) removeFileLocked(path string) {
+	entry := idx.data.Files[path]
+	if entry == nil {
+		return
+	}
+	for _, term := range entry.Terms {
+		postings := idx.data.Postings[term]
+		delete(postings, path)
+		if len(postings) == 0 {
+			delete(idx.data.Postings, term)
+		}
+	}
+	delete(idx.data.Files, path)
+}
+
+// inFolders 判断文件是否位于任一文件夹中
+func inFolders(folders []string, path string) bool {
+	for _, folder := range folders {
+		rel, err := filepath.Rel(folder, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildEntry 提取文件文本并统计词频
+func buildEntry(path string) (*FileEntry, map[string]int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	text, err := extractText(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens := Tokenize(text)
+	termFreqs := make(map[string]int)
+	for _, token := range tokens {
+		termFreqs[token]++
+	}
+
+	terms := make([]string, 0, len(termFreqs))
+	for term := range termFreqs {
+		terms = append(terms, term)
+	}
+
+	return &FileEntry{
+		Path:    path,
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+		Length:  len(tokens),
+		Terms:   terms,
+		Preview: truncateRunes(text, previewLength),
+	}, termFreqs, nil
+}
+
+// isIndexable 判断文件是否需要索引（跳过Word生成的 ~$ 临时锁文件）
+func isIndexable(path string) bool {
+	name := filepath.Base(path)
+	return strings.EqualFold(filepath.Ext(name), ".docx") && !strings.HasPrefix(name, "~$")
+}
+
+// normalizeFolder 将文件夹路径转换为规范的绝对路径
+func normalizeFolder(folder string) (string, error) {
+	if strings.TrimSpace(folder) == "" {
+		return "", fmt.Errorf("文件夹路径为空")
+	}
+	abs, err := filepath.Abs(folder)
+	if err != nil {
+		return "", fmt.Errorf("无效的文件夹路径 %s: %v", folder, err)
+	}
+	return filepath.Clean(abs), nil
+}
+
+// uniqueTerms 去除重复词项并保持顺序
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			result = append(result, term)
+		}
+	}
+	return result
+}
+
+// makeSnippet 在预览文本中查找查询词的位置并截取前后内容作为摘要
+func makeSnippet(preview, query string) string {
+	const context = 40
+
+	text := strings.Join(strings.Fields(preview), " ")
+	runes := []rune(text)
+	lowerRunes := []rune(strings.ToLower(text))
+
+	pos := -1
+	for _, candidate := range append([]string{strings.TrimSpace(query)}, strings.Fields(query)...) {
+		if candidate == "" {
+			continue
+		}
+		if i := strings.Index(string(lowerRunes), strings.ToLower(candidate)); i >= 0 {
+			pos = utf8.RuneCountInString(string(lowerRunes)[:i])
+			break
+		}
+	}
+	if pos < 0 {
+		return truncateRunes(text, context*2)
+	}
+
+	start := pos - context
+	if start < 0 {
+		start = 0
+	}
+	end := pos + context
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(runes) {
+		snippet += "..."
+	}
+	return snippet
+}
+
+// truncateRunes 按字符数截断文本
+func truncateRunes(text string, maxLen int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+	return string(runes[:maxLen])
+}
@@ -0,0 +1,237 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestIndex 在临时目录中创建索引和文档文件夹，文档内容由 texts 按文件名提供
+func newTestIndex(t *testing.T, texts map[string]string) (*Index, string) {
+	t.Helper()
+	folder := t.TempDir()
+	for name := range texts {
+		if err := os.WriteFile(filepath.Join(folder, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saved := extractText
+	extractText = func(path string) (string, error) {
+		return texts[filepath.Base(path)], nil
+	}
+	t.Cleanup(func() { extractText = saved })
+
+	idx, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("打开索引失败: %v", err)
+	}
+	if err := idx.AddFolder(folder); err != nil {
+		t.Fatalf("添加索引文件夹失败: %v", err)
+	}
+	if _, err := idx.Update(nil); err != nil {
+		t.Fatalf("更新索引失败: %v", err)
+	}
+	return idx, folder
+}
+
+// resultNames 返回搜索结果的文件名
+func resultNames(results []Result) []string {
+	var names []string
+	for _, r := range results {
+		names = append(names, filepath.Base(r.Path))
+	}
+	return names
+}
+
+func TestSearchRanking(t *testing.T) {
+	idx, _ := newTestIndex(t, map[string]string{
+		"一次.docx":   "合同 其他 内容 其他 内容 其他 内容",
+		"三次.docx":   "合同 合同 合同 其他 内容 其他 内容",
+		"短文档.docx":  "合同",
+		"无关.docx":   "会议纪要",
+		"英文.docx":   "The Quick brown fox",
+		"~$临时.docx": "合同",
+	})
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		{"词频高和文档短的排在前面", "合同", 0, []string{"短文档.docx", "三次.docx", "一次.docx"}},
+		{"限制数量", "合同", 2, []string{"短文档.docx", "三次.docx"}},
+		{"二元组匹配多字查询", "纪要", 0, []string{"无关.docx"}},
+		{"英文不区分大小写", "QUICK", 0, []string{"英文.docx"}},
+		{"没有匹配", "不存在", 0, nil},
+		{"空查询", " ，", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resultNames(idx.Search(tt.query, tt.limit)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %q, 期望 %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchRareTermScoresHigher(t *testing.T) {
+	idx, _ := newTestIndex(t, map[string]string{
+		"a.docx": "常见 少见",
+		"b.docx": "常见 其他",
+		"c.docx": "常见 其他",
+	})
+	results := idx.Search("常见 少见", 0)
+	if len(results) != 3 || filepath.Base(results[0].Path) != "a.docx" {
+		t.Fatalf("搜索结果 %q, 期望 a.docx 排在第一", resultNames(results))
+	}
+	if results[1].Score >= results[0].Score {
+		t.Errorf("包含少见词项的文档得分 %f 不高于 %f", results[0].Score, results[1].Score)
+	}
+}
+
+func TestUpdateIncremental(t *testing.T) {
+	texts := map[string]string{
+		"保留.docx": "保留 内容",
+		"修改.docx": "苹果 内容",
+		"删除.docx": "删除 内容",
+	}
+	idx, folder := newTestIndex(t, texts)
+
+	texts["修改.docx"] = "香蕉 内容"
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(folder, "修改.docx"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(folder, "删除.docx")); err != nil {
+		t.Fatal(err)
+	}
+	texts["新增.docx"] = "新增 内容"
+	if err := os.WriteFile(filepath.Join(folder, "新增.docx"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := idx.Update(nil)
+	if err != nil {
+		t.Fatalf("更新索引失败: %v", err)
+	}
+	if want := (UpdateStats{Added: 1, Updated: 1, Removed: 1, Unchanged: 1}); stats != want {
+		t.Errorf("更新统计 %+v, 期望 %+v", stats, want)
+	}
+	if got := resultNames(idx.Search("苹果", 0)); got != nil {
+		t.Errorf("修改后仍能搜到旧内容: %q", got)
+	}
+	if got := resultNames(idx.Search("香蕉", 0)); !reflect.DeepEqual(got, []string{"修改.docx"}) {
+		t.Errorf("搜索新内容 %q", got)
+	}
+
+	// 重新打开后索引内容不变
+	reopened, err := Open(idx.Dir())
+	if err != nil {
+		t.Fatalf("重新打开索引失败: %v", err)
+	}
+	if reopened.FileCount() != 3 || !reflect.DeepEqual(reopened.Folders(), idx.Folders()) {
+		t.Errorf("重新打开后有 %d 个文件, 文件夹 %q", reopened.FileCount(), reopened.Folders())
+	}
+
+	if err := reopened.RemoveFolder(folder); err != nil {
+		t.Fatalf("移除索引文件夹失败: %v", err)
+	}
+	if reopened.FileCount() != 0 || reopened.Search("内容", 0) != nil {
+		t.Errorf("移除文件夹后仍有 %d 个文件", reopened.FileCount())
+	}
+}
+
+func TestMakeSnippet(t *testing.T) {
+	long := strings.Repeat("甲", 60) + "关键词" + strings.Repeat("乙", 60)
+	tests := []struct {
+		name           string
+		preview, query string
+		want           string
+	}{
+		{"短文本", "一段  简短的\n文字", "简短", "一段 简短的 文字"},
+		{"不区分大小写", "Hello World", "world", "Hello World"},
+		{"没有匹配时截取开头", strings.Repeat("字", 100), "无", strings.Repeat("字", 80)},
+		{"匹配处前后截取", long, "关键词", "..." + strings.Repeat("甲", 40) + "关键词" + strings.Repeat("乙", 37) + "..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := makeSnippet(tt.preview, tt.query); got != tt.want {
+				t.Errorf("makeSnippet = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSharedIndex(t *testing.T) {
+	gui, folder := newTestIndex(t, map[string]string{"合同.docx": "合同 条款"})
+	cli, err := Open(gui.Dir())
+	if err != nil {
+		t.Fatalf("打开索引失败: %v", err)
+	}
+
+	// 另一个进程添加的文件夹和文件在修改和搜索前重新读取，不会被覆盖
+	other := t.TempDir()
+	if err := os.WriteFile(filepath.Join(other, "会议.docx"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.AddFolder(other); err != nil {
+		t.Fatalf("添加索引文件夹失败: %v", err)
+	}
+	if _, err := gui.Update(nil); err != nil {
+		t.Fatalf("更新索引失败: %v", err)
+	}
+	if got := len(cli.Folders()); got != 2 {
+		t.Errorf("索引文件夹 %d 个, 期望 2 个", got)
+	}
+	if got := resultNames(cli.Search("合同", 0)); !reflect.DeepEqual(got, []string{"合同.docx"}) {
+		t.Errorf("搜索结果 %q, 期望 %q", got, []string{"合同.docx"})
+	}
+
+	if err := gui.RemoveFolder(folder); err != nil {
+		t.Fatalf("移除索引文件夹失败: %v", err)
+	}
+	if got := cli.Folders(); !reflect.DeepEqual(got, []string{other}) {
+		t.Errorf("移除后的索引文件夹 %q, 期望 %q", got, []string{other})
+	}
+	if got := cli.Search("合同", 0); len(got) != 0 {
+		t.Errorf("移除文件夹后仍能搜索到 %q", resultNames(got))
+	}
+}
+
+func TestIndexLockFile(t *testing.T) {
+	saved := lockTimeout
+	lockTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { lockTimeout = saved })
+
+	idx, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("打开索引失败: %v", err)
+	}
+	lockPath := filepath.Join(idx.Dir(), lockFileName)
+	if err := os.WriteFile(lockPath, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.AddFolder(t.TempDir()); err == nil {
+		t.Fatalf("其它进程持有锁时修改索引没有返回错误")
+	}
+	if len(idx.Folders()) != 0 {
+		t.Errorf("获取锁失败时修改了索引")
+	}
+
+	// 过期的锁文件被删除
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.AddFolder(t.TempDir()); err != nil {
+		t.Fatalf("锁文件过期后修改索引失败: %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("修改索引后没有删除锁文件")
+	}
+}
@@ -0,0 +1,45 @@
+package index
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockFileName 修改索引时创建的锁文件，防止GUI和命令行工具同时写入索引
+const lockFileName = "index.lock"
+
+// staleLockAge 锁文件超过该时间未释放时视为持有者已异常退出
+const staleLockAge = time.Minute
+
+// lockTimeout 等待其它进程释放锁的最长时间
+var lockTimeout = 10 * time.Second
+
+// lockFile 创建索引目录中的锁文件，返回释放锁的函数
+func (idx *Index) lockFile() (func(), error) {
+	path := filepath.Join(idx.dir, lockFileName)
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			fmt.Fprintf(file, "%d\n", os.Getpid())
+			file.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("创建索引锁文件失败: %v", err)
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			log.Printf("删除过期的索引锁文件: %s", path)
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("索引正在被其它程序修改，请稍后重试")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
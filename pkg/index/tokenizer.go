@@ -0,0 +1,59 @@
+package index
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenize 将文本切分为索引词项
+//
+// 拉丁文字按字母数字连续片段切分并转为小写；中日韩文字没有空格分词，
+// 因此对每个连续的CJK片段同时产出单字和相邻两字（二元组）词项，
+// 既能匹配单字查询，也能让多字查询按二元组获得更高的相关度。
+func Tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		for i, r := range cjk {
+			tokens = append(tokens, string(r))
+			if i+1 < len(cjk) {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+
+	return tokens
+}
+
+// isCJK 判断字符是否属于需要按字切分的中日韩文字
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
@@ -0,0 +1,32 @@
+package index
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"空文本", "", nil},
+		{"英文转小写", "Hello, World!", []string{"hello", "world"}},
+		{"字母和数字", "go1.22 v2", []string{"go1", "22", "v2"}},
+		{"单个汉字", "字", []string{"字"}},
+		{"汉字单字和二元组", "文档库", []string{"文", "文档", "档", "档库", "库"}},
+		{"中英混排", "使用Go编写", []string{"使", "使用", "用", "go", "编", "编写", "写"}},
+		{"标点分隔中文", "你好，世界", []string{"你", "你好", "好", "世", "世界", "界"}},
+		{"日文假名", "カナ", []string{"カ", "カナ", "ナ"}},
+		{"朝鲜文", "한글", []string{"한", "한글", "글"}},
+		{"其他字母", "Café Ünïcode", []string{"café", "ünïcode"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %q, 期望 %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,532 @@
+package layout
+
+import (
+	"math"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// cellMargin 单元格的左右边距（Word默认0.19厘米），单位为磅
+const cellMargin = 5.4
+
+// tableBorderColor 表格边框的颜色
+const tableBorderColor = "000000"
+
+// Engine 排版引擎，按节的纸张大小、页边距和分栏将正文的段落、表格和图片排到页面上
+//
+// 同一个引擎用于页面预览、统计页数、生成目录页码和导出，
+// Engine 实现了 document.PageLocator 和 document.PageLineCounter。
+type Engine struct {
+	measurer Measurer
+}
+
+// New 创建排版引擎，measurer 为nil时按字符类别估算文字宽度
+func New(measurer Measurer) *Engine {
+	if measurer == nil {
+		measurer = EstimateMeasurer{}
+	}
+	return &Engine{measurer: measurer}
+}
+
+// Layout 排版文档，返回所有页面
+func (e *Engine) Layout(doc *document.Document) (*Result, error) {
+	blocks, err := doc.Blocks()
+	if err != nil {
+		return nil, err
+	}
+	sections, err := doc.Sections()
+	if err != nil {
+		return nil, err
+	}
+	paragraphs := 0
+	for _, b := range blocks {
+		if b.Paragraph >= paragraphs {
+			paragraphs = b.Paragraph + 1
+		}
+	}
+
+	f := &flow{
+		engine:   e,
+		sections: sections,
+		blocks:   blocks,
+		lines:    make(map[int][]Line),
+		result:   &Result{paragraphPages: make([]int, paragraphs)},
+	}
+	for i := range blocks {
+		f.block(i)
+	}
+	if f.page == nil {
+		f.newPage()
+	}
+	return f.result, nil
+}
+
+// Continuous 不分页地排版整篇文档，用于编辑视图
+//
+// 所有内容排在一个高度不限的页面上，版心宽度和页边距使用第一节的页面设置，
+// 不分栏，分页符和分节符不换页。
+func (e *Engine) Continuous(doc *document.Document) (*Page, error) {
+	blocks, err := doc.Blocks()
+	if err != nil {
+		return nil, err
+	}
+	sections, err := doc.Sections()
+	if err != nil {
+		return nil, err
+	}
+	s := sections[0].PageSetup
+	lines, boxes, height := e.layoutBlocks(blocks, twips(s.TextWidth()))
+	page := &Page{
+		Number:  1,
+		Width:   twips(s.Width),
+		Height:  twips(s.Top) + height + twips(s.Bottom),
+		Margins: [4]float64{twips(s.Top), twips(s.Right), twips(s.Bottom), twips(s.Left)},
+		Boxes:   boxes,
+		Lines:   lines,
+	}
+	shift(page.Lines, page.Boxes, twips(s.Left+s.Gutter), twips(s.Top))
+	return page, nil
+}
+
+// ParagraphPages 返回正文每个段落所在的页码
+func (e *Engine) ParagraphPages(doc *document.Document) ([]int, error) {
+	result, err := e.Layout(doc)
+	if err != nil {
+		return nil, err
+	}
+	return result.paragraphPages, nil
+}
+
+// PageCount 返回文档的页数
+func (e *Engine) PageCount(doc *document.Document) (int, error) {
+	result, err := e.Layout(doc)
+	if err != nil {
+		return 0, err
+	}
+	return result.PageCount(), nil
+}
+
+// CountPagesAndLines 返回文档的页数和有内容的行数，实现 document.PageLineCounter
+func (e *Engine) CountPagesAndLines(doc *document.Document) (pages, lines int, err error) {
+	result, err := e.Layout(doc)
+	if err != nil {
+		return 0, 0, err
+	}
+	return result.PageCount(), result.LineCount(), nil
+}
+
+// flow 分页排版的状态
+type flow struct {
+	engine   *Engine
+	sections []document.Section
+	blocks   []document.Block
+	lines    map[int][]Line // 已分行的段落，按块索引缓存
+	result   *Result
+
+	page    *Page
+	section int
+	column  int
+	bandTop float64 // 当前分栏区域的顶端，连续分节符之后从上一节的内容下方开始
+	maxY    float64 // 当前页已使用区域的底端
+	y       float64 // 当前位置
+	empty   bool    // 当前栏还没有内容
+}
+
+// setup 返回当前节的页面设置
+func (f *flow) setup() document.PageSetup {
+	return f.sections[f.section].PageSetup
+}
+
+// columnWidth 返回节中一栏的宽度
+func (f *flow) columnWidth(section int) float64 {
+	s := f.sections[section].PageSetup
+	columns := s.Columns
+	if columns < 1 {
+		columns = 1
+	}
+	return (twips(s.TextWidth()) - twips(s.ColumnSpace)*float64(columns-1)) / float64(columns)
+}
+
+// columnX 返回当前栏左边界在页面上的位置
+func (f *flow) columnX() float64 {
+	s := f.setup()
+	return twips(s.Left+s.Gutter) + float64(f.column)*(f.columnWidth(f.section)+twips(s.ColumnSpace))
+}
+
+// bottom 返回当前页版心的底端
+func (f *flow) bottom() float64 {
+	return f.page.Height - twips(f.setup().Bottom)
+}
+
+// newPage 开始新的一页
+func (f *flow) newPage() {
+	s := f.setup()
+	f.page = &Page{
+		Number:  len(f.result.Pages) + 1,
+		Section: f.section,
+		Width:   twips(s.Width),
+		Height:  twips(s.Height),
+		Margins: [4]float64{twips(s.Top), twips(s.Right), twips(s.Bottom), twips(s.Left)},
+	}
+	f.result.Pages = append(f.result.Pages, f.page)
+	f.column = 0
+	f.bandTop = twips(s.Top)
+	f.maxY = f.bandTop
+	f.y = f.bandTop
+	f.empty = true
+}
+
+// nextColumn 转到下一栏，最后一栏之后转到新的一页
+func (f *flow) nextColumn() {
+	if f.column+1 < f.setup().Columns {
+		f.column++
+		f.y = f.bandTop
+		f.empty = true
+		return
+	}
+	f.newPage()
+}
+
+// startSection 开始块所在的节，按分节符类型换页
+func (f *flow) startSection(section int) {
+	if f.page != nil && section == f.section {
+		return
+	}
+	previous := f.setup()
+	f.section = section
+	if f.page == nil {
+		f.newPage()
+		return
+	}
+	current := f.setup()
+	switch f.sections[section].Break {
+	case document.SectionContinuous:
+		// 纸张大小不同时无法在同一页上继续
+		if previous.Width == current.Width && previous.Height == current.Height {
+			f.column = 0
+			f.bandTop = f.maxY
+			f.y = f.maxY
+			f.empty = len(f.page.Lines) == 0
+			return
+		}
+		f.newPage()
+	case document.SectionEvenPage:
+		f.newPage()
+		if f.page.Number%2 == 1 {
+			f.newPage()
+		}
+	case document.SectionOddPage:
+		f.newPage()
+		if f.page.Number%2 == 0 {
+			f.newPage()
+		}
+	default:
+		f.newPage()
+	}
+}
+
+// block 排版第 i 个块
+func (f *flow) block(i int) {
+	b := f.blocks[i]
+	f.startSection(b.Section)
+	if b.Kind == document.TableBlock {
+		f.table(b)
+		return
+	}
+	f.paragraph(i)
+}
+
+// paragraphLines 返回第 i 个块分行后的结果
+func (f *flow) paragraphLines(i int) []Line {
+	if lines, ok := f.lines[i]; ok {
+		return lines
+	}
+	lines := f.engine.paragraphLines(f.blocks[i], f.columnWidth(f.blocks[i].Section))
+	f.lines[i] = lines
+	return lines
+}
+
+// linesHeight 返回行的总高度
+func linesHeight(lines []Line) float64 {
+	height := 0.0
+	for _, l := range lines {
+		height += l.Height
+	}
+	return height
+}
+
+// keepNextHeight 返回与下一段同页时需要一起放下的高度：后续的与下一段同页段落，以及之后段落的开头几行
+func (f *flow) keepNextHeight(i int) float64 {
+	height := 0.0
+	for k := i + 1; k < len(f.blocks); k++ {
+		next := f.blocks[k]
+		if next.Kind != document.ParagraphBlock || next.Section != f.blocks[i].Section || next.Format.PageBreakBefore {
+			break
+		}
+		lines := f.paragraphLines(k)
+		height += twips(next.Format.SpaceBefore)
+		if !next.Format.KeepNext {
+			n := 1
+			if next.WidowControl {
+				n = 2
+			}
+			if n > len(lines) {
+				n = len(lines)
+			}
+			return height + linesHeight(lines[:n])
+		}
+		height += linesHeight(lines) + twips(next.Format.SpaceAfter)
+	}
+	return height
+}
+
+// paragraph 排版第 i 个块中的段落，按孤行控制、段中不分页和与下一段同页的设置分页
+func (f *flow) paragraph(i int) {
+	b := f.blocks[i]
+	format := b.Format
+	lines := f.paragraphLines(i)
+	before, after := twips(format.SpaceBefore), twips(format.SpaceAfter)
+
+	if format.PageBreakBefore && len(f.page.Lines) > 0 {
+		f.newPage()
+	}
+	if !f.empty && (format.KeepLines || format.KeepNext) {
+		need := before + linesHeight(lines)
+		if format.KeepNext {
+			need += after + f.keepNextHeight(i)
+		}
+		// 整栏都放不下时不再移动，避免留下空白页
+		if f.y+need > f.bottom()+0.01 && need <= f.bottom()-f.bandTop {
+			f.nextColumn()
+		}
+	}
+	// 栏顶端的段前间距不显示
+	if !f.empty {
+		f.y += before
+	}
+
+	for start := 0; start < len(lines); {
+		fit := 0
+		y := f.y
+		for k := start; k < len(lines); k++ {
+			if y+lines[k].Height > f.bottom()+0.01 {
+				break
+			}
+			y += lines[k].Height
+			fit++
+			if lines[k].pageBreak {
+				break
+			}
+		}
+		n := fit
+		if b.WidowControl && start+n < len(lines) && (n == 0 || !lines[start+n-1].pageBreak) {
+			// 段落的末行不单独放到下一页，首行不单独留在上一页
+			if len(lines)-start-n == 1 {
+				n--
+			}
+			if start == 0 && n == 1 {
+				n = 0
+			}
+			if n < 0 {
+				n = 0
+			}
+		}
+		if n == 0 {
+			if !f.empty {
+				f.nextColumn()
+				continue
+			}
+			n = fit
+			if n == 0 {
+				n = 1
+			}
+		}
+		f.placeLines(b, lines[start:start+n])
+		start += n
+		if lines[start-1].pageBreak {
+			f.newPage()
+		} else if start < len(lines) {
+			f.nextColumn()
+		}
+	}
+	f.y += after
+	f.maxY = math.Max(f.maxY, f.y)
+}
+
+// placeLines 将段落的行放到当前位置，同时加入段落的边框和底纹
+func (f *flow) placeLines(b document.Block, lines []Line) {
+	x := f.columnX()
+	top := f.y
+	for _, l := range lines {
+		l.X = x
+		l.Y = f.y
+		f.page.Lines = append(f.page.Lines, l)
+		f.y += l.Height
+	}
+	if box, ok := paragraphDecoration(b, x, f.columnWidth(f.section), top, f.y); ok {
+		f.page.Boxes = append(f.page.Boxes, box)
+	}
+	if b.Paragraph >= 0 && f.result.paragraphPages[b.Paragraph] == 0 {
+		f.result.paragraphPages[b.Paragraph] = f.page.Number
+	}
+	f.empty = false
+	f.maxY = math.Max(f.maxY, f.y)
+}
+
+// paragraphDecoration 返回段落边框和底纹的矩形，段落没有边框和底纹时返回 false
+//
+// 设置了任意一边的边框时绘制完整的边框。
+func paragraphDecoration(b document.Block, x, width, top, bottom float64) (Box, bool) {
+	format := b.Format
+	if format.Shading == "" && format.Border.Sides == 0 {
+		return Box{}, false
+	}
+	left := twips(format.LeftIndent)
+	box := Box{
+		X:      x + left,
+		Y:      top,
+		Width:  width - left - twips(format.RightIndent),
+		Height: bottom - top,
+		Fill:   format.Shading,
+	}
+	if format.Border.Sides != 0 {
+		box.Stroke = format.Border.Color
+		if box.Stroke == "" {
+			box.Stroke = tableBorderColor
+		}
+		// w:sz 的单位为八分之一磅
+		box.StrokeWidth = math.Max(float64(format.Border.Size)/8, 0.5)
+	}
+	return box, true
+}
+
+// rowLayout 排版后的表格行，坐标相对于行的左上角
+type rowLayout struct {
+	height float64
+	lines  []Line
+	boxes  []Box
+}
+
+// table 排版表格，行不跨页拆分，标题行在每页顶端重复
+func (f *flow) table(b document.Block) {
+	width := f.columnWidth(f.section)
+	var headers []rowLayout
+	for r, row := range b.Rows {
+		layout := f.engine.layoutRow(b, row, width)
+		if row.Header && len(headers) == r {
+			headers = append(headers, layout)
+		}
+		if f.y+layout.height > f.bottom()+0.01 && !f.empty {
+			f.nextColumn()
+			if r >= len(headers) {
+				for _, header := range headers {
+					f.placeRow(header)
+				}
+			}
+		}
+		f.placeRow(layout)
+	}
+	f.maxY = math.Max(f.maxY, f.y)
+}
+
+// placeRow 将表格行放到当前位置
+func (f *flow) placeRow(row rowLayout) {
+	lines := append([]Line(nil), row.lines...)
+	boxes := append([]Box(nil), row.boxes...)
+	shift(lines, boxes, f.columnX(), f.y)
+	f.page.Boxes = append(f.page.Boxes, boxes...)
+	f.page.Lines = append(f.page.Lines, lines...)
+	f.y += row.height
+	f.empty = false
+}
+
+// cellWidths 计算行中单元格的宽度，未指定宽度的单元格平分剩余宽度，总宽度超出时按比例缩小
+func cellWidths(row document.TableRow, width float64) []float64 {
+	widths := make([]float64, len(row.Cells))
+	specified, unspecified := 0.0, 0
+	for i, cell := range row.Cells {
+		widths[i] = twips(cell.Width)
+		if cell.Width > 0 {
+			specified += widths[i]
+		} else {
+			unspecified++
+		}
+	}
+	if unspecified > 0 {
+		share := math.Max((width-specified)/float64(unspecified), 2*cellMargin+DefaultFontSize)
+		for i := range widths {
+			if widths[i] == 0 {
+				widths[i] = share
+			}
+		}
+		specified += share * float64(unspecified)
+	}
+	if specified > width {
+		for i := range widths {
+			widths[i] *= width / specified
+		}
+	}
+	return widths
+}
+
+// layoutRow 排版表格的一行
+func (e *Engine) layoutRow(b document.Block, row document.TableRow, width float64) rowLayout {
+	var layout rowLayout
+	widths := cellWidths(row, width)
+	var contents []rowLayout
+	for i, cell := range row.Cells {
+		lines, boxes, height := e.layoutBlocks(cell.Blocks, math.Max(widths[i]-2*cellMargin, 1))
+		contents = append(contents, rowLayout{height: height, lines: lines, boxes: boxes})
+		layout.height = math.Max(layout.height, height)
+	}
+	if row.Exact {
+		layout.height = twips(row.Height)
+	} else {
+		layout.height = math.Max(layout.height, twips(row.Height))
+	}
+
+	x := 0.0
+	for i, cell := range row.Cells {
+		box := Box{X: x, Width: widths[i], Height: layout.height, Fill: cell.Shading}
+		if b.Borders {
+			box.Stroke, box.StrokeWidth = tableBorderColor, 0.5
+		}
+		if box.Fill != "" || box.Stroke != "" {
+			layout.boxes = append(layout.boxes, box)
+		}
+		shift(contents[i].lines, contents[i].boxes, x+cellMargin, 0)
+		layout.boxes = append(layout.boxes, contents[i].boxes...)
+		layout.lines = append(layout.lines, contents[i].lines...)
+		x += widths[i]
+	}
+	return layout
+}
+
+// layoutBlocks 不分页地排版单元格中的块，返回的坐标相对于单元格内容区的左上角
+func (e *Engine) layoutBlocks(blocks []document.Block, width float64) ([]Line, []Box, float64) {
+	var lines []Line
+	var boxes []Box
+	y := 0.0
+	for _, b := range blocks {
+		if b.Kind == document.TableBlock {
+			for _, row := range b.Rows {
+				layout := e.layoutRow(b, row, width)
+				shift(layout.lines, layout.boxes, 0, y)
+				lines = append(lines, layout.lines...)
+				boxes = append(boxes, layout.boxes...)
+				y += layout.height
+			}
+			continue
+		}
+		paragraph := e.paragraphLines(b, width)
+		y += twips(b.Format.SpaceBefore)
+		shift(paragraph, nil, 0, y)
+		height := linesHeight(paragraph)
+		if box, ok := paragraphDecoration(b, 0, width, y, y+height); ok {
+			boxes = append(boxes, box)
+		}
+		lines = append(lines, paragraph...)
+		y += height + twips(b.Format.SpaceAfter)
+	}
+	return lines, boxes, y
+}
@@ -0,0 +1,104 @@
+package layout
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// testLineHeight 测试用的行高，测试页面的版心正好放下5行
+const testLineHeight = 20
+
+// testParagraph 测试用的段落，lines 为段落的行数
+type testParagraph struct {
+	lines  int
+	widow  bool
+	format document.ParagraphFormat
+}
+
+// layoutTestParagraphs 使用固定行高的段落分页，返回每页各行所属的段落
+func layoutTestParagraphs(paragraphs []testParagraph) [][]int {
+	setup := document.PageSetup{Width: 12000, Height: 5 * testLineHeight * 20, Columns: 1}
+	f := &flow{
+		engine:   New(nil),
+		sections: []document.Section{{PageSetup: setup}},
+		lines:    make(map[int][]Line),
+		result:   &Result{paragraphPages: make([]int, len(paragraphs))},
+	}
+	for i, p := range paragraphs {
+		f.blocks = append(f.blocks, document.Block{Paragraph: i, WidowControl: p.widow, Format: p.format})
+		for k := 0; k < p.lines; k++ {
+			f.lines[i] = append(f.lines[i], Line{Height: testLineHeight, Paragraph: i})
+		}
+	}
+	for i := range f.blocks {
+		f.block(i)
+	}
+
+	var pages [][]int
+	for _, page := range f.result.Pages {
+		var lines []int
+		for _, l := range page.Lines {
+			lines = append(lines, l.Paragraph)
+		}
+		pages = append(pages, lines)
+	}
+	return pages
+}
+
+func TestWidowControl(t *testing.T) {
+	tests := []struct {
+		name       string
+		paragraphs []testParagraph
+		want       [][]int
+	}{
+		{"不控制孤行时填满页面", []testParagraph{{lines: 3}, {lines: 3}},
+			[][]int{{0, 0, 0, 1, 1}, {1}}},
+		{"末行不单独放到下一页", []testParagraph{{lines: 1}, {lines: 5, widow: true}},
+			[][]int{{0, 1, 1, 1}, {1, 1}}},
+		{"首行不单独留在上一页", []testParagraph{{lines: 4}, {lines: 3, widow: true}},
+			[][]int{{0, 0, 0, 0}, {1, 1, 1}}},
+		{"三行段落整段移到下一页", []testParagraph{{lines: 3}, {lines: 3, widow: true}},
+			[][]int{{0, 0, 0}, {1, 1, 1}}},
+		{"段落可以在中间分页", []testParagraph{{lines: 2}, {lines: 5, widow: true}},
+			[][]int{{0, 0, 1, 1, 1}, {1, 1}}},
+		{"页面顶端的长段落", []testParagraph{{lines: 6, widow: true}},
+			[][]int{{0, 0, 0, 0}, {0, 0}}},
+		{"两行段落不拆分", []testParagraph{{lines: 4}, {lines: 2, widow: true}},
+			[][]int{{0, 0, 0, 0}, {1, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := layoutTestParagraphs(tt.paragraphs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("分页结果 %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeepTogether(t *testing.T) {
+	tests := []struct {
+		name       string
+		paragraphs []testParagraph
+		want       [][]int
+	}{
+		{"段中不分页", []testParagraph{{lines: 3}, {lines: 3, format: document.ParagraphFormat{KeepLines: true}}},
+			[][]int{{0, 0, 0}, {1, 1, 1}}},
+		{"与下一段同页", []testParagraph{{lines: 3}, {lines: 1, format: document.ParagraphFormat{KeepNext: true}}, {lines: 3, widow: true}},
+			[][]int{{0, 0, 0}, {1, 2, 2, 2}}},
+		{"与下一段同页时下一段可以分页", []testParagraph{{lines: 2}, {lines: 1, format: document.ParagraphFormat{KeepNext: true}}, {lines: 4, widow: true}},
+			[][]int{{0, 0, 1, 2, 2}, {2, 2}}},
+		{"整页放不下时不移动", []testParagraph{{lines: 1}, {lines: 6, format: document.ParagraphFormat{KeepLines: true}}},
+			[][]int{{0, 1, 1, 1, 1}, {1, 1}}},
+		{"段前分页", []testParagraph{{lines: 1}, {lines: 1, format: document.ParagraphFormat{PageBreakBefore: true}}},
+			[][]int{{0}, {1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := layoutTestParagraphs(tt.paragraphs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("分页结果 %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
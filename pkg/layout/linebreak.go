@@ -0,0 +1,52 @@
+package layout
+
+import (
+	"strings"
+	"unicode"
+)
+
+// noLineStart 不能出现在行首的标点（避头点）
+const noLineStart = "，。、；：？！）］｝」』》〉】〗〕”’…‥·～％‰" +
+	",.;:?!)]}%"
+
+// noLineEnd 不能出现在行尾的标点（避尾点）
+const noLineEnd = "（［｛「『《〈【〖〔“‘" +
+	"([{"
+
+// isWide 判断字符是否为全角字符：中日韩文字、全角标点和全角字母
+func isWide(r rune) bool {
+	switch {
+	case unicode.Is(unicode.Han, r),
+		unicode.Is(unicode.Hiragana, r),
+		unicode.Is(unicode.Katakana, r),
+		unicode.Is(unicode.Hangul, r):
+		return true
+	case r >= 0x3000 && r <= 0x303F: // 中日韩符号和标点
+		return true
+	case r >= 0xFF01 && r <= 0xFF60: // 全角字母和标点
+		return true
+	case r == '“' || r == '”' || r == '‘' || r == '’' || r == '…' || r == '—':
+		return true
+	}
+	return false
+}
+
+// canBreak 判断两个相邻字符之间是否可以换行
+//
+// 中日韩文字之间任意位置都可以换行，但遵守避头尾规则；
+// 拉丁文字只在空格之后或连字符之后换行，单词内部不换行。
+func canBreak(prev, next rune) bool {
+	if strings.ContainsRune(noLineStart, next) || strings.ContainsRune(noLineEnd, prev) {
+		return false
+	}
+	if next == ' ' {
+		return false
+	}
+	if prev == ' ' {
+		return true
+	}
+	if isWide(prev) || isWide(next) {
+		return true
+	}
+	return prev == '-' && unicode.IsLetter(next)
+}
@@ -0,0 +1,70 @@
+package layout
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+func TestCanBreak(t *testing.T) {
+	tests := []struct {
+		prev, next rune
+		want       bool
+	}{
+		{'中', '文', true},
+		{'中', '，', false}, // 避头点不在行首
+		{'中', '。', false},
+		{'中', '”', false},
+		{'”', '中', true},
+		{'（', '中', false}, // 避尾点不在行尾
+		{'“', '中', false},
+		{'《', '书', false},
+		{'a', 'b', false}, // 单词内部不换行
+		{'a', ' ', false},
+		{' ', 'a', true},
+		{'a', '中', true},
+		{'中', 'a', true},
+		{'-', 'a', true},
+		{'-', '1', false},
+		{'5', '%', false},
+		{'(', 'a', false},
+	}
+	for _, tt := range tests {
+		if got := canBreak(tt.prev, tt.next); got != tt.want {
+			t.Errorf("canBreak(%q, %q) = %v, 期望 %v", tt.prev, tt.next, got, tt.want)
+		}
+	}
+}
+
+func TestParagraphLinesKinsoku(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		chars float64 // 行宽可以放下的全角字符数
+		want  []string
+	}{
+		{"任意汉字之间换行", "一二三四五六", 3, []string{"一二三", "四五六"}},
+		{"句号不在行首", "一二三。四五", 3, []string{"一二", "三。四", "五"}},
+		{"左括号不在行尾", "一二（三）四", 3, []string{"一二", "（三）", "四"}},
+		{"单词不拆开", "ab cd", 3, []string{"ab cd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := document.Block{Spans: []document.TextSpan{{Text: tt.text}}}
+			lines := New(nil).paragraphLines(b, tt.chars*DefaultFontSize+0.01)
+			var got []string
+			for _, l := range lines {
+				var text strings.Builder
+				for _, f := range l.Fragments {
+					text.WriteString(f.Text)
+				}
+				got = append(got, text.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("分行结果 %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
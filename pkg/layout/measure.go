@@ -0,0 +1,79 @@
+package layout
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultFontSize 文字没有设置字号时使用的字号（五号），单位为磅
+const DefaultFontSize = 10.5
+
+// 字体的纵向度量，按字号的倍数计算；中文字体的自然行高约为字号的1.3倍
+const (
+	ascentRatio  = 1.0
+	descentRatio = 0.3
+)
+
+// Font 排版使用的字体
+type Font struct {
+	Name   string
+	Size   float64 // 字号，单位为磅
+	Bold   bool
+	Italic bool
+}
+
+// ascent 返回字体基线以上的高度
+func (f Font) ascent() float64 {
+	return f.Size * ascentRatio
+}
+
+// descent 返回字体基线以下的高度
+func (f Font) descent() float64 {
+	return f.Size * descentRatio
+}
+
+// Measurer 测量文字宽度
+//
+// 预览界面使用实际渲染的字体测量，使分行结果与显示一致；
+// 没有图形界面时（统计页数、生成目录页码）可以使用 EstimateMeasurer。
+type Measurer interface {
+	// MeasureText 返回文字使用指定字体时的宽度，单位为磅
+	MeasureText(text string, font Font) float64
+}
+
+// EstimateMeasurer 按字符类别估算文字宽度，不依赖字体文件
+//
+// 全角字符的宽度等于字号，拉丁字母按常见比例字体的平均宽度估算。
+type EstimateMeasurer struct{}
+
+// MeasureText 估算文字宽度
+func (EstimateMeasurer) MeasureText(text string, font Font) float64 {
+	width := 0.0
+	for _, r := range text {
+		width += runeWidth(r)
+	}
+	width *= font.Size
+	if font.Bold {
+		width *= 1.05
+	}
+	return width
+}
+
+// runeWidth 返回字符宽度与字号的比值
+func runeWidth(r rune) float64 {
+	switch {
+	case isWide(r):
+		return 1
+	case r == ' ':
+		return 0.25
+	case strings.ContainsRune("iljIf.,:;'|!`", r):
+		return 0.28
+	case strings.ContainsRune("trs()[]{}/\\-\"", r):
+		return 0.36
+	case r == 'm' || r == 'w' || r == 'M' || r == 'W':
+		return 0.8
+	case unicode.IsUpper(r):
+		return 0.66
+	}
+	return 0.52
+}
@@ -0,0 +1,114 @@
+package layout
+
+import (
+	"strings"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// Style 文字片段的显示格式
+type Style struct {
+	Font      Font
+	Color     string // RRGGBB，空表示自动（黑色）
+	Underline bool
+	Strike    bool
+	Highlight string // 突出显示颜色名称，空表示无
+	Link      string // 超链接目标，空表示不是超链接
+}
+
+// Fragment 行中格式相同的一段文字、一张图片或一个制表符
+type Fragment struct {
+	X      float64 // 相对于行左端的位置
+	Width  float64
+	Text   string // 制表符的片段没有文字
+	Style  Style
+	Image  *document.InlineImage // 非nil时片段是图片
+	Height float64               // 图片的显示高度
+	Offset int                   // 片段在段落最终文本中的起始位置
+	Length int                   // 片段占用的字符数，列表编号、注释编号和图片为0
+}
+
+// Line 页面上的一行
+type Line struct {
+	X, Y      float64 // 行左上角在页面上的位置
+	Width     float64 // 行中内容的宽度
+	Indent    float64 // 内容起点相对于行左端的位置，包括缩进和对齐
+	Height    float64
+	Baseline  float64 // 基线到行顶端的距离
+	Paragraph int     // 正文段落索引，表格中的行为-1
+	Start     int     // 行在段落最终文本中的起始位置
+	End       int     // 行在段落最终文本中的结束位置，不含行尾的换行符
+	Fragments []Fragment
+	pageBreak bool // 行后有分页符
+}
+
+// Box 页面上的矩形，用于段落边框、底纹和表格单元格
+type Box struct {
+	X, Y, Width, Height float64
+	Fill                string  // 填充色 RRGGBB，空表示不填充
+	Stroke              string  // 边框颜色 RRGGBB，空表示没有边框
+	StrokeWidth         float64 // 边框宽度，单位为磅
+}
+
+// Page 排版后的一页，坐标和尺寸的单位为磅，原点在页面左上角
+type Page struct {
+	Number  int // 页码，从1开始
+	Section int // 页面所属的节
+	Width   float64
+	Height  float64
+	Margins [4]float64 // 上、右、下、左边距
+	Boxes   []Box      // 先于文字绘制
+	Lines   []Line
+}
+
+// Result 文档的排版结果
+type Result struct {
+	Pages          []*Page
+	paragraphPages []int
+}
+
+// PageCount 返回总页数
+func (r *Result) PageCount() int {
+	return len(r.Pages)
+}
+
+// LineCount 返回所有页面上有文字或图片的行数，空段落和只有列表编号的行不计
+func (r *Result) LineCount() int {
+	count := 0
+	for _, page := range r.Pages {
+		for _, line := range page.Lines {
+			for _, f := range line.Fragments {
+				if f.Image != nil || (f.Length > 0 && strings.TrimSpace(f.Text) != "") {
+					count++
+					break
+				}
+			}
+		}
+	}
+	return count
+}
+
+// ParagraphPage 返回正文段落所在的页码（段落第一行所在的页），索引无效时返回0
+func (r *Result) ParagraphPage(paragraph int) int {
+	if paragraph < 0 || paragraph >= len(r.paragraphPages) {
+		return 0
+	}
+	return r.paragraphPages[paragraph]
+}
+
+// ParagraphPages 返回每个正文段落所在的页码
+func (r *Result) ParagraphPages() []int {
+	return append([]int(nil), r.paragraphPages...)
+}
+
+// shift 平移行和矩形的位置
+func shift(lines []Line, boxes []Box, dx, dy float64) {
+	for i := range lines {
+		lines[i].X += dx
+		lines[i].Y += dy
+	}
+	for i := range boxes {
+		boxes[i].X += dx
+		boxes[i].Y += dy
+	}
+}
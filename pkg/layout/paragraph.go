@@ -0,0 +1,421 @@
+package layout
+
+import (
+	"math"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// defaultTabStop 默认制表位间隔（两个五号字），单位为磅
+const defaultTabStop = 21.0
+
+// twips 将缇转换为磅
+func twips(value int) float64 {
+	return float64(value) / 20
+}
+
+// unitKind 排版单位的类型
+type unitKind int
+
+const (
+	unitText unitKind = iota
+	unitTab
+	unitNewline
+	unitPageBreak
+	unitImage
+)
+
+// char 段落中的字符及其格式
+type char struct {
+	r      rune
+	style  int
+	offset int  // 在段落最终文本中的位置
+	atom   bool // 列表编号、注释编号和图片，不占文本位置
+}
+
+// piece 排版单位中格式相同、位置相连的一段文字
+type piece struct {
+	text   string
+	style  int
+	width  float64
+	offset int
+	length int
+}
+
+// unit 换行时不可拆分的排版单位：拉丁单词及其后的空格、单个全角字符、图片或控制字符
+type unit struct {
+	kind   unitKind
+	chars  []char
+	pieces []piece
+	width  float64 // 不含末尾空格的宽度
+	space  float64 // 末尾空格的宽度，位于行尾时不占位置
+	image  int     // 图片在块中的索引
+	height float64 // 图片的显示高度
+	offset int     // 在段落最终文本中的起始位置
+}
+
+// end 返回排版单位之后在段落最终文本中的位置
+func (u unit) end() int {
+	switch u.kind {
+	case unitText:
+		end := u.offset
+		for _, c := range u.chars {
+			if !c.atom {
+				end = c.offset + 1
+			}
+		}
+		return end
+	case unitImage:
+		return u.offset
+	}
+	return u.offset + 1
+}
+
+// placed 已放入行中的排版单位，x 相对于栏的左边界
+type placed struct {
+	unit
+	x float64
+}
+
+// textLine 分行的中间结果
+type textLine struct {
+	units     []placed
+	right     float64 // 内容的右端
+	start     int     // 在段落最终文本中的起始位置
+	end       int     // 在段落最终文本中的结束位置
+	hard      bool    // 以换行符或段落结束，两端对齐时不拉伸
+	pageBreak bool    // 行后有分页符
+}
+
+// paragraphBox 段落在栏中的位置和缩进
+type paragraphBox struct {
+	left, right float64 // 左右缩进后的边界，相对于栏的左边界
+	first       float64 // 首行缩进，负值为悬挂缩进
+}
+
+// spanStyle 将文字片段的格式转换为显示格式
+func spanStyle(span document.TextSpan) Style {
+	size := DefaultFontSize
+	if span.FontSize > 0 {
+		size = float64(span.FontSize) / 2
+	}
+	return Style{
+		Font:      Font{Name: span.FontName, Size: size, Bold: span.Bold, Italic: span.Italic},
+		Color:     span.Color,
+		Underline: span.Underline,
+		Strike:    span.Strike,
+		Highlight: span.Highlight,
+		Link:      span.Link,
+	}
+}
+
+// paragraphLines 将段落排成行，行的坐标相对于栏的左边界和段落第一行的顶端
+func (e *Engine) paragraphLines(b document.Block, width float64) []Line {
+	styles := make([]Style, 0, len(b.Spans)+1)
+	for _, span := range b.Spans {
+		styles = append(styles, spanStyle(span))
+	}
+	mark := len(styles)
+	styles = append(styles, spanStyle(b.Mark))
+
+	f := b.Format
+	box := paragraphBox{left: twips(f.LeftIndent), right: width - twips(f.RightIndent), first: twips(f.FirstLine)}
+	if f.FirstLineChars != 0 {
+		box.first = float64(f.FirstLineChars) / 100 * styles[mark].Font.Size
+	}
+	if box.right-box.left < styles[mark].Font.Size {
+		box.left, box.right = 0, width
+	}
+
+	var units []unit
+	if b.ListLabel != "" {
+		var label []char
+		for _, r := range b.ListLabel {
+			label = append(label, char{r: r, style: mark, atom: true})
+		}
+		units = append(units, e.makeUnit(label, styles), unit{kind: unitTab, offset: -1})
+	}
+	units = append(units, e.paragraphUnits(b, styles, box)...)
+
+	textLines := e.breakLines(units, styles, box)
+	return e.finishLines(b, textLines, styles, mark, box)
+}
+
+// paragraphUnits 按换行规则将段落文字切分为排版单位
+func (e *Engine) paragraphUnits(b document.Block, styles []Style, box paragraphBox) []unit {
+	var chars []char
+	offset := 0
+	for i, span := range b.Spans {
+		if i < len(b.SpanOffsets) {
+			offset = b.SpanOffsets[i]
+		}
+		for _, r := range span.Text {
+			atom := span.NoteID != "" || r == document.ObjectReplacement
+			chars = append(chars, char{r: r, style: i, offset: offset, atom: atom})
+			if !atom {
+				offset++
+			}
+		}
+	}
+
+	var units []unit
+	var current []char
+	flush := func() {
+		if len(current) > 0 {
+			units = append(units, e.makeUnit(current, styles))
+			current = nil
+		}
+	}
+	image := 0
+	for i, c := range chars {
+		switch c.r {
+		case '\n':
+			flush()
+			units = append(units, unit{kind: unitNewline, offset: c.offset})
+			continue
+		case '\t':
+			flush()
+			units = append(units, unit{kind: unitTab, offset: c.offset})
+			continue
+		case document.PageBreak:
+			flush()
+			units = append(units, unit{kind: unitPageBreak, offset: c.offset})
+			continue
+		case document.ObjectReplacement:
+			flush()
+			if image < len(b.Images) {
+				u := imageUnit(b.Images[image], image, box.right-box.left)
+				u.offset = c.offset
+				units = append(units, u)
+			}
+			image++
+			continue
+		}
+		current = append(current, c)
+		if i+1 == len(chars) || canBreak(c.r, chars[i+1].r) {
+			flush()
+		}
+	}
+	flush()
+	return units
+}
+
+// imageUnit 创建图片的排版单位，比可用宽度宽的图片按比例缩小
+func imageUnit(image document.InlineImage, index int, available float64) unit {
+	width, height := twips(image.Width), twips(image.Height)
+	if width > available && available > 0 {
+		height = height * available / width
+		width = available
+	}
+	return unit{kind: unitImage, width: width, height: height, image: index}
+}
+
+// makeUnit 将字符组合为排版单位，测量各个格式片段的宽度
+func (e *Engine) makeUnit(chars []char, styles []Style) unit {
+	u := unit{kind: unitText, chars: chars, offset: chars[0].offset}
+	start := 0
+	total := 0.0
+	for i := 1; i <= len(chars); i++ {
+		if i < len(chars) && chars[i].style == chars[start].style && chars[i].atom == chars[start].atom &&
+			(chars[i].atom || chars[i].offset == chars[i-1].offset+1) {
+			continue
+		}
+		text := charsText(chars[start:i])
+		width := e.measurer.MeasureText(text, styles[chars[start].style].Font)
+		length := 0
+		if !chars[start].atom {
+			length = i - start
+		}
+		u.pieces = append(u.pieces, piece{text: text, style: chars[start].style, width: width, offset: chars[start].offset, length: length})
+		total += width
+		start = i
+	}
+	trail := len(chars)
+	for trail > 0 && chars[trail-1].r == ' ' {
+		trail--
+	}
+	if trail < len(chars) {
+		u.space = e.measurer.MeasureText(charsText(chars[trail:]), styles[chars[len(chars)-1].style].Font)
+	}
+	u.width = math.Max(total-u.space, 0)
+	return u
+}
+
+// charsText 返回字符序列的文字
+func charsText(chars []char) string {
+	runes := make([]rune, len(chars))
+	for i, c := range chars {
+		runes[i] = c.r
+	}
+	return string(runes)
+}
+
+// nextTab 返回位置 x 之后的下一个制表位，悬挂缩进的位置也是制表位
+func nextTab(x float64, box paragraphBox) float64 {
+	if box.first < 0 && x < box.left-0.01 {
+		return box.left
+	}
+	return (math.Floor(x/defaultTabStop+0.01) + 1) * defaultTabStop
+}
+
+// breakLines 按可用宽度将排版单位分行，放不下的单位移到下一行，比整行还宽的单位按字符拆分
+func (e *Engine) breakLines(units []unit, styles []Style, box paragraphBox) []textLine {
+	var lines []textLine
+	var current textLine
+	x := box.left + box.first
+	current.right = x
+	endLine := func(hard, pageBreak bool, next int) {
+		current.hard, current.pageBreak = hard, pageBreak
+		lines = append(lines, current)
+		x = box.left
+		current = textLine{right: x, start: next, end: next}
+	}
+
+	for i := 0; i < len(units); i++ {
+		u := units[i]
+		switch u.kind {
+		case unitNewline, unitPageBreak:
+			current.end = u.offset
+			endLine(true, u.kind == unitPageBreak, u.offset+1)
+			continue
+		case unitTab:
+			next := math.Min(nextTab(x, box), box.right)
+			current.units = append(current.units, placed{unit{kind: unitTab, width: next - x, offset: u.offset}, x})
+			x = next
+			current.right = x
+			if u.offset >= 0 {
+				current.end = u.end()
+			}
+			continue
+		}
+		if x+u.width > box.right+0.01 && len(current.units) > 0 {
+			endLine(false, false, u.offset)
+		}
+		if x+u.width > box.right+0.01 && u.kind == unitText && len(u.chars) > 1 {
+			parts := make([]unit, 0, len(units)-i+len(u.chars))
+			for _, c := range u.chars {
+				parts = append(parts, e.makeUnit([]char{c}, styles))
+			}
+			units = append(parts, units[i+1:]...)
+			i = -1
+			continue
+		}
+		current.units = append(current.units, placed{u, x})
+		current.right = x + u.width
+		current.end = u.end()
+		x += u.width + u.space
+	}
+	endLine(true, false, 0)
+	return lines
+}
+
+// finishLines 计算行高、对齐方式和两端对齐的间距，生成行中的片段
+func (e *Engine) finishLines(b document.Block, textLines []textLine, styles []Style, mark int, box paragraphBox) []Line {
+	f := b.Format
+	lines := make([]Line, 0, len(textLines))
+	y := 0.0
+	for i, tl := range textLines {
+		// 行高按行中最大的字体和图片计算
+		ascent, descent := 0.0, 0.0
+		for _, p := range tl.units {
+			switch p.kind {
+			case unitText:
+				for _, pc := range p.pieces {
+					ascent = math.Max(ascent, styles[pc.style].Font.ascent())
+					descent = math.Max(descent, styles[pc.style].Font.descent())
+				}
+			case unitImage:
+				ascent = math.Max(ascent, p.height)
+			}
+		}
+		if ascent == 0 {
+			ascent, descent = styles[mark].Font.ascent(), styles[mark].Font.descent()
+		}
+		natural := ascent + descent
+		height := natural
+		switch f.LineRule {
+		case document.LineExact:
+			height = twips(f.LineSpacing)
+		case document.LineAtLeast:
+			height = math.Max(natural, twips(f.LineSpacing))
+		default:
+			if f.LineSpacing > 0 {
+				height = natural * float64(f.LineSpacing) / 240
+			}
+		}
+
+		// 对齐：两端对齐只拉伸最后一个制表位之后的单位
+		start := box.left
+		if i == 0 {
+			start += box.first
+		}
+		extra := math.Max(box.right-tl.right, 0)
+		offset, gap, stretchFrom := 0.0, 0.0, 0
+		for k, p := range tl.units {
+			if p.kind == unitTab {
+				stretchFrom = k + 1
+			}
+		}
+		gaps := len(tl.units) - stretchFrom - 1
+		switch f.Alignment {
+		case document.AlignCenter:
+			offset = extra / 2
+		case document.AlignRight:
+			offset = extra
+		case document.AlignJustify:
+			if !tl.hard && gaps > 0 {
+				gap = extra / float64(gaps)
+			}
+		case document.AlignDistribute:
+			if gaps > 0 {
+				gap = extra / float64(gaps)
+			} else {
+				offset = extra / 2
+			}
+		}
+
+		line := Line{
+			Y: y, Width: tl.right - start, Indent: start + offset, Height: height, Baseline: height - descent,
+			Paragraph: b.Paragraph, Start: tl.start, End: tl.end, pageBreak: tl.pageBreak,
+		}
+		for k, p := range tl.units {
+			x := p.x + offset
+			if k > stretchFrom {
+				x += gap * float64(k-stretchFrom)
+			}
+			switch p.kind {
+			case unitText:
+				for _, pc := range p.pieces {
+					line.addFragment(Fragment{X: x, Width: pc.width, Text: pc.text, Style: styles[pc.style], Offset: pc.offset, Length: pc.length})
+					x += pc.width
+				}
+			case unitTab:
+				if p.offset >= 0 {
+					line.Fragments = append(line.Fragments, Fragment{X: x, Width: p.width, Offset: p.offset, Length: 1})
+				}
+			case unitImage:
+				if p.image < len(b.Images) {
+					line.Fragments = append(line.Fragments, Fragment{X: x, Width: p.width, Image: &b.Images[p.image], Height: p.height, Offset: p.offset})
+				}
+			}
+		}
+		lines = append(lines, line)
+		y += height
+	}
+	return lines
+}
+
+// addFragment 加入文字片段，与前一个格式相同、位置和文本都相连的片段合并
+func (l *Line) addFragment(fragment Fragment) {
+	if n := len(l.Fragments); n > 0 {
+		last := &l.Fragments[n-1]
+		if last.Image == nil && last.Text != "" && last.Style == fragment.Style && math.Abs(last.X+last.Width-fragment.X) < 0.01 &&
+			(last.Length == 0) == (fragment.Length == 0) && last.Offset+last.Length == fragment.Offset {
+			last.Text += fragment.Text
+			last.Width += fragment.Width
+			last.Length += fragment.Length
+			return
+		}
+	}
+	l.Fragments = append(l.Fragments, fragment)
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// clipboardTimeout 通过系统工具读写剪贴板HTML格式的超时时间，这些工具在后台运行，
+// PowerShell 启动较慢，因此留出较长的时间
+const clipboardTimeout = 5 * time.Second
+
+// lastCopied 本程序最近复制的内容，系统剪贴板中仍是这段文字时粘贴使用保存的HTML，
+// 不需要再通过系统工具读取
+var lastCopied document.ClipboardContent
+
+// clipboardWrites 保证后台写入系统剪贴板的操作依次执行，并跳过已经被新的复制取代的内容
+var clipboardWrites struct {
+	sync.Mutex
+	latest int
+}
+
+// writeClipboard 将复制的内容放到剪贴板
+//
+// 纯文本通过 Fyne 立即写入。Windows 上随后在后台通过 PowerShell 同时写入HTML和纯文本，
+// 其他程序粘贴时可以保留格式。Linux 上的 xclip 和 wl-copy 一次只能提供一种格式，
+// 写入HTML会使只接受纯文本的程序无法粘贴，因此只写纯文本，HTML只在本程序内使用。
+func writeClipboard(content document.ClipboardContent) {
+	lastCopied = content
+	fyne.CurrentApp().Clipboard().SetContent(content.Text)
+	if runtime.GOOS != "windows" || content.HTML == "" {
+		return
+	}
+
+	clipboardWrites.Lock()
+	clipboardWrites.latest++
+	id := clipboardWrites.latest
+	clipboardWrites.Unlock()
+	go func() {
+		clipboardWrites.Lock()
+		defer clipboardWrites.Unlock()
+		if id != clipboardWrites.latest {
+			return
+		}
+		if err := writeWindowsClipboardHTML(content); err != nil {
+			log.Printf("写入剪贴板HTML格式失败，只保留纯文本: %v", err)
+		}
+	}()
+}
+
+// windowsSetClipboardScript 从标准输入读取Base64编码的纯文本和CF_HTML，同时放到剪贴板
+const windowsSetClipboardScript = `Add-Type -AssemblyName System.Windows.Forms
+$lines = [Console]::In.ReadToEnd().Split("` + "`" + `n")
+$text = [Text.Encoding]::UTF8.GetString([Convert]::FromBase64String($lines[0].Trim()))
+$html = [Text.Encoding]::UTF8.GetString([Convert]::FromBase64String($lines[1].Trim()))
+$data = New-Object System.Windows.Forms.DataObject
+$data.SetData([System.Windows.Forms.DataFormats]::UnicodeText, $text)
+$data.SetData([System.Windows.Forms.DataFormats]::Html, $html)
+[System.Windows.Forms.Clipboard]::SetDataObject($data, $true)`
+
+// writeWindowsClipboardHTML 通过 PowerShell 将纯文本和HTML格式一起写入Windows剪贴板
+func writeWindowsClipboardHTML(content document.ClipboardContent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), clipboardTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-STA", "-Command", windowsSetClipboardScript)
+	cmd.Stdin = strings.NewReader(base64.StdEncoding.EncodeToString([]byte(content.Text)) + "\n" +
+		base64.StdEncoding.EncodeToString([]byte(cfHTML(content.HTML))) + "\n")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// cfHTML 为HTML加上Windows剪贴板HTML格式（CF_HTML）要求的头部，偏移量按UTF-8字节计算
+func cfHTML(markup string) string {
+	const header = "Version:0.9\r\nStartHTML:%010d\r\nEndHTML:%010d\r\nStartFragment:%010d\r\nEndFragment:%010d\r\n"
+	const startMarker, endMarker = "<!--StartFragment-->", "<!--EndFragment-->"
+	if !strings.Contains(markup, startMarker) || !strings.Contains(markup, endMarker) {
+		markup = "<html><body>" + startMarker + markup + endMarker + "</body></html>"
+	}
+	offset := len(fmt.Sprintf(header, 0, 0, 0, 0))
+	startFragment := offset + strings.Index(markup, startMarker) + len(startMarker)
+	endFragment := offset + strings.Index(markup, endMarker)
+	return fmt.Sprintf(header, offset, offset+len(markup), startFragment, endFragment) + markup
+}
+
+// readClipboard 读取剪贴板，完成后在界面线程中以HTML（没有时为空）和纯文本调用 done
+//
+// 本程序复制的内容直接使用保存的HTML；其他程序放到系统剪贴板中的HTML格式
+// 需要通过系统工具读取，在后台进行，不会阻塞界面。
+func readClipboard(done func(markup, text string)) {
+	text := fyne.CurrentApp().Clipboard().Content()
+	if lastCopied.HTML != "" && text == lastCopied.Text {
+		done(lastCopied.HTML, text)
+		return
+	}
+	name, args := systemClipboardHTMLCommand()
+	if name == "" {
+		done("", text)
+		return
+	}
+	go func() {
+		markup := systemClipboardHTML(name, args)
+		fyne.Do(func() { done(markup, text) })
+	}()
+}
+
+// systemClipboardHTMLCommand 返回读取系统剪贴板HTML格式的命令，当前系统不支持时 name 为空
+func systemClipboardHTMLCommand() (name string, args []string) {
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		if _, err := exec.LookPath("wl-paste"); err == nil {
+			return "wl-paste", []string{"--no-newline", "--type", "text/html"}
+		}
+		if _, err := exec.LookPath("xclip"); err == nil {
+			return "xclip", []string{"-selection", "clipboard", "-t", "text/html", "-o"}
+		}
+	case "windows":
+		return "powershell", []string{"-NoProfile", "-Command", "Get-Clipboard -TextFormatType Html"}
+	}
+	return "", nil
+}
+
+// systemClipboardHTML 通过系统工具读取剪贴板中的HTML格式，没有HTML或读取失败时返回空
+func systemClipboardHTML(name string, args []string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), clipboardTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
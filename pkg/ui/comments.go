@@ -0,0 +1,219 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// commentSummary 返回批注在树形视图中显示的摘要
+func commentSummary(c document.Comment) string {
+	summary := fmt.Sprintf("%s: %s", c.Author, truncateRunes(c.Text, 20))
+	if len(c.Replies) > 0 {
+		summary += fmt.Sprintf(" (%d条回复)", len(c.Replies))
+	}
+	if c.Done {
+		summary = "✔ " + summary
+	}
+	return summary
+}
+
+// commentHeader 返回批注的作者和时间
+func commentHeader(c document.Comment) string {
+	header := c.Author
+	if !c.Date.IsZero() {
+		header += "  " + c.Date.Local().Format("2006-01-02 15:04")
+	}
+	if c.Done {
+		header += "  (已解决)"
+	}
+	return header
+}
+
+// marginNotes 创建显示在段落旁边的批注便签，没有批注时返回nil
+func marginNotes(comments []document.Comment) fyne.CanvasObject {
+	if len(comments) == 0 {
+		return nil
+	}
+	notes := container.NewVBox()
+	for _, c := range comments {
+		bg := canvas.NewRectangle(theme.Color(theme.ColorNameInputBackground))
+		bg.CornerRadius = theme.InputRadiusSize()
+		label := widget.NewLabel("💬 " + commentSummary(c))
+		if c.Done {
+			label.Importance = widget.LowImportance
+		}
+		notes.Add(container.NewStack(bg, label))
+	}
+	return notes
+}
+
+// createCommentsView 创建批注列表视图，可以为段落添加批注
+func (gcv *ContentView) createCommentsView(doc *document.Document) []fyne.CanvasObject {
+	var widgets []fyne.CanvasObject
+
+	widgets = append(widgets, widget.NewLabel("批注"))
+	widgets = append(widgets, widget.NewSeparator())
+
+	if doc.Package == nil {
+		widgets = append(widgets, widget.NewLabel("文档不支持批注"))
+		return widgets
+	}
+
+	comments, err := doc.Comments()
+	if err != nil {
+		widgets = append(widgets, widget.NewLabel(err.Error()))
+		return widgets
+	}
+
+	// 添加批注
+	adapter := document.NewDocumentAdapter(doc)
+	var options []string
+	for i := 0; i < adapter.GetParagraphCount(); i++ {
+		options = append(options, fmt.Sprintf("段落 %d: %s", i+1, truncateRunes(adapter.GetParagraphText(i), 20)))
+	}
+	paragraphSelect := widget.NewSelect(options, nil)
+	paragraphSelect.PlaceHolder = "选择段落"
+	entry := widget.NewMultiLineEntry()
+	entry.SetPlaceHolder("批注内容")
+	addBtn := widget.NewButtonWithIcon("添加批注", theme.ContentAddIcon(), func() {
+		index := paragraphSelect.SelectedIndex()
+		if index < 0 {
+			gcv.afterChange(fmt.Errorf("请选择要添加批注的段落"), "comments")
+			return
+		}
+		doc.Checkpoint("添加批注")
+		_, err := doc.AddComment(index, gcv.currentAuthor(), entry.Text)
+		gcv.afterChange(err, "comments")
+	})
+	widgets = append(widgets, paragraphSelect, entry, container.NewHBox(addBtn))
+	widgets = append(widgets, widget.NewSeparator())
+
+	if len(comments) == 0 {
+		widgets = append(widgets, widget.NewLabel("文档中没有批注"))
+		return widgets
+	}
+	for i, c := range comments {
+		location := "未找到批注范围"
+		if c.Paragraph >= 0 {
+			location = fmt.Sprintf("段落 %d", c.Paragraph+1)
+		}
+		widgets = append(widgets, widget.NewLabel(fmt.Sprintf("批注 %d  %s  %s", i+1, location, commentHeader(c))))
+		text := widget.NewLabel(c.Text)
+		text.Wrapping = fyne.TextWrapWord
+		widgets = append(widgets, text)
+		if len(c.Replies) > 0 {
+			widgets = append(widgets, widget.NewLabel(fmt.Sprintf("%d 条回复", len(c.Replies))))
+		}
+		widgets = append(widgets, widget.NewSeparator())
+	}
+
+	return widgets
+}
+
+// createCommentDetailView 创建批注详细视图，显示回复并支持回复、解决和删除
+func (gcv *ContentView) createCommentDetailView(doc *document.Document, index int) []fyne.CanvasObject {
+	var widgets []fyne.CanvasObject
+
+	comments, err := doc.Comments()
+	if err != nil || index >= len(comments) {
+		return widgets
+	}
+	thread := comments[index]
+	node := fmt.Sprintf("c%d", index+1)
+
+	widgets = append(widgets, widget.NewLabel(fmt.Sprintf("批注 %d", index+1)))
+	widgets = append(widgets, widget.NewSeparator())
+
+	if thread.AnchorText != "" {
+		anchor := widget.NewLabel("批注范围: " + thread.AnchorText)
+		anchor.Wrapping = fyne.TextWrapWord
+		widgets = append(widgets, anchor)
+	}
+
+	// 批注和所有回复
+	for _, c := range append([]document.Comment{thread}, thread.Replies...) {
+		comment := c
+		deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			gcv.confirmDeleteComment(doc, comment, node)
+		})
+		widgets = append(widgets, container.NewBorder(nil, nil, nil, deleteBtn,
+			widget.NewLabelWithStyle(commentHeader(comment), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})))
+		text := widget.NewLabel(comment.Text)
+		text.Wrapping = fyne.TextWrapWord
+		widgets = append(widgets, text)
+	}
+	widgets = append(widgets, widget.NewSeparator())
+
+	// 回复
+	replyEntry := widget.NewMultiLineEntry()
+	replyEntry.SetPlaceHolder("回复内容")
+	replyBtn := widget.NewButton("回复", func() {
+		doc.Checkpoint("回复批注")
+		_, err := doc.ReplyComment(thread.ID, gcv.currentAuthor(), replyEntry.Text)
+		gcv.afterChange(err, node)
+	})
+
+	resolveText := "标记为已解决"
+	if thread.Done {
+		resolveText = "重新打开"
+	}
+	resolveBtn := widget.NewButtonWithIcon(resolveText, theme.ConfirmIcon(), func() {
+		doc.Checkpoint(resolveText)
+		gcv.afterChange(doc.ResolveComment(thread.ID, !thread.Done), node)
+	})
+
+	widgets = append(widgets, replyEntry, container.NewHBox(replyBtn, resolveBtn))
+	return widgets
+}
+
+// createParagraphComments 创建段落详细视图中的批注区域，可以为段落添加批注
+func (gcv *ContentView) createParagraphComments(doc *document.Document, comments []document.Comment, index int) []fyne.CanvasObject {
+	var widgets []fyne.CanvasObject
+
+	widgets = append(widgets, widget.NewLabel("批注"))
+	if notes := marginNotes(comments); notes != nil {
+		widgets = append(widgets, notes)
+	}
+
+	entry := widget.NewMultiLineEntry()
+	entry.SetPlaceHolder("为此段落添加批注")
+	addBtn := widget.NewButtonWithIcon("添加批注", theme.ContentAddIcon(), func() {
+		doc.Checkpoint("添加批注")
+		_, err := doc.AddComment(index, gcv.currentAuthor(), entry.Text)
+		gcv.afterChange(err, fmt.Sprintf("p%d", index+1))
+	})
+	widgets = append(widgets, entry, container.NewHBox(addBtn))
+	return widgets
+}
+
+// confirmDeleteComment 确认后删除批注，删除顶层批注时同时删除所有回复
+func (gcv *ContentView) confirmDeleteComment(doc *document.Document, c document.Comment, node string) {
+	message := "确定删除这条批注吗？"
+	if c.ParentID == "" {
+		node = "comments"
+		if len(c.Replies) > 0 {
+			message = fmt.Sprintf("确定删除这条批注及其 %d 条回复吗？", len(c.Replies))
+		}
+	}
+	remove := func() {
+		doc.Checkpoint("删除批注")
+		gcv.afterChange(doc.DeleteComment(c.ID), node)
+	}
+	if gcv.window == nil {
+		remove()
+		return
+	}
+	dialog.ShowConfirm("删除批注", message, func(ok bool) {
+		if ok {
+			remove()
+		}
+	}, gcv.window)
+}
@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// 比较视图的显示模式
+const (
+	compareModeInline     = "行内"
+	compareModeSideBySide = "并排"
+)
+
+// 比较视图的变化类型筛选项
+const compareFilterAll = "全部变化"
+
+// CompareView 文档比较结果视图，支持行内和并排两种显示方式
+type CompareView struct {
+	comparison    *document.Comparison
+	mode          string
+	filter        string
+	showUnchanged bool
+
+	list    *fyne.Container
+	summary *widget.Label
+	content fyne.CanvasObject
+}
+
+// NewCompareView 创建文档比较结果视图
+func NewCompareView(comparison *document.Comparison) *CompareView {
+	cv := &CompareView{
+		comparison: comparison,
+		mode:       compareModeInline,
+		filter:     compareFilterAll,
+		list:       container.NewVBox(),
+		summary:    widget.NewLabel(""),
+	}
+
+	modeGroup := widget.NewRadioGroup([]string{compareModeInline, compareModeSideBySide}, func(mode string) {
+		if mode == "" {
+			return
+		}
+		cv.mode = mode
+		cv.updateContent()
+	})
+	modeGroup.Horizontal = true
+	modeGroup.SetSelected(cv.mode)
+
+	filterSelect := widget.NewSelect([]string{
+		compareFilterAll,
+		document.ChangeInserted.String(),
+		document.ChangeDeleted.String(),
+		document.ChangeModified.String(),
+		document.ChangeFormatted.String(),
+	}, func(filter string) {
+		cv.filter = filter
+		cv.updateContent()
+	})
+	filterSelect.SetSelected(cv.filter)
+
+	unchangedCheck := widget.NewCheck("显示未变化段落", func(checked bool) {
+		cv.showUnchanged = checked
+		cv.updateContent()
+	})
+
+	stats := comparison.Stats()
+	cv.summary.SetText(fmt.Sprintf("%s → %s：插入 %d，删除 %d，修改 %d，格式 %d",
+		comparison.OldName, comparison.NewName,
+		stats.Inserted, stats.Deleted, stats.Modified, stats.Formatted))
+	cv.summary.Wrapping = fyne.TextWrapWord
+
+	toolbar := container.NewHBox(modeGroup, widget.NewSeparator(), filterSelect, unchangedCheck)
+	cv.content = container.NewBorder(
+		container.NewVBox(cv.summary, toolbar, widget.NewSeparator()),
+		nil, nil, nil,
+		container.NewVScroll(cv.list),
+	)
+
+	cv.updateContent()
+	return cv
+}
+
+// GetWidget 获取Fyne组件
+func (cv *CompareView) GetWidget() fyne.CanvasObject {
+	return cv.content
+}
+
+// updateContent 按当前模式和筛选条件重建比较结果列表
+func (cv *CompareView) updateContent() {
+	var widgets []fyne.CanvasObject
+
+	for _, change := range cv.comparison.Changes {
+		if !cv.isVisible(change) {
+			continue
+		}
+		widgets = append(widgets, cv.createChangeView(change))
+		widgets = append(widgets, widget.NewSeparator())
+	}
+
+	if len(widgets) == 0 {
+		if cv.comparison.HasChanges() {
+			widgets = append(widgets, widget.NewLabel("没有符合条件的变化"))
+		} else {
+			widgets = append(widgets, widget.NewLabel("两个文档内容相同"))
+		}
+	}
+
+	cv.list.Objects = widgets
+	cv.list.Refresh()
+}
+
+// isVisible 判断变化是否符合当前筛选条件
+func (cv *CompareView) isVisible(change document.ParagraphChange) bool {
+	if change.Type == document.ChangeUnchanged {
+		return cv.showUnchanged && cv.filter == compareFilterAll
+	}
+	return cv.filter == compareFilterAll || cv.filter == change.Type.String()
+}
+
+// createChangeView 创建单个段落变化的显示组件
+func (cv *CompareView) createChangeView(change document.ParagraphChange) fyne.CanvasObject {
+	header := widget.NewLabelWithStyle(changeTitle(change), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+
+	var body fyne.CanvasObject
+	if cv.mode == compareModeSideBySide {
+		body = container.NewGridWithColumns(2,
+			newDiffText(change.Segments, document.DiffDelete),
+			newDiffText(change.Segments, document.DiffInsert),
+		)
+	} else {
+		body = newDiffText(change.Segments, -1)
+	}
+
+	items := []fyne.CanvasObject{header, body}
+	for _, format := range change.Formats {
+		formatText := widget.NewRichText(&widget.TextSegment{
+			Text:  "格式变化 " + format.String(),
+			Style: widget.RichTextStyle{ColorName: theme.ColorNameWarning, Inline: true},
+		})
+		formatText.Wrapping = fyne.TextWrapWord
+		items = append(items, formatText)
+	}
+
+	return container.NewVBox(items...)
+}
+
+// changeTitle 生成段落变化的标题
+func changeTitle(change document.ParagraphChange) string {
+	switch {
+	case change.OldIndex >= 0 && change.NewIndex >= 0:
+		return fmt.Sprintf("[%s] 原段落 %d → 新段落 %d", change.Type, change.OldIndex+1, change.NewIndex+1)
+	case change.NewIndex >= 0:
+		return fmt.Sprintf("[%s] 新段落 %d", change.Type, change.NewIndex+1)
+	default:
+		return fmt.Sprintf("[%s] 原段落 %d", change.Type, change.OldIndex+1)
+	}
+}
+
+// newDiffText 将差异片段渲染为富文本
+//
+// exclude 为要隐藏的操作类型：并排显示时左侧隐藏插入、右侧隐藏删除；传-1显示全部。
+func newDiffText(segments []document.DiffSegment, exclude document.DiffOp) *widget.RichText {
+	var richSegments []widget.RichTextSegment
+
+	for _, seg := range segments {
+		if seg.Op == exclude || seg.Text == "" {
+			continue
+		}
+		style := widget.RichTextStyle{Inline: true}
+		switch seg.Op {
+		case document.DiffDelete:
+			style.ColorName = theme.ColorNameError
+			style.TextStyle = fyne.TextStyle{Italic: true}
+		case document.DiffInsert:
+			style.ColorName = theme.ColorNameSuccess
+			style.TextStyle = fyne.TextStyle{Bold: true}
+		}
+		richSegments = append(richSegments, &widget.TextSegment{Text: seg.Text, Style: style})
+	}
+
+	if len(richSegments) == 0 {
+		richSegments = append(richSegments, &widget.TextSegment{
+			Text:  "（无）",
+			Style: widget.RichTextStyle{ColorName: theme.ColorNameDisabled, Inline: true},
+		})
+	}
+
+	rt := widget.NewRichText(richSegments...)
+	rt.Wrapping = fyne.TextWrapWord
+	return rt
+}
@@ -2,11 +2,14 @@ package ui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	"github.com/tanqiangyes/fyne-word/pkg/document"
+	"github.com/tanqiangyes/fyne-word/pkg/layout"
 	"log"
 )
 
@@ -15,6 +18,10 @@ type TreeView struct {
 	tree        *widget.Tree
 	docManager  *document.Manager
 	onSelect    func(nodeID string)
+	window      fyne.Window
+	outlineMode bool         // 按标题层级显示段落
+	outline     *outlineTree // 大纲模式下缓存的段落树
+	selected    string       // 最后选中的节点
 }
 
 // NewTreeView 创建新的go-word文档树形视图
@@ -42,9 +49,25 @@ func (gtv *TreeView) GetWidget() fyne.CanvasObject {
 
 // Refresh 刷新树形视图
 func (gtv *TreeView) Refresh() {
+	gtv.outline = nil
 	gtv.tree.Refresh()
 }
 
+// SetWindow 设置显示对话框使用的窗口
+func (gtv *TreeView) SetWindow(window fyne.Window) {
+	gtv.window = window
+}
+
+// Select 选中指定节点，与用户点击节点效果相同
+func (gtv *TreeView) Select(nodeID string) {
+	gtv.tree.Select(nodeID)
+}
+
+// Selected 返回最后选中的节点，没有选中过节点时返回空字符串
+func (gtv *TreeView) Selected() string {
+	return gtv.selected
+}
+
 // SetOnSelect 设置节点选择回调
 func (gtv *TreeView) SetOnSelect(callback func(nodeID string)) {
 	gtv.onSelect = callback
@@ -52,11 +75,19 @@ func (gtv *TreeView) SetOnSelect(callback func(nodeID string)) {
 
 // getChildIDs 获取子节点ID列表
 func (gtv *TreeView) getChildIDs(id widget.TreeNodeID) []widget.TreeNodeID {
+	if gtv.outlineMode {
+		doc := gtv.docManager.GetCurrentDocument()
+		if doc == nil {
+			return []string{}
+		}
+		return gtv.currentOutline(doc).children[id]
+	}
+	
 	if id == "" {
 		// 根节点
 		doc := gtv.docManager.GetCurrentDocument()
 		if doc != nil {
-			return []string{"title", "paragraphs", "tables", "images", "styles", "metadata"}
+			return []string{"title", "paragraphs", "tables", "images", "styles", "sections", "headers", "notes", "links", "fields", "revisions", "comments", "metadata"}
 		}
 		return []string{}
 	}
@@ -98,6 +129,40 @@ func (gtv *TreeView) getChildIDs(id widget.TreeNodeID) []widget.TreeNodeID {
 			ids = append(ids, fmt.Sprintf("s%d", i+1))
 		}
 		return ids
+	case "revisions":
+		var ids []string
+		revisions, _ := doc.Revisions()
+		for i := range revisions {
+			ids = append(ids, fmt.Sprintf("r%d", i+1))
+		}
+		return ids
+	case "sections":
+		var ids []string
+		for i := 0; i < doc.SectionCount(); i++ {
+			ids = append(ids, fmt.Sprintf("x%d", i+1))
+		}
+		return ids
+	case "headers":
+		var ids []string
+		headers, _ := doc.HeadersFooters()
+		for i := range headers {
+			ids = append(ids, fmt.Sprintf("h%d", i+1))
+		}
+		return ids
+	case "comments":
+		var ids []string
+		comments, _ := doc.Comments()
+		for i := range comments {
+			ids = append(ids, fmt.Sprintf("c%d", i+1))
+		}
+		return ids
+	case "notes":
+		var ids []string
+		notes, _ := doc.AllNotes()
+		for i := range notes {
+			ids = append(ids, fmt.Sprintf("n%d", i+1))
+		}
+		return ids
 	}
 	
 	return []string{}
@@ -111,12 +176,15 @@ func (gtv *TreeView) hasChildren(id widget.TreeNodeID) bool {
 
 // createNode 创建节点显示组件
 func (gtv *TreeView) createNode(b bool) fyne.CanvasObject {
-	return widget.NewLabel("")
+	return newTreeNode(gtv)
 }
 
 // updateNode 更新节点内容
 func (gtv *TreeView) updateNode(id widget.TreeNodeID, b bool, o fyne.CanvasObject) {
-	label := o.(*widget.Label)
+	node := o.(*treeNode)
+	node.id = id
+	label := node.label
+	label.TextStyle = fyne.TextStyle{}
 	
 	doc := gtv.docManager.GetCurrentDocument()
 	if doc == nil {
@@ -124,6 +192,11 @@ func (gtv *TreeView) updateNode(id widget.TreeNodeID, b bool, o fyne.CanvasObjec
 		return
 	}
 	
+	if gtv.outlineMode {
+		gtv.updateOutlineNode(doc, id, label)
+		return
+	}
+	
 	// 创建适配器
 	adapter := document.NewDocumentAdapter(doc)
 	
@@ -142,6 +215,27 @@ func (gtv *TreeView) updateNode(id widget.TreeNodeID, b bool, o fyne.CanvasObjec
 	case "styles":
 		count := adapter.GetStyleCount()
 		label.SetText(fmt.Sprintf("🎨 样式 (%d)", count))
+	case "sections":
+		label.SetText(fmt.Sprintf("📄 节 (%d)", doc.SectionCount()))
+	case "headers":
+		headers, _ := doc.HeadersFooters()
+		label.SetText(fmt.Sprintf("📑 页眉页脚 (%d)", len(headers)))
+	case "revisions":
+		revisions, _ := doc.Revisions()
+		label.SetText(fmt.Sprintf("🔁 修订 (%d)", len(revisions)))
+	case "comments":
+		comments, _ := doc.Comments()
+		label.SetText(fmt.Sprintf("💬 批注 (%d)", len(comments)))
+	case "notes":
+		notes, _ := doc.AllNotes()
+		label.SetText(fmt.Sprintf("📎 脚注和尾注 (%d)", len(notes)))
+	case "links":
+		links, _ := doc.Hyperlinks()
+		bookmarks, _ := doc.Bookmarks()
+		label.SetText(fmt.Sprintf("🔗 超链接和书签 (%d/%d)", len(links), len(bookmarks)))
+	case "fields":
+		fields, _ := doc.Fields()
+		label.SetText(fmt.Sprintf("🧮 域 (%d)", len(fields)))
 	case "metadata":
 		label.SetText("ℹ️ 元数据")
 	default:
@@ -151,7 +245,10 @@ func (gtv *TreeView) updateNode(id widget.TreeNodeID, b bool, o fyne.CanvasObjec
 			index := parseIndex(id[1:])
 			if index >= 0 {
 				text := adapter.GetParagraphText(index)
-				label.SetText(fmt.Sprintf("📝 %s", truncateText(text, 30)))
+				if item, ok := adapter.GetParagraphListItem(index); ok && item.Label != "" {
+					text = item.Label + " " + text
+				}
+				label.SetText(fmt.Sprintf("📝 %s", truncateRunes(text, 30)))
 			}
 		} else if strings.HasPrefix(id, "t") {
 			// 表格
@@ -171,12 +268,48 @@ func (gtv *TreeView) updateNode(id widget.TreeNodeID, b bool, o fyne.CanvasObjec
 			if index >= 0 {
 				label.SetText(fmt.Sprintf("🎨 样式 %d", index+1))
 			}
+		} else if strings.HasPrefix(id, "r") {
+			// 修订
+			index := parseIndex(id[1:])
+			revisions, _ := doc.Revisions()
+			if index >= 0 && index < len(revisions) {
+				label.SetText("🔁 " + revisionSummary(revisions[index]))
+			}
+		} else if strings.HasPrefix(id, "x") {
+			// 节
+			index := parseIndex(id[1:])
+			sections, _ := doc.Sections()
+			if index >= 0 && index < len(sections) {
+				label.SetText("📄 " + sectionSummary(sections[index]))
+			}
+		} else if strings.HasPrefix(id, "h") {
+			// 页眉页脚
+			index := parseIndex(id[1:])
+			headers, _ := doc.HeadersFooters()
+			if index >= 0 && index < len(headers) {
+				label.SetText("📑 " + headerFooterTitle(headers[index]))
+			}
+		} else if strings.HasPrefix(id, "c") {
+			// 批注
+			index := parseIndex(id[1:])
+			comments, _ := doc.Comments()
+			if index >= 0 && index < len(comments) {
+				label.SetText("💬 " + commentSummary(comments[index]))
+			}
+		} else if strings.HasPrefix(id, "n") {
+			// 脚注和尾注
+			index := parseIndex(id[1:])
+			notes, _ := doc.AllNotes()
+			if index >= 0 && index < len(notes) {
+				label.SetText("📎 " + noteSummary(notes[index]))
+			}
 		}
 	}
 }
 
 // onNodeSelected 节点选择事件处理
 func (gtv *TreeView) onNodeSelected(id widget.TreeNodeID) {
+	gtv.selected = id
 	if gtv.onSelect != nil {
 		gtv.onSelect(id)
 	}
@@ -185,8 +318,16 @@ func (gtv *TreeView) onNodeSelected(id widget.TreeNodeID) {
 // ContentView 基于go-word库的内容显示组件
 type ContentView struct {
 	container   *fyne.Container
+	scroll      *container.Scroll
 	docManager  *document.Manager
 	currentNode string
+	window      fyne.Window
+	onChanged   func()
+	onPosition  func(paragraph int) // 当前段落变化后调用，-1表示当前显示的不是段落
+	author      func() string
+	
+	engine *layout.Engine    // 编辑器排版使用的引擎
+	editor *RichTextEditor   // 正文编辑器，切换到其他节点后保留光标位置
 }
 
 // NewContentView 创建新的go-word内容显示组件
@@ -198,19 +339,83 @@ func NewContentView(docManager *document.Manager) *ContentView {
 	gcv.container = container.NewVBox(
 		widget.NewLabel("请选择一个文档节点查看内容"),
 	)
+	gcv.scroll = container.NewVScroll(gcv.container)
 	
 	return gcv
 }
 
 // GetWidget 获取Fyne组件
 func (gcv *ContentView) GetWidget() fyne.CanvasObject {
-	return gcv.container
+	return gcv.scroll
+}
+
+// SetWindow 设置显示对话框使用的窗口
+func (gcv *ContentView) SetWindow(window fyne.Window) {
+	gcv.window = window
+}
+
+// SetOnChanged 设置内容视图修改文档后的回调
+func (gcv *ContentView) SetOnChanged(callback func()) {
+	gcv.onChanged = callback
+}
+
+// SetAuthorFunc 设置获取当前用户名称的函数，用于批注等需要作者的操作
+func (gcv *ContentView) SetAuthorFunc(author func() string) {
+	gcv.author = author
+}
+
+// currentAuthor 返回当前用户名称
+func (gcv *ContentView) currentAuthor() string {
+	if gcv.author != nil {
+		return gcv.author()
+	}
+	return "fyne-word"
+}
+
+// afterChange 内容视图修改文档后显示错误或刷新视图，node 为修改后显示的节点
+func (gcv *ContentView) afterChange(err error, node string) {
+	if err != nil {
+		if gcv.window != nil {
+			dialog.ShowError(err, gcv.window)
+		}
+		return
+	}
+
+	gcv.currentNode = node
+	gcv.updateContent()
+	if gcv.onChanged != nil {
+		gcv.onChanged()
+	}
 }
 
 // ShowNode 显示指定节点的内容
 func (gcv *ContentView) ShowNode(nodeID string) {
 	gcv.currentNode = nodeID
 	gcv.updateContent()
+	gcv.positionChanged(gcv.CurrentParagraph())
+}
+
+// SetOnPositionChanged 设置当前段落变化后的回调，参数为段落索引，当前显示的不是段落时为-1
+func (gcv *ContentView) SetOnPositionChanged(callback func(paragraph int)) {
+	gcv.onPosition = callback
+}
+
+// positionChanged 通知当前段落已变化
+func (gcv *ContentView) positionChanged(paragraph int) {
+	if gcv.onPosition != nil {
+		gcv.onPosition(paragraph)
+	}
+}
+
+// CurrentParagraph 返回当前显示的段落索引，显示编辑器时返回光标所在的段落，当前显示的不是段落时返回-1
+func (gcv *ContentView) CurrentParagraph() int {
+	if editor := gcv.visibleEditor(); editor != nil {
+		return editor.Caret().Paragraph
+	}
+	if !strings.HasPrefix(gcv.currentNode, "p") || gcv.currentNode == "paragraphs" {
+		return -1
+	}
+	return parseIndex(gcv.currentNode[1:])
 }
 
 // updateContent 更新内容显示
@@ -231,15 +436,29 @@ func (gcv *ContentView) updateContent() {
 	
 	switch gcv.currentNode {
 	case "title":
-		contentWidgets = gcv.createTitleView(adapter.GetTitle())
+		contentWidgets = gcv.createTitleView(doc, adapter.GetTitle())
 	case "paragraphs":
-		contentWidgets = gcv.createParagraphsView(adapter)
+		contentWidgets = gcv.createEditorView(doc)
 	case "tables":
 		contentWidgets = gcv.createTablesView(adapter)
 	case "images":
 		contentWidgets = gcv.createImagesView(adapter)
 	case "styles":
 		contentWidgets = gcv.createStylesView(adapter)
+	case "sections":
+		contentWidgets = gcv.createSectionsView(doc)
+	case "headers":
+		contentWidgets = gcv.createHeadersView(doc)
+	case "revisions":
+		contentWidgets = gcv.createRevisionsView(doc)
+	case "comments":
+		contentWidgets = gcv.createCommentsView(doc)
+	case "notes":
+		contentWidgets = gcv.createNotesView(doc)
+	case "links":
+		contentWidgets = gcv.createLinksView(doc)
+	case "fields":
+		contentWidgets = gcv.createFieldsView(doc)
 	case "metadata":
 		contentWidgets = gcv.createMetadataView(adapter)
 	default:
@@ -264,6 +483,31 @@ func (gcv *ContentView) updateContent() {
 			if index >= 0 {
 				contentWidgets = gcv.createStyleDetailView(adapter, index)
 			}
+		} else if strings.HasPrefix(gcv.currentNode, "r") {
+			index := parseIndex(gcv.currentNode[1:])
+			if index >= 0 {
+				contentWidgets = gcv.createRevisionDetailView(doc, index)
+			}
+		} else if strings.HasPrefix(gcv.currentNode, "x") {
+			index := parseIndex(gcv.currentNode[1:])
+			if index >= 0 {
+				contentWidgets = gcv.createSectionDetailView(doc, index)
+			}
+		} else if strings.HasPrefix(gcv.currentNode, "h") {
+			index := parseIndex(gcv.currentNode[1:])
+			if index >= 0 {
+				contentWidgets = gcv.createHeaderDetailView(doc, index)
+			}
+		} else if strings.HasPrefix(gcv.currentNode, "c") {
+			index := parseIndex(gcv.currentNode[1:])
+			if index >= 0 {
+				contentWidgets = gcv.createCommentDetailView(doc, index)
+			}
+		} else if strings.HasPrefix(gcv.currentNode, "n") {
+			index := parseIndex(gcv.currentNode[1:])
+			if index >= 0 {
+				contentWidgets = gcv.createNoteDetailView(doc, index)
+			}
 		}
 	}
 	
@@ -278,7 +522,7 @@ func (gcv *ContentView) updateContent() {
 }
 
 // createTitleView 创建标题视图
-func (gcv *ContentView) createTitleView(title string) []fyne.CanvasObject {
+func (gcv *ContentView) createTitleView(doc *document.Document, title string) []fyne.CanvasObject {
 	var widgets []fyne.CanvasObject
 	
 	widgets = append(widgets, widget.NewLabel("文档标题"))
@@ -296,35 +540,7 @@ func (gcv *ContentView) createTitleView(title string) []fyne.CanvasObject {
 	// 添加文本编辑区域
 	widgets = append(widgets, widget.NewSeparator())
 	widgets = append(widgets, widget.NewLabel("文档内容"))
-	
-	textArea := widget.NewMultiLineEntry()
-	textArea.SetPlaceHolder("在此输入文档内容...")
-	textArea.OnChanged = func(text string) {
-		// TODO: 保存文本内容
-		log.Printf("文本内容已更新，长度: %d", len(text))
-	}
-	widgets = append(widgets, textArea)
-	
-	return widgets
-}
-
-// createParagraphsView 创建段落列表视图
-func (gcv *ContentView) createParagraphsView(adapter *document.DocumentAdapter) []fyne.CanvasObject {
-	var widgets []fyne.CanvasObject
-	
-	widgets = append(widgets, widget.NewLabel("段落列表"))
-	widgets = append(widgets, widget.NewSeparator())
-	
-	count := adapter.GetParagraphCount()
-	for i := 0; i < count; i++ {
-		text := adapter.GetParagraphText(i)
-		paraLabel := widget.NewLabel(fmt.Sprintf("段落 %d: %s", i+1, truncateText(text, 50)))
-		widgets = append(widgets, paraLabel)
-	}
-	
-	if count == 0 {
-		widgets = append(widgets, widget.NewLabel("没有段落"))
-	}
+	widgets = append(widgets, gcv.createEditorView(doc)...)
 	
 	return widgets
 }
@@ -400,8 +616,13 @@ func (gcv *ContentView) createMetadataView(adapter *document.DocumentAdapter) []
 	widgets = append(widgets, widget.NewSeparator())
 	
 	metadata := adapter.GetMetadataInfo()
-	for key, value := range metadata {
-		widgets = append(widgets, widget.NewLabel(fmt.Sprintf("%s: %s", key, value)))
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		widgets = append(widgets, widget.NewLabel(fmt.Sprintf("%s: %s", key, metadata[key])))
 	}
 	
 	return widgets
@@ -414,13 +635,31 @@ func (gcv *ContentView) createParagraphDetailView(adapter *document.DocumentAdap
 	widgets = append(widgets, widget.NewLabel(fmt.Sprintf("段落 %d 详情", index+1)))
 	widgets = append(widgets, widget.NewSeparator())
 	
-	text := adapter.GetParagraphText(index)
+	// 显示文本内容，在编辑器中修改
+	textLabel := widget.NewLabel(adapter.GetParagraphText(index))
+	textLabel.Wrapping = fyne.TextWrapWord
+	widgets = append(widgets, textLabel)
+	widgets = append(widgets, widget.NewButton("在编辑器中编辑", func() {
+		gcv.EditParagraph(index)
+	}))
+	
+	// 显示段落格式和修订标记
+	if spans, err := adapter.GetParagraphSpans(index); err == nil && len(spans) > 0 {
+		widgets = append(widgets, widget.NewSeparator())
+		widgets = append(widgets, widget.NewLabel("格式预览"))
+		widgets = append(widgets, NewStyledText(spanSegments(spans)...))
+	}
 	
-	// 显示文本内容
-	textArea := widget.NewMultiLineEntry()
-	textArea.SetText(text)
-	textArea.Disable()
-	widgets = append(widgets, textArea)
+	// 显示段落格式、列表、批注和注释
+	if doc := gcv.docManager.GetCurrentDocument(); doc != nil && doc.Package != nil {
+		widgets = append(widgets, widget.NewSeparator())
+		widgets = append(widgets, gcv.createParagraphFormat(doc, index)...)
+		widgets = append(widgets, gcv.createParagraphList(doc, adapter, index)...)
+		widgets = append(widgets, widget.NewSeparator())
+		widgets = append(widgets, gcv.createParagraphComments(doc, adapter.GetCommentsByParagraph()[index], index)...)
+		widgets = append(widgets, widget.NewSeparator())
+		widgets = append(widgets, gcv.createParagraphNotes(doc, adapter.GetNotesByParagraph()[index], index)...)
+	}
 	
 	return widgets
 }
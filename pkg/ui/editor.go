@@ -0,0 +1,919 @@
+package ui
+
+import (
+	"image/color"
+	"log"
+	"math"
+	"unicode"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+	"github.com/tanqiangyes/fyne-word/pkg/layout"
+)
+
+// editorCaretWidth 光标的宽度
+const editorCaretWidth = 2
+
+// paragraphMarkWidth 选择范围包含段落标记时在行尾多显示的宽度，单位为磅
+const paragraphMarkWidth = 5.0
+
+// caretStop 行中光标可以停留的位置，x 相对于行的左端，单位为磅
+type caretStop struct {
+	offset int
+	x      float64
+}
+
+// RichTextEditor 正文编辑器，按排版引擎的结果显示带格式的正文，支持光标、跨段落选择和文字输入
+//
+// 输入法提交的文字通过 TypedRune 逐字输入。编辑直接修改文档模型后重新排版，
+// 表格中的文字只显示，不能编辑。
+type RichTextEditor struct {
+	widget.BaseWidget
+
+	doc     *document.Document
+	engine  *layout.Engine
+	images  *imageCache
+	page    *layout.Page
+	version int   // 排版结果的版本，渲染器据此重建页面内容
+	lines   []int // 正文段落的行在 page.Lines 中的索引
+	lengths []int // 各正文段落的文本长度
+
+	caret, anchor document.TextPosition
+	preferredX    float64 // 上下移动光标时保持的横坐标，小于0表示未设置
+	focused       bool
+	shift         bool // 正在按住Shift键
+	scroll        *container.Scroll
+	lastEdit      string // 正在连续进行的编辑，连续输入或删除的文字合并为一步撤销
+	overLink      bool   // 按住Ctrl时鼠标位于超链接上
+	pasting       bool   // 正在后台读取剪贴板
+
+	// OnChanged 编辑修改文档后调用
+	OnChanged func()
+	// OnCaretMoved 光标移动后调用，参数为光标所在的段落
+	OnCaretMoved func(paragraph int)
+	// OnLinkTapped 按住Ctrl点击超链接时调用，参数为链接目标（外部地址，或 # 加书签名称）
+	OnLinkTapped func(link string)
+}
+
+// NewRichTextEditor 创建文档的正文编辑器
+func NewRichTextEditor(doc *document.Document, engine *layout.Engine) *RichTextEditor {
+	e := &RichTextEditor{doc: doc, engine: engine, images: newImageCache(doc), preferredX: -1}
+	e.ExtendBaseWidget(e)
+	e.relayout()
+	return e
+}
+
+// Document 返回编辑器显示的文档
+func (e *RichTextEditor) Document() *document.Document {
+	return e.doc
+}
+
+// SetScroll 设置包含编辑器的滚动容器，光标移动后滚动到光标位置
+//
+// 编辑器须直接位于滚动内容中。
+func (e *RichTextEditor) SetScroll(scroll *container.Scroll) {
+	e.scroll = scroll
+}
+
+// Reload 重新排版文档并刷新显示，文档被编辑器以外的操作修改后调用
+func (e *RichTextEditor) Reload() {
+	e.lastEdit = ""
+	e.relayout()
+	e.caret, e.anchor = e.clamp(e.caret), e.clamp(e.anchor)
+	e.Refresh()
+}
+
+// Caret 返回光标位置
+func (e *RichTextEditor) Caret() document.TextPosition {
+	return e.caret
+}
+
+// SetCaret 将光标移到指定位置并取消选择
+func (e *RichTextEditor) SetCaret(pos document.TextPosition) {
+	e.moveTo(pos, false)
+}
+
+// Selection 返回选择的文字范围，没有选择文字时返回 false
+func (e *RichTextEditor) Selection() (document.TextRange, bool) {
+	if e.caret == e.anchor {
+		return document.TextRange{}, false
+	}
+	start, end := e.ordered()
+	return document.TextRange{
+		StartParagraph: start.Paragraph,
+		StartOffset:    start.Offset,
+		EndParagraph:   end.Paragraph,
+		EndOffset:      end.Offset,
+	}, true
+}
+
+// SelectAll 选择整篇文档
+func (e *RichTextEditor) SelectAll() {
+	if len(e.lengths) == 0 {
+		return
+	}
+	e.anchor = document.TextPosition{}
+	e.caret = e.documentEnd()
+	e.Refresh()
+	e.caretMoved()
+}
+
+// ordered 返回选择范围的开始和结束位置
+func (e *RichTextEditor) ordered() (document.TextPosition, document.TextPosition) {
+	if e.caret.Before(e.anchor) {
+		return e.caret, e.anchor
+	}
+	return e.anchor, e.caret
+}
+
+// documentEnd 返回文档末尾的位置
+func (e *RichTextEditor) documentEnd() document.TextPosition {
+	if len(e.lengths) == 0 {
+		return document.TextPosition{}
+	}
+	last := len(e.lengths) - 1
+	return document.TextPosition{Paragraph: last, Offset: e.lengths[last]}
+}
+
+// relayout 重新排版文档，记录正文段落的行和长度
+func (e *RichTextEditor) relayout() {
+	page, err := e.engine.Continuous(e.doc)
+	if err != nil {
+		log.Printf("排版失败: %v", err)
+		page = &layout.Page{}
+	}
+	e.page = page
+	e.version++
+	e.lines = e.lines[:0]
+	e.lengths = e.lengths[:0]
+	for i, line := range page.Lines {
+		if line.Paragraph < 0 {
+			continue
+		}
+		e.lines = append(e.lines, i)
+		for len(e.lengths) <= line.Paragraph {
+			e.lengths = append(e.lengths, 0)
+		}
+		if line.End > e.lengths[line.Paragraph] {
+			e.lengths[line.Paragraph] = line.End
+		}
+	}
+}
+
+// clamp 将位置限制在文档范围内
+func (e *RichTextEditor) clamp(pos document.TextPosition) document.TextPosition {
+	if len(e.lengths) == 0 {
+		return document.TextPosition{}
+	}
+	if pos.Paragraph >= len(e.lengths) {
+		return e.documentEnd()
+	}
+	if pos.Paragraph < 0 {
+		return document.TextPosition{}
+	}
+	pos.Offset = int(math.Max(0, math.Min(float64(pos.Offset), float64(e.lengths[pos.Paragraph]))))
+	return pos
+}
+
+// scale 返回磅到界面单位的比例
+func (e *RichTextEditor) scale() float64 {
+	return pointScale
+}
+
+// origin 返回页面左上角在编辑器中的位置，编辑器比页面宽时页面居中
+func (e *RichTextEditor) origin() fyne.Position {
+	width := float32(e.page.Width * e.scale())
+	return fyne.NewPos(float32(math.Max(0, float64(e.Size().Width-width)/2)), 0)
+}
+
+// lineStops 返回行中光标可以停留的位置
+func (e *RichTextEditor) lineStops(line *layout.Line) []caretStop {
+	stops := []caretStop{{line.Start, line.Indent}}
+	for _, f := range line.Fragments {
+		switch {
+		case f.Length == 0:
+			// 列表编号、注释编号和图片之后的位置
+			stops = append(stops, caretStop{f.Offset, f.X + f.Width})
+		case f.Text == "":
+			stops = append(stops, caretStop{f.Offset, f.X}, caretStop{f.Offset + 1, f.X + f.Width})
+		default:
+			runes := []rune(f.Text)
+			style := fyne.TextStyle{Bold: f.Style.Font.Bold, Italic: f.Style.Font.Italic}
+			for k := 0; k <= len(runes) && k <= f.Length; k++ {
+				x := f.X
+				if k > 0 {
+					x += float64(fyne.MeasureText(string(runes[:k]), float32(f.Style.Font.Size), style).Width)
+				}
+				stops = append(stops, caretStop{f.Offset + k, x})
+			}
+		}
+	}
+	return stops
+}
+
+// offsetX 返回行中位置 offset 的横坐标，相对于行的左端
+//
+// 同一位置有多个停留点时（例如注释编号前后）取最右边的，与插入文字的位置一致。
+func (e *RichTextEditor) offsetX(line *layout.Line, offset int) float64 {
+	best := caretStop{line.Start, line.Indent}
+	for _, stop := range e.lineStops(line) {
+		if stop.offset <= offset && (stop.offset > best.offset || (stop.offset == best.offset && stop.x > best.x)) {
+			best = stop
+		}
+	}
+	return best.x
+}
+
+// nearestOffset 返回行中离横坐标 x 最近的位置
+func (e *RichTextEditor) nearestOffset(line *layout.Line, x float64) int {
+	best, distance := line.Start, math.Inf(1)
+	for _, stop := range e.lineStops(line) {
+		if d := math.Abs(stop.x - x); d < distance {
+			best, distance = stop.offset, d
+		}
+	}
+	return best
+}
+
+// caretLine 返回光标位置所在的行在 e.lines 中的索引，行尾与下一行开头重合时取下一行
+func (e *RichTextEditor) caretLine(pos document.TextPosition) int {
+	found := -1
+	for i, index := range e.lines {
+		line := &e.page.Lines[index]
+		if line.Paragraph == pos.Paragraph && line.Start <= pos.Offset && pos.Offset <= line.End {
+			found = i
+		}
+		if line.Paragraph > pos.Paragraph {
+			break
+		}
+	}
+	if found < 0 {
+		for i, index := range e.lines {
+			if e.page.Lines[index].Paragraph == pos.Paragraph {
+				return i
+			}
+		}
+		return 0
+	}
+	return found
+}
+
+// positionAt 返回编辑器中坐标对应的文字位置
+func (e *RichTextEditor) positionAt(p fyne.Position) document.TextPosition {
+	if len(e.lines) == 0 {
+		return document.TextPosition{}
+	}
+	origin := e.origin()
+	x := float64(p.X-origin.X) / e.scale()
+	y := float64(p.Y-origin.Y) / e.scale()
+
+	best, distance := e.lines[0], math.Inf(1)
+	for _, index := range e.lines {
+		line := &e.page.Lines[index]
+		d := 0.0
+		if y < line.Y {
+			d = line.Y - y
+		} else if y > line.Y+line.Height {
+			d = y - line.Y - line.Height
+		}
+		if d < distance {
+			best, distance = index, d
+		}
+	}
+	line := &e.page.Lines[best]
+	return document.TextPosition{Paragraph: line.Paragraph, Offset: e.nearestOffset(line, x-line.X)}
+}
+
+// caretRect 返回光标在编辑器中的位置和高度
+func (e *RichTextEditor) caretRect() (fyne.Position, float32) {
+	if len(e.lines) == 0 {
+		return e.origin(), float32(layout.DefaultFontSize * e.scale())
+	}
+	line := &e.page.Lines[e.lines[e.caretLine(e.caret)]]
+	origin := e.origin()
+	x := origin.X + float32((line.X+e.offsetX(line, e.caret.Offset))*e.scale())
+	y := origin.Y + float32(line.Y*e.scale())
+	return fyne.NewPos(x, y), float32(line.Height * e.scale())
+}
+
+// moveTo 移动光标，extend 为 true 时扩展选择范围
+func (e *RichTextEditor) moveTo(pos document.TextPosition, extend bool) {
+	e.caret = e.clamp(pos)
+	if !extend {
+		e.anchor = e.caret
+	}
+	e.preferredX = -1
+	e.lastEdit = ""
+	e.Refresh()
+	e.caretMoved()
+}
+
+// caretMoved 滚动到光标位置并通知光标移动
+func (e *RichTextEditor) caretMoved() {
+	e.ensureVisible()
+	if e.OnCaretMoved != nil {
+		e.OnCaretMoved(e.caret.Paragraph)
+	}
+}
+
+// ensureVisible 滚动容器使光标可见
+func (e *RichTextEditor) ensureVisible() {
+	if e.scroll == nil {
+		return
+	}
+	pos, height := e.caretRect()
+	top := e.Position().Y + pos.Y
+	offset := e.scroll.Offset
+	view := e.scroll.Size().Height
+	switch {
+	case top < offset.Y:
+		offset.Y = top
+	case top+height > offset.Y+view:
+		offset.Y = top + height - view
+	default:
+		return
+	}
+	e.scroll.Offset = offset
+	e.scroll.Refresh()
+}
+
+// step 返回向前或向后移动一个字符后的位置，在段落边界移到相邻段落
+func (e *RichTextEditor) step(pos document.TextPosition, forward bool) document.TextPosition {
+	if forward {
+		if pos.Offset < e.lengths[pos.Paragraph] {
+			pos.Offset++
+		} else if pos.Paragraph+1 < len(e.lengths) {
+			pos = document.TextPosition{Paragraph: pos.Paragraph + 1}
+		}
+		return pos
+	}
+	if pos.Offset > 0 {
+		pos.Offset--
+	} else if pos.Paragraph > 0 {
+		pos = document.TextPosition{Paragraph: pos.Paragraph - 1, Offset: e.lengths[pos.Paragraph-1]}
+	}
+	return pos
+}
+
+// vertical 上下移动 lines 行，保持光标的横坐标
+func (e *RichTextEditor) vertical(lines int, extend bool) {
+	current := e.caretLine(e.caret)
+	line := &e.page.Lines[e.lines[current]]
+	x := e.preferredX
+	if x < 0 {
+		x = line.X + e.offsetX(line, e.caret.Offset)
+	}
+	target := current + lines
+	var pos document.TextPosition
+	switch {
+	case target < 0:
+		pos = document.TextPosition{}
+	case target >= len(e.lines):
+		pos = e.documentEnd()
+	default:
+		next := &e.page.Lines[e.lines[target]]
+		pos = document.TextPosition{Paragraph: next.Paragraph, Offset: e.nearestOffset(next, x-next.X)}
+	}
+	e.moveTo(pos, extend)
+	e.preferredX = x
+}
+
+// visibleLines 返回滚动容器一屏显示的大致行数
+func (e *RichTextEditor) visibleLines() int {
+	if e.scroll == nil || len(e.lines) == 0 {
+		return 20
+	}
+	line := e.page.Lines[e.lines[e.caretLine(e.caret)]]
+	return int(math.Max(1, float64(e.scroll.Size().Height)/(line.Height*e.scale())-1))
+}
+
+// CreateRenderer 实现 fyne.Widget
+func (e *RichTextEditor) CreateRenderer() fyne.WidgetRenderer {
+	background := canvas.NewRectangle(color.White)
+	background.StrokeColor = color.Gray{Y: 0x99}
+	background.StrokeWidth = 1
+	r := &editorRenderer{
+		editor:     e,
+		version:    -1,
+		background: background,
+		selection:  container.NewWithoutLayout(),
+		content:    container.NewWithoutLayout(),
+		caret:      canvas.NewRectangle(color.Black),
+	}
+	r.Refresh()
+	return r
+}
+
+// MinSize 编辑器的高度为排版后的页面高度，宽度不限制以便放在较窄的窗口中
+func (e *RichTextEditor) MinSize() fyne.Size {
+	e.ExtendBaseWidget(e)
+	return fyne.NewSize(theme.Padding()*20, float32(e.page.Height*e.scale()))
+}
+
+// Cursor 实现 desktop.Cursorable，鼠标在编辑器中显示为文字光标，按住Ctrl指向超链接时显示为手形
+func (e *RichTextEditor) Cursor() desktop.Cursor {
+	if e.overLink {
+		return desktop.PointerCursor
+	}
+	return desktop.TextCursor
+}
+
+// requestFocus 使编辑器获得键盘焦点
+func (e *RichTextEditor) requestFocus() {
+	if c := fyne.CurrentApp().Driver().CanvasForObject(e); c != nil && c.Focused() != e {
+		c.Focus(e)
+	}
+}
+
+// FocusGained 实现 fyne.Focusable
+func (e *RichTextEditor) FocusGained() {
+	e.focused = true
+	e.Refresh()
+}
+
+// FocusLost 实现 fyne.Focusable
+func (e *RichTextEditor) FocusLost() {
+	e.focused = false
+	e.shift = false
+	e.Refresh()
+}
+
+// AcceptsTab 实现 fyne.Tabbable，Tab 键插入制表符而不是切换焦点
+func (e *RichTextEditor) AcceptsTab() bool {
+	return true
+}
+
+// Tapped 实现 fyne.Tappable
+func (e *RichTextEditor) Tapped(*fyne.PointEvent) {
+	e.requestFocus()
+}
+
+// DoubleTapped 实现 fyne.DoubleTappable，选择光标所在的词
+func (e *RichTextEditor) DoubleTapped(ev *fyne.PointEvent) {
+	pos := e.positionAt(ev.Position)
+	text, err := e.doc.ParagraphText(pos.Paragraph)
+	if err != nil {
+		return
+	}
+	runes := []rune(text)
+	start, end := pos.Offset, pos.Offset
+	if end < len(runes) {
+		class := runeClass(runes[end])
+		for start > 0 && runeClass(runes[start-1]) == class {
+			start--
+		}
+		for end < len(runes) && runeClass(runes[end]) == class {
+			end++
+		}
+	}
+	e.anchor = document.TextPosition{Paragraph: pos.Paragraph, Offset: start}
+	e.caret = document.TextPosition{Paragraph: pos.Paragraph, Offset: end}
+	e.Refresh()
+	e.caretMoved()
+}
+
+// runeClass 返回双击选词时字符的类别：汉字、字母数字、空白或其他符号
+func runeClass(r rune) int {
+	switch {
+	case unicode.Is(unicode.Han, r):
+		return 1
+	case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+		return 2
+	case unicode.IsSpace(r):
+		return 3
+	}
+	return 4
+}
+
+// MouseDown 实现 desktop.Mouseable，按下左键时移动光标，按住Shift时扩展选择范围，
+// 按住Ctrl点击超链接时打开链接
+func (e *RichTextEditor) MouseDown(ev *desktop.MouseEvent) {
+	if ev.Button != desktop.MouseButtonPrimary {
+		return
+	}
+	e.requestFocus()
+	if ev.Modifier&fyne.KeyModifierShortcutDefault != 0 {
+		if link := e.linkAt(ev.Position); link != "" && e.OnLinkTapped != nil {
+			e.OnLinkTapped(link)
+			return
+		}
+	}
+	e.moveTo(e.positionAt(ev.Position), ev.Modifier&fyne.KeyModifierShift != 0)
+}
+
+// MouseUp 实现 desktop.Mouseable
+func (e *RichTextEditor) MouseUp(*desktop.MouseEvent) {
+}
+
+// MouseIn 实现 desktop.Hoverable
+func (e *RichTextEditor) MouseIn(ev *desktop.MouseEvent) {
+	e.MouseMoved(ev)
+}
+
+// MouseMoved 实现 desktop.Hoverable，记录鼠标是否位于可以点击的超链接上
+func (e *RichTextEditor) MouseMoved(ev *desktop.MouseEvent) {
+	e.overLink = ev.Modifier&fyne.KeyModifierShortcutDefault != 0 && e.linkAt(ev.Position) != ""
+}
+
+// MouseOut 实现 desktop.Hoverable
+func (e *RichTextEditor) MouseOut() {
+	e.overLink = false
+}
+
+// linkAt 返回坐标处文字所在超链接的目标，不在超链接上时返回空
+func (e *RichTextEditor) linkAt(p fyne.Position) string {
+	if e.page == nil {
+		return ""
+	}
+	origin := e.origin()
+	x := float64(p.X-origin.X) / e.scale()
+	y := float64(p.Y-origin.Y) / e.scale()
+	for _, line := range e.page.Lines {
+		if y < line.Y || y > line.Y+line.Height {
+			continue
+		}
+		for _, f := range line.Fragments {
+			if f.Style.Link != "" && x >= line.X+f.X && x <= line.X+f.X+f.Width {
+				return f.Style.Link
+			}
+		}
+	}
+	return ""
+}
+
+// Dragged 实现 fyne.Draggable，拖动鼠标选择文字
+func (e *RichTextEditor) Dragged(ev *fyne.DragEvent) {
+	e.moveTo(e.positionAt(ev.Position), true)
+}
+
+// DragEnd 实现 fyne.Draggable
+func (e *RichTextEditor) DragEnd() {
+}
+
+// KeyDown 实现 desktop.Keyable，记录Shift键的状态
+func (e *RichTextEditor) KeyDown(ev *fyne.KeyEvent) {
+	if ev.Name == desktop.KeyShiftLeft || ev.Name == desktop.KeyShiftRight {
+		e.shift = true
+	}
+}
+
+// KeyUp 实现 desktop.Keyable
+func (e *RichTextEditor) KeyUp(ev *fyne.KeyEvent) {
+	if ev.Name == desktop.KeyShiftLeft || ev.Name == desktop.KeyShiftRight {
+		e.shift = false
+	}
+}
+
+// TypedRune 实现 fyne.Focusable，输入法提交的文字也逐字通过这里输入
+func (e *RichTextEditor) TypedRune(r rune) {
+	if unicode.IsControl(r) {
+		return
+	}
+	e.insert(string(r))
+}
+
+// TypedKey 实现 fyne.Focusable，处理光标移动、删除和换行
+func (e *RichTextEditor) TypedKey(ev *fyne.KeyEvent) {
+	if len(e.lengths) == 0 {
+		return
+	}
+	_, selected := e.Selection()
+	start, end := e.ordered()
+	switch ev.Name {
+	case fyne.KeyLeft:
+		if selected && !e.shift {
+			e.moveTo(start, false)
+			return
+		}
+		e.moveTo(e.step(e.caret, false), e.shift)
+	case fyne.KeyRight:
+		if selected && !e.shift {
+			e.moveTo(end, false)
+			return
+		}
+		e.moveTo(e.step(e.caret, true), e.shift)
+	case fyne.KeyUp:
+		e.vertical(-1, e.shift)
+	case fyne.KeyDown:
+		e.vertical(1, e.shift)
+	case fyne.KeyPageUp:
+		e.vertical(-e.visibleLines(), e.shift)
+	case fyne.KeyPageDown:
+		e.vertical(e.visibleLines(), e.shift)
+	case fyne.KeyHome:
+		line := e.page.Lines[e.lines[e.caretLine(e.caret)]]
+		e.moveTo(document.TextPosition{Paragraph: line.Paragraph, Offset: line.Start}, e.shift)
+	case fyne.KeyEnd:
+		line := e.page.Lines[e.lines[e.caretLine(e.caret)]]
+		e.moveTo(document.TextPosition{Paragraph: line.Paragraph, Offset: line.End}, e.shift)
+	case fyne.KeyBackspace:
+		if selected {
+			e.erase(start, end)
+		} else if previous := e.step(e.caret, false); previous != e.caret {
+			e.erase(previous, e.caret)
+		}
+	case fyne.KeyDelete:
+		if selected {
+			e.erase(start, end)
+		} else if next := e.step(e.caret, true); next != e.caret {
+			e.erase(e.caret, next)
+		}
+	case fyne.KeyReturn, fyne.KeyEnter:
+		// Shift+Enter 插入手动换行
+		if e.shift {
+			e.insert("\v")
+		} else {
+			e.insert("\n")
+		}
+	case fyne.KeyTab:
+		e.insert("\t")
+	}
+}
+
+// TypedShortcut 实现 fyne.Shortcutable，处理全选、剪贴板、撤销和 Ctrl+Home、Ctrl+End
+func (e *RichTextEditor) TypedShortcut(shortcut fyne.Shortcut) {
+	switch s := shortcut.(type) {
+	case *fyne.ShortcutSelectAll:
+		e.SelectAll()
+	case *fyne.ShortcutCopy:
+		e.Copy()
+	case *fyne.ShortcutCut:
+		e.Cut()
+	case *fyne.ShortcutPaste:
+		e.Paste()
+	case *fyne.ShortcutUndo:
+		e.Undo()
+	case *fyne.ShortcutRedo:
+		e.Redo()
+	case *desktop.CustomShortcut:
+		if s.Modifier&fyne.KeyModifierShortcutDefault == 0 {
+			return
+		}
+		extend := s.Modifier&fyne.KeyModifierShift != 0
+		switch s.KeyName {
+		case fyne.KeyHome:
+			e.moveTo(document.TextPosition{}, extend)
+		case fyne.KeyEnd:
+			e.moveTo(e.documentEnd(), extend)
+		}
+	}
+}
+
+// Copy 将选择的文字以纯文本和HTML格式复制到剪贴板，没有选择时返回 false
+func (e *RichTextEditor) Copy() bool {
+	r, ok := e.Selection()
+	if !ok {
+		return false
+	}
+	content, err := e.doc.CopyRange(r)
+	if err != nil {
+		log.Printf("复制失败: %v", err)
+		return false
+	}
+	writeClipboard(content)
+	return true
+}
+
+// Cut 将选择的文字复制到剪贴板后删除，没有选择时返回 false
+func (e *RichTextEditor) Cut() bool {
+	if !e.Copy() {
+		return false
+	}
+	e.checkpoint("剪切", false)
+	e.deleteSelection()
+	return true
+}
+
+// Paste 用剪贴板的内容替换选择的文字，HTML格式转换为带格式的段落和文字，
+// 整个粘贴作为一步撤销
+//
+// 其他程序复制的HTML在后台读取，读取完成后粘贴到当时的光标位置。
+func (e *RichTextEditor) Paste() {
+	if e.pasting {
+		return
+	}
+	e.pasting = true
+	readClipboard(func(markup, text string) {
+		e.pasting = false
+		e.paste(markup, text)
+	})
+}
+
+// paste 用读取到的剪贴板内容替换选择的文字
+func (e *RichTextEditor) paste(markup, text string) {
+	if markup == "" && text == "" {
+		return
+	}
+	e.checkpoint("粘贴", false)
+	pos := e.caret
+	if r, ok := e.Selection(); ok {
+		if err := e.doc.DeleteText(r); err != nil {
+			e.afterEdit(pos, err)
+			return
+		}
+		pos, _ = e.ordered()
+	}
+	var next document.TextPosition
+	var err error
+	if markup != "" {
+		next, err = e.doc.InsertHTML(pos, markup)
+	} else {
+		next, err = e.doc.InsertText(pos, text)
+	}
+	e.afterEdit(next, err)
+}
+
+// Undo 撤销文档最近一步操作并重新显示，没有可撤销的操作时返回错误
+func (e *RichTextEditor) Undo() error {
+	if _, err := e.doc.Undo(); err != nil {
+		return err
+	}
+	e.afterHistory()
+	return nil
+}
+
+// Redo 重做最近撤销的操作并重新显示，没有可重做的操作时返回错误
+func (e *RichTextEditor) Redo() error {
+	if _, err := e.doc.Redo(); err != nil {
+		return err
+	}
+	e.afterHistory()
+	return nil
+}
+
+// afterHistory 撤销或重做后重新排版，取消选择并通知文档已修改
+func (e *RichTextEditor) afterHistory() {
+	e.relayout()
+	e.moveTo(e.caret, false)
+	if e.OnChanged != nil {
+		e.OnChanged()
+	}
+}
+
+// checkpoint 在编辑之前记录撤销点，merge 为 true 时同类的连续编辑只记录一次
+func (e *RichTextEditor) checkpoint(label string, merge bool) {
+	if merge && e.lastEdit == label {
+		return
+	}
+	e.doc.Checkpoint(label)
+	e.lastEdit = ""
+	if merge {
+		e.lastEdit = label
+	}
+}
+
+// insert 用文字替换选择的内容，没有选择时在光标处插入
+func (e *RichTextEditor) insert(text string) {
+	e.checkpoint("输入", true)
+	pos := e.caret
+	if r, ok := e.Selection(); ok {
+		if err := e.doc.DeleteText(r); err != nil {
+			e.afterEdit(pos, err)
+			return
+		}
+		pos, _ = e.ordered()
+	}
+	next, err := e.doc.InsertText(pos, text)
+	e.afterEdit(next, err)
+}
+
+// erase 删除键删除两个位置之间的文字，连续删除合并为一步撤销
+func (e *RichTextEditor) erase(start, end document.TextPosition) {
+	e.checkpoint("删除", true)
+	e.deleteRange(start, end)
+}
+
+// deleteSelection 删除选择的文字
+func (e *RichTextEditor) deleteSelection() {
+	start, end := e.ordered()
+	e.deleteRange(start, end)
+}
+
+// deleteRange 删除两个位置之间的文字
+func (e *RichTextEditor) deleteRange(start, end document.TextPosition) {
+	err := e.doc.DeleteText(document.TextRange{
+		StartParagraph: start.Paragraph,
+		StartOffset:    start.Offset,
+		EndParagraph:   end.Paragraph,
+		EndOffset:      end.Offset,
+	})
+	e.afterEdit(start, err)
+}
+
+// afterEdit 编辑后重新排版，将光标移到 pos 并通知文档已修改
+func (e *RichTextEditor) afterEdit(pos document.TextPosition, err error) {
+	if err != nil {
+		log.Printf("编辑失败: %v", err)
+	}
+	e.relayout()
+	edit := e.lastEdit
+	e.moveTo(pos, false)
+	e.lastEdit = edit
+	if err == nil && e.OnChanged != nil {
+		e.OnChanged()
+	}
+}
+
+// editorRenderer 编辑器的渲染器
+type editorRenderer struct {
+	editor     *RichTextEditor
+	version    int // 已显示的排版结果版本
+	background *canvas.Rectangle
+	selection  *fyne.Container
+	content    *fyne.Container
+	caret      *canvas.Rectangle
+}
+
+// Layout 页面居中显示
+func (r *editorRenderer) Layout(fyne.Size) {
+	e := r.editor
+	origin := e.origin()
+	r.background.Move(origin)
+	r.background.Resize(fyne.NewSize(float32(e.page.Width*e.scale()), float32(e.page.Height*e.scale())))
+	r.content.Move(origin)
+	r.updateCaret()
+}
+
+// MinSize 返回编辑器的最小尺寸
+func (r *editorRenderer) MinSize() fyne.Size {
+	return r.editor.MinSize()
+}
+
+// Refresh 排版结果变化时重建页面内容，并更新选择范围和光标
+func (r *editorRenderer) Refresh() {
+	e := r.editor
+	if r.version != e.version {
+		r.version = e.version
+		r.content.Objects = pageContent(e.page, e.scale(), e.images)
+	}
+	r.Layout(e.Size())
+	r.updateSelection()
+	canvas.Refresh(e)
+}
+
+// updateCaret 移动光标，编辑器没有焦点时不显示光标
+func (r *editorRenderer) updateCaret() {
+	pos, height := r.editor.caretRect()
+	r.caret.Move(fyne.NewPos(pos.X-editorCaretWidth/2, pos.Y))
+	r.caret.Resize(fyne.NewSize(editorCaretWidth, height))
+	if r.editor.focused {
+		r.caret.Show()
+	} else {
+		r.caret.Hide()
+	}
+}
+
+// updateSelection 为选择范围中的每一行创建背景矩形
+func (r *editorRenderer) updateSelection() {
+	e := r.editor
+	r.selection.Objects = nil
+	if _, ok := e.Selection(); ok {
+		start, end := e.ordered()
+		fill := theme.Color(theme.ColorNameSelection)
+		origin := e.origin()
+		for _, index := range e.lines {
+			line := &e.page.Lines[index]
+			if line.Paragraph < start.Paragraph || line.Paragraph > end.Paragraph {
+				continue
+			}
+			from, to := line.Start, line.End
+			if line.Paragraph == start.Paragraph && start.Offset > from {
+				from = start.Offset
+			}
+			if line.Paragraph == end.Paragraph && end.Offset < to {
+				to = end.Offset
+			}
+			if from > to {
+				continue
+			}
+			left, right := e.offsetX(line, from), e.offsetX(line, to)
+			// 选择范围包含段落标记
+			if line.Paragraph < end.Paragraph && line.End == e.lengths[line.Paragraph] {
+				right += paragraphMarkWidth
+			}
+			if right <= left {
+				continue
+			}
+			rect := canvas.NewRectangle(fill)
+			rect.Move(fyne.NewPos(origin.X+float32((line.X+left)*e.scale()), origin.Y+float32(line.Y*e.scale())))
+			rect.Resize(fyne.NewSize(float32((right-left)*e.scale()), float32(line.Height*e.scale())))
+			r.selection.Add(rect)
+		}
+	}
+	r.selection.Refresh()
+}
+
+// Objects 返回渲染的对象，选择范围位于文字下方
+func (r *editorRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.background, r.selection, r.content, r.caret}
+}
+
+// Destroy 实现 fyne.WidgetRenderer
+func (r *editorRenderer) Destroy() {
+}
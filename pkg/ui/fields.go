@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// fieldTypeNames 域类型在界面中显示的名称
+var fieldTypeNames = map[document.FieldType]string{
+	document.FieldPage:        "页码",
+	document.FieldNumPages:    "总页数",
+	document.FieldDate:        "日期",
+	document.FieldAuthor:      "作者",
+	document.FieldTitle:       "标题",
+	document.FieldRef:         "交叉引用",
+	document.FieldSeq:         "顺序编号",
+	document.FieldDocProperty: "文档属性",
+}
+
+// fieldDateFormats 日期域可以选择的格式
+var fieldDateFormats = []string{"yyyy/M/d", "yyyy-MM-dd", "yyyy年M月d日", "yyyy年M月d日 dddd", "yyyy/M/d HH:mm", "HH:mm:ss"}
+
+// fieldSequenceNames 顺序编号域常用的序列名称
+var fieldSequenceNames = []string{"图", "表", "公式", "Figure", "Table", "Equation"}
+
+// fieldStandardProperties 文档属性域可以选择的Word内置属性名称
+var fieldStandardProperties = []string{
+	"Title", "Subject", "Author", "Keywords", "Comments", "Category", "Company", "Manager", "LastSavedBy", "CreateTime", "LastSavedTime",
+}
+
+// fieldTypeLabel 返回域类型在列表中显示的文字，如 "页码 (PAGE)"
+func fieldTypeLabel(t document.FieldType) string {
+	if name, ok := fieldTypeNames[t]; ok {
+		return fmt.Sprintf("%s (%s)", name, t)
+	}
+	return string(t)
+}
+
+// fieldArgumentOptions 返回域参数可以选择的值
+func fieldArgumentOptions(doc *document.Document, t document.FieldType) []string {
+	switch t {
+	case document.FieldRef:
+		return bookmarkNames(doc, true)
+	case document.FieldSeq:
+		return fieldSequenceNames
+	case document.FieldDocProperty:
+		seen := make(map[string]bool)
+		var names []string
+		for _, name := range append(append([]string{}, fieldStandardProperties...), doc.DocumentPropertyNames()...) {
+			if !seen[strings.ToLower(name)] {
+				seen[strings.ToLower(name)] = true
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// ShowFieldDialog 显示插入域的对话框，在 pos 处插入选择的域并计算结果，插入前记录撤销点
+func ShowFieldDialog(doc *document.Document, pos document.TextPosition, opts document.FieldUpdateOptions,
+	window fyne.Window, onInserted func(document.TextPosition)) {
+	var labels []string
+	for _, t := range document.FieldTypes {
+		labels = append(labels, fieldTypeLabel(t))
+	}
+
+	argument := widget.NewSelectEntry(nil)
+	format := widget.NewSelectEntry(fieldDateFormats)
+	format.SetText(fieldDateFormats[0])
+	code := widget.NewLabel("")
+	sample := widget.NewLabel("")
+
+	var selected document.FieldType
+	fieldCode := func() document.FieldCode {
+		c := document.FieldCode{Type: selected, Argument: strings.TrimSpace(argument.Text)}
+		if selected == document.FieldDate {
+			c.Format = strings.TrimSpace(format.Text)
+		}
+		return c
+	}
+	refresh := func() {
+		c := fieldCode()
+		code.SetText("{ " + c.String() + " }")
+		if selected == document.FieldDate {
+			sample.SetText(document.FormatFieldDate(time.Now(), c.Format))
+		} else {
+			sample.SetText("")
+		}
+	}
+	argument.OnChanged = func(string) { refresh() }
+	format.OnChanged = func(string) { refresh() }
+
+	var kind *widget.Select
+	kind = widget.NewSelect(labels, func(string) {
+		selected = document.FieldTypes[kind.SelectedIndex()]
+		options := fieldArgumentOptions(doc, selected)
+		argument.SetOptions(options)
+		argument.SetText("")
+		if len(options) > 0 {
+			argument.SetText(options[0])
+		}
+		if selected.NeedsArgument() {
+			argument.Enable()
+		} else {
+			argument.Disable()
+		}
+		if selected == document.FieldDate {
+			format.Enable()
+		} else {
+			format.Disable()
+		}
+		refresh()
+	})
+	kind.SetSelectedIndex(0)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("类型", kind),
+		widget.NewFormItem("参数", argument),
+		widget.NewFormItem("日期格式", format),
+		widget.NewFormItem("域代码", code),
+		widget.NewFormItem("示例", sample),
+	}
+	d := dialog.NewForm("插入域", "插入", "取消", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		doc.Checkpoint("插入域")
+		end, err := doc.InsertField(pos, fieldCode(), opts)
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		if onInserted != nil {
+			onInserted(end)
+		}
+	}, window)
+	d.Resize(fyne.NewSize(460, 0))
+	d.Show()
+}
+
+// fieldOptions 返回计算域结果的选项，页码由编辑器使用的排版引擎计算
+func (gcv *ContentView) fieldOptions() document.FieldUpdateOptions {
+	var opts document.FieldUpdateOptions
+	if gcv.engine != nil {
+		opts.Pages = gcv.engine
+	}
+	return opts
+}
+
+// createFieldsView 创建域列表视图，结果与按当前文档计算的值不同时一并显示
+func (gcv *ContentView) createFieldsView(doc *document.Document) []fyne.CanvasObject {
+	var widgets []fyne.CanvasObject
+
+	widgets = append(widgets, widget.NewLabel("域"))
+	widgets = append(widgets, widget.NewSeparator())
+
+	if doc.Package == nil {
+		widgets = append(widgets, widget.NewLabel("文档不支持域"))
+		return widgets
+	}
+	fields, err := doc.Fields()
+	if err != nil {
+		widgets = append(widgets, widget.NewLabel(err.Error()))
+		return widgets
+	}
+	values, _ := doc.EvaluateFields(gcv.fieldOptions())
+
+	update := widget.NewButton("更新域", func() {
+		doc.Checkpoint("更新域")
+		_, err := doc.UpdateFields(gcv.fieldOptions())
+		gcv.afterChange(err, "fields")
+	})
+	widgets = append(widgets, container.NewHBox(update))
+	if len(fields) == 0 {
+		widgets = append(widgets, widget.NewLabel("文档中没有域"))
+	}
+	for _, field := range fields {
+		field := field
+		location := "表格中"
+		if field.Paragraph >= 0 {
+			location = fmt.Sprintf("段落 %d", field.Paragraph+1)
+		}
+		text := fmt.Sprintf("%s  { %s }  %s\n结果: %s", fieldTypeLabel(field.Type), field.Instruction, location, field.Result)
+		if value, ok := values[field.Index]; !ok {
+			text += "（不支持更新）"
+		} else if value != field.Result {
+			text += fmt.Sprintf("（更新后: %s）", value)
+		}
+		label := widget.NewLabel(text)
+		label.Wrapping = fyne.TextWrapWord
+		goTo := widget.NewButton("定位", func() {
+			gcv.ShowPosition(document.TextPosition{Paragraph: field.Paragraph, Offset: field.Offset})
+		})
+		if field.Paragraph < 0 {
+			goTo.Disable()
+		}
+		widgets = append(widgets, label, container.NewHBox(goTo))
+	}
+	return widgets
+}
@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// headerFooterTypes 页眉页脚类型选项，顺序与名称对应
+var headerFooterTypes = []string{document.HeaderFooterDefault, document.HeaderFooterFirst, document.HeaderFooterEven}
+
+// fieldHint 提示如何在页眉页脚中插入页码
+const fieldHint = "使用 {PAGE} 插入页码，{NUMPAGES} 插入总页数"
+
+// headerFooterTitle 返回页眉页脚的显示名称
+func headerFooterTitle(hf document.HeaderFooter) string {
+	return fmt.Sprintf("第 %d 节 %s%s", hf.Section+1, document.HeaderFooterTypeName(hf.Type), hf.Kind)
+}
+
+// createHeadersView 创建页眉页脚列表视图，可以为节添加页眉页脚
+func (gcv *ContentView) createHeadersView(doc *document.Document) []fyne.CanvasObject {
+	var widgets []fyne.CanvasObject
+
+	widgets = append(widgets, widget.NewLabel("页眉页脚"))
+	widgets = append(widgets, widget.NewSeparator())
+
+	if doc.Package == nil {
+		widgets = append(widgets, widget.NewLabel("文档不支持页眉页脚"))
+		return widgets
+	}
+
+	headers, err := doc.HeadersFooters()
+	if err != nil {
+		widgets = append(widgets, widget.NewLabel(err.Error()))
+		return widgets
+	}
+	for _, hf := range headers {
+		text := ""
+		if len(hf.Paragraphs) > 0 {
+			text = hf.Paragraphs[0]
+		}
+		widgets = append(widgets, widget.NewLabel(fmt.Sprintf("%s: %s", headerFooterTitle(hf), truncateRunes(text, 40))))
+	}
+	if len(headers) == 0 {
+		widgets = append(widgets, widget.NewLabel("文档没有页眉页脚"))
+	}
+	widgets = append(widgets, widget.NewSeparator())
+
+	// 添加页眉页脚
+	count := doc.SectionCount()
+	if count == 0 {
+		count = 1
+	}
+	var sections []string
+	for i := 0; i < count; i++ {
+		sections = append(sections, fmt.Sprintf("第 %d 节", i+1))
+	}
+	sectionSelect := widget.NewSelect(sections, nil)
+	sectionSelect.SetSelectedIndex(0)
+	kindSelect := widget.NewSelect([]string{document.HeaderKind.String(), document.FooterKind.String()}, nil)
+	kindSelect.SetSelectedIndex(0)
+	var typeNames []string
+	for _, t := range headerFooterTypes {
+		typeNames = append(typeNames, document.HeaderFooterTypeName(t))
+	}
+	typeSelect := widget.NewSelect(typeNames, nil)
+	typeSelect.SetSelectedIndex(0)
+
+	entry := widget.NewMultiLineEntry()
+	entry.SetPlaceHolder(fieldHint)
+	addBtn := widget.NewButtonWithIcon("添加", theme.ContentAddIcon(), func() {
+		kind := document.HeaderFooterKind(kindSelect.SelectedIndex())
+		hfType := headerFooterTypes[typeSelect.SelectedIndex()]
+		doc.Checkpoint("添加页眉页脚")
+		_, err := doc.AddHeaderFooter(sectionSelect.SelectedIndex(), kind, hfType, entry.Text)
+		gcv.afterChange(err, "headers")
+	})
+
+	widgets = append(widgets, widget.NewLabel("添加页眉页脚"))
+	widgets = append(widgets, container.NewHBox(sectionSelect, kindSelect, typeSelect))
+	widgets = append(widgets, entry, container.NewHBox(addBtn))
+	return widgets
+}
+
+// createHeaderDetailView 创建单个页眉页脚的编辑视图
+func (gcv *ContentView) createHeaderDetailView(doc *document.Document, index int) []fyne.CanvasObject {
+	var widgets []fyne.CanvasObject
+
+	headers, err := doc.HeadersFooters()
+	if err != nil || index >= len(headers) {
+		return widgets
+	}
+	hf := headers[index]
+	node := fmt.Sprintf("h%d", index+1)
+
+	widgets = append(widgets, widget.NewLabel(fmt.Sprintf("%s (%s)", headerFooterTitle(hf), hf.Part)))
+	widgets = append(widgets, widget.NewSeparator())
+	widgets = append(widgets, widget.NewLabel(fieldHint))
+
+	// 每个段落一个编辑框
+	entries := make([]*widget.Entry, len(hf.Paragraphs))
+	for i, text := range hf.Paragraphs {
+		entry := widget.NewMultiLineEntry()
+		entry.SetText(text)
+		entries[i] = entry
+		pageBtn := widget.NewButton("页码", func() {
+			entry.SetText(entry.Text + "{PAGE}")
+		})
+		totalBtn := widget.NewButton("总页数", func() {
+			entry.SetText(entry.Text + "{NUMPAGES}")
+		})
+		widgets = append(widgets, widget.NewLabel(fmt.Sprintf("段落 %d", i+1)))
+		widgets = append(widgets, container.NewBorder(nil, nil, nil, container.NewHBox(pageBtn, totalBtn), entry))
+	}
+
+	saveBtn := widget.NewButtonWithIcon("保存修改", theme.DocumentSaveIcon(), func() {
+		changed := false
+		for i, entry := range entries {
+			if entry.Text == hf.Paragraphs[i] {
+				continue
+			}
+			if !changed {
+				doc.Checkpoint("编辑" + headerFooterTitle(hf))
+				changed = true
+			}
+			if err := doc.SetHeaderFooterParagraph(hf.Part, i, entry.Text); err != nil {
+				gcv.afterChange(err, node)
+				return
+			}
+		}
+		gcv.afterChange(nil, node)
+	})
+	removeBtn := widget.NewButtonWithIcon("删除", theme.DeleteIcon(), func() {
+		remove := func() {
+			doc.Checkpoint("删除" + headerFooterTitle(hf))
+			gcv.afterChange(doc.RemoveHeaderFooter(hf.Section, hf.Kind, hf.Type), "headers")
+		}
+		if gcv.window == nil {
+			remove()
+			return
+		}
+		dialog.ShowConfirm("删除"+hf.Kind.String(), fmt.Sprintf("确定删除%s吗？", headerFooterTitle(hf)),
+			func(ok bool) {
+				if ok {
+					remove()
+				}
+			}, gcv.window)
+	})
+	widgets = append(widgets, widget.NewSeparator())
+	widgets = append(widgets, container.NewHBox(saveBtn, removeBtn))
+	return widgets
+}
@@ -0,0 +1,383 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// linkKinds 超链接目标类型选项
+var linkKinds = []string{"网址", "本文档中的书签"}
+
+// linkSummary 返回超链接在列表中显示的摘要
+func linkSummary(link document.Hyperlink) string {
+	return fmt.Sprintf("%s → %s", truncateRunes(link.Text, 20), link.Link())
+}
+
+// normalizeURL 为没有协议的地址补充协议，包含 @ 的地址作为邮件地址
+func normalizeURL(address string) string {
+	address = strings.TrimSpace(address)
+	if address == "" || strings.Contains(address, ":") {
+		return address
+	}
+	if strings.Contains(address, "@") && !strings.Contains(address, "/") {
+		return "mailto:" + address
+	}
+	return "https://" + address
+}
+
+// bookmarkNames 返回文档中书签的名称，hidden 为 false 时不包括隐藏书签
+func bookmarkNames(doc *document.Document, hidden bool) []string {
+	bookmarks, err := doc.Bookmarks()
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, bookmark := range bookmarks {
+		if hidden || !bookmark.Hidden {
+			names = append(names, bookmark.Name)
+		}
+	}
+	return names
+}
+
+// ShowHyperlinkDialog 显示插入或编辑超链接的对话框，修改前记录撤销点
+//
+// r 是编辑器中选择的文字，没有选择文字时为光标位置。位置在已有超链接中时编辑该超链接，
+// 并可以取消链接；否则将选择的文字设置为超链接，没有选择文字时插入显示文字。
+func ShowHyperlinkDialog(doc *document.Document, r document.TextRange, window fyne.Window, onApplied func()) {
+	if r.StartParagraph != r.EndParagraph {
+		dialog.ShowError(fmt.Errorf("超链接不能跨越多个段落"), window)
+		return
+	}
+	paragraphText, err := doc.ParagraphText(r.StartParagraph)
+	if err != nil {
+		dialog.ShowError(err, window)
+		return
+	}
+	runes := []rune(paragraphText)
+	if r.EndOffset < 0 || r.EndOffset > len(runes) {
+		r.EndOffset = len(runes)
+	}
+	selected := string(runes[r.StartOffset:r.EndOffset])
+
+	existing, editing := doc.HyperlinkAt(r.StartParagraph, r.StartOffset)
+	if editing && r.EndOffset > existing.Offset+existing.Length {
+		editing = false
+	}
+
+	text := widget.NewEntry()
+	text.SetText(selected)
+	address := widget.NewEntry()
+	address.SetPlaceHolder("https://")
+	bookmark := widget.NewSelect(bookmarkNames(doc, false), nil)
+	bookmark.PlaceHolder = "选择书签"
+	tooltip := widget.NewEntry()
+	tooltip.SetPlaceHolder("鼠标指向链接时显示的提示（可选）")
+	kind := widget.NewRadioGroup(linkKinds, func(value string) {
+		if value == linkKinds[0] {
+			address.Enable()
+			bookmark.Disable()
+		} else {
+			address.Disable()
+			bookmark.Enable()
+		}
+	})
+	kind.Horizontal = true
+	kind.Required = true
+	kind.SetSelected(linkKinds[0])
+
+	title := "插入超链接"
+	if editing {
+		title = "编辑超链接"
+		text.SetText(existing.Text)
+		tooltip.SetText(existing.Tooltip)
+		if existing.URL != "" {
+			address.SetText(existing.URL)
+		} else {
+			bookmark.SetSelected(existing.Anchor)
+			kind.SetSelected(linkKinds[1])
+		}
+	}
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("显示文字", text),
+		widget.NewFormItem("链接到", kind),
+		widget.NewFormItem("地址", address),
+		widget.NewFormItem("书签", bookmark),
+		widget.NewFormItem("屏幕提示", tooltip),
+	}
+	var d dialog.Dialog
+	if editing {
+		remove := widget.NewButtonWithIcon("取消链接", theme.DeleteIcon(), func() {
+			doc.Checkpoint("取消超链接")
+			if err := doc.RemoveHyperlink(existing.Index); err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			d.Hide()
+			if onApplied != nil {
+				onApplied()
+			}
+		})
+		items = append(items, widget.NewFormItem("", container.NewHBox(remove)))
+	}
+
+	d = dialog.NewForm(title, "确定", "取消", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		target := document.LinkTarget{Tooltip: strings.TrimSpace(tooltip.Text)}
+		if kind.Selected == linkKinds[0] {
+			target.URL = normalizeURL(address.Text)
+		} else {
+			target.Anchor = bookmark.Selected
+		}
+		display := strings.NewReplacer("\r", "", "\n", " ", "\v", " ").Replace(text.Text)
+		if display == "" {
+			display = target.Link()
+		}
+
+		doc.Checkpoint(title)
+		if editing {
+			err = doc.EditHyperlink(existing.Index, target, display)
+		} else {
+			err = insertHyperlink(doc, r, selected, display, target)
+		}
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		if onApplied != nil {
+			onApplied()
+		}
+	}, window)
+	d.Resize(fyne.NewSize(480, 0))
+	d.Show()
+}
+
+// insertHyperlink 将范围内的文字替换为 display 后设置为超链接，display 与原文字相同时保留原文字的格式
+func insertHyperlink(doc *document.Document, r document.TextRange, selected, display string, target document.LinkTarget) error {
+	start := document.TextPosition{Paragraph: r.StartParagraph, Offset: r.StartOffset}
+	end := document.TextPosition{Paragraph: r.EndParagraph, Offset: r.EndOffset}
+	if display != selected {
+		if selected != "" {
+			if err := doc.DeleteText(r); err != nil {
+				return err
+			}
+		}
+		var err error
+		if end, err = doc.InsertText(start, display); err != nil {
+			return err
+		}
+	}
+	return doc.InsertHyperlink(document.TextRange{
+		StartParagraph: start.Paragraph,
+		StartOffset:    start.Offset,
+		EndParagraph:   end.Paragraph,
+		EndOffset:      end.Offset,
+	}, target)
+}
+
+// ShowBookmarkDialog 显示书签对话框，可以为选择的文字添加书签，删除、重命名书签或定位到书签
+//
+// onGoTo 在定位到书签时调用，onChanged 在书签被修改后调用。
+func ShowBookmarkDialog(doc *document.Document, r document.TextRange, window fyne.Window,
+	onGoTo func(document.Bookmark), onChanged func()) {
+	name := widget.NewEntry()
+	name.SetPlaceHolder("书签名称")
+	showHidden := widget.NewCheck("显示隐藏书签", nil)
+	names := bookmarkNames(doc, false)
+	selected := -1
+	list := widget.NewList(
+		func() int { return len(names) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) { o.(*widget.Label).SetText(names[id]) },
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		selected = id
+		name.SetText(names[id])
+	}
+	reload := func() {
+		names = bookmarkNames(doc, showHidden.Checked)
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+	}
+	showHidden.OnChanged = func(bool) { reload() }
+
+	// change 修改书签后刷新列表
+	change := func(label string, apply func() error) {
+		doc.Checkpoint(label)
+		if err := apply(); err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		reload()
+		if onChanged != nil {
+			onChanged()
+		}
+	}
+	current := func() (string, bool) {
+		if selected < 0 || selected >= len(names) {
+			dialog.ShowInformation("提示", "请先在列表中选择书签", window)
+			return "", false
+		}
+		return names[selected], true
+	}
+
+	var d dialog.Dialog
+	add := widget.NewButtonWithIcon("添加", theme.ContentAddIcon(), func() {
+		change("添加书签", func() error { return doc.AddBookmark(strings.TrimSpace(name.Text), r) })
+	})
+	rename := widget.NewButton("重命名", func() {
+		if old, ok := current(); ok {
+			change("重命名书签", func() error { return doc.RenameBookmark(old, strings.TrimSpace(name.Text)) })
+		}
+	})
+	remove := widget.NewButtonWithIcon("删除", theme.DeleteIcon(), func() {
+		if old, ok := current(); ok {
+			change("删除书签", func() error { return doc.RemoveBookmark(old) })
+		}
+	})
+	goTo := widget.NewButtonWithIcon("定位", theme.NavigateNextIcon(), func() {
+		old, ok := current()
+		if !ok {
+			return
+		}
+		bookmark, err := doc.FindBookmark(old)
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		d.Hide()
+		if onGoTo != nil {
+			onGoTo(bookmark)
+		}
+	})
+
+	content := container.NewBorder(
+		container.NewVBox(name, showHidden),
+		container.NewHBox(add, rename, remove, goTo),
+		nil, nil, list)
+	d = dialog.NewCustom("书签", "关闭", content, window)
+	d.Resize(fyne.NewSize(400, 420))
+	d.Show()
+}
+
+// FollowLink 打开超链接：外部地址用系统默认程序打开，书签链接在编辑器中定位到书签
+func (gcv *ContentView) FollowLink(link string) {
+	doc := gcv.docManager.GetCurrentDocument()
+	if doc == nil {
+		return
+	}
+	if strings.HasPrefix(link, "#") {
+		bookmark, err := doc.FindBookmark(link[1:])
+		if err == nil && bookmark.Paragraph < 0 {
+			err = fmt.Errorf("书签不在正文段落中: %s", bookmark.Name)
+		}
+		if err != nil {
+			gcv.afterChange(err, gcv.currentNode)
+			return
+		}
+		gcv.ShowPosition(document.TextPosition{Paragraph: bookmark.Paragraph, Offset: bookmark.Offset})
+		return
+	}
+	target, err := url.Parse(link)
+	if err == nil {
+		err = fyne.CurrentApp().OpenURL(target)
+	}
+	if err != nil {
+		log.Printf("打开链接失败: %v", err)
+		gcv.afterChange(fmt.Errorf("打开链接失败: %v", err), gcv.currentNode)
+	}
+}
+
+// createLinksView 创建超链接和书签列表视图
+func (gcv *ContentView) createLinksView(doc *document.Document) []fyne.CanvasObject {
+	var widgets []fyne.CanvasObject
+
+	widgets = append(widgets, widget.NewLabel("超链接和书签"))
+	widgets = append(widgets, widget.NewSeparator())
+
+	if doc.Package == nil {
+		widgets = append(widgets, widget.NewLabel("文档不支持超链接和书签"))
+		return widgets
+	}
+	links, err := doc.Hyperlinks()
+	if err != nil {
+		widgets = append(widgets, widget.NewLabel(err.Error()))
+		return widgets
+	}
+	bookmarks, _ := doc.Bookmarks()
+
+	widgets = append(widgets, widget.NewLabelWithStyle(fmt.Sprintf("超链接 (%d)", len(links)),
+		fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	if len(links) == 0 {
+		widgets = append(widgets, widget.NewLabel("文档中没有超链接"))
+	}
+	for _, link := range links {
+		link := link
+		location := "表格中"
+		if link.Paragraph >= 0 {
+			location = fmt.Sprintf("段落 %d", link.Paragraph+1)
+		}
+		label := widget.NewLabel(fmt.Sprintf("%s  %s", linkSummary(link), location))
+		label.Wrapping = fyne.TextWrapWord
+		open := widget.NewButton("打开", func() { gcv.FollowLink(link.Link()) })
+		edit := widget.NewButton("编辑", func() {
+			r := document.TextRange{
+				StartParagraph: link.Paragraph,
+				StartOffset:    link.Offset,
+				EndParagraph:   link.Paragraph,
+				EndOffset:      link.Offset + link.Length,
+			}
+			ShowHyperlinkDialog(doc, r, gcv.window, func() { gcv.afterChange(nil, "links") })
+		})
+		remove := widget.NewButton("取消链接", func() {
+			doc.Checkpoint("取消超链接")
+			gcv.afterChange(doc.RemoveHyperlink(link.Index), "links")
+		})
+		if link.Paragraph < 0 {
+			edit.Disable()
+		}
+		widgets = append(widgets, label, container.NewHBox(open, edit, remove))
+	}
+
+	widgets = append(widgets, widget.NewSeparator())
+	widgets = append(widgets, widget.NewLabelWithStyle(fmt.Sprintf("书签 (%d)", len(bookmarks)),
+		fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	if len(bookmarks) == 0 {
+		widgets = append(widgets, widget.NewLabel("文档中没有书签"))
+	}
+	for _, bookmark := range bookmarks {
+		bookmark := bookmark
+		location := "表格中"
+		if bookmark.Paragraph >= 0 {
+			location = fmt.Sprintf("段落 %d", bookmark.Paragraph+1)
+		}
+		name := bookmark.Name
+		if bookmark.Hidden {
+			name += "（隐藏）"
+		}
+		goTo := widget.NewButton("定位", func() { gcv.FollowLink("#" + bookmark.Name) })
+		remove := widget.NewButton("删除", func() {
+			doc.Checkpoint("删除书签")
+			gcv.afterChange(doc.RemoveBookmark(bookmark.Name), "links")
+		})
+		if bookmark.Paragraph < 0 {
+			goTo.Disable()
+		}
+		widgets = append(widgets, container.NewHBox(widget.NewLabel(fmt.Sprintf("%s  %s", name, location)), goTo, remove))
+	}
+	return widgets
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// listLevels 列表级别选项
+var listLevels = []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"}
+
+// listSummary 返回段落列表信息的描述
+func listSummary(item document.ListItem, ok bool) string {
+	if !ok {
+		return "列表: 无"
+	}
+	return fmt.Sprintf("列表: %s，第 %d 级，编号 %q", item.Kind, item.Level+1, item.Label)
+}
+
+// createParagraphList 创建段落的列表设置，可以切换项目符号和编号、修改级别和重新编号
+func (gcv *ContentView) createParagraphList(doc *document.Document, adapter *document.DocumentAdapter, index int) []fyne.CanvasObject {
+	node := fmt.Sprintf("p%d", index+1)
+	item, inList := adapter.GetParagraphListItem(index)
+
+	bullets := widget.NewButton("项目符号", func() {
+		doc.Checkpoint("项目符号")
+		gcv.afterChange(doc.ToggleList(document.BulletList, index, index), node)
+	})
+	numbering := widget.NewButton("编号", func() {
+		doc.Checkpoint("编号")
+		gcv.afterChange(doc.ToggleList(document.NumberedList, index, index), node)
+	})
+	if inList {
+		if item.Kind == document.BulletList {
+			bullets.Importance = widget.HighImportance
+		} else {
+			numbering.Importance = widget.HighImportance
+		}
+	}
+
+	level := widget.NewSelect(listLevels, nil)
+	restart := widget.NewButton("重新编号", func() {
+		doc.Checkpoint("重新编号")
+		gcv.afterChange(doc.RestartNumbering(index), node)
+	})
+	if inList {
+		level.SetSelectedIndex(item.Level)
+		level.OnChanged = func(value string) {
+			l, _ := strconv.Atoi(value)
+			if l-1 != item.Level {
+				doc.Checkpoint("设置列表级别")
+				gcv.afterChange(doc.SetListLevel(index, l-1), node)
+			}
+		}
+	} else {
+		level.Disable()
+	}
+	if !inList || item.Kind != document.NumberedList {
+		restart.Disable()
+	}
+
+	return []fyne.CanvasObject{
+		widget.NewLabel(listSummary(item, inList)),
+		container.NewHBox(bullets, numbering, widget.NewLabel("级别"), level, restart),
+	}
+}
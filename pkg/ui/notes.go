@@ -0,0 +1,211 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// noteKinds 注释类型选项，顺序与名称对应
+var noteKinds = []document.NoteKind{document.FootnoteKind, document.EndnoteKind}
+
+// noteTitle 返回注释的类型和编号
+func noteTitle(note document.Note) string {
+	if note.Number == 0 {
+		return fmt.Sprintf("%s (未引用)", note.Kind)
+	}
+	return fmt.Sprintf("%s %d", note.Kind, note.Number)
+}
+
+// noteSummary 返回注释在树形视图中显示的摘要
+func noteSummary(note document.Note) string {
+	return fmt.Sprintf("%s: %s", noteTitle(note), truncateRunes(note.Text, 20))
+}
+
+// noteForm 插入脚注或尾注的输入控件
+type noteForm struct {
+	kind     *widget.RadioGroup
+	position *widget.Entry
+	text     *widget.Entry
+}
+
+// newNoteForm 创建插入注释的输入控件
+func newNoteForm() *noteForm {
+	var names []string
+	for _, kind := range noteKinds {
+		names = append(names, kind.String())
+	}
+	form := &noteForm{
+		kind:     widget.NewRadioGroup(names, nil),
+		position: widget.NewEntry(),
+		text:     widget.NewMultiLineEntry(),
+	}
+	form.kind.Horizontal = true
+	form.kind.SetSelected(names[0])
+	form.position.SetPlaceHolder("插入位置（第几个字符之后，留空为段落末尾）")
+	form.text.SetPlaceHolder("注释内容")
+	return form
+}
+
+// widgets 返回表单控件
+func (f *noteForm) widgets() []fyne.CanvasObject {
+	return []fyne.CanvasObject{f.kind, f.position, f.text}
+}
+
+// insert 按表单内容在段落中插入注释
+func (f *noteForm) insert(doc *document.Document, paragraph int) error {
+	offset := -1
+	if position := strings.TrimSpace(f.position.Text); position != "" {
+		value, err := strconv.Atoi(position)
+		if err != nil || value < 0 {
+			return fmt.Errorf("无效的插入位置: %s", position)
+		}
+		offset = value
+	}
+	kind := document.FootnoteKind
+	if f.kind.Selected == document.EndnoteKind.String() {
+		kind = document.EndnoteKind
+	}
+	doc.Checkpoint("插入" + kind.String())
+	_, err := doc.InsertNote(kind, paragraph, offset, f.text.Text)
+	return err
+}
+
+// createNotesView 创建脚注和尾注列表视图，可以在段落中插入注释
+func (gcv *ContentView) createNotesView(doc *document.Document) []fyne.CanvasObject {
+	var widgets []fyne.CanvasObject
+
+	widgets = append(widgets, widget.NewLabel("脚注和尾注"))
+	widgets = append(widgets, widget.NewSeparator())
+
+	if doc.Package == nil {
+		widgets = append(widgets, widget.NewLabel("文档不支持脚注和尾注"))
+		return widgets
+	}
+
+	notes, err := doc.AllNotes()
+	if err != nil {
+		widgets = append(widgets, widget.NewLabel(err.Error()))
+		return widgets
+	}
+
+	// 插入注释
+	adapter := document.NewDocumentAdapter(doc)
+	var options []string
+	for i := 0; i < adapter.GetParagraphCount(); i++ {
+		options = append(options, fmt.Sprintf("段落 %d: %s", i+1, truncateRunes(adapter.GetParagraphText(i), 20)))
+	}
+	paragraphSelect := widget.NewSelect(options, nil)
+	paragraphSelect.PlaceHolder = "选择段落"
+	form := newNoteForm()
+	insertBtn := widget.NewButtonWithIcon("插入", theme.ContentAddIcon(), func() {
+		index := paragraphSelect.SelectedIndex()
+		if index < 0 {
+			gcv.afterChange(fmt.Errorf("请选择要插入注释的段落"), "notes")
+			return
+		}
+		gcv.afterChange(form.insert(doc, index), "notes")
+	})
+	widgets = append(widgets, paragraphSelect)
+	widgets = append(widgets, form.widgets()...)
+	widgets = append(widgets, container.NewHBox(insertBtn))
+	widgets = append(widgets, widget.NewSeparator())
+
+	if len(notes) == 0 {
+		widgets = append(widgets, widget.NewLabel("文档中没有脚注和尾注"))
+		return widgets
+	}
+	for _, note := range notes {
+		location := "未找到引用"
+		if note.Paragraph >= 0 {
+			location = fmt.Sprintf("段落 %d", note.Paragraph+1)
+		}
+		widgets = append(widgets, widget.NewLabelWithStyle(fmt.Sprintf("%s  %s", noteTitle(note), location),
+			fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		text := widget.NewLabel(note.Text)
+		text.Wrapping = fyne.TextWrapWord
+		widgets = append(widgets, text)
+	}
+
+	return widgets
+}
+
+// createNoteDetailView 创建单个注释的编辑视图
+func (gcv *ContentView) createNoteDetailView(doc *document.Document, index int) []fyne.CanvasObject {
+	var widgets []fyne.CanvasObject
+
+	notes, err := doc.AllNotes()
+	if err != nil || index >= len(notes) {
+		return widgets
+	}
+	note := notes[index]
+	node := fmt.Sprintf("n%d", index+1)
+
+	widgets = append(widgets, widget.NewLabel(noteTitle(note)))
+	widgets = append(widgets, widget.NewSeparator())
+
+	if note.Paragraph >= 0 {
+		if spans, err := doc.ParagraphSpans(note.Paragraph); err == nil {
+			widgets = append(widgets, widget.NewLabel(fmt.Sprintf("引用位置: 段落 %d", note.Paragraph+1)))
+			widgets = append(widgets, NewStyledText(spanSegments(spans)...))
+		}
+	}
+
+	entry := widget.NewMultiLineEntry()
+	entry.SetText(note.Text)
+	saveBtn := widget.NewButtonWithIcon("保存修改", theme.DocumentSaveIcon(), func() {
+		doc.Checkpoint("编辑" + note.Kind.String())
+		gcv.afterChange(doc.SetNoteText(note.Kind, note.ID, entry.Text), node)
+	})
+	removeBtn := widget.NewButtonWithIcon("删除", theme.DeleteIcon(), func() {
+		gcv.confirmDeleteNote(doc, note)
+	})
+	widgets = append(widgets, widget.NewSeparator())
+	widgets = append(widgets, entry, container.NewHBox(saveBtn, removeBtn))
+	return widgets
+}
+
+// createParagraphNotes 创建段落详细视图中的注释区域，可以在段落中插入脚注或尾注
+func (gcv *ContentView) createParagraphNotes(doc *document.Document, notes []document.Note, index int) []fyne.CanvasObject {
+	var widgets []fyne.CanvasObject
+
+	widgets = append(widgets, widget.NewLabel("脚注和尾注"))
+	for _, note := range notes {
+		text := widget.NewLabel(fmt.Sprintf("%s: %s", noteTitle(note), note.Text))
+		text.Wrapping = fyne.TextWrapWord
+		widgets = append(widgets, text)
+	}
+
+	form := newNoteForm()
+	insertBtn := widget.NewButtonWithIcon("插入注释", theme.ContentAddIcon(), func() {
+		gcv.afterChange(form.insert(doc, index), fmt.Sprintf("p%d", index+1))
+	})
+	widgets = append(widgets, form.widgets()...)
+	widgets = append(widgets, container.NewHBox(insertBtn))
+	return widgets
+}
+
+// confirmDeleteNote 确认后删除注释及其引用
+func (gcv *ContentView) confirmDeleteNote(doc *document.Document, note document.Note) {
+	remove := func() {
+		doc.Checkpoint("删除" + note.Kind.String())
+		gcv.afterChange(doc.DeleteNote(note.Kind, note.ID), "notes")
+	}
+	if gcv.window == nil {
+		remove()
+		return
+	}
+	dialog.ShowConfirm("删除"+note.Kind.String(), fmt.Sprintf("确定删除%s吗？", noteTitle(note)), func(ok bool) {
+		if ok {
+			remove()
+		}
+	}, gcv.window)
+}
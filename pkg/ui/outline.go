@@ -0,0 +1,289 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// outlineTree 大纲模式下按标题层级组织的段落树
+type outlineTree struct {
+	children map[string][]string // 节点ID到子节点ID的映射，根节点ID为空字符串
+	levels   map[string]int      // 标题节点的级别
+}
+
+// buildOutline 按标题层级将段落组织为树，正文段落挂在前面最近的标题下
+func buildOutline(doc *document.Document) *outlineTree {
+	outline := &outlineTree{
+		children: make(map[string][]string),
+		levels:   make(map[string]int),
+	}
+	headings, err := doc.Headings()
+	if err != nil {
+		return outline
+	}
+	headingLevels := make(map[int]int)
+	for _, h := range headings {
+		headingLevels[h.Paragraph] = h.Level
+	}
+
+	type entry struct {
+		id    string
+		level int
+	}
+	stack := []entry{{id: "", level: 0}}
+	count := document.NewDocumentAdapter(doc).GetParagraphCount()
+	for i := 0; i < count; i++ {
+		id := fmt.Sprintf("p%d", i+1)
+		level, isHeading := headingLevels[i]
+		if isHeading {
+			for len(stack) > 1 && stack[len(stack)-1].level >= level {
+				stack = stack[:len(stack)-1]
+			}
+		}
+		parent := stack[len(stack)-1].id
+		outline.children[parent] = append(outline.children[parent], id)
+		if isHeading {
+			outline.levels[id] = level
+			stack = append(stack, entry{id: id, level: level})
+		}
+	}
+	return outline
+}
+
+// SetOutlineMode 切换大纲模式，大纲模式下按标题层级显示段落
+func (gtv *TreeView) SetOutlineMode(outline bool) {
+	gtv.outlineMode = outline
+	gtv.outline = nil
+	gtv.tree.UnselectAll()
+	gtv.tree.Refresh()
+}
+
+// OutlineMode 判断是否处于大纲模式
+func (gtv *TreeView) OutlineMode() bool {
+	return gtv.outlineMode
+}
+
+// ExpandAll 展开所有节点
+func (gtv *TreeView) ExpandAll() {
+	gtv.tree.OpenAllBranches()
+}
+
+// CollapseAll 折叠所有节点
+func (gtv *TreeView) CollapseAll() {
+	gtv.tree.CloseAllBranches()
+}
+
+// currentOutline 返回当前文档的大纲，文档修改后重新生成
+func (gtv *TreeView) currentOutline(doc *document.Document) *outlineTree {
+	if gtv.outline == nil {
+		gtv.outline = buildOutline(doc)
+	}
+	return gtv.outline
+}
+
+// visibleNodes 返回大纲中当前可见的节点，顺序与显示顺序一致
+func (gtv *TreeView) visibleNodes(outline *outlineTree) []string {
+	var nodes []string
+	var walk func(id string)
+	walk = func(id string) {
+		for _, child := range outline.children[id] {
+			nodes = append(nodes, child)
+			if gtv.tree.IsBranchOpen(child) {
+				walk(child)
+			}
+		}
+	}
+	walk("")
+	return nodes
+}
+
+// dropHeading 将拖动的标题章节移动到拖动结束位置，rows 为拖动经过的行数
+func (gtv *TreeView) dropHeading(id string, rows int) {
+	doc := gtv.docManager.GetCurrentDocument()
+	if doc == nil || !gtv.outlineMode || rows == 0 {
+		return
+	}
+	outline := gtv.currentOutline(doc)
+	if _, ok := outline.levels[id]; !ok {
+		return
+	}
+
+	visible := gtv.visibleNodes(outline)
+	source := -1
+	for i, node := range visible {
+		if node == id {
+			source = i
+		}
+	}
+	if source < 0 {
+		return
+	}
+	target := source + rows
+	if target < 0 {
+		target = 0
+	}
+	if target >= len(visible) {
+		target = len(visible) - 1
+	}
+	targetID := visible[target]
+	targetIndex := parseIndex(targetID[1:])
+
+	// 向上拖动时放在目标之前，向下拖动时放在目标之后，折叠的标题作为整体
+	before := targetIndex
+	if rows > 0 {
+		before = targetIndex + 1
+		if _, isHeading := outline.levels[targetID]; isHeading && !gtv.tree.IsBranchOpen(targetID) {
+			if _, end, err := doc.SectionRange(targetIndex); err == nil {
+				before = end
+			}
+		}
+	}
+	doc.Checkpoint("移动章节")
+	moved, err := doc.MoveSection(parseIndex(id[1:]), before)
+	gtv.afterOutlineChange(err, moved)
+}
+
+// showOutlineMenu 显示大纲节点的右键菜单
+func (gtv *TreeView) showOutlineMenu(id string, canvas fyne.Canvas, pos fyne.Position) {
+	doc := gtv.docManager.GetCurrentDocument()
+	if doc == nil || !gtv.outlineMode || canvas == nil {
+		return
+	}
+	index := parseIndex(id[1:])
+	if index < 0 {
+		return
+	}
+
+	var items []*fyne.MenuItem
+	if _, isHeading := gtv.currentOutline(doc).levels[id]; isHeading {
+		items = append(items,
+			fyne.NewMenuItem("升级", func() {
+				doc.Checkpoint("升级标题")
+				gtv.afterOutlineChange(doc.PromoteHeading(index), index)
+			}),
+			fyne.NewMenuItem("降级", func() {
+				doc.Checkpoint("降级标题")
+				gtv.afterOutlineChange(doc.DemoteHeading(index), index)
+			}),
+			fyne.NewMenuItem("降为正文", func() {
+				doc.Checkpoint("降为正文")
+				gtv.afterOutlineChange(doc.SetHeadingLevel(index, 0), index)
+			}),
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem("上移章节", func() {
+				doc.Checkpoint("上移章节")
+				moved, err := doc.MoveSectionUp(index)
+				gtv.afterOutlineChange(err, moved)
+			}),
+			fyne.NewMenuItem("下移章节", func() {
+				doc.Checkpoint("下移章节")
+				moved, err := doc.MoveSectionDown(index)
+				gtv.afterOutlineChange(err, moved)
+			}),
+		)
+	} else {
+		for level := 1; level <= 3; level++ {
+			l := level
+			items = append(items, fyne.NewMenuItem(fmt.Sprintf("设为 %d 级标题", l), func() {
+				doc.Checkpoint("设置标题级别")
+				gtv.afterOutlineChange(doc.SetHeadingLevel(index, l), index)
+			}))
+		}
+	}
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), canvas, pos)
+}
+
+// afterOutlineChange 大纲操作后显示错误，或刷新视图并选中 paragraph 段落
+func (gtv *TreeView) afterOutlineChange(err error, paragraph int) {
+	if err != nil {
+		if gtv.window != nil {
+			dialog.ShowError(err, gtv.window)
+		} else {
+			log.Printf("大纲操作失败: %v", err)
+		}
+		return
+	}
+	gtv.Refresh()
+	// 重新选中以刷新内容视图
+	gtv.tree.UnselectAll()
+	gtv.tree.Select(fmt.Sprintf("p%d", paragraph+1))
+}
+
+// updateOutlineNode 更新大纲模式下的节点显示，标题加粗显示
+func (gtv *TreeView) updateOutlineNode(doc *document.Document, id string, label *widget.Label) {
+	index := parseIndex(strings.TrimPrefix(id, "p"))
+	if index < 0 {
+		return
+	}
+	adapter := document.NewDocumentAdapter(doc)
+	text := adapter.GetParagraphText(index)
+	if item, ok := adapter.GetParagraphListItem(index); ok && item.Label != "" {
+		text = item.Label + " " + text
+	}
+	if level, ok := gtv.currentOutline(doc).levels[id]; ok {
+		label.TextStyle = fyne.TextStyle{Bold: true}
+		label.SetText(fmt.Sprintf("H%d %s", level, truncateRunes(text, 30)))
+		return
+	}
+	label.SetText("📝 " + truncateRunes(text, 30))
+}
+
+// treeNode 树形视图的节点组件，大纲模式下标题可以拖动并提供右键菜单
+type treeNode struct {
+	widget.BaseWidget
+	label *widget.Label
+	tree  *TreeView
+	id    string
+	dragY float32
+}
+
+// newTreeNode 创建树形视图的节点组件
+func newTreeNode(tree *TreeView) *treeNode {
+	node := &treeNode{label: widget.NewLabel(""), tree: tree}
+	node.ExtendBaseWidget(node)
+	return node
+}
+
+// CreateRenderer 创建节点的渲染器
+func (n *treeNode) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(n.label)
+}
+
+// Dragged 记录拖动距离，拖动时高亮节点
+func (n *treeNode) Dragged(e *fyne.DragEvent) {
+	if !n.tree.outlineMode {
+		return
+	}
+	n.dragY += e.Dragged.DY
+	if n.label.Importance != widget.HighImportance {
+		n.label.Importance = widget.HighImportance
+		n.label.Refresh()
+	}
+}
+
+// DragEnd 按拖动经过的行数移动章节
+func (n *treeNode) DragEnd() {
+	rowHeight := n.Size().Height + theme.Padding()
+	rows := 0
+	if rowHeight > 0 {
+		rows = int(math.Round(float64(n.dragY / rowHeight)))
+	}
+	n.dragY = 0
+	n.label.Importance = widget.MediumImportance
+	n.label.Refresh()
+	n.tree.dropHeading(n.id, rows)
+}
+
+// TappedSecondary 显示大纲操作菜单
+func (n *treeNode) TappedSecondary(e *fyne.PointEvent) {
+	n.tree.showOutlineMenu(n.id, fyne.CurrentApp().Driver().CanvasForObject(n), e.AbsolutePosition)
+}
@@ -0,0 +1,408 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// charTwips 缩进按字符换算时一个字符的宽度，按五号字（10.5磅）计算，单位为缇
+const charTwips = 210
+
+// alignmentOptions 对齐方式选项
+var alignmentOptions = []struct {
+	name  string
+	value document.Alignment
+}{
+	{"左对齐", document.AlignLeft}, {"居中", document.AlignCenter}, {"右对齐", document.AlignRight},
+	{"两端对齐", document.AlignJustify}, {"分散对齐", document.AlignDistribute},
+}
+
+// 首行缩进选项
+const (
+	specialNone      = "无"
+	specialFirstLine = "首行缩进"
+	specialHanging   = "悬挂缩进"
+)
+
+// 行距选项
+const (
+	lineSingle   = "单倍行距"
+	lineOneHalf  = "1.5倍行距"
+	lineDouble   = "2倍行距"
+	lineMultiple = "多倍行距"
+	lineAtLeast  = "最小值"
+	lineExact    = "固定值"
+)
+
+// borderOptions 边框选项
+var borderOptions = []struct {
+	name  string
+	sides document.BorderSide
+}{
+	{"无", 0}, {"方框", document.BorderBox}, {"上框线", document.BorderTop}, {"下框线", document.BorderBottom},
+	{"上下框线", document.BorderTop | document.BorderBottom},
+}
+
+// shadingOptions 底纹选项
+var shadingOptions = []struct {
+	name  string
+	value string
+}{
+	{"无", ""}, {"浅灰", "D9D9D9"}, {"浅黄", "FFF2CC"}, {"浅蓝", "DEEAF6"}, {"浅绿", "E2EFD9"}, {"浅橙", "FBE4D5"},
+}
+
+// paragraphFormatForm 段落格式的输入控件
+type paragraphFormatForm struct {
+	initial     document.ParagraphFormat
+	initialText map[fyne.CanvasObject]string // 特殊格式和行距控件的初始显示，用于判断是否修改
+
+	alignment   *widget.Select
+	leftIndent  *widget.Entry
+	rightIndent *widget.Entry
+	special     *widget.Select
+	specialBy   *widget.Entry
+	before      *widget.Entry
+	after       *widget.Entry
+	lineRule    *widget.Select
+	lineAt      *widget.Entry
+	keepNext    *widget.Check
+	keepLines   *widget.Check
+	pageBreak   *widget.Check
+	border      *widget.Select
+	shading     *widget.Select
+}
+
+// newParagraphFormatForm 创建段落格式的输入控件，初始值为段落当前的格式
+func newParagraphFormatForm(format document.ParagraphFormat) *paragraphFormatForm {
+	f := &paragraphFormatForm{initial: format}
+
+	var names []string
+	for _, a := range alignmentOptions {
+		names = append(names, a.name)
+	}
+	f.alignment = widget.NewSelect(names, nil)
+	f.alignment.SetSelectedIndex(0)
+	for i, a := range alignmentOptions {
+		if a.value == format.Alignment {
+			f.alignment.SetSelectedIndex(i)
+		}
+	}
+
+	f.leftIndent = numberEntry(float64(format.LeftIndent) / charTwips)
+	f.rightIndent = numberEntry(float64(format.RightIndent) / charTwips)
+	f.special = widget.NewSelect([]string{specialNone, specialFirstLine, specialHanging}, nil)
+	chars := float64(format.FirstLineChars) / 100
+	if format.FirstLineChars == 0 {
+		chars = float64(format.FirstLine) / charTwips
+	}
+	switch {
+	case chars > 0:
+		f.special.SetSelected(specialFirstLine)
+	case chars < 0:
+		f.special.SetSelected(specialHanging)
+		chars = -chars
+	default:
+		f.special.SetSelected(specialNone)
+	}
+	f.specialBy = numberEntry(chars)
+	if chars == 0 {
+		f.specialBy.SetText("2")
+	}
+
+	f.before = numberEntry(float64(format.SpaceBefore) / 20)
+	f.after = numberEntry(float64(format.SpaceAfter) / 20)
+	f.lineRule = widget.NewSelect([]string{lineSingle, lineOneHalf, lineDouble, lineMultiple, lineAtLeast, lineExact}, nil)
+	f.lineAt = widget.NewEntry()
+	switch {
+	case format.LineSpacing == 0 || format.LineRule == document.LineAuto && format.LineSpacing == 240:
+		f.lineRule.SetSelected(lineSingle)
+	case format.LineRule == document.LineAuto && format.LineSpacing == 360:
+		f.lineRule.SetSelected(lineOneHalf)
+	case format.LineRule == document.LineAuto && format.LineSpacing == 480:
+		f.lineRule.SetSelected(lineDouble)
+	case format.LineRule == document.LineAuto:
+		f.lineRule.SetSelected(lineMultiple)
+		f.lineAt.SetText(formatNumber(float64(format.LineSpacing) / 240))
+	case format.LineRule == document.LineAtLeast:
+		f.lineRule.SetSelected(lineAtLeast)
+		f.lineAt.SetText(formatNumber(float64(format.LineSpacing) / 20))
+	default:
+		f.lineRule.SetSelected(lineExact)
+		f.lineAt.SetText(formatNumber(float64(format.LineSpacing) / 20))
+	}
+
+	f.initialText = map[fyne.CanvasObject]string{
+		f.special: f.special.Selected, f.specialBy: f.specialBy.Text,
+		f.lineRule: f.lineRule.Selected, f.lineAt: f.lineAt.Text,
+	}
+
+	f.keepNext = widget.NewCheck("与下段同页", nil)
+	f.keepNext.SetChecked(format.KeepNext)
+	f.keepLines = widget.NewCheck("段中不分页", nil)
+	f.keepLines.SetChecked(format.KeepLines)
+	f.pageBreak = widget.NewCheck("段前分页", nil)
+	f.pageBreak.SetChecked(format.PageBreakBefore)
+
+	names = nil
+	for _, b := range borderOptions {
+		names = append(names, b.name)
+	}
+	f.border = widget.NewSelect(names, nil)
+	f.border.SetSelectedIndex(0)
+	for i, b := range borderOptions {
+		if b.sides == format.Border.Sides {
+			f.border.SetSelectedIndex(i)
+		}
+	}
+	if f.border.SelectedIndex() == 0 && format.Border.Sides != 0 {
+		// 其他组合的边框保持不变
+		f.border.Options = append(f.border.Options, "自定义")
+		f.border.SetSelected("自定义")
+	}
+
+	names = nil
+	for _, s := range shadingOptions {
+		names = append(names, s.name)
+	}
+	f.shading = widget.NewSelect(names, nil)
+	f.shading.SetSelectedIndex(0)
+	for i, s := range shadingOptions {
+		if strings.EqualFold(s.value, format.Shading) {
+			f.shading.SetSelectedIndex(i)
+		}
+	}
+	if f.shading.SelectedIndex() == 0 && format.Shading != "" {
+		f.shading.Options = append(f.shading.Options, "#"+format.Shading)
+		f.shading.SetSelected("#" + format.Shading)
+	}
+	return f
+}
+
+// numberEntry 创建显示数值的输入框
+func numberEntry(value float64) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.SetText(formatNumber(value))
+	return entry
+}
+
+// formatNumber 格式化数值，最多保留两位小数
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// parseNumber 解析输入框中的非负数值，空表示0
+func parseNumber(entry *widget.Entry, name string) (float64, error) {
+	text := strings.TrimSpace(entry.Text)
+	if text == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("无效的%s: %s", name, text)
+	}
+	return value, nil
+}
+
+// edited 判断输入框中的数值是否与初始值的显示不同
+func edited(entry *widget.Entry, initial float64) bool {
+	return strings.TrimSpace(entry.Text) != formatNumber(initial)
+}
+
+// items 返回表单项
+func (f *paragraphFormatForm) items() []*widget.FormItem {
+	return []*widget.FormItem{
+		widget.NewFormItem("对齐方式", f.alignment),
+		widget.NewFormItem("左缩进(字符)", f.leftIndent),
+		widget.NewFormItem("右缩进(字符)", f.rightIndent),
+		widget.NewFormItem("特殊格式", container.NewGridWithColumns(2, f.special, f.specialBy)),
+		widget.NewFormItem("段前(磅)", f.before),
+		widget.NewFormItem("段后(磅)", f.after),
+		widget.NewFormItem("行距", container.NewGridWithColumns(2, f.lineRule, f.lineAt)),
+		widget.NewFormItem("换行和分页", container.NewHBox(f.keepNext, f.keepLines, f.pageBreak)),
+		widget.NewFormItem("边框", f.border),
+		widget.NewFormItem("底纹", f.shading),
+	}
+}
+
+// format 返回输入的段落格式和与初始格式相比修改了的属性
+func (f *paragraphFormatForm) format() (document.ParagraphFormat, document.ParaProperty, error) {
+	format := f.initial
+	var props document.ParaProperty
+
+	initialAlignment := f.initial.Alignment
+	if initialAlignment == "" {
+		initialAlignment = document.AlignLeft
+	}
+	if i := f.alignment.SelectedIndex(); i >= 0 && alignmentOptions[i].value != initialAlignment {
+		format.Alignment = alignmentOptions[i].value
+		props |= document.ParaAlignment
+	}
+
+	left, err := parseNumber(f.leftIndent, "左缩进")
+	if err != nil {
+		return format, 0, err
+	}
+	right, err := parseNumber(f.rightIndent, "右缩进")
+	if err != nil {
+		return format, 0, err
+	}
+	if edited(f.leftIndent, float64(f.initial.LeftIndent)/charTwips) ||
+		edited(f.rightIndent, float64(f.initial.RightIndent)/charTwips) {
+		format.LeftIndent = int(left*charTwips + 0.5)
+		format.RightIndent = int(right*charTwips + 0.5)
+		props |= document.ParaIndent
+	}
+
+	by, err := parseNumber(f.specialBy, "缩进值")
+	if err != nil {
+		return format, 0, err
+	}
+	format.FirstLine = 0
+	format.FirstLineChars = 0
+	switch f.special.Selected {
+	case specialFirstLine:
+		format.FirstLineChars = int(by*100 + 0.5)
+	case specialHanging:
+		format.FirstLineChars = -int(by*100 + 0.5)
+	}
+	if f.special.Selected != f.initialText[f.special] || f.specialBy.Text != f.initialText[f.specialBy] {
+		props |= document.ParaFirstLine
+	} else {
+		format.FirstLine = f.initial.FirstLine
+		format.FirstLineChars = f.initial.FirstLineChars
+	}
+
+	before, err := parseNumber(f.before, "段前间距")
+	if err != nil {
+		return format, 0, err
+	}
+	after, err := parseNumber(f.after, "段后间距")
+	if err != nil {
+		return format, 0, err
+	}
+	if edited(f.before, float64(f.initial.SpaceBefore)/20) || edited(f.after, float64(f.initial.SpaceAfter)/20) {
+		format.SpaceBefore = int(before*20 + 0.5)
+		format.SpaceAfter = int(after*20 + 0.5)
+		props |= document.ParaSpacing
+	}
+
+	format.LineRule = document.LineAuto
+	switch f.lineRule.Selected {
+	case lineSingle:
+		format.LineSpacing = 240
+	case lineOneHalf:
+		format.LineSpacing = 360
+	case lineDouble:
+		format.LineSpacing = 480
+	default:
+		value, err := parseNumber(f.lineAt, "行距")
+		if err != nil {
+			return format, 0, err
+		}
+		if value == 0 {
+			return format, 0, fmt.Errorf("请输入%s的值", f.lineRule.Selected)
+		}
+		switch f.lineRule.Selected {
+		case lineMultiple:
+			format.LineSpacing = int(value*240 + 0.5)
+		case lineAtLeast:
+			format.LineRule = document.LineAtLeast
+			format.LineSpacing = int(value*20 + 0.5)
+		default:
+			format.LineRule = document.LineExact
+			format.LineSpacing = int(value*20 + 0.5)
+		}
+	}
+	if f.lineRule.Selected != f.initialText[f.lineRule] || f.lineAt.Text != f.initialText[f.lineAt] {
+		props |= document.ParaLineSpacing
+	} else {
+		format.LineRule = f.initial.LineRule
+		format.LineSpacing = f.initial.LineSpacing
+	}
+
+	format.KeepNext = f.keepNext.Checked
+	format.KeepLines = f.keepLines.Checked
+	format.PageBreakBefore = f.pageBreak.Checked
+	if format.KeepNext != f.initial.KeepNext {
+		props |= document.ParaKeepNext
+	}
+	if format.KeepLines != f.initial.KeepLines {
+		props |= document.ParaKeepLines
+	}
+	if format.PageBreakBefore != f.initial.PageBreakBefore {
+		props |= document.ParaPageBreakBefore
+	}
+
+	if i := f.border.SelectedIndex(); i >= 0 && i < len(borderOptions) && borderOptions[i].sides != f.initial.Border.Sides {
+		format.Border = document.ParagraphBorder{Sides: borderOptions[i].sides}
+		props |= document.ParaBorder
+	}
+	if i := f.shading.SelectedIndex(); i >= 0 && i < len(shadingOptions) && !strings.EqualFold(shadingOptions[i].value, f.initial.Shading) {
+		format.Shading = shadingOptions[i].value
+		props |= document.ParaShading
+	}
+	return format, props, nil
+}
+
+// apply 将修改的段落格式写入段落
+func (f *paragraphFormatForm) apply(doc *document.Document, paragraph int) error {
+	format, props, err := f.format()
+	if err != nil {
+		return err
+	}
+	if props == 0 {
+		return nil
+	}
+	doc.Checkpoint("段落格式")
+	return doc.SetParagraphFormat(paragraph, paragraph, format, props)
+}
+
+// createParagraphFormat 创建段落详情中的段落格式面板
+func (gcv *ContentView) createParagraphFormat(doc *document.Document, index int) []fyne.CanvasObject {
+	format, err := doc.ParagraphFormat(index)
+	if err != nil {
+		return []fyne.CanvasObject{widget.NewLabel(fmt.Sprintf("无法读取段落格式: %v", err))}
+	}
+	f := newParagraphFormatForm(format)
+	form := widget.NewForm(f.items()...)
+	form.SubmitText = "应用"
+	form.OnSubmit = func() {
+		gcv.afterChange(f.apply(doc, index), fmt.Sprintf("p%d", index+1))
+	}
+	return []fyne.CanvasObject{
+		widget.NewAccordion(widget.NewAccordionItem("段落格式", form)),
+	}
+}
+
+// ShowParagraphFormatDialog 显示段落格式对话框，应用后调用 onApplied
+func ShowParagraphFormatDialog(doc *document.Document, paragraph int, window fyne.Window, onApplied func()) {
+	format, err := doc.ParagraphFormat(paragraph)
+	if err != nil {
+		dialog.ShowError(err, window)
+		return
+	}
+	f := newParagraphFormatForm(format)
+	d := dialog.NewForm(fmt.Sprintf("段落 %d 格式", paragraph+1), "应用", "取消", f.items(), func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if err := f.apply(doc, paragraph); err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		if onApplied != nil {
+			onApplied()
+		}
+	}, window)
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+}
@@ -0,0 +1,261 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	fynelayout "fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+	"github.com/tanqiangyes/fyne-word/pkg/layout"
+)
+
+// pointScale 缩放为100%时每磅对应的界面单位
+const pointScale = 96.0 / 72.0
+
+// zoomLevels 页面视图的缩放比例
+var zoomLevels = []string{"50%", "75%", "100%", "125%", "150%", "200%"}
+
+// measureKey 文字测量缓存的键
+type measureKey struct {
+	text string
+	font layout.Font
+}
+
+// textMeasurer 使用界面字体测量文字宽度，使页面视图的分行与显示一致
+type textMeasurer struct {
+	mu    sync.Mutex
+	cache map[measureKey]float64
+}
+
+// MeasureText 测量文字宽度，字号按界面单位计算后与磅成比例
+func (m *textMeasurer) MeasureText(text string, font layout.Font) float64 {
+	key := measureKey{text, font}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if width, ok := m.cache[key]; ok {
+		return width
+	}
+	size := fyne.MeasureText(text, float32(font.Size), fyne.TextStyle{Bold: font.Bold, Italic: font.Italic})
+	if len(m.cache) > 100000 {
+		m.cache = make(map[measureKey]float64)
+	}
+	m.cache[key] = float64(size.Width)
+	return float64(size.Width)
+}
+
+// NewLayoutEngine 创建使用界面字体测量文字的排版引擎，页面视图、页数统计和目录页码共用
+func NewLayoutEngine() *layout.Engine {
+	return layout.New(&textMeasurer{cache: make(map[measureKey]float64)})
+}
+
+// PagePreview 只读的页面视图，按排版引擎的结果分页显示文档
+type PagePreview struct {
+	doc    *document.Document
+	engine *layout.Engine
+	result *layout.Result
+	zoom   float64
+
+	pages  *fyne.Container
+	scroll *container.Scroll
+	info   *widget.Label
+	zoomBy *widget.Select
+	images *imageCache
+}
+
+// NewPagePreview 创建文档的页面视图
+func NewPagePreview(doc *document.Document, engine *layout.Engine) *PagePreview {
+	p := &PagePreview{
+		doc:    doc,
+		engine: engine,
+		zoom:   1,
+		pages:  container.NewVBox(),
+		info:   widget.NewLabel(""),
+		images: newImageCache(doc),
+	}
+	p.scroll = container.NewScroll(p.pages)
+	p.zoomBy = widget.NewSelect(zoomLevels, func(value string) {
+		percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+		if err != nil || float64(percent)/100 == p.zoom {
+			return
+		}
+		p.zoom = float64(percent) / 100
+		p.render()
+	})
+	p.zoomBy.SetSelected("100%")
+	return p
+}
+
+// Content 返回页面视图的界面，顶部是 actions、缩放比例和页数
+func (p *PagePreview) Content(actions ...fyne.CanvasObject) fyne.CanvasObject {
+	toolbar := container.NewHBox(append(actions, widget.NewLabel("缩放:"), p.zoomBy, p.info)...)
+	return container.NewBorder(toolbar, nil, nil, nil, p.scroll)
+}
+
+// Result 返回最近一次排版的结果
+func (p *PagePreview) Result() *layout.Result {
+	return p.result
+}
+
+// Refresh 重新排版并显示文档
+func (p *PagePreview) Refresh() error {
+	result, err := p.engine.Layout(p.doc)
+	if err != nil {
+		return fmt.Errorf("排版失败: %v", err)
+	}
+	p.result = result
+	p.render()
+	return nil
+}
+
+// ShowPage 滚动到指定页
+func (p *PagePreview) ShowPage(number int) {
+	if p.result == nil || number < 1 || number > len(p.result.Pages) {
+		return
+	}
+	offset := float32(0)
+	for _, page := range p.result.Pages[:number-1] {
+		offset += float32(page.Height*p.zoom*pointScale) + theme.Padding()
+	}
+	p.scroll.Offset = fyne.NewPos(p.scroll.Offset.X, offset)
+	p.scroll.Refresh()
+}
+
+// render 按当前缩放比例创建所有页面
+func (p *PagePreview) render() {
+	if p.result == nil {
+		return
+	}
+	objects := make([]fyne.CanvasObject, 0, len(p.result.Pages))
+	for _, page := range p.result.Pages {
+		objects = append(objects, container.NewCenter(p.pageObject(page)))
+	}
+	p.pages.Objects = objects
+	p.pages.Refresh()
+	p.info.SetText(fmt.Sprintf("共 %d 页", p.result.PageCount()))
+}
+
+// pageObject 创建一页的显示内容
+func (p *PagePreview) pageObject(page *layout.Page) fyne.CanvasObject {
+	scale := p.zoom * pointScale
+	size := fyne.NewSize(float32(page.Width*scale), float32(page.Height*scale))
+
+	background := canvas.NewRectangle(color.White)
+	background.StrokeColor = color.Gray{Y: 0x99}
+	background.StrokeWidth = 1
+	background.Resize(size)
+	objects := append([]fyne.CanvasObject{background}, pageContent(page, scale, p.images)...)
+	return container.New(fynelayout.NewGridWrapLayout(size), container.NewWithoutLayout(objects...))
+}
+
+// imageCache 图片部件内容的缓存
+type imageCache struct {
+	doc  *document.Document
+	data map[string][]byte
+}
+
+// newImageCache 创建文档的图片缓存
+func newImageCache(doc *document.Document) *imageCache {
+	return &imageCache{doc: doc, data: make(map[string][]byte)}
+}
+
+// get 返回图片部件的内容
+func (c *imageCache) get(part string) []byte {
+	if part == "" || c.doc.Package == nil {
+		return nil
+	}
+	if data, ok := c.data[part]; ok {
+		return data
+	}
+	data, _ := c.doc.Package.Part(part)
+	c.data[part] = data
+	return data
+}
+
+// pageContent 按缩放比例创建页面中的矩形、文字和图片，坐标相对于页面左上角
+func pageContent(page *layout.Page, scale float64, images *imageCache) []fyne.CanvasObject {
+	at := func(value float64) float32 {
+		return float32(value * scale)
+	}
+	var objects []fyne.CanvasObject
+	for _, box := range page.Boxes {
+		rect := canvas.NewRectangle(color.Transparent)
+		if fill := hexColor(box.Fill); fill != nil {
+			rect.FillColor = fill
+		}
+		if stroke := hexColor(box.Stroke); stroke != nil {
+			rect.StrokeColor = stroke
+			rect.StrokeWidth = float32(box.StrokeWidth * scale)
+		}
+		rect.Move(fyne.NewPos(at(box.X), at(box.Y)))
+		rect.Resize(fyne.NewSize(at(box.Width), at(box.Height)))
+		objects = append(objects, rect)
+	}
+
+	driver := fyne.CurrentApp().Driver()
+	for _, line := range page.Lines {
+		baseline := at(line.Y + line.Baseline)
+		for _, fragment := range line.Fragments {
+			x := at(line.X + fragment.X)
+			if fragment.Image != nil {
+				objects = append(objects, imageObject(images.get(fragment.Image.Part), fragment.Image.Part,
+					fyne.NewPos(x, baseline-at(fragment.Height)), fyne.NewSize(at(fragment.Width), at(fragment.Height))))
+				continue
+			}
+			if fragment.Text == "" {
+				continue
+			}
+			if highlight := highlightColor(fragment.Style.Highlight); highlight != nil {
+				rect := canvas.NewRectangle(highlight)
+				rect.Move(fyne.NewPos(x, at(line.Y)))
+				rect.Resize(fyne.NewSize(at(fragment.Width), at(line.Height)))
+				objects = append(objects, rect)
+			}
+			fg := hexColor(fragment.Style.Color)
+			if fg == nil {
+				fg = color.Black
+			}
+			text := canvas.NewText(fragment.Text, fg)
+			text.TextSize = at(fragment.Style.Font.Size)
+			text.TextStyle = fyne.TextStyle{Bold: fragment.Style.Font.Bold, Italic: fragment.Style.Font.Italic}
+			textSize, ascent := driver.RenderedTextSize(fragment.Text, text.TextSize, text.TextStyle, nil)
+			text.Move(fyne.NewPos(x, baseline-ascent))
+			text.Resize(textSize)
+			objects = append(objects, text)
+			if fragment.Style.Underline {
+				objects = append(objects, decorationLine(fg, x, baseline+at(1), at(fragment.Width)))
+			}
+			if fragment.Style.Strike {
+				objects = append(objects, decorationLine(fg, x, baseline-at(fragment.Style.Font.Size*0.3), at(fragment.Width)))
+			}
+		}
+	}
+	return objects
+}
+
+// imageObject 创建图片，没有图片内容时显示占位框
+func imageObject(data []byte, name string, pos fyne.Position, size fyne.Size) fyne.CanvasObject {
+	var object fyne.CanvasObject
+	if data != nil {
+		img := canvas.NewImageFromReader(bytes.NewReader(data), name)
+		img.FillMode = canvas.ImageFillStretch
+		object = img
+	} else {
+		rect := canvas.NewRectangle(color.Gray{Y: 0xe0})
+		rect.StrokeColor = color.Gray{Y: 0x99}
+		rect.StrokeWidth = 1
+		object = rect
+	}
+	object.Move(pos)
+	object.Resize(size)
+	return object
+}
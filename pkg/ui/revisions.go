@@ -0,0 +1,239 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// revisionSummary 返回修订在树形视图和列表中显示的摘要
+func revisionSummary(rev document.Revision) string {
+	author := rev.Author
+	if author == "" {
+		author = "未知作者"
+	}
+	return fmt.Sprintf("[%s] %s: %s", rev.Type, author, truncateRunes(rev.Text, 20))
+}
+
+// revisionDetail 返回修订的作者、时间和位置
+func revisionDetail(rev document.Revision) string {
+	detail := "作者: " + rev.Author
+	if !rev.Date.IsZero() {
+		detail += "  时间: " + rev.Date.Local().Format("2006-01-02 15:04")
+	}
+	if rev.Paragraph >= 0 {
+		detail += fmt.Sprintf("  位置: 段落 %d", rev.Paragraph+1)
+	} else {
+		detail += "  位置: 表格"
+	}
+	return detail
+}
+
+// revisionSegment 按修订类型显示受影响的文字：插入加下划线，删除加删除线
+func revisionSegment(rev document.Revision) StyledSegment {
+	segment := StyledSegment{Text: rev.Text}
+	if segment.Text == "" {
+		segment.Text = "¶"
+	}
+	switch rev.Type {
+	case document.RevisionInsert, document.RevisionMoveTo, document.RevisionParagraphInsert:
+		segment.Underline = true
+		segment.Color = theme.Color(theme.ColorNamePrimary)
+	case document.RevisionDelete, document.RevisionMoveFrom, document.RevisionParagraphDelete:
+		segment.Strike = true
+		segment.Color = theme.Color(theme.ColorNameError)
+	default:
+		segment.Color = theme.Color(theme.ColorNameWarning)
+	}
+	return segment
+}
+
+// spanSegments 将文档的格式片段转换为显示片段，修订内容使用修订标记显示，注释编号使用主题色
+func spanSegments(spans []document.TextSpan) []StyledSegment {
+	segments := make([]StyledSegment, 0, len(spans))
+	for _, span := range spans {
+		segment := StyledSegment{
+			Text:      span.Text,
+			Bold:      span.Bold,
+			Italic:    span.Italic,
+			Underline: span.Underline,
+			Strike:    span.Strike,
+			Color:     hexColor(span.Color),
+			Highlight: highlightColor(span.Highlight),
+		}
+		if span.Inserted {
+			segment.Underline = true
+			segment.Color = theme.Color(theme.ColorNamePrimary)
+		}
+		if span.Deleted {
+			segment.Strike = true
+			segment.Color = theme.Color(theme.ColorNameError)
+		}
+		if span.NoteID != "" {
+			segment.Color = theme.Color(theme.ColorNamePrimary)
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// hexColor 解析 RRGGBB 格式的颜色，无效时返回nil
+func hexColor(hex string) color.Color {
+	if len(hex) != 6 {
+		return nil
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil
+	}
+	return color.NRGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 0xff}
+}
+
+// highlightColors Word突出显示颜色名称对应的颜色
+var highlightColors = map[string]string{
+	"yellow": "FFFF00", "green": "00FF00", "cyan": "00FFFF", "magenta": "FF00FF",
+	"blue": "0000FF", "red": "FF0000", "darkBlue": "000080", "darkCyan": "008080",
+	"darkGreen": "008000", "darkMagenta": "800080", "darkRed": "800000", "darkYellow": "808000",
+	"darkGray": "808080", "lightGray": "C0C0C0", "black": "000000", "white": "FFFFFF",
+}
+
+// highlightColor 返回突出显示颜色名称对应的颜色，无效时返回nil
+func highlightColor(name string) color.Color {
+	return hexColor(highlightColors[name])
+}
+
+// truncateRunes 按字符截断文本
+func truncateRunes(text string, maxLen int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+	return string(runes[:maxLen]) + "..."
+}
+
+// createRevisionsView 创建修订列表视图，支持逐条、按作者或全部接受和拒绝
+func (gcv *ContentView) createRevisionsView(doc *document.Document) []fyne.CanvasObject {
+	var widgets []fyne.CanvasObject
+
+	widgets = append(widgets, widget.NewLabel("修订"))
+	widgets = append(widgets, widget.NewSeparator())
+
+	revisions, err := doc.Revisions()
+	if err != nil {
+		widgets = append(widgets, widget.NewLabel(err.Error()))
+		return widgets
+	}
+	if len(revisions) == 0 {
+		widgets = append(widgets, widget.NewLabel("文档中没有修订"))
+		return widgets
+	}
+
+	acceptAll := widget.NewButton("全部接受", func() {
+		gcv.confirmRevisions(doc, "接受所有修订", doc.AcceptAllRevisions)
+	})
+	rejectAll := widget.NewButton("全部拒绝", func() {
+		gcv.confirmRevisions(doc, "拒绝所有修订", doc.RejectAllRevisions)
+	})
+	widgets = append(widgets, container.NewHBox(acceptAll, rejectAll))
+
+	authors, _ := doc.RevisionAuthors()
+	authorSelect := widget.NewSelect(authors, nil)
+	authorSelect.PlaceHolder = "选择作者"
+	if len(authors) > 0 {
+		authorSelect.SetSelected(authors[0])
+	}
+	acceptAuthor := widget.NewButton("接受该作者的修订", func() {
+		author := authorSelect.Selected
+		gcv.confirmRevisions(doc, fmt.Sprintf("接受 %s 的所有修订", author), func() (int, error) {
+			return doc.AcceptRevisionsByAuthor(author)
+		})
+	})
+	rejectAuthor := widget.NewButton("拒绝该作者的修订", func() {
+		author := authorSelect.Selected
+		gcv.confirmRevisions(doc, fmt.Sprintf("拒绝 %s 的所有修订", author), func() (int, error) {
+			return doc.RejectRevisionsByAuthor(author)
+		})
+	})
+	widgets = append(widgets, container.NewHBox(authorSelect, acceptAuthor, rejectAuthor))
+	widgets = append(widgets, widget.NewSeparator())
+
+	for _, rev := range revisions {
+		header := widget.NewLabel(fmt.Sprintf("[%s] %s", rev.Type, revisionDetail(rev)))
+		widgets = append(widgets, container.NewBorder(nil, nil, nil,
+			gcv.revisionActions(doc, rev), header))
+		widgets = append(widgets, NewStyledText(revisionSegment(rev)))
+		widgets = append(widgets, widget.NewSeparator())
+	}
+
+	return widgets
+}
+
+// createRevisionDetailView 创建单个修订的详细视图，显示修订所在段落的修订标记
+func (gcv *ContentView) createRevisionDetailView(doc *document.Document, index int) []fyne.CanvasObject {
+	var widgets []fyne.CanvasObject
+
+	revisions, err := doc.Revisions()
+	if err != nil || index >= len(revisions) {
+		return widgets
+	}
+	rev := revisions[index]
+
+	widgets = append(widgets, widget.NewLabel(fmt.Sprintf("修订 %d: %s", index+1, rev.Type)))
+	widgets = append(widgets, widget.NewSeparator())
+	widgets = append(widgets, widget.NewLabel(revisionDetail(rev)))
+	widgets = append(widgets, NewStyledText(revisionSegment(rev)))
+	widgets = append(widgets, gcv.revisionActions(doc, rev))
+
+	if rev.Paragraph >= 0 {
+		if spans, err := doc.ParagraphSpans(rev.Paragraph); err == nil {
+			widgets = append(widgets, widget.NewSeparator())
+			widgets = append(widgets, widget.NewLabel(fmt.Sprintf("段落 %d", rev.Paragraph+1)))
+			widgets = append(widgets, NewStyledText(spanSegments(spans)...))
+		}
+	}
+
+	return widgets
+}
+
+// revisionActions 创建单个修订的接受和拒绝按钮
+func (gcv *ContentView) revisionActions(doc *document.Document, rev document.Revision) fyne.CanvasObject {
+	accept := widget.NewButtonWithIcon("接受", theme.ConfirmIcon(), func() {
+		doc.Checkpoint("接受修订")
+		gcv.afterChange(doc.AcceptRevision(rev), "revisions")
+	})
+	reject := widget.NewButtonWithIcon("拒绝", theme.CancelIcon(), func() {
+		doc.Checkpoint("拒绝修订")
+		gcv.afterChange(doc.RejectRevision(rev), "revisions")
+	})
+	return container.NewHBox(accept, reject)
+}
+
+// confirmRevisions 确认后批量处理修订
+func (gcv *ContentView) confirmRevisions(doc *document.Document, title string, resolve func() (int, error)) {
+	run := func() {
+		doc.Checkpoint(title)
+		count, err := resolve()
+		if err == nil && count == 0 {
+			return
+		}
+		// 修订编号已变化，回到修订列表
+		gcv.afterChange(err, "revisions")
+	}
+	if gcv.window == nil {
+		run()
+		return
+	}
+	dialog.ShowConfirm(title, "确定"+title+"吗？", func(ok bool) {
+		if ok {
+			run()
+		}
+	}, gcv.window)
+}
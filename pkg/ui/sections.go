@@ -0,0 +1,286 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// twipsPerCm 每厘米的缇数
+const twipsPerCm = 1440 / 2.54
+
+// customPaper 自定义纸张大小选项
+const customPaper = "自定义"
+
+// sectionOrientation 返回节的纸张方向名称
+func sectionOrientation(section document.Section) string {
+	if section.Landscape {
+		return "横向"
+	}
+	return "纵向"
+}
+
+// sectionSummary 返回节在树形视图中显示的摘要
+func sectionSummary(section document.Section) string {
+	return fmt.Sprintf("第 %d 节: %s %s，%s", section.Index+1,
+		document.PaperSizeName(section.Width, section.Height), sectionOrientation(section), section.Break)
+}
+
+// centimeters 将缇转换为厘米文本
+func centimeters(twips int) string {
+	return formatNumber(float64(twips) / twipsPerCm)
+}
+
+// createSectionsView 创建节列表视图
+func (gcv *ContentView) createSectionsView(doc *document.Document) []fyne.CanvasObject {
+	var widgets []fyne.CanvasObject
+
+	widgets = append(widgets, widget.NewLabel("节"))
+	widgets = append(widgets, widget.NewSeparator())
+
+	if doc.Package == nil {
+		widgets = append(widgets, widget.NewLabel("文档不支持分节"))
+		return widgets
+	}
+	sections, err := doc.Sections()
+	if err != nil {
+		widgets = append(widgets, widget.NewLabel(err.Error()))
+		return widgets
+	}
+	for _, section := range sections {
+		widgets = append(widgets, widget.NewLabel(fmt.Sprintf("%s（段落 %d-%d）",
+			sectionSummary(section), section.StartParagraph+1, section.EndParagraph+1)))
+	}
+	widgets = append(widgets, widget.NewSeparator())
+	widgets = append(widgets, widget.NewLabel("在段落详情或“插入”菜单中插入分节符"))
+	return widgets
+}
+
+// createSectionDetailView 创建节详细视图，显示页面设置并可以修改
+func (gcv *ContentView) createSectionDetailView(doc *document.Document, index int) []fyne.CanvasObject {
+	var widgets []fyne.CanvasObject
+
+	widgets = append(widgets, widget.NewLabel(fmt.Sprintf("第 %d 节", index+1)))
+	widgets = append(widgets, widget.NewSeparator())
+
+	sections, err := doc.Sections()
+	if err != nil || index >= len(sections) {
+		widgets = append(widgets, widget.NewLabel("无法读取节"))
+		return widgets
+	}
+	section := sections[index]
+	info := fmt.Sprintf("开始方式: %s\n段落: %d-%d\n纸张: %s %s（%s × %s 厘米）\n"+
+		"页边距: 上 %s 下 %s 左 %s 右 %s 厘米，装订线 %s 厘米\n页眉 %s 厘米，页脚 %s 厘米\n分栏: %d 栏，间距 %s 厘米",
+		section.Break, section.StartParagraph+1, section.EndParagraph+1,
+		document.PaperSizeName(section.Width, section.Height), sectionOrientation(section),
+		centimeters(section.Width), centimeters(section.Height),
+		centimeters(section.Top), centimeters(section.Bottom), centimeters(section.Left), centimeters(section.Right),
+		centimeters(section.Gutter), centimeters(section.Header), centimeters(section.Footer),
+		section.Columns, centimeters(section.ColumnSpace))
+	widgets = append(widgets, widget.NewLabel(info))
+
+	node := fmt.Sprintf("x%d", index+1)
+	setupBtn := widget.NewButtonWithIcon("页面设置", theme.DocumentIcon(), func() {
+		ShowPageSetupDialog(doc, index, gcv.window, func() {
+			gcv.afterChange(nil, node)
+		})
+	})
+	deleteBtn := widget.NewButtonWithIcon("删除分节符", theme.DeleteIcon(), func() {
+		doc.Checkpoint("删除分节符")
+		gcv.afterChange(doc.DeleteSectionBreak(index), "sections")
+	})
+	if index == len(sections)-1 {
+		deleteBtn.Disable()
+	}
+	widgets = append(widgets, container.NewHBox(setupBtn, deleteBtn))
+	return widgets
+}
+
+// pageSetupForm 页面设置的输入控件
+type pageSetupForm struct {
+	paper       *widget.Select
+	width       *widget.Entry
+	height      *widget.Entry
+	orientation *widget.RadioGroup
+	top         *widget.Entry
+	bottom      *widget.Entry
+	left        *widget.Entry
+	right       *widget.Entry
+	gutter      *widget.Entry
+	header      *widget.Entry
+	footer      *widget.Entry
+	columns     *widget.Select
+	columnSpace *widget.Entry
+	applyTo     *widget.Select
+	initial     document.PageSetup
+}
+
+// newPageSetupForm 创建页面设置的输入控件，初始值为节当前的设置
+func newPageSetupForm(setup document.PageSetup) *pageSetupForm {
+	f := &pageSetupForm{initial: setup}
+	cmEntry := func(twips int) *widget.Entry {
+		entry := widget.NewEntry()
+		entry.SetText(centimeters(twips))
+		return entry
+	}
+	f.width = cmEntry(setup.Width)
+	f.height = cmEntry(setup.Height)
+	f.top = cmEntry(setup.Top)
+	f.bottom = cmEntry(setup.Bottom)
+	f.left = cmEntry(setup.Left)
+	f.right = cmEntry(setup.Right)
+	f.gutter = cmEntry(setup.Gutter)
+	f.header = cmEntry(setup.Header)
+	f.footer = cmEntry(setup.Footer)
+	f.columnSpace = cmEntry(setup.ColumnSpace)
+
+	f.orientation = widget.NewRadioGroup([]string{"纵向", "横向"}, nil)
+	f.orientation.Horizontal = true
+	f.orientation.Required = true
+	if setup.Landscape {
+		f.orientation.SetSelected("横向")
+	} else {
+		f.orientation.SetSelected("纵向")
+	}
+	// 切换方向时交换宽度和高度
+	f.orientation.OnChanged = func(value string) {
+		width, _ := strconv.ParseFloat(f.width.Text, 64)
+		height, _ := strconv.ParseFloat(f.height.Text, 64)
+		if (value == "横向") != (width > height) {
+			f.width.SetText(f.height.Text)
+			f.height.SetText(formatNumber(width))
+		}
+	}
+
+	var papers []string
+	for _, size := range document.PaperSizes {
+		papers = append(papers, size.Name)
+	}
+	papers = append(papers, customPaper)
+	f.paper = widget.NewSelect(papers, nil)
+	f.paper.SetSelected(document.PaperSizeName(setup.Width, setup.Height))
+	f.paper.OnChanged = func(name string) {
+		for _, size := range document.PaperSizes {
+			if size.Name != name {
+				continue
+			}
+			width, height := size.Width, size.Height
+			if f.orientation.Selected == "横向" {
+				width, height = height, width
+			}
+			f.width.SetText(centimeters(width))
+			f.height.SetText(centimeters(height))
+		}
+	}
+
+	f.columns = widget.NewSelect([]string{"1", "2", "3", "4", "5", "6"}, nil)
+	f.columns.SetSelected(strconv.Itoa(setup.Columns))
+	if f.columns.Selected == "" {
+		f.columns.Options = append(f.columns.Options, strconv.Itoa(setup.Columns))
+		f.columns.SetSelected(strconv.Itoa(setup.Columns))
+	}
+	f.applyTo = widget.NewSelect([]string{"本节", "整篇文档"}, nil)
+	f.applyTo.SetSelectedIndex(0)
+	return f
+}
+
+// items 返回表单项
+func (f *pageSetupForm) items() []*widget.FormItem {
+	pair := func(a, b fyne.CanvasObject) fyne.CanvasObject {
+		return container.NewGridWithColumns(2, a, b)
+	}
+	return []*widget.FormItem{
+		widget.NewFormItem("纸张大小", f.paper),
+		widget.NewFormItem("宽度/高度(厘米)", pair(f.width, f.height)),
+		widget.NewFormItem("方向", f.orientation),
+		widget.NewFormItem("上/下边距(厘米)", pair(f.top, f.bottom)),
+		widget.NewFormItem("左/右边距(厘米)", pair(f.left, f.right)),
+		widget.NewFormItem("装订线(厘米)", f.gutter),
+		widget.NewFormItem("页眉/页脚(厘米)", pair(f.header, f.footer)),
+		widget.NewFormItem("栏数/间距(厘米)", pair(f.columns, f.columnSpace)),
+		widget.NewFormItem("应用于", f.applyTo),
+	}
+}
+
+// setup 返回输入的页面设置，未修改的值保持原来的精度
+func (f *pageSetupForm) setup() (document.PageSetup, error) {
+	setup := f.initial
+	fields := []struct {
+		entry  *widget.Entry
+		name   string
+		target *int
+	}{
+		{f.width, "宽度", &setup.Width}, {f.height, "高度", &setup.Height},
+		{f.top, "上边距", &setup.Top}, {f.bottom, "下边距", &setup.Bottom},
+		{f.left, "左边距", &setup.Left}, {f.right, "右边距", &setup.Right},
+		{f.gutter, "装订线", &setup.Gutter}, {f.header, "页眉距离", &setup.Header},
+		{f.footer, "页脚距离", &setup.Footer}, {f.columnSpace, "栏间距", &setup.ColumnSpace},
+	}
+	for _, field := range fields {
+		if field.entry.Text == centimeters(*field.target) {
+			continue
+		}
+		value, err := parseNumber(field.entry, field.name)
+		if err != nil {
+			return setup, err
+		}
+		*field.target = int(value*twipsPerCm + 0.5)
+	}
+	setup.Landscape = f.orientation.Selected == "横向"
+	setup.Columns, _ = strconv.Atoi(f.columns.Selected)
+	return setup, nil
+}
+
+// ShowPageSetupDialog 显示页面设置对话框，应用后调用 onApplied
+func ShowPageSetupDialog(doc *document.Document, section int, window fyne.Window, onApplied func()) {
+	sections, err := doc.Sections()
+	if err != nil {
+		dialog.ShowError(err, window)
+		return
+	}
+	if section < 0 || section >= len(sections) {
+		dialog.ShowError(fmt.Errorf("节索引超出范围: %d", section+1), window)
+		return
+	}
+	f := newPageSetupForm(sections[section].PageSetup)
+	title := "页面设置"
+	if len(sections) > 1 {
+		title = fmt.Sprintf("页面设置（第 %d 节）", section+1)
+	}
+	d := dialog.NewForm(title, "确定", "取消", f.items(), func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		setup, err := f.setup()
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		targets := []int{section}
+		if f.applyTo.SelectedIndex() == 1 {
+			targets = targets[:0]
+			for i := range sections {
+				targets = append(targets, i)
+			}
+		}
+		doc.Checkpoint("页面设置")
+		for _, target := range targets {
+			if err := doc.SetPageSetup(target, setup); err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+		}
+		if onApplied != nil {
+			onApplied()
+		}
+	}, window)
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+}
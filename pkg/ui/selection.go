@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+	"github.com/tanqiangyes/fyne-word/pkg/layout"
+)
+
+// SetLayoutEngine 设置编辑器排版使用的引擎，未设置时创建使用界面字体的引擎
+func (gcv *ContentView) SetLayoutEngine(engine *layout.Engine) {
+	gcv.engine = engine
+}
+
+// createEditorView 创建正文编辑器，同一文档再次显示时保留光标位置
+func (gcv *ContentView) createEditorView(doc *document.Document) []fyne.CanvasObject {
+	if doc.ReadOnly {
+		return []fyne.CanvasObject{widget.NewLabel("此文档以只读方式打开，不能编辑")}
+	}
+	if doc.Package == nil {
+		return []fyne.CanvasObject{widget.NewLabel("此文档不支持编辑")}
+	}
+	if gcv.editor == nil || gcv.editor.Document() != doc {
+		if gcv.engine == nil {
+			gcv.engine = NewLayoutEngine()
+		}
+		gcv.editor = NewRichTextEditor(doc, gcv.engine)
+		gcv.editor.OnChanged = func() {
+			if gcv.onChanged != nil {
+				gcv.onChanged()
+			}
+		}
+		gcv.editor.OnCaretMoved = gcv.positionChanged
+		gcv.editor.OnLinkTapped = gcv.FollowLink
+	} else {
+		gcv.editor.Reload()
+	}
+	gcv.editor.SetScroll(gcv.scroll)
+	return []fyne.CanvasObject{gcv.editor}
+}
+
+// visibleEditor 返回当前显示的编辑器，没有显示编辑器时返回nil
+func (gcv *ContentView) visibleEditor() *RichTextEditor {
+	if gcv.editor == nil || (gcv.currentNode != "paragraphs" && gcv.currentNode != "title") {
+		return nil
+	}
+	if gcv.editor.Document() != gcv.docManager.GetCurrentDocument() {
+		return nil
+	}
+	return gcv.editor
+}
+
+// EditParagraph 显示编辑器并将光标移到段落开头
+func (gcv *ContentView) EditParagraph(paragraph int) {
+	gcv.ShowPosition(document.TextPosition{Paragraph: paragraph})
+}
+
+// ShowPosition 显示编辑器并将光标移到指定位置
+func (gcv *ContentView) ShowPosition(pos document.TextPosition) {
+	gcv.ShowNode("paragraphs")
+	if gcv.editor != nil {
+		gcv.editor.SetCaret(pos)
+		gcv.editor.requestFocus()
+	}
+}
+
+// EditorRange 返回编辑器中选择的文字范围，没有选择文字时返回光标位置的空范围，没有显示编辑器时返回false
+func (gcv *ContentView) EditorRange() (document.TextRange, bool) {
+	editor := gcv.visibleEditor()
+	if editor == nil {
+		return document.TextRange{}, false
+	}
+	if r, ok := editor.Selection(); ok {
+		return r, true
+	}
+	caret := editor.Caret()
+	return document.TextRange{
+		StartParagraph: caret.Paragraph,
+		StartOffset:    caret.Offset,
+		EndParagraph:   caret.Paragraph,
+		EndOffset:      caret.Offset,
+	}, true
+}
+
+// ShowParagraph 在段落被修改后刷新显示：显示编辑器时重新排版并保留光标和选择范围，否则显示段落详情
+func (gcv *ContentView) ShowParagraph(paragraph int) {
+	if gcv.visibleEditor() != nil {
+		gcv.updateContent()
+		return
+	}
+	gcv.ShowNode(fmt.Sprintf("p%d", paragraph+1))
+}
+
+// Selection 返回内容视图中选择的文字范围，没有选择文字时返回当前段落的整个范围
+//
+// 显示编辑器时返回编辑器中的选择范围，可以跨越多个段落；当前显示的不是段落时返回false。
+func (gcv *ContentView) Selection() (document.TextRange, bool) {
+	if editor := gcv.visibleEditor(); editor != nil {
+		if r, ok := editor.Selection(); ok {
+			return r, true
+		}
+		return document.ParagraphRange(editor.Caret().Paragraph), true
+	}
+	paragraph := gcv.CurrentParagraph()
+	if paragraph < 0 {
+		return document.TextRange{}, false
+	}
+	return document.ParagraphRange(paragraph), true
+}
+
+// Copy 复制编辑器中选择的文字
+func (gcv *ContentView) Copy() error {
+	editor := gcv.visibleEditor()
+	if editor == nil || !editor.Copy() {
+		return fmt.Errorf("请先在文档内容中选择文字")
+	}
+	return nil
+}
+
+// Cut 剪切编辑器中选择的文字
+func (gcv *ContentView) Cut() error {
+	editor := gcv.visibleEditor()
+	if editor == nil || !editor.Cut() {
+		return fmt.Errorf("请先在文档内容中选择文字")
+	}
+	return nil
+}
+
+// Paste 将剪贴板的内容粘贴到编辑器的光标位置
+func (gcv *ContentView) Paste() error {
+	editor := gcv.visibleEditor()
+	if editor == nil {
+		return fmt.Errorf("请先在文档内容中确定粘贴位置")
+	}
+	editor.Paste()
+	return nil
+}
+
+// Undo 撤销当前文档最近一步操作，返回操作名称
+func (gcv *ContentView) Undo() (string, error) {
+	return gcv.applyHistory((*document.Document).Undo)
+}
+
+// Redo 重做当前文档最近撤销的操作，返回操作名称
+func (gcv *ContentView) Redo() (string, error) {
+	return gcv.applyHistory((*document.Document).Redo)
+}
+
+// applyHistory 对当前文档执行撤销或重做并刷新显示
+func (gcv *ContentView) applyHistory(apply func(*document.Document) (string, error)) (string, error) {
+	doc := gcv.docManager.GetCurrentDocument()
+	if doc == nil {
+		return "", fmt.Errorf("没有打开的文档")
+	}
+	label, err := apply(doc)
+	if err != nil {
+		return "", err
+	}
+	gcv.updateContent()
+	if gcv.onChanged != nil {
+		gcv.onChanged()
+	}
+	return label, nil
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// statusMessageDuration 状态栏中临时消息的显示时间
+const statusMessageDuration = 5 * time.Second
+
+// StatusBar 主窗口底部的状态栏：文档路径和修改状态、临时消息、保存进度、当前段落和样式、字数
+type StatusBar struct {
+	container *fyne.Container
+
+	path      *widget.Label
+	modified  *widget.Label
+	message   *widget.Label
+	progress  *widget.ProgressBarInfinite
+	position  *widget.Label
+	wordCount *widget.Button
+
+	messageID int // 每条临时消息的序号，用于只清除自己显示的消息
+}
+
+// NewStatusBar 创建状态栏，点击字数时调用 onWordCount
+func NewStatusBar(onWordCount func()) *StatusBar {
+	sb := &StatusBar{
+		path:      widget.NewLabel(""),
+		modified:  widget.NewLabel(""),
+		message:   widget.NewLabel(""),
+		progress:  widget.NewProgressBarInfinite(),
+		position:  widget.NewLabel(""),
+		wordCount: widget.NewButton("", onWordCount),
+	}
+	sb.path.Truncation = fyne.TextTruncateEllipsis
+	sb.message.Importance = widget.HighImportance
+	sb.wordCount.Importance = widget.LowImportance
+	sb.progress.Stop()
+	sb.progress.Hide()
+
+	right := container.NewHBox(sb.message, sb.progress, sb.position, sb.wordCount)
+	sb.container = container.NewBorder(widget.NewSeparator(), nil, sb.modified, right, sb.path)
+	return sb
+}
+
+// GetWidget 返回状态栏组件
+func (sb *StatusBar) GetWidget() fyne.CanvasObject {
+	return sb.container
+}
+
+// SetDocument 显示当前文档的路径和修改状态，path 为空表示没有打开的文档
+func (sb *StatusBar) SetDocument(path string, modified, readOnly bool) {
+	sb.path.SetText(path)
+	switch {
+	case path == "":
+		sb.modified.SetText("")
+	case readOnly:
+		sb.modified.SetText("只读")
+	case modified:
+		sb.modified.SetText("● 已修改")
+	default:
+		sb.modified.SetText("已保存")
+	}
+}
+
+// SetWordCount 显示字数和字符数，words 小于0时不显示
+func (sb *StatusBar) SetWordCount(words, characters int) {
+	if words < 0 {
+		sb.wordCount.SetText("")
+		return
+	}
+	sb.wordCount.SetText(fmt.Sprintf("字数: %d  字符数: %d", words, characters))
+}
+
+// SetPosition 显示当前段落（从0开始的索引）和它的样式，paragraph 小于0时不显示
+func (sb *StatusBar) SetPosition(paragraph int, style string) {
+	if paragraph < 0 {
+		sb.position.SetText("")
+		return
+	}
+	text := fmt.Sprintf("第 %d 段", paragraph+1)
+	if style != "" {
+		text += "  样式: " + style
+	}
+	sb.position.SetText(text)
+}
+
+// ShowMessage 在状态栏中显示一条临时消息，几秒后自动清除
+func (sb *StatusBar) ShowMessage(text string) {
+	sb.messageID++
+	id := sb.messageID
+	sb.message.SetText(text)
+	time.AfterFunc(statusMessageDuration, func() {
+		fyne.Do(func() {
+			if sb.messageID == id {
+				sb.message.SetText("")
+			}
+		})
+	})
+}
+
+// StartProgress 显示正在进行的操作，例如保存文档，直到调用 StopProgress
+func (sb *StatusBar) StartProgress(text string) {
+	sb.messageID++
+	sb.message.SetText(text)
+	sb.progress.Show()
+	sb.progress.Start()
+}
+
+// StopProgress 隐藏进度，text 不为空时作为临时消息显示
+func (sb *StatusBar) StopProgress(text string) {
+	sb.progress.Stop()
+	sb.progress.Hide()
+	if text != "" {
+		sb.ShowMessage(text)
+	} else {
+		sb.message.SetText("")
+	}
+}
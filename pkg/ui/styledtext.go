@@ -0,0 +1,256 @@
+package ui
+
+import (
+	"image/color"
+	"strings"
+	"unicode"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// StyledSegment 格式相同的一段文字
+type StyledSegment struct {
+	Text      string
+	Bold      bool
+	Italic    bool
+	Underline bool
+	Strike    bool
+	Color     color.Color // 为nil时使用主题前景色
+	Highlight color.Color // 背景色，为nil时不突出显示
+}
+
+// StyledText 按段落自动换行显示带格式文字的组件
+//
+// 与 widget.RichText 不同，StyledText 支持下划线和删除线，
+// 中文按字符换行，英文按单词换行。
+type StyledText struct {
+	widget.BaseWidget
+	Segments []StyledSegment
+}
+
+// NewStyledText 创建带格式文字组件
+func NewStyledText(segments ...StyledSegment) *StyledText {
+	t := &StyledText{Segments: segments}
+	t.ExtendBaseWidget(t)
+	return t
+}
+
+// SetSegments 替换显示的文字片段
+func (t *StyledText) SetSegments(segments []StyledSegment) {
+	t.Segments = segments
+	t.Refresh()
+}
+
+// CreateRenderer 实现 fyne.Widget 接口
+func (t *StyledText) CreateRenderer() fyne.WidgetRenderer {
+	r := &styledTextRenderer{text: t}
+	r.Layout(t.Size())
+	return r
+}
+
+// styledTextRenderer 负责排版和绘制
+type styledTextRenderer struct {
+	text    *StyledText
+	objects []fyne.CanvasObject
+	width   float32
+	height  float32
+}
+
+// styledToken 排版时不可拆分的最小单位
+type styledToken struct {
+	text    string
+	segment int
+	newline bool
+	space   bool
+}
+
+// Layout 按宽度重新排版
+func (r *styledTextRenderer) Layout(size fyne.Size) {
+	r.objects, r.height = r.layoutFor(size.Width, true)
+	r.width = size.Width
+}
+
+// MinSize 宽度固定时返回排版所需的高度
+func (r *styledTextRenderer) MinSize() fyne.Size {
+	pad := theme.InnerPadding()
+	width := r.text.Size().Width
+	if width <= 0 {
+		// 尚未确定宽度时按单行计算
+		_, height := r.layoutFor(0, false)
+		return fyne.NewSize(pad*2, height)
+	}
+	if width != r.width {
+		_, height := r.layoutFor(width, false)
+		return fyne.NewSize(pad*2, height)
+	}
+	return fyne.NewSize(pad*2, r.height)
+}
+
+// Refresh 内容或主题变化时重新排版
+func (r *styledTextRenderer) Refresh() {
+	r.Layout(r.text.Size())
+	canvas.Refresh(r.text)
+}
+
+// Objects 返回绘制对象
+func (r *styledTextRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+// Destroy 实现 fyne.WidgetRenderer 接口
+func (r *styledTextRenderer) Destroy() {
+}
+
+// layoutFor 按给定宽度排版，width 为0时不换行；build 为false时只计算高度
+func (r *styledTextRenderer) layoutFor(width float32, build bool) ([]fyne.CanvasObject, float32) {
+	pad := theme.InnerPadding()
+	textSize := theme.TextSize()
+	lineHeight := fyne.MeasureText("国", textSize, fyne.TextStyle{}).Height
+	maxWidth := width - pad*2
+
+	var objects []fyne.CanvasObject
+	x, y := float32(0), float32(0)
+
+	// 同一行中连续的同一片段合并为一个 canvas.Text
+	var pending strings.Builder
+	pendingSegment := -1
+	pendingX := float32(0)
+	flush := func() {
+		if pendingSegment < 0 || pending.Len() == 0 {
+			pending.Reset()
+			pendingSegment = -1
+			return
+		}
+		if build {
+			objects = append(objects, r.textObjects(r.text.Segments[pendingSegment], pending.String(),
+				pad+pendingX, pad+y, lineHeight)...)
+		}
+		pending.Reset()
+		pendingSegment = -1
+	}
+	newLine := func() {
+		flush()
+		x = 0
+		y += lineHeight
+	}
+
+	for _, token := range r.tokens() {
+		if token.newline {
+			newLine()
+			continue
+		}
+		style := segmentStyle(r.text.Segments[token.segment])
+		w := fyne.MeasureText(token.text, textSize, style).Width
+		if maxWidth > 0 && x > 0 && x+w > maxWidth {
+			newLine()
+			if token.space {
+				// 行首的空白不显示
+				continue
+			}
+		}
+		if token.segment != pendingSegment {
+			flush()
+			pendingSegment = token.segment
+			pendingX = x
+		}
+		pending.WriteString(token.text)
+		x += w
+	}
+	flush()
+
+	return objects, y + lineHeight + pad*2
+}
+
+// textObjects 创建一段文字及其背景、下划线和删除线
+func (r *styledTextRenderer) textObjects(segment StyledSegment, text string, x, y, lineHeight float32) []fyne.CanvasObject {
+	fg := segment.Color
+	if fg == nil {
+		fg = theme.Color(theme.ColorNameForeground)
+	}
+	style := segmentStyle(segment)
+
+	label := canvas.NewText(text, fg)
+	label.TextStyle = style
+	label.TextSize = theme.TextSize()
+	size := fyne.MeasureText(text, label.TextSize, style)
+	label.Move(fyne.NewPos(x, y))
+	label.Resize(fyne.NewSize(size.Width, lineHeight))
+
+	var objects []fyne.CanvasObject
+	if segment.Highlight != nil {
+		background := canvas.NewRectangle(segment.Highlight)
+		background.Move(fyne.NewPos(x, y))
+		background.Resize(fyne.NewSize(size.Width, lineHeight))
+		objects = append(objects, background)
+	}
+	objects = append(objects, label)
+	if segment.Underline {
+		objects = append(objects, decorationLine(fg, x, y+lineHeight-1, size.Width))
+	}
+	if segment.Strike {
+		objects = append(objects, decorationLine(fg, x, y+lineHeight/2, size.Width))
+	}
+	return objects
+}
+
+// decorationLine 创建下划线或删除线
+func decorationLine(c color.Color, x, y, width float32) fyne.CanvasObject {
+	line := canvas.NewLine(c)
+	line.StrokeWidth = 1
+	line.Position1 = fyne.NewPos(x, y)
+	line.Position2 = fyne.NewPos(x+width, y)
+	return line
+}
+
+// segmentStyle 返回片段的字体样式
+func segmentStyle(segment StyledSegment) fyne.TextStyle {
+	return fyne.TextStyle{Bold: segment.Bold, Italic: segment.Italic}
+}
+
+// tokens 将所有片段拆分为排版单位：英文单词、空白、单个中文字符和换行
+func (r *styledTextRenderer) tokens() []styledToken {
+	var tokens []styledToken
+	for i, segment := range r.text.Segments {
+		var word []rune
+		wordSpace := false
+		emit := func() {
+			if len(word) > 0 {
+				tokens = append(tokens, styledToken{text: string(word), segment: i, space: wordSpace})
+				word = word[:0]
+			}
+		}
+		for _, ch := range segment.Text {
+			switch {
+			case ch == '\n':
+				emit()
+				tokens = append(tokens, styledToken{segment: i, newline: true})
+			case ch == '\t':
+				emit()
+				tokens = append(tokens, styledToken{text: "    ", segment: i, space: true})
+			case unicode.IsSpace(ch):
+				if !wordSpace {
+					emit()
+				}
+				wordSpace = true
+				word = append(word, ch)
+			case ch > unicode.MaxLatin1 && !unicode.IsLetter(ch) && !unicode.IsDigit(ch),
+				unicode.Is(unicode.Han, ch), unicode.Is(unicode.Hiragana, ch),
+				unicode.Is(unicode.Katakana, ch), unicode.Is(unicode.Hangul, ch):
+				// 中日韩字符和全角标点单独成为排版单位
+				emit()
+				tokens = append(tokens, styledToken{text: string(ch), segment: i})
+			default:
+				if wordSpace {
+					emit()
+				}
+				wordSpace = false
+				word = append(word, ch)
+			}
+		}
+		emit()
+	}
+	return tokens
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tanqiangyes/fyne-word/pkg/document"
+)
+
+// templateDataExtensions 占位符数据文件的扩展名
+var templateDataExtensions = []string{".json", ".yaml", ".yml"}
+
+// ShowPlaceholderDialog 按文档中的占位符生成表单，填写或从JSON/YAML文件导入值后替换占位符
+//
+// 留空的占位符保持不变。替换前记录撤销点，onFilled 收到替换的数量和没有填写的占位符。
+func ShowPlaceholderDialog(doc *document.Document, window fyne.Window, onFilled func(filled int, missing []string)) {
+	names, err := doc.Placeholders()
+	if err != nil {
+		dialog.ShowError(err, window)
+		return
+	}
+	if len(names) == 0 {
+		dialog.ShowInformation("填写占位符", "文档中没有 {{名称}} 形式的占位符", window)
+		return
+	}
+
+	entries := make(map[string]*widget.Entry)
+	form := widget.NewForm()
+	for _, name := range names {
+		entry := widget.NewEntry()
+		entry.SetPlaceHolder("{{" + name + "}}")
+		entries[name] = entry
+		form.Append(name, entry)
+	}
+
+	status := widget.NewLabel(fmt.Sprintf("共 %d 个占位符，留空的保持不变", len(names)))
+	status.Wrapping = fyne.TextWrapWord
+	importButton := widget.NewButton("从文件导入...", func() {
+		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			if reader == nil {
+				return
+			}
+			reader.Close()
+			values, err := document.LoadTemplateData(reader.URI().Path())
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			imported := 0
+			for _, name := range names {
+				for key, value := range values {
+					if strings.EqualFold(key, name) {
+						entries[name].SetText(value)
+						imported++
+						break
+					}
+				}
+			}
+			status.SetText(fmt.Sprintf("已从 %s 导入 %d 个值，共 %d 个占位符", reader.URI().Name(), imported, len(names)))
+		}, window)
+		fd.SetFilter(storage.NewExtensionFileFilter(templateDataExtensions))
+		fd.Show()
+	})
+
+	content := container.NewBorder(container.NewVBox(status, container.NewHBox(importButton)), nil, nil, nil,
+		container.NewVScroll(form))
+	d := dialog.NewCustomConfirm("填写占位符", "填写", "取消", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		values := make(map[string]string)
+		for name, entry := range entries {
+			if entry.Text != "" {
+				values[name] = entry.Text
+			}
+		}
+		doc.Checkpoint("填写占位符")
+		filled, missing, err := doc.FillPlaceholders(values)
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		if onFilled != nil {
+			onFilled(filled, missing)
+		}
+	}, window)
+	d.Resize(fyne.NewSize(520, 480))
+	d.Show()
+}
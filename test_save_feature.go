@@ -25,7 +25,7 @@ func main() {
 	
 	// 创建新文档
 	fmt.Println("\n1. 创建新文档...")
-	doc, err := manager.NewDocument()
+	doc, err := manager.NewDocument(document.NewDocumentOptions{})
 	if err != nil {
 		log.Fatalf("新建文档失败: %v", err)
 	}